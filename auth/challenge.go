@@ -0,0 +1,261 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package auth
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// WWWAuthenticateHeader is the header a 401 response uses to carry its challenges.
+const WWWAuthenticateHeader = "WWW-Authenticate"
+
+// Bearer token error codes of RFC 6750, section 3.1.
+const (
+	// BearerErrorInvalidRequest reports a malformed Authorization header.
+	BearerErrorInvalidRequest = "invalid_request"
+	// BearerErrorInvalidToken reports an expired, revoked or otherwise invalid token.
+	BearerErrorInvalidToken = "invalid_token"
+)
+
+// Challenge is a single authentication challenge of a WWW-Authenticate header,
+// as defined by RFC 9110, section 11.
+type Challenge struct {
+	// Scheme is the authentication scheme, e.g. "Bearer".
+	Scheme string
+	// Realm is the optional protection space of the challenge.
+	Realm string
+	// Params holds the other auth parameters, e.g. "error" or "scope".
+	Params map[string]string
+}
+
+// String formats the challenge as it appears in a WWW-Authenticate header, with
+// the realm first and the other parameters sorted by name.
+func (c Challenge) String() string {
+	var b strings.Builder
+	b.WriteString(c.Scheme)
+	params := make([]string, 0, len(c.Params)+1)
+	if c.Realm != "" {
+		params = append(params, "realm="+quoteParam(c.Realm))
+	}
+	names := make([]string, 0, len(c.Params))
+	for name := range c.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		params = append(params, name+"="+quoteParam(c.Params[name]))
+	}
+	if len(params) > 0 {
+		b.WriteString(" ")
+		b.WriteString(strings.Join(params, ", "))
+	}
+	return b.String()
+}
+
+// Challenger is implemented by providers that can describe the credentials they
+// accept, so requests they reject get a WWW-Authenticate header.
+type Challenger interface {
+	// Challenges returns the challenges for a request rejected with err.
+	Challenges(err error) []Challenge
+}
+
+// bearerChallenge returns the Bearer challenge for a request rejected with err.
+// Following RFC 6750, a request without credentials gets no error code.
+func bearerChallenge(err error) Challenge {
+	challenge := Challenge{Scheme: string(TokenTypeBearer)}
+	switch {
+	case errors.Is(err, ErrMissingToken):
+	case errors.Is(err, ErrInvalidAuthHeader):
+		challenge.Params = map[string]string{"error": BearerErrorInvalidRequest}
+	default:
+		challenge.Params = map[string]string{"error": BearerErrorInvalidToken}
+	}
+	return challenge
+}
+
+// Challenges implements Challenger.
+func (p *JWTAuthProvider) Challenges(err error) []Challenge {
+	return []Challenge{bearerChallenge(err)}
+}
+
+// Challenges implements Challenger.
+func (p *OAuth2AuthProvider) Challenges(err error) []Challenge {
+	return []Challenge{bearerChallenge(err)}
+}
+
+// Challenges implements Challenger.
+func (p *OAuth2IntrospectionProvider) Challenges(err error) []Challenge {
+	return []Challenge{bearerChallenge(err)}
+}
+
+// Challenges implements Challenger. API keys have no registered scheme, so the
+// challenge uses "ApiKey" and names the header the key is expected in.
+func (p *APIKeyAuthProvider) Challenges(err error) []Challenge {
+	return []Challenge{{Scheme: "ApiKey", Params: map[string]string{"header": p.HeaderName}}}
+}
+
+// Challenges implements Challenger, collecting the challenges of every provider
+// in the chain that implements it, without duplicates.
+func (p *ChainAuthProvider) Challenges(err error) []Challenge {
+	var challenges []Challenge
+	seen := make(map[string]bool)
+	for _, provider := range p.providers {
+		challenger, ok := provider.(Challenger)
+		if !ok {
+			continue
+		}
+		for _, challenge := range challenger.Challenges(err) {
+			if key := challenge.String(); !seen[key] {
+				seen[key] = true
+				challenges = append(challenges, challenge)
+			}
+		}
+	}
+	return challenges
+}
+
+// ParseChallenges parses the challenges of WWW-Authenticate header values.
+// Scheme names are kept as sent; parameter names are lowercased. Malformed
+// parts and token68 credentials are skipped.
+func ParseChallenges(values ...string) []Challenge {
+	var challenges []Challenge
+	for _, value := range values {
+		p := challengeParser{s: value}
+		// A new challenge starts at the beginning or after a comma; a bare token
+		// anywhere else is token68 credentials.
+		atStart := true
+		for {
+			p.skip(" \t")
+			if p.peek() == ',' {
+				p.pos++
+				atStart = true
+				continue
+			}
+			if p.done() {
+				break
+			}
+			name := p.token()
+			if name == "" {
+				p.pos++ // Skip the unexpected character.
+				continue
+			}
+			wasAtStart := atStart
+			atStart = false
+			p.skip(" \t")
+			if p.peek() != '=' {
+				if wasAtStart {
+					challenges = append(challenges, Challenge{Scheme: name})
+				}
+				continue
+			}
+			p.pos++
+			if c := p.peek(); c == '=' || c == ',' || c == 0 {
+				p.skip("=") // token68, e.g. "Negotiate abc==".
+				continue
+			}
+			p.skip(" \t")
+			value := p.value()
+			if len(challenges) == 0 {
+				continue // A parameter without a scheme.
+			}
+			current := &challenges[len(challenges)-1]
+			if name = strings.ToLower(name); name == "realm" {
+				current.Realm = value
+				continue
+			}
+			if current.Params == nil {
+				current.Params = make(map[string]string)
+			}
+			current.Params[name] = value
+		}
+	}
+	return challenges
+}
+
+// challengeParser scans a WWW-Authenticate header value.
+type challengeParser struct {
+	s   string
+	pos int
+}
+
+// done reports whether the whole value was consumed.
+func (p *challengeParser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+// peek returns the next character, or 0 at the end.
+func (p *challengeParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// skip consumes any of the given characters.
+func (p *challengeParser) skip(chars string) {
+	for !p.done() && strings.IndexByte(chars, p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// token consumes an RFC 9110 token.
+func (p *challengeParser) token() string {
+	start := p.pos
+	for !p.done() && isTokenChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// value consumes a parameter value, either a token or a quoted string.
+func (p *challengeParser) value() string {
+	if p.peek() != '"' {
+		return p.token()
+	}
+	p.pos++
+	var b strings.Builder
+	for !p.done() {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '"':
+			return b.String()
+		case c == '\\' && !p.done():
+			b.WriteByte(p.s[p.pos])
+			p.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// isTokenChar reports whether c may appear in an RFC 9110 token.
+func isTokenChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+	}
+}
+
+// quoteParam formats a parameter value as a quoted string.
+func quoteParam(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		if value[i] == '"' || value[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(value[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
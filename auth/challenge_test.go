@@ -0,0 +1,113 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package auth_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
+)
+
+func TestChallenge_String(t *testing.T) {
+	assert.Equal(t, "Bearer", auth.Challenge{Scheme: "Bearer"}.String())
+	assert.Equal(t,
+		`Bearer realm="a2a \"agent\"", error="invalid_token", scope="tasks"`,
+		auth.Challenge{
+			Scheme: "Bearer",
+			Realm:  `a2a "agent"`,
+			Params: map[string]string{"scope": "tasks", "error": "invalid_token"},
+		}.String(),
+	)
+}
+
+func TestParseChallenges(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []auth.Challenge
+	}{
+		{
+			name:   "SchemeOnly",
+			values: []string{"Bearer"},
+			want:   []auth.Challenge{{Scheme: "Bearer"}},
+		},
+		{
+			name:   "QuotedAndTokenParams",
+			values: []string{`Bearer realm="my \"agent\"", Error=invalid_token, error_description="token expired"`},
+			want: []auth.Challenge{{
+				Scheme: "Bearer",
+				Realm:  `my "agent"`,
+				Params: map[string]string{"error": "invalid_token", "error_description": "token expired"},
+			}},
+		},
+		{
+			name:   "SeveralChallengesInOneValue",
+			values: []string{`Basic realm="a", Bearer realm="b", scope="read"`},
+			want: []auth.Challenge{
+				{Scheme: "Basic", Realm: "a"},
+				{Scheme: "Bearer", Realm: "b", Params: map[string]string{"scope": "read"}},
+			},
+		},
+		{
+			name:   "SeveralValues",
+			values: []string{`Bearer realm="a"`, `ApiKey header="X-API-Key"`},
+			want: []auth.Challenge{
+				{Scheme: "Bearer", Realm: "a"},
+				{Scheme: "ApiKey", Params: map[string]string{"header": "X-API-Key"}},
+			},
+		},
+		{
+			name:   "Token68Skipped",
+			values: []string{"Negotiate YIIB==, Bearer"},
+			want:   []auth.Challenge{{Scheme: "Negotiate"}, {Scheme: "Bearer"}},
+		},
+		{
+			name:   "Empty",
+			values: []string{"", " , "},
+			want:   nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, auth.ParseChallenges(tc.values...))
+		})
+	}
+}
+
+func TestChallenges_RoundTrip(t *testing.T) {
+	challenge := auth.Challenge{
+		Scheme: "Bearer",
+		Realm:  "Test Agent",
+		Params: map[string]string{"error": auth.BearerErrorInvalidToken},
+	}
+	assert.Equal(t, []auth.Challenge{challenge}, auth.ParseChallenges(challenge.String()))
+}
+
+func TestProviderChallenges(t *testing.T) {
+	jwtProvider := auth.NewJWTAuthProvider([]byte("secret"), "", "", time.Hour)
+	apiKeyProvider := auth.NewAPIKeyAuthProvider(map[string]string{"key": "user"}, "X-API-Key")
+
+	assert.Equal(t, []auth.Challenge{{Scheme: "Bearer"}}, jwtProvider.Challenges(auth.ErrMissingToken),
+		"a missing token gets no error code")
+	assert.Equal(t,
+		[]auth.Challenge{{Scheme: "Bearer", Params: map[string]string{"error": auth.BearerErrorInvalidRequest}}},
+		jwtProvider.Challenges(auth.ErrInvalidAuthHeader))
+	assert.Equal(t,
+		[]auth.Challenge{{Scheme: "Bearer", Params: map[string]string{"error": auth.BearerErrorInvalidToken}}},
+		jwtProvider.Challenges(fmt.Errorf("token is expired")))
+
+	chain := auth.NewChainAuthProvider(jwtProvider, auth.NewOAuth2ClientCredentialsProvider("id", "secret", "", nil),
+		apiKeyProvider)
+	assert.Equal(t, []auth.Challenge{
+		{Scheme: "Bearer"},
+		{Scheme: "ApiKey", Params: map[string]string{"header": "X-API-Key"}},
+	}, chain.Challenges(auth.ErrMissingToken), "duplicate challenges are merged")
+}
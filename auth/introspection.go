@@ -0,0 +1,245 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Introspection errors.
+var (
+	// ErrInactiveToken is returned when the introspection endpoint reports the token as inactive.
+	ErrInactiveToken = errors.New("token is not active")
+	// ErrIntrospectionFailed is returned when the introspection endpoint cannot be queried.
+	ErrIntrospectionFailed = errors.New("token introspection failed")
+)
+
+// introspectionResponse holds the standard RFC 7662 introspection response fields.
+type introspectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+}
+
+// defaultIntrospectionCacheSize is the default maximum number of cached introspection results.
+const defaultIntrospectionCacheSize = 10000
+
+// introspectionCacheEntry is a cached introspection result for a token.
+type introspectionCacheEntry struct {
+	user   *User
+	expiry time.Time
+}
+
+// OAuth2IntrospectionProvider authenticates opaque OAuth2 bearer tokens by calling
+// an RFC 7662 token introspection endpoint. Results for active tokens are cached
+// until the token expires, up to a maximum number of tokens.
+type OAuth2IntrospectionProvider struct {
+	// introspectionURL is the URL of the introspection endpoint.
+	introspectionURL string
+	// clientID and clientSecret authenticate this server to the introspection endpoint.
+	clientID     string
+	clientSecret string
+	// httpClient is used to call the introspection endpoint.
+	httpClient *http.Client
+
+	cacheMu   sync.RWMutex
+	cache     map[string]introspectionCacheEntry
+	cacheSize int
+}
+
+// NewOAuth2IntrospectionProvider creates a new provider that validates tokens against
+// the given introspection endpoint, authenticating with HTTP basic auth when a client ID is set.
+func NewOAuth2IntrospectionProvider(introspectionURL, clientID, clientSecret string) *OAuth2IntrospectionProvider {
+	return &OAuth2IntrospectionProvider{
+		introspectionURL: introspectionURL,
+		clientID:         clientID,
+		clientSecret:     clientSecret,
+		httpClient:       &http.Client{Timeout: 10 * time.Second},
+		cache:            make(map[string]introspectionCacheEntry),
+		cacheSize:        defaultIntrospectionCacheSize,
+	}
+}
+
+// SetHTTPClient sets the HTTP client used to call the introspection endpoint.
+func (p *OAuth2IntrospectionProvider) SetHTTPClient(client *http.Client) {
+	if client != nil {
+		p.httpClient = client
+	}
+}
+
+// SetCacheSize sets the maximum number of cached introspection results.
+// A size of zero or less disables caching.
+func (p *OAuth2IntrospectionProvider) SetCacheSize(size int) {
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	p.cacheSize = size
+	for token := range p.cache {
+		if len(p.cache) <= size {
+			break
+		}
+		delete(p.cache, token)
+	}
+}
+
+// Authenticate validates the bearer token from the request's Authorization header
+// via the introspection endpoint.
+func (p *OAuth2IntrospectionProvider) Authenticate(r *http.Request) (*User, error) {
+	authHeader := r.Header.Get(AuthHeaderName)
+	if authHeader == "" {
+		return nil, ErrMissingToken
+	}
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || !strings.EqualFold(parts[0], string(TokenTypeBearer)) {
+		return nil, ErrInvalidAuthHeader
+	}
+	tokenString := parts[1]
+	if user, ok := p.cachedUser(tokenString); ok {
+		return user, nil
+	}
+	result, claims, err := p.introspect(r, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Active {
+		return nil, ErrInactiveToken
+	}
+	user := &User{
+		ID:     result.Sub,
+		Claims: claims,
+		OAuth2Info: &OAuth2UserInfo{
+			AccessToken: tokenString,
+			TokenType:   string(TokenTypeBearer),
+			Scope:       result.Scope,
+		},
+	}
+	if user.ID == "" {
+		user.ID = result.Username
+	}
+	// Only cache tokens with a known expiry.
+	if result.Exp > 0 {
+		expiry := time.Unix(result.Exp, 0)
+		user.OAuth2Info.Expiry = expiry
+		if time.Now().Before(expiry) {
+			p.storeUser(tokenString, copyUser(user), expiry)
+		}
+	}
+	return user, nil
+}
+
+// storeUser caches the user for a token. When the cache is full, expired entries are
+// swept first and an arbitrary entry is evicted if that does not free a slot.
+func (p *OAuth2IntrospectionProvider) storeUser(token string, user *User, expiry time.Time) {
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+	if p.cacheSize <= 0 {
+		return
+	}
+	if _, exists := p.cache[token]; !exists && len(p.cache) >= p.cacheSize {
+		now := time.Now()
+		for cached, entry := range p.cache {
+			if !now.Before(entry.expiry) {
+				delete(p.cache, cached)
+			}
+		}
+		for cached := range p.cache {
+			if len(p.cache) < p.cacheSize {
+				break
+			}
+			delete(p.cache, cached)
+		}
+	}
+	p.cache[token] = introspectionCacheEntry{user: user, expiry: expiry}
+}
+
+// cachedUser returns the cached user for a token if it has not yet expired.
+func (p *OAuth2IntrospectionProvider) cachedUser(token string) (*User, bool) {
+	p.cacheMu.RLock()
+	entry, ok := p.cache[token]
+	p.cacheMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if time.Now().Before(entry.expiry) {
+		return copyUser(entry.user), true
+	}
+	p.cacheMu.Lock()
+	delete(p.cache, token)
+	p.cacheMu.Unlock()
+	return nil, false
+}
+
+// copyUser returns a copy of user that callers may modify without affecting the cache.
+func copyUser(user *User) *User {
+	userCopy := *user
+	if user.Claims != nil {
+		userCopy.Claims = make(jwt.MapClaims, len(user.Claims))
+		for k, v := range user.Claims {
+			userCopy.Claims[k] = v
+		}
+	}
+	if user.OAuth2Info != nil {
+		info := *user.OAuth2Info
+		userCopy.OAuth2Info = &info
+	}
+	return &userCopy
+}
+
+// introspect calls the introspection endpoint for the token.
+func (p *OAuth2IntrospectionProvider) introspect(
+	r *http.Request,
+	token string,
+) (*introspectionResponse, jwt.MapClaims, error) {
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", "access_token")
+	req, err := http.NewRequestWithContext(
+		r.Context(), http.MethodPost, p.introspectionURL, strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to create request: %v", ErrIntrospectionFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrIntrospectionFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%w: unexpected status code: %d", ErrIntrospectionFailed, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to read response body: %v", ErrIntrospectionFailed, err)
+	}
+	var result introspectionResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to parse response: %v", ErrIntrospectionFailed, err)
+	}
+	claims := jwt.MapClaims{}
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to parse claims: %v", ErrIntrospectionFailed, err)
+	}
+	return &result, claims, nil
+}
@@ -0,0 +1,190 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
+)
+
+func TestOAuth2IntrospectionProvider(t *testing.T) {
+	var calls int32
+	introspectionServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "resource-server" || clientSecret != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		var resp map[string]interface{}
+		switch r.Form.Get("token") {
+		case "active-token":
+			resp = map[string]interface{}{
+				"active":    true,
+				"sub":       "user-123",
+				"scope":     "tasks:read tasks:write",
+				"client_id": "client-app",
+				"exp":       time.Now().Add(time.Hour).Unix(),
+			}
+		case "active-no-exp":
+			resp = map[string]interface{}{"active": true, "username": "alice"}
+		case "cached-a", "cached-b", "cached-c":
+			resp = map[string]interface{}{
+				"active": true,
+				"sub":    r.Form.Get("token"),
+				"exp":    time.Now().Add(time.Hour).Unix(),
+			}
+		case "broken-token":
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		default:
+			resp = map[string]interface{}{"active": false}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer introspectionServer.Close()
+
+	newRequest := func(token string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if token != "" {
+			req.Header.Set(auth.AuthHeaderName, "Bearer "+token)
+		}
+		return req
+	}
+
+	t.Run("ActiveTokenCached", func(t *testing.T) {
+		provider := auth.NewOAuth2IntrospectionProvider(introspectionServer.URL, "resource-server", "secret")
+		atomic.StoreInt32(&calls, 0)
+
+		user, err := provider.Authenticate(newRequest("active-token"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-123", user.ID)
+		require.NotNil(t, user.OAuth2Info)
+		assert.Equal(t, "tasks:read tasks:write", user.OAuth2Info.Scope)
+		assert.False(t, user.OAuth2Info.Expiry.IsZero())
+		assert.Equal(t, "client-app", user.Claims["client_id"])
+
+		// Second call is served from the cache.
+		user, err = provider.Authenticate(newRequest("active-token"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-123", user.ID)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("CachedUserIsCopy", func(t *testing.T) {
+		provider := auth.NewOAuth2IntrospectionProvider(introspectionServer.URL, "resource-server", "secret")
+
+		user, err := provider.Authenticate(newRequest("active-token"))
+		require.NoError(t, err)
+		cached, err := provider.Authenticate(newRequest("active-token"))
+		require.NoError(t, err)
+		cached.ID = "tampered"
+		cached.OAuth2Info.Scope = "admin"
+		cached.Claims["sub"] = "tampered"
+
+		again, err := provider.Authenticate(newRequest("active-token"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-123", again.ID)
+		assert.Equal(t, "tasks:read tasks:write", again.OAuth2Info.Scope)
+		assert.Equal(t, "user-123", again.Claims["sub"])
+		assert.Equal(t, "user-123", user.ID)
+	})
+
+	t.Run("CacheSizeBounded", func(t *testing.T) {
+		provider := auth.NewOAuth2IntrospectionProvider(introspectionServer.URL, "resource-server", "secret")
+		provider.SetCacheSize(2)
+		atomic.StoreInt32(&calls, 0)
+
+		tokens := []string{"cached-a", "cached-b", "cached-c"}
+		for _, token := range tokens {
+			user, err := provider.Authenticate(newRequest(token))
+			require.NoError(t, err)
+			assert.Equal(t, token, user.ID)
+		}
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+		// Only two of the three tokens fit in the cache.
+		for _, token := range tokens {
+			_, err := provider.Authenticate(newRequest(token))
+			require.NoError(t, err)
+		}
+		assert.Greater(t, atomic.LoadInt32(&calls), int32(3))
+	})
+
+	t.Run("ActiveTokenWithoutExpiryNotCached", func(t *testing.T) {
+		provider := auth.NewOAuth2IntrospectionProvider(introspectionServer.URL, "resource-server", "secret")
+		atomic.StoreInt32(&calls, 0)
+
+		for i := 0; i < 2; i++ {
+			user, err := provider.Authenticate(newRequest("active-no-exp"))
+			require.NoError(t, err)
+			assert.Equal(t, "alice", user.ID)
+		}
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("InactiveToken", func(t *testing.T) {
+		provider := auth.NewOAuth2IntrospectionProvider(introspectionServer.URL, "resource-server", "secret")
+
+		user, err := provider.Authenticate(newRequest("revoked-token"))
+		assert.ErrorIs(t, err, auth.ErrInactiveToken)
+		assert.NotErrorIs(t, err, auth.ErrIntrospectionFailed)
+		assert.Nil(t, user)
+	})
+
+	t.Run("EndpointFailure", func(t *testing.T) {
+		provider := auth.NewOAuth2IntrospectionProvider(introspectionServer.URL, "resource-server", "secret")
+
+		user, err := provider.Authenticate(newRequest("broken-token"))
+		assert.ErrorIs(t, err, auth.ErrIntrospectionFailed)
+		assert.NotErrorIs(t, err, auth.ErrInactiveToken)
+		assert.Nil(t, user)
+	})
+
+	t.Run("EndpointRejectsClientCredentials", func(t *testing.T) {
+		provider := auth.NewOAuth2IntrospectionProvider(introspectionServer.URL, "resource-server", "wrong")
+
+		_, err := provider.Authenticate(newRequest("active-token"))
+		assert.ErrorIs(t, err, auth.ErrIntrospectionFailed)
+	})
+
+	t.Run("MiddlewareMapsEndpointFailureToUnavailable", func(t *testing.T) {
+		provider := auth.NewOAuth2IntrospectionProvider(introspectionServer.URL, "resource-server", "secret")
+		handler := auth.NewMiddleware(provider).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, newRequest("broken-token"))
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, newRequest("revoked-token"))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, newRequest("active-token"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		provider := auth.NewOAuth2IntrospectionProvider(introspectionServer.URL, "resource-server", "secret")
+
+		_, err := provider.Authenticate(newRequest(""))
+		assert.ErrorIs(t, err, auth.ErrMissingToken)
+	})
+}
@@ -10,6 +10,7 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -317,6 +318,12 @@ type OAuth2AuthProvider struct {
 	userInfoURL string
 	// UserIDField is the JSON field name that contains the user ID in the userinfo response
 	userIDField string
+	// Token store backed source for the authorization code flow
+	storedSource *storedTokenSource
+	// Shared token cache for the client credentials flow
+	tokenCache TokenCache
+	// Retry policy for failed token fetches, nil to fetch once
+	tokenRetry *tokenRetryPolicy
 }
 
 // NewOAuth2AuthProviderWithConfig creates a new OAuth2 authentication provider with custom OAuth2 config.
@@ -347,6 +354,51 @@ func NewOAuth2ClientCredentialsProvider(clientID, clientSecret, tokenURL string,
 	}
 }
 
+// NewOAuth2AuthCodeProvider creates a new OAuth2 provider for the authorization code flow.
+// Tokens are loaded from the store; expired tokens are refreshed via the refresh token
+// and the refreshed token is saved back to the store.
+func NewOAuth2AuthCodeProvider(config *oauth2.Config, store TokenStore) *OAuth2AuthProvider {
+	source := &storedTokenSource{
+		config: config,
+		store:  store,
+	}
+	return &OAuth2AuthProvider{
+		config:       config,
+		tokenSource:  source,
+		storedSource: source,
+		userIDField:  "sub",
+	}
+}
+
+// AuthCodeURL returns the URL of the consent page for the authorization code flow.
+// The PKCE challenge is derived from the verifier, which should be created with
+// oauth2.GenerateVerifier and passed to ExchangeAuthCode later.
+func (p *OAuth2AuthProvider) AuthCodeURL(state, verifier string) (string, error) {
+	if p.config == nil {
+		return "", errors.New("OAuth2 config not set")
+	}
+	return p.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// ExchangeAuthCode exchanges an authorization code for a token using the PKCE verifier.
+// The token is saved to the token store when the provider was created with one.
+func (p *OAuth2AuthProvider) ExchangeAuthCode(ctx context.Context, code, verifier string) (*oauth2.Token, error) {
+	if p.config == nil {
+		return nil, errors.New("OAuth2 config not set")
+	}
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if p.storedSource != nil {
+		if err := p.storedSource.store.SaveToken(token); err != nil {
+			return nil, fmt.Errorf("failed to save token: %w", err)
+		}
+		p.storedSource.setToken(token)
+	}
+	return token, nil
+}
+
 // Authenticate validates an OAuth2 token from the request's Authorization header.
 func (p *OAuth2AuthProvider) Authenticate(r *http.Request) (*User, error) {
 	// Extract token from Authorization header
@@ -385,15 +437,31 @@ func (p *OAuth2AuthProvider) Authenticate(r *http.Request) (*User, error) {
 
 // ConfigureClient implements ClientProvider interface.
 func (p *OAuth2AuthProvider) ConfigureClient(client *http.Client) *http.Client {
+	// With a token cache, client credentials tokens come from the cached token source.
+	if p.clientCredentials != nil && p.tokenCache != nil {
+		// Fetch tokens and send requests through the caller's client.
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+		if cached, ok := p.tokenSource.(*cachedTokenSource); ok {
+			cached.base = p.retrying(p.clientCredentials.TokenSource(ctx))
+		}
+		httpClient := oauth2.NewClient(ctx, p.tokenSource)
+		httpClient.Timeout = client.Timeout
+		return httpClient
+	}
+
 	// If we have a client credentials config, create a client with that
 	if p.clientCredentials != nil {
-		return p.clientCredentials.Client(context.Background())
+		ctx := context.Background()
+		return oauth2.NewClient(ctx, p.retrying(p.clientCredentials.TokenSource(ctx)))
 	}
 
 	// If we have a token source already (from a previous auth), use that
 	if p.tokenSource != nil {
+		if p.storedSource != nil {
+			p.storedSource.setHTTPClient(client)
+		}
 		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
-		return oauth2.NewClient(ctx, p.tokenSource)
+		return oauth2.NewClient(ctx, p.retrying(p.tokenSource))
 	}
 
 	// If we have a config but no token yet, we can't configure a client
@@ -401,6 +469,47 @@ func (p *OAuth2AuthProvider) ConfigureClient(client *http.Client) *http.Client {
 	return client
 }
 
+// SetTokenCache makes the client credentials flow reuse tokens from cache, so clients
+// sharing the cache and credentials fetch a token only when the cached one has expired.
+// It has no effect for other flows. Call ConfigureClient afterwards.
+func (p *OAuth2AuthProvider) SetTokenCache(cache TokenCache) {
+	if p.clientCredentials == nil || cache == nil {
+		return
+	}
+	p.tokenCache = cache
+	p.tokenSource = &cachedTokenSource{
+		cache: cache,
+		key: clientCredentialsCacheKey(
+			p.clientCredentials.ClientID,
+			p.clientCredentials.ClientSecret,
+			p.clientCredentials.TokenURL,
+			p.clientCredentials.Scopes,
+		),
+		base: p.clientCredentials.TokenSource(context.Background()),
+	}
+}
+
+// SetTokenRetry makes token fetches try again, up to maxAttempts times in all, while
+// the token endpoint cannot be reached or answers with a 429 or 5xx status, waiting
+// backoff before the first retry and doubling the wait each time. Errors such as
+// invalid_client are returned at once. This is separate from the retries of the
+// requests sent with the token. Call ConfigureClient afterwards.
+func (p *OAuth2AuthProvider) SetTokenRetry(maxAttempts int, backoff time.Duration) {
+	if maxAttempts <= 1 {
+		p.tokenRetry = nil
+		return
+	}
+	p.tokenRetry = &tokenRetryPolicy{maxAttempts: maxAttempts, backoff: backoff}
+}
+
+// retrying returns source wrapped to follow the SetTokenRetry policy, if any.
+func (p *OAuth2AuthProvider) retrying(source oauth2.TokenSource) oauth2.TokenSource {
+	if p.tokenRetry == nil {
+		return source
+	}
+	return &retryingTokenSource{base: source, policy: *p.tokenRetry, sleep: time.Sleep}
+}
+
 // getUserFromUserInfo fetches user info from the userinfo endpoint
 func (p *OAuth2AuthProvider) getUserFromUserInfo(ctx context.Context, token *oauth2.Token) (*User, error) {
 	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
@@ -516,6 +625,7 @@ func (p *ChainAuthProvider) ConfigureClient(client *http.Client) *http.Client {
 // Middleware wraps an HTTP handler with authentication.
 type Middleware struct {
 	provider Provider
+	realm    string
 }
 
 // NewMiddleware creates a new authentication middleware.
@@ -525,11 +635,32 @@ func NewMiddleware(provider Provider) *Middleware {
 	}
 }
 
-// Wrap adds authentication to an HTTP handler.
+// SetRealm sets the realm of the WWW-Authenticate challenges sent with 401
+// responses, for challenges that do not name one themselves.
+func (m *Middleware) SetRealm(realm string) {
+	m.realm = realm
+}
+
+// Wrap adds authentication to an HTTP handler. Rejected requests get a 401
+// response whose WWW-Authenticate header lists the challenges of the provider,
+// if it implements Challenger.
 func (m *Middleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user, err := m.provider.Authenticate(r)
+		if errors.Is(err, ErrIntrospectionFailed) {
+			// The credential could not be checked, which is not the caller's fault.
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
 		if err != nil {
+			if challenger, ok := m.provider.(Challenger); ok {
+				for _, challenge := range challenger.Challenges(err) {
+					if challenge.Realm == "" {
+						challenge.Realm = m.realm
+					}
+					w.Header().Add(WWWAuthenticateHeader, challenge.String())
+				}
+			}
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
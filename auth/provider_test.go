@@ -7,9 +7,15 @@
 package auth_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -375,6 +381,355 @@ func TestOAuth2AuthProvider(t *testing.T) {
 	})
 }
 
+// newMockTokenServer creates a token endpoint that serves the authorization_code
+// and refresh_token grants, counting the refresh requests it receives.
+func newMockTokenServer(t *testing.T, refreshCount *int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		var resp map[string]interface{}
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			if r.Form.Get("code") != "auth-code" || r.Form.Get("code_verifier") == "" {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			resp = map[string]interface{}{
+				"access_token":  "exchanged-access-token",
+				"refresh_token": "exchanged-refresh-token",
+				"token_type":    "Bearer",
+				"expires_in":    3600,
+			}
+		case "refresh_token":
+			if r.Form.Get("refresh_token") != "valid-refresh-token" {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			*refreshCount++
+			resp = map[string]interface{}{
+				"access_token":  "refreshed-access-token",
+				"refresh_token": "rotated-refresh-token",
+				"token_type":    "Bearer",
+				"expires_in":    3600,
+			}
+		default:
+			http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestOAuth2AuthCodeProvider(t *testing.T) {
+	// Resource server that echoes the bearer token it received.
+	resourceServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get(auth.AuthHeaderName)))
+	}))
+	defer resourceServer.Close()
+
+	t.Run("RefreshExpiredToken", func(t *testing.T) {
+		var refreshCount int
+		tokenServer := newMockTokenServer(t, &refreshCount)
+		defer tokenServer.Close()
+		config := &oauth2.Config{
+			ClientID: "client-id",
+			Endpoint: oauth2.Endpoint{TokenURL: tokenServer.URL},
+		}
+		store := auth.NewMemoryTokenStore(&oauth2.Token{
+			AccessToken:  "expired-access-token",
+			RefreshToken: "valid-refresh-token",
+			Expiry:       time.Now().Add(-time.Hour),
+		})
+		provider := auth.NewOAuth2AuthCodeProvider(config, store)
+		client := provider.ConfigureClient(&http.Client{})
+
+		for i := 0; i < 2; i++ {
+			resp, err := client.Get(resourceServer.URL)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			require.NoError(t, err)
+			assert.Equal(t, "Bearer refreshed-access-token", string(body))
+		}
+		assert.Equal(t, 1, refreshCount, "token should be refreshed only once")
+
+		stored, err := store.LoadToken()
+		require.NoError(t, err)
+		assert.Equal(t, "refreshed-access-token", stored.AccessToken)
+		assert.Equal(t, "rotated-refresh-token", stored.RefreshToken)
+	})
+
+	t.Run("ValidTokenNotRefreshed", func(t *testing.T) {
+		var refreshCount int
+		tokenServer := newMockTokenServer(t, &refreshCount)
+		defer tokenServer.Close()
+		config := &oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: tokenServer.URL}}
+		store := auth.NewMemoryTokenStore(&oauth2.Token{
+			AccessToken:  "stored-access-token",
+			RefreshToken: "valid-refresh-token",
+			Expiry:       time.Now().Add(time.Hour),
+		})
+		client := auth.NewOAuth2AuthCodeProvider(config, store).ConfigureClient(&http.Client{})
+
+		resp, err := client.Get(resourceServer.URL)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "Bearer stored-access-token", string(body))
+		assert.Equal(t, 0, refreshCount)
+	})
+
+	t.Run("RefreshFailure", func(t *testing.T) {
+		var refreshCount int
+		tokenServer := newMockTokenServer(t, &refreshCount)
+		defer tokenServer.Close()
+		config := &oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: tokenServer.URL}}
+		store := auth.NewMemoryTokenStore(&oauth2.Token{
+			AccessToken:  "expired-access-token",
+			RefreshToken: "revoked-refresh-token",
+			Expiry:       time.Now().Add(-time.Hour),
+		})
+		client := auth.NewOAuth2AuthCodeProvider(config, store).ConfigureClient(&http.Client{})
+
+		_, err := client.Get(resourceServer.URL)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to refresh token")
+	})
+
+	t.Run("NoStoredToken", func(t *testing.T) {
+		config := &oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: "http://127.0.0.1:0/token"}}
+		client := auth.NewOAuth2AuthCodeProvider(config, auth.NewMemoryTokenStore(nil)).
+			ConfigureClient(&http.Client{})
+
+		_, err := client.Get(resourceServer.URL)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, auth.ErrNoStoredToken)
+	})
+
+	t.Run("ReloadsNewerStoredToken", func(t *testing.T) {
+		var refreshCount int
+		tokenServer := newMockTokenServer(t, &refreshCount)
+		defer tokenServer.Close()
+		config := &oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: tokenServer.URL}}
+		first := &oauth2.Token{
+			AccessToken:  "first-access-token",
+			RefreshToken: "stale-refresh-token",
+			Expiry:       time.Now().Add(time.Hour),
+		}
+		store := auth.NewMemoryTokenStore(first)
+		client := auth.NewOAuth2AuthCodeProvider(config, store).ConfigureClient(&http.Client{})
+
+		resp, err := client.Get(resourceServer.URL)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "Bearer first-access-token", string(body))
+
+		// The cached token expires while another provider saves a newer token.
+		first.Expiry = time.Now().Add(-time.Hour)
+		require.NoError(t, store.SaveToken(&oauth2.Token{
+			AccessToken:  "second-access-token",
+			RefreshToken: "valid-refresh-token",
+			Expiry:       time.Now().Add(time.Hour),
+		}))
+
+		resp, err = client.Get(resourceServer.URL)
+		require.NoError(t, err)
+		body, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "Bearer second-access-token", string(body))
+		assert.Equal(t, 0, refreshCount, "a newer stored token should be used without refreshing")
+	})
+
+	t.Run("RefreshUsesConfiguredClient", func(t *testing.T) {
+		var refreshCount int
+		tokenServer := newMockTokenServer(t, &refreshCount)
+		defer tokenServer.Close()
+		config := &oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: tokenServer.URL}}
+		store := auth.NewMemoryTokenStore(&oauth2.Token{
+			AccessToken:  "expired-access-token",
+			RefreshToken: "valid-refresh-token",
+			Expiry:       time.Now().Add(-time.Hour),
+		})
+		var tokenRequests int
+		transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if "http://"+r.URL.Host == tokenServer.URL {
+				tokenRequests++
+			}
+			return http.DefaultTransport.RoundTrip(r)
+		})
+		client := auth.NewOAuth2AuthCodeProvider(config, store).
+			ConfigureClient(&http.Client{Transport: transport})
+
+		resp, err := client.Get(resourceServer.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, 1, refreshCount)
+		assert.Equal(t, 1, tokenRequests, "refresh should go through the configured client")
+	})
+
+	t.Run("ExchangeAuthCodeWithPKCE", func(t *testing.T) {
+		var refreshCount int
+		tokenServer := newMockTokenServer(t, &refreshCount)
+		defer tokenServer.Close()
+		config := &oauth2.Config{
+			ClientID:    "client-id",
+			RedirectURL: "http://localhost/callback",
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  "https://auth.example.com/authorize",
+				TokenURL: tokenServer.URL,
+			},
+		}
+		store := auth.NewMemoryTokenStore(nil)
+		provider := auth.NewOAuth2AuthCodeProvider(config, store)
+
+		verifier := oauth2.GenerateVerifier()
+		authURL, err := provider.AuthCodeURL("state-1", verifier)
+		require.NoError(t, err)
+		parsed, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, oauth2.S256ChallengeFromVerifier(verifier), parsed.Query().Get("code_challenge"))
+		assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+
+		token, err := provider.ExchangeAuthCode(context.Background(), "auth-code", verifier)
+		require.NoError(t, err)
+		assert.Equal(t, "exchanged-access-token", token.AccessToken)
+
+		stored, err := store.LoadToken()
+		require.NoError(t, err)
+		assert.Equal(t, "exchanged-access-token", stored.AccessToken)
+
+		client := provider.ConfigureClient(&http.Client{})
+		resp, err := client.Get(resourceServer.URL)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "Bearer exchanged-access-token", string(body))
+	})
+}
+
+func TestOAuth2AuthProvider_TokenRetry(t *testing.T) {
+	// tokenServer answers the first failures token fetches with status and body. The
+	// oauth2 package repeats a failed fetch with the credentials in the form instead
+	// of the Authorization header, so only requests using the header are counted.
+	tokenServer := func(t *testing.T, failures int32, status int, body string) (*httptest.Server, *atomic.Int32) {
+		var hits atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fetch := hits.Load()
+			if _, _, ok := r.BasicAuth(); ok {
+				fetch = hits.Add(1)
+			}
+			if fetch <= failures {
+				w.WriteHeader(status)
+				io.WriteString(w, body)
+				return
+			}
+			io.WriteString(w, `{"access_token":"retried-token","token_type":"bearer","expires_in":3600}`)
+		}))
+		t.Cleanup(server.Close)
+		return server, &hits
+	}
+	resourceServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer resourceServer.Close()
+	get := func(client *http.Client) (string, error) {
+		resp, err := client.Get(resourceServer.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	t.Run("RetriesServerErrors", func(t *testing.T) {
+		server, hits := tokenServer(t, 2, http.StatusServiceUnavailable, `{"error":"temporarily_unavailable"}`)
+		provider := auth.NewOAuth2ClientCredentialsProvider("id", "secret", server.URL, nil)
+		provider.SetTokenRetry(3, time.Millisecond)
+		body, err := get(provider.ConfigureClient(&http.Client{}))
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer retried-token", body)
+		assert.Equal(t, int32(3), hits.Load())
+	})
+
+	t.Run("RetriesWithTokenCache", func(t *testing.T) {
+		server, hits := tokenServer(t, 2, http.StatusBadGateway, "")
+		provider := auth.NewOAuth2ClientCredentialsProvider("id", "secret", server.URL, nil)
+		provider.SetTokenCache(auth.NewMemoryTokenCache())
+		provider.SetTokenRetry(3, time.Millisecond)
+		body, err := get(provider.ConfigureClient(&http.Client{}))
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer retried-token", body)
+		assert.Equal(t, int32(3), hits.Load())
+	})
+
+	t.Run("StopsAfterMaxAttempts", func(t *testing.T) {
+		server, hits := tokenServer(t, 5, http.StatusServiceUnavailable, "")
+		provider := auth.NewOAuth2ClientCredentialsProvider("id", "secret", server.URL, nil)
+		provider.SetTokenRetry(3, time.Millisecond)
+		_, err := get(provider.ConfigureClient(&http.Client{}))
+		var retrieveErr *oauth2.RetrieveError
+		require.ErrorAs(t, err, &retrieveErr)
+		assert.Equal(t, http.StatusServiceUnavailable, retrieveErr.Response.StatusCode)
+		assert.Equal(t, int32(3), hits.Load())
+	})
+
+	t.Run("InvalidClientNotRetried", func(t *testing.T) {
+		server, hits := tokenServer(t, 5, http.StatusUnauthorized, `{"error":"invalid_client"}`)
+		provider := auth.NewOAuth2ClientCredentialsProvider("id", "secret", server.URL, nil)
+		provider.SetTokenRetry(3, time.Millisecond)
+		_, err := get(provider.ConfigureClient(&http.Client{}))
+		var retrieveErr *oauth2.RetrieveError
+		require.ErrorAs(t, err, &retrieveErr)
+		assert.Equal(t, "invalid_client", retrieveErr.ErrorCode)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("RetriesNetworkErrors", func(t *testing.T) {
+		var hits atomic.Int32
+		source := oauth2.TokenSource(tokenSourceFunc(func() (*oauth2.Token, error) {
+			if hits.Add(1) <= 2 {
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+			}
+			return &oauth2.Token{AccessToken: "retried-token", TokenType: "Bearer"}, nil
+		}))
+		provider := auth.NewOAuth2AuthProviderWithConfig(&oauth2.Config{}, "", "")
+		provider.SetTokenSource(source)
+		provider.SetTokenRetry(3, time.Millisecond)
+		body, err := get(provider.ConfigureClient(&http.Client{}))
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer retried-token", body)
+		assert.Equal(t, int32(3), hits.Load())
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		server, hits := tokenServer(t, 1, http.StatusServiceUnavailable, "")
+		provider := auth.NewOAuth2ClientCredentialsProvider("id", "secret", server.URL, nil)
+		_, err := get(provider.ConfigureClient(&http.Client{}))
+		require.Error(t, err)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+}
+
+// tokenSourceFunc adapts a function to oauth2.TokenSource.
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+// Token implements oauth2.TokenSource.
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
+
 func TestChainAuthProvider(t *testing.T) {
 	// Setup JWT provider
 	jwtProvider := auth.NewJWTAuthProvider(
@@ -515,5 +870,24 @@ func TestAuthMiddleware(t *testing.T) {
 		wrappedHandler.ServeHTTP(rr, req)
 
 		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, []string{"Bearer"}, rr.Header().Values(auth.WWWAuthenticateHeader))
+	})
+
+	t.Run("ChallengeRealm", func(t *testing.T) {
+		middleware := auth.NewMiddleware(provider)
+		middleware.SetRealm("Test Agent")
+		handler := middleware.Wrap(testHandler)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, `Bearer realm="Test Agent"`, rr.Header().Get(auth.WWWAuthenticateHeader))
+
+		req = httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set(auth.AuthHeaderName, "Bearer not-a-jwt")
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, `Bearer realm="Test Agent", error="invalid_token"`, rr.Header().Get(auth.WWWAuthenticateHeader))
 	})
 }
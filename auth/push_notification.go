@@ -16,7 +16,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -40,11 +42,15 @@ var (
 )
 
 // PushNotificationAuthenticator handles authentication for push notifications.
+// On the agent side it holds a set of signing keys so they can be rotated without
+// downtime: notifications are signed with the active key, and every key in the
+// set is published in the JWKS until it is retired. It is safe for concurrent use.
 type PushNotificationAuthenticator struct {
 	// For sending notifications (agent side).
-	privateKey *rsa.PrivateKey
-	keySet     jwk.Set
-	keyID      string
+	mu          sync.RWMutex
+	signingKeys map[string]*rsa.PrivateKey // Private keys by key ID.
+	keySet      jwk.Set                    // Public keys of signingKeys, served as the JWKS.
+	keyID       string                     // ID of the active signing key.
 
 	// For verifying notifications (client side).
 	jwksClient *JWKSClient
@@ -53,48 +59,120 @@ type PushNotificationAuthenticator struct {
 // NewPushNotificationAuthenticator creates a new push notification authenticator.
 func NewPushNotificationAuthenticator() *PushNotificationAuthenticator {
 	return &PushNotificationAuthenticator{
-		keySet: jwk.NewSet(),
+		signingKeys: make(map[string]*rsa.PrivateKey),
+		keySet:      jwk.NewSet(),
 	}
 }
 
-// GenerateKeyPair generates a new RSA key pair for signing push notifications.
+// GenerateKeyPair generates a new RSA key pair for signing push notifications
+// and makes it the active signing key.
 func (a *PushNotificationAuthenticator) GenerateKeyPair() error {
 	// Generate a new RSA key
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		return fmt.Errorf("failed to generate RSA key: %w", err)
 	}
+	return a.AddSigningKey(fmt.Sprintf("key-%d", time.Now().UnixNano()), privateKey)
+}
 
-	a.privateKey = privateKey
-	a.keyID = fmt.Sprintf("key-%d", time.Now().Unix())
-
+// AddSigningKey adds privateKey to the signing key set under keyID, publishes its
+// public key in the JWKS and makes it the active signing key. Keys added before
+// stay published, so notifications they signed still verify until they are retired.
+func (a *PushNotificationAuthenticator) AddSigningKey(keyID string, privateKey *rsa.PrivateKey) error {
+	if keyID == "" {
+		return errors.New("signing key ID cannot be empty")
+	}
+	if privateKey == nil {
+		return errors.New("signing key cannot be nil")
+	}
 	// Create a JWK from the private key
 	key, err := jwk.FromRaw(privateKey.Public())
 	if err != nil {
 		return fmt.Errorf("failed to create JWK from public key: %w", err)
 	}
-
 	// Set key ID
-	if err := key.Set(jwk.KeyIDKey, a.keyID); err != nil {
+	if err := key.Set(jwk.KeyIDKey, keyID); err != nil {
 		return fmt.Errorf("failed to set key ID: %w", err)
 	}
-
 	// Set key usage
 	if err := key.Set(jwk.KeyUsageKey, "sig"); err != nil {
 		return fmt.Errorf("failed to set key usage: %w", err)
 	}
-
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, exists := a.signingKeys[keyID]; exists {
+		return fmt.Errorf("signing key %s already exists", keyID)
+	}
 	// Add the key to the key set
-	a.keySet.AddKey(key)
+	if err := a.keySet.AddKey(key); err != nil {
+		return fmt.Errorf("failed to add key to key set: %w", err)
+	}
+	a.signingKeys[keyID] = privateKey
+	a.keyID = keyID
+	return nil
+}
 
+// RetireSigningKey removes a key from the signing key set and the JWKS, so
+// notifications it signed no longer verify. The active key cannot be retired;
+// add its replacement first.
+func (a *PushNotificationAuthenticator) RetireSigningKey(keyID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, exists := a.signingKeys[keyID]; !exists {
+		return fmt.Errorf("signing key %s not found", keyID)
+	}
+	if keyID == a.keyID {
+		return fmt.Errorf("signing key %s is active and cannot be retired", keyID)
+	}
+	if key, found := a.keySet.LookupKeyID(keyID); found {
+		if err := a.keySet.RemoveKey(key); err != nil {
+			return fmt.Errorf("failed to remove key from key set: %w", err)
+		}
+	}
+	delete(a.signingKeys, keyID)
 	return nil
 }
 
-// SignPayload signs a payload for push notification.
+// ActiveKeyID returns the ID of the key notifications are signed with,
+// or "" if there is none.
+func (a *PushNotificationAuthenticator) ActiveKeyID() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.keyID
+}
+
+// SigningKeyIDs returns the IDs of all keys in the signing key set, sorted.
+func (a *PushNotificationAuthenticator) SigningKeyIDs() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	ids := make([]string, 0, len(a.signingKeys))
+	for id := range a.signingKeys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SignPayload signs a payload for push notification with the active signing key.
 func (a *PushNotificationAuthenticator) SignPayload(payload []byte) (string, error) {
-	if a.privateKey == nil {
+	a.mu.RLock()
+	keyID := a.keyID
+	a.mu.RUnlock()
+	if keyID == "" {
 		return "", errors.New("private key not initialized")
 	}
+	return a.SignPayloadWithKey(keyID, payload)
+}
+
+// SignPayloadWithKey signs a payload for push notification with the signing key
+// keyID, which need not be the active one, e.g. while replicas roll over to a new key.
+func (a *PushNotificationAuthenticator) SignPayloadWithKey(keyID string, payload []byte) (string, error) {
+	a.mu.RLock()
+	privateKey, exists := a.signingKeys[keyID]
+	a.mu.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("signing key %s not found", keyID)
+	}
 	// Calculate SHA256 hash of payload.
 	hash := sha256.Sum256(payload)
 	payloadHash := fmt.Sprintf("%x", hash)
@@ -103,10 +181,10 @@ func (a *PushNotificationAuthenticator) SignPayload(payload []byte) (string, err
 		"iat":                 time.Now().Unix(),
 		"request_body_sha256": payloadHash,
 	})
-	// Set key ID in token header.
-	token.Header["kid"] = a.keyID
+	// Set key ID in token header, so verifiers can select the key.
+	token.Header["kid"] = keyID
 	// Sign the token.
-	tokenString, err := token.SignedString(a.privateKey)
+	tokenString, err := token.SignedString(privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -121,7 +199,9 @@ func (a *PushNotificationAuthenticator) HandleJWKS(w http.ResponseWriter, r *htt
 	}
 
 	// Marshal the entire key set to JSON
+	a.mu.RLock()
 	keySetJSON, err := json.Marshal(a.keySet)
+	a.mu.RUnlock()
 	if err != nil {
 		http.Error(w, "Failed to marshal key set", http.StatusInternalServerError)
 		return
@@ -145,12 +225,20 @@ func (a *PushNotificationAuthenticator) HandleJWKS(w http.ResponseWriter, r *htt
 	}
 }
 
+// defaultJWKSMinRefresh is the default shortest time between JWKS fetches
+// triggered by unknown key IDs.
+const defaultJWKSMinRefresh = 5 * time.Second
+
 // JWKSClient retrieves and caches JWKs from a remote endpoint.
+// It is safe for concurrent use.
 type JWKSClient struct {
-	jwksURL   string
+	jwksURL    string
+	cacheTTL   time.Duration
+	minRefresh time.Duration // Shortest time between fetches for unknown key IDs.
+
+	mu        sync.Mutex
 	keySet    jwk.Set
 	lastFetch time.Time
-	cacheTTL  time.Duration
 }
 
 // NewJWKSClient creates a new JWKS client for a specific URL.
@@ -159,18 +247,36 @@ func NewJWKSClient(jwksURL string, cacheTTL time.Duration) *JWKSClient {
 		cacheTTL = 1 * time.Hour
 	}
 	return &JWKSClient{
-		jwksURL:  jwksURL,
-		keySet:   jwk.NewSet(),
-		cacheTTL: cacheTTL,
+		jwksURL:    jwksURL,
+		keySet:     jwk.NewSet(),
+		cacheTTL:   cacheTTL,
+		minRefresh: defaultJWKSMinRefresh,
 	}
 }
 
+// SetMinRefreshInterval sets how soon after a fetch GetKey may fetch the keys
+// again to look up a key ID missing from the cache, as happens right after the
+// signer rotates keys. It keeps tokens with made-up key IDs from flooding the
+// endpoint. Default is 5 seconds.
+func (c *JWKSClient) SetMinRefreshInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.minRefresh = interval
+}
+
 // FetchKeys fetches the JWKs from the remote endpoint.
 func (c *JWKSClient) FetchKeys(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	// Check if we need to refresh the keys.
 	if !c.lastFetch.IsZero() && time.Since(c.lastFetch) < c.cacheTTL {
 		return nil
 	}
+	return c.fetchKeysLocked(ctx)
+}
+
+// fetchKeysLocked fetches the JWKs unconditionally. The caller must hold c.mu.
+func (c *JWKSClient) fetchKeysLocked(ctx context.Context) error {
 	// Fetch the JWKs from the remote endpoint.
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.jwksURL, nil)
 	if err != nil {
@@ -209,12 +315,22 @@ func (c *JWKSClient) FetchKeys(ctx context.Context) error {
 	return nil
 }
 
-// GetKey returns a key with the specified ID.
+// GetKey returns a key with the specified ID. A key ID missing from the cached
+// keys triggers a refetch, rate limited by SetMinRefreshInterval, so keys added
+// by a rotation are found before the cache expires.
 func (c *JWKSClient) GetKey(ctx context.Context, keyID string) (jwk.Key, error) {
 	if err := c.FetchKeys(ctx); err != nil {
 		return nil, err
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	key, found := c.keySet.LookupKeyID(keyID)
+	if !found && time.Since(c.lastFetch) >= c.minRefresh {
+		if err := c.fetchKeysLocked(ctx); err != nil {
+			return nil, err
+		}
+		key, found = c.keySet.LookupKeyID(keyID)
+	}
 	if !found {
 		return nil, fmt.Errorf("key with ID %s not found", keyID)
 	}
@@ -292,6 +408,11 @@ func (a *PushNotificationAuthenticator) SetJWKSClient(jwksURL string) {
 	a.jwksClient = NewJWKSClient(jwksURL, 1*time.Hour)
 }
 
+// UseJWKSClient sets a configured JWKS client for verifying push notifications.
+func (a *PushNotificationAuthenticator) UseJWKSClient(client *JWKSClient) {
+	a.jwksClient = client
+}
+
 // CreateAuthorizationHeader creates an Authorization header for a push notification.
 func (a *PushNotificationAuthenticator) CreateAuthorizationHeader(payload []byte) (string, error) {
 	token, err := a.SignPayload(payload)
@@ -8,6 +8,8 @@ package auth_test
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -253,3 +255,78 @@ func TestEndToEndPushNotificationAuth(t *testing.T) {
 		assert.Contains(t, err.Error(), "payload hash mismatch", "Error should indicate payload hash mismatch")
 	})
 }
+
+func TestPushNotifAuth_KeyRotation(t *testing.T) {
+	serverAuth := auth.NewPushNotificationAuthenticator()
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	require.NoError(t, serverAuth.AddSigningKey("old", oldKey))
+
+	jwksServer := httptest.NewServer(http.HandlerFunc(serverAuth.HandleJWKS))
+	defer jwksServer.Close()
+	jwksClient := auth.NewJWKSClient(jwksServer.URL, time.Hour)
+	jwksClient.SetMinRefreshInterval(0)
+	clientAuth := auth.NewPushNotificationAuthenticator()
+	clientAuth.UseJWKSClient(jwksClient)
+
+	payload := []byte(`{"message":"rotation"}`)
+	verify := func(t *testing.T, token string) error {
+		req := httptest.NewRequest(http.MethodPost, "/notification", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return clientAuth.VerifyPushNotification(req, payload)
+	}
+
+	// The verifier caches the JWKS while only the old key is published.
+	token, err := serverAuth.SignPayload(payload)
+	require.NoError(t, err)
+	require.NoError(t, verify(t, token))
+
+	t.Run("BothKeysVerifyDuringRotation", func(t *testing.T) {
+		require.NoError(t, serverAuth.AddSigningKey("new", newKey))
+		assert.Equal(t, "new", serverAuth.ActiveKeyID())
+		assert.Equal(t, []string{"new", "old"}, serverAuth.SigningKeyIDs())
+
+		newToken, err := serverAuth.SignPayload(payload)
+		require.NoError(t, err)
+		assert.NoError(t, verify(t, newToken), "an unknown key ID should refresh the cached keys")
+
+		oldToken, err := serverAuth.SignPayloadWithKey("old", payload)
+		require.NoError(t, err)
+		assert.NoError(t, verify(t, oldToken), "the old key should verify until it is retired")
+	})
+
+	t.Run("RetiredKeyNoLongerVerifies", func(t *testing.T) {
+		assert.Error(t, serverAuth.RetireSigningKey("new"), "the active key cannot be retired")
+		require.NoError(t, serverAuth.RetireSigningKey("old"))
+		assert.Equal(t, []string{"new"}, serverAuth.SigningKeyIDs())
+		_, err := serverAuth.SignPayloadWithKey("old", payload)
+		assert.Error(t, err)
+
+		// A fresh verifier only sees the keys still published.
+		freshAuth := auth.NewPushNotificationAuthenticator()
+		freshAuth.SetJWKSClient(jwksServer.URL)
+		oldToken, err := signWithKey(oldKey, "old", payload)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/notification", nil)
+		req.Header.Set("Authorization", "Bearer "+oldToken)
+		assert.ErrorContains(t, freshAuth.VerifyPushNotification(req, payload), "key with ID old not found")
+	})
+
+	t.Run("InvalidKeys", func(t *testing.T) {
+		assert.Error(t, serverAuth.AddSigningKey("", newKey))
+		assert.Error(t, serverAuth.AddSigningKey("other", nil))
+		assert.Error(t, serverAuth.AddSigningKey("new", newKey), "key IDs must be unique")
+		assert.Error(t, serverAuth.RetireSigningKey("missing"))
+	})
+}
+
+// signWithKey signs payload the way the authenticator does, with a key it may not hold.
+func signWithKey(key *rsa.PrivateKey, keyID string, payload []byte) (string, error) {
+	signer := auth.NewPushNotificationAuthenticator()
+	if err := signer.AddSigningKey(keyID, key); err != nil {
+		return "", err
+	}
+	return signer.SignPayload(payload)
+}
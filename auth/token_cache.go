@@ -0,0 +1,134 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenCache shares OAuth2 tokens between clients, e.g. across short-lived client
+// instances using the same credentials. Implementations must be safe for concurrent use.
+type TokenCache interface {
+	// GetToken returns the token cached under key, if any.
+	GetToken(key string) (*oauth2.Token, bool)
+	// PutToken caches token under key, replacing any previous token.
+	PutToken(key string, token *oauth2.Token)
+}
+
+// MemoryTokenCache is a TokenCache that keeps tokens in memory.
+type MemoryTokenCache struct {
+	mu     sync.RWMutex
+	tokens map[string]*oauth2.Token
+}
+
+// NewMemoryTokenCache creates a new, empty in-memory token cache.
+func NewMemoryTokenCache() *MemoryTokenCache {
+	return &MemoryTokenCache{tokens: make(map[string]*oauth2.Token)}
+}
+
+// GetToken implements TokenCache.
+func (c *MemoryTokenCache) GetToken(key string) (*oauth2.Token, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	token, ok := c.tokens[key]
+	return token, ok
+}
+
+// PutToken implements TokenCache.
+func (c *MemoryTokenCache) PutToken(key string, token *oauth2.Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[key] = token
+}
+
+// clientCredentialsCacheKey derives the cache key for a client credentials grant.
+// The secret is hashed in so clients with different credentials never share a token.
+func clientCredentialsCacheKey(clientID, clientSecret, tokenURL string, scopes []string) string {
+	sorted := append([]string(nil), scopes...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(
+		[]string{clientID, clientSecret, tokenURL, strings.Join(sorted, " ")}, "\x00",
+	)))
+	return "client_credentials:" + hex.EncodeToString(sum[:])
+}
+
+// tokenFetches serializes token fetches per cache key across all cached token
+// sources, so clients sharing a cache fetch a missing token only once.
+var tokenFetches = &keyedMutex{locks: make(map[string]*keyedLock)}
+
+// keyedMutex hands out one mutex per key and drops it once nobody holds or waits for it.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is a mutex shared by everyone locking the same key.
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int // Holders and waiters; guarded by keyedMutex.mu.
+}
+
+// lock locks key and returns the function that unlocks it.
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		k.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// cachedTokenSource is an oauth2.TokenSource that consults a TokenCache before
+// fetching a new token from base, and stores fetched tokens in the cache.
+type cachedTokenSource struct {
+	cache TokenCache
+	key   string
+	base  oauth2.TokenSource
+}
+
+// Token implements oauth2.TokenSource.
+// Each call returns its own copy, since oauth2 mutates the tokens it is handed.
+func (s *cachedTokenSource) Token() (*oauth2.Token, error) {
+	if token, ok := s.cache.GetToken(s.key); ok && token.Valid() {
+		return copyToken(token), nil
+	}
+	unlock := tokenFetches.lock(s.key)
+	defer unlock()
+	// Another source sharing the cache may have fetched the token while we waited.
+	if token, ok := s.cache.GetToken(s.key); ok && token.Valid() {
+		return copyToken(token), nil
+	}
+	token, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.cache.PutToken(s.key, copyToken(token))
+	return token, nil
+}
+
+// copyToken returns a shallow copy of token.
+func copyToken(token *oauth2.Token) *oauth2.Token {
+	clone := *token
+	return &clone
+}
@@ -0,0 +1,58 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package auth
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// tokenRetryPolicy holds the settings of SetTokenRetry.
+type tokenRetryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// retryingTokenSource fetches tokens from base, trying again while the token
+// endpoint fails transiently.
+type retryingTokenSource struct {
+	base   oauth2.TokenSource
+	policy tokenRetryPolicy
+	sleep  func(time.Duration)
+}
+
+// Token implements oauth2.TokenSource.
+func (s *retryingTokenSource) Token() (*oauth2.Token, error) {
+	for attempt := 1; ; attempt++ {
+		token, err := s.base.Token()
+		if err == nil || attempt >= s.policy.maxAttempts || !retryableTokenError(err) {
+			return token, err
+		}
+		s.sleep(s.policy.backoff << (attempt - 1))
+	}
+}
+
+// retryableTokenError reports whether a token fetch that failed with err may
+// succeed if tried again: the token endpoint could not be reached, or it answered
+// with a 429 or 5xx status. Errors the endpoint reports for the request itself,
+// such as invalid_client or invalid_grant, are not retryable.
+func retryableTokenError(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		if retrieveErr.Response == nil {
+			return false
+		}
+		status := retrieveErr.Response.StatusCode
+		return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,121 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrNoStoredToken is returned when a TokenStore has no token to use.
+var ErrNoStoredToken = errors.New("no OAuth2 token found in token store")
+
+// TokenStore persists OAuth2 tokens for the authorization code flow.
+// Implementations must be safe for concurrent use.
+type TokenStore interface {
+	// LoadToken returns the stored token, or nil if none has been stored yet.
+	LoadToken() (*oauth2.Token, error)
+	// SaveToken persists the given token, replacing any previously stored token.
+	SaveToken(token *oauth2.Token) error
+}
+
+// MemoryTokenStore is a TokenStore that keeps the token in memory.
+type MemoryTokenStore struct {
+	mu    sync.RWMutex
+	token *oauth2.Token
+}
+
+// NewMemoryTokenStore creates a new in-memory token store holding the given token, which may be nil.
+func NewMemoryTokenStore(token *oauth2.Token) *MemoryTokenStore {
+	return &MemoryTokenStore{token: token}
+}
+
+// LoadToken implements TokenStore.
+func (s *MemoryTokenStore) LoadToken() (*oauth2.Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token, nil
+}
+
+// SaveToken implements TokenStore.
+func (s *MemoryTokenStore) SaveToken(token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+	return nil
+}
+
+// storedTokenSource is an oauth2.TokenSource backed by a TokenStore.
+// It refreshes expired tokens using the refresh token and saves the result back to the store.
+type storedTokenSource struct {
+	config *oauth2.Config
+	store  TokenStore
+
+	mu         sync.Mutex
+	token      *oauth2.Token
+	httpClient *http.Client // Client used for refresh requests; nil means http.DefaultClient.
+}
+
+// Token implements oauth2.TokenSource.
+func (s *storedTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	// Another provider sharing the store may have saved a newer token, so reload
+	// before refreshing with a refresh token that may have been rotated.
+	token, err := s.store.LoadToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token: %w", err)
+	}
+	if token != nil {
+		s.token = token
+	}
+	if s.token == nil {
+		return nil, ErrNoStoredToken
+	}
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	if s.token.RefreshToken == "" {
+		return nil, ErrTokenExpired
+	}
+	ctx := context.Background()
+	if s.httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, s.httpClient)
+	}
+	// The oauth2 refresh token source handles the refresh_token grant.
+	refreshed, err := s.config.TokenSource(ctx, s.token).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	if err := s.store.SaveToken(refreshed); err != nil {
+		return nil, fmt.Errorf("failed to save refreshed token: %w", err)
+	}
+	s.token = refreshed
+	return refreshed, nil
+}
+
+// setToken replaces the cached token, e.g. after a new authorization code exchange.
+func (s *storedTokenSource) setToken(token *oauth2.Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// setHTTPClient sets the client used for refresh requests.
+func (s *storedTokenSource) setHTTPClient(client *http.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.httpClient = client
+}
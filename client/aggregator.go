@@ -0,0 +1,135 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/textchunk"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// TextAggregator accumulates the text of streamed artifact chunks per artifact index.
+// It honors the Append flag and buffers multi-byte UTF-8 runes split across chunks,
+// so the text it returns is always valid UTF-8, even when decoded incrementally.
+// Servers built with this package already align streamed text chunks on rune
+// boundaries; the buffering also covers events built or relayed in process.
+// It is safe for concurrent use.
+type TextAggregator struct {
+	mu        sync.Mutex
+	artifacts map[int]*textBuffer
+}
+
+// textBuffer holds the aggregated text of a single artifact.
+type textBuffer struct {
+	text    strings.Builder
+	pending string // Trailing bytes of an incomplete rune.
+}
+
+// NewTextAggregator creates a new, empty TextAggregator.
+func NewTextAggregator() *TextAggregator {
+	return &TextAggregator{artifacts: make(map[int]*textBuffer)}
+}
+
+// Add aggregates the text parts of an artifact event and returns the newly completed
+// text, which is always valid UTF-8. Bytes of a rune that is not yet complete are held
+// back until the next chunk. A chunk without Append set starts the artifact over.
+// On the last chunk any dangling incomplete rune is replaced with U+FFFD.
+func (a *TextAggregator) Add(event protocol.TaskArtifactUpdateEvent) string {
+	artifact := event.Artifact
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	buf, ok := a.artifacts[artifact.Index]
+	if !ok || artifact.Append == nil || !*artifact.Append {
+		buf = &textBuffer{}
+		a.artifacts[artifact.Index] = buf
+	}
+	data := buf.pending
+	for _, part := range artifact.Parts {
+		if textPart, ok := part.(protocol.TextPart); ok {
+			data += textPart.Text
+		}
+	}
+	complete, pending := textchunk.SplitIncompleteRune(data)
+	lastChunk := artifact.LastChunk != nil && *artifact.LastChunk
+	if lastChunk && pending != "" {
+		complete += pending
+		pending = ""
+	}
+	buf.pending = pending
+	delta := strings.ToValidUTF8(complete, string(utf8.RuneError))
+	buf.text.WriteString(delta)
+	return delta
+}
+
+// Text returns the valid UTF-8 text aggregated so far for the artifact index.
+func (a *TextAggregator) Text(index int) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if buf, ok := a.artifacts[index]; ok {
+		return buf.text.String()
+	}
+	return ""
+}
+
+// ArtifactAggregator rebuilds streamed artifacts from artifact update, patch and
+// delete events, keeping the latest state of each artifact by index. An update
+// without Append set starts the artifact over, replacing any earlier version, one
+// with Append set adds its parts, a TaskArtifactPatchEvent applies its JSON Patch
+// to a DataPart of the artifact, and a TaskArtifactDeleteEvent drops the artifact.
+// It is safe for concurrent use.
+type ArtifactAggregator struct {
+	mu        sync.Mutex
+	artifacts map[int]protocol.Artifact
+}
+
+// NewArtifactAggregator creates a new, empty ArtifactAggregator.
+func NewArtifactAggregator() *ArtifactAggregator {
+	return &ArtifactAggregator{artifacts: make(map[int]protocol.Artifact)}
+}
+
+// Add applies an artifact update, patch or delete event; other events are ignored.
+// A patch that is invalid, or targets an unknown artifact or a part that is not a
+// DataPart, is rejected with a protocol.ErrInvalidPatch error and changes nothing.
+// Deleting an unknown artifact is not an error: the client may have missed it, for
+// example by subscribing late, and the outcome is the same.
+func (a *ArtifactAggregator) Add(event protocol.TaskEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch e := event.(type) {
+	case protocol.TaskArtifactUpdateEvent:
+		artifact := e.Artifact
+		previous, ok := a.artifacts[artifact.Index]
+		if ok && artifact.Append != nil && *artifact.Append {
+			artifact.Parts = append(append([]protocol.Part(nil), previous.Parts...), artifact.Parts...)
+		}
+		a.artifacts[artifact.Index] = artifact
+	case protocol.TaskArtifactPatchEvent:
+		artifact, ok := a.artifacts[e.Index]
+		if !ok {
+			return fmt.Errorf("%w: no artifact with index %d", protocol.ErrInvalidPatch, e.Index)
+		}
+		if err := artifact.ApplyPatch(e.Part, e.Patch); err != nil {
+			return err
+		}
+		a.artifacts[e.Index] = artifact
+	case protocol.TaskArtifactDeleteEvent:
+		delete(a.artifacts, e.Index)
+	}
+	return nil
+}
+
+// Artifact returns the aggregated artifact with the given index, if any.
+func (a *ArtifactAggregator) Artifact(index int) (protocol.Artifact, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	artifact, ok := a.artifacts[index]
+	return artifact, ok
+}
@@ -0,0 +1,163 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// textChunk builds an artifact event carrying the given raw text bytes.
+func textChunk(index int, text string, appendChunk, lastChunk bool) protocol.TaskArtifactUpdateEvent {
+	return protocol.TaskArtifactUpdateEvent{
+		ID: "aggregator-task",
+		Artifact: protocol.Artifact{
+			Index:     index,
+			Parts:     []protocol.Part{protocol.NewTextPart(text)},
+			Append:    &appendChunk,
+			LastChunk: &lastChunk,
+		},
+	}
+}
+
+func TestTextAggregator(t *testing.T) {
+	emoji := "😀" // 4 bytes: F0 9F 98 80.
+
+	t.Run("EmojiSplitAcrossChunks", func(t *testing.T) {
+		agg := NewTextAggregator()
+		full := "hi " + emoji + "!"
+		first, second := full[:5], full[5:] // Split inside the emoji.
+
+		delta := agg.Add(textChunk(0, first, false, false))
+		assert.Equal(t, "hi ", delta, "incomplete rune must be held back")
+		assert.True(t, utf8.ValidString(delta))
+		assert.Equal(t, "hi ", agg.Text(0))
+
+		delta = agg.Add(textChunk(0, second, true, true))
+		assert.Equal(t, emoji+"!", delta)
+		assert.Equal(t, full, agg.Text(0))
+	})
+
+	t.Run("EmojiSplitByteByByte", func(t *testing.T) {
+		agg := NewTextAggregator()
+		for i := 0; i < len(emoji); i++ {
+			delta := agg.Add(textChunk(0, emoji[i:i+1], i > 0, i == len(emoji)-1))
+			assert.True(t, utf8.ValidString(delta))
+			if i < len(emoji)-1 {
+				assert.Empty(t, delta)
+			}
+		}
+		assert.Equal(t, emoji, agg.Text(0))
+	})
+
+	t.Run("DanglingRuneOnLastChunk", func(t *testing.T) {
+		agg := NewTextAggregator()
+		agg.Add(textChunk(0, "ok"+emoji[:2], false, false))
+		delta := agg.Add(textChunk(0, "", true, true))
+		assert.Equal(t, string(utf8.RuneError), delta)
+		assert.Equal(t, "ok"+string(utf8.RuneError), agg.Text(0))
+	})
+
+	t.Run("NonAppendResetsArtifact", func(t *testing.T) {
+		agg := NewTextAggregator()
+		agg.Add(textChunk(0, "draft", false, false))
+		agg.Add(textChunk(1, "other", false, true))
+		agg.Add(textChunk(0, "final", false, true))
+		assert.Equal(t, "final", agg.Text(0))
+		assert.Equal(t, "other", agg.Text(1))
+		assert.Empty(t, agg.Text(2))
+	})
+}
+
+func TestArtifactAggregator(t *testing.T) {
+	dataArtifact := func(appendChunk bool, parts ...protocol.Part) protocol.TaskArtifactUpdateEvent {
+		return protocol.TaskArtifactUpdateEvent{
+			ID:       "aggregator-task",
+			Artifact: protocol.Artifact{Index: 0, Parts: parts, Append: &appendChunk},
+		}
+	}
+	patchEvent := func(part int, ops ...protocol.PatchOperation) protocol.TaskArtifactPatchEvent {
+		return protocol.TaskArtifactPatchEvent{ID: "aggregator-task", Index: 0, Part: part, Patch: ops}
+	}
+	board := protocol.DataPart{
+		Type: protocol.PartTypeData,
+		Data: map[string]interface{}{"status": "running", "steps": []interface{}{"plan"}, "eta": 30},
+	}
+
+	t.Run("AddReplaceRemove", func(t *testing.T) {
+		agg := NewArtifactAggregator()
+		require.NoError(t, agg.Add(dataArtifact(false, protocol.NewTextPart("progress"), board)))
+		require.NoError(t, agg.Add(patchEvent(1,
+			protocol.PatchOperation{Op: protocol.PatchOpAdd, Path: "/steps/-", Value: "build"},
+			protocol.PatchOperation{Op: protocol.PatchOpReplace, Path: "/status", Value: "done"},
+		)))
+		require.NoError(t, agg.Add(patchEvent(1, protocol.PatchOperation{Op: protocol.PatchOpRemove, Path: "/eta"})))
+
+		artifact, ok := agg.Artifact(0)
+		require.True(t, ok)
+		require.Len(t, artifact.Parts, 2)
+		assert.Equal(t, "progress", artifact.Parts[0].(protocol.TextPart).Text)
+		assert.Equal(t, map[string]interface{}{
+			"status": "done",
+			"steps":  []interface{}{"plan", "build"},
+		}, artifact.Parts[1].(protocol.DataPart).Data)
+	})
+
+	t.Run("InvalidPatchChangesNothing", func(t *testing.T) {
+		agg := NewArtifactAggregator()
+		require.NoError(t, agg.Add(dataArtifact(false, board)))
+		before, _ := agg.Artifact(0)
+
+		err := agg.Add(patchEvent(0,
+			protocol.PatchOperation{Op: protocol.PatchOpReplace, Path: "/status", Value: "done"},
+			protocol.PatchOperation{Op: protocol.PatchOpRemove, Path: "/missing"},
+		))
+		assert.ErrorIs(t, err, protocol.ErrInvalidPatch)
+		err = agg.Add(patchEvent(0, protocol.PatchOperation{Op: "merge", Path: "/status"}))
+		assert.ErrorIs(t, err, protocol.ErrInvalidPatch)
+
+		after, _ := agg.Artifact(0)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("UnknownTargets", func(t *testing.T) {
+		agg := NewArtifactAggregator()
+		assert.ErrorIs(t, agg.Add(patchEvent(0)), protocol.ErrInvalidPatch, "no artifact yet")
+		require.NoError(t, agg.Add(dataArtifact(false, protocol.NewTextPart("text"))))
+		assert.ErrorIs(t, agg.Add(patchEvent(0)), protocol.ErrInvalidPatch, "part is not a DataPart")
+		assert.ErrorIs(t, agg.Add(patchEvent(3)), protocol.ErrInvalidPatch, "part out of range")
+	})
+
+	t.Run("AppendAddsParts", func(t *testing.T) {
+		agg := NewArtifactAggregator()
+		require.NoError(t, agg.Add(dataArtifact(false, protocol.NewTextPart("a"))))
+		require.NoError(t, agg.Add(dataArtifact(true, board)))
+		require.NoError(t, agg.Add(patchEvent(1, protocol.PatchOperation{Op: protocol.PatchOpRemove, Path: "/steps"})))
+		artifact, _ := agg.Artifact(0)
+		require.Len(t, artifact.Parts, 2)
+		assert.NotContains(t, artifact.Parts[1].(protocol.DataPart).Data, "steps")
+
+		require.NoError(t, agg.Add(dataArtifact(false, protocol.NewTextPart("b"))))
+		artifact, _ = agg.Artifact(0)
+		assert.Len(t, artifact.Parts, 1, "an update without append starts over")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		agg := NewArtifactAggregator()
+		require.NoError(t, agg.Add(dataArtifact(false, board)))
+		require.NoError(t, agg.Add(protocol.TaskArtifactDeleteEvent{ID: "aggregator-task", Index: 0}))
+		_, ok := agg.Artifact(0)
+		assert.False(t, ok)
+		// A delete for an index the client never saw is ignored.
+		assert.NoError(t, agg.Add(protocol.TaskArtifactDeleteEvent{ID: "aggregator-task", Index: 7}))
+	})
+}
@@ -0,0 +1,281 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// Default passive health check settings of a balanced client.
+const (
+	defaultEjectFailures = 3
+	defaultEjectTime     = 30 * time.Second
+)
+
+// maxTaskAffinity is the number of tasks a balanced client remembers the endpoint
+// of. The oldest are forgotten first.
+const maxTaskAffinity = 10000
+
+// WeightedEndpoint is an endpoint of an agent served by several replicas, see
+// NewBalancedClient.
+type WeightedEndpoint struct {
+	// URL is the base URL of the replica, as passed to NewA2AClient.
+	URL string
+	// Weight is the share of calls sent to the replica relative to the others.
+	// Zero or less counts as 1.
+	Weight int
+}
+
+// NewBalancedClient creates a client for an agent run by several replicas. Each
+// call is sent to one of the endpoints, picked by smooth weighted round-robin, and
+// a stream stays on the endpoint it was opened on for its lifetime. The endpoint
+// a task is created on, by tasks/send, tasks/sendSubscribe or tasks/import, is
+// remembered: later calls for the task, including resubscribes and retries, go to
+// the same endpoint while it is in rotation, so replicas need not share a task
+// store. Calls for tasks the client did not create, and batch calls, travel the
+// rotation; for those the replicas must share their task store. Endpoints that
+// fail a number of calls in a row, with a transport error or a 5xx status, are
+// ejected for a while, see WithEndpointEjection; while every endpoint is ejected,
+// calls are spread over all of them. With WithRetry, a retried call not bound to
+// a task is sent to the next endpoint picked. The options are those of
+// NewA2AClient and apply to every endpoint.
+func NewBalancedClient(endpoints []WeightedEndpoint, opts ...Option) (*A2AClient, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("NewBalancedClient requires at least one endpoint")
+	}
+	b := &balancer{
+		maxFailures: defaultEjectFailures,
+		ejectTime:   defaultEjectTime,
+		now:         time.Now,
+		tasks:       make(map[string]*balancedEndpoint),
+	}
+	for _, endpoint := range endpoints {
+		parsedURL, err := parseAgentURL(endpoint.URL)
+		if err != nil {
+			return nil, err
+		}
+		weight := endpoint.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		b.endpoints = append(b.endpoints, &balancedEndpoint{url: parsedURL, weight: weight})
+	}
+	opts = append(opts[:len(opts):len(opts)], func(c *A2AClient) {
+		if c.ejection != nil {
+			b.maxFailures = c.ejection.failures
+			b.ejectTime = c.ejection.duration
+		}
+		c.balancer = b
+	})
+	return NewA2AClient(endpoints[0].URL, opts...)
+}
+
+// ejectionPolicy holds the settings of WithEndpointEjection.
+type ejectionPolicy struct {
+	failures int
+	duration time.Duration
+}
+
+// balancedEndpoint is an endpoint of a balancer.
+type balancedEndpoint struct {
+	url          *url.URL
+	weight       int
+	current      int       // Smooth weighted round-robin state.
+	failures     int       // Calls failed in a row.
+	ejectedUntil time.Time // Zero while the endpoint is in rotation.
+}
+
+// balancer picks the endpoint of each call of a balanced client.
+type balancer struct {
+	maxFailures int
+	ejectTime   time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	endpoints []*balancedEndpoint
+	tasks     map[string]*balancedEndpoint // Endpoint each task was created on.
+	taskOrder []string                     // Keys of tasks, oldest first.
+}
+
+// pick returns the URL of the endpoint the next call is sent to.
+func (b *balancer) pick() *url.URL {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pickLocked().url
+}
+
+// route returns the URL of the endpoint a call for taskID is sent to: the one the
+// task was created on unless it is ejected, or else the next one picked. A call
+// that creates the task records the endpoint picked for it.
+func (b *balancer) route(taskID string, create bool) *url.URL {
+	if taskID == "" {
+		return b.pick()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if endpoint, ok := b.tasks[taskID]; ok && !b.now().Before(endpoint.ejectedUntil) {
+		return endpoint.url
+	}
+	endpoint := b.pickLocked()
+	if create {
+		if _, ok := b.tasks[taskID]; !ok {
+			if len(b.taskOrder) >= maxTaskAffinity {
+				delete(b.tasks, b.taskOrder[0])
+				b.taskOrder = b.taskOrder[1:]
+			}
+			b.taskOrder = append(b.taskOrder, taskID)
+		}
+		b.tasks[taskID] = endpoint
+	}
+	return endpoint.url
+}
+
+// pickLocked implements pick. b.mu must be held.
+func (b *balancer) pickLocked() *balancedEndpoint {
+	now := b.now()
+	candidates := make([]*balancedEndpoint, 0, len(b.endpoints))
+	for _, endpoint := range b.endpoints {
+		if !now.Before(endpoint.ejectedUntil) {
+			candidates = append(candidates, endpoint)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = b.endpoints
+	}
+	var best *balancedEndpoint
+	total := 0
+	for _, endpoint := range candidates {
+		endpoint.current += endpoint.weight
+		total += endpoint.weight
+		if best == nil || endpoint.current > best.current {
+			best = endpoint
+		}
+	}
+	best.current -= total
+	return best
+}
+
+// report records the outcome of req, ejecting the endpoint it was sent to once it
+// failed maxFailures times in a row. Requests whose context ended are not counted.
+func (b *balancer) report(req *http.Request, resp *http.Response, err error) {
+	if b == nil || req.Context().Err() != nil {
+		return
+	}
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	endpoint := b.lookup(req.URL)
+	if endpoint == nil {
+		return
+	}
+	if !failed {
+		endpoint.failures = 0
+		return
+	}
+	endpoint.failures++
+	if endpoint.failures >= b.maxFailures {
+		endpoint.failures = 0
+		endpoint.ejectedUntil = b.now().Add(b.ejectTime)
+		log.Warnf("A2A Client: ejecting endpoint %s for %s after %d failed calls",
+			endpoint.url, b.ejectTime, b.maxFailures)
+	}
+}
+
+// lookup returns the endpoint target was sent to: the one on its host with the
+// longest base path target starts with. b.mu must be held.
+func (b *balancer) lookup(target *url.URL) *balancedEndpoint {
+	var found *balancedEndpoint
+	for _, endpoint := range b.endpoints {
+		if endpoint.url.Host != target.Host || !strings.HasPrefix(target.Path, endpoint.url.Path) {
+			continue
+		}
+		if found == nil || len(endpoint.url.Path) > len(found.url.Path) {
+			found = endpoint
+		}
+	}
+	return found
+}
+
+// endpoint returns the base URL the next call is sent to.
+func (c *A2AClient) endpoint() *url.URL {
+	if c.balancer == nil {
+		return c.baseURL
+	}
+	return c.balancer.pick()
+}
+
+// endpointFor returns the base URL a call of method with params is sent to, the
+// endpoint of its task if known, see balancer.route.
+func (c *A2AClient) endpointFor(method string, params json.RawMessage) *url.URL {
+	if c.balancer == nil {
+		return c.baseURL
+	}
+	return c.balancer.route(taskIDOf(params), createsTask(method))
+}
+
+// taskIDOf returns the ID of the task params refer to, or "" if none.
+func taskIDOf(params json.RawMessage) string {
+	var scope struct {
+		ID       string `json:"id"`
+		Snapshot *struct {
+			Task struct {
+				ID string `json:"id"`
+			} `json:"task"`
+		} `json:"snapshot"`
+	}
+	if len(params) == 0 || json.Unmarshal(params, &scope) != nil {
+		return ""
+	}
+	if scope.ID == "" && scope.Snapshot != nil {
+		return scope.Snapshot.Task.ID
+	}
+	return scope.ID
+}
+
+// createsTask reports whether method creates the task its params refer to.
+func createsTask(method string) bool {
+	switch method {
+	case protocol.MethodTasksSend, protocol.MethodTasksSendSubscribe, protocol.MethodTasksImport:
+		return true
+	default:
+		return false
+	}
+}
+
+// do sends req with the HTTP client, reporting the outcome to the balancer, if any.
+// A request safe to repeat follows the redirects the redirect policy allows.
+func (c *A2AClient) do(req *http.Request, safe bool) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	c.balancer.report(req, resp, err)
+	if err != nil {
+		return resp, err
+	}
+	return c.followRedirects(req, resp, safe)
+}
+
+// parseAgentURL parses the base URL of an agent, adding the trailing slash that
+// correct path joining needs.
+func parseAgentURL(agentURL string) (*url.URL, error) {
+	if !strings.HasSuffix(agentURL, "/") {
+		agentURL += "/"
+	}
+	parsedURL, err := url.ParseRequestURI(agentURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent URL %q: %w", agentURL, err)
+	}
+	return parsedURL, nil
+}
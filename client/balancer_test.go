@@ -0,0 +1,168 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// replicaAgent is an agent replica with a task store of its own: it knows the
+// tasks sent to it and answers tasks/get and tasks/cancel for others with a task
+// not found error.
+func replicaAgent(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	tasks := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var params protocol.TaskIDParams
+		require.NoError(t, json.Unmarshal(req.Params, &params))
+		mu.Lock()
+		if req.Method == protocol.MethodTasksSend {
+			tasks[params.ID] = true
+		}
+		known := tasks[params.ID]
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if !known {
+			json.NewEncoder(w).Encode(jsonrpc.NewErrorResponse(req.ID, &jsonrpc.Error{
+				Code: -32001, Message: "Task not found",
+			}))
+			return
+		}
+		json.NewEncoder(w).Encode(jsonrpc.Response{
+			Message: jsonrpc.Message{JSONRPC: "2.0", ID: req.ID},
+			Result:  protocol.Task{ID: params.ID, Status: protocol.TaskStatus{State: protocol.TaskStateWorking}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewBalancedClient(t *testing.T) {
+	ctx := context.Background()
+	query := protocol.TaskQueryParams{ID: "retry-task"}
+
+	t.Run("DistributesByWeight", func(t *testing.T) {
+		light, lightHits := failingAgent(t, 0, http.StatusOK)
+		medium, mediumHits := failingAgent(t, 0, http.StatusOK)
+		heavy, heavyHits := failingAgent(t, 0, http.StatusOK)
+		client, err := NewBalancedClient([]WeightedEndpoint{
+			{URL: light.URL, Weight: 1},
+			{URL: medium.URL, Weight: 2},
+			{URL: heavy.URL, Weight: 3},
+		})
+		require.NoError(t, err)
+		for i := 0; i < 60; i++ {
+			_, err := client.GetTasks(ctx, query)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(10), lightHits.Load())
+		assert.Equal(t, int64(20), mediumHits.Load())
+		assert.Equal(t, int64(30), heavyHits.Load())
+	})
+
+	t.Run("EjectsFailingEndpoint", func(t *testing.T) {
+		good, goodHits := failingAgent(t, 0, http.StatusOK)
+		bad, badHits := failingAgent(t, 1<<30, http.StatusServiceUnavailable)
+		client, err := NewBalancedClient(
+			[]WeightedEndpoint{{URL: good.URL}, {URL: bad.URL}},
+			WithEndpointEjection(2, time.Minute),
+		)
+		require.NoError(t, err)
+		now := time.Unix(1000, 0)
+		client.balancer.now = func() time.Time { return now }
+		for i := 0; i < 10; i++ {
+			_, _ = client.GetTasks(ctx, query)
+		}
+		assert.Equal(t, int64(2), badHits.Load())
+		assert.Equal(t, int64(8), goodHits.Load())
+
+		// Once the ejection is over the endpoint is tried again.
+		now = now.Add(time.Minute)
+		for i := 0; i < 2; i++ {
+			_, _ = client.GetTasks(ctx, query)
+		}
+		assert.Equal(t, int64(3), badHits.Load())
+	})
+
+	t.Run("AllEjectedStillServe", func(t *testing.T) {
+		bad, badHits := failingAgent(t, 1<<30, http.StatusBadGateway)
+		client, err := NewBalancedClient([]WeightedEndpoint{{URL: bad.URL}},
+			WithEndpointEjection(1, time.Minute))
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err := client.GetTasks(ctx, query)
+			assert.Error(t, err)
+		}
+		assert.Equal(t, int64(3), badHits.Load())
+	})
+
+	t.Run("RetryMovesToNextEndpoint", func(t *testing.T) {
+		bad, _ := failingAgent(t, 1<<30, http.StatusServiceUnavailable)
+		good, _ := failingAgent(t, 0, http.StatusOK)
+		client, err := NewBalancedClient(
+			[]WeightedEndpoint{{URL: bad.URL}, {URL: good.URL}},
+			WithRetry(2, time.Millisecond),
+		)
+		require.NoError(t, err)
+		for i := 0; i < 4; i++ {
+			_, err := client.GetTasks(ctx, query)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("TasksStayOnTheirEndpoint", func(t *testing.T) {
+		first, second := replicaAgent(t), replicaAgent(t)
+		client, err := NewBalancedClient(
+			[]WeightedEndpoint{{URL: first.URL}, {URL: second.URL}},
+			WithRetry(2, time.Millisecond),
+		)
+		require.NoError(t, err)
+		for i := 0; i < 4; i++ {
+			taskID := fmt.Sprintf("affinity-%d", i)
+			_, err := client.SendTasks(ctx, protocol.SendTaskParams{
+				ID:      taskID,
+				Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+			})
+			require.NoError(t, err)
+		}
+		// Each call for a task reaches the replica that has it.
+		for i := 0; i < 4; i++ {
+			taskID := fmt.Sprintf("affinity-%d", i)
+			for j := 0; j < 3; j++ {
+				_, err := client.GetTasks(ctx, protocol.TaskQueryParams{ID: taskID})
+				require.NoError(t, err, "get %s", taskID)
+			}
+			_, err := client.CancelTasks(ctx, protocol.TaskIDParams{ID: taskID})
+			require.NoError(t, err, "cancel %s", taskID)
+		}
+		// Tasks created elsewhere still travel the rotation.
+		_, err = client.GetTasks(ctx, protocol.TaskQueryParams{ID: "unknown"})
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidEndpoints", func(t *testing.T) {
+		_, err := NewBalancedClient(nil)
+		require.Error(t, err)
+		_, err = NewBalancedClient([]WeightedEndpoint{{URL: "not a url"}})
+		require.Error(t, err)
+	})
+}
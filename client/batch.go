@@ -0,0 +1,60 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// BatchResult is the outcome of one task sent with SendTasksBatch.
+type BatchResult struct {
+	// Task is the initial state of the task, nil if it was rejected.
+	Task *protocol.Task
+	// Err is why the agent rejected the task, as SendTasks would return it.
+	Err error
+}
+
+// SendTasksBatch sends several tasks in one request using the tasks/sendBatch
+// method. The agent handles each task on its own, so some may be rejected, for
+// example for invalid params, while the others are created. The results are in
+// the order of tasks. The error is set only if the batch as a whole failed, in
+// which case no task was sent. At most protocol.MaxSendBatchSize tasks may be sent.
+func (c *A2AClient) SendTasksBatch(ctx context.Context, tasks []protocol.SendTaskParams) ([]BatchResult, error) {
+	params := protocol.SendTaskBatchParams{Tasks: make([]protocol.SendTaskParams, len(tasks))}
+	for i, task := range tasks {
+		if err := c.signSendParams(&task); err != nil {
+			return nil, fmt.Errorf("a2aClient.SendTasksBatch: task %d: %w", i, err)
+		}
+		params.Tasks[i] = task
+	}
+	var entries []struct {
+		Task  *protocol.Task `json:"task"`
+		Error *jsonrpc.Error `json:"error"`
+	}
+	if err := c.call(ctx, protocol.MethodTasksSendBatch, params, &entries); err != nil {
+		return nil, fmt.Errorf("a2aClient.SendTasksBatch: %w", err)
+	}
+	if len(entries) != len(tasks) {
+		return nil, fmt.Errorf("a2aClient.SendTasksBatch: got %d results for %d tasks", len(entries), len(tasks))
+	}
+	results := make([]BatchResult, len(entries))
+	for i, entry := range entries {
+		switch {
+		case entry.Error != nil:
+			results[i].Err = entry.Error
+		case entry.Task != nil:
+			results[i].Task = entry.Task
+		default:
+			results[i].Err = fmt.Errorf("no result for task %s", tasks[i].ID)
+		}
+	}
+	return results, nil
+}
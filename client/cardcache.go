@@ -0,0 +1,136 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+)
+
+// agentCardCache keeps the last agent card fetched, following the Cache-Control
+// max-age and stale-while-revalidate directives of its response.
+type agentCardCache struct {
+	now func() time.Time // Replaced by tests.
+
+	mu           sync.Mutex
+	entry        *cachedAgentCard
+	revalidating bool
+}
+
+// cachedAgentCard is a verified agent card response body.
+type cachedAgentCard struct {
+	body        []byte
+	contentType string
+	freshUntil  time.Time     // Served as is until then.
+	staleUntil  time.Time     // Served while revalidating until then.
+	staleWindow time.Duration // The stale-while-revalidate duration.
+}
+
+// newAgentCardCache creates an empty agentCardCache.
+func newAgentCardCache() *agentCardCache {
+	return &agentCardCache{now: time.Now}
+}
+
+// lookup returns the cached card if it may be served, and whether the caller
+// should revalidate it in the background. Only one caller at a time is asked to
+// revalidate.
+func (c *agentCardCache) lookup() (entry *cachedAgentCard, revalidate bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entry == nil {
+		return nil, false
+	}
+	now := c.now()
+	if now.Before(c.entry.freshUntil) {
+		return c.entry, false
+	}
+	if !now.Before(c.entry.staleUntil) {
+		return nil, false
+	}
+	if c.revalidating {
+		return c.entry, false
+	}
+	c.revalidating = true
+	return c.entry, true
+}
+
+// store caches body according to cacheControl. Responses without a max-age, or
+// marked no-store or no-cache, replace nothing and are not cached.
+func (c *agentCardCache) store(body []byte, contentType, cacheControl string) {
+	maxAge, staleWindow, ok := parseCacheControl(cacheControl)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.revalidating = false
+	if !ok {
+		c.entry = nil
+		return
+	}
+	now := c.now()
+	c.entry = &cachedAgentCard{
+		body:        body,
+		contentType: contentType,
+		freshUntil:  now.Add(maxAge),
+		staleUntil:  now.Add(maxAge + staleWindow),
+		staleWindow: staleWindow,
+	}
+}
+
+// revalidationFailed keeps serving the stale card for another stale window, so a
+// briefly unreachable agent does not fail every call.
+func (c *agentCardCache) revalidationFailed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.revalidating = false
+	if c.entry != nil {
+		c.entry.staleUntil = c.now().Add(c.entry.staleWindow)
+	}
+}
+
+// parseCacheControl returns the max-age and stale-while-revalidate durations of
+// a Cache-Control header, and whether the response may be cached at all.
+func parseCacheControl(header string) (maxAge, staleWindow time.Duration, ok bool) {
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0, 0, false
+		case "max-age":
+			if err != nil || seconds <= 0 {
+				return 0, 0, false
+			}
+			maxAge, ok = time.Duration(seconds)*time.Second, true
+		case "stale-while-revalidate":
+			if err == nil && seconds > 0 {
+				staleWindow = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return maxAge, staleWindow, ok
+}
+
+// revalidateAgentCard refetches the agent card for the cache in the background.
+func (c *A2AClient) revalidateAgentCard(ctx context.Context) {
+	go func() {
+		resp, err := c.fetchAgentCard(ctx)
+		if err == nil {
+			var probe interface{}
+			err = c.decodeAgentCard(resp.body, resp.contentType, &probe)
+		}
+		if err != nil {
+			log.Warnf("Failed to revalidate cached agent card, serving it for longer: %v", err)
+			c.cardCache.revalidationFailed()
+			return
+		}
+		c.cardCache.store(resp.body, resp.contentType, resp.cacheControl)
+	}()
+}
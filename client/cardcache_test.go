@@ -0,0 +1,179 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// cardServer serves an agent card whose version counts the fetches.
+type cardServer struct {
+	*httptest.Server
+	cacheControl string
+	fetches      atomic.Int32
+	failing      atomic.Bool
+}
+
+func newCardServer(t *testing.T, cacheControl string) *cardServer {
+	s := &cardServer{cacheControl: cacheControl}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := s.fetches.Add(1)
+		if s.failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if s.cacheControl != "" {
+			w.Header().Set("Cache-Control", s.cacheControl)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"name":"cached","version":"%d"}`, n)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestA2AClient_AgentCardCache(t *testing.T) {
+	type card struct {
+		Version string `json:"version"`
+	}
+	newClient := func(t *testing.T, s *cardServer) (*A2AClient, *fakeClock) {
+		client, err := NewA2AClient(s.URL, WithAgentCardCache())
+		require.NoError(t, err)
+		clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+		client.cardCache.now = clock.Now
+		return client, clock
+	}
+	version := func(t *testing.T, client *A2AClient) string {
+		var c card
+		require.NoError(t, client.GetAgentCard(context.Background(), &c))
+		return c.Version
+	}
+
+	t.Run("FreshStaleExpired", func(t *testing.T) {
+		s := newCardServer(t, "max-age=60, stale-while-revalidate=30")
+		client, clock := newClient(t, s)
+
+		assert.Equal(t, "1", version(t, client))
+		clock.Advance(59 * time.Second)
+		assert.Equal(t, "1", version(t, client), "fresh cards are served from the cache")
+		assert.EqualValues(t, 1, s.fetches.Load())
+
+		// Stale but usable: the old card is served at once and refreshed in the background.
+		clock.Advance(10 * time.Second)
+		assert.Equal(t, "1", version(t, client))
+		require.Eventually(t, func() bool { return version(t, client) == "2" }, time.Second, 5*time.Millisecond)
+		assert.EqualValues(t, 2, s.fetches.Load(), "one background revalidation")
+
+		// Expired: past max-age and the stale window, the card is fetched before returning.
+		clock.Advance(91 * time.Second)
+		assert.Equal(t, "3", version(t, client))
+		assert.EqualValues(t, 3, s.fetches.Load())
+	})
+
+	t.Run("FailedRevalidationExtendsStaleWindow", func(t *testing.T) {
+		s := newCardServer(t, "max-age=60, stale-while-revalidate=30")
+		client, clock := newClient(t, s)
+		assert.Equal(t, "1", version(t, client))
+
+		s.failing.Store(true)
+		clock.Advance(80 * time.Second)
+		assert.Equal(t, "1", version(t, client))
+		require.Eventually(t, func() bool {
+			client.cardCache.mu.Lock()
+			defer client.cardCache.mu.Unlock()
+			return s.fetches.Load() == 2 && !client.cardCache.revalidating
+		}, time.Second, 5*time.Millisecond)
+
+		// The original stale window ended at 90s; the failure at 80s extended it to 110s.
+		clock.Advance(25 * time.Second)
+		assert.Equal(t, "1", version(t, client))
+		require.Eventually(t, func() bool {
+			client.cardCache.mu.Lock()
+			defer client.cardCache.mu.Unlock()
+			return s.fetches.Load() == 3 && !client.cardCache.revalidating
+		}, time.Second, 5*time.Millisecond)
+
+		// Once no revalidation succeeded for a whole stale window, the fetch error is returned.
+		clock.Advance(31 * time.Second)
+		var c card
+		assert.Error(t, client.GetAgentCard(context.Background(), &c))
+
+		s.failing.Store(false)
+		assert.Equal(t, "5", version(t, client))
+	})
+
+	t.Run("NotCacheable", func(t *testing.T) {
+		for _, cacheControl := range []string{"", "no-store", "max-age=60, no-cache", "max-age=0"} {
+			s := newCardServer(t, cacheControl)
+			client, _ := newClient(t, s)
+			version(t, client)
+			version(t, client)
+			assert.EqualValues(t, 2, s.fetches.Load(), "Cache-Control: %q", cacheControl)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		s := newCardServer(t, "max-age=60")
+		client, err := NewA2AClient(s.URL)
+		require.NoError(t, err)
+		version(t, client)
+		version(t, client)
+		assert.EqualValues(t, 2, s.fetches.Load())
+	})
+}
+
+func TestParseCacheControl(t *testing.T) {
+	tests := []struct {
+		header        string
+		maxAge, stale time.Duration
+		ok            bool
+	}{
+		{"max-age=60", time.Minute, 0, true},
+		{"public, max-age=60, stale-while-revalidate=30", time.Minute, 30 * time.Second, true},
+		{`Max-Age="10"`, 10 * time.Second, 0, true},
+		{"stale-while-revalidate=30", 0, 30 * time.Second, false},
+		{"max-age=abc", 0, 0, false},
+		{"max-age=60, no-store", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tc := range tests {
+		maxAge, stale, ok := parseCacheControl(tc.header)
+		assert.Equal(t, tc.ok, ok, tc.header)
+		if tc.ok {
+			assert.Equal(t, tc.maxAge, maxAge, tc.header)
+			assert.Equal(t, tc.stale, stale, tc.header)
+		}
+	}
+}
@@ -17,10 +17,13 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"trpc.group/trpc-go/trpc-a2a-go/auth"
 	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/redact"
 	"trpc.group/trpc-go/trpc-a2a-go/internal/sse"
 	"trpc.group/trpc-go/trpc-a2a-go/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
@@ -31,13 +34,81 @@ const (
 	defaultUserAgent = "trpc-a2a-go-client/0.1"
 )
 
+// Artifact verification errors.
+var (
+	// ErrArtifactChecksumMissing is returned when an artifact carries no checksum to verify against.
+	ErrArtifactChecksumMissing = errors.New("artifact has no checksum")
+	// ErrArtifactChecksumMismatch is returned when downloaded content does not match the artifact checksum.
+	ErrArtifactChecksumMismatch = errors.New("artifact checksum mismatch")
+)
+
+// ErrPreflightUnauthorized is returned by NewA2AClient with WithPreflight when the
+// agent rejects the client's credentials.
+var ErrPreflightUnauthorized = errors.New("agent rejected the client credentials")
+
+// UnauthorizedError is returned, wrapped, when the agent rejects a request with
+// 401 Unauthorized. Challenges lists what the agent accepts instead, parsed from
+// the WWW-Authenticate header of the response.
+type UnauthorizedError struct {
+	Challenges []auth.Challenge
+}
+
+func (e *UnauthorizedError) Error() string {
+	if len(e.Challenges) == 0 {
+		return "unauthorized"
+	}
+	accepted := make([]string, len(e.Challenges))
+	for i, challenge := range e.Challenges {
+		accepted[i] = challenge.String()
+	}
+	return "unauthorized, agent accepts: " + strings.Join(accepted, "; ")
+}
+
+// unauthorizedError returns the UnauthorizedError of a 401 response with header.
+func unauthorizedError(header http.Header) *UnauthorizedError {
+	return &UnauthorizedError{Challenges: auth.ParseChallenges(header.Values(auth.WWWAuthenticateHeader)...)}
+}
+
+// preflightProbeTaskID is the task looked up to check that the agent accepts the
+// client's credentials. The lookup is read-only; not finding the task is expected.
+const preflightProbeTaskID = "a2a-preflight-probe"
+
+// ErrTaskNotModified is returned by GetTaskIfChanged when the task is unchanged.
+var ErrTaskNotModified = errors.New("task not modified")
+
+// ErrStreamIdleTimeout is reported when no data arrives on an SSE stream within
+// the configured idle timeout.
+var ErrStreamIdleTimeout = errors.New("sse stream idle timeout")
+
 // A2AClient provides methods to interact with an A2A agent server.
 // It handles making HTTP requests and encoding/decoding JSON-RPC messages.
 type A2AClient struct {
-	baseURL      *url.URL            // Parsed base URL of the agent server.
-	httpClient   *http.Client        // Underlying HTTP client.
-	userAgent    string              // User-Agent header string.
-	authProvider auth.ClientProvider // Authentication provider.
+	baseURL           *url.URL            // Parsed base URL of the agent server.
+	httpClient        *http.Client        // Underlying HTTP client.
+	userAgent         string              // User-Agent header string.
+	authProvider      auth.ClientProvider // Authentication provider.
+	streamIdleTimeout time.Duration       // Max time between data on an SSE stream (0 disables).
+	nextRequestID     atomic.Uint64       // Counter for generated JSON-RPC request IDs.
+	streamFallback    time.Duration       // Poll interval when falling back from streaming (0 disables).
+	agentCardKey      interface{}         // Public key for verifying signed agent cards (nil disables).
+	messageKey        interface{}         // Private key for signing sent messages (nil disables).
+	tokenCache        auth.TokenCache     // Shared OAuth2 token cache (nil disables).
+	tokenRetry        *retryPolicy        // Retries of failed OAuth2 token fetches (nil disables).
+	authBaseClient    *http.Client        // HTTP client before the OAuth2 provider wrapped it.
+	streamingCheck    sync.Once           // Guards the one-time agent card capability lookup.
+	streamingDisabled bool                // Agent card reports no streaming support.
+	preflight         bool                // Check the agent card and credentials in NewA2AClient.
+	limiter           *ConcurrencyLimiter // Caps requests in flight (nil for no cap).
+	cardCache         *agentCardCache     // Cached agent card (nil disables).
+	timeoutSet        bool                // The HTTP timeout was set explicitly.
+	cardTimeout       bool                // Derive the HTTP timeout from the agent card.
+	retry             *retryPolicy        // Retries of failed calls (nil disables).
+	retryBudget       *retryBudget        // Caps retries across calls (nil for no cap).
+	balancer          *balancer           // Spreads calls over endpoints (nil for baseURL only).
+	ejection          *ejectionPolicy     // Passive health check settings of the balancer.
+	logBodies         bool                // Log JSON-RPC request and response bodies at debug level.
+	redirect          RedirectPolicy      // Redirects followed by calls.
+	plainClient       *http.Client        // HTTP client before the auth options wrapped it (nil if none did).
 }
 
 // NewA2AClient creates a new A2A client targeting the specified agentURL.
@@ -46,12 +117,9 @@ type A2AClient struct {
 // http.Client or timeout.
 // Returns an error if the agentURL is invalid.
 func NewA2AClient(agentURL string, opts ...Option) (*A2AClient, error) {
-	if !strings.HasSuffix(agentURL, "/") {
-		agentURL += "/" // Ensure base URL ends with a slash for correct path joining.
-	}
-	parsedURL, err := url.ParseRequestURI(agentURL)
+	parsedURL, err := parseAgentURL(agentURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid agent URL %q: %w", agentURL, err)
+		return nil, err
 	}
 	client := &A2AClient{
 		baseURL: parsedURL,
@@ -64,23 +132,124 @@ func NewA2AClient(agentURL string, opts ...Option) (*A2AClient, error) {
 	for _, opt := range opts {
 		opt(client)
 	}
+	// Apply the token cache and retries once all options are set, so option order does
+	// not matter.
+	provider, ok := client.authProvider.(*auth.OAuth2AuthProvider)
+	if ok && (client.tokenCache != nil || client.tokenRetry != nil) {
+		if client.tokenCache != nil {
+			provider.SetTokenCache(client.tokenCache)
+		}
+		if client.tokenRetry != nil {
+			provider.SetTokenRetry(client.tokenRetry.maxAttempts, client.tokenRetry.backoff)
+		}
+		client.httpClient = provider.ConfigureClient(client.authBaseClient)
+	}
+	// Redirects are followed by do, as the redirect policy allows.
+	client.httpClient = withoutRedirects(client.httpClient)
+	if client.cardTimeout && !client.timeoutSet {
+		client.applyCardTimeout(context.Background())
+	}
+	if client.preflight {
+		if err := client.checkPreflight(context.Background()); err != nil {
+			return nil, fmt.Errorf("preflight for agent %q failed: %w", agentURL, err)
+		}
+	}
 	return client, nil
 }
 
+// applyCardTimeout sets the HTTP timeout from the processing time hint of the
+// agent card, keeping the current one if there is no usable hint.
+func (c *A2AClient) applyCardTimeout(ctx context.Context) {
+	var card struct {
+		Extensions map[string]json.RawMessage `json:"extensions"`
+	}
+	if err := c.GetAgentCard(ctx, &card); err != nil {
+		log.Debugf("Could not fetch agent card to derive the timeout: %v", err)
+		return
+	}
+	data, ok := card.Extensions[protocol.ExtensionProcessingTime]
+	if !ok {
+		return
+	}
+	var hint protocol.ProcessingTimeHint
+	if err := json.Unmarshal(data, &hint); err != nil {
+		log.Warnf("Ignoring invalid processing time hint of agent %s: %v", c.baseURL, err)
+		return
+	}
+	timeout := hint.Timeout()
+	if timeout <= 0 {
+		return
+	}
+	httpClient := *c.httpClient
+	httpClient.Timeout = timeout
+	c.httpClient = &httpClient
+}
+
+// checkPreflight fetches the agent card and makes an authenticated read-only call,
+// so connectivity and credential problems surface before the first real call.
+func (c *A2AClient) checkPreflight(ctx context.Context) error {
+	var card struct {
+		Capabilities struct {
+			Streaming *bool `json:"streaming"`
+		} `json:"capabilities"`
+	}
+	if err := c.GetAgentCard(ctx, &card); err != nil {
+		return err
+	}
+	// Remember the capability so StreamTask does not fetch the card again.
+	c.streamingCheck.Do(func() {
+		c.streamingDisabled = card.Capabilities.Streaming != nil && !*card.Capabilities.Streaming
+	})
+	// Any JSON-RPC answer, including task not found, means the request got past
+	// authentication.
+	request := jsonrpc.NewRequest(protocol.MethodTasksGet, preflightProbeTaskID)
+	params, err := json.Marshal(protocol.TaskQueryParams{ID: preflightProbeTaskID})
+	if err != nil {
+		return fmt.Errorf("a2aClient.checkPreflight: failed to marshal params: %w", err)
+	}
+	request.Params = params
+	_, err = c.doRequest(ctx, request, nil)
+	var statusErr *HTTPError
+	if errors.As(err, &statusErr) {
+		if statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("a2aClient.checkPreflight: %w: %v", ErrPreflightUnauthorized, err)
+		}
+		if statusErr.rpcErr != nil {
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("a2aClient.checkPreflight: %w", err)
+	}
+	return nil
+}
+
 // SendTasks sends a message using the tasks/send method.
 // It returns the initial task state received from the agent.
 func (c *A2AClient) SendTasks(
 	ctx context.Context,
 	params protocol.SendTaskParams,
+	opts ...SendOption,
 ) (*protocol.Task, error) {
-	request := jsonrpc.NewRequest(protocol.MethodTasksSend, params.ID)
+	sendOpts := &sendOptions{}
+	for _, opt := range opts {
+		opt(sendOpts)
+	}
+	if err := c.signSendParams(&params); err != nil {
+		return nil, fmt.Errorf("a2aClient.SendTasks: %w", err)
+	}
+	requestID := params.ID
+	if sendOpts.requestID != "" {
+		requestID = sendOpts.requestID
+	}
+	request := jsonrpc.NewRequest(protocol.MethodTasksSend, requestID)
 	paramsBytes, err := json.Marshal(params)
 	if err != nil {
 		return nil, fmt.Errorf("a2aClient.SendTasks: failed to marshal params: %w", err)
 	}
 	request.Params = paramsBytes
 	// Execute the request and decode the result field directly into task.
-	task, err := c.doRequestAndDecodeTask(ctx, request)
+	task, err := c.doRequestAndDecodeTask(ctx, request, sendOpts)
 	if err != nil {
 		// Return error, potentially wrapping a *jsonrpc.JSONRPCError.
 		return nil, fmt.Errorf("a2aClient.SendTasks: %w", err)
@@ -99,13 +268,65 @@ func (c *A2AClient) GetTasks(
 		return nil, fmt.Errorf("a2aClient.GetTasks: failed to marshal params: %w", err)
 	}
 	request.Params = paramsBytes
-	task, err := c.doRequestAndDecodeTask(ctx, request)
+	task, err := c.doRequestAndDecodeTask(ctx, request, nil)
 	if err != nil {
 		return nil, fmt.Errorf("a2aClient.GetTasks: %w", err)
 	}
 	return task, nil
 }
 
+// GetTaskIfChanged fetches a task only if it changed since the fetch that returned
+// the ETag since, and returns the task with its new ETag. The agent replies with
+// 304 Not Modified instead of the task when nothing changed, in which case it
+// returns ErrTaskNotModified and since. An empty since fetches unconditionally.
+// The task is fetched without its history or lifecycle events.
+func (c *A2AClient) GetTaskIfChanged(
+	ctx context.Context,
+	taskID string,
+	since string,
+) (*protocol.Task, string, error) {
+	request := jsonrpc.NewRequest(protocol.MethodTasksGet, taskID)
+	paramsBytes, err := json.Marshal(protocol.TaskQueryParams{ID: taskID})
+	if err != nil {
+		return nil, "", fmt.Errorf("a2aClient.GetTaskIfChanged: failed to marshal params: %w", err)
+	}
+	request.Params = paramsBytes
+	var etag string
+	task, err := c.doRequestAndDecodeTask(ctx, request, &sendOptions{ifNoneMatch: since, etag: &etag})
+	if errors.Is(err, ErrTaskNotModified) {
+		return nil, since, ErrTaskNotModified
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("a2aClient.GetTaskIfChanged: %w", err)
+	}
+	return task, etag, nil
+}
+
+// WaitForTaskChange long-polls the agent for the task: the agent holds the request
+// until the task's status changes or timeout elapses, then returns the current task,
+// changed or not. It returns at once for a task already in a final state.
+// Agents may wait less than timeout, and agents without long-poll support answer
+// immediately. It serves clients whose proxies break SSE streams; timeout should be
+// below the HTTP client timeout.
+func (c *A2AClient) WaitForTaskChange(
+	ctx context.Context,
+	taskID string,
+	timeout time.Duration,
+) (*protocol.Task, error) {
+	params := protocol.TaskQueryParams{ID: taskID, WaitMs: int(timeout.Milliseconds())}
+	request := jsonrpc.NewRequest(protocol.MethodTasksGet, taskID)
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.WaitForTaskChange: failed to marshal params: %w", err)
+	}
+	request.Params = paramsBytes
+	task, err := c.doRequestAndDecodeTask(ctx, request, nil)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.WaitForTaskChange: %w", err)
+	}
+	return task, nil
+}
+
 // CancelTasks cancels an in-progress task using the tasks/cancel method.
 // It returns the task state immediately after the cancellation request.
 func (c *A2AClient) CancelTasks(
@@ -118,33 +339,322 @@ func (c *A2AClient) CancelTasks(
 		return nil, fmt.Errorf("a2aClient.CancelTasks: failed to marshal params: %w", err)
 	}
 	request.Params = paramsBytes
-	task, err := c.doRequestAndDecodeTask(ctx, request)
+	task, err := c.doRequestAndDecodeTask(ctx, request, nil)
 	if err != nil {
 		return nil, fmt.Errorf("a2aClient.CancelTasks: %w", err)
 	}
 	return task, nil
 }
 
+// Call invokes an arbitrary JSON-RPC method on the agent, for methods the typed API
+// does not cover. params is sent as the request params (nil omits them) and the
+// response result is unmarshaled into result (nil discards it). A JSON-RPC error
+// response is returned as an error.
+func (c *A2AClient) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if err := c.call(ctx, method, params, result); err != nil {
+		return fmt.Errorf("a2aClient.Call: %w", err)
+	}
+	return nil
+}
+
+// GetCapabilities asks the agent what it supports with the a2a/capabilities method,
+// a cheaper alternative to fetching its card. Agents of other implementations may
+// answer with a method not found error.
+func (c *A2AClient) GetCapabilities(ctx context.Context) (*protocol.Capabilities, error) {
+	var caps protocol.Capabilities
+	if err := c.call(ctx, protocol.MethodCapabilities, nil, &caps); err != nil {
+		return nil, fmt.Errorf("a2aClient.GetCapabilities: %w", err)
+	}
+	return &caps, nil
+}
+
+// call implements Call, returning its errors unwrapped.
+func (c *A2AClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	request := jsonrpc.NewRequest(method, c.nextRequestID.Add(1))
+	if params != nil {
+		paramsBytes, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal params: %w", err)
+		}
+		request.Params = paramsBytes
+	}
+	fullResponse, err := c.doRequest(ctx, request, nil)
+	if err != nil {
+		return err
+	}
+	if fullResponse.Error != nil {
+		return fullResponse.Error
+	}
+	if result == nil || len(fullResponse.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(fullResponse.Result, result); err != nil {
+		return fmt.Errorf("failed to unmarshal rpc result: %w", err)
+	}
+	return nil
+}
+
+// GetAgentCard fetches the agent card from the well-known path on the agent's host
+// and unmarshals it into card (typically a *server.AgentCard). Cards of later spec
+// versions are converted to that layout first, see protocol.DecodeAgentCard.
+// When a verification key is configured with WithAgentCardVerificationKey, the signed
+// card is requested and its signature checked; an unsigned or tampered card is rejected.
+// With WithAgentCardCache, a cached card is returned instead while the agent's
+// Cache-Control header allows it.
+func (c *A2AClient) GetAgentCard(ctx context.Context, card interface{}) error {
+	if c.cardCache != nil {
+		if entry, revalidate := c.cardCache.lookup(); entry != nil {
+			if revalidate {
+				// The caller may cancel ctx as soon as this returns.
+				c.revalidateAgentCard(context.WithoutCancel(ctx))
+			}
+			return c.decodeAgentCard(entry.body, entry.contentType, card)
+		}
+	}
+	resp, err := c.fetchAgentCard(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.decodeAgentCard(resp.body, resp.contentType, card); err != nil {
+		return err
+	}
+	if c.cardCache != nil {
+		c.cardCache.store(resp.body, resp.contentType, resp.cacheControl)
+	}
+	return nil
+}
+
+// agentCardResponse is a successful agent card response.
+type agentCardResponse struct {
+	body         []byte
+	contentType  string
+	cacheControl string
+}
+
+// fetchAgentCard requests the agent card from the agent.
+func (c *A2AClient) fetchAgentCard(ctx context.Context) (*agentCardResponse, error) {
+	cardURL := c.endpoint().ResolveReference(&url.URL{Path: protocol.AgentCardPath})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cardURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.GetAgentCard: failed to create http request: %w", err)
+	}
+	if c.agentCardKey != nil {
+		req.Header.Set("Accept", protocol.AgentCardJWSContentType)
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	release, err := c.limiter.acquireCall(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.GetAgentCard: %w", err)
+	}
+	defer release()
+	resp, err := c.do(req, true)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.GetAgentCard: http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.GetAgentCard: failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("a2aClient.GetAgentCard: %w", newHTTPError(resp, body))
+	}
+	return &agentCardResponse{
+		body:         body,
+		contentType:  resp.Header.Get("Content-Type"),
+		cacheControl: resp.Header.Get("Cache-Control"),
+	}, nil
+}
+
+// decodeAgentCard unmarshals an agent card response body into card, verifying its
+// signature when a verification key is configured.
+func (c *A2AClient) decodeAgentCard(body []byte, contentType string, card interface{}) error {
+	if c.agentCardKey == nil {
+		if err := protocol.DecodeAgentCard(body, card); err != nil {
+			return fmt.Errorf("a2aClient.GetAgentCard: %w", err)
+		}
+		return nil
+	}
+	if !strings.HasPrefix(contentType, protocol.AgentCardJWSContentType) {
+		return fmt.Errorf("a2aClient.GetAgentCard: %w", protocol.ErrAgentCardUnsigned)
+	}
+	if err := protocol.VerifyAgentCard(body, c.agentCardKey, card); err != nil {
+		return fmt.Errorf("a2aClient.GetAgentCard: %w", err)
+	}
+	return nil
+}
+
+// setBaggage sets the baggage header of req to the baggage of its context, if any,
+// see protocol.WithBaggage.
+func setBaggage(req *http.Request) {
+	if baggage := protocol.BaggageFromContext(req.Context()); len(baggage) > 0 {
+		if header := baggage.String(); header != "" {
+			req.Header.Set(protocol.HeaderBaggage, header)
+		}
+	}
+}
+
+// signMessage signs message with the key set by WithMessageSigningKey, if any.
+func (c *A2AClient) signMessage(message *protocol.Message) error {
+	if c.messageKey == nil {
+		return nil
+	}
+	return protocol.SignMessage(message, c.messageKey)
+}
+
+// signSendParams signs the messages of params, those seeding the task history
+// included, with the key set by WithMessageSigningKey, if any. The seed messages
+// are copied, leaving those of the caller unchanged.
+func (c *A2AClient) signSendParams(params *protocol.SendTaskParams) error {
+	if c.messageKey == nil {
+		return nil
+	}
+	if len(params.Messages) > 0 {
+		messages := make([]protocol.Message, len(params.Messages))
+		for i, message := range params.Messages {
+			if err := c.signMessage(&message); err != nil {
+				return fmt.Errorf("messages[%d]: %w", i, err)
+			}
+			messages[i] = message
+		}
+		params.Messages = messages
+	}
+	return c.signMessage(&params.Message)
+}
+
 // StreamTask sends a message using tasks_sendSubscribe and returns a channel for receiving SSE events.
 // It handles setting up the SSE connection and parsing events.
 // The returned channel will be closed when the stream ends (task completion, error, or context cancellation).
+// If the stream breaks before the task finishes, the last event is a protocol.TaskStreamErrorEvent.
+// That includes ctx's deadline passing, which ends even a silent stream at once;
+// canceling ctx just closes the channel.
 func (c *A2AClient) StreamTask(
 	ctx context.Context,
 	params protocol.SendTaskParams,
+	opts ...StreamOption,
 ) (<-chan protocol.TaskEvent, error) {
+	streamOpts := &streamOptions{eventFilter: protocol.StreamEventFilterAll}
+	for _, opt := range opts {
+		opt(streamOpts)
+	}
+	if err := c.signSendParams(&params); err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTask: %w", err)
+	}
+	if c.limiter == nil {
+		return c.streamTask(ctx, params, streamOpts)
+	}
+	release, err := c.limiter.acquireStream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTask: %w", err)
+	}
+	events, err := c.streamTask(ctx, params, streamOpts)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return releaseOnClose(ctx, events, release), nil
+}
+
+// streamTask implements StreamTask once the stream slot, if any, is held.
+func (c *A2AClient) streamTask(
+	ctx context.Context,
+	params protocol.SendTaskParams,
+	streamOpts *streamOptions,
+) (<-chan protocol.TaskEvent, error) {
+	if c.streamFallback > 0 && !c.agentSupportsStreaming(ctx) {
+		log.Infof("Agent card does not advertise streaming, polling for task %s", params.ID)
+		return c.streamByPolling(ctx, params, nil, streamOpts.eventFilter)
+	}
+	req, err := c.newStreamRequest(ctx, protocol.MethodTasksSendSubscribe, params.ID, params, streamOpts.eventFilter)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTask: %w", err)
+	}
+	// Make the initial request to establish the stream, counted as a call until it is open.
+	release, err := c.limiter.acquireCall(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTask: %w", err)
+	}
+	resp, err := c.do(req, retrySafe(protocol.MethodTasksSendSubscribe, &sendOptions{}))
+	release()
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTask: http request failed: %w", err)
+	}
+	// Check for non-success HTTP status codes.
+	// For SSE, a successful setup should result in 200 OK.
+	if resp.StatusCode != http.StatusOK {
+		// Read body for error details if possible.
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if c.streamFallback > 0 && isMethodNotFound(bodyBytes) {
+			log.Infof("Agent does not support %s, falling back to polling for task %s",
+				protocol.MethodTasksSendSubscribe, params.ID)
+			return c.streamByPolling(ctx, params, nil, streamOpts.eventFilter)
+		}
+		return nil, fmt.Errorf("a2aClient.StreamTask: establishing stream: %w", newHTTPError(resp, bodyBytes))
+	}
+	// Check if the response is actually an event stream.
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream") {
+		if c.streamFallback > 0 {
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			// Only a task result or method-not-found means the agent cannot stream;
+			// any other error (auth, invalid params) is returned rather than re-sent.
+			if task := decodeTaskResult(bodyBytes); task != nil {
+				log.Infof("Agent did not open an event stream, polling for task %s", params.ID)
+				return c.streamByPolling(ctx, params, task, streamOpts.eventFilter)
+			}
+			if isMethodNotFound(bodyBytes) {
+				log.Infof("Agent does not support %s, falling back to polling for task %s",
+					protocol.MethodTasksSendSubscribe, params.ID)
+				return c.streamByPolling(ctx, params, nil, streamOpts.eventFilter)
+			}
+			if rpcErr := decodeRPCError(bodyBytes); rpcErr != nil {
+				return nil, fmt.Errorf("a2aClient.StreamTask: %w", rpcErr)
+			}
+			return nil, fmt.Errorf(
+				"a2aClient.StreamTask: unexpected response establishing stream: %s", string(bodyBytes),
+			)
+		}
+		resp.Body.Close()
+		return nil, fmt.Errorf(
+			"a2aClient.StreamTask: server did not respond with Content-Type 'text/event-stream', got %s",
+			resp.Header.Get("Content-Type"),
+		)
+	}
+	log.Debugf("A2A Client Stream Response <- Status: %d, ID: %v. Stream established.", resp.StatusCode, params.ID)
+	// Create the channel to send events back to the caller.
+	eventsChan := make(chan protocol.TaskEvent, 10) // Buffered channel.
+	// Start a goroutine to read from the SSE stream.
+	go c.processSSEStream(ctx, resp, []string{params.ID}, eventsChan, streamOpts.eventFilter)
+	return eventsChan, nil
+}
+
+// newStreamRequest creates the HTTP request opening an SSE stream with the JSON-RPC
+// method, using id as the request ID.
+func (c *A2AClient) newStreamRequest(
+	ctx context.Context,
+	method, id string,
+	params interface{},
+	filter protocol.StreamEventFilter,
+) (*http.Request, error) {
 	// Create the JSON-RPC request.
-	request := jsonrpc.NewRequest(protocol.MethodTasksSendSubscribe, params.ID)
+	request := jsonrpc.NewRequest(method, id)
 	paramsBytes, err := json.Marshal(params)
 	if err != nil {
-		return nil, fmt.Errorf("a2aClient.StreamTask: failed to marshal params: %w", err)
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
 	}
 	request.Params = paramsBytes
 	reqBody, err := json.Marshal(request)
 	if err != nil {
-		return nil, fmt.Errorf("a2aClient.StreamTask: failed to marshal request body: %w", err)
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
+	c.logRequestBody(reqBody)
 	// Construct the target URL.
-	targetURL := c.baseURL.String()
+	targetURL := c.endpointFor(method, paramsBytes).String()
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
@@ -152,73 +662,293 @@ func (c *A2AClient) StreamTask(
 		bytes.NewReader(reqBody),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("a2aClient.StreamTask: failed to create http request: %w", err)
+		return nil, fmt.Errorf("failed to create http request: %w", err)
 	}
 	// Set headers, including Accept for event stream.
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("Accept", "text/event-stream") // Crucial for SSE.
+	if filter != protocol.StreamEventFilterAll {
+		// Let the server skip unwanted events; they are filtered client-side as well.
+		req.Header.Set(protocol.HeaderStreamEventFilter, string(filter))
+	}
 	if c.userAgent != "" {
 		req.Header.Set("User-Agent", c.userAgent)
 	}
+	setBaggage(req)
 	log.Debugf("A2A Client Stream Request -> Method: %s, ID: %v, URL: %s", request.Method, request.ID, targetURL)
-	// Make the initial request to establish the stream.
-	resp, err := c.httpClient.Do(req)
+	return req, nil
+}
+
+// ResubscribeTask reopens the event stream of a running task with tasks/resubscribe,
+// for example after the connection of an earlier stream broke. The agent first
+// sends the task's current status; events sent while no stream was open are not
+// replayed. The channel is closed once the task reaches a final state.
+func (c *A2AClient) ResubscribeTask(
+	ctx context.Context,
+	taskID string,
+	opts ...StreamOption,
+) (<-chan protocol.TaskEvent, error) {
+	streamOpts := &streamOptions{eventFilter: protocol.StreamEventFilterAll}
+	for _, opt := range opts {
+		opt(streamOpts)
+	}
+	if c.limiter == nil {
+		return c.resubscribeTask(ctx, taskID, streamOpts)
+	}
+	release, err := c.limiter.acquireStream(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("a2aClient.StreamTask: http request failed: %w", err)
+		return nil, fmt.Errorf("a2aClient.ResubscribeTask: %w", err)
+	}
+	events, err := c.resubscribeTask(ctx, taskID, streamOpts)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return releaseOnClose(ctx, events, release), nil
+}
+
+// resubscribeTask implements ResubscribeTask once the stream slot, if any, is held.
+func (c *A2AClient) resubscribeTask(
+	ctx context.Context,
+	taskID string,
+	streamOpts *streamOptions,
+) (<-chan protocol.TaskEvent, error) {
+	req, err := c.newStreamRequest(ctx, protocol.MethodTasksResubscribe, taskID,
+		protocol.TaskIDParams{ID: taskID}, streamOpts.eventFilter)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.ResubscribeTask: %w", err)
+	}
+	release, err := c.limiter.acquireCall(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.ResubscribeTask: %w", err)
+	}
+	resp, err := c.do(req, retrySafe(protocol.MethodTasksResubscribe, &sendOptions{}))
+	release()
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.ResubscribeTask: http request failed: %w", err)
 	}
-	// Check for non-success HTTP status codes.
-	// For SSE, a successful setup should result in 200 OK.
 	if resp.StatusCode != http.StatusOK {
-		// Read body for error details if possible.
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf(
-			"a2aClient.StreamTask: unexpected http status %d establishing stream: %s",
-			resp.StatusCode, string(bodyBytes),
-		)
+		return nil, fmt.Errorf("a2aClient.ResubscribeTask: establishing stream: %w", newHTTPError(resp, bodyBytes))
 	}
-	// Check if the response is actually an event stream.
 	if !strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream") {
+		bodyBytes, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
+		if rpcErr := decodeRPCError(bodyBytes); rpcErr != nil {
+			return nil, fmt.Errorf("a2aClient.ResubscribeTask: %w", rpcErr)
+		}
 		return nil, fmt.Errorf(
-			"a2aClient.StreamTask: server did not respond with Content-Type 'text/event-stream', got %s",
+			"a2aClient.ResubscribeTask: server did not respond with Content-Type 'text/event-stream', got %s",
 			resp.Header.Get("Content-Type"),
 		)
 	}
-	log.Debugf("A2A Client Stream Response <- Status: %d, ID: %v. Stream established.", resp.StatusCode, request.ID)
-	// Create the channel to send events back to the caller.
-	eventsChan := make(chan protocol.TaskEvent, 10) // Buffered channel.
-	// Start a goroutine to read from the SSE stream.
-	go c.processSSEStream(ctx, resp, params.ID, eventsChan)
+	eventsChan := make(chan protocol.TaskEvent, 10)
+	go c.processSSEStream(ctx, resp, []string{taskID}, eventsChan, streamOpts.eventFilter)
+	return eventsChan, nil
+}
+
+// streamByPolling emulates a task stream for agents without streaming support.
+// Unless the agent already returned the task, it is sent with tasks/send, then
+// polled with tasks/get, and events are synthesized from the changes.
+func (c *A2AClient) streamByPolling(
+	ctx context.Context,
+	params protocol.SendTaskParams,
+	task *protocol.Task,
+	filter protocol.StreamEventFilter,
+) (<-chan protocol.TaskEvent, error) {
+	if task == nil {
+		var err error
+		task, err = c.SendTasks(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("a2aClient.StreamTask: fallback send failed: %w", err)
+		}
+	}
+	eventsChan := make(chan protocol.TaskEvent, 10)
+	go c.pollTaskEvents(ctx, task, filter, eventsChan)
 	return eventsChan, nil
 }
 
+// pollTaskEvents polls the task until it reaches a final state, sending synthesized
+// artifact and status events onto the channel. It closes the channel when done.
+// Runs in its own goroutine.
+func (c *A2AClient) pollTaskEvents(
+	ctx context.Context,
+	task *protocol.Task,
+	filter protocol.StreamEventFilter,
+	eventsChan chan<- protocol.TaskEvent,
+) {
+	defer close(eventsChan)
+	ticker := time.NewTicker(c.streamFallback)
+	defer ticker.Stop()
+	var lastStatus *protocol.TaskStatus
+	sentArtifacts := 0
+	for {
+		var events []protocol.TaskEvent
+		// New artifacts come before the status that reports them, as on a real stream.
+		for ; sentArtifacts < len(task.Artifacts); sentArtifacts++ {
+			artifact := task.Artifacts[sentArtifacts]
+			events = append(events, protocol.TaskArtifactUpdateEvent{
+				ID:       task.ID,
+				Artifact: artifact,
+				Final:    artifact.LastChunk != nil && *artifact.LastChunk,
+			})
+		}
+		if lastStatus == nil || lastStatus.State != task.Status.State ||
+			lastStatus.Timestamp != task.Status.Timestamp {
+			status := task.Status
+			lastStatus = &status
+			events = append(events, protocol.TaskStatusUpdateEvent{
+				ID:     task.ID,
+				Status: status,
+				Final:  status.State.IsFinal(),
+			})
+		}
+		for _, event := range events {
+			if !filter.Allows(event) {
+				continue
+			}
+			select {
+			case eventsChan <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if task.Status.State.IsFinal() {
+			log.Debugf("Polled task %s reached final state %s. Closing stream.", task.ID, task.Status.State)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		polled, err := c.GetTasks(ctx, protocol.TaskQueryParams{ID: task.ID})
+		if err != nil {
+			log.Errorf("Error polling task %s: %v", task.ID, err)
+			sendStreamError(ctx, eventsChan, task.ID, fmt.Errorf("a2aClient.StreamTask: poll failed: %w", err))
+			return
+		}
+		task = polled
+	}
+}
+
+// agentSupportsStreaming reports whether the agent card advertises streaming.
+// The card is fetched once per client; if it cannot be fetched or omits the
+// capability, streaming is assumed and the subscribe response decides.
+func (c *A2AClient) agentSupportsStreaming(ctx context.Context) bool {
+	c.streamingCheck.Do(func() {
+		var card struct {
+			Capabilities struct {
+				Streaming *bool `json:"streaming"`
+			} `json:"capabilities"`
+		}
+		if err := c.GetAgentCard(ctx, &card); err != nil {
+			log.Debugf("Could not fetch agent card to check streaming support: %v", err)
+			return
+		}
+		c.streamingDisabled = card.Capabilities.Streaming != nil && !*card.Capabilities.Streaming
+	})
+	return !c.streamingDisabled
+}
+
+// isMethodNotFound reports whether body is a JSON-RPC method-not-found error response.
+func isMethodNotFound(body []byte) bool {
+	var response jsonrpc.RawResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return false
+	}
+	return response.Error != nil && response.Error.Code == jsonrpc.CodeMethodNotFound
+}
+
+// decodeTaskResult returns the task carried in a JSON-RPC success response body, or nil.
+func decodeTaskResult(body []byte) *protocol.Task {
+	var response jsonrpc.RawResponse
+	if err := json.Unmarshal(body, &response); err != nil || response.Error != nil || len(response.Result) == 0 {
+		return nil
+	}
+	task := &protocol.Task{}
+	if err := json.Unmarshal(response.Result, task); err != nil || task.ID == "" {
+		return nil
+	}
+	return task
+}
+
 // processSSEStream reads Server-Sent Events from the response body and sends them
 // onto the provided channel. It handles closing the channel and response body.
+// The stream ends once every task in taskIDs sent its final status.
 // Runs in its own goroutine.
 func (c *A2AClient) processSSEStream(
 	ctx context.Context,
 	resp *http.Response,
-	taskID string,
+	taskIDs []string,
 	eventsChan chan<- protocol.TaskEvent,
+	filter protocol.StreamEventFilter,
 ) {
 	// Ensure resources are cleaned up when the goroutine exits.
 	defer resp.Body.Close()
 	defer close(eventsChan)
-	reader := sse.NewEventReader(resp.Body)
+	taskID := strings.Join(taskIDs, ",") // For logging.
+	finished := make(map[string]bool, len(taskIDs))
+	// sendStreamErrors reports the broken stream for every task still running.
+	sendStreamErrors := func(err error) {
+		for _, id := range taskIDs {
+			if !finished[id] {
+				sendStreamError(ctx, eventsChan, id, err)
+			}
+		}
+	}
+	// endOnContext reports a stream ended by ctx. Only a deadline is reported as an
+	// error; a caller that cancels is no longer listening. The send does not block,
+	// as ctx is already done.
+	endOnContext := func() {
+		log.Debugf("SSE context done for task %s: %v", taskID, ctx.Err())
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+		for _, id := range taskIDs {
+			if finished[id] {
+				continue
+			}
+			select {
+			case eventsChan <- protocol.TaskStreamErrorEvent{ID: id, Err: ctx.Err()}:
+			default:
+			}
+		}
+	}
+	// Close the body once ctx ends, so a read blocked on a silent stream returns
+	// even if the transport does not watch the request context.
+	stopClosing := context.AfterFunc(ctx, func() { resp.Body.Close() })
+	defer stopClosing()
+	var body io.Reader = resp.Body
+	if c.streamIdleTimeout > 0 {
+		idleReader := newIdleTimeoutReader(resp.Body, c.streamIdleTimeout)
+		defer idleReader.stop()
+		body = idleReader
+	}
+	reader := sse.NewEventReader(body)
 	log.Debugf("SSE Processor started for task %s", taskID)
 	for {
 		select {
 		case <-ctx.Done():
 			// Context canceled (e.g., timeout or manual cancellation by caller).
-			log.Debugf("SSE context canceled for task %s: %v", taskID, ctx.Err())
+			endOnContext()
 			return
 		default:
 			// Read the next event from the stream.
 			eventBytes, eventType, err := reader.ReadEvent()
 			if err != nil {
+				if ctx.Err() != nil {
+					// The read was interrupted by the context ending.
+					endOnContext()
+					return
+				}
 				if err == io.EOF {
 					log.Debugf("SSE stream ended cleanly (EOF) for task %s", taskID)
+				} else if errors.Is(err, ErrStreamIdleTimeout) {
+					log.Warnf("No data received on SSE stream for task %s within %s, closing stream",
+						taskID, c.streamIdleTimeout)
+					sendStreamErrors(err)
 				} else if errors.Is(err, context.Canceled) ||
 					strings.Contains(err.Error(), "connection reset by peer") {
 					// Client disconnected normally
@@ -226,6 +956,7 @@ func (c *A2AClient) processSSEStream(
 				} else {
 					// Log unexpected errors (like network issues or parsing problems)
 					log.Errorf("Error reading SSE stream for task %s: %v", taskID, err)
+					sendStreamErrors(err)
 				}
 				return // Stop processing on any error or EOF.
 			}
@@ -233,6 +964,9 @@ func (c *A2AClient) processSSEStream(
 			if len(eventBytes) == 0 {
 				continue
 			}
+			if c.logBodies {
+				log.Debugf("A2A Client SSE event body (task %s): %s", taskID, redact.JSON(eventBytes))
+			}
 			// Handle close event immediately before any other processing.
 			if eventType == protocol.EventClose {
 				log.Debugf(
@@ -276,6 +1010,10 @@ func (c *A2AClient) processSSEStream(
 					)
 					continue // Skip malformed event.
 				}
+				// Terminal states are always final, even if the server omitted the flag.
+				if statusEvent.Status.State.IsFinal() {
+					statusEvent.Final = true
+				}
 				taskEvent = statusEvent
 			case protocol.EventTaskArtifactUpdate:
 				var artifactEvent protocol.TaskArtifactUpdateEvent
@@ -287,6 +1025,40 @@ func (c *A2AClient) processSSEStream(
 					continue // Skip malformed event.
 				}
 				taskEvent = artifactEvent
+			case protocol.EventTaskArtifactPatch:
+				var patchEvent protocol.TaskArtifactPatchEvent
+				if err := json.Unmarshal(eventBytes, &patchEvent); err != nil {
+					log.Errorf(
+						"Error unmarshaling TaskArtifactPatchEvent for task %s: %v. Data: %s",
+						taskID, err, string(eventBytes),
+					)
+					continue // Skip malformed event.
+				}
+				if err := protocol.ValidatePatch(patchEvent.Patch); err != nil {
+					log.Errorf("Rejected artifact patch for task %s: %v", taskID, err)
+					continue // Skip invalid patch.
+				}
+				taskEvent = patchEvent
+			case protocol.EventTaskArtifactDelete:
+				var deleteEvent protocol.TaskArtifactDeleteEvent
+				if err := json.Unmarshal(eventBytes, &deleteEvent); err != nil {
+					log.Errorf(
+						"Error unmarshaling TaskArtifactDeleteEvent for task %s: %v. Data: %s",
+						taskID, err, string(eventBytes),
+					)
+					continue // Skip malformed event.
+				}
+				taskEvent = deleteEvent
+			case protocol.EventTaskMessage:
+				var messageEvent protocol.TaskMessageEvent
+				if err := json.Unmarshal(eventBytes, &messageEvent); err != nil {
+					log.Errorf(
+						"Error unmarshaling TaskMessageEvent for task %s: %v. Data: %s",
+						taskID, err, string(eventBytes),
+					)
+					continue // Skip malformed event.
+				}
+				taskEvent = messageEvent
 			default:
 				log.Warnf(
 					"Received unknown SSE event type '%s' for task %s. Data: %s",
@@ -294,6 +1066,18 @@ func (c *A2AClient) processSSEStream(
 				)
 				continue // Skip unknown event types.
 			}
+			// A final status event still counts towards ending the stream when the filter drops it.
+			if statusEvent, ok := taskEvent.(protocol.TaskStatusUpdateEvent); ok && statusEvent.Final {
+				finished[statusEvent.ID] = true
+			}
+			allFinished := len(finished) >= len(taskIDs)
+			if !filter.Allows(taskEvent) {
+				if allFinished {
+					log.Debugf("Received final status event for task %s. Closing stream.", taskID)
+					return
+				}
+				continue
+			}
 			// Send the deserialized event to the caller's channel.
 			// Use a select to avoid blocking if the caller isn't reading fast enough
 			// or if the context was canceled concurrently.
@@ -301,22 +1085,73 @@ func (c *A2AClient) processSSEStream(
 			case eventsChan <- taskEvent:
 				// Event sent successfully.
 			case <-ctx.Done():
-				log.Debugf(
-					"SSE context canceled while sending event for task %s: %v",
-					taskID, ctx.Err(),
-				)
+				endOnContext()
 				return // Stop processing.
 			}
+			// Close the channel after the last final status event, even if the HTTP stream lingers.
+			if allFinished {
+				log.Debugf("Received final status event for task %s. Closing stream.", taskID)
+				return
+			}
 		}
 	}
 }
 
+// sendStreamError delivers a TaskStreamErrorEvent as the last event of a stream,
+// unless the caller has already gone away.
+func sendStreamError(ctx context.Context, eventsChan chan<- protocol.TaskEvent, taskID string, err error) {
+	select {
+	case eventsChan <- protocol.TaskStreamErrorEvent{ID: taskID, Err: err}:
+	case <-ctx.Done():
+	}
+}
+
+// idleTimeoutReader wraps an SSE response body and closes it when no data
+// (events or heartbeat comments) arrives within the timeout.
+type idleTimeoutReader struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+// newIdleTimeoutReader creates an idleTimeoutReader and starts its idle timer.
+func newIdleTimeoutReader(body io.ReadCloser, timeout time.Duration) *idleTimeoutReader {
+	r := &idleTimeoutReader{
+		body:    body,
+		timeout: timeout,
+	}
+	r.timer = time.AfterFunc(timeout, func() {
+		r.timedOut.Store(true)
+		r.body.Close() // Unblocks any pending Read.
+	})
+	return r
+}
+
+// Read implements io.Reader, resetting the idle timer whenever data arrives.
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if r.timedOut.Load() {
+		return n, ErrStreamIdleTimeout
+	}
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
+}
+
+// stop stops the idle timer.
+func (r *idleTimeoutReader) stop() {
+	r.timer.Stop()
+}
+
 func (c *A2AClient) doRequestAndDecodeTask(
 	ctx context.Context,
 	request *jsonrpc.Request,
+	opts *sendOptions,
 ) (*protocol.Task, error) {
 	// Perform the HTTP request and basic JSON unmarshaling into fullResponse.
-	fullResponse, err := c.doRequest(ctx, request)
+	fullResponse, err := c.doRequest(ctx, request, opts)
 	if err != nil {
 		return nil, err // Error is already contextualized by doRequest.
 	}
@@ -340,60 +1175,86 @@ func (c *A2AClient) doRequestAndDecodeTask(
 	return task, nil
 }
 
+// logRequestBody logs body, a marshaled JSON-RPC request, at debug level with
+// secrets redacted, if WithBodyLogging is set.
+func (c *A2AClient) logRequestBody(body []byte) {
+	if c.logBodies {
+		log.Debugf("A2A Client request body: %s", redact.JSON(body))
+	}
+}
+
 // doRequest performs the HTTP POST request for a JSON-RPC call.
 // It handles request marshaling, setting headers, sending the request,
 // checking the HTTP status, and decoding the base JSON response structure.
 // It does NOT specifically handle the 'result' or 'error' fields, leaving that
 // to the caller or doRequestAndDecodeResult.
+// Per-call send options, if any, are applied to the request.
 func (c *A2AClient) doRequest(
-	ctx context.Context, request *jsonrpc.Request,
+	ctx context.Context, request *jsonrpc.Request, opts *sendOptions,
 ) (*jsonrpc.RawResponse, error) {
+	if opts == nil {
+		opts = &sendOptions{}
+	}
 	reqBody, err := json.Marshal(request)
 	if err != nil {
 		// Use a more specific error message prefix.
 		return nil, fmt.Errorf("a2aClient.doRequest: failed to marshal request: %w", err)
 	}
-	// Construct the target URL using the base URL.
+	c.logRequestBody(reqBody)
+	var body io.Reader = bytes.NewReader(reqBody)
+	if opts.uploadProgress != nil {
+		body = newProgressReader(body, int64(len(reqBody)), opts.uploadProgress)
+	}
+	// Construct the target URL using the base URL, or the endpoint of the task.
 	// Assume the RPC endpoint is at the root of the baseURL.
-	targetURL := c.baseURL.String()
+	targetURL := c.endpointFor(request.Method, request.Params).String()
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
 		targetURL,
-		bytes.NewReader(reqBody),
+		body,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("a2aClient.doRequest: failed to create http request: %w", err)
 	}
+	if opts.uploadProgress != nil {
+		// The wrapped reader hides the length from http.NewRequest.
+		req.ContentLength = int64(len(reqBody))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(reqBody)), nil
+		}
+	}
 	// Set required headers.
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("Accept", "application/json")
+	if opts.idempotencyKey != "" {
+		req.Header.Set(protocol.HeaderIdempotencyKey, opts.idempotencyKey)
+	}
+	if opts.ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", opts.ifNoneMatch)
+	}
 	if c.userAgent != "" {
 		req.Header.Set("User-Agent", c.userAgent)
 	}
+	setBaggage(req)
 	log.Debugf("A2A Client Request -> Method: %s, ID: %v, URL: %s", request.Method, request.ID, targetURL)
-	resp, err := c.httpClient.Do(req)
+	resp, respBodyBytes, err := c.sendRequest(ctx, req, request, opts)
 	if err != nil {
-		return nil, fmt.Errorf("a2aClient.doRequest: http request failed: %w", err)
-	}
-	// Ensure body is always closed.
-	defer resp.Body.Close()
-	// Read the body first for potential error reporting.
-	respBodyBytes, readErr := io.ReadAll(resp.Body)
-	if readErr != nil {
-		log.Warnf(
-			"Warning: a2aClient.doRequest: failed to read response body (status %d): %v",
-			resp.StatusCode, readErr,
-		)
-		// Continue to check status code, but decoding will likely fail.
+		return nil, err
 	}
 	log.Debugf("A2A Client Response <- Status: %d, ID: %v", resp.StatusCode, request.ID)
+	if c.logBodies {
+		log.Debugf("A2A Client response body (ID: %v): %s", request.ID, redact.JSON(respBodyBytes))
+	}
+	if opts.etag != nil {
+		*opts.etag = resp.Header.Get("ETag")
+	}
+	if resp.StatusCode == http.StatusNotModified && opts.ifNoneMatch != "" {
+		return nil, ErrTaskNotModified
+	}
 	// Check for non-success HTTP status codes. This is separate from JSON-RPC errors.
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf(
-			"a2aClient.doRequest: unexpected http status %d: %s",
-			resp.StatusCode, string(respBodyBytes),
-		)
+		return nil, fmt.Errorf("a2aClient.doRequest: %w", newHTTPError(resp, respBodyBytes))
 	}
 	response := &jsonrpc.RawResponse{}
 	// Decode the full JSON response body into the provided target.
@@ -404,9 +1265,38 @@ func (c *A2AClient) doRequest(
 			resp.StatusCode, err, string(respBodyBytes),
 		)
 	}
+	attachValidationError(response.Error)
 	return response, nil
 }
 
+// decodeRPCError returns the JSON-RPC error in a response body, or nil if the
+// body is not a JSON-RPC error response.
+func decodeRPCError(body []byte) *jsonrpc.Error {
+	var response jsonrpc.RawResponse
+	if err := json.Unmarshal(body, &response); err != nil || response.Error == nil {
+		return nil
+	}
+	attachValidationError(response.Error)
+	return response.Error
+}
+
+// attachValidationError lets errors.As find the *protocol.ValidationError carried
+// in the data of an invalid params error.
+func attachValidationError(rpcErr *jsonrpc.Error) {
+	if rpcErr == nil || rpcErr.Code != jsonrpc.CodeInvalidParams || rpcErr.Data == nil {
+		return
+	}
+	data, err := json.Marshal(rpcErr.Data)
+	if err != nil {
+		return
+	}
+	validation := &protocol.ValidationError{}
+	if err := json.Unmarshal(data, validation); err != nil || len(validation.Fields) == 0 {
+		return // Data is some other detail, such as a message.
+	}
+	rpcErr.SetCause(validation)
+}
+
 // SetPushNotification configures push notifications for a task.
 // It allows specifying a callback URL where task status updates will be sent.
 func (c *A2AClient) SetPushNotification(
@@ -421,7 +1311,7 @@ func (c *A2AClient) SetPushNotification(
 	request.Params = paramsBytes
 
 	// Perform the HTTP request and basic JSON unmarshaling
-	fullResponse, err := c.doRequest(ctx, request)
+	fullResponse, err := c.doRequest(ctx, request, nil)
 	if err != nil {
 		return nil, fmt.Errorf("a2aClient.SetPushNotification: %w", err)
 	}
@@ -461,7 +1351,7 @@ func (c *A2AClient) GetPushNotification(
 	request.Params = paramsBytes
 
 	// Perform the HTTP request and basic JSON unmarshaling
-	fullResponse, err := c.doRequest(ctx, request)
+	fullResponse, err := c.doRequest(ctx, request, nil)
 	if err != nil {
 		return nil, fmt.Errorf("a2aClient.GetPushNotification: %w", err)
 	}
@@ -487,3 +1377,16 @@ func (c *A2AClient) GetPushNotification(
 
 	return config, nil
 }
+
+// VerifyArtifact checks that content (e.g. downloaded from the artifact's file URI)
+// matches the SHA-256 checksum carried by the artifact.
+func VerifyArtifact(a protocol.Artifact, content []byte) error {
+	if a.Checksum == nil || *a.Checksum == "" {
+		return ErrArtifactChecksumMissing
+	}
+	actual := protocol.ComputeChecksum(content)
+	if !strings.EqualFold(actual, *a.Checksum) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrArtifactChecksumMismatch, *a.Checksum, actual)
+	}
+	return nil
+}
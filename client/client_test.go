@@ -7,19 +7,26 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
 	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
 
@@ -103,6 +110,93 @@ func TestA2AClient_SendTask(t *testing.T) {
 	})
 }
 
+// TestA2AClient_HTTPError verifies that HTTP-level failures expose their status
+// and back-off headers through an *HTTPError.
+func TestA2AClient_HTTPError(t *testing.T) {
+	params := protocol.SendTaskParams{
+		ID:      "throttled-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+	}
+	newServer := func(t *testing.T, status int, retryAfter string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", retryAfter)
+			w.Header().Set("RateLimit-Limit", "100")
+			w.Header().Set("RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			w.Header().Set("Set-Cookie", "session=secret")
+			http.Error(w, http.StatusText(status), status)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	t.Run("Calls", func(t *testing.T) {
+		server := newServer(t, http.StatusTooManyRequests, "7")
+		client, err := NewA2AClient(server.URL)
+		require.NoError(t, err)
+		ctx := context.Background()
+		_, sendErr := client.SendTasks(ctx, params)
+		_, streamErr := client.StreamTask(ctx, params)
+		cardErr := client.GetAgentCard(ctx, &struct{}{})
+		for name, err := range map[string]error{"SendTasks": sendErr, "StreamTask": streamErr, "GetAgentCard": cardErr} {
+			var httpErr *HTTPError
+			require.ErrorAs(t, err, &httpErr, name)
+			assert.Equal(t, http.StatusTooManyRequests, httpErr.StatusCode, name)
+			retryAfter, ok := httpErr.RetryAfter()
+			assert.True(t, ok, name)
+			assert.Equal(t, 7*time.Second, retryAfter, name)
+			assert.Equal(t, "0", httpErr.Header.Get("RateLimit-Remaining"), name)
+			assert.Equal(t, "100", httpErr.Header.Get("RateLimit-Limit"), name)
+			assert.Equal(t, "1700000000", httpErr.Header.Get("X-RateLimit-Reset"), name)
+			assert.Empty(t, httpErr.Header.Get("Set-Cookie"), name)
+			assert.Contains(t, err.Error(), "unexpected http status 429", name)
+		}
+	})
+
+	t.Run("RetryAfterDate", func(t *testing.T) {
+		server := newServer(t, http.StatusServiceUnavailable, time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		client, err := NewA2AClient(server.URL)
+		require.NoError(t, err)
+		_, err = client.SendTasks(context.Background(), params)
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+		retryAfter, ok := httpErr.RetryAfter()
+		assert.True(t, ok)
+		assert.InDelta(t, time.Hour, retryAfter, float64(time.Minute))
+	})
+
+	t.Run("RetryAfterValues", func(t *testing.T) {
+		for value, want := range map[string]bool{"": false, "soon": false, "-1": false, "0": true} {
+			_, ok := (&HTTPError{Header: http.Header{"Retry-After": {value}}}).RetryAfter()
+			assert.Equal(t, want, ok, "Retry-After: %q", value)
+		}
+		wait, ok := (&HTTPError{Header: http.Header{
+			"Retry-After": {time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)},
+		}}).RetryAfter()
+		assert.True(t, ok)
+		assert.Zero(t, wait, "a date in the past means retry now")
+	})
+
+	t.Run("JSONRPCBody", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(jsonrpc.NewErrorResponse("1", jsonrpc.ErrInternalError("overloaded")))
+		}))
+		defer server.Close()
+		client, err := NewA2AClient(server.URL)
+		require.NoError(t, err)
+		_, err = client.SendTasks(context.Background(), params)
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr, "the JSON-RPC error in the body is still reachable")
+		assert.Equal(t, jsonrpc.CodeInternalError, rpcErr.Code)
+	})
+}
+
 // TestA2AClient_StreamTask tests the StreamTask client method for SSE.
 // It covers success, HTTP errors, and non-SSE response scenarios.
 func TestA2AClient_StreamTask(t *testing.T) {
@@ -303,3 +397,1111 @@ func createMockServerHandler(
 		assert.NoError(t, err, "MockHandler: Failed to write response body")
 	}
 }
+
+// TestA2AClient_StreamTask_FinalEventClosesChannel verifies that the client closes
+// its event channel after a terminal status event, even if the HTTP stream lingers.
+func TestA2AClient_StreamTask_FinalEventClosesChannel(t *testing.T) {
+	terminalStates := []protocol.TaskState{
+		protocol.TaskStateCompleted,
+		protocol.TaskStateFailed,
+		protocol.TaskStateCanceled,
+	}
+	for _, state := range terminalStates {
+		t.Run(string(state), func(t *testing.T) {
+			taskID := "client-task-final-" + string(state)
+			finalData, err := json.Marshal(protocol.TaskStatusUpdateEvent{
+				ID:     taskID,
+				Status: protocol.TaskStatus{State: state},
+				Final:  true,
+			})
+			require.NoError(t, err)
+
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, "event: task_status_update\ndata: %s\n\n", string(finalData))
+				w.(http.Flusher).Flush()
+				// Keep the stream open after the final event.
+				select {
+				case <-r.Context().Done():
+				case <-release:
+				}
+			}))
+			defer server.Close()
+			defer close(release)
+
+			client, err := NewA2AClient(server.URL)
+			require.NoError(t, err)
+			eventChan, err := client.StreamTask(context.Background(), protocol.SendTaskParams{
+				ID: taskID,
+				Message: protocol.Message{
+					Role:  protocol.MessageRoleUser,
+					Parts: []protocol.Part{protocol.NewTextPart("final event test")},
+				},
+			})
+			require.NoError(t, err)
+
+			timeout := time.After(2 * time.Second)
+			var received []protocol.TaskEvent
+		loop:
+			for {
+				select {
+				case event, ok := <-eventChan:
+					if !ok {
+						break loop
+					}
+					received = append(received, event)
+				case <-timeout:
+					t.Fatal("Timeout waiting for channel to close after final event")
+				}
+			}
+			require.Len(t, received, 1)
+			statusEvent, ok := received[0].(protocol.TaskStatusUpdateEvent)
+			require.True(t, ok)
+			assert.Equal(t, state, statusEvent.Status.State)
+			assert.True(t, statusEvent.IsFinal())
+		})
+	}
+}
+
+// TestA2AClient_StreamTask_IdleTimeout verifies that a silent SSE stream is closed
+// after the configured idle timeout, while heartbeats keep the stream alive.
+func TestA2AClient_StreamTask_IdleTimeout(t *testing.T) {
+	taskID := "client-task-idle"
+	params := protocol.SendTaskParams{
+		ID: taskID,
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleUser,
+			Parts: []protocol.Part{protocol.NewTextPart("idle timeout test")},
+		},
+	}
+	workingData, err := json.Marshal(protocol.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: protocol.TaskStatus{State: protocol.TaskStateWorking},
+	})
+	require.NoError(t, err)
+	completedData, err := json.Marshal(protocol.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: protocol.TaskStatus{State: protocol.TaskStateCompleted},
+		Final:  true,
+	})
+	require.NoError(t, err)
+
+	collect := func(t *testing.T, eventChan <-chan protocol.TaskEvent) []protocol.TaskEvent {
+		var received []protocol.TaskEvent
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case event, ok := <-eventChan:
+				if !ok {
+					return received
+				}
+				received = append(received, event)
+			case <-timeout:
+				t.Fatal("Timeout waiting for stream channel to close")
+				return nil
+			}
+		}
+	}
+
+	t.Run("SilentServer", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, "event: task_status_update\ndata: %s\n\n", string(workingData))
+			w.(http.Flusher).Flush()
+			// Go silent.
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		client, err := NewA2AClient(server.URL, WithStreamIdleTimeout(100*time.Millisecond))
+		require.NoError(t, err)
+		start := time.Now()
+		eventChan, err := client.StreamTask(context.Background(), params)
+		require.NoError(t, err)
+
+		received := collect(t, eventChan)
+		require.Len(t, received, 2)
+		assert.False(t, received[0].IsFinal())
+		streamErr, ok := received[1].(protocol.TaskStreamErrorEvent)
+		require.True(t, ok, "an idle stream should end with a stream error event")
+		assert.Equal(t, taskID, streamErr.ID)
+		assert.ErrorIs(t, streamErr, ErrStreamIdleTimeout)
+		assert.True(t, streamErr.IsFinal())
+		assert.Less(t, time.Since(start), time.Second, "idle timeout should close the stream promptly")
+	})
+
+	t.Run("HeartbeatsKeepStreamAlive", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			flusher := w.(http.Flusher)
+			fmt.Fprintf(w, "event: task_status_update\ndata: %s\n\n", string(workingData))
+			flusher.Flush()
+			for i := 0; i < 6; i++ {
+				time.Sleep(40 * time.Millisecond)
+				fmt.Fprint(w, ": heartbeat\n\n")
+				flusher.Flush()
+			}
+			fmt.Fprintf(w, "event: task_status_update\ndata: %s\n\n", string(completedData))
+			flusher.Flush()
+		}))
+		defer server.Close()
+
+		client, err := NewA2AClient(server.URL, WithStreamIdleTimeout(100*time.Millisecond))
+		require.NoError(t, err)
+		eventChan, err := client.StreamTask(context.Background(), params)
+		require.NoError(t, err)
+
+		received := collect(t, eventChan)
+		require.Len(t, received, 2)
+		assert.True(t, received[1].IsFinal())
+		_, isErr := received[1].(protocol.TaskStreamErrorEvent)
+		assert.False(t, isErr, "a finished stream should not report an error")
+	})
+}
+
+// detachedTransport sends requests without their context, like a transport that
+// does not watch it, so only the client itself can interrupt a stream read.
+type detachedTransport struct{}
+
+func (detachedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(req.WithContext(context.Background()))
+}
+
+// TestA2AClient_StreamTask_ContextDeadline verifies that a ctx deadline ends an
+// active stream promptly with a stream error, while a cancel just closes it.
+func TestA2AClient_StreamTask_ContextDeadline(t *testing.T) {
+	taskID := "client-task-deadline"
+	params := protocol.SendTaskParams{
+		ID:      taskID,
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("deadline test")}),
+	}
+	workingData, err := json.Marshal(protocol.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: protocol.TaskStatus{State: protocol.TaskStateWorking},
+	})
+	require.NoError(t, err)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "event: task_status_update\ndata: %s\n\n", string(workingData))
+		w.(http.Flusher).Flush()
+		// Keep the stream open without sending anything.
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	collect := func(t *testing.T, eventChan <-chan protocol.TaskEvent) []protocol.TaskEvent {
+		var received []protocol.TaskEvent
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case event, ok := <-eventChan:
+				if !ok {
+					return received
+				}
+				received = append(received, event)
+			case <-timeout:
+				t.Fatal("Timeout waiting for stream channel to close")
+				return nil
+			}
+		}
+	}
+
+	for name, opts := range map[string][]Option{
+		"Default":           nil,
+		"DetachedTransport": {WithHTTPClient(&http.Client{Transport: detachedTransport{}})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewA2AClient(server.URL, opts...)
+			require.NoError(t, err)
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			eventChan, err := client.StreamTask(ctx, params)
+			require.NoError(t, err)
+
+			received := collect(t, eventChan)
+			assert.Less(t, time.Since(start), time.Second, "the deadline should end the stream promptly")
+			require.Len(t, received, 2)
+			assert.False(t, received[0].IsFinal())
+			streamErr, ok := received[1].(protocol.TaskStreamErrorEvent)
+			require.True(t, ok, "a stream ended by its deadline should end with a stream error event")
+			assert.Equal(t, taskID, streamErr.ID)
+			assert.ErrorIs(t, streamErr, context.DeadlineExceeded)
+		})
+	}
+
+	t.Run("Canceled", func(t *testing.T) {
+		client, err := NewA2AClient(server.URL, WithHTTPClient(&http.Client{Transport: detachedTransport{}}))
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		eventChan, err := client.StreamTask(ctx, params)
+		require.NoError(t, err)
+		first := <-eventChan
+		assert.False(t, first.IsFinal())
+		cancel()
+
+		received := collect(t, eventChan)
+		assert.Empty(t, received, "a canceled stream closes without an error event")
+	})
+}
+
+func TestVerifyArtifact(t *testing.T) {
+	content := []byte("downloaded artifact content")
+	checksum := protocol.ComputeChecksum(content)
+
+	t.Run("Matching checksum", func(t *testing.T) {
+		artifact := protocol.Artifact{Checksum: &checksum}
+		assert.NoError(t, VerifyArtifact(artifact, content))
+	})
+
+	t.Run("Mismatching checksum", func(t *testing.T) {
+		artifact := protocol.Artifact{Checksum: &checksum}
+		err := VerifyArtifact(artifact, []byte("tampered content"))
+		assert.ErrorIs(t, err, ErrArtifactChecksumMismatch)
+	})
+
+	t.Run("Missing checksum", func(t *testing.T) {
+		err := VerifyArtifact(protocol.Artifact{}, content)
+		assert.ErrorIs(t, err, ErrArtifactChecksumMissing)
+	})
+}
+
+// TestA2AClient_StreamTask_EventFilter verifies that filtered streams only carry the
+// requested event types and still close on the final status event.
+func TestA2AClient_StreamTask_EventFilter(t *testing.T) {
+	taskID := "client-task-filter"
+	lastChunk := true
+	events := []struct {
+		eventType string
+		payload   interface{}
+	}{
+		{protocol.EventTaskStatusUpdate, protocol.TaskStatusUpdateEvent{
+			ID: taskID, Status: protocol.TaskStatus{State: protocol.TaskStateWorking},
+		}},
+		{protocol.EventTaskArtifactUpdate, protocol.TaskArtifactUpdateEvent{
+			ID: taskID, Artifact: protocol.Artifact{Index: 0, Parts: []protocol.Part{protocol.NewTextPart("chunk 1")}},
+		}},
+		{protocol.EventTaskArtifactUpdate, protocol.TaskArtifactUpdateEvent{
+			ID: taskID, Artifact: protocol.Artifact{
+				Index: 0, Parts: []protocol.Part{protocol.NewTextPart("chunk 2")}, LastChunk: &lastChunk,
+			}, Final: true,
+		}},
+		{protocol.EventTaskStatusUpdate, protocol.TaskStatusUpdateEvent{
+			ID: taskID, Status: protocol.TaskStatus{State: protocol.TaskStateCompleted}, Final: true,
+		}},
+	}
+
+	var receivedFilter string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// This server ignores the filter header so client-side filtering is exercised.
+		receivedFilter = r.Header.Get(protocol.HeaderStreamEventFilter)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for _, event := range events {
+			data, err := json.Marshal(event.payload)
+			require.NoError(t, err)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.eventType, string(data))
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client, err := NewA2AClient(server.URL)
+	require.NoError(t, err)
+	params := protocol.SendTaskParams{
+		ID: taskID,
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleUser,
+			Parts: []protocol.Part{protocol.NewTextPart("filter test")},
+		},
+	}
+
+	collect := func(t *testing.T, eventChan <-chan protocol.TaskEvent) []protocol.TaskEvent {
+		timeout := time.After(2 * time.Second)
+		var received []protocol.TaskEvent
+		for {
+			select {
+			case event, ok := <-eventChan:
+				if !ok {
+					return received
+				}
+				received = append(received, event)
+			case <-timeout:
+				t.Fatal("Timeout waiting for filtered stream to close")
+				return nil
+			}
+		}
+	}
+
+	t.Run("ArtifactOnly", func(t *testing.T) {
+		eventChan, err := client.StreamTask(context.Background(), params,
+			WithEventFilter(protocol.StreamEventFilterArtifact))
+		require.NoError(t, err)
+		received := collect(t, eventChan)
+		assert.Equal(t, string(protocol.StreamEventFilterArtifact), receivedFilter)
+		require.Len(t, received, 2)
+		for _, event := range received {
+			assert.IsType(t, protocol.TaskArtifactUpdateEvent{}, event)
+		}
+	})
+
+	t.Run("StatusOnly", func(t *testing.T) {
+		eventChan, err := client.StreamTask(context.Background(), params,
+			WithEventFilter(protocol.StreamEventFilterStatus))
+		require.NoError(t, err)
+		received := collect(t, eventChan)
+		assert.Equal(t, string(protocol.StreamEventFilterStatus), receivedFilter)
+		require.Len(t, received, 2)
+		for _, event := range received {
+			assert.IsType(t, protocol.TaskStatusUpdateEvent{}, event)
+		}
+		assert.True(t, received[1].IsFinal())
+	})
+
+	t.Run("All", func(t *testing.T) {
+		eventChan, err := client.StreamTask(context.Background(), params)
+		require.NoError(t, err)
+		received := collect(t, eventChan)
+		assert.Empty(t, receivedFilter, "no filter header should be sent by default")
+		assert.Len(t, received, 4)
+	})
+}
+
+// TestA2AClient_SendTask_IdempotencyKey verifies that the idempotency key is sent as a header.
+func TestA2AClient_SendTask_IdempotencyKey(t *testing.T) {
+	var receivedKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedKey = r.Header.Get(protocol.HeaderIdempotencyKey)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":"idem-task","result":{"id":"idem-task","status":{"state":"completed"}}}`)
+	}))
+	defer server.Close()
+
+	client, err := NewA2AClient(server.URL)
+	require.NoError(t, err)
+	params := protocol.SendTaskParams{
+		ID:      "idem-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+	}
+
+	_, err = client.SendTasks(context.Background(), params, WithIdempotencyKey("retry-key"))
+	require.NoError(t, err)
+	assert.Equal(t, "retry-key", receivedKey)
+
+	_, err = client.SendTasks(context.Background(), params)
+	require.NoError(t, err)
+	assert.Empty(t, receivedKey, "no idempotency header should be sent without the option")
+}
+
+// TestA2AClient_Call tests calling arbitrary JSON-RPC methods.
+func TestA2AClient_Call(t *testing.T) {
+	var requestIDs []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requestIDs = append(requestIDs, req.ID)
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Method {
+		case "vendor/echo":
+			resp := jsonrpc.NewResponse(req.ID, req.Params)
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+		default:
+			resp := jsonrpc.NewErrorResponse(req.ID, jsonrpc.ErrMethodNotFound(req.Method))
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewA2AClient(server.URL)
+	require.NoError(t, err)
+
+	type echoParams struct {
+		Text  string `json:"text"`
+		Count int    `json:"count"`
+	}
+
+	t.Run("EchoParams", func(t *testing.T) {
+		var result echoParams
+		err := client.Call(context.Background(), "vendor/echo", echoParams{Text: "hello", Count: 3}, &result)
+		require.NoError(t, err)
+		assert.Equal(t, echoParams{Text: "hello", Count: 3}, result)
+	})
+
+	t.Run("NilResult", func(t *testing.T) {
+		err := client.Call(context.Background(), "vendor/echo", map[string]string{"k": "v"}, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("JSONRPCError", func(t *testing.T) {
+		err := client.Call(context.Background(), "vendor/unknown", nil, nil)
+		require.Error(t, err)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeMethodNotFound, rpcErr.Code)
+	})
+
+	require.Len(t, requestIDs, 3)
+	assert.NotEqual(t, requestIDs[0], requestIDs[1], "each call should use a new request ID")
+}
+
+// TestA2AClient_StreamTask_Fallback verifies that StreamTask falls back to polling
+// against an agent without streaming support and synthesizes stream events.
+func TestA2AClient_StreamTask_Fallback(t *testing.T) {
+	taskID := "client-task-fallback"
+	lastChunk := true
+	artifact0 := protocol.Artifact{Index: 0, Parts: []protocol.Part{protocol.NewTextPart("part 1")}}
+	artifact1 := protocol.Artifact{Index: 1, Parts: []protocol.Part{protocol.NewTextPart("part 2")}, LastChunk: &lastChunk}
+	working := protocol.Task{
+		ID:     taskID,
+		Status: protocol.TaskStatus{State: protocol.TaskStateWorking, Timestamp: "2025-01-01T00:00:00Z"},
+	}
+	polls := []protocol.Task{
+		{
+			ID:        taskID,
+			Status:    working.Status,
+			Artifacts: []protocol.Artifact{artifact0},
+		},
+		{
+			ID:        taskID,
+			Status:    protocol.TaskStatus{State: protocol.TaskStateCompleted, Timestamp: "2025-01-01T00:00:01Z"},
+			Artifacts: []protocol.Artifact{artifact0, artifact1},
+		},
+	}
+
+	var sendCalls, getCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			http.NotFound(w, r) // No agent card: capabilities are unknown.
+			return
+		}
+		var req jsonrpc.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Method {
+		case protocol.MethodTasksSend:
+			sendCalls++
+			json.NewEncoder(w).Encode(jsonrpc.NewResponse(req.ID, working))
+		case protocol.MethodTasksGet:
+			task := polls[min(getCalls, len(polls)-1)]
+			getCalls++
+			json.NewEncoder(w).Encode(jsonrpc.NewResponse(req.ID, task))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(jsonrpc.NewErrorResponse(req.ID, jsonrpc.ErrMethodNotFound(req.Method)))
+		}
+	}))
+	defer server.Close()
+
+	params := protocol.SendTaskParams{
+		ID: taskID,
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleUser,
+			Parts: []protocol.Part{protocol.NewTextPart("fallback test")},
+		},
+	}
+
+	t.Run("WithoutFallback", func(t *testing.T) {
+		client, err := NewA2AClient(server.URL)
+		require.NoError(t, err)
+		_, err = client.StreamTask(context.Background(), params)
+		assert.Error(t, err)
+	})
+
+	t.Run("WithFallback", func(t *testing.T) {
+		client, err := NewA2AClient(server.URL, WithStreamFallback(10*time.Millisecond))
+		require.NoError(t, err)
+		eventChan, err := client.StreamTask(context.Background(), params)
+		require.NoError(t, err)
+
+		var received []protocol.TaskEvent
+		timeout := time.After(2 * time.Second)
+	loop:
+		for {
+			select {
+			case event, ok := <-eventChan:
+				if !ok {
+					break loop
+				}
+				received = append(received, event)
+			case <-timeout:
+				t.Fatal("Timeout waiting for synthesized stream to close")
+			}
+		}
+
+		assert.Equal(t, 1, sendCalls)
+		assert.Equal(t, []protocol.TaskEvent{
+			protocol.TaskStatusUpdateEvent{ID: taskID, Status: working.Status},
+			protocol.TaskArtifactUpdateEvent{ID: taskID, Artifact: artifact0},
+			protocol.TaskArtifactUpdateEvent{ID: taskID, Artifact: artifact1, Final: true},
+			protocol.TaskStatusUpdateEvent{ID: taskID, Status: polls[1].Status, Final: true},
+		}, received)
+	})
+}
+
+func TestA2AClient_StreamTask_FallbackDecision(t *testing.T) {
+	taskID := "client-task-fallback-decision"
+	params := protocol.SendTaskParams{
+		ID: taskID,
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleUser,
+			Parts: []protocol.Part{protocol.NewTextPart("fallback decision")},
+		},
+	}
+	working := protocol.Task{
+		ID:     taskID,
+		Status: protocol.TaskStatus{State: protocol.TaskStateWorking},
+	}
+	completed := protocol.Task{
+		ID:     taskID,
+		Status: protocol.TaskStatus{State: protocol.TaskStateCompleted},
+	}
+
+	t.Run("CardWithoutStreamingPollsDirectly", func(t *testing.T) {
+		var cardCalls, subscribeCalls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.Method == http.MethodGet {
+				cardCalls.Add(1)
+				w.Write([]byte(`{"name":"test","capabilities":{"streaming":false}}`))
+				return
+			}
+			var req jsonrpc.Request
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			switch req.Method {
+			case protocol.MethodTasksSendSubscribe:
+				subscribeCalls.Add(1)
+				json.NewEncoder(w).Encode(jsonrpc.NewResponse(req.ID, working))
+			case protocol.MethodTasksSend:
+				json.NewEncoder(w).Encode(jsonrpc.NewResponse(req.ID, completed))
+			}
+		}))
+		defer server.Close()
+
+		client, err := NewA2AClient(server.URL, WithStreamFallback(10*time.Millisecond))
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			eventChan, err := client.StreamTask(context.Background(), params)
+			require.NoError(t, err)
+			var last protocol.TaskEvent
+			for event := range eventChan {
+				last = event
+			}
+			require.NotNil(t, last)
+			assert.True(t, last.IsFinal())
+		}
+		assert.Equal(t, int32(0), subscribeCalls.Load(), "subscribe should not be attempted")
+		assert.Equal(t, int32(1), cardCalls.Load(), "agent card should be fetched once")
+	})
+
+	t.Run("OtherErrorsAreNotResent", func(t *testing.T) {
+		var sendCalls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				http.NotFound(w, r)
+				return
+			}
+			var req jsonrpc.Request
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if req.Method == protocol.MethodTasksSend {
+				sendCalls.Add(1)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(jsonrpc.NewErrorResponse(req.ID, jsonrpc.ErrInvalidParams("bad message")))
+		}))
+		defer server.Close()
+
+		client, err := NewA2AClient(server.URL, WithStreamFallback(10*time.Millisecond))
+		require.NoError(t, err)
+		_, err = client.StreamTask(context.Background(), params)
+		require.Error(t, err)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+		assert.Equal(t, int32(0), sendCalls.Load(), "task should not be re-sent")
+	})
+
+	t.Run("PollErrorIsReported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				http.NotFound(w, r)
+				return
+			}
+			var req jsonrpc.Request
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			switch req.Method {
+			case protocol.MethodTasksSendSubscribe:
+				json.NewEncoder(w).Encode(jsonrpc.NewResponse(req.ID, working))
+			default:
+				json.NewEncoder(w).Encode(jsonrpc.NewErrorResponse(req.ID, jsonrpc.ErrInternalError("store down")))
+			}
+		}))
+		defer server.Close()
+
+		client, err := NewA2AClient(server.URL, WithStreamFallback(10*time.Millisecond))
+		require.NoError(t, err)
+		eventChan, err := client.StreamTask(context.Background(), params)
+		require.NoError(t, err)
+		var events []protocol.TaskEvent
+		timeout := time.After(2 * time.Second)
+	loop:
+		for {
+			select {
+			case event, ok := <-eventChan:
+				if !ok {
+					break loop
+				}
+				events = append(events, event)
+			case <-timeout:
+				t.Fatal("Timeout waiting for stream to close")
+			}
+		}
+		require.NotEmpty(t, events)
+		errEvent, ok := events[len(events)-1].(protocol.TaskStreamErrorEvent)
+		require.True(t, ok, "last event should be a stream error, got %T", events[len(events)-1])
+		assert.Equal(t, taskID, errEvent.ID)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, errEvent, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInternalError, rpcErr.Code)
+	})
+}
+
+func TestA2AClient_TokenCache(t *testing.T) {
+	var tokenRequests atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		time.Sleep(20 * time.Millisecond) // Widen the window for concurrent fetches.
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"shared-token","token_type":"bearer","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+	agentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer shared-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req jsonrpc.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.Header().Set("Content-Type", "application/json")
+		task := protocol.Task{ID: "task-1", Status: protocol.TaskStatus{State: protocol.TaskStateCompleted}}
+		require.NoError(t, json.NewEncoder(w).Encode(jsonrpc.NewResponse(req.ID, task)))
+	}))
+	defer agentServer.Close()
+
+	getTask := func(t *testing.T, opts ...Option) {
+		client, err := NewA2AClient(agentServer.URL, opts...)
+		require.NoError(t, err)
+		task, err := client.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "task-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "task-1", task.ID)
+	}
+
+	t.Run("SharedCache", func(t *testing.T) {
+		tokenRequests.Store(0)
+		cache := auth.NewMemoryTokenCache()
+		getTask(t, WithOAuth2ClientCredentials("id", "secret", tokenServer.URL, []string{"a2a"}), WithTokenCache(cache))
+		// Option order does not matter.
+		getTask(t, WithTokenCache(cache), WithOAuth2ClientCredentials("id", "secret", tokenServer.URL, []string{"a2a"}))
+		assert.Equal(t, int32(1), tokenRequests.Load())
+	})
+
+	t.Run("DifferentCredentials", func(t *testing.T) {
+		tokenRequests.Store(0)
+		cache := auth.NewMemoryTokenCache()
+		getTask(t, WithOAuth2ClientCredentials("id", "secret", tokenServer.URL, nil), WithTokenCache(cache))
+		getTask(t, WithOAuth2ClientCredentials("other-id", "secret", tokenServer.URL, nil), WithTokenCache(cache))
+		assert.Equal(t, int32(2), tokenRequests.Load())
+	})
+
+	t.Run("NoCache", func(t *testing.T) {
+		tokenRequests.Store(0)
+		getTask(t, WithOAuth2ClientCredentials("id", "secret", tokenServer.URL, nil))
+		getTask(t, WithOAuth2ClientCredentials("id", "secret", tokenServer.URL, nil))
+		assert.Equal(t, int32(2), tokenRequests.Load())
+	})
+
+	t.Run("ConcurrentClientsFetchOnce", func(t *testing.T) {
+		tokenRequests.Store(0)
+		cache := auth.NewMemoryTokenCache()
+		var wg sync.WaitGroup
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			client, err := NewA2AClient(agentServer.URL,
+				WithOAuth2ClientCredentials("concurrent", "secret", tokenServer.URL, nil), WithTokenCache(cache))
+			require.NoError(t, err)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "task-1"})
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), tokenRequests.Load())
+	})
+
+	t.Run("UsesCallerTransport", func(t *testing.T) {
+		var roundTrips atomic.Int32
+		httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			roundTrips.Add(1)
+			return http.DefaultTransport.RoundTrip(r)
+		})}
+		cache := auth.NewMemoryTokenCache()
+		getTask(t, WithHTTPClient(httpClient),
+			WithOAuth2ClientCredentials("transport", "secret", tokenServer.URL, nil), WithTokenCache(cache))
+		// Both the token fetch and the agent request go through the caller's transport.
+		assert.Equal(t, int32(2), roundTrips.Load())
+	})
+}
+
+func TestA2AClient_OAuth2TokenRetry(t *testing.T) {
+	var fetches atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The oauth2 package repeats a failed fetch with the credentials in the form;
+		// count only the first request of each fetch.
+		fetch := fetches.Load()
+		if _, _, ok := r.BasicAuth(); ok {
+			fetch = fetches.Add(1)
+		}
+		if fetch <= 2 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"retried-token","token_type":"bearer","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+	agentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer retried-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req jsonrpc.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.Header().Set("Content-Type", "application/json")
+		task := protocol.Task{ID: "task-1", Status: protocol.TaskStatus{State: protocol.TaskStateCompleted}}
+		require.NoError(t, json.NewEncoder(w).Encode(jsonrpc.NewResponse(req.ID, task)))
+	}))
+	defer agentServer.Close()
+
+	// The retry option is given before the credentials: option order does not matter.
+	client, err := NewA2AClient(agentServer.URL,
+		WithOAuth2TokenRetry(3, time.Millisecond),
+		WithOAuth2ClientCredentials("id", "secret", tokenServer.URL, nil))
+	require.NoError(t, err)
+	task, err := client.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "task-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "task-1", task.ID)
+	assert.Equal(t, int32(3), fetches.Load())
+}
+
+// TestA2AClient_SendTask_UploadProgress verifies progress is reported while a large file part is uploaded.
+func TestA2AClient_SendTask_UploadProgress(t *testing.T) {
+	var receivedLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, err := io.Copy(io.Discard, r.Body)
+		require.NoError(t, err)
+		receivedLength = n
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":"upload-task","result":{"id":"upload-task","status":{"state":"completed"}}}`)
+	}))
+	defer server.Close()
+
+	client, err := NewA2AClient(server.URL)
+	require.NoError(t, err)
+	content := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("0123456789abcdef"), 1<<16))
+	params := protocol.SendTaskParams{
+		ID: "upload-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{
+			protocol.FilePart{Type: protocol.PartTypeFile, File: protocol.FileContent{Bytes: &content}},
+		}),
+	}
+
+	var sent, totals []int64
+	_, err = client.SendTasks(context.Background(), params, WithUploadProgress(func(bytesSent, total int64) {
+		sent = append(sent, bytesSent)
+		totals = append(totals, total)
+	}))
+	require.NoError(t, err)
+
+	require.Greater(t, len(sent), 1, "progress should be reported more than once for a large body")
+	for i := 1; i < len(sent); i++ {
+		assert.Greater(t, sent[i], sent[i-1], "bytes sent should increase")
+	}
+	assert.Equal(t, receivedLength, sent[len(sent)-1])
+	for _, total := range totals {
+		assert.Equal(t, receivedLength, total)
+	}
+}
+
+func TestProgressReader_UnknownTotal(t *testing.T) {
+	var calls [][2]int64
+	r := newProgressReader(strings.NewReader("hello world"), 0, func(bytesSent, total int64) {
+		calls = append(calls, [2]int64{bytesSent, total})
+	})
+	buf := make([]byte, 4)
+	for {
+		if _, err := r.Read(buf); err == io.EOF {
+			break
+		}
+	}
+	assert.Equal(t, [][2]int64{{4, -1}, {8, -1}, {11, -1}}, calls)
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// TestNewA2AClient_Preflight tests that WithPreflight checks the agent at construction.
+func TestNewA2AClient_Preflight(t *testing.T) {
+	// newAgent returns a mock agent that requires apiKey on the JSON-RPC endpoint
+	// when it is not empty, and counts the calls it receives there.
+	newAgent := func(t *testing.T, apiKey string, rpcCalls *atomic.Int32) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == protocol.AgentCardPath {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"name":"Test Agent","capabilities":{"streaming":false}}`))
+				return
+			}
+			rpcCalls.Add(1)
+			if apiKey != "" && r.Header.Get("X-API-Key") != apiKey {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			var req jsonrpc.Request
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, protocol.MethodTasksGet, req.Method)
+			// Answer like the server does for an unknown task.
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(jsonrpc.NewErrorResponse(req.ID, &jsonrpc.Error{
+				Code: -32001, Message: "Task not found",
+			}))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	t.Run("HealthyAgent", func(t *testing.T) {
+		var rpcCalls atomic.Int32
+		server := newAgent(t, "secret", &rpcCalls)
+		client, err := NewA2AClient(server.URL, WithAPIKeyAuth("secret", "X-API-Key"), WithPreflight())
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), rpcCalls.Load())
+		// The card fetched by the preflight is reused for the streaming check.
+		assert.False(t, client.agentSupportsStreaming(context.Background()))
+	})
+
+	t.Run("MissingAuth", func(t *testing.T) {
+		var rpcCalls atomic.Int32
+		server := newAgent(t, "secret", &rpcCalls)
+		client, err := NewA2AClient(server.URL, WithPreflight())
+		require.ErrorIs(t, err, ErrPreflightUnauthorized)
+		assert.Nil(t, client)
+	})
+
+	t.Run("WrongAuth", func(t *testing.T) {
+		var rpcCalls atomic.Int32
+		server := newAgent(t, "secret", &rpcCalls)
+		_, err := NewA2AClient(server.URL, WithAPIKeyAuth("wrong", "X-API-Key"), WithPreflight())
+		require.ErrorIs(t, err, ErrPreflightUnauthorized)
+	})
+
+	t.Run("UnreachableAgent", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		agentURL := server.URL
+		server.Close()
+		_, err := NewA2AClient(agentURL, WithPreflight(), WithTimeout(time.Second))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "http request failed")
+		assert.NotErrorIs(t, err, ErrPreflightUnauthorized)
+	})
+
+	t.Run("NotAnAgent", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(server.Close)
+		_, err := NewA2AClient(server.URL, WithPreflight())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected http status 404")
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		agentURL := server.URL
+		server.Close()
+		_, err := NewA2AClient(agentURL)
+		require.NoError(t, err)
+	})
+}
+
+func TestNewA2AClient_AgentCardTimeout(t *testing.T) {
+	// newAgent returns a mock agent serving card and counting the card requests.
+	newAgent := func(t *testing.T, card string, cardRequests *atomic.Int32) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != protocol.AgentCardPath {
+				http.NotFound(w, r)
+				return
+			}
+			cardRequests.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(card))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	hintCard := func(hint string) string {
+		return `{"name":"Test Agent","extensions":{"` + protocol.ExtensionProcessingTime + `":` + hint + `}}`
+	}
+
+	tests := []struct {
+		name string
+		card string
+		want time.Duration
+	}{
+		{name: "MaxHint", card: hintCard(`{"typicalMs":5000,"maxMs":180000}`), want: 3 * time.Minute},
+		{name: "TypicalHint", card: hintCard(`{"typicalMs":45000}`), want: 90 * time.Second},
+		{name: "EmptyHint", card: hintCard(`{}`), want: defaultTimeout},
+		{name: "InvalidHint", card: hintCard(`"soon"`), want: defaultTimeout},
+		{name: "NoHint", card: `{"name":"Test Agent"}`, want: defaultTimeout},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var cardRequests atomic.Int32
+			server := newAgent(t, tc.card, &cardRequests)
+			client, err := NewA2AClient(server.URL, WithAgentCardTimeout())
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, client.httpClient.Timeout)
+			assert.Equal(t, int32(1), cardRequests.Load())
+		})
+	}
+
+	t.Run("ExplicitTimeoutWins", func(t *testing.T) {
+		var cardRequests atomic.Int32
+		server := newAgent(t, hintCard(`{"maxMs":180000}`), &cardRequests)
+		client, err := NewA2AClient(server.URL, WithAgentCardTimeout(), WithTimeout(5*time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+		assert.Zero(t, cardRequests.Load(), "the card is not fetched")
+
+		custom := &http.Client{Timeout: 7 * time.Second}
+		client, err = NewA2AClient(server.URL, WithHTTPClient(custom), WithAgentCardTimeout())
+		require.NoError(t, err)
+		assert.Equal(t, 7*time.Second, client.httpClient.Timeout)
+	})
+
+	t.Run("UnreachableAgent", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		agentURL := server.URL
+		server.Close()
+		client, err := NewA2AClient(agentURL, WithAgentCardTimeout())
+		require.NoError(t, err)
+		assert.Equal(t, defaultTimeout, client.httpClient.Timeout)
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		var cardRequests atomic.Int32
+		server := newAgent(t, hintCard(`{"maxMs":180000}`), &cardRequests)
+		client, err := NewA2AClient(server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, defaultTimeout, client.httpClient.Timeout)
+		assert.Zero(t, cardRequests.Load())
+	})
+}
+
+// debugRecorder records the Debug messages logged through it.
+type debugRecorder struct {
+	log.Logger
+	mu     sync.Mutex
+	debugs []string
+}
+
+func (l *debugRecorder) Debugf(format string, args ...interface{}) {
+	l.mu.Lock()
+	l.debugs = append(l.debugs, fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+	l.Logger.Debugf(format, args...)
+}
+
+// matching returns the recorded messages containing substr.
+func (l *debugRecorder) matching(substr string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var matched []string
+	for _, debug := range l.debugs {
+		if strings.Contains(debug, substr) {
+			matched = append(matched, debug)
+		}
+	}
+	return matched
+}
+
+func TestA2AClient_BodyLogging(t *testing.T) {
+	send := func(t *testing.T, opts ...Option) *debugRecorder {
+		logger := &debugRecorder{Logger: log.Default}
+		log.Default = logger
+		t.Cleanup(func() { log.Default = logger.Logger })
+
+		server, _ := failingAgent(t, 0, http.StatusOK)
+		client, err := NewA2AClient(server.URL, opts...)
+		require.NoError(t, err)
+		part := protocol.NewTextPart("logged text")
+		part.Metadata = map[string]interface{}{"password": "hunter2"}
+		_, err = client.SendTasks(context.Background(), protocol.SendTaskParams{
+			ID:      "retry-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{part}),
+		})
+		require.NoError(t, err)
+		return logger
+	}
+
+	t.Run("LoggedWhenEnabled", func(t *testing.T) {
+		logger := send(t, WithBodyLogging())
+		requests := logger.matching("A2A Client request body")
+		require.Len(t, requests, 1)
+		assert.Contains(t, requests[0], "logged text")
+		assert.Contains(t, requests[0], `"password":"[REDACTED]"`)
+		assert.NotContains(t, requests[0], "hunter2")
+
+		responses := logger.matching("A2A Client response body")
+		require.Len(t, responses, 1)
+		assert.Contains(t, responses[0], "retry-task")
+	})
+
+	t.Run("AbsentByDefault", func(t *testing.T) {
+		logger := send(t)
+		assert.Empty(t, logger.matching("A2A Client request body"))
+		assert.Empty(t, logger.matching("A2A Client response body"))
+	})
+}
@@ -0,0 +1,256 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// ErrConnClosed is returned for calls on a ConnClient whose connection is closed.
+var ErrConnClosed = errors.New("connection closed")
+
+// ErrCallTimeout is returned when a call gets no response within the call timeout.
+var ErrCallTimeout = errors.New("call timed out waiting for response")
+
+// ConnOption configures a ConnClient.
+type ConnOption func(*ConnClient)
+
+// WithCallTimeout bounds how long each call waits for its response. Default is
+// 60 seconds; zero waits until the context is done.
+func WithCallTimeout(timeout time.Duration) ConnOption {
+	return func(c *ConnClient) {
+		if timeout >= 0 {
+			c.callTimeout = timeout
+		}
+	}
+}
+
+// WithRequestIDGenerator sets the function generating the JSON-RPC id of each call.
+// IDs must be unique among the calls in flight; the default is a counter.
+func WithRequestIDGenerator(next func() string) ConnOption {
+	return func(c *ConnClient) {
+		if next != nil {
+			c.nextID = next
+		}
+	}
+}
+
+// ConnClient calls an A2A agent over a single long-lived connection carrying
+// newline-delimited JSON-RPC messages, such as a WebSocket or an HTTP/2 stream
+// adapted to an io.ReadWriteCloser. Calls may be in flight concurrently; since
+// the agent may answer them in any order, each response is routed to its call
+// by JSON-RPC id. It is safe for concurrent use.
+type ConnClient struct {
+	conn        io.ReadWriteCloser
+	callTimeout time.Duration
+	nextID      func() string
+
+	writeMu sync.Mutex // Serializes writes so requests do not interleave.
+	encoder *json.Encoder
+
+	mu      sync.Mutex
+	pending map[string]chan *jsonrpc.RawResponse // Calls awaiting a response, by id.
+	err     error                                // Why the connection stopped; nil while open.
+
+	streams map[string]*ConnStream // Open streams, by the id of the request opening them.
+}
+
+// NewConnClient creates a ConnClient over conn and starts reading its responses.
+// The client owns conn and closes it in Close.
+func NewConnClient(conn io.ReadWriteCloser, opts ...ConnOption) (*ConnClient, error) {
+	if conn == nil {
+		return nil, errors.New("a2aConnClient: connection cannot be nil")
+	}
+	var counter atomic.Uint64
+	c := &ConnClient{
+		conn:        conn,
+		callTimeout: defaultTimeout,
+		nextID: func() string {
+			return "a2a-" + strconv.FormatUint(counter.Add(1), 10)
+		},
+		encoder: json.NewEncoder(conn),
+		pending: make(map[string]chan *jsonrpc.RawResponse),
+		streams: make(map[string]*ConnStream),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// readLoop routes each response to the call waiting for its id, and each stream
+// event to its stream, until the connection fails, then fails the calls and
+// streams still pending.
+func (c *ConnClient) readLoop() {
+	decoder := json.NewDecoder(c.conn)
+	for {
+		var message struct {
+			jsonrpc.RawResponse
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = ErrConnClosed
+			}
+			c.fail(err)
+			return
+		}
+		if message.Method == protocol.MethodTasksStreamEvent {
+			c.routeStreamEvent(message.Params)
+			continue
+		}
+		response := &message.RawResponse
+		id, _ := response.ID.(string)
+		if c.endStreamOnResponse(id, response) {
+			continue
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+		if !ok {
+			// The call may have timed out already.
+			log.Warnf("a2aConnClient: dropping response for unknown request id %v", response.ID)
+			continue
+		}
+		ch <- response // Buffered; the call may have gone, but the send never blocks.
+	}
+}
+
+// fail records why the connection stopped and fails all pending calls.
+func (c *ConnClient) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	for id, stream := range c.streams {
+		stream.end(protocol.TaskStreamErrorEvent{ID: stream.taskID, Err: c.err})
+		delete(c.streams, id)
+	}
+}
+
+// Close closes the connection, failing the calls and streams in flight with
+// ErrConnClosed.
+func (c *ConnClient) Close() error {
+	c.fail(ErrConnClosed)
+	return c.conn.Close()
+}
+
+// Call invokes method with params and unmarshals the response result into result
+// (nil discards it). A JSON-RPC error response is returned as an error.
+func (c *ConnClient) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if c.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, c.callTimeout, ErrCallTimeout)
+		defer cancel()
+	}
+	id := c.nextID()
+	request := jsonrpc.NewRequest(method, id)
+	if params != nil {
+		paramsBytes, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("a2aConnClient.Call: failed to marshal params: %w", err)
+		}
+		request.Params = paramsBytes
+	}
+
+	ch := make(chan *jsonrpc.RawResponse, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return fmt.Errorf("a2aConnClient.Call: %w", err)
+	}
+	if c.inFlight(id) {
+		c.mu.Unlock()
+		return fmt.Errorf("a2aConnClient.Call: request id %q is already in flight", id)
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	c.writeMu.Lock()
+	err := c.encoder.Encode(request)
+	c.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("a2aConnClient.Call: failed to send request: %w", err)
+	}
+
+	var response *jsonrpc.RawResponse
+	select {
+	case response = <-ch:
+	case <-ctx.Done():
+		return fmt.Errorf("a2aConnClient.Call: %w", context.Cause(ctx))
+	}
+	if response == nil {
+		c.mu.Lock()
+		err := c.err
+		c.mu.Unlock()
+		return fmt.Errorf("a2aConnClient.Call: %w", err)
+	}
+	if response.Error != nil {
+		attachValidationError(response.Error)
+		return fmt.Errorf("a2aConnClient.Call: %w", response.Error)
+	}
+	if result == nil || len(response.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("a2aConnClient.Call: failed to unmarshal rpc result: %w", err)
+	}
+	return nil
+}
+
+// SendTasks sends a message using the tasks/send method.
+func (c *ConnClient) SendTasks(ctx context.Context, params protocol.SendTaskParams) (*protocol.Task, error) {
+	task := &protocol.Task{}
+	if err := c.Call(ctx, protocol.MethodTasksSend, params, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// GetTasks retrieves the status of a task using the tasks/get method.
+func (c *ConnClient) GetTasks(ctx context.Context, params protocol.TaskQueryParams) (*protocol.Task, error) {
+	task := &protocol.Task{}
+	if err := c.Call(ctx, protocol.MethodTasksGet, params, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// CancelTasks cancels an in-progress task using the tasks/cancel method.
+func (c *ConnClient) CancelTasks(ctx context.Context, params protocol.TaskIDParams) (*protocol.Task, error) {
+	task := &protocol.Task{}
+	if err := c.Call(ctx, protocol.MethodTasksCancel, params, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
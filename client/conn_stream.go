@@ -0,0 +1,316 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// ConnStream is a stream of task events opened on a ConnClient, with credit-based
+// flow control: the server sends one event per credit granted, and pauses the
+// stream while it has no credits left, until Grant adds more. Credits not used
+// when the stream ends are lost.
+type ConnStream struct {
+	client *ConnClient
+	id     string // JSON-RPC id of the request that opened the stream.
+	taskID string
+	events chan protocol.TaskEvent
+
+	mu    sync.Mutex
+	queue []protocol.TaskEvent // Events received but not delivered yet.
+	ended bool
+	wake  chan struct{} // Signaled when the queue grows or the stream ends.
+}
+
+// SendTaskSubscribe sends a message using tasks/sendSubscribe and opens a stream of
+// the task's events, granting it credits events to begin with. The stream is
+// closed after the final status event, when ctx is done, or with a
+// protocol.TaskStreamErrorEvent if the call fails or the connection breaks.
+func (c *ConnClient) SendTaskSubscribe(
+	ctx context.Context,
+	params protocol.SendTaskParams,
+	credits int,
+) (*ConnStream, error) {
+	return c.openStream(ctx, protocol.MethodTasksSendSubscribe, params.ID, params, credits)
+}
+
+// Resubscribe resubscribes to the events of a task using tasks/resubscribe, like
+// SendTaskSubscribe.
+func (c *ConnClient) Resubscribe(
+	ctx context.Context,
+	params protocol.TaskIDParams,
+	credits int,
+) (*ConnStream, error) {
+	return c.openStream(ctx, protocol.MethodTasksResubscribe, params.ID, params, credits)
+}
+
+// Events returns the channel of the stream's events, closed when the stream ends.
+func (s *ConnStream) Events() <-chan protocol.TaskEvent {
+	return s.events
+}
+
+// Grant lets the server send credits more events of the stream, sending a
+// tasks/grantCredits notification.
+func (s *ConnStream) Grant(credits int) error {
+	params := protocol.GrantCreditsParams{ID: s.id, Credits: credits}
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("a2aConnClient.Grant: %w", err)
+	}
+	if err := s.client.notify(protocol.MethodTasksGrantCredits, params); err != nil {
+		return fmt.Errorf("a2aConnClient.Grant: %w", err)
+	}
+	return nil
+}
+
+// openStream sends the request opening a stream and grants its first credits.
+func (c *ConnClient) openStream(
+	ctx context.Context,
+	method string,
+	taskID string,
+	params interface{},
+	credits int,
+) (*ConnStream, error) {
+	id := c.nextID()
+	request := jsonrpc.NewRequest(method, id)
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("a2aConnClient.openStream: failed to marshal params: %w", err)
+	}
+	request.Params = paramsBytes
+	stream := &ConnStream{
+		client: c,
+		id:     id,
+		taskID: taskID,
+		events: make(chan protocol.TaskEvent, 10),
+		wake:   make(chan struct{}, 1),
+	}
+
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return nil, fmt.Errorf("a2aConnClient.openStream: %w", err)
+	}
+	if c.inFlight(id) {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("a2aConnClient.openStream: request id %q is already in flight", id)
+	}
+	c.streams[id] = stream
+	c.mu.Unlock()
+
+	c.writeMu.Lock()
+	err = c.encoder.Encode(request)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.removeStream(id)
+		return nil, fmt.Errorf("a2aConnClient.openStream: failed to send request: %w", err)
+	}
+	if credits > 0 {
+		if err := stream.Grant(credits); err != nil {
+			c.removeStream(id)
+			return nil, err
+		}
+	}
+	go stream.pump(ctx)
+	return stream, nil
+}
+
+// notify sends a JSON-RPC notification.
+func (c *ConnClient) notify(method string, params interface{}) error {
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal params: %w", err)
+	}
+	notification := jsonrpc.Request{
+		Message: jsonrpc.Message{JSONRPC: jsonrpc.Version},
+		Method:  method,
+		Params:  paramsBytes,
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.encoder.Encode(notification)
+}
+
+// inFlight reports whether a call or stream uses request id. c.mu must be held.
+func (c *ConnClient) inFlight(id string) bool {
+	_, calling := c.pending[id]
+	_, streaming := c.streams[id]
+	return calling || streaming
+}
+
+// removeStream forgets a stream, whose events are then ignored.
+func (c *ConnClient) removeStream(id string) {
+	c.mu.Lock()
+	delete(c.streams, id)
+	c.mu.Unlock()
+}
+
+// routeStreamEvent delivers the event of a tasks/streamEvent notification to its
+// stream, ending the stream after its last event.
+func (c *ConnClient) routeStreamEvent(rawParams json.RawMessage) {
+	var params protocol.StreamEventParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		log.Errorf("a2aConnClient: malformed %s notification: %v", protocol.MethodTasksStreamEvent, err)
+		return
+	}
+	c.mu.Lock()
+	stream, ok := c.streams[params.ID]
+	c.mu.Unlock()
+	if !ok {
+		// The stream may have been abandoned already.
+		log.Debugf("a2aConnClient: dropping event for unknown stream %q", params.ID)
+		return
+	}
+	if params.EventType == protocol.EventClose {
+		c.removeStream(params.ID)
+		stream.end(nil)
+		return
+	}
+	event, err := decodeTaskEvent(params.EventType, params.Event)
+	if err != nil {
+		log.Errorf("a2aConnClient: skipping event of stream %q: %v", params.ID, err)
+		return
+	}
+	if status, ok := event.(protocol.TaskStatusUpdateEvent); ok && status.Final {
+		c.removeStream(params.ID)
+		stream.end(event)
+		return
+	}
+	stream.push(event)
+}
+
+// endStreamOnResponse ends the stream opened by request id, if any, with the
+// response to the request: the server answers a call opening a stream only if it
+// could not stream. It reports whether there was such a stream.
+func (c *ConnClient) endStreamOnResponse(id string, response *jsonrpc.RawResponse) bool {
+	c.mu.Lock()
+	stream, ok := c.streams[id]
+	delete(c.streams, id)
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if response.Error != nil {
+		attachValidationError(response.Error)
+		stream.end(protocol.TaskStreamErrorEvent{ID: stream.taskID, Err: response.Error})
+		return true
+	}
+	stream.end(nil)
+	return true
+}
+
+// push queues an event for delivery. It never blocks, so a stream whose events
+// are not read does not hold up the connection.
+func (s *ConnStream) push(event protocol.TaskEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.queue = append(s.queue, event)
+	s.signal()
+}
+
+// end ends the stream after the queued events and last, if not nil.
+func (s *ConnStream) end(last protocol.TaskEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	if last != nil {
+		s.queue = append(s.queue, last)
+	}
+	s.ended = true
+	s.signal()
+}
+
+// signal wakes the pump. s.mu must be held.
+func (s *ConnStream) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump delivers the queued events on the events channel until the stream ends or
+// ctx is done, then closes the channel.
+func (s *ConnStream) pump(ctx context.Context) {
+	defer close(s.events)
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			ended := s.ended
+			s.mu.Unlock()
+			if ended {
+				return
+			}
+			select {
+			case <-s.wake:
+				continue
+			case <-ctx.Done():
+				s.client.removeStream(s.id)
+				return
+			}
+		}
+		event := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+		select {
+		case s.events <- event:
+		case <-ctx.Done():
+			s.client.removeStream(s.id)
+			return
+		}
+	}
+}
+
+// decodeTaskEvent decodes a stream event of an SSE event type.
+func decodeTaskEvent(eventType string, data []byte) (protocol.TaskEvent, error) {
+	switch eventType {
+	case protocol.EventTaskStatusUpdate:
+		var statusEvent protocol.TaskStatusUpdateEvent
+		if err := json.Unmarshal(data, &statusEvent); err != nil {
+			return nil, err
+		}
+		// Terminal states are always final, even if the server omitted the flag.
+		if statusEvent.Status.State.IsFinal() {
+			statusEvent.Final = true
+		}
+		return statusEvent, nil
+	case protocol.EventTaskArtifactUpdate:
+		var artifactEvent protocol.TaskArtifactUpdateEvent
+		err := json.Unmarshal(data, &artifactEvent)
+		return artifactEvent, err
+	case protocol.EventTaskArtifactPatch:
+		var patchEvent protocol.TaskArtifactPatchEvent
+		if err := json.Unmarshal(data, &patchEvent); err != nil {
+			return nil, err
+		}
+		if err := protocol.ValidatePatch(patchEvent.Patch); err != nil {
+			return nil, err
+		}
+		return patchEvent, nil
+	case protocol.EventTaskArtifactDelete:
+		var deleteEvent protocol.TaskArtifactDeleteEvent
+		err := json.Unmarshal(data, &deleteEvent)
+		return deleteEvent, err
+	case protocol.EventTaskMessage:
+		var messageEvent protocol.TaskMessageEvent
+		err := json.Unmarshal(data, &messageEvent)
+		return messageEvent, err
+	default:
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+}
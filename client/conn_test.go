@@ -0,0 +1,164 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// connAgent is the agent end of a ConnClient connection.
+type connAgent struct {
+	t       *testing.T
+	conn    net.Conn
+	decoder *json.Decoder
+	encoder *json.Encoder
+}
+
+func newConnPair(t *testing.T, opts ...ConnOption) (*ConnClient, *connAgent) {
+	clientConn, agentConn := net.Pipe()
+	c, err := NewConnClient(clientConn, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		c.Close()
+		agentConn.Close()
+	})
+	return c, &connAgent{t: t, conn: agentConn, decoder: json.NewDecoder(agentConn), encoder: json.NewEncoder(agentConn)}
+}
+
+// read reads the next request from the client.
+func (a *connAgent) read() jsonrpc.Request {
+	var request jsonrpc.Request
+	require.NoError(a.t, a.decoder.Decode(&request))
+	return request
+}
+
+// replyTask answers request with a completed task echoing the requested task ID.
+func (a *connAgent) replyTask(request jsonrpc.Request) {
+	var params protocol.TaskQueryParams
+	require.NoError(a.t, json.Unmarshal(request.Params, &params))
+	task := protocol.NewTask(params.ID, nil)
+	task.Status.State = protocol.TaskStateCompleted
+	require.NoError(a.t, a.encoder.Encode(jsonrpc.NewResponse(request.ID, task)))
+}
+
+func TestConnClient_OutOfOrderResponses(t *testing.T) {
+	c, agent := newConnPair(t)
+
+	const calls = 3
+	go func() {
+		var requests []jsonrpc.Request
+		for i := 0; i < calls; i++ {
+			requests = append(requests, agent.read())
+		}
+		// Reply in reverse order of arrival.
+		for i := len(requests) - 1; i >= 0; i-- {
+			agent.replyTask(requests[i])
+		}
+	}()
+
+	var wg sync.WaitGroup
+	results := make([]*protocol.Task, calls)
+	errs := make([]error, calls)
+	taskIDs := []string{"task-a", "task-b", "task-c"}
+	for i, taskID := range taskIDs {
+		wg.Add(1)
+		go func(i int, taskID string) {
+			defer wg.Done()
+			results[i], errs[i] = c.GetTasks(context.Background(), protocol.TaskQueryParams{ID: taskID})
+		}(i, taskID)
+	}
+	wg.Wait()
+	for i, taskID := range taskIDs {
+		require.NoError(t, errs[i])
+		assert.Equal(t, taskID, results[i].ID, "call %d got another call's response", i)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	assert.Empty(t, c.pending)
+}
+
+func TestConnClient_Errors(t *testing.T) {
+	t.Run("JSONRPCError", func(t *testing.T) {
+		c, agent := newConnPair(t)
+		go func() {
+			request := agent.read()
+			agent.encoder.Encode(jsonrpc.NewErrorResponse(request.ID, jsonrpc.ErrMethodNotFound("no such method")))
+		}()
+		err := c.Call(context.Background(), "tasks/unknown", nil, nil)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeMethodNotFound, rpcErr.Code)
+	})
+
+	t.Run("CallTimeout", func(t *testing.T) {
+		c, agent := newConnPair(t, WithCallTimeout(20*time.Millisecond))
+		requests := make(chan jsonrpc.Request, 1)
+		go func() { requests <- agent.read() }()
+		_, err := c.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "slow-task"})
+		assert.ErrorIs(t, err, ErrCallTimeout)
+
+		// A late response for the timed-out call is dropped, and the connection stays usable.
+		go func() {
+			agent.replyTask(<-requests)
+			agent.replyTask(agent.read())
+		}()
+		task, err := c.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "next-task"})
+		require.NoError(t, err)
+		assert.Equal(t, "next-task", task.ID)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		c, agent := newConnPair(t)
+		go func() {
+			agent.read()
+			c.Close()
+		}()
+		_, err := c.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "task"})
+		assert.ErrorIs(t, err, ErrConnClosed)
+		_, err = c.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "task"})
+		assert.ErrorIs(t, err, ErrConnClosed)
+	})
+
+	t.Run("CustomRequestIDs", func(t *testing.T) {
+		c, agent := newConnPair(t, WithRequestIDGenerator(func() string { return "fixed" }))
+		go func() { agent.replyTask(agent.read()) }()
+		blocked := make(chan error, 1)
+		go func() {
+			_, err := c.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "first"})
+			blocked <- err
+		}()
+		require.NoError(t, <-blocked)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			request := agent.read()
+			assert.Equal(t, "fixed", request.ID)
+			// A second call reusing the id while the first is in flight is refused.
+			_, err := c.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "second"})
+			assert.ErrorContains(t, err, `request id "fixed" is already in flight`)
+			cancel()
+		}()
+		_, err := c.GetTasks(ctx, protocol.TaskQueryParams{ID: "first"})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("NilConn", func(t *testing.T) {
+		_, err := NewConnClient(nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,172 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+)
+
+// defaultDownloadAttempts is the number of requests a download makes at most when
+// the client has no retry policy with more attempts.
+const defaultDownloadAttempts = 3
+
+// maxDownloadErrorBody is the largest error response body a download reads.
+const maxDownloadErrorBody = 64 << 10
+
+// interruptedError is a download failure that a Range request can resume from.
+type interruptedError struct {
+	err error
+}
+
+func (e *interruptedError) Error() string { return e.err.Error() }
+
+func (e *interruptedError) Unwrap() error { return e.err }
+
+// readRecorder records the error of the reader of a copy, telling read failures
+// from write failures.
+type readRecorder struct {
+	r   io.Reader
+	err error
+}
+
+func (r *readRecorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// DownloadArtifact downloads the content at uri, usually the URI of a FilePart
+// served by the artifact content endpoint of the agent (see
+// server.WithArtifactContentEndpoint), to w and returns the number of bytes
+// written. A relative uri is resolved against the agent URL. When the connection
+// fails midway, the download resumes where it stopped with a Range request, made
+// conditional on the ETag of the content with If-Range so that content changed in
+// the meantime is not spliced together. Up to 3 requests are made, or the attempts
+// of WithRetry if more. Errors writing to w are not retried.
+func (c *A2AClient) DownloadArtifact(ctx context.Context, uri string, w io.Writer) (int64, error) {
+	ref, err := url.Parse(uri)
+	if err != nil {
+		return 0, fmt.Errorf("a2aClient.DownloadArtifact: invalid uri %q: %w", uri, err)
+	}
+	target := c.endpoint().ResolveReference(ref)
+	attempts := defaultDownloadAttempts
+	if c.retry != nil && c.retry.maxAttempts > attempts {
+		attempts = c.retry.maxAttempts
+	}
+	var written int64
+	var etag string
+	for attempt := 1; ; attempt++ {
+		n, tag, err := c.downloadFrom(ctx, target, w, written, etag)
+		written += n
+		if err == nil {
+			return written, nil
+		}
+		var interrupted *interruptedError
+		if !errors.As(err, &interrupted) || attempt >= attempts || ctx.Err() != nil {
+			return written, fmt.Errorf("a2aClient.DownloadArtifact: %w", err)
+		}
+		if etag == "" {
+			etag = tag
+		}
+		log.Debugf("A2A Client: download of %s interrupted after %d bytes, resuming: %v", target, written, err)
+	}
+}
+
+// downloadFrom writes the content at target from offset on to w, returning the
+// number of bytes written and the ETag of the content. A download from a nonzero
+// offset only accepts the content with etag, if known.
+func (c *A2AClient) downloadFrom(
+	ctx context.Context,
+	target *url.URL,
+	w io.Writer,
+	offset int64,
+	etag string,
+) (int64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create http request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if etag != "" {
+			req.Header.Set("If-Range", etag)
+		}
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	setBaggage(req)
+	release, err := c.limiter.acquireCall(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	defer release()
+	resp, err := c.do(req, true)
+	if err != nil {
+		return 0, "", &interruptedError{fmt.Errorf("http request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+	tag := resp.Header.Get("ETag")
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return 0, tag, fmt.Errorf("unexpected content range %q resuming at byte %d",
+				resp.Header.Get("Content-Range"), offset)
+		}
+		if etag != "" && tag != etag {
+			return 0, tag, errors.New("content changed since the download started")
+		}
+	case resp.StatusCode == http.StatusOK:
+		if offset > 0 {
+			// The server ignored the range: skip what was written, if it is the same content.
+			if etag == "" || tag != etag {
+				return 0, tag, errors.New("content changed since the download started")
+			}
+			if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+				return 0, tag, &interruptedError{fmt.Errorf("failed to read response body: %w", err)}
+			}
+		}
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxDownloadErrorBody))
+		return 0, tag, newHTTPError(resp, body)
+	}
+	src := &readRecorder{r: resp.Body}
+	n, err := io.Copy(w, src)
+	if err != nil {
+		if src.err != nil {
+			return n, tag, &interruptedError{fmt.Errorf("failed to read response body: %w", err)}
+		}
+		return n, tag, fmt.Errorf("failed to write content: %w", err)
+	}
+	return n, tag, nil
+}
+
+// contentRangeStart returns the first byte position of a Content-Range header
+// value such as "bytes 100-199/200".
+func contentRangeStart(value string) (int64, bool) {
+	spec, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	return start, err == nil
+}
@@ -0,0 +1,95 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+)
+
+// HTTPError is returned when the agent answers with a non-success HTTP status, such
+// as 429 or 503, so callers can back off as the agent asks. Find it with errors.As.
+// It unwraps to the JSON-RPC error in the body, or the UnauthorizedError of a 401.
+type HTTPError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Header holds the response headers relevant to backing off: Retry-After and
+	// the RateLimit-* and X-RateLimit-* headers.
+	Header http.Header
+	// Body is the response body.
+	Body string
+
+	rpcErr  *jsonrpc.Error     // JSON-RPC error in the body, if any.
+	authErr *UnauthorizedError // Set for 401 responses.
+}
+
+// newHTTPError creates the HTTPError for a non-success response with the given body.
+func newHTTPError(resp *http.Response, body []byte) *HTTPError {
+	e := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Header:     make(http.Header),
+		Body:       string(body),
+		rpcErr:     decodeRPCError(body),
+	}
+	for name, values := range resp.Header {
+		if name == "Retry-After" || strings.HasPrefix(name, "Ratelimit-") || strings.HasPrefix(name, "X-Ratelimit-") {
+			e.Header[name] = values
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		e.authErr = unauthorizedError(resp.Header)
+	}
+	return e
+}
+
+// Error implements error.
+func (e *HTTPError) Error() string {
+	if e.authErr != nil {
+		return fmt.Sprintf("unexpected http status %d: %v", e.StatusCode, e.authErr)
+	}
+	return fmt.Sprintf("unexpected http status %d: %s", e.StatusCode, e.Body)
+}
+
+// Unwrap returns the JSON-RPC error in the body or the UnauthorizedError, if any.
+func (e *HTTPError) Unwrap() error {
+	if e.rpcErr != nil {
+		return e.rpcErr
+	}
+	if e.authErr != nil {
+		return e.authErr
+	}
+	return nil
+}
+
+// RetryAfter returns how long the agent asked to wait before retrying, from the
+// Retry-After header given in seconds or as an HTTP date, and whether the header
+// was present and valid. A date in the past yields zero.
+func (e *HTTPError) RetryAfter() (time.Duration, bool) {
+	value := strings.TrimSpace(e.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := time.Until(date); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
@@ -0,0 +1,47 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// SendInput streams message to the running task taskID using the tasks/sendInput
+// method, for input the agent takes while it works, such as chunks of audio built
+// with protocol.NewAudioPart. Set final on the last message to end the input. The
+// agent answers once the input is handed to its processor, with the task's state;
+// what it makes of the input, such as the partial transcriptions read with
+// protocol.TranscriptionFromEvent, arrives on the task's stream. Calls for the
+// same task should not overlap, so the input arrives in order. SendInput is
+// never retried, since the agent cannot tell a repeated input apart.
+func (c *A2AClient) SendInput(
+	ctx context.Context,
+	taskID string,
+	message protocol.Message,
+	final bool,
+) (*protocol.Task, error) {
+	if err := c.signMessage(&message); err != nil {
+		return nil, fmt.Errorf("a2aClient.SendInput: %w", err)
+	}
+	params := protocol.TaskInputParams{ID: taskID, Message: message, Final: final}
+	request := jsonrpc.NewRequest(protocol.MethodTasksSendInput, c.nextRequestID.Add(1))
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.SendInput: failed to marshal params: %w", err)
+	}
+	request.Params = paramsBytes
+	task, err := c.doRequestAndDecodeTask(ctx, request, nil)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.SendInput: %w", err)
+	}
+	return task, nil
+}
@@ -0,0 +1,100 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// ConcurrencyLimiter caps the requests in flight across every client it is shared
+// with. Short calls and streaming subscriptions have separate caps, so long-lived
+// streams cannot starve short calls: a stream holds a stream slot until its event
+// channel is closed, and a call slot only while it is being established.
+// Requests over a cap wait for a free slot until their context is done.
+// It is safe for concurrent use.
+type ConcurrencyLimiter struct {
+	calls   chan struct{} // Nil for no limit.
+	streams chan struct{} // Nil for no limit.
+}
+
+// NewConcurrencyLimiter creates a limiter allowing at most maxCalls short calls
+// and maxStreams streaming subscriptions in flight. A value of zero or less
+// leaves that kind of request unlimited.
+func NewConcurrencyLimiter(maxCalls, maxStreams int) *ConcurrencyLimiter {
+	l := &ConcurrencyLimiter{}
+	if maxCalls > 0 {
+		l.calls = make(chan struct{}, maxCalls)
+	}
+	if maxStreams > 0 {
+		l.streams = make(chan struct{}, maxStreams)
+	}
+	return l
+}
+
+// InFlight returns the number of short calls and streams currently holding a slot.
+func (l *ConcurrencyLimiter) InFlight() (calls, streams int) {
+	if l == nil {
+		return 0, 0
+	}
+	return len(l.calls), len(l.streams)
+}
+
+// acquireCall waits for a call slot and returns the function releasing it.
+func (l *ConcurrencyLimiter) acquireCall(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	return acquire(ctx, l.calls)
+}
+
+// acquireStream waits for a stream slot and returns the function releasing it.
+func (l *ConcurrencyLimiter) acquireStream(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	return acquire(ctx, l.streams)
+}
+
+// acquire takes a slot of the semaphore slots, or returns at once if it is nil.
+func acquire(ctx context.Context, slots chan struct{}) (func(), error) {
+	if slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free request slot: %w", ctx.Err())
+	}
+}
+
+// releaseOnClose forwards events and calls release once events is closed. Once
+// ctx is done the events are no longer forwarded, so a consumer that stopped
+// reading cannot hold the slot: they are drained until the stream closes the
+// channel, which it does as ctx ends.
+func releaseOnClose(
+	ctx context.Context, events <-chan protocol.TaskEvent, release func(),
+) <-chan protocol.TaskEvent {
+	out := make(chan protocol.TaskEvent, cap(events))
+	go func() {
+		defer release()
+		defer close(out)
+		for event := range events {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				for range events {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
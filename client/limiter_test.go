@@ -0,0 +1,213 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// concurrencyAgent answers tasks/get slowly and holds streams open until released,
+// recording the most short calls it served at once.
+type concurrencyAgent struct {
+	release chan struct{} // Closing it ends the open streams.
+
+	mu          sync.Mutex
+	calls       int
+	maxCalls    int
+	openStreams int
+}
+
+func newConcurrencyAgent(t *testing.T) (*concurrencyAgent, *httptest.Server) {
+	agent := &concurrencyAgent{release: make(chan struct{})}
+	server := httptest.NewServer(agent)
+	t.Cleanup(server.Close)
+	return agent, server
+}
+
+func (a *concurrencyAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request jsonrpc.Request
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.Method == protocol.MethodTasksSendSubscribe {
+		a.serveStream(w, r, request)
+		return
+	}
+	a.mu.Lock()
+	a.calls++
+	if a.calls > a.maxCalls {
+		a.maxCalls = a.calls
+	}
+	a.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	a.mu.Lock()
+	a.calls--
+	a.mu.Unlock()
+	task := protocol.NewTask(fmt.Sprint(request.ID), nil)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(jsonrpc.NewResponse(request.ID, task))
+}
+
+func (a *concurrencyAgent) serveStream(w http.ResponseWriter, r *http.Request, request jsonrpc.Request) {
+	a.mu.Lock()
+	a.openStreams++
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.openStreams--
+		a.mu.Unlock()
+	}()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+	select {
+	case <-a.release:
+	case <-r.Context().Done():
+		return
+	}
+	data, _ := json.Marshal(protocol.TaskStatusUpdateEvent{
+		ID:     fmt.Sprint(request.ID),
+		Status: protocol.TaskStatus{State: protocol.TaskStateCompleted},
+		Final:  true,
+	})
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", protocol.EventTaskStatusUpdate, data)
+	w.(http.Flusher).Flush()
+}
+
+func (a *concurrencyAgent) stats() (maxCalls, openStreams int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.maxCalls, a.openStreams
+}
+
+// getTasks issues n concurrent tasks/get calls through the clients in turn.
+func getTasks(t *testing.T, n int, clients ...*A2AClient) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := clients[i%len(clients)].GetTasks(context.Background(), protocol.TaskQueryParams{
+				ID: fmt.Sprintf("task-%d", i),
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestA2AClient_MaxConcurrency(t *testing.T) {
+	t.Run("CapsCalls", func(t *testing.T) {
+		agent, server := newConcurrencyAgent(t)
+		client, err := NewA2AClient(server.URL, WithMaxConcurrency(3))
+		require.NoError(t, err)
+		getTasks(t, 30, client)
+		maxCalls, _ := agent.stats()
+		assert.LessOrEqual(t, maxCalls, 3)
+		calls, streams := client.limiter.InFlight()
+		assert.Zero(t, calls+streams, "every slot should be released")
+	})
+
+	t.Run("SharedAcrossClients", func(t *testing.T) {
+		// Two agents sharing one recorder, so it sees the calls to both at once.
+		agent, serverA := newConcurrencyAgent(t)
+		serverB := httptest.NewServer(agent)
+		defer serverB.Close()
+		limiter := NewConcurrencyLimiter(4, 0)
+		clientA, err := NewA2AClient(serverA.URL, WithConcurrencyLimiter(limiter))
+		require.NoError(t, err)
+		clientB, err := NewA2AClient(serverB.URL, WithConcurrencyLimiter(limiter))
+		require.NoError(t, err)
+		getTasks(t, 40, clientA, clientB)
+		maxCalls, _ := agent.stats()
+		assert.LessOrEqual(t, maxCalls, 4)
+	})
+
+	t.Run("BlockedCallRespectsContext", func(t *testing.T) {
+		_, server := newConcurrencyAgent(t)
+		limiter := NewConcurrencyLimiter(1, 0)
+		client, err := NewA2AClient(server.URL, WithConcurrencyLimiter(limiter))
+		require.NoError(t, err)
+		release, err := limiter.acquireCall(context.Background())
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = client.GetTasks(ctx, protocol.TaskQueryParams{ID: "blocked"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("StreamsCountSeparately", func(t *testing.T) {
+		agent, server := newConcurrencyAgent(t)
+		client, err := NewA2AClient(server.URL, WithMaxConcurrency(1))
+		require.NoError(t, err)
+		message := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")})
+		events, err := client.StreamTask(context.Background(), protocol.SendTaskParams{ID: "stream-1", Message: message})
+		require.NoError(t, err)
+		calls, streams := client.limiter.InFlight()
+		assert.Equal(t, 0, calls, "an open stream should not hold a call slot")
+		assert.Equal(t, 1, streams)
+
+		// Short calls still go through while the stream is open.
+		getTasks(t, 3, client)
+
+		// A second stream waits for the first to finish.
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = client.StreamTask(ctx, protocol.SendTaskParams{ID: "stream-2", Message: message})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		_, openStreams := agent.stats()
+		assert.Equal(t, 1, openStreams)
+
+		close(agent.release)
+		for range events {
+		}
+		require.Eventually(t, func() bool {
+			_, streams := client.limiter.InFlight()
+			return streams == 0
+		}, time.Second, time.Millisecond)
+		events, err = client.StreamTask(context.Background(), protocol.SendTaskParams{ID: "stream-3", Message: message})
+		require.NoError(t, err)
+		for range events {
+		}
+	})
+
+	t.Run("AbandonedStreamReleasedOnCancel", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(0, 1)
+		release, err := limiter.acquireStream(context.Background())
+		require.NoError(t, err)
+		upstream := make(chan protocol.TaskEvent)
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = releaseOnClose(ctx, upstream, release) // The consumer never reads.
+		go func() {
+			upstream <- protocol.TaskStatusUpdateEvent{ID: "abandoned"}
+			<-ctx.Done()
+			close(upstream) // As the stream does once its context ends.
+		}()
+
+		cancel()
+		require.Eventually(t, func() bool {
+			_, streams := limiter.InFlight()
+			return streams == 0
+		}, time.Second, time.Millisecond, "the stream slot should be released")
+	})
+}
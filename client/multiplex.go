@@ -0,0 +1,115 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// StreamTasks subscribes to the events of several existing tasks over a single
+// stream using tasks/subscribeMultiple. Events of different tasks are interleaved;
+// use TaskID on each event to tell them apart.
+// The returned channel is closed once every task sent its final status, or when
+// the stream ends otherwise. If it breaks early, each unfinished task gets a
+// protocol.TaskStreamErrorEvent.
+func (c *A2AClient) StreamTasks(
+	ctx context.Context,
+	ids []string,
+	opts ...StreamOption,
+) (<-chan protocol.TaskEvent, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("a2aClient.StreamTasks: at least one task ID is required")
+	}
+	streamOpts := &streamOptions{eventFilter: protocol.StreamEventFilterAll}
+	for _, opt := range opts {
+		opt(streamOpts)
+	}
+	release, err := c.limiter.acquireStream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTasks: %w", err)
+	}
+	events, err := c.streamTasks(ctx, ids, streamOpts)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return releaseOnClose(ctx, events, release), nil
+}
+
+// streamTasks implements StreamTasks once the stream slot, if any, is held.
+func (c *A2AClient) streamTasks(
+	ctx context.Context,
+	ids []string,
+	streamOpts *streamOptions,
+) (<-chan protocol.TaskEvent, error) {
+	request := jsonrpc.NewRequest(protocol.MethodTasksSubscribeMultiple, strings.Join(ids, ","))
+	paramsBytes, err := json.Marshal(protocol.TaskIDsParams{IDs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTasks: failed to marshal params: %w", err)
+	}
+	request.Params = paramsBytes
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTasks: failed to marshal request body: %w", err)
+	}
+	c.logRequestBody(reqBody)
+	targetURL := c.endpoint().String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTasks: failed to create http request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "text/event-stream")
+	if streamOpts.eventFilter != protocol.StreamEventFilterAll {
+		req.Header.Set(protocol.HeaderStreamEventFilter, string(streamOpts.eventFilter))
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	log.Debugf("A2A Client Stream Request -> Method: %s, ID: %v, URL: %s", request.Method, request.ID, targetURL)
+	release, err := c.limiter.acquireCall(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTasks: %w", err)
+	}
+	resp, err := c.do(req, retrySafe(protocol.MethodTasksSubscribeMultiple, &sendOptions{}))
+	release()
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.StreamTasks: http request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("a2aClient.StreamTasks: establishing stream: %w", newHTTPError(resp, bodyBytes))
+	}
+	// Errors such as an unknown task come back as a plain JSON-RPC response.
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream") {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if rpcErr := decodeRPCError(bodyBytes); rpcErr != nil {
+			return nil, fmt.Errorf("a2aClient.StreamTasks: %w", rpcErr)
+		}
+		return nil, fmt.Errorf(
+			"a2aClient.StreamTasks: server did not respond with Content-Type 'text/event-stream', got %s",
+			resp.Header.Get("Content-Type"),
+		)
+	}
+	log.Debugf("A2A Client Stream Response <- Status: %d, ID: %v. Stream established.", resp.StatusCode, request.ID)
+	eventsChan := make(chan protocol.TaskEvent, 10)
+	go c.processSSEStream(ctx, resp, ids, eventsChan, streamOpts.eventFilter)
+	return eventsChan, nil
+}
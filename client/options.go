@@ -7,11 +7,14 @@
 package client
 
 import (
+	"crypto/tls"
 	"net/http"
 	"time"
 
 	"golang.org/x/oauth2"
 	"trpc.group/trpc-go/trpc-a2a-go/auth"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
 
 // Option is a functional option type for configuring the A2AClient.
@@ -22,6 +25,8 @@ func WithHTTPClient(client *http.Client) Option {
 	return func(c *A2AClient) {
 		if client != nil {
 			c.httpClient = client
+			c.plainClient = nil
+			c.timeoutSet = true
 		}
 	}
 }
@@ -32,10 +37,229 @@ func WithTimeout(timeout time.Duration) Option {
 	return func(c *A2AClient) {
 		if timeout > 0 && c.httpClient != nil {
 			c.httpClient.Timeout = timeout
+			c.timeoutSet = true
 		}
 	}
 }
 
+// WithAgentCardTimeout makes NewA2AClient fetch the agent card and use the
+// processing time it advertises in the protocol.ExtensionProcessingTime extension
+// as the HTTP timeout. It has no effect when a timeout is given with WithTimeout or
+// a client with WithHTTPClient. The default of 60 seconds is kept if the card cannot
+// be fetched or has no hint.
+func WithAgentCardTimeout() Option {
+	return func(c *A2AClient) {
+		c.cardTimeout = true
+	}
+}
+
+// WithTLSServerName verifies the agent's certificate against serverName instead of
+// the host in the agent URL, and sends it as the TLS SNI, for example when a load
+// balancer presents a certificate for another name. Certificate verification stays
+// enabled. It applies to the transport of the client configured so far, so give it
+// after WithHTTPClient and before any authentication option.
+func WithTLSServerName(serverName string) Option {
+	return func(c *A2AClient) {
+		if serverName == "" {
+			return
+		}
+		configureTLS(c, "WithTLSServerName", func(config *tls.Config) {
+			config.ServerName = serverName
+		})
+	}
+}
+
+// WithCertPinning rejects agents whose certificate chain contains none of the
+// given SHA-256 hashes of a DER-encoded SubjectPublicKeyInfo, so a certificate
+// that is valid but was not expected fails the TLS handshake with
+// ErrCertificatePinMismatch. Pinning adds to the usual verification, which stays
+// enabled. Like WithTLSServerName, it applies to the transport of the client
+// configured so far.
+func WithCertPinning(spkiSHA256 ...[]byte) Option {
+	return func(c *A2AClient) {
+		if len(spkiSHA256) == 0 {
+			return
+		}
+		configureTLS(c, "WithCertPinning", func(config *tls.Config) {
+			config.VerifyPeerCertificate = pinVerifier(spkiSHA256, config.VerifyPeerCertificate)
+		})
+	}
+}
+
+// configureTLS lets configure change the TLS config of a copy of the client's
+// transport, leaving a transport shared with other clients untouched.
+func configureTLS(c *A2AClient, option string, configure func(config *tls.Config)) {
+	if c.httpClient == nil {
+		return
+	}
+	var transport *http.Transport
+	switch base := c.httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = base.Clone()
+	default:
+		log.Warnf("%s: transport %T is not an *http.Transport, TLS config not changed", option, base)
+		return
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	configure(transport.TLSClientConfig)
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+}
+
+// WithStreamIdleTimeout sets the maximum time to wait for data (an event or a
+// heartbeat) on an SSE stream. If the stream stays silent for longer, it is treated
+// as dead: a protocol.TaskStreamErrorEvent wrapping ErrStreamIdleTimeout is delivered
+// and the event channel is closed. This is independent of the overall
+// request timeout. A zero value disables the idle timeout.
+func WithStreamIdleTimeout(timeout time.Duration) Option {
+	return func(c *A2AClient) {
+		if timeout >= 0 {
+			c.streamIdleTimeout = timeout
+		}
+	}
+}
+
+// WithStreamFallback makes StreamTask fall back to tasks/send followed by polling
+// tasks/get every pollInterval when the agent does not support streaming.
+// The agent card is checked once per client; when it does not advertise streaming,
+// polling is used directly. Otherwise the fallback happens only if the agent answers
+// with method-not-found or a plain task result; other errors are returned as is.
+// Status and artifact events are synthesized from the polled task, and the channel
+// is closed once the task reaches a final state. A failed poll is reported as a
+// protocol.TaskStreamErrorEvent. A zero value disables the fallback.
+func WithStreamFallback(pollInterval time.Duration) Option {
+	return func(c *A2AClient) {
+		if pollInterval >= 0 {
+			c.streamFallback = pollInterval
+		}
+	}
+}
+
+// WithAgentCardVerificationKey makes GetAgentCard request the JWS-signed agent card
+// and verify it against the agent's public key. Unsigned cards are rejected.
+// See protocol.VerifyAgentCard for the supported key types.
+func WithAgentCardVerificationKey(key interface{}) Option {
+	return func(c *A2AClient) {
+		c.agentCardKey = key
+	}
+}
+
+// WithAgentCardCache caches the agent card fetched by GetAgentCard as allowed by
+// the Cache-Control header of the agent's response. The card is served from the
+// cache for max-age; for a further stale-while-revalidate period the stale card is
+// still served at once while a fresh one is fetched in the background. If that
+// fetch fails, the stale card stays usable for another such period. Responses
+// without max-age, or marked no-store or no-cache, are not cached.
+func WithAgentCardCache() Option {
+	return func(c *A2AClient) {
+		c.cardCache = newAgentCardCache()
+	}
+}
+
+// WithMessageSigningKey signs the message of every task sent with SendTasks or
+// StreamTask with key using protocol.SignMessage, for servers that verify message
+// signatures. key is an *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey.
+func WithMessageSigningKey(key interface{}) Option {
+	return func(c *A2AClient) {
+		c.messageKey = key
+	}
+}
+
+// WithPreflight makes NewA2AClient fetch the agent card and make one read-only,
+// authenticated call before returning, so an unreachable agent, a bad URL or
+// rejected credentials fail at construction instead of on the first call.
+// Rejected credentials are reported as ErrPreflightUnauthorized. The requests use
+// the client's HTTP timeout.
+func WithPreflight() Option {
+	return func(c *A2AClient) {
+		c.preflight = true
+	}
+}
+
+// WithMaxConcurrency caps the client's requests in flight at n short calls and,
+// separately, n streaming subscriptions. Requests over the cap block until a slot
+// frees or their context is done. Use WithConcurrencyLimiter to share a cap across
+// clients. A value of zero or less removes the cap.
+func WithMaxConcurrency(n int) Option {
+	return func(c *A2AClient) {
+		c.limiter = nil
+		if n > 0 {
+			c.limiter = NewConcurrencyLimiter(n, n)
+		}
+	}
+}
+
+// WithConcurrencyLimiter makes the client take its request slots from limiter,
+// which may be shared with other clients to cap requests across agents.
+func WithConcurrencyLimiter(limiter *ConcurrencyLimiter) Option {
+	return func(c *A2AClient) {
+		c.limiter = limiter
+	}
+}
+
+// WithRetry sends a call up to maxAttempts times in all while it fails with a
+// network error or a 408, 429, 502, 503 or 504 reply, waiting backoff before the
+// first retry and doubling it for each further one, or as long as a Retry-After
+// header asks. Only calls that read are retried, and tasks/send with
+// WithIdempotencyKey, so a task is not started twice: tasks/sendBatch,
+// tasks/import, tasks/cancel and custom methods, among others, are not. Streams
+// are not retried; see NewResilientStream.
+// A maxAttempts of one or less disables retries.
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(c *A2AClient) {
+		c.retry = nil
+		if maxAttempts > 1 {
+			c.retry = &retryPolicy{maxAttempts: maxAttempts, backoff: backoff}
+		}
+	}
+}
+
+// WithRetryBudget caps the retries of WithRetry across all calls of the client, so
+// a broad outage does not multiply the load on the agent: each call earns ratio
+// retries, e.g. 0.1 for one retry per ten calls, and minPerSec retries are allowed
+// every second regardless. Once the budget is spent, failed calls return their
+// error at once instead of retrying.
+func WithRetryBudget(ratio float64, minPerSec int) Option {
+	return func(c *A2AClient) {
+		c.retryBudget = newRetryBudget(ratio, minPerSec)
+	}
+}
+
+// WithEndpointEjection sets the passive health checks of a client created with
+// NewBalancedClient: an endpoint failing failures calls in a row is taken out of
+// rotation for duration. The default is 3 failures and 30 seconds.
+func WithEndpointEjection(failures int, duration time.Duration) Option {
+	return func(c *A2AClient) {
+		if failures > 0 && duration > 0 {
+			c.ejection = &ejectionPolicy{failures: failures, duration: duration}
+		}
+	}
+}
+
+// WithRedirectPolicy sets the redirects of the agent endpoint the client follows,
+// for example when an agent that moved answers with a 307 or 308. The default
+// follows up to 10 redirects, to other origins without credentials. The client
+// follows redirects itself, so the CheckRedirect of WithHTTPClient is not used.
+func WithRedirectPolicy(policy RedirectPolicy) Option {
+	return func(c *A2AClient) {
+		c.redirect = policy
+	}
+}
+
+// WithBodyLogging logs the body of every JSON-RPC request and response, and every
+// SSE event, at debug level. Secrets such as tokens and passwords are redacted, but
+// keep it off, the default, outside of debugging.
+func WithBodyLogging() Option {
+	return func(c *A2AClient) {
+		c.logBodies = true
+	}
+}
+
 // WithUserAgent sets a custom User-Agent header for requests.
 func WithUserAgent(userAgent string) Option {
 	return func(c *A2AClient) {
@@ -43,6 +267,64 @@ func WithUserAgent(userAgent string) Option {
 	}
 }
 
+// SendOption is a functional option type for configuring a single SendTasks call.
+type SendOption func(*sendOptions)
+
+// sendOptions holds the per-call settings for SendTasks.
+type sendOptions struct {
+	idempotencyKey string
+	requestID      string
+	uploadProgress UploadProgressFunc
+	ifNoneMatch    string  // Sent as If-None-Match; a 304 reply yields ErrTaskNotModified.
+	etag           *string // Receives the response's ETag header, if set.
+}
+
+// WithIdempotencyKey sends the key in the Idempotency-Key header so that retries of
+// the same request return the existing task instead of starting duplicate work.
+// Reuse the same key only for retries of the same logical request.
+func WithIdempotencyKey(key string) SendOption {
+	return func(o *sendOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithRequestID sends id as the JSON-RPC request ID instead of the task ID. The
+// server records it on the task the request creates, as protocol.Task.RequestID,
+// and in its logs, so a tracing ID can correlate the logs of both sides.
+func WithRequestID(id string) SendOption {
+	return func(o *sendOptions) {
+		o.requestID = id
+	}
+}
+
+// WithUploadProgress reports progress while the request body is uploaded, e.g. to
+// show progress for messages carrying large file parts. progress is called from the
+// goroutine sending the request with the bytes sent so far and the total size.
+func WithUploadProgress(progress UploadProgressFunc) SendOption {
+	return func(o *sendOptions) {
+		o.uploadProgress = progress
+	}
+}
+
+// StreamOption is a functional option type for configuring a single StreamTask call.
+type StreamOption func(*streamOptions)
+
+// streamOptions holds the per-call settings for StreamTask.
+type streamOptions struct {
+	eventFilter protocol.StreamEventFilter
+}
+
+// WithEventFilter restricts the stream to the given event types. The filter is
+// applied client-side and also sent to the server so it can skip unwanted events.
+// The stream still ends when the task reaches a final state.
+func WithEventFilter(filter protocol.StreamEventFilter) StreamOption {
+	return func(o *streamOptions) {
+		if filter != "" {
+			o.eventFilter = filter
+		}
+	}
+}
+
 // Authentication options
 
 // WithJWTAuth configures the client to use JWT authentication.
@@ -50,6 +332,7 @@ func WithJWTAuth(secret []byte, audience, issuer string, lifetime time.Duration)
 	return func(c *A2AClient) {
 		provider := auth.NewJWTAuthProvider(secret, audience, issuer, lifetime)
 		c.authProvider = provider
+		c.keepPlainClient()
 		c.httpClient = provider.ConfigureClient(c.httpClient)
 	}
 }
@@ -60,6 +343,7 @@ func WithAPIKeyAuth(apiKey, headerName string) Option {
 		provider := auth.NewAPIKeyAuthProvider(make(map[string]string), headerName)
 		provider.SetClientAPIKey(apiKey)
 		c.authProvider = provider
+		c.keepPlainClient()
 		c.httpClient = provider.ConfigureClient(c.httpClient)
 	}
 }
@@ -74,6 +358,42 @@ func WithOAuth2ClientCredentials(clientID, clientSecret, tokenURL string, scopes
 			scopes,
 		)
 		c.authProvider = provider
+		c.authBaseClient = c.httpClient
+		c.keepPlainClient()
+		c.httpClient = provider.ConfigureClient(c.httpClient)
+	}
+}
+
+// WithTokenCache shares OAuth2 client credentials tokens through cache, so client
+// instances created with the same credentials and cache reuse a valid token instead
+// of fetching a new one each time. Use auth.NewMemoryTokenCache for an in-process cache.
+// It applies to WithOAuth2ClientCredentials and may be given in any order.
+func WithTokenCache(cache auth.TokenCache) Option {
+	return func(c *A2AClient) {
+		c.tokenCache = cache
+	}
+}
+
+// WithOAuth2TokenRetry makes the OAuth2 provider try a failed token fetch again, up
+// to maxAttempts times in all, while the token endpoint cannot be reached or answers
+// with a 429 or 5xx status, waiting backoff before the first retry and doubling the
+// wait each time. Errors such as invalid_client are returned at once. It is separate
+// from WithRetry, applies to the OAuth2 options and may be given in any order.
+func WithOAuth2TokenRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(c *A2AClient) {
+		c.tokenRetry = &retryPolicy{maxAttempts: maxAttempts, backoff: backoff}
+	}
+}
+
+// WithOAuth2AuthCode configures the client to use tokens obtained via the OAuth2
+// authorization code flow (e.g. with PKCE). Tokens are loaded from tokenStore and
+// refreshed via the refresh token when expired; refreshed tokens are saved back.
+func WithOAuth2AuthCode(config *oauth2.Config, tokenStore auth.TokenStore) Option {
+	return func(c *A2AClient) {
+		provider := auth.NewOAuth2AuthCodeProvider(config, tokenStore)
+		c.authProvider = provider
+		c.authBaseClient = c.httpClient
+		c.keepPlainClient()
 		c.httpClient = provider.ConfigureClient(c.httpClient)
 	}
 }
@@ -84,6 +404,8 @@ func WithOAuth2TokenSource(config *oauth2.Config, tokenSource oauth2.TokenSource
 		provider := auth.NewOAuth2AuthProviderWithConfig(config, "", "")
 		provider.SetTokenSource(tokenSource)
 		c.authProvider = provider
+		c.authBaseClient = c.httpClient
+		c.keepPlainClient()
 		c.httpClient = provider.ConfigureClient(c.httpClient)
 	}
 }
@@ -92,6 +414,15 @@ func WithOAuth2TokenSource(config *oauth2.Config, tokenSource oauth2.TokenSource
 func WithAuthProvider(provider auth.ClientProvider) Option {
 	return func(c *A2AClient) {
 		c.authProvider = provider
+		c.keepPlainClient()
 		c.httpClient = provider.ConfigureClient(c.httpClient)
 	}
 }
+
+// keepPlainClient records the HTTP client before the first auth option wraps it,
+// for redirects to other origins.
+func (c *A2AClient) keepPlainClient() {
+	if c.plainClient == nil {
+		c.plainClient = c.httpClient
+	}
+}
@@ -7,11 +7,25 @@
 package client
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
 )
 
 func TestWithHTTPClient(t *testing.T) {
@@ -84,3 +98,152 @@ func TestWithUserAgent(t *testing.T) {
 	WithUserAgent("")(client)
 	assert.Equal(t, "", client.userAgent)
 }
+
+func TestWithOAuth2AuthCode(t *testing.T) {
+	client := &A2AClient{
+		httpClient: &http.Client{},
+	}
+	config := &oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: "https://example.com/token"}}
+	store := auth.NewMemoryTokenStore(&oauth2.Token{
+		AccessToken: "stored-token",
+		Expiry:      time.Now().Add(time.Hour),
+	})
+
+	WithOAuth2AuthCode(config, store)(client)
+
+	assert.IsType(t, &auth.OAuth2AuthProvider{}, client.authProvider)
+	assert.NotNil(t, client.httpClient.Transport, "HTTP client should use an OAuth2 transport")
+}
+
+func TestWithStreamIdleTimeout(t *testing.T) {
+	client := &A2AClient{}
+
+	WithStreamIdleTimeout(5 * time.Second)(client)
+	assert.Equal(t, 5*time.Second, client.streamIdleTimeout)
+
+	// Negative values are ignored.
+	WithStreamIdleTimeout(-time.Second)(client)
+	assert.Equal(t, 5*time.Second, client.streamIdleTimeout)
+
+	// Zero disables the idle timeout.
+	WithStreamIdleTimeout(0)(client)
+	assert.Equal(t, time.Duration(0), client.streamIdleTimeout)
+}
+
+func TestWithTLSServerName(t *testing.T) {
+	// The certificate is only valid for agent.internal, not the 127.0.0.1 being dialed.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "agent.internal"},
+		DNSNames:              []string{"agent.internal"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	var gotServerName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotServerName = r.TLS.ServerName
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"tls agent"}`))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	server.StartTLS()
+	defer server.Close()
+
+	newClient := func(opts ...Option) *A2AClient {
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		client, err := NewA2AClient(server.URL, append([]Option{WithHTTPClient(httpClient)}, opts...)...)
+		require.NoError(t, err)
+		return client
+	}
+	var card map[string]interface{}
+
+	t.Run("MismatchedHostRejected", func(t *testing.T) {
+		err := newClient().GetAgentCard(context.Background(), &card)
+		var hostErr x509.HostnameError
+		assert.ErrorAs(t, err, &hostErr)
+	})
+
+	t.Run("OverrideVerifies", func(t *testing.T) {
+		require.NoError(t, newClient(WithTLSServerName("agent.internal")).GetAgentCard(context.Background(), &card))
+		assert.Equal(t, "tls agent", card["name"])
+		assert.Equal(t, "agent.internal", gotServerName, "the override should be sent as SNI")
+	})
+
+	t.Run("WrongOverrideRejected", func(t *testing.T) {
+		err := newClient(WithTLSServerName("other.internal")).GetAgentCard(context.Background(), &card)
+		var hostErr x509.HostnameError
+		assert.ErrorAs(t, err, &hostErr, "verification must stay enabled")
+	})
+
+	t.Run("DoesNotModifySharedTransport", func(t *testing.T) {
+		client := &A2AClient{httpClient: &http.Client{}}
+		WithTLSServerName("agent.internal")(client)
+		transport, ok := client.httpClient.Transport.(*http.Transport)
+		require.True(t, ok)
+		assert.Equal(t, "agent.internal", transport.TLSClientConfig.ServerName)
+		assert.False(t, transport.TLSClientConfig.InsecureSkipVerify)
+		if shared := http.DefaultTransport.(*http.Transport).TLSClientConfig; shared != nil {
+			assert.Empty(t, shared.ServerName)
+		}
+	})
+}
+
+func TestWithCertPinning(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"pinned agent"}`))
+	}))
+	defer server.Close()
+	pin := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	otherPin := sha256.Sum256([]byte("another key"))
+
+	getCard := func(httpClient *http.Client, opts ...Option) (map[string]interface{}, error) {
+		client, err := NewA2AClient(server.URL, append([]Option{WithHTTPClient(httpClient)}, opts...)...)
+		require.NoError(t, err)
+		var card map[string]interface{}
+		err = client.GetAgentCard(context.Background(), &card)
+		return card, err
+	}
+
+	t.Run("MatchingPin", func(t *testing.T) {
+		card, err := getCard(server.Client(), WithCertPinning(otherPin[:], pin[:]))
+		require.NoError(t, err)
+		assert.Equal(t, "pinned agent", card["name"])
+	})
+
+	t.Run("MismatchedPinFailsHandshake", func(t *testing.T) {
+		_, err := getCard(server.Client(), WithCertPinning(otherPin[:]))
+		assert.ErrorIs(t, err, ErrCertificatePinMismatch)
+	})
+
+	t.Run("VerificationStaysEnabled", func(t *testing.T) {
+		// The pin matches, but the certificate is not signed by a trusted root.
+		_, err := getCard(&http.Client{}, WithCertPinning(pin[:]))
+		var authErr x509.UnknownAuthorityError
+		assert.ErrorAs(t, err, &authErr)
+	})
+
+	t.Run("DoesNotModifySharedTransport", func(t *testing.T) {
+		client := &A2AClient{httpClient: &http.Client{}}
+		WithCertPinning(pin[:])(client)
+		transport, ok := client.httpClient.Transport.(*http.Transport)
+		require.True(t, ok)
+		assert.NotNil(t, transport.TLSClientConfig.VerifyPeerCertificate)
+		if shared := http.DefaultTransport.(*http.Transport).TLSClientConfig; shared != nil {
+			assert.Nil(t, shared.VerifyPeerCertificate)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// ErrCertificatePinMismatch is returned when the agent's certificate chain matches
+// none of the pins given to WithCertPinning.
+var ErrCertificatePinMismatch = errors.New("certificate matches no pinned public key")
+
+// peerVerifier is the signature of tls.Config.VerifyPeerCertificate.
+type peerVerifier func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error
+
+// pinVerifier returns a peerVerifier accepting chains with a certificate whose
+// SubjectPublicKeyInfo hashes to one of pins, after running next if set.
+func pinVerifier(pins [][]byte, next peerVerifier) peerVerifier {
+	pins = append([][]byte(nil), pins...)
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if next != nil {
+			if err := next(rawCerts, verifiedChains); err != nil {
+				return err
+			}
+		}
+		// Only trust the chains verification built. Without verification, as with
+		// InsecureSkipVerify, only the leaf is known to belong to the peer.
+		chains := verifiedChains
+		if len(chains) == 0 {
+			if len(rawCerts) == 0 {
+				return ErrCertificatePinMismatch
+			}
+			leaf, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return fmt.Errorf("failed to parse agent certificate: %w", err)
+			}
+			chains = [][]*x509.Certificate{{leaf}}
+		}
+		for _, chain := range chains {
+			for _, cert := range chain {
+				sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+				for _, pin := range pins {
+					if bytes.Equal(sum[:], pin) {
+						return nil
+					}
+				}
+			}
+		}
+		return ErrCertificatePinMismatch
+	}
+}
@@ -0,0 +1,40 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import "io"
+
+// UploadProgressFunc is called as a request body is sent. bytesSent is the number of
+// bytes sent so far and total is the body size, or -1 if it is unknown.
+type UploadProgressFunc func(bytesSent, total int64)
+
+// progressReader wraps a request body and reports how much of it has been read.
+type progressReader struct {
+	r        io.Reader
+	total    int64
+	sent     int64
+	progress UploadProgressFunc
+}
+
+// newProgressReader wraps r, reporting progress against total. A non-positive total
+// is reported as unknown.
+func newProgressReader(r io.Reader, total int64, progress UploadProgressFunc) *progressReader {
+	if total <= 0 {
+		total = -1
+	}
+	return &progressReader{r: r, total: total, progress: progress}
+}
+
+// Read implements io.Reader.
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent, p.total)
+	}
+	return n, err
+}
@@ -0,0 +1,154 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+)
+
+// defaultMaxRedirects is the number of redirects a call follows by default.
+const defaultMaxRedirects = 10
+
+// credentialHeaders are the request headers dropped from a redirect to another
+// origin, as net/http does.
+var credentialHeaders = []string{"Authorization", "Cookie", "Cookie2", "WWW-Authenticate"}
+
+// RedirectPolicy selects the redirects of the agent endpoint a client follows, see
+// WithRedirectPolicy. Only calls safe to send twice follow redirects: the calls
+// that read and tasks/send with an idempotency key, as for WithRetry.
+// JSON-RPC calls follow 307 and 308 redirects, which keep the method and body;
+// fetching the agent card follows any redirect.
+type RedirectPolicy struct {
+	// MaxRedirects bounds the redirects a call follows before the last redirect
+	// response is returned as an *HTTPError. Zero means 10 and a negative value
+	// follows none.
+	MaxRedirects int
+	// SameOriginOnly refuses redirects to another origin (scheme, host and port).
+	// Otherwise they are followed without the credentials of the client's
+	// authentication options, which are only sent to the origin of the agent URL.
+	SameOriginOnly bool
+}
+
+// maxRedirects returns the number of redirects a call may follow.
+func (p RedirectPolicy) maxRedirects() int {
+	switch {
+	case p.MaxRedirects < 0:
+		return 0
+	case p.MaxRedirects == 0:
+		return defaultMaxRedirects
+	default:
+		return p.MaxRedirects
+	}
+}
+
+// useLastResponse stops net/http from following redirects, which do follows itself.
+func useLastResponse(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// withoutRedirects returns a copy of client that does not follow redirects.
+func withoutRedirects(client *http.Client) *http.Client {
+	noRedirects := *client
+	noRedirects.CheckRedirect = useLastResponse
+	return &noRedirects
+}
+
+// isRedirect reports whether a response to a request with method is a redirect
+// that can be followed with the same request.
+func isRedirect(method string, statusCode int) bool {
+	switch statusCode {
+	case http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther:
+		return method == http.MethodGet
+	default:
+		return false
+	}
+}
+
+// sameOrigin reports whether a and b have the same scheme, host and port.
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(hostPort(a), hostPort(b))
+}
+
+// hostPort returns the host of u with its port, defaulted from the scheme.
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return u.Hostname() + ":443"
+	case "http":
+		return u.Hostname() + ":80"
+	default:
+		return u.Host
+	}
+}
+
+// followRedirects sends req again to the location of resp, a response to req,
+// while it is a redirect the policy allows for a call safe to repeat. It returns
+// the last response; a redirect not followed is returned as it is.
+func (c *A2AClient) followRedirects(req *http.Request, resp *http.Response, safe bool) (*http.Response, error) {
+	origin := req.URL
+	for hops := 0; isRedirect(req.Method, resp.StatusCode); hops++ {
+		if !safe || hops >= c.redirect.maxRedirects() {
+			return resp, nil
+		}
+		location, err := resp.Location()
+		if err != nil {
+			return resp, nil
+		}
+		crossOrigin := !sameOrigin(origin, location)
+		if crossOrigin && c.redirect.SameOriginOnly {
+			log.Warnf("A2A Client: not following redirect of %s to another origin %s", req.URL, location)
+			return resp, nil
+		}
+		next := req.Clone(req.Context())
+		next.URL = location
+		next.Host = location.Host
+		if req.GetBody != nil {
+			if next.Body, err = req.GetBody(); err != nil {
+				return resp, nil
+			}
+		} else if req.Body != nil && req.Body != http.NoBody {
+			return resp, nil // The body cannot be sent again.
+		}
+		client := c.httpClient
+		if crossOrigin {
+			for _, header := range credentialHeaders {
+				next.Header.Del(header)
+			}
+			client = c.credentialFreeClient()
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		log.Debugf("A2A Client: following %d redirect of %s to %s", resp.StatusCode, req.URL, location)
+		if resp, err = client.Do(next); err != nil {
+			return nil, fmt.Errorf("following redirect to %s: %w", location, err)
+		}
+		req = next
+	}
+	return resp, nil
+}
+
+// credentialFreeClient returns the HTTP client for requests to another origin than
+// the agent's: the client before the authentication options wrapped it.
+func (c *A2AClient) credentialFreeClient() *http.Client {
+	if c.plainClient == nil {
+		return c.httpClient
+	}
+	client := withoutRedirects(c.plainClient)
+	client.Timeout = c.httpClient.Timeout
+	return client
+}
@@ -0,0 +1,197 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// movedAgent is an agent answering calls on its mux, recording the headers of the
+// calls it answered.
+type movedAgent struct {
+	server *httptest.Server
+	mux    *http.ServeMux
+
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func newMovedAgent(t *testing.T) *movedAgent {
+	a := &movedAgent{mux: http.NewServeMux()}
+	a.server = httptest.NewServer(a.mux)
+	t.Cleanup(a.server.Close)
+	return a
+}
+
+// redirect answers requests to from with status, redirecting to location.
+func (a *movedAgent) redirect(from, location string, status int) {
+	a.mux.HandleFunc(from, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, location, status)
+	})
+}
+
+// serve answers calls to path with a task, or an SSE stream of its final status.
+func (a *movedAgent) serve(t *testing.T, path string) {
+	a.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		a.headers = append(a.headers, r.Header.Clone())
+		a.mu.Unlock()
+		var req jsonrpc.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		task := protocol.Task{ID: "moved-task", Status: protocol.TaskStatus{State: protocol.TaskStateCompleted}}
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			event, _ := json.Marshal(jsonrpc.NewResponse(req.ID, protocol.TaskStatusUpdateEvent{
+				ID: task.ID, Status: task.Status, Final: true,
+			}))
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", protocol.EventTaskStatusUpdate, event)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jsonrpc.NewResponse(req.ID, task))
+	})
+}
+
+// lastHeader returns the headers of the last call answered.
+func (a *movedAgent) lastHeader(t *testing.T) http.Header {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	require.NotEmpty(t, a.headers)
+	return a.headers[len(a.headers)-1]
+}
+
+func TestA2AClient_Redirects(t *testing.T) {
+	ctx := context.Background()
+	query := protocol.TaskQueryParams{ID: "moved-task"}
+	send := protocol.SendTaskParams{
+		ID: "moved-task",
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleUser,
+			Parts: []protocol.Part{protocol.NewTextPart("hello")},
+		},
+	}
+
+	t.Run("SameOriginKeepsCredentials", func(t *testing.T) {
+		agent := newMovedAgent(t)
+		agent.redirect("/old/", "/new/", http.StatusPermanentRedirect)
+		agent.serve(t, "/new/")
+		client, err := NewA2AClient(agent.server.URL+"/old/",
+			WithAPIKeyAuth("secret", "X-API-Key"),
+			WithJWTAuth([]byte("jwt-secret"), "agent", "client", 0))
+		require.NoError(t, err)
+		task, err := client.GetTasks(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+		header := agent.lastHeader(t)
+		assert.Equal(t, "secret", header.Get("X-API-Key"))
+		assert.NotEmpty(t, header.Get("Authorization"))
+	})
+
+	t.Run("CrossOriginStripsCredentials", func(t *testing.T) {
+		moved := newMovedAgent(t)
+		moved.serve(t, "/")
+		agent := newMovedAgent(t)
+		agent.redirect("/", moved.server.URL+"/", http.StatusTemporaryRedirect)
+		client, err := NewA2AClient(agent.server.URL,
+			WithAPIKeyAuth("secret", "X-API-Key"),
+			WithJWTAuth([]byte("jwt-secret"), "agent", "client", 0),
+			WithUserAgent("redirect-test"))
+		require.NoError(t, err)
+		_, err = client.GetTasks(ctx, query)
+		require.NoError(t, err)
+		header := moved.lastHeader(t)
+		assert.Empty(t, header.Get("X-API-Key"))
+		assert.Empty(t, header.Get("Authorization"))
+		assert.Equal(t, "redirect-test", header.Get("User-Agent"))
+	})
+
+	t.Run("SameOriginOnly", func(t *testing.T) {
+		moved := newMovedAgent(t)
+		moved.serve(t, "/")
+		agent := newMovedAgent(t)
+		agent.redirect("/", moved.server.URL+"/", http.StatusTemporaryRedirect)
+		client, err := NewA2AClient(agent.server.URL,
+			WithRedirectPolicy(RedirectPolicy{SameOriginOnly: true}))
+		require.NoError(t, err)
+		_, err = client.GetTasks(ctx, query)
+		var httpErr *HTTPError
+		require.True(t, errors.As(err, &httpErr), "got %v", err)
+		assert.Equal(t, http.StatusTemporaryRedirect, httpErr.StatusCode)
+	})
+
+	t.Run("NonIdempotentNotFollowed", func(t *testing.T) {
+		agent := newMovedAgent(t)
+		agent.redirect("/old/", "/new/", http.StatusPermanentRedirect)
+		agent.serve(t, "/new/")
+		client, err := NewA2AClient(agent.server.URL + "/old/")
+		require.NoError(t, err)
+		_, err = client.SendTasks(ctx, send)
+		var httpErr *HTTPError
+		require.True(t, errors.As(err, &httpErr), "got %v", err)
+		assert.Equal(t, http.StatusPermanentRedirect, httpErr.StatusCode)
+
+		// With an idempotency key the call is safe to send again.
+		_, err = client.SendTasks(ctx, send, WithIdempotencyKey("key-1"))
+		require.NoError(t, err)
+		assert.Equal(t, "key-1", agent.lastHeader(t).Get(protocol.HeaderIdempotencyKey))
+	})
+
+	t.Run("MaxRedirects", func(t *testing.T) {
+		agent := newMovedAgent(t)
+		agent.redirect("/a/", "/b/", http.StatusTemporaryRedirect)
+		agent.redirect("/b/", "/c/", http.StatusTemporaryRedirect)
+		agent.serve(t, "/c/")
+		client, err := NewA2AClient(agent.server.URL+"/a/", WithRedirectPolicy(RedirectPolicy{MaxRedirects: 1}))
+		require.NoError(t, err)
+		_, err = client.GetTasks(ctx, query)
+		require.Error(t, err)
+
+		client, err = NewA2AClient(agent.server.URL+"/a/", WithRedirectPolicy(RedirectPolicy{MaxRedirects: 2}))
+		require.NoError(t, err)
+		_, err = client.GetTasks(ctx, query)
+		require.NoError(t, err)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		moved := newMovedAgent(t)
+		moved.serve(t, "/")
+		agent := newMovedAgent(t)
+		agent.redirect("/", moved.server.URL+"/", http.StatusTemporaryRedirect)
+		client, err := NewA2AClient(agent.server.URL, WithAPIKeyAuth("secret", "X-API-Key"))
+		require.NoError(t, err)
+		events, err := client.ResubscribeTask(ctx, "moved-task")
+		require.NoError(t, err)
+		var received []protocol.TaskEvent
+		for event := range events {
+			received = append(received, event)
+		}
+		require.Len(t, received, 1)
+		status, ok := received[0].(protocol.TaskStatusUpdateEvent)
+		require.True(t, ok)
+		assert.True(t, status.Final)
+		assert.Empty(t, moved.lastHeader(t).Get("X-API-Key"))
+
+		// tasks/sendSubscribe starts a task, so it is not sent again.
+		_, err = client.StreamTask(ctx, send)
+		var httpErr *HTTPError
+		require.True(t, errors.As(err, &httpErr), "got %v", err)
+		assert.Equal(t, http.StatusTemporaryRedirect, httpErr.StatusCode)
+	})
+}
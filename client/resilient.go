@@ -0,0 +1,375 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// ErrStreamPermanent is wrapped by the error of a ResilientStream that stopped
+// because reconnecting cannot help, such as a request the agent rejected or a task
+// it does not know, or because the reconnect attempts were used up.
+var ErrStreamPermanent = errors.New("stream failed permanently")
+
+// StreamState is the connection state of a ResilientStream.
+type StreamState int
+
+// Connection states of a ResilientStream.
+const (
+	// StreamStateConnected means a stream to the agent is open.
+	StreamStateConnected StreamState = iota + 1
+	// StreamStateReconnecting means the stream broke and is being reopened.
+	StreamStateReconnecting
+	// StreamStateDisconnected means the stream ended for good. No events follow.
+	StreamStateDisconnected
+)
+
+// String returns the state name.
+func (s StreamState) String() string {
+	switch s {
+	case StreamStateConnected:
+		return "connected"
+	case StreamStateReconnecting:
+		return "reconnecting"
+	case StreamStateDisconnected:
+		return "disconnected"
+	default:
+		return fmt.Sprintf("StreamState(%d)", int(s))
+	}
+}
+
+// StreamHandlers receives the events and state changes of a ResilientStream.
+// The handlers are called from a single goroutine, one at a time. Either may be nil.
+type StreamHandlers struct {
+	// OnEvent is called for each event of the task. Events already delivered
+	// before a reconnect are not delivered again.
+	OnEvent func(event protocol.TaskEvent)
+	// OnStateChange is called when the connection state changes. err is why the
+	// stream broke for StreamStateReconnecting, and why it stopped for
+	// StreamStateDisconnected; it is nil once the task reached a final state.
+	OnStateChange func(state StreamState, err error)
+}
+
+// ResilientOption configures a ResilientStream.
+type ResilientOption func(*ResilientStream)
+
+// WithReconnectBackoff sets the delay before the first reconnect attempt and the
+// cap it doubles up to on consecutive failures. A Retry-After sent by the agent
+// takes precedence. Defaults are 500 milliseconds and 30 seconds.
+func WithReconnectBackoff(initial, max time.Duration) ResilientOption {
+	return func(s *ResilientStream) {
+		if initial > 0 {
+			s.initialBackoff = initial
+		}
+		if max > 0 {
+			s.maxBackoff = max
+		}
+	}
+}
+
+// WithMaxReconnects sets how many reconnect attempts in a row may fail, without a
+// new event in between, before the stream gives up with ErrStreamPermanent.
+// Default is 5; a value below zero retries until the context is done.
+func WithMaxReconnects(n int) ResilientOption {
+	return func(s *ResilientStream) {
+		s.maxReconnects = n
+	}
+}
+
+// WithResilientStreamOptions applies opts to every stream the ResilientStream opens.
+func WithResilientStreamOptions(opts ...StreamOption) ResilientOption {
+	return func(s *ResilientStream) {
+		s.streamOpts = append(s.streamOpts, opts...)
+	}
+}
+
+// ResilientStream follows the events of a task across broken connections. See
+// A2AClient.NewResilientStream.
+type ResilientStream struct {
+	client   *A2AClient
+	params   protocol.SendTaskParams
+	handlers StreamHandlers
+
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	maxReconnects  int
+	streamOpts     []StreamOption
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error // Why the stream stopped; set before done is closed.
+
+	seen  map[string]struct{} // IDs of the idempotent events delivered so far.
+	state StreamState
+}
+
+// NewResilientStream sends the task with StreamTask and delivers its events to
+// handlers until the task reaches a final state, ctx is done or Close is called.
+// When the connection breaks, it reopens the stream with ResubscribeTask, backing
+// off between attempts, and sends the task again only if the agent no longer knows
+// it. Since the agent repeats the current status on resubscribe, events are
+// deduplicated by an ID derived from their content: status updates, whole artifact
+// updates and artifact deletes are delivered once. Artifact chunks with Append set,
+// patches and messages are always delivered; those sent while disconnected are lost.
+// Network errors, 5xx, 408 and 429 replies and idle timeouts are transient;
+// anything else the agent rejects stops the stream with an error wrapping
+// ErrStreamPermanent. The stream runs in its own goroutine; use Wait for its result.
+func (c *A2AClient) NewResilientStream(
+	ctx context.Context,
+	params protocol.SendTaskParams,
+	handlers StreamHandlers,
+	opts ...ResilientOption,
+) *ResilientStream {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &ResilientStream{
+		client:         c,
+		params:         params,
+		handlers:       handlers,
+		initialBackoff: 500 * time.Millisecond,
+		maxBackoff:     30 * time.Second,
+		maxReconnects:  5,
+		cancel:         cancel,
+		done:           make(chan struct{}),
+		seen:           make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	go s.run(ctx)
+	return s
+}
+
+// Wait blocks until the stream stops and returns why: nil once the task reached a
+// final state, the context's error after Close or cancellation, or the last error
+// otherwise. Errors that reconnecting cannot fix wrap ErrStreamPermanent.
+func (s *ResilientStream) Wait() error {
+	<-s.done
+	return s.err
+}
+
+// Done returns a channel closed once the stream stopped.
+func (s *ResilientStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close stops the stream and waits until the handlers are no longer called.
+func (s *ResilientStream) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// run connects and reconnects until the stream stops.
+func (s *ResilientStream) run(ctx context.Context) {
+	defer close(s.done)
+	defer s.cancel()
+	err := s.follow(ctx)
+	if ctxErr := ctx.Err(); ctxErr != nil && err != nil {
+		err = ctxErr
+	}
+	s.err = err
+	s.setState(StreamStateDisconnected, err)
+}
+
+// follow returns nil once the task reached a final state.
+func (s *ResilientStream) follow(ctx context.Context) error {
+	failures := 0
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if s.maxReconnects >= 0 && failures > s.maxReconnects {
+				return fmt.Errorf("%w: giving up after %d reconnect attempts: %w",
+					ErrStreamPermanent, failures, lastErr)
+			}
+			if err := sleepContext(ctx, s.backoff(failures, lastErr)); err != nil {
+				return err
+			}
+		}
+		events, err := s.open(ctx, attempt == 0)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !isTransientStreamError(err) {
+				return fmt.Errorf("%w: %w", ErrStreamPermanent, err)
+			}
+			failures++
+			lastErr = err
+			s.setState(StreamStateReconnecting, err)
+			continue
+		}
+		s.setState(StreamStateConnected, nil)
+		finished, progressed, err := s.consume(events)
+		if finished {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !isTransientStreamError(err) {
+			return fmt.Errorf("%w: %w", ErrStreamPermanent, err)
+		}
+		if err == nil {
+			err = errors.New("stream closed before the task finished")
+		}
+		if progressed {
+			failures = 0
+		} else if attempt > 0 {
+			failures++
+		}
+		lastErr = err
+		s.setState(StreamStateReconnecting, err)
+	}
+}
+
+// open opens a stream for the task: the first time by sending it, afterwards by
+// resubscribing, or by sending it again if the agent does not know the task.
+func (s *ResilientStream) open(ctx context.Context, first bool) (<-chan protocol.TaskEvent, error) {
+	if first {
+		return s.client.StreamTask(ctx, s.params, s.streamOpts...)
+	}
+	events, err := s.client.ResubscribeTask(ctx, s.params.ID, s.streamOpts...)
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.Code == taskmanager.ErrCodeTaskNotFound {
+		log.Debugf("ResilientStream: task %s unknown to the agent, sending it again", s.params.ID)
+		return s.client.StreamTask(ctx, s.params, s.streamOpts...)
+	}
+	return events, err
+}
+
+// consume delivers the events of one stream. It reports whether the task finished,
+// whether any event not delivered before arrived, and why the stream broke, if it
+// said so.
+func (s *ResilientStream) consume(events <-chan protocol.TaskEvent) (finished, progressed bool, err error) {
+	for event := range events {
+		if errEvent, ok := event.(protocol.TaskStreamErrorEvent); ok {
+			// Keep draining so the channel's goroutine ends.
+			err = errEvent.Err
+			continue
+		}
+		if s.deliver(event) {
+			progressed = true
+		}
+		if status, ok := event.(protocol.TaskStatusUpdateEvent); ok && status.Final {
+			finished = true
+		}
+	}
+	return finished, progressed, err
+}
+
+// deliver passes event to the handler unless it was delivered before, and reports
+// whether it did.
+func (s *ResilientStream) deliver(event protocol.TaskEvent) bool {
+	if id, ok := eventID(event); ok {
+		if _, dup := s.seen[id]; dup {
+			return false
+		}
+		s.seen[id] = struct{}{}
+	}
+	if s.handlers.OnEvent != nil {
+		s.handlers.OnEvent(event)
+	}
+	return true
+}
+
+// setState reports a change of the connection state.
+func (s *ResilientStream) setState(state StreamState, err error) {
+	if state == s.state {
+		return
+	}
+	s.state = state
+	if s.handlers.OnStateChange != nil {
+		s.handlers.OnStateChange(state, err)
+	}
+}
+
+// backoff returns the delay before the next reconnect attempt after the given
+// number of failed ones.
+func (s *ResilientStream) backoff(failures int, lastErr error) time.Duration {
+	var httpErr *HTTPError
+	if errors.As(lastErr, &httpErr) {
+		if delay, ok := httpErr.RetryAfter(); ok {
+			return delay
+		}
+	}
+	delay := s.initialBackoff
+	for i := 0; i < failures && delay < s.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.maxBackoff {
+		delay = s.maxBackoff
+	}
+	return delay
+}
+
+// eventID returns an ID identifying event by its content, if delivering the event
+// twice would be harmful to callers deduplicating by it. Events whose repetition
+// changes their meaning, such as appended chunks, have no ID.
+func eventID(event protocol.TaskEvent) (string, bool) {
+	switch e := event.(type) {
+	case protocol.TaskStatusUpdateEvent, protocol.TaskArtifactDeleteEvent:
+	case protocol.TaskArtifactUpdateEvent:
+		if e.Artifact.Append != nil && *e.Artifact.Append {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(append([]byte(fmt.Sprintf("%T:", event)), data...))
+	return hex.EncodeToString(sum[:]), true
+}
+
+// isTransientStreamError reports whether opening or reading a stream failed in a
+// way that reconnecting may fix.
+func isTransientStreamError(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable,
+			http.StatusRequestTimeout, http.StatusBadGateway, http.StatusGatewayTimeout:
+			return true
+		case http.StatusInternalServerError:
+			// Agents answer JSON-RPC errors such as task-not-found with a 500 too.
+			return httpErr.rpcErr == nil || httpErr.rpcErr.Code == jsonrpc.CodeInternalError
+		default:
+			return false
+		}
+	}
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == jsonrpc.CodeInternalError
+	}
+	// Network failures and streams that broke or went idle.
+	return true
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,298 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// flakyAgent is an SSE agent whose streams break after the events of each step.
+type flakyAgent struct {
+	mu    sync.Mutex
+	calls []string // JSON-RPC methods in the order they were called.
+	// steps answers the n-th call. It returns the events to stream and whether to
+	// keep the stream open afterwards instead of dropping the connection, or no
+	// events if it wrote an error reply itself.
+	steps []func(w http.ResponseWriter) (events []protocol.TaskEvent, keep bool)
+}
+
+func (a *flakyAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req jsonrpc.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a.mu.Lock()
+	n := len(a.calls)
+	a.calls = append(a.calls, req.Method)
+	a.mu.Unlock()
+	if n >= len(a.steps) {
+		http.Error(w, "unexpected call", http.StatusServiceUnavailable)
+		return
+	}
+	events, keep := a.steps[n](w)
+	if events == nil {
+		return // The step wrote an error reply.
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	for _, event := range events {
+		data, _ := json.Marshal(event)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sseEventType(event), data)
+	}
+	w.(http.Flusher).Flush()
+	if keep {
+		<-r.Context().Done()
+		return
+	}
+	// Break the connection as a network blip would.
+	panic(http.ErrAbortHandler)
+}
+
+func (a *flakyAgent) methods() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.calls...)
+}
+
+func sseEventType(event protocol.TaskEvent) string {
+	switch event.(type) {
+	case protocol.TaskStatusUpdateEvent:
+		return protocol.EventTaskStatusUpdate
+	case protocol.TaskArtifactUpdateEvent:
+		return protocol.EventTaskArtifactUpdate
+	case protocol.TaskMessageEvent:
+		return protocol.EventTaskMessage
+	default:
+		return "unknown"
+	}
+}
+
+func stream(events ...protocol.TaskEvent) func(http.ResponseWriter) ([]protocol.TaskEvent, bool) {
+	return func(http.ResponseWriter) ([]protocol.TaskEvent, bool) {
+		return events, false
+	}
+}
+
+func reply(status int, rpcErr *jsonrpc.Error) func(http.ResponseWriter) ([]protocol.TaskEvent, bool) {
+	return func(w http.ResponseWriter) ([]protocol.TaskEvent, bool) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if rpcErr != nil {
+			json.NewEncoder(w).Encode(jsonrpc.Response{
+				Message: jsonrpc.Message{JSONRPC: "2.0"},
+				Error:   rpcErr,
+			})
+		}
+		return nil, false
+	}
+}
+
+type stateChange struct {
+	state StreamState
+	err   error
+}
+
+// recorder collects what a ResilientStream delivers to its handlers.
+type recorder struct {
+	events []protocol.TaskEvent
+	states []stateChange
+}
+
+func (r *recorder) handlers() StreamHandlers {
+	return StreamHandlers{
+		OnEvent: func(event protocol.TaskEvent) {
+			r.events = append(r.events, event)
+		},
+		OnStateChange: func(state StreamState, err error) {
+			r.states = append(r.states, stateChange{state, err})
+		},
+	}
+}
+
+func (r *recorder) stateNames() []string {
+	var names []string
+	for _, change := range r.states {
+		names = append(names, change.state.String())
+	}
+	return names
+}
+
+func TestA2AClient_NewResilientStream(t *testing.T) {
+	const taskID = "resilient-task"
+	params := protocol.SendTaskParams{
+		ID: taskID,
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleUser,
+			Parts: []protocol.Part{protocol.NewTextPart("hello")},
+		},
+	}
+	working := protocol.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: protocol.TaskStatus{State: protocol.TaskStateWorking, Timestamp: "2025-01-01T00:00:00Z"},
+	}
+	report := protocol.TaskArtifactUpdateEvent{
+		ID:       taskID,
+		Artifact: protocol.Artifact{Index: 0, Parts: []protocol.Part{protocol.NewTextPart("report")}},
+	}
+	appendFlag := true
+	chunk := protocol.TaskArtifactUpdateEvent{
+		ID: taskID,
+		Artifact: protocol.Artifact{
+			Index: 0, Parts: []protocol.Part{protocol.NewTextPart(" more")}, Append: &appendFlag,
+		},
+	}
+	note := protocol.TaskMessageEvent{
+		ID: taskID,
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleAgent,
+			Parts: []protocol.Part{protocol.NewTextPart("almost done")},
+		},
+	}
+	completed := protocol.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: protocol.TaskStatus{State: protocol.TaskStateCompleted, Timestamp: "2025-01-01T00:01:00Z"},
+		Final:  true,
+	}
+	fast := WithReconnectBackoff(time.Millisecond, 5*time.Millisecond)
+
+	run := func(t *testing.T, agent *flakyAgent, opts ...ResilientOption) (*recorder, error) {
+		server := httptest.NewServer(agent)
+		defer server.Close()
+		client, err := NewA2AClient(server.URL)
+		require.NoError(t, err)
+		rec := &recorder{}
+		s := client.NewResilientStream(context.Background(), params, rec.handlers(), opts...)
+		select {
+		case <-s.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the stream to stop")
+		}
+		return rec, s.Wait()
+	}
+
+	t.Run("IntermittentDisconnects", func(t *testing.T) {
+		agent := &flakyAgent{steps: []func(http.ResponseWriter) ([]protocol.TaskEvent, bool){
+			stream(working, report),
+			reply(http.StatusServiceUnavailable, nil),
+			// The agent repeats the current status and, here, the last artifact.
+			stream(working, report, chunk),
+			stream(working, note),
+			stream(working, completed),
+		}}
+		rec, err := run(t, agent, fast)
+		require.NoError(t, err)
+
+		assert.Equal(t, []protocol.TaskEvent{working, report, chunk, note, completed}, rec.events)
+		assert.Equal(t, []string{
+			"connected", "reconnecting", "connected", "reconnecting",
+			"connected", "reconnecting", "connected", "disconnected",
+		}, rec.stateNames())
+		assert.NoError(t, rec.states[len(rec.states)-1].err)
+		assert.Equal(t, []string{
+			protocol.MethodTasksSendSubscribe, protocol.MethodTasksResubscribe, protocol.MethodTasksResubscribe,
+			protocol.MethodTasksResubscribe, protocol.MethodTasksResubscribe,
+		}, agent.methods())
+	})
+
+	t.Run("UnknownTaskIsSentAgain", func(t *testing.T) {
+		agent := &flakyAgent{steps: []func(http.ResponseWriter) ([]protocol.TaskEvent, bool){
+			stream(working),
+			reply(http.StatusInternalServerError, &jsonrpc.Error{Code: -32001, Message: "Task not found"}),
+			stream(working, completed),
+		}}
+		rec, err := run(t, agent, fast)
+		require.NoError(t, err)
+		assert.Equal(t, []protocol.TaskEvent{working, completed}, rec.events)
+		assert.Equal(t, []string{
+			protocol.MethodTasksSendSubscribe, protocol.MethodTasksResubscribe, protocol.MethodTasksSendSubscribe,
+		}, agent.methods())
+	})
+
+	t.Run("PermanentFailure", func(t *testing.T) {
+		agent := &flakyAgent{steps: []func(http.ResponseWriter) ([]protocol.TaskEvent, bool){
+			stream(working),
+			reply(http.StatusBadRequest, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "bad task"}),
+		}}
+		rec, err := run(t, agent, fast)
+		require.ErrorIs(t, err, ErrStreamPermanent)
+		assert.Contains(t, err.Error(), "bad task")
+		assert.Equal(t, []string{"connected", "reconnecting", "disconnected"}, rec.stateNames())
+		assert.ErrorIs(t, rec.states[2].err, ErrStreamPermanent)
+	})
+
+	t.Run("RejectedAtStart", func(t *testing.T) {
+		agent := &flakyAgent{steps: []func(http.ResponseWriter) ([]protocol.TaskEvent, bool){
+			reply(http.StatusUnauthorized, nil),
+		}}
+		rec, err := run(t, agent, fast)
+		require.ErrorIs(t, err, ErrStreamPermanent)
+		assert.Empty(t, rec.events)
+		assert.Equal(t, []string{"disconnected"}, rec.stateNames())
+		assert.Len(t, agent.methods(), 1)
+	})
+
+	t.Run("GivesUpAfterMaxReconnects", func(t *testing.T) {
+		agent := &flakyAgent{steps: []func(http.ResponseWriter) ([]protocol.TaskEvent, bool){
+			stream(working),
+			reply(http.StatusServiceUnavailable, nil),
+			stream(working), // Reconnects without a new event do not reset the count.
+			reply(http.StatusBadGateway, nil),
+		}}
+		rec, err := run(t, agent, fast, WithMaxReconnects(2))
+		require.ErrorIs(t, err, ErrStreamPermanent)
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+		assert.Equal(t, []protocol.TaskEvent{working}, rec.events)
+		assert.Len(t, agent.methods(), 4)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		agent := &flakyAgent{steps: []func(http.ResponseWriter) ([]protocol.TaskEvent, bool){
+			func(http.ResponseWriter) ([]protocol.TaskEvent, bool) {
+				return []protocol.TaskEvent{working}, true
+			},
+		}}
+		server := httptest.NewServer(agent)
+		defer server.Close()
+		client, err := NewA2AClient(server.URL)
+		require.NoError(t, err)
+		received := make(chan protocol.TaskEvent, 1)
+		s := client.NewResilientStream(context.Background(), params, StreamHandlers{
+			OnEvent: func(event protocol.TaskEvent) { received <- event },
+		})
+		select {
+		case event := <-received:
+			assert.Equal(t, working, event)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the first event")
+		}
+		s.Close()
+		assert.ErrorIs(t, s.Wait(), context.Canceled)
+	})
+}
+
+func TestStreamState_String(t *testing.T) {
+	assert.Equal(t, "connected", StreamStateConnected.String())
+	assert.Equal(t, "reconnecting", StreamStateReconnecting.String())
+	assert.Equal(t, "disconnected", StreamStateDisconnected.String())
+	assert.Equal(t, "StreamState(0)", StreamState(0).String())
+}
@@ -0,0 +1,135 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+var (
+	// ErrTaskNotAwaitingInput is returned by RespondWithData for a task that is not
+	// in the input-required state.
+	ErrTaskNotAwaitingInput = errors.New("task is not awaiting input")
+	// ErrInvalidInputData is returned by RespondWithData when the data does not match
+	// the input schema requested by the agent.
+	ErrInvalidInputData = errors.New("data does not match the requested input schema")
+)
+
+// RespondWithData continues a task waiting for input with data, sent in a user
+// message as a single DataPart. data is anything that marshals to JSON, such as a
+// map or a struct. If the agent's input-required status message carries a JSON
+// Schema under protocol.MetadataKeyInputSchema, data is validated against it first
+// and rejected with ErrInvalidInputData without contacting the agent again.
+// It returns the task state received from the agent, like SendTasks.
+func (c *A2AClient) RespondWithData(
+	ctx context.Context,
+	taskID string,
+	data interface{},
+	opts ...SendOption,
+) (*protocol.Task, error) {
+	task, err := c.GetTasks(ctx, protocol.TaskQueryParams{ID: taskID})
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.RespondWithData: %w", err)
+	}
+	if task.Status.State != protocol.TaskStateInputRequired {
+		return nil, fmt.Errorf("a2aClient.RespondWithData: %w: task %s is %s",
+			ErrTaskNotAwaitingInput, taskID, task.Status.State)
+	}
+	value, err := jsonValue(data)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.RespondWithData: failed to marshal data: %w", err)
+	}
+	if err := validateInputData(task.Status.Message, value); err != nil {
+		return nil, fmt.Errorf("a2aClient.RespondWithData: %w", err)
+	}
+	params := protocol.SendTaskParams{
+		ID:        taskID,
+		SessionID: task.SessionID,
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{
+			protocol.DataPart{Type: protocol.PartTypeData, Data: value},
+		}),
+	}
+	task, err = c.SendTasks(ctx, params, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("a2aClient.RespondWithData: %w", err)
+	}
+	return task, nil
+}
+
+// jsonValue converts data to its generic JSON form, keeping numbers exact.
+func jsonValue(data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// validateInputData checks value against the input schema of the agent's status
+// message, if it has one.
+func validateInputData(statusMsg *protocol.Message, value interface{}) error {
+	if statusMsg == nil {
+		return nil
+	}
+	rawSchema, ok := statusMsg.Metadata[protocol.MetadataKeyInputSchema]
+	if !ok {
+		return nil
+	}
+	schemaBytes, err := json.Marshal(rawSchema)
+	if err != nil {
+		return fmt.Errorf("invalid input schema: %w", err)
+	}
+	const url = "a2a://input-schema"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, bytes.NewReader(schemaBytes)); err != nil {
+		return fmt.Errorf("invalid input schema: %w", err)
+	}
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		return fmt.Errorf("invalid input schema: %w", err)
+	}
+	err = schema.Validate(value)
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return fmt.Errorf("%w: %v", ErrInvalidInputData, err)
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidInputData, strings.Join(inputViolations(validationErr), "; "))
+}
+
+// inputViolations flattens a validation error into its leaf causes, each as the
+// location of the offending value and what is wrong with it.
+func inputViolations(err *jsonschema.ValidationError) []string {
+	if len(err.Causes) == 0 {
+		location := err.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		return []string{location + ": " + err.Message}
+	}
+	var violations []string
+	for _, cause := range err.Causes {
+		violations = append(violations, inputViolations(cause)...)
+	}
+	return violations
+}
@@ -0,0 +1,202 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// retryBudgetCalls bounds the retry credit to what this many calls earn, so a long
+// healthy period does not bank enough retries for a storm.
+const retryBudgetCalls = 100
+
+// retryPolicy holds the settings of WithRetry.
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// delay returns how long to wait before the attempt following attempt, preferring
+// the Retry-After of resp.
+func (p *retryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := newHTTPError(resp, nil).RetryAfter(); ok {
+			return delay
+		}
+	}
+	return p.backoff << (attempt - 1)
+}
+
+// retryBudget caps the retries of a client relative to its calls. Each call earns
+// ratio retries, and minPerSec retries are allowed every second regardless.
+type retryBudget struct {
+	ratio     float64
+	minPerSec int
+	now       func() time.Time
+
+	mu          sync.Mutex
+	balance     float64   // Retries earned by calls and not spent yet.
+	window      time.Time // Start of the second the reserve was last refilled.
+	windowSpent int       // Retries taken from the per-second reserve in window.
+}
+
+// newRetryBudget creates a retryBudget for WithRetryBudget.
+func newRetryBudget(ratio float64, minPerSec int) *retryBudget {
+	if ratio < 0 {
+		ratio = 0
+	}
+	if minPerSec < 0 {
+		minPerSec = 0
+	}
+	return &retryBudget{ratio: ratio, minPerSec: minPerSec, now: time.Now}
+}
+
+// deposit credits the budget for a call.
+func (b *retryBudget) deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.balance += b.ratio
+	if limit := math.Max(b.ratio*retryBudgetCalls, 1); b.balance > limit {
+		b.balance = limit
+	}
+}
+
+// withdraw takes one retry from the budget and reports whether one was left.
+func (b *retryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if now := b.now(); now.Sub(b.window) >= time.Second {
+		b.window = now
+		b.windowSpent = 0
+	}
+	if b.windowSpent < b.minPerSec {
+		b.windowSpent++
+		return true
+	}
+	// Allow for rounding, so that ten calls at a ratio of 0.1 earn a retry.
+	if b.balance >= 1-1e-9 {
+		b.balance--
+		return true
+	}
+	return false
+}
+
+// retrySafe reports whether the call may be sent again after a failure that may
+// have reached the agent: the calls that only read, and tasks/send with an
+// idempotency key. Anything else, such as tasks/sendBatch, tasks/import or a
+// custom method, may have taken effect and must not be repeated.
+func retrySafe(method string, opts *sendOptions) bool {
+	switch method {
+	case protocol.MethodTasksSend:
+		return opts.idempotencyKey != ""
+	case protocol.MethodTasksGet,
+		protocol.MethodTasksPushNotificationGet,
+		protocol.MethodTasksResubscribe,
+		protocol.MethodTasksSubscribeMultiple,
+		protocol.MethodTasksExport,
+		protocol.MethodCapabilities:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable reports whether a call that ended with resp or err may succeed if
+// sent again.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendRequest sends req, the HTTP request of request, and returns the response
+// with its body read. With WithRetry it sends the request again while it fails
+// transiently, the method is safe to repeat and the retry budget, if any, allows.
+func (c *A2AClient) sendRequest(
+	ctx context.Context, req *http.Request, request *jsonrpc.Request, opts *sendOptions,
+) (*http.Response, []byte, error) {
+	method := request.Method
+	retry := c.retry != nil && retrySafe(method, opts)
+	if retry {
+		c.retryBudget.deposit()
+	}
+	for attempt := 1; ; attempt++ {
+		resp, body, err := c.sendOnce(ctx, req, retrySafe(method, opts))
+		if !retry || attempt >= c.retry.maxAttempts || !retryable(ctx, resp, err) {
+			return resp, body, err
+		}
+		if !c.retryBudget.withdraw() {
+			log.Debugf("A2A Client: retry budget exhausted, not retrying %s", method)
+			return resp, body, err
+		}
+		if err := sleepContext(ctx, c.retry.delay(attempt, resp)); err != nil {
+			return nil, nil, fmt.Errorf("a2aClient.doRequest: %w", err)
+		}
+		next := req.Clone(ctx)
+		if c.balancer != nil {
+			next.URL = c.endpointFor(method, request.Params)
+			next.Host = next.URL.Host
+		}
+		if req.GetBody != nil {
+			if next.Body, err = req.GetBody(); err != nil {
+				return nil, nil, fmt.Errorf("a2aClient.doRequest: failed to rewind request body: %w", err)
+			}
+		}
+		req = next
+		log.Debugf("A2A Client: retrying %s, attempt %d", method, attempt+1)
+	}
+}
+
+// sendOnce sends req once, following redirects if it is safe to repeat, and reads
+// the response body.
+func (c *A2AClient) sendOnce(ctx context.Context, req *http.Request, safe bool) (*http.Response, []byte, error) {
+	release, err := c.limiter.acquireCall(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("a2aClient.doRequest: %w", err)
+	}
+	defer release()
+	resp, err := c.do(req, safe)
+	if err != nil {
+		return nil, nil, fmt.Errorf("a2aClient.doRequest: http request failed: %w", err)
+	}
+	// Ensure body is always closed.
+	defer resp.Body.Close()
+	// Read the body first for potential error reporting.
+	body, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		log.Warnf(
+			"Warning: a2aClient.doRequest: failed to read response body (status %d): %v",
+			resp.StatusCode, readErr,
+		)
+		// Continue to check status code, but decoding will likely fail.
+	}
+	return resp, body, nil
+}
@@ -0,0 +1,214 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// failingAgent answers the first failures requests with status, then with a task.
+func failingAgent(t *testing.T, failures int64, status int) (*httptest.Server, *atomic.Int64) {
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if hits.Add(1) <= failures {
+			http.Error(w, "unavailable", status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jsonrpc.Response{
+			Message: jsonrpc.Message{JSONRPC: "2.0", ID: req.ID},
+			Result:  protocol.Task{ID: "retry-task", Status: protocol.TaskStatus{State: protocol.TaskStateWorking}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestA2AClient_WithRetry(t *testing.T) {
+	ctx := context.Background()
+	query := protocol.TaskQueryParams{ID: "retry-task"}
+	send := protocol.SendTaskParams{
+		ID: "retry-task",
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleUser,
+			Parts: []protocol.Part{protocol.NewTextPart("hello")},
+		},
+	}
+
+	t.Run("RetriesTransientFailures", func(t *testing.T) {
+		server, hits := failingAgent(t, 2, http.StatusServiceUnavailable)
+		client, err := NewA2AClient(server.URL, WithRetry(3, time.Millisecond))
+		require.NoError(t, err)
+		task, err := client.GetTasks(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, "retry-task", task.ID)
+		assert.Equal(t, int64(3), hits.Load())
+	})
+
+	t.Run("StopsAfterMaxAttempts", func(t *testing.T) {
+		server, hits := failingAgent(t, 5, http.StatusBadGateway)
+		client, err := NewA2AClient(server.URL, WithRetry(3, time.Millisecond))
+		require.NoError(t, err)
+		_, err = client.GetTasks(ctx, query)
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+		assert.Equal(t, int64(3), hits.Load())
+	})
+
+	t.Run("PermanentFailureNotRetried", func(t *testing.T) {
+		server, hits := failingAgent(t, 5, http.StatusBadRequest)
+		client, err := NewA2AClient(server.URL, WithRetry(3, time.Millisecond))
+		require.NoError(t, err)
+		_, err = client.GetTasks(ctx, query)
+		require.Error(t, err)
+		assert.Equal(t, int64(1), hits.Load())
+	})
+
+	t.Run("SendRetriedOnlyWithIdempotencyKey", func(t *testing.T) {
+		server, hits := failingAgent(t, 1, http.StatusServiceUnavailable)
+		client, err := NewA2AClient(server.URL, WithRetry(3, time.Millisecond))
+		require.NoError(t, err)
+		_, err = client.SendTasks(ctx, send)
+		require.Error(t, err)
+		assert.Equal(t, int64(1), hits.Load())
+
+		server, hits = failingAgent(t, 1, http.StatusServiceUnavailable)
+		client, err = NewA2AClient(server.URL, WithRetry(3, time.Millisecond))
+		require.NoError(t, err)
+		_, err = client.SendTasks(ctx, send, WithIdempotencyKey("retry-key"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), hits.Load())
+	})
+
+	t.Run("UnsafeMethodsNotRetried", func(t *testing.T) {
+		for name, call := range map[string]func(*A2AClient) error{
+			"SendBatch": func(client *A2AClient) error {
+				_, err := client.SendTasksBatch(ctx, []protocol.SendTaskParams{send})
+				return err
+			},
+			"Cancel": func(client *A2AClient) error {
+				_, err := client.CancelTasks(ctx, protocol.TaskIDParams{ID: "retry-task"})
+				return err
+			},
+			"Custom": func(client *A2AClient) error {
+				return client.Call(ctx, "custom/charge", map[string]int{"amount": 1}, nil)
+			},
+		} {
+			server, hits := failingAgent(t, 1, http.StatusBadGateway)
+			client, err := NewA2AClient(server.URL, WithRetry(3, time.Millisecond))
+			require.NoError(t, err)
+			require.Error(t, call(client), name)
+			assert.Equal(t, int64(1), hits.Load(), name)
+		}
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		server, hits := failingAgent(t, 1, http.StatusServiceUnavailable)
+		client, err := NewA2AClient(server.URL)
+		require.NoError(t, err)
+		_, err = client.GetTasks(ctx, query)
+		require.Error(t, err)
+		assert.Equal(t, int64(1), hits.Load())
+	})
+}
+
+func TestA2AClient_WithRetryBudget(t *testing.T) {
+	ctx := context.Background()
+	query := protocol.TaskQueryParams{ID: "retry-task"}
+
+	t.Run("RetriesStopOnceBudgetEmpties", func(t *testing.T) {
+		server, hits := failingAgent(t, 1<<30, http.StatusServiceUnavailable)
+		client, err := NewA2AClient(server.URL,
+			WithRetry(3, time.Millisecond), WithRetryBudget(0.1, 0))
+		require.NoError(t, err)
+		const calls = 50
+		for i := 0; i < calls; i++ {
+			_, err := client.GetTasks(ctx, query)
+			assert.Error(t, err)
+		}
+		// Without the budget each call would have been sent three times; the calls
+		// earned five retries in all.
+		assert.Equal(t, int64(calls+5), hits.Load())
+
+		// The budget is spent: further failing calls are not retried.
+		before := hits.Load()
+		_, err = client.GetTasks(ctx, query)
+		require.Error(t, err)
+		assert.Equal(t, before+1, hits.Load())
+	})
+
+	t.Run("SharedAcrossConcurrentCalls", func(t *testing.T) {
+		server, hits := failingAgent(t, 1<<30, http.StatusServiceUnavailable)
+		client, err := NewA2AClient(server.URL,
+			WithRetry(3, time.Millisecond), WithRetryBudget(0.1, 0))
+		require.NoError(t, err)
+		const calls = 50
+		var wg sync.WaitGroup
+		for i := 0; i < calls; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.GetTasks(ctx, query)
+				assert.Error(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, hits.Load(), int64(calls+5))
+	})
+
+	t.Run("MinRetriesPerSecond", func(t *testing.T) {
+		server, hits := failingAgent(t, 1<<30, http.StatusServiceUnavailable)
+		client, err := NewA2AClient(server.URL,
+			WithRetry(2, time.Millisecond), WithRetryBudget(0, 3))
+		require.NoError(t, err)
+		now := time.Unix(1000, 0)
+		client.retryBudget.now = func() time.Time { return now }
+		for i := 0; i < 10; i++ {
+			_, _ = client.GetTasks(ctx, query)
+		}
+		assert.Equal(t, int64(13), hits.Load())
+
+		now = now.Add(time.Second)
+		_, _ = client.GetTasks(ctx, query)
+		assert.Equal(t, int64(15), hits.Load())
+	})
+
+	t.Run("CallsEarnRetries", func(t *testing.T) {
+		budget := newRetryBudget(0.5, 0)
+		assert.False(t, budget.withdraw())
+		budget.deposit()
+		budget.deposit()
+		assert.True(t, budget.withdraw())
+		assert.False(t, budget.withdraw())
+
+		// Credit is capped so a healthy period cannot bank a retry storm.
+		for i := 0; i < 10*retryBudgetCalls; i++ {
+			budget.deposit()
+		}
+		allowed := 0
+		for budget.withdraw() {
+			allowed++
+		}
+		assert.Equal(t, retryBudgetCalls/2, allowed)
+	})
+}
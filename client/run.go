@@ -0,0 +1,168 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+const (
+	// maxRunWait bounds each long-poll request made by Run in sync mode.
+	maxRunWait = 30 * time.Second
+	// minRunPollInterval spaces out polls to agents that answer long polls at once.
+	minRunPollInterval = 200 * time.Millisecond
+)
+
+// RunMode selects how Run exchanges messages with the agent.
+type RunMode int
+
+const (
+	// RunModeAuto streams if the agent card advertises streaming, and uses sync
+	// mode otherwise. It is the default.
+	RunModeAuto RunMode = iota
+	// RunModeStream always uses tasks/sendSubscribe.
+	RunModeStream
+	// RunModeSync always uses tasks/send, then long-polls tasks/get.
+	RunModeSync
+)
+
+// RunOption is a functional option type for configuring a single Run call.
+type RunOption func(*runOptions)
+
+// runOptions holds the per-call settings for Run.
+type runOptions struct {
+	mode         RunMode
+	maxAttempts  int
+	retryBackoff time.Duration
+}
+
+// WithRunMode forces Run to use mode instead of choosing from the agent card.
+func WithRunMode(mode RunMode) RunOption {
+	return func(o *runOptions) {
+		o.mode = mode
+	}
+}
+
+// WithRunRetry makes Run send the task again, up to maxAttempts times in all,
+// while it fails with an error the agent marked retryable (see
+// IsRetryableFailure). The wait before the n-th retry is backoff doubled n-1
+// times. Failures the agent did not mark retryable are returned at once.
+func WithRunRetry(maxAttempts int, backoff time.Duration) RunOption {
+	return func(o *runOptions) {
+		o.maxAttempts = maxAttempts
+		o.retryBackoff = backoff
+	}
+}
+
+// IsRetryableFailure reports whether task failed with an error the agent marked
+// retryable, so sending it again may succeed.
+func IsRetryableFailure(task *protocol.Task) bool {
+	return task != nil && task.Status.State == protocol.TaskStateFailed &&
+		task.Status.Error != nil && task.Status.Error.Retryable
+}
+
+// Run sends a task and returns it once it no longer needs the agent: in a final
+// state, or waiting for input. It streams when the agent supports streaming and
+// otherwise sends synchronously and waits for the task, so the application does
+// not depend on the transport. Either way the returned task is fetched with
+// tasks/get, honoring params.HistoryLength.
+func (c *A2AClient) Run(ctx context.Context, params protocol.SendTaskParams, opts ...RunOption) (*protocol.Task, error) {
+	runOpts := &runOptions{mode: RunModeAuto}
+	for _, opt := range opts {
+		opt(runOpts)
+	}
+	stream := runOpts.mode == RunModeStream
+	if runOpts.mode == RunModeAuto {
+		stream = c.agentSupportsStreaming(ctx)
+	}
+	for attempt := 1; ; attempt++ {
+		task, err := c.runOnce(ctx, params, stream)
+		if err != nil {
+			return nil, fmt.Errorf("a2aClient.Run: %w", err)
+		}
+		if attempt >= runOpts.maxAttempts || !IsRetryableFailure(task) {
+			return task, nil
+		}
+		log.Debugf("A2A Client: task %s failed with a retryable error, sending it again: %s",
+			params.ID, task.Status.Error.Message)
+		if err := sleepContext(ctx, runOpts.retryBackoff<<(attempt-1)); err != nil {
+			return nil, fmt.Errorf("a2aClient.Run: %w", err)
+		}
+	}
+}
+
+// runOnce runs the task until it is done and fetches it.
+func (c *A2AClient) runOnce(ctx context.Context, params protocol.SendTaskParams, stream bool) (*protocol.Task, error) {
+	var err error
+	if stream {
+		err = c.runStream(ctx, params)
+	} else {
+		err = c.runSync(ctx, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.GetTasks(ctx, protocol.TaskQueryParams{ID: params.ID, HistoryLength: params.HistoryLength})
+}
+
+// runDone reports whether Run can return a task in state.
+func runDone(state protocol.TaskState) bool {
+	return state.IsFinal() || state == protocol.TaskStateInputRequired
+}
+
+// runStream streams the task until it is done.
+func (c *A2AClient) runStream(ctx context.Context, params protocol.SendTaskParams) error {
+	// The stream of a task waiting for input may stay open; cancel it once done.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, err := c.StreamTask(ctx, params, WithEventFilter(protocol.StreamEventFilterStatus))
+	if err != nil {
+		return err
+	}
+	for event := range events {
+		switch e := event.(type) {
+		case protocol.TaskStreamErrorEvent:
+			return e.Err
+		case protocol.TaskStatusUpdateEvent:
+			if runDone(e.Status.State) {
+				return nil
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// runSync sends the task and long-polls it until it is done.
+func (c *A2AClient) runSync(ctx context.Context, params protocol.SendTaskParams) error {
+	task, err := c.SendTasks(ctx, params)
+	if err != nil {
+		return err
+	}
+	wait := maxRunWait
+	if timeout := c.httpClient.Timeout; timeout > 0 && timeout/2 < wait {
+		wait = timeout / 2 // Leave the agent time to answer before the client gives up.
+	}
+	for !runDone(task.Status.State) {
+		start := time.Now()
+		if task, err = c.WaitForTaskChange(ctx, params.ID, wait); err != nil {
+			return err
+		}
+		if elapsed := time.Since(start); elapsed < minRunPollInterval && !runDone(task.Status.State) {
+			select {
+			case <-time.After(minRunPollInterval - elapsed):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// ExportTask fetches a snapshot of a task, with its full history, artifacts and
+// lifecycle events, using the tasks/export method. The agent must serve task
+// snapshots (server.WithTaskSnapshots), which requires authentication.
+func (c *A2AClient) ExportTask(ctx context.Context, taskID string) (*protocol.TaskSnapshot, error) {
+	var snapshot protocol.TaskSnapshot
+	if err := c.call(ctx, protocol.MethodTasksExport, protocol.TaskIDParams{ID: taskID}, &snapshot); err != nil {
+		return nil, fmt.Errorf("a2aClient.ExportTask: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// ImportTask stores the task of snapshot on the agent using the tasks/import
+// method and returns it as stored. onConflict selects what happens if the agent
+// already has a task with the same ID; see protocol.ImportConflict. The agent must
+// serve task snapshots (server.WithTaskSnapshots), which requires authentication.
+func (c *A2AClient) ImportTask(
+	ctx context.Context,
+	snapshot protocol.TaskSnapshot,
+	onConflict protocol.ImportConflict,
+) (*protocol.Task, error) {
+	params := protocol.TaskImportParams{Snapshot: snapshot, OnConflict: onConflict}
+	var task protocol.Task
+	if err := c.call(ctx, protocol.MethodTasksImport, params, &task); err != nil {
+		return nil, fmt.Errorf("a2aClient.ImportTask: %w", err)
+	}
+	return &task, nil
+}
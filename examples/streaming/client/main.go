@@ -198,6 +198,9 @@ func processStreamEvents(ctx context.Context, streamChan <-chan protocol.TaskEve
 				if e.IsFinal() {
 					log.Info("Received final artifact update, waiting for final status.")
 				}
+			case protocol.TaskMessageEvent:
+				log.Infof("Received Message - TaskID: %s, Role: %s", e.ID, e.Message.Role)
+				log.Infof("  Message Parts: %+v", e.Message.Parts)
 			default:
 				log.Infof("Received unknown event type: %T %v", event, event)
 			}
@@ -0,0 +1,187 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// Client calls an A2A agent over gRPC. Its methods match those of client.A2AClient,
+// and errors reported by the agent are the same JSON-RPC errors.
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient creates a Client that calls the A2A service over conn.
+func NewClient(conn grpc.ClientConnInterface) (*Client, error) {
+	if conn == nil {
+		return nil, errors.New("NewClient requires a non-nil conn")
+	}
+	return &Client{conn: conn}, nil
+}
+
+// SendTasks sends a message using the SendTask method.
+func (c *Client) SendTasks(ctx context.Context, params protocol.SendTaskParams) (*protocol.Task, error) {
+	task := &protocol.Task{}
+	if err := c.invoke(ctx, methodSendTask, params, task); err != nil {
+		return nil, fmt.Errorf("grpcClient.SendTasks: %w", err)
+	}
+	return task, nil
+}
+
+// GetTasks retrieves the status of a task using the GetTask method.
+func (c *Client) GetTasks(ctx context.Context, params protocol.TaskQueryParams) (*protocol.Task, error) {
+	task := &protocol.Task{}
+	if err := c.invoke(ctx, methodGetTask, params, task); err != nil {
+		return nil, fmt.Errorf("grpcClient.GetTasks: %w", err)
+	}
+	return task, nil
+}
+
+// CancelTasks cancels an ongoing task using the CancelTask method.
+func (c *Client) CancelTasks(ctx context.Context, params protocol.TaskIDParams) (*protocol.Task, error) {
+	task := &protocol.Task{}
+	if err := c.invoke(ctx, methodCancelTask, params, task); err != nil {
+		return nil, fmt.Errorf("grpcClient.CancelTasks: %w", err)
+	}
+	return task, nil
+}
+
+// SetPushNotification configures push notifications for a task using the
+// SetPushNotification method.
+func (c *Client) SetPushNotification(
+	ctx context.Context,
+	params protocol.TaskPushNotificationConfig,
+) (*protocol.TaskPushNotificationConfig, error) {
+	config := &protocol.TaskPushNotificationConfig{}
+	if err := c.invoke(ctx, methodSetPushNotification, params, config); err != nil {
+		return nil, fmt.Errorf("grpcClient.SetPushNotification: %w", err)
+	}
+	return config, nil
+}
+
+// GetPushNotification retrieves the push notification config of a task using the
+// GetPushNotification method.
+func (c *Client) GetPushNotification(
+	ctx context.Context,
+	params protocol.TaskIDParams,
+) (*protocol.TaskPushNotificationConfig, error) {
+	config := &protocol.TaskPushNotificationConfig{}
+	if err := c.invoke(ctx, methodGetPushNotification, params, config); err != nil {
+		return nil, fmt.Errorf("grpcClient.GetPushNotification: %w", err)
+	}
+	return config, nil
+}
+
+// StreamTask sends a message using the SendTaskSubscribe method and returns a
+// channel of task events. The channel is closed after the final status event,
+// when the stream ends or when ctx is done. A stream that fails midway delivers
+// a protocol.TaskStreamErrorEvent as its last event.
+func (c *Client) StreamTask(ctx context.Context, params protocol.SendTaskParams) (<-chan protocol.TaskEvent, error) {
+	events, err := c.stream(ctx, streamSendTaskSubscribe, params.ID, params)
+	if err != nil {
+		return nil, fmt.Errorf("grpcClient.StreamTask: %w", err)
+	}
+	return events, nil
+}
+
+// ResubscribeTask reopens the event stream of an existing task using the
+// Resubscribe method. The channel behaves as the one returned by StreamTask.
+func (c *Client) ResubscribeTask(ctx context.Context, params protocol.TaskIDParams) (<-chan protocol.TaskEvent, error) {
+	events, err := c.stream(ctx, streamResubscribe, params.ID, params)
+	if err != nil {
+		return nil, fmt.Errorf("grpcClient.ResubscribeTask: %w", err)
+	}
+	return events, nil
+}
+
+// invoke makes a unary call of the named method.
+func (c *Client) invoke(ctx context.Context, method string, params, result any) error {
+	if err := c.conn.Invoke(ctx, fullMethod(method), params, result, grpc.CallContentSubtype(codecName)); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+// stream opens the streaming method at index in serviceDesc.Streams and
+// forwards its events to the returned channel.
+func (c *Client) stream(ctx context.Context, index int, taskID string, params any) (<-chan protocol.TaskEvent, error) {
+	desc := &serviceDesc.Streams[index]
+	stream, err := c.conn.NewStream(ctx, desc, fullMethod(desc.StreamName), grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	if err := stream.SendMsg(params); err != nil {
+		return nil, fromStatus(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, fromStatus(err)
+	}
+	// The server sends headers once the task manager accepts the subscription.
+	// A stream that ends without them was rejected, and its error is returned
+	// directly, as it is over HTTP, rather than as a stream error event.
+	header, err := stream.Header()
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	if header == nil {
+		if err := stream.RecvMsg(&event{}); !errors.Is(err, io.EOF) {
+			return nil, fromStatus(err)
+		}
+	}
+	eventsChan := make(chan protocol.TaskEvent, 10)
+	go func() {
+		defer close(eventsChan)
+		for {
+			taskEvent, err := recvEvent(stream)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				sendStreamError(ctx, eventsChan, taskID, err)
+				return
+			}
+			select {
+			case eventsChan <- taskEvent:
+			case <-ctx.Done():
+				return
+			}
+			if taskEvent.IsFinal() {
+				return
+			}
+		}
+	}()
+	return eventsChan, nil
+}
+
+// recvEvent reads the next event from stream. It returns io.EOF when the
+// server ends the stream normally.
+func recvEvent(stream grpc.ClientStream) (protocol.TaskEvent, error) {
+	var msg event
+	if err := stream.RecvMsg(&msg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fromStatus(err)
+	}
+	return decodeEvent(&msg)
+}
+
+// sendStreamError delivers err as the last event of the stream unless ctx is done.
+func sendStreamError(ctx context.Context, eventsChan chan<- protocol.TaskEvent, taskID string, err error) {
+	select {
+	case eventsChan <- protocol.TaskStreamErrorEvent{ID: taskID, Err: err}:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,39 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package grpc
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is the content subtype of the A2A service, sent as "application/grpc+a2a-json".
+const codecName = "a2a-json"
+
+// jsonCodec encodes gRPC messages as JSON so the service can carry the protocol
+// types directly, with the same wire shape they have over HTTP JSON-RPC.
+type jsonCodec struct{}
+
+// Marshal implements encoding.Codec.
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements encoding.Codec.
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// Name implements encoding.Codec.
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
@@ -0,0 +1,350 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"trpc.group/trpc-go/trpc-a2a-go/client"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/server"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// transport is the part of the A2A client API shared by client.A2AClient and Client.
+type transport interface {
+	SendTasks(ctx context.Context, params protocol.SendTaskParams) (*protocol.Task, error)
+	GetTasks(ctx context.Context, params protocol.TaskQueryParams) (*protocol.Task, error)
+	CancelTasks(ctx context.Context, params protocol.TaskIDParams) (*protocol.Task, error)
+	StreamTask(ctx context.Context, params protocol.SendTaskParams) (<-chan protocol.TaskEvent, error)
+}
+
+// echoProcessor reports progress, then completes with the upper-cased input and
+// the request locale as its artifact.
+type echoProcessor struct{}
+
+func (echoProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	text := msg.Parts[0].(protocol.TextPart).Text
+	if text == "wait" {
+		<-ctx.Done()
+		return nil
+	}
+	working := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("working")})
+	if err := handle.UpdateStatus(protocol.TaskStateWorking, &working); err != nil {
+		return err
+	}
+	done := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("done")})
+	return handle.Complete(done, protocol.Artifact{
+		Name:  stringPtr(taskmanager.LocaleFromContext(ctx)),
+		Parts: []protocol.Part{protocol.NewTextPart(strings.ToUpper(text))},
+		Index: 0,
+	})
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// newHTTPTransport serves a new memory task manager over HTTP JSON-RPC.
+func newHTTPTransport(t *testing.T) transport {
+	t.Helper()
+	tm, err := taskmanager.NewMemoryTaskManager(echoProcessor{})
+	require.NoError(t, err)
+	a2aServer, err := server.NewA2AServer(server.AgentCard{
+		Name:         "Echo Agent",
+		URL:          "http://localhost",
+		Version:      "1.0.0",
+		Capabilities: server.AgentCapabilities{Streaming: true},
+	}, tm)
+	require.NoError(t, err)
+	httpServer := httptest.NewServer(a2aServer.Handler())
+	t.Cleanup(httpServer.Close)
+	a2aClient, err := client.NewA2AClient(httpServer.URL)
+	require.NoError(t, err)
+	return httpTransport{a2aClient}
+}
+
+// httpTransport adapts client.A2AClient to transport, sending no per-call options.
+type httpTransport struct {
+	*client.A2AClient
+}
+
+func (h httpTransport) SendTasks(ctx context.Context, params protocol.SendTaskParams) (*protocol.Task, error) {
+	return h.A2AClient.SendTasks(ctx, params)
+}
+
+func (h httpTransport) StreamTask(
+	ctx context.Context,
+	params protocol.SendTaskParams,
+) (<-chan protocol.TaskEvent, error) {
+	return h.A2AClient.StreamTask(ctx, params)
+}
+
+// newGRPCTransport serves a new memory task manager over gRPC on an in-memory listener.
+func newGRPCTransport(t *testing.T) *Client {
+	t.Helper()
+	tm, err := taskmanager.NewMemoryTaskManager(echoProcessor{})
+	require.NoError(t, err)
+	a2aServer, err := NewServer(tm)
+	require.NoError(t, err)
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	a2aServer.Register(grpcServer)
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	grpcClient, err := NewClient(conn)
+	require.NoError(t, err)
+	return grpcClient
+}
+
+// normalize returns v as generic JSON without timestamps, for comparing results
+// across transports.
+func normalize(t *testing.T, v any) any {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var generic any
+	require.NoError(t, json.Unmarshal(data, &generic))
+	return dropTimestamps(generic)
+}
+
+func dropTimestamps(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		delete(value, "timestamp")
+		delete(value, "requestId") // Only JSON-RPC requests have an ID to record.
+		for key, item := range value {
+			value[key] = dropTimestamps(item)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = dropTimestamps(item)
+		}
+	}
+	return v
+}
+
+// collectEvents reads events until the channel closes.
+func collectEvents(t *testing.T, events <-chan protocol.TaskEvent) []protocol.TaskEvent {
+	t.Helper()
+	var collected []protocol.TaskEvent
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return collected
+			}
+			collected = append(collected, event)
+		case <-timeout:
+			t.Fatalf("timed out after %d events", len(collected))
+		}
+	}
+}
+
+func sendParams(id, text string) protocol.SendTaskParams {
+	return protocol.SendTaskParams{
+		ID:      id,
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)}),
+	}
+}
+
+// TestTransports_SameResults runs the same processor behind both transports and
+// checks that every call gives the same result.
+func TestTransports_SameResults(t *testing.T) {
+	ctx := context.Background()
+
+	// run calls fn against both transports and returns the normalized results.
+	run := func(t *testing.T, fn func(t *testing.T, tr transport) any) (httpResult, grpcResult any) {
+		return fn(t, newHTTPTransport(t)), fn(t, newGRPCTransport(t))
+	}
+
+	t.Run("SendTasks", func(t *testing.T) {
+		httpResult, grpcResult := run(t, func(t *testing.T, tr transport) any {
+			task, err := tr.SendTasks(ctx, sendParams("task-1", "hello"))
+			require.NoError(t, err)
+			assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+			return normalize(t, task)
+		})
+		assert.Equal(t, httpResult, grpcResult)
+	})
+
+	t.Run("SendTasks with locale", func(t *testing.T) {
+		httpResult, grpcResult := run(t, func(t *testing.T, tr transport) any {
+			params := sendParams("task-1", "hello")
+			params.Locale = stringPtr("fr-FR")
+			task, err := tr.SendTasks(ctx, params)
+			require.NoError(t, err)
+			require.Len(t, task.Artifacts, 1)
+			assert.Equal(t, "fr-FR", *task.Artifacts[0].Name)
+			return normalize(t, task)
+		})
+		assert.Equal(t, httpResult, grpcResult)
+	})
+
+	t.Run("GetTasks", func(t *testing.T) {
+		httpResult, grpcResult := run(t, func(t *testing.T, tr transport) any {
+			_, err := tr.SendTasks(ctx, sendParams("task-1", "hello"))
+			require.NoError(t, err)
+			historyLength := 10
+			task, err := tr.GetTasks(ctx, protocol.TaskQueryParams{ID: "task-1", HistoryLength: &historyLength})
+			require.NoError(t, err)
+			return normalize(t, task)
+		})
+		assert.Equal(t, httpResult, grpcResult)
+	})
+
+	t.Run("StreamTask", func(t *testing.T) {
+		httpResult, grpcResult := run(t, func(t *testing.T, tr transport) any {
+			events, err := tr.StreamTask(ctx, sendParams("task-1", "hello"))
+			require.NoError(t, err)
+			collected := collectEvents(t, events)
+			require.NotEmpty(t, collected)
+			assert.True(t, collected[len(collected)-1].IsFinal())
+			types := make([]string, len(collected))
+			for i, event := range collected {
+				types[i] = fmt.Sprintf("%T", event)
+			}
+			return []any{types, normalize(t, collected)}
+		})
+		assert.Equal(t, httpResult, grpcResult)
+	})
+
+	t.Run("CancelTasks", func(t *testing.T) {
+		httpResult, grpcResult := run(t, func(t *testing.T, tr transport) any {
+			events, err := tr.StreamTask(ctx, sendParams("task-1", "wait"))
+			require.NoError(t, err)
+			task, err := tr.CancelTasks(ctx, protocol.TaskIDParams{ID: "task-1"})
+			require.NoError(t, err)
+			assert.Equal(t, protocol.TaskStateCanceled, task.Status.State)
+			collectEvents(t, events)
+			return normalize(t, task)
+		})
+		assert.Equal(t, httpResult, grpcResult)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		httpResult, grpcResult := run(t, func(t *testing.T, tr transport) any {
+			var errCodes []int
+			_, err := tr.GetTasks(ctx, protocol.TaskQueryParams{ID: "missing"})
+			errCodes = append(errCodes, errorCode(t, err))
+			_, err = tr.CancelTasks(ctx, protocol.TaskIDParams{ID: "missing", Reason: protocol.CancelReasonTimeout})
+			errCodes = append(errCodes, errorCode(t, err))
+			_, err = tr.StreamTask(ctx, protocol.SendTaskParams{ID: "task-1"})
+			errCodes = append(errCodes, errorCode(t, err))
+			return errCodes
+		})
+		assert.Equal(t, []int{taskmanager.ErrCodeTaskNotFound, jsonrpc.CodeInvalidParams, jsonrpc.CodeInvalidParams},
+			grpcResult)
+		assert.Equal(t, httpResult, grpcResult)
+	})
+}
+
+// errorCodePattern finds the JSON-RPC error code in the HTTP client's error
+// message for responses with a non-200 status.
+var errorCodePattern = regexp.MustCompile(`"code":(-?\d+)`)
+
+// errorCode returns the JSON-RPC error code carried by err.
+func errorCode(t *testing.T, err error) int {
+	t.Helper()
+	require.Error(t, err)
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code
+	}
+	match := errorCodePattern.FindStringSubmatch(err.Error())
+	require.NotNil(t, match, "no JSON-RPC error code in %q", err)
+	code, convErr := strconv.Atoi(match[1])
+	require.NoError(t, convErr)
+	return code
+}
+
+// requireRPCError returns the JSON-RPC error wrapped in err.
+func requireRPCError(t *testing.T, err error) *jsonrpc.Error {
+	t.Helper()
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	return rpcErr
+}
+
+func TestToStatus(t *testing.T) {
+	t.Run("JSON-RPC error round trips", func(t *testing.T) {
+		err := toStatus(taskmanager.ErrTaskNotFound("task-1"))
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		rpcErr := requireRPCError(t, fromStatus(err))
+		assert.Equal(t, taskmanager.ErrCodeTaskNotFound, rpcErr.Code)
+		assert.Equal(t, taskmanager.ErrTaskNotFound("task-1").Message, rpcErr.Message)
+	})
+
+	t.Run("other errors are internal", func(t *testing.T) {
+		err := toStatus(errors.New("store down"))
+		assert.Equal(t, codes.Internal, status.Code(err))
+		rpcErr := requireRPCError(t, fromStatus(err))
+		assert.Equal(t, jsonrpc.CodeInternalError, rpcErr.Code)
+		assert.Equal(t, "store down", rpcErr.Data)
+	})
+
+	t.Run("plain status is unchanged", func(t *testing.T) {
+		err := status.Error(codes.Unavailable, "connection refused")
+		assert.Equal(t, err, fromStatus(err))
+	})
+}
+
+func TestServer_WithErrorVerbosity(t *testing.T) {
+	tm, err := taskmanager.NewMemoryTaskManager(echoProcessor{})
+	require.NoError(t, err)
+
+	t.Run("TerseByDefault", func(t *testing.T) {
+		s, err := NewServer(tm)
+		require.NoError(t, err)
+		rpcErr := requireRPCError(t, fromStatus(s.statusError(wrapError(errors.New("store down"), "failed"))))
+		assert.Equal(t, jsonrpc.CodeInternalError, rpcErr.Code)
+		assert.Nil(t, rpcErr.Data, "the cause of an internal error should be omitted")
+
+		// Errors caused by the request keep their details.
+		rpcErr = requireRPCError(t, fromStatus(s.statusError(jsonrpc.ErrInvalidParams("task ID is required"))))
+		assert.Equal(t, "task ID is required", rpcErr.Data)
+	})
+
+	t.Run("Verbose", func(t *testing.T) {
+		s, err := NewServer(tm, WithErrorVerbosity(server.ErrorVerbosityVerbose))
+		require.NoError(t, err)
+		rpcErr := requireRPCError(t, fromStatus(s.statusError(errors.New("store down"))))
+		assert.Equal(t, "store down", rpcErr.Data)
+	})
+}
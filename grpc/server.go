@@ -0,0 +1,276 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/textchunk"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/server"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// acceptLanguageMetadataKey is the request metadata key read as the locale
+// fallback, like the Accept-Language header over HTTP.
+const acceptLanguageMetadataKey = "accept-language"
+
+// Server serves a task manager as the A2A gRPC service.
+type Server struct {
+	taskManager    taskmanager.TaskManager
+	errorVerbosity server.ErrorVerbosity // Detail of the errors sent to clients.
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithErrorVerbosity sets how much detail the errors returned to clients carry,
+// like server.WithErrorVerbosity does for JSON-RPC over HTTP. The default,
+// server.ErrorVerbosityTerse, omits the causes of internal errors.
+func WithErrorVerbosity(level server.ErrorVerbosity) ServerOption {
+	return func(s *Server) {
+		s.errorVerbosity = level
+	}
+}
+
+// NewServer creates a Server for taskManager.
+func NewServer(taskManager taskmanager.TaskManager, opts ...ServerOption) (*Server, error) {
+	if taskManager == nil {
+		return nil, errors.New("NewServer requires a non-nil taskManager")
+	}
+	s := &Server{taskManager: taskManager}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Register registers the A2A service on registrar, typically a *grpc.Server.
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&serviceDesc, s)
+}
+
+// sendTask handles the SendTask method.
+func (s *Server) sendTask(ctx context.Context, params protocol.SendTaskParams) (*protocol.Task, error) {
+	if err := params.Validate(); err != nil {
+		return nil, s.statusError(jsonrpc.ErrInvalidParams(err))
+	}
+	ctx, err := resolveLocale(ctx, &params)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	ctx, err = taskmanager.ResolveArtifactReferences(ctx, s.taskManager, params.Message)
+	if err != nil {
+		return nil, s.statusError(wrapError(err, "failed to resolve artifact references"))
+	}
+	task, err := s.taskManager.OnSendTask(ctx, params)
+	if err != nil {
+		log.Errorf("Error calling OnSendTask for task %s: %v", params.ID, err)
+		return nil, s.statusError(wrapError(err, "task processing failed"))
+	}
+	return task, nil
+}
+
+// getTask handles the GetTask method.
+func (s *Server) getTask(ctx context.Context, params protocol.TaskQueryParams) (*protocol.Task, error) {
+	task, err := s.taskManager.OnGetTask(ctx, params)
+	if err != nil {
+		log.Errorf("Error calling OnGetTask for task %s: %v", params.ID, err)
+		return nil, s.statusError(wrapError(err, "failed to get task"))
+	}
+	return task, nil
+}
+
+// cancelTask handles the CancelTask method.
+func (s *Server) cancelTask(ctx context.Context, params protocol.TaskIDParams) (*protocol.Task, error) {
+	if params.Reason != "" && !params.Reason.IsValid() {
+		return nil, s.statusError(jsonrpc.ErrInvalidParams(fmt.Sprintf("unknown cancel reason %q", params.Reason)))
+	}
+	if params.Reason != "" && !params.Reason.IsClientRequestable() {
+		return nil, s.statusError(
+			jsonrpc.ErrInvalidParams(fmt.Sprintf("cancel reason %q is reserved for the server", params.Reason)))
+	}
+	task, err := s.taskManager.OnCancelTask(ctx, params)
+	if err != nil {
+		log.Errorf("Error calling OnCancelTask for task %s: %v", params.ID, err)
+		return nil, s.statusError(wrapError(err, "failed to cancel task"))
+	}
+	return task, nil
+}
+
+// setPushNotification handles the SetPushNotification method.
+func (s *Server) setPushNotification(
+	ctx context.Context,
+	params protocol.TaskPushNotificationConfig,
+) (*protocol.TaskPushNotificationConfig, error) {
+	if params.ID == "" {
+		return nil, s.statusError(jsonrpc.ErrInvalidParams("task ID is required"))
+	}
+	if params.PushNotificationConfig.URL == "" {
+		return nil, s.statusError(jsonrpc.ErrInvalidParams("push notification URL is required"))
+	}
+	config, err := s.taskManager.OnPushNotificationSet(ctx, params)
+	if err != nil {
+		log.Errorf("Error calling OnPushNotificationSet for task %s: %v", params.ID, err)
+		return nil, s.statusError(wrapError(err, "push notification setup failed"))
+	}
+	return config, nil
+}
+
+// getPushNotification handles the GetPushNotification method.
+func (s *Server) getPushNotification(
+	ctx context.Context,
+	params protocol.TaskIDParams,
+) (*protocol.TaskPushNotificationConfig, error) {
+	if params.ID == "" {
+		return nil, s.statusError(jsonrpc.ErrInvalidParams("task ID is required"))
+	}
+	config, err := s.taskManager.OnPushNotificationGet(ctx, params)
+	if err != nil {
+		log.Errorf("Error calling OnPushNotificationGet for task %s: %v", params.ID, err)
+		return nil, s.statusError(wrapError(err, "failed to get push notification config"))
+	}
+	return config, nil
+}
+
+// sendTaskSubscribe handles the SendTaskSubscribe method.
+func (s *Server) sendTaskSubscribe(params protocol.SendTaskParams, stream grpc.ServerStream) error {
+	if err := params.Validate(); err != nil {
+		return s.statusError(jsonrpc.ErrInvalidParams(err))
+	}
+	ctx, err := resolveLocale(stream.Context(), &params)
+	if err != nil {
+		return s.statusError(err)
+	}
+	ctx, err = taskmanager.ResolveArtifactReferences(ctx, s.taskManager, params.Message)
+	if err != nil {
+		return s.statusError(wrapError(err, "failed to resolve artifact references"))
+	}
+	events, err := s.taskManager.OnSendTaskSubscribe(ctx, params)
+	if err != nil {
+		log.Errorf("Error calling OnSendTaskSubscribe for task %s: %v", params.ID, err)
+		return s.statusError(jsonrpc.ErrInternalError(fmt.Sprintf("failed to subscribe to task events: %v", err)))
+	}
+	return forwardEvents(ctx, stream, events, params.ID)
+}
+
+// resubscribe handles the Resubscribe method.
+func (s *Server) resubscribe(params protocol.TaskIDParams, stream grpc.ServerStream) error {
+	if params.ID == "" {
+		return s.statusError(jsonrpc.ErrInvalidParams("task ID is required"))
+	}
+	ctx := stream.Context()
+	events, err := s.taskManager.OnResubscribe(ctx, params)
+	if err != nil {
+		log.Errorf("Error calling OnResubscribe for task %s: %v", params.ID, err)
+		return s.statusError(wrapError(err, "failed to resubscribe to task events"))
+	}
+	return forwardEvents(ctx, stream, events, params.ID)
+}
+
+// forwardEvents streams task events until the final status event, the channel
+// closes or the client goes away.
+func forwardEvents(
+	ctx context.Context,
+	stream grpc.ServerStream,
+	events <-chan protocol.TaskEvent,
+	taskID string,
+) error {
+	// Send headers now so the client knows the subscription was accepted.
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+	// Keep text chunks on rune boundaries; split runes would not survive JSON encoding.
+	runeCarry := textchunk.NewCarry()
+	for {
+		select {
+		case taskEvent, ok := <-events:
+			if !ok {
+				return nil
+			}
+			var terminal bool
+			switch e := taskEvent.(type) {
+			case protocol.TaskStatusUpdateEvent:
+				// Terminal states always carry final=true so clients know to stop reading.
+				if e.Status.State.IsFinal() {
+					e.Final = true
+					taskEvent = e
+				}
+				terminal = e.Final
+			case protocol.TaskArtifactUpdateEvent:
+				taskEvent = runeCarry.Apply(e)
+			}
+			msg, err := encodeEvent(taskEvent)
+			if err != nil {
+				log.Warnf("Skipping event for task %s: %v", taskID, err)
+				continue
+			}
+			if err := stream.SendMsg(msg); err != nil {
+				log.Errorf("Error sending event for task %s (client likely disconnected): %v", taskID, err)
+				return err
+			}
+			// Stop after the final status event, even if the manager keeps the channel open.
+			if terminal {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// resolveLocale determines the locale for a send request as the HTTP server
+// does, falling back to the accept-language metadata and then the default locale.
+func resolveLocale(ctx context.Context, params *protocol.SendTaskParams) (context.Context, error) {
+	locale := protocol.DefaultLocale
+	if params.Locale != nil && *params.Locale != "" {
+		if err := protocol.ValidateLocale(*params.Locale); err != nil {
+			return ctx, jsonrpc.ErrInvalidParams(err.Error())
+		}
+		locale = *params.Locale
+	} else if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if header := md.Get(acceptLanguageMetadataKey); len(header) > 0 {
+			if tags := protocol.ParseAcceptLanguage(header[0]); len(tags) > 0 {
+				locale = tags[0]
+			}
+		}
+	}
+	params.Locale = &locale
+	return taskmanager.WithLocale(ctx, locale), nil
+}
+
+// statusError converts err into a gRPC status error like toStatus, with the data
+// of internal errors omitted unless the error verbosity is verbose.
+func (s *Server) statusError(err error) error {
+	var rpcErr *jsonrpc.Error
+	if !errors.As(err, &rpcErr) {
+		rpcErr = jsonrpc.ErrInternalError(err.Error())
+	}
+	if s.errorVerbosity != server.ErrorVerbosityVerbose {
+		if redacted := jsonrpc.WithoutInternalData(rpcErr); redacted != rpcErr {
+			log.Debugf("Omitting internal error details from response: %v", rpcErr.Data)
+			rpcErr = redacted
+		}
+	}
+	return toStatus(rpcErr)
+}
+
+// wrapError returns err if it is already a JSON-RPC error, and an internal error
+// prefixed with msg otherwise.
+func wrapError(err error, msg string) error {
+	if rpcErr, ok := err.(*jsonrpc.Error); ok {
+		return rpcErr
+	}
+	return jsonrpc.ErrInternalError(fmt.Sprintf("%s: %v", msg, err))
+}
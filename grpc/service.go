@@ -0,0 +1,305 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+// Package grpc serves the A2A protocol over gRPC, as an alternative to the HTTP
+// JSON-RPC transport of the server and client packages.
+//
+// The service mirrors the A2A methods one to one and carries the protocol types
+// encoded as JSON, so no generated code is needed and a taskmanager.TaskManager
+// behaves the same over either transport. Errors keep their JSON-RPC code and
+// data, attached to the gRPC status as an ErrorInfo detail.
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// ServiceName is the fully qualified name of the A2A gRPC service.
+const ServiceName = "a2a.A2AService"
+
+// Method names of the A2A gRPC service, one per A2A protocol method.
+const (
+	methodSendTask            = "SendTask"
+	methodGetTask             = "GetTask"
+	methodCancelTask          = "CancelTask"
+	methodSetPushNotification = "SetPushNotification"
+	methodGetPushNotification = "GetPushNotification"
+	methodSendTaskSubscribe   = "SendTaskSubscribe"
+	methodResubscribe         = "Resubscribe"
+)
+
+// errorInfoReason marks the ErrorInfo detail that carries the JSON-RPC error.
+const errorInfoReason = "A2A_ERROR"
+
+// errorInfoDomain is the ErrorInfo domain of A2A errors.
+const errorInfoDomain = "a2a"
+
+// errorMetadataKey is the ErrorInfo metadata key holding the JSON-encoded JSON-RPC error.
+const errorMetadataKey = "error"
+
+// a2aService is the handler type of the service, implemented by Server.
+type a2aService interface {
+	sendTask(ctx context.Context, params protocol.SendTaskParams) (*protocol.Task, error)
+	getTask(ctx context.Context, params protocol.TaskQueryParams) (*protocol.Task, error)
+	cancelTask(ctx context.Context, params protocol.TaskIDParams) (*protocol.Task, error)
+	setPushNotification(
+		ctx context.Context, params protocol.TaskPushNotificationConfig,
+	) (*protocol.TaskPushNotificationConfig, error)
+	getPushNotification(ctx context.Context, params protocol.TaskIDParams) (*protocol.TaskPushNotificationConfig, error)
+	sendTaskSubscribe(params protocol.SendTaskParams, stream grpc.ServerStream) error
+	resubscribe(params protocol.TaskIDParams, stream grpc.ServerStream) error
+}
+
+// serviceDesc describes the A2A service. Streams are listed in the order
+// streamSendTaskSubscribe and streamResubscribe index them.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*a2aService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: methodSendTask,
+			Handler: unaryHandler(methodSendTask,
+				func(s a2aService, ctx context.Context, params protocol.SendTaskParams) (any, error) {
+					return s.sendTask(ctx, params)
+				}),
+		},
+		{
+			MethodName: methodGetTask,
+			Handler: unaryHandler(methodGetTask,
+				func(s a2aService, ctx context.Context, params protocol.TaskQueryParams) (any, error) {
+					return s.getTask(ctx, params)
+				}),
+		},
+		{
+			MethodName: methodCancelTask,
+			Handler: unaryHandler(methodCancelTask,
+				func(s a2aService, ctx context.Context, params protocol.TaskIDParams) (any, error) {
+					return s.cancelTask(ctx, params)
+				}),
+		},
+		{
+			MethodName: methodSetPushNotification,
+			Handler: unaryHandler(methodSetPushNotification,
+				func(s a2aService, ctx context.Context, params protocol.TaskPushNotificationConfig) (any, error) {
+					return s.setPushNotification(ctx, params)
+				}),
+		},
+		{
+			MethodName: methodGetPushNotification,
+			Handler: unaryHandler(methodGetPushNotification,
+				func(s a2aService, ctx context.Context, params protocol.TaskIDParams) (any, error) {
+					return s.getPushNotification(ctx, params)
+				}),
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: methodSendTaskSubscribe,
+			Handler: streamHandler(func(s a2aService, params protocol.SendTaskParams, stream grpc.ServerStream) error {
+				return s.sendTaskSubscribe(params, stream)
+			}),
+			ServerStreams: true,
+		},
+		{
+			StreamName: methodResubscribe,
+			Handler: streamHandler(func(s a2aService, params protocol.TaskIDParams, stream grpc.ServerStream) error {
+				return s.resubscribe(params, stream)
+			}),
+			ServerStreams: true,
+		},
+	},
+	Metadata: "a2a.proto",
+}
+
+// Indexes of the streaming methods in serviceDesc.Streams.
+const (
+	streamSendTaskSubscribe = 0
+	streamResubscribe       = 1
+)
+
+// fullMethod returns the gRPC method path of the named service method.
+func fullMethod(method string) string {
+	return "/" + ServiceName + "/" + method
+}
+
+// unaryHandler adapts a typed unary call to a grpc.MethodDesc handler,
+// running it through the server's unary interceptor if one is set.
+func unaryHandler[P any](
+	method string,
+	call func(s a2aService, ctx context.Context, params P) (any, error),
+) func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		var params P
+		if err := dec(&params); err != nil {
+			return nil, toStatus(jsonrpc.ErrParseError(err.Error()))
+		}
+		if interceptor == nil {
+			return call(srv.(a2aService), ctx, params)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod(method)}
+		return interceptor(ctx, &params, info, func(ctx context.Context, req any) (any, error) {
+			return call(srv.(a2aService), ctx, *req.(*P))
+		})
+	}
+}
+
+// streamHandler adapts a typed server-streaming call to a grpc.StreamDesc handler.
+// The request message is read before the call.
+func streamHandler[P any](
+	call func(s a2aService, params P, stream grpc.ServerStream) error,
+) grpc.StreamHandler {
+	return func(srv any, stream grpc.ServerStream) error {
+		var params P
+		if err := stream.RecvMsg(&params); err != nil {
+			return toStatus(jsonrpc.ErrParseError(err.Error()))
+		}
+		return call(srv.(a2aService), params, stream)
+	}
+}
+
+// event is the message streamed by the subscribe methods. Type is one of the
+// protocol.EventTask* names, as used for SSE event types, and Event is the
+// JSON encoding of the matching protocol event.
+type event struct {
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// encodeEvent wraps a task event for the stream.
+func encodeEvent(taskEvent protocol.TaskEvent) (*event, error) {
+	var eventType string
+	switch taskEvent.(type) {
+	case protocol.TaskStatusUpdateEvent:
+		eventType = protocol.EventTaskStatusUpdate
+	case protocol.TaskArtifactUpdateEvent:
+		eventType = protocol.EventTaskArtifactUpdate
+	case protocol.TaskArtifactPatchEvent:
+		eventType = protocol.EventTaskArtifactPatch
+	case protocol.TaskArtifactDeleteEvent:
+		eventType = protocol.EventTaskArtifactDelete
+	case protocol.TaskMessageEvent:
+		eventType = protocol.EventTaskMessage
+	default:
+		return nil, fmt.Errorf("unknown event type %T", taskEvent)
+	}
+	data, err := json.Marshal(taskEvent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return &event{Type: eventType, Event: data}, nil
+}
+
+// decodeEvent unwraps a streamed event into its protocol type.
+func decodeEvent(e *event) (protocol.TaskEvent, error) {
+	switch e.Type {
+	case protocol.EventTaskStatusUpdate:
+		var statusEvent protocol.TaskStatusUpdateEvent
+		if err := json.Unmarshal(e.Event, &statusEvent); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s event: %w", e.Type, err)
+		}
+		return statusEvent, nil
+	case protocol.EventTaskArtifactUpdate:
+		var artifactEvent protocol.TaskArtifactUpdateEvent
+		if err := json.Unmarshal(e.Event, &artifactEvent); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s event: %w", e.Type, err)
+		}
+		return artifactEvent, nil
+	case protocol.EventTaskArtifactPatch:
+		var patchEvent protocol.TaskArtifactPatchEvent
+		if err := json.Unmarshal(e.Event, &patchEvent); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s event: %w", e.Type, err)
+		}
+		if err := protocol.ValidatePatch(patchEvent.Patch); err != nil {
+			return nil, err
+		}
+		return patchEvent, nil
+	case protocol.EventTaskArtifactDelete:
+		var deleteEvent protocol.TaskArtifactDeleteEvent
+		if err := json.Unmarshal(e.Event, &deleteEvent); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s event: %w", e.Type, err)
+		}
+		return deleteEvent, nil
+	case protocol.EventTaskMessage:
+		var messageEvent protocol.TaskMessageEvent
+		if err := json.Unmarshal(e.Event, &messageEvent); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s event: %w", e.Type, err)
+		}
+		return messageEvent, nil
+	default:
+		return nil, fmt.Errorf("unknown event type %q", e.Type)
+	}
+}
+
+// toStatus converts a JSON-RPC error into a gRPC status error carrying it as an
+// ErrorInfo detail. Other errors become Internal errors.
+func toStatus(err error) error {
+	var rpcErr *jsonrpc.Error
+	if !errors.As(err, &rpcErr) {
+		rpcErr = jsonrpc.ErrInternalError(err.Error())
+	}
+	st := status.New(grpcCode(rpcErr.Code), rpcErr.Message)
+	encoded, encodeErr := json.Marshal(rpcErr)
+	if encodeErr != nil {
+		return st.Err()
+	}
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   errorInfoReason,
+		Domain:   errorInfoDomain,
+		Metadata: map[string]string{errorMetadataKey: string(encoded)},
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// fromStatus recovers the JSON-RPC error attached by toStatus. Errors without
+// one are returned unchanged.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetReason() != errorInfoReason || info.GetDomain() != errorInfoDomain {
+			continue
+		}
+		var rpcErr jsonrpc.Error
+		if json.Unmarshal([]byte(info.GetMetadata()[errorMetadataKey]), &rpcErr) == nil {
+			return &rpcErr
+		}
+	}
+	return err
+}
+
+// grpcCode maps a JSON-RPC error code to the closest gRPC status code.
+func grpcCode(code int) codes.Code {
+	switch code {
+	case jsonrpc.CodeParseError, jsonrpc.CodeInvalidRequest, jsonrpc.CodeInvalidParams:
+		return codes.InvalidArgument
+	case jsonrpc.CodeMethodNotFound:
+		return codes.Unimplemented
+	case taskmanager.ErrCodeTaskNotFound:
+		return codes.NotFound
+	case taskmanager.ErrCodeTaskFinal, taskmanager.ErrCodePushNotificationNotConfigured:
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,42 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+// Package checksum fills in artifact checksums for the task managers.
+package checksum
+
+import (
+	"encoding/base64"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// EnsureArtifact populates artifact.Checksum when it is not already set and the
+// artifact is a complete, single file part with inline bytes.
+// Chunked artifacts are skipped because a per-chunk checksum would not match the
+// assembled content. Artifacts referencing content by URI must have their checksum
+// set by the TaskProcessor.
+func EnsureArtifact(artifact *protocol.Artifact) {
+	if artifact.Checksum != nil || len(artifact.Parts) != 1 || isChunk(*artifact) {
+		return
+	}
+	filePart, ok := artifact.Parts[0].(protocol.FilePart)
+	if !ok || filePart.File.Bytes == nil {
+		return
+	}
+	content, err := base64.StdEncoding.DecodeString(*filePart.File.Bytes)
+	if err != nil {
+		return
+	}
+	sum := protocol.ComputeChecksum(content)
+	artifact.Checksum = &sum
+}
+
+// isChunk reports whether the artifact is part of a multi-chunk stream.
+func isChunk(artifact protocol.Artifact) bool {
+	appending := artifact.Append != nil && *artifact.Append
+	moreChunks := artifact.LastChunk != nil && !*artifact.LastChunk
+	return appending || moreChunks
+}
@@ -0,0 +1,53 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package checksum
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+func TestEnsureArtifact(t *testing.T) {
+	content := []byte("artifact content")
+	encoded := base64.StdEncoding.EncodeToString(content)
+	uri := "https://example.com/artifact.bin"
+	yes, no := true, false
+	filePart := protocol.FilePart{Type: protocol.PartTypeFile, File: protocol.FileContent{Bytes: &encoded}}
+
+	tests := []struct {
+		name     string
+		artifact protocol.Artifact
+		want     bool
+	}{
+		{"inline bytes", protocol.Artifact{Parts: []protocol.Part{filePart}}, true},
+		{"single last chunk", protocol.Artifact{Parts: []protocol.Part{filePart}, LastChunk: &yes}, true},
+		{"first of several chunks", protocol.Artifact{Parts: []protocol.Part{filePart}, LastChunk: &no}, false},
+		{"appended chunk", protocol.Artifact{Parts: []protocol.Part{filePart}, Append: &yes, LastChunk: &yes}, false},
+		{"uri", protocol.Artifact{Parts: []protocol.Part{protocol.FilePart{
+			Type: protocol.PartTypeFile,
+			File: protocol.FileContent{URI: &uri},
+		}}}, false},
+		{"text", protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart("text")}}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			artifact := tc.artifact
+			EnsureArtifact(&artifact)
+			if !tc.want {
+				assert.Nil(t, artifact.Checksum)
+				return
+			}
+			require.NotNil(t, artifact.Checksum)
+			assert.Equal(t, protocol.ComputeChecksum(content), *artifact.Checksum)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+// Package jsonlimit bounds the shape of untrusted JSON before it is decoded.
+package jsonlimit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxDepth is the nesting depth allowed when no other limit is configured.
+const DefaultMaxDepth = 64
+
+// ErrTooDeep is returned by CheckDepth for JSON nested deeper than allowed.
+var ErrTooDeep = errors.New("json nested too deeply")
+
+// CheckDepth returns an error wrapping ErrTooDeep if data nests objects and
+// arrays more than maxDepth levels deep. It only tracks nesting: data that is
+// not valid JSON is left for the decoder to reject. A non-positive maxDepth
+// disables the check.
+func CheckDepth(data []byte, maxDepth int) error {
+	if maxDepth <= 0 {
+		return nil
+	}
+	depth := 0
+	inString, escaped := false, false
+	for i, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("%w: more than %d levels at offset %d", ErrTooDeep, maxDepth, i)
+			}
+		case '}', ']':
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package jsonlimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDepth(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		max     int
+		tooDeep bool
+	}{
+		{name: "Flat", data: `{"a": 1, "b": [1, 2]}`, max: 2},
+		{name: "AtLimit", data: `{"a": [{"b": 1}]}`, max: 3},
+		{name: "OverLimit", data: `{"a": [{"b": [1]}]}`, max: 3, tooDeep: true},
+		{name: "BracketsInStrings", data: `{"a": "[[[[{{{{", "b": "\"[[["}`, max: 1},
+		{name: "EscapedBackslash", data: `{"a": "\\", "b": [[1]]}`, max: 2, tooDeep: true},
+		{name: "Truncated", data: `[[[[`, max: 3, tooDeep: true},
+		{name: "Unbalanced", data: `]]]]{}`, max: 1},
+		{name: "Disabled", data: strings.Repeat("[", 1000), max: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckDepth([]byte(tc.data), tc.max)
+			if tc.tooDeep {
+				assert.ErrorIs(t, err, ErrTooDeep)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func FuzzCheckDepth(f *testing.F) {
+	for _, seed := range []string{`{}`, `[[1]]`, `{"a":"[\"]"}`, `"\\"`, `[{"a":[{"b":[]}]}]`} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if !json.Valid(data) {
+			_ = CheckDepth(data, 3)
+			return
+		}
+		// For valid JSON the check agrees with the real nesting depth.
+		var v interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber() // Huge numbers are valid JSON but overflow float64.
+		if err := decoder.Decode(&v); err != nil {
+			t.Fatalf("valid JSON failed to decode: %v", err)
+		}
+		depth := valueDepth(v)
+		if err := CheckDepth(data, depth); err != nil {
+			t.Fatalf("depth %d rejected at its own depth: %v", depth, err)
+		}
+		if depth > 1 && CheckDepth(data, depth-1) == nil {
+			t.Fatalf("depth %d accepted at limit %d", depth, depth-1)
+		}
+	})
+}
+
+// valueDepth returns the nesting depth of a decoded JSON value.
+func valueDepth(v interface{}) int {
+	var children []interface{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			children = append(children, child)
+		}
+	case []interface{}:
+		children = v
+	default:
+		return 0
+	}
+	deepest := 0
+	for _, child := range children {
+		deepest = max(deepest, valueDepth(child))
+	}
+	return deepest + 1
+}
@@ -0,0 +1,88 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package jsonrpc
+
+import "fmt"
+
+// A2A-specific JSON-RPC error codes, in the server-error range, as defined by the
+// A2A specification.
+const (
+	// CodeTaskNotFound indicates the task ID does not match a task.
+	CodeTaskNotFound = -32001
+	// CodeTaskNotCancelable indicates the task cannot be canceled, e.g. because it
+	// is already in a final state.
+	CodeTaskNotCancelable = -32002
+	// CodePushNotificationNotSupported indicates the agent does not support push
+	// notifications.
+	CodePushNotificationNotSupported = -32003
+	// CodeUnsupportedOperation indicates the operation is not supported by the agent.
+	CodeUnsupportedOperation = -32004
+	// CodeContentTypeNotSupported indicates the content types of the request are
+	// not compatible with the agent.
+	CodeContentTypeNotSupported = -32005
+	// CodeInvalidAgentResponse indicates the agent returned a response that does
+	// not conform to the specification.
+	CodeInvalidAgentResponse = -32006
+)
+
+// NewError creates an Error with the given code, message and optional data.
+func NewError(code int, message string, data interface{}) *Error {
+	return &Error{Code: code, Message: message, Data: data}
+}
+
+// WithoutInternalData returns err without its data if it is an internal error,
+// whose data may reveal causes and internal messages, and err itself otherwise.
+// It leaves err unchanged.
+func WithoutInternalData(err *Error) *Error {
+	if err.Code != CodeInternalError || err.Data == nil {
+		return err
+	}
+	redacted := *err
+	redacted.Data = nil
+	return &redacted
+}
+
+// --- A2A Error Constructors ---
+
+// NewTaskNotFoundError creates the A2A Task Not Found error (-32001) for taskID.
+func NewTaskNotFoundError(taskID string) *Error {
+	return NewError(CodeTaskNotFound, "Task not found",
+		fmt.Sprintf("Task with ID '%s' was not found.", taskID))
+}
+
+// NewTaskNotCancelableError creates the A2A Task Not Cancelable error (-32002) for
+// taskID.
+func NewTaskNotCancelableError(taskID string) *Error {
+	return NewError(CodeTaskNotCancelable, "Task cannot be canceled",
+		fmt.Sprintf("Task with ID '%s' cannot be canceled.", taskID))
+}
+
+// NewPushNotificationNotSupportedError creates the A2A Push Notification Not
+// Supported error (-32003).
+func NewPushNotificationNotSupportedError() *Error {
+	return NewError(CodePushNotificationNotSupported, "Push Notification is not supported", nil)
+}
+
+// NewUnsupportedOperationError creates the A2A Unsupported Operation error (-32004)
+// for the named operation, such as a JSON-RPC method.
+func NewUnsupportedOperationError(operation string) *Error {
+	return NewError(CodeUnsupportedOperation, "This operation is not supported",
+		fmt.Sprintf("Operation '%s' is not supported.", operation))
+}
+
+// NewContentTypeNotSupportedError creates the A2A Content Type Not Supported error
+// (-32005) for the given MIME types.
+func NewContentTypeNotSupportedError(contentTypes ...string) *Error {
+	return NewError(CodeContentTypeNotSupported, "Incompatible content types",
+		fmt.Sprintf("Content types %q are not supported.", contentTypes))
+}
+
+// NewInvalidAgentResponseError creates the A2A Invalid Agent Response error
+// (-32006), with detail describing what is wrong with the response.
+func NewInvalidAgentResponseError(detail string) *Error {
+	return NewError(CodeInvalidAgentResponse, "Invalid agent response", detail)
+}
@@ -206,6 +206,48 @@ func TestErrorCreation(t *testing.T) {
 	}
 }
 
+func TestA2AErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        *Error
+		expectJSON string
+	}{
+		{"NewError", NewError(-32050, "size too large", map[string]int{"max": 100}),
+			`{"code":-32050,"message":"size too large","data":{"max":100}}`},
+		{"TaskNotFound", NewTaskNotFoundError("task-1"),
+			`{"code":-32001,"message":"Task not found","data":"Task with ID 'task-1' was not found."}`},
+		{"TaskNotCancelable", NewTaskNotCancelableError("task-1"),
+			`{"code":-32002,"message":"Task cannot be canceled","data":"Task with ID 'task-1' cannot be canceled."}`},
+		{"PushNotificationNotSupported", NewPushNotificationNotSupportedError(),
+			`{"code":-32003,"message":"Push Notification is not supported"}`},
+		{"UnsupportedOperation", NewUnsupportedOperationError("tasks/export"),
+			`{"code":-32004,"message":"This operation is not supported","data":"Operation 'tasks/export' is not supported."}`},
+		{"ContentTypeNotSupported", NewContentTypeNotSupportedError("image/png", "video/mp4"),
+			`{"code":-32005,"message":"Incompatible content types",` +
+				`"data":"Content types [\"image/png\" \"video/mp4\"] are not supported."}`},
+		{"InvalidAgentResponse", NewInvalidAgentResponseError("result is not a task"),
+			`{"code":-32006,"message":"Invalid agent response","data":"result is not a task"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.err)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expectJSON, string(data))
+
+			// The error survives a round trip through a response.
+			resp := NewErrorResponse("req-1", tc.err)
+			data, err = json.Marshal(resp)
+			require.NoError(t, err)
+			var decoded Response
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			require.NotNil(t, decoded.Error)
+			assert.Equal(t, tc.err.Code, decoded.Error.Code)
+			assert.Equal(t, tc.err.Message, decoded.Error.Message)
+		})
+	}
+}
+
 func TestNewRequest(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -435,3 +477,75 @@ func TestNewNotificationResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateID(t *testing.T) {
+	valid := []string{`"abc"`, `""`, `0`, `42`, `-7`, `1e3`, `null`}
+	for _, raw := range valid {
+		var id interface{}
+		require.NoError(t, json.Unmarshal([]byte(raw), &id))
+		assert.NoError(t, ValidateID(id), raw)
+	}
+	invalid := []string{`1.5`, `-0.25`, `true`, `{"n":1}`, `[1]`}
+	for _, raw := range invalid {
+		var id interface{}
+		require.NoError(t, json.Unmarshal([]byte(raw), &id))
+		assert.Error(t, ValidateID(id), raw)
+	}
+	assert.NoError(t, ValidateID(json.Number("12")))
+	assert.Error(t, ValidateID(json.Number("1.2")))
+}
+
+// FuzzRequestUnmarshal checks that decoding arbitrary requests and validating
+// their ids never panics, and that decoded requests survive a round trip.
+func FuzzRequestUnmarshal(f *testing.F) {
+	for _, seed := range []string{
+		`{"jsonrpc":"2.0","method":"tasks/get","params":{"id":"t"},"id":1}`,
+		`{"jsonrpc":"2.0","method":"m","id":1e400}`,
+		`{"jsonrpc":"2.0","method":"m","id":-9223372036854775809}`,
+		`{"jsonrpc":"2.0","method":"m","id":[{}]}`,
+		`{"jsonrpc":"2.0","method":"m","params":`,
+		`{"id":"\ud800"}`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var request Request
+		if err := json.Unmarshal(data, &request); err != nil {
+			return
+		}
+		_ = ValidateID(request.ID)
+		encoded, err := json.Marshal(request)
+		if err != nil {
+			t.Fatalf("decoded request failed to encode: %v", err)
+		}
+		var again Request
+		if err := json.Unmarshal(encoded, &again); err != nil {
+			t.Fatalf("encoded request failed to decode: %v: %s", err, encoded)
+		}
+	})
+}
+
+// FuzzResponseUnmarshal checks that decoding arbitrary responses never panics
+// and that a decoded error can always be formatted.
+func FuzzResponseUnmarshal(f *testing.F) {
+	for _, seed := range []string{
+		`{"jsonrpc":"2.0","result":{"id":"t"},"id":1}`,
+		`{"jsonrpc":"2.0","error":{"code":-32600,"message":"bad","data":[1]},"id":null}`,
+		`{"jsonrpc":"2.0","error":null,"result":null}`,
+		`{"jsonrpc":"2.0","error":{"code":1.5}}`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var response RawResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return
+		}
+		if response.Error != nil {
+			_ = response.Error.Error()
+		}
+		if _, err := json.Marshal(response); err != nil {
+			t.Fatalf("decoded response failed to encode: %v", err)
+		}
+	})
+}
@@ -6,7 +6,11 @@
 
 package jsonrpc
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
 
 // Request represents a JSON-RPC request object.
 type Request struct {
@@ -29,3 +33,24 @@ func NewRequest(method string, id interface{}) *Request {
 		Method: method,
 	}
 }
+
+// ValidateID checks that id, as decoded from JSON, is a type the JSON-RPC 2.0 spec
+// allows: a string, a number without a fractional part, or null.
+func ValidateID(id interface{}) error {
+	switch v := id.(type) {
+	case nil, string:
+		return nil
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("id must not have a fractional part, got %v", v)
+		}
+		return nil
+	case json.Number:
+		if _, err := v.Int64(); err != nil {
+			return fmt.Errorf("id must be an integer, got %s", v)
+		}
+		return nil
+	default:
+		return fmt.Errorf("id must be a string, number or null, got %T", id)
+	}
+}
@@ -65,6 +65,8 @@ type Error struct {
 	// The value of this member is defined by the Server (e.g. detailed error
 	// information, nested errors etc.).
 	Data interface{} `json:"data,omitempty"`
+
+	cause error // Go error decoded from Data, returned by Unwrap.
 }
 
 // Error implements the standard Go error interface for JSONRPCError, providing
@@ -76,6 +78,17 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
 }
 
+// SetCause records the Go error the error's Data decodes to, so errors.As can
+// find it through the JSON-RPC error.
+func (e *Error) SetCause(cause error) {
+	e.cause = cause
+}
+
+// Unwrap returns the cause set with SetCause, if any.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
 // --- Standard Error Constructors ---
 
 // ErrParseError creates a standard Parse Error (-32700) JSONRPCError.
@@ -0,0 +1,83 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+// Package redact hides secrets in JSON documents before they are logged.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Placeholder replaces the value of a sensitive field.
+const Placeholder = "[REDACTED]"
+
+// sensitiveKeys are the names of the fields whose values are redacted, such as the
+// token of a push notification config or authentication credentials, lowercased and
+// without separators.
+var sensitiveKeys = map[string]bool{
+	"token":         true,
+	"accesstoken":   true,
+	"refreshtoken":  true,
+	"idtoken":       true,
+	"credentials":   true,
+	"password":      true,
+	"secret":        true,
+	"clientsecret":  true,
+	"apikey":        true,
+	"authorization": true,
+}
+
+// keySeparators are dropped from field names before looking them up in sensitiveKeys,
+// so api_key and api-key match apikey.
+var keySeparators = strings.NewReplacer("_", "", "-", "")
+
+// JSON returns data, a JSON document, with the value of every sensitive field, at
+// any depth, replaced by Placeholder. Data that is not valid JSON is not shown,
+// since its secrets could not be found.
+func JSON(data []byte) string {
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Show numbers as they were sent.
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Sprintf("[%d bytes of invalid JSON]", len(data))
+	}
+	redacted, err := json.Marshal(value(doc))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes of unencodable JSON]", len(data))
+	}
+	return string(redacted)
+}
+
+// Value returns v encoded as JSON with its sensitive fields redacted, see JSON.
+func Value(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("[unencodable %T]", v)
+	}
+	return JSON(data)
+}
+
+// value redacts the sensitive fields of a decoded JSON value in place.
+func value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveKeys[keySeparators.Replace(strings.ToLower(key))] {
+				v[key] = Placeholder
+				continue
+			}
+			v[key] = value(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = value(item)
+		}
+	}
+	return v
+}
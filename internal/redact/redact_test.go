@@ -0,0 +1,38 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+	t.Run("RedactsSensitiveFields", func(t *testing.T) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"tasks/pushNotification/set","params":{
+			"id":"task-1","pushNotificationConfig":{"url":"https://hook.example.com","token":"s3cret",
+			"authentication":{"schemes":["bearer"],"credentials":"hunter2"}},
+			"metadata":{"items":[{"API_KEY":"k"},{"count":12345678901234567890}]}}}`
+		redacted := JSON([]byte(body))
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"tasks/pushNotification/set","params":{
+			"id":"task-1","pushNotificationConfig":{"url":"https://hook.example.com","token":"[REDACTED]",
+			"authentication":{"schemes":["bearer"],"credentials":"[REDACTED]"}},
+			"metadata":{"items":[{"API_KEY":"[REDACTED]"},{"count":12345678901234567890}]}}}`, redacted)
+		assert.NotContains(t, redacted, "s3cret")
+		assert.NotContains(t, redacted, "hunter2")
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		assert.Equal(t, "[9 bytes of invalid JSON]", JSON([]byte(`{"token":`)))
+	})
+
+	t.Run("Value", func(t *testing.T) {
+		assert.JSONEq(t, `{"password":"[REDACTED]","user":"alice"}`,
+			Value(map[string]string{"user": "alice", "password": "pw"}))
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
@@ -290,3 +291,40 @@ func TestFormatJSONRPCEvent(t *testing.T) {
 	assert.Equal(t, "value1", resultMap["key1"], "Value for key1 should match")
 	assert.Equal(t, "value2", resultMap["key2"], "Value for key2 should match")
 }
+
+// FuzzReadEvent checks that arbitrary streams never panic the reader, and that
+// events written by FormatEvent read back unchanged.
+func FuzzReadEvent(f *testing.F) {
+	f.Add([]byte("event: message\ndata: {\"a\":1}\n\n"), "task_status_update", "text")
+	f.Add([]byte("data: x\ndata:\n\n:comment\nid: 1\nretry: 5\n\nno prefix\n"), "", "")
+	f.Add([]byte("data: unterminated"), "close", "line\nbreak")
+	f.Fuzz(func(t *testing.T, stream []byte, eventType string, text string) {
+		reader := NewEventReader(bytes.NewReader(stream))
+		for i := 0; i <= len(stream); i++ {
+			if _, _, err := reader.ReadEvent(); err != nil {
+				break
+			}
+		}
+
+		if eventType == "" || strings.ContainsAny(eventType, "\r\n") || strings.TrimSpace(eventType) != eventType {
+			return
+		}
+		if !utf8.ValidString(text) {
+			return // JSON replaces invalid UTF-8, so it cannot round-trip.
+		}
+		var buf bytes.Buffer
+		if err := FormatEvent(&buf, eventType, text); err != nil {
+			return
+		}
+		data, gotType, err := NewEventReader(&buf).ReadEvent()
+		if err != nil {
+			t.Fatalf("failed to read formatted event: %v", err)
+		}
+		var gotText string
+		if err := json.Unmarshal(data, &gotText); err != nil {
+			t.Fatalf("formatted event data is not JSON: %v: %q", err, data)
+		}
+		assert.Equal(t, eventType, gotType)
+		assert.Equal(t, text, gotText)
+	})
+}
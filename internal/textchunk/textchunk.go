@@ -0,0 +1,93 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+// Package textchunk keeps streamed text chunks aligned on UTF-8 rune boundaries.
+package textchunk
+
+import (
+	"unicode/utf8"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// SplitIncompleteRune splits s into a prefix ending on a rune boundary and the
+// trailing bytes of a rune that is not yet complete.
+func SplitIncompleteRune(s string) (complete, pending string) {
+	// A UTF-8 rune is at most utf8.UTFMax bytes, so only the tail needs checking.
+	for i := len(s) - 1; i >= 0 && i >= len(s)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(s[i]) {
+			continue
+		}
+		if !utf8.FullRuneInString(s[i:]) {
+			return s[:i], s[i:]
+		}
+		break
+	}
+	return s, ""
+}
+
+// Carry moves the bytes of a rune split across streamed artifact chunks into the
+// next chunk of the same artifact, so that every chunk is valid UTF-8 and survives
+// JSON encoding, which would otherwise replace the split bytes with U+FFFD.
+// It is not safe for concurrent use; use one Carry per stream.
+type Carry struct {
+	pending map[int]string // Artifact index -> incomplete trailing rune.
+}
+
+// NewCarry creates an empty Carry.
+func NewCarry() *Carry {
+	return &Carry{pending: make(map[int]string)}
+}
+
+// Apply returns the event with any bytes held back from the previous chunk prepended
+// to its first text part, and the incomplete rune ending its last text part held
+// back for the next chunk. The last chunk of an artifact is never held back.
+// The event's parts are copied, never modified in place.
+func (c *Carry) Apply(event protocol.TaskArtifactUpdateEvent) protocol.TaskArtifactUpdateEvent {
+	artifact := event.Artifact
+	if artifact.Append == nil || !*artifact.Append {
+		delete(c.pending, artifact.Index) // A new artifact starts over.
+	}
+	carry := c.pending[artifact.Index]
+	parts := make([]protocol.Part, len(artifact.Parts))
+	copy(parts, artifact.Parts)
+	lastText := -1
+	for i, part := range parts {
+		textPart, ok := part.(protocol.TextPart)
+		if !ok {
+			continue
+		}
+		if carry != "" {
+			textPart.Text = carry + textPart.Text
+			carry = ""
+			parts[i] = textPart
+		}
+		lastText = i
+	}
+	lastChunk := artifact.LastChunk != nil && *artifact.LastChunk
+	if lastChunk {
+		if carry != "" {
+			parts = append(parts, protocol.NewTextPart(carry))
+		}
+		delete(c.pending, artifact.Index)
+	} else {
+		if lastText >= 0 {
+			textPart := parts[lastText].(protocol.TextPart)
+			var tail string
+			textPart.Text, tail = SplitIncompleteRune(textPart.Text)
+			parts[lastText] = textPart
+			carry += tail
+		}
+		if carry != "" {
+			c.pending[artifact.Index] = carry
+		} else {
+			delete(c.pending, artifact.Index)
+		}
+	}
+	artifact.Parts = parts
+	event.Artifact = artifact
+	return event
+}
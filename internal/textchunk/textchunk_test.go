@@ -0,0 +1,108 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package textchunk
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// chunk builds an artifact event carrying the given raw text bytes.
+func chunk(index int, text string, appendChunk, lastChunk bool) protocol.TaskArtifactUpdateEvent {
+	return protocol.TaskArtifactUpdateEvent{
+		Artifact: protocol.Artifact{
+			Index:     index,
+			Parts:     []protocol.Part{protocol.NewTextPart(text)},
+			Append:    &appendChunk,
+			LastChunk: &lastChunk,
+		},
+	}
+}
+
+// text returns the text of the event's only part.
+func text(t *testing.T, event protocol.TaskArtifactUpdateEvent) string {
+	t.Helper()
+	require.Len(t, event.Artifact.Parts, 1)
+	return event.Artifact.Parts[0].(protocol.TextPart).Text
+}
+
+func TestSplitIncompleteRune(t *testing.T) {
+	emoji := "😀"
+	for _, tc := range []struct {
+		in, complete, pending string
+	}{
+		{"", "", ""},
+		{"abc", "abc", ""},
+		{"hi " + emoji, "hi " + emoji, ""},
+		{"hi " + emoji[:1], "hi ", emoji[:1]},
+		{"hi " + emoji[:3], "hi ", emoji[:3]},
+		{emoji[1:], emoji[1:], ""}, // Stray continuation bytes are not held back.
+	} {
+		complete, pending := SplitIncompleteRune(tc.in)
+		assert.Equal(t, tc.complete, complete, "input %q", tc.in)
+		assert.Equal(t, tc.pending, pending, "input %q", tc.in)
+	}
+}
+
+func TestCarry(t *testing.T) {
+	emoji := "😀" // 4 bytes: F0 9F 98 80.
+	full := "hi " + emoji + "!"
+
+	t.Run("EmojiSplitAcrossChunks", func(t *testing.T) {
+		carry := NewCarry()
+		original := chunk(0, full[:5], false, false)
+		first := carry.Apply(original)
+		assert.Equal(t, "hi ", text(t, first))
+		assert.Equal(t, full[:5], text(t, original), "the original event must not be modified")
+
+		second := carry.Apply(chunk(0, full[5:], true, true))
+		assert.Equal(t, emoji+"!", text(t, second))
+		assert.True(t, utf8.ValidString(text(t, second)))
+	})
+
+	t.Run("EmojiSplitByteByByte", func(t *testing.T) {
+		carry := NewCarry()
+		var got string
+		for i := 0; i < len(emoji); i++ {
+			event := carry.Apply(chunk(0, emoji[i:i+1], i > 0, i == len(emoji)-1))
+			assert.True(t, utf8.ValidString(text(t, event)))
+			got += text(t, event)
+		}
+		assert.Equal(t, emoji, got)
+	})
+
+	t.Run("LastChunkFlushesWithoutText", func(t *testing.T) {
+		carry := NewCarry()
+		carry.Apply(chunk(0, "ok"+emoji[:2], false, false))
+		yes := true
+		event := carry.Apply(protocol.TaskArtifactUpdateEvent{Artifact: protocol.Artifact{
+			Append:    &yes,
+			LastChunk: &yes,
+		}})
+		require.Len(t, event.Artifact.Parts, 1)
+		assert.Equal(t, emoji[:2], event.Artifact.Parts[0].(protocol.TextPart).Text)
+	})
+
+	t.Run("ArtifactsCarriedSeparately", func(t *testing.T) {
+		carry := NewCarry()
+		assert.Equal(t, "a", text(t, carry.Apply(chunk(0, "a"+emoji[:2], false, false))))
+		assert.Equal(t, "b", text(t, carry.Apply(chunk(1, "b"+emoji[:1], false, false))))
+		assert.Equal(t, emoji, text(t, carry.Apply(chunk(0, emoji[2:], true, true))))
+		assert.Equal(t, emoji, text(t, carry.Apply(chunk(1, emoji[1:], true, true))))
+	})
+
+	t.Run("NonAppendDropsPending", func(t *testing.T) {
+		carry := NewCarry()
+		carry.Apply(chunk(0, "draft"+emoji[:2], false, false))
+		assert.Equal(t, "final", text(t, carry.Apply(chunk(0, "final", false, true))))
+	})
+}
@@ -0,0 +1,122 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+)
+
+// AgentCardJWSContentType is the media type of a JWS-signed agent card.
+// Clients request it through the Accept header; other clients get the plain JSON card.
+const AgentCardJWSContentType = "application/jose"
+
+// ExtensionProcessingTime is the agent card extension, a ProcessingTimeHint,
+// advertising how long the agent takes to process a task.
+const ExtensionProcessingTime = "https://trpc.group/trpc-go/trpc-a2a-go/ext/processing-time"
+
+// ProcessingTimeHint tells clients how long the agent typically and at most takes
+// to process a task, so they can choose a call timeout.
+type ProcessingTimeHint struct {
+	// TypicalMs is the usual processing time in milliseconds.
+	TypicalMs int64 `json:"typicalMs,omitempty"`
+	// MaxMs is the longest processing time in milliseconds.
+	MaxMs int64 `json:"maxMs,omitempty"`
+}
+
+// Timeout returns the call timeout the hint suggests: the maximum processing time
+// if set, otherwise twice the typical one. It returns zero if the hint sets neither.
+func (h ProcessingTimeHint) Timeout() time.Duration {
+	if h.MaxMs > 0 {
+		return time.Duration(h.MaxMs) * time.Millisecond
+	}
+	if h.TypicalMs > 0 {
+		return 2 * time.Duration(h.TypicalMs) * time.Millisecond
+	}
+	return 0
+}
+
+// Agent card signature errors.
+var (
+	// ErrAgentCardSignature is returned when a signed agent card fails verification.
+	ErrAgentCardSignature = errors.New("agent card signature verification failed")
+	// ErrAgentCardUnsigned is returned when a signed agent card was expected but a plain one was received.
+	ErrAgentCardUnsigned = errors.New("agent card is not signed")
+)
+
+// SignAgentCard serializes card to JSON and signs it as a compact JWS.
+// key is an *rsa.PrivateKey (RS256), *ecdsa.PrivateKey (ES256/ES384/ES512 by curve)
+// or ed25519.PrivateKey (EdDSA).
+func SignAgentCard(card interface{}, key interface{}) ([]byte, error) {
+	alg, err := signatureAlgorithm(key)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(card)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal agent card: %w", err)
+	}
+	signed, err := jws.Sign(payload, jws.WithKey(alg, key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign agent card: %w", err)
+	}
+	return signed, nil
+}
+
+// VerifyAgentCard verifies a compact JWS produced by SignAgentCard against the
+// signer's public key and unmarshals the card into card, as DecodeAgentCard does.
+// Errors caused by a bad signature wrap ErrAgentCardSignature.
+func VerifyAgentCard(signed []byte, key interface{}, card interface{}) error {
+	alg, err := signatureAlgorithm(key)
+	if err != nil {
+		return err
+	}
+	payload, err := jws.Verify(signed, jws.WithKey(alg, key))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrAgentCardSignature, err)
+	}
+	return DecodeAgentCard(payload, card)
+}
+
+// signatureAlgorithm picks the JWS algorithm for a private or public key.
+func signatureAlgorithm(key interface{}) (jwa.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return jwa.RS256, nil
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return jwa.EdDSA, nil
+	case *ecdsa.PrivateKey:
+		return ecdsaAlgorithm(k.Curve)
+	case *ecdsa.PublicKey:
+		return ecdsaAlgorithm(k.Curve)
+	default:
+		return "", fmt.Errorf("unsupported signing key type %T", key)
+	}
+}
+
+// ecdsaAlgorithm returns the JWS algorithm matching an ECDSA curve.
+func ecdsaAlgorithm(curve elliptic.Curve) (jwa.SignatureAlgorithm, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwa.ES256, nil
+	case elliptic.P384():
+		return jwa.ES384, nil
+	case elliptic.P521():
+		return jwa.ES512, nil
+	default:
+		return "", fmt.Errorf("unsupported ECDSA curve %s", curve.Params().Name)
+	}
+}
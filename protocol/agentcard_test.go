@@ -0,0 +1,106 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCard struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Version string `json:"version"`
+}
+
+func TestSignAgentCard(t *testing.T) {
+	card := testCard{Name: "Signed Agent", URL: "https://agent.example.com/", Version: "1.0.0"}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keys := []struct {
+		name string
+		priv interface{}
+		pub  interface{}
+	}{
+		{"RSA", rsaKey, &rsaKey.PublicKey},
+		{"ECDSA", ecKey, &ecKey.PublicKey},
+		{"Ed25519", edKey, edPub},
+	}
+	for _, k := range keys {
+		t.Run(k.name, func(t *testing.T) {
+			signed, err := SignAgentCard(card, k.priv)
+			require.NoError(t, err)
+
+			var verified testCard
+			require.NoError(t, VerifyAgentCard(signed, k.pub, &verified))
+			assert.Equal(t, card, verified)
+		})
+	}
+
+	t.Run("Tampered", func(t *testing.T) {
+		signed, err := SignAgentCard(card, ecKey)
+		require.NoError(t, err)
+		parts := bytes.Split(signed, []byte("."))
+		require.Len(t, parts, 3)
+		tamperedCard := card
+		tamperedCard.URL = "https://evil.example.com/"
+		payload, err := json.Marshal(tamperedCard)
+		require.NoError(t, err)
+		parts[1] = []byte(base64.RawURLEncoding.EncodeToString(payload))
+
+		var verified testCard
+		err = VerifyAgentCard(bytes.Join(parts, []byte(".")), &ecKey.PublicKey, &verified)
+		assert.ErrorIs(t, err, ErrAgentCardSignature)
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		signed, err := SignAgentCard(card, rsaKey)
+		require.NoError(t, err)
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		var verified testCard
+		assert.ErrorIs(t, VerifyAgentCard(signed, &otherKey.PublicKey, &verified), ErrAgentCardSignature)
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		plain, err := json.Marshal(card)
+		require.NoError(t, err)
+
+		var verified testCard
+		assert.ErrorIs(t, VerifyAgentCard(plain, &ecKey.PublicKey, &verified), ErrAgentCardSignature)
+	})
+
+	t.Run("UnsupportedKey", func(t *testing.T) {
+		_, err := SignAgentCard(card, []byte("secret"))
+		assert.Error(t, err)
+	})
+}
+
+func TestProcessingTimeHint_Timeout(t *testing.T) {
+	assert.Equal(t, 2*time.Minute, ProcessingTimeHint{TypicalMs: 1000, MaxMs: 120000}.Timeout())
+	assert.Equal(t, 3*time.Second, ProcessingTimeHint{TypicalMs: 1500}.Timeout())
+	assert.Zero(t, ProcessingTimeHint{}.Timeout())
+	assert.Zero(t, ProcessingTimeHint{TypicalMs: -1}.Timeout())
+}
@@ -0,0 +1,90 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrArtifactNotFound is returned when deleting an artifact index a task does not have.
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// ReplaceArtifacts returns artifacts with every stored chunk of artifact.Index
+// replaced by artifact, which is added at the end. An index not yet present is
+// simply added. Append is cleared so clients start the artifact over. It leaves
+// artifacts unchanged.
+func ReplaceArtifacts(artifacts []Artifact, artifact Artifact) []Artifact {
+	replaced := make([]Artifact, 0, len(artifacts)+1)
+	for _, stored := range artifacts {
+		if stored.Index != artifact.Index {
+			replaced = append(replaced, stored)
+		}
+	}
+	artifact.Append = nil
+	return append(replaced, artifact)
+}
+
+// DeleteArtifacts returns artifacts without the stored chunks of index, or an
+// ErrArtifactNotFound error if there are none. It leaves artifacts unchanged.
+func DeleteArtifacts(artifacts []Artifact, index int) ([]Artifact, error) {
+	kept := make([]Artifact, 0, len(artifacts))
+	for _, stored := range artifacts {
+		if stored.Index != index {
+			kept = append(kept, stored)
+		}
+	}
+	if len(kept) == len(artifacts) {
+		return nil, fmt.Errorf("%w: no artifact with index %d", ErrArtifactNotFound, index)
+	}
+	return kept, nil
+}
+
+// FindArtifact returns the artifact with the given index assembled from its stored
+// chunks: the parts of the last chunk without Append set followed by those of the
+// chunks appended to it. It reports whether artifacts hold the index.
+func FindArtifact(artifacts []Artifact, index int) (Artifact, bool) {
+	var found Artifact
+	var ok bool
+	for _, stored := range artifacts {
+		if stored.Index != index {
+			continue
+		}
+		if !ok || stored.Append == nil || !*stored.Append {
+			found = stored
+			found.Parts = append([]Part(nil), stored.Parts...)
+			found.Append = nil
+			ok = true
+			continue
+		}
+		found.Parts = append(found.Parts, stored.Parts...)
+		found.LastChunk = stored.LastChunk
+	}
+	return found, ok
+}
+
+// AssembleArtifacts returns the artifacts stored as chunks in artifacts, each
+// assembled as by FindArtifact, ordered by index. The parts of each artifact are in
+// the order they were streamed. It leaves artifacts unchanged.
+func AssembleArtifacts(artifacts []Artifact) []Artifact {
+	var indexes []int
+	seen := make(map[int]bool)
+	for _, stored := range artifacts {
+		if !seen[stored.Index] {
+			seen[stored.Index] = true
+			indexes = append(indexes, stored.Index)
+		}
+	}
+	sort.Ints(indexes)
+	assembled := make([]Artifact, 0, len(indexes))
+	for _, index := range indexes {
+		artifact, _ := FindArtifact(artifacts, index)
+		assembled = append(assembled, artifact)
+	}
+	return assembled
+}
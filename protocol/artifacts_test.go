@@ -0,0 +1,90 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceArtifacts(t *testing.T) {
+	appendChunk := true
+	artifacts := []Artifact{
+		{Index: 0, Parts: []Part{NewTextPart("draft ")}},
+		{Index: 1, Parts: []Part{NewTextPart("other")}},
+		{Index: 0, Parts: []Part{NewTextPart("chunk")}, Append: &appendChunk},
+	}
+
+	replaced := ReplaceArtifacts(artifacts, Artifact{
+		Index: 0, Parts: []Part{NewTextPart("final")}, Append: &appendChunk,
+	})
+	require.Len(t, replaced, 2)
+	assert.Equal(t, 1, replaced[0].Index)
+	assert.Equal(t, "final", replaced[1].Parts[0].(TextPart).Text)
+	assert.Nil(t, replaced[1].Append, "a replacement starts the artifact over")
+	assert.Len(t, artifacts, 3, "input is left unchanged")
+
+	added := ReplaceArtifacts(nil, Artifact{Index: 2})
+	require.Len(t, added, 1)
+	assert.Equal(t, 2, added[0].Index)
+}
+
+func TestDeleteArtifacts(t *testing.T) {
+	artifacts := []Artifact{{Index: 0}, {Index: 1}, {Index: 0}}
+
+	kept, err := DeleteArtifacts(artifacts, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []Artifact{{Index: 1}}, kept)
+	assert.Len(t, artifacts, 3, "input is left unchanged")
+
+	_, err = DeleteArtifacts(artifacts, 5)
+	assert.ErrorIs(t, err, ErrArtifactNotFound)
+}
+
+func TestFindArtifact(t *testing.T) {
+	appendChunk, last := true, true
+	artifacts := []Artifact{
+		{Index: 0, Parts: []Part{NewTextPart("old")}},
+		{Index: 1, Parts: []Part{NewTextPart("other")}},
+		{Index: 0, Parts: []Part{NewTextPart("draft ")}},
+		{Index: 0, Parts: []Part{NewTextPart("v1")}, Append: &appendChunk, LastChunk: &last},
+	}
+
+	artifact, ok := FindArtifact(artifacts, 0)
+	require.True(t, ok)
+	assert.Equal(t, []Part{NewTextPart("draft "), NewTextPart("v1")}, artifact.Parts,
+		"chunks after the last restart are joined")
+	assert.Nil(t, artifact.Append)
+	assert.Equal(t, &last, artifact.LastChunk)
+	assert.Equal(t, []Part{NewTextPart("draft ")}, artifacts[2].Parts, "input is left unchanged")
+
+	_, ok = FindArtifact(artifacts, 5)
+	assert.False(t, ok)
+}
+
+func TestAssembleArtifacts(t *testing.T) {
+	appendChunk := true
+	artifacts := []Artifact{
+		{Index: 1, Parts: []Part{NewTextPart("caption: ")}},
+		{Index: 0, Parts: []Part{NewTextPart("intro "), NewArtifactReference("task-1", 3)}},
+		{Index: 1, Parts: []Part{NewTextPart("a chart")}, Append: &appendChunk},
+		{Index: 0, Parts: []Part{NewTextPart(" outro")}, Append: &appendChunk},
+	}
+
+	assembled := AssembleArtifacts(artifacts)
+	require.Len(t, assembled, 2)
+	assert.Equal(t, 0, assembled[0].Index)
+	assert.Equal(t, []Part{NewTextPart("intro "), NewArtifactReference("task-1", 3), NewTextPart(" outro")},
+		assembled[0].Parts, "parts keep the order they were streamed in")
+	assert.Equal(t, 1, assembled[1].Index)
+	assert.Equal(t, []Part{NewTextPart("caption: "), NewTextPart("a chart")}, assembled[1].Parts)
+	assert.Len(t, artifacts[1].Parts, 2, "input is left unchanged")
+
+	assert.Empty(t, AssembleArtifacts(nil))
+}
@@ -0,0 +1,153 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// HeaderBaggage is the W3C Baggage header carrying the baggage of a call from the
+// client to the server, see WithBaggage.
+const HeaderBaggage = "baggage"
+
+// Limits of the W3C Baggage specification. Members that do not fit are dropped.
+const (
+	MaxBaggageMembers = 64   // Most list members in a baggage header.
+	MaxBaggageBytes   = 8192 // Largest baggage header, in bytes.
+)
+
+// Baggage is the key-value baggage propagated with a call, such as the tenant or
+// the experiment of a distributed trace. Member properties are not kept.
+type Baggage map[string]string
+
+// baggageKey is the context key for the baggage of a call.
+type baggageKey struct{}
+
+// WithBaggage returns a copy of ctx carrying baggage, merged into the baggage ctx
+// already carries. The client sends it with the calls made with the context and
+// the server sets the baggage it received on the context of the task processor.
+func WithBaggage(ctx context.Context, baggage Baggage) context.Context {
+	merged := make(Baggage, len(baggage))
+	for key, value := range BaggageFromContext(ctx) {
+		merged[key] = value
+	}
+	for key, value := range baggage {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, baggageKey{}, merged)
+}
+
+// BaggageFromContext returns the baggage stored in ctx, or nil if none is set. The
+// returned map must not be modified.
+func BaggageFromContext(ctx context.Context) Baggage {
+	baggage, _ := ctx.Value(baggageKey{}).(Baggage)
+	return baggage
+}
+
+// ParseBaggage parses the value of a baggage header, or the values of several
+// joined with commas. Malformed members and members beyond the limits of the
+// specification are skipped.
+func ParseBaggage(header string) Baggage {
+	if len(header) > MaxBaggageBytes {
+		header = header[:MaxBaggageBytes]
+		if i := strings.LastIndexByte(header, ','); i >= 0 {
+			header = header[:i] // Drop the member cut off.
+		} else {
+			return nil
+		}
+	}
+	var baggage Baggage
+	members := 0
+	for _, member := range strings.Split(header, ",") {
+		member, _, _ = strings.Cut(member, ";") // Drop the properties.
+		key, value, ok := strings.Cut(member, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isBaggageKey(key) {
+			continue
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		if members++; members > MaxBaggageMembers {
+			break
+		}
+		if baggage == nil {
+			baggage = make(Baggage)
+		}
+		baggage[key] = decoded
+	}
+	return baggage
+}
+
+// String encodes b as the value of a baggage header, with its members sorted by
+// key. Members with invalid keys, and those that would exceed the limits of the
+// specification, are left out.
+func (b Baggage) String() string {
+	keys := make([]string, 0, len(b))
+	for key := range b {
+		if isBaggageKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var header strings.Builder
+	members := 0
+	for _, key := range keys {
+		member := key + "=" + escapeBaggageValue(b[key])
+		size := len(member)
+		if members > 0 {
+			size++ // The comma.
+		}
+		if members >= MaxBaggageMembers || header.Len()+size > MaxBaggageBytes {
+			continue
+		}
+		if members > 0 {
+			header.WriteByte(',')
+		}
+		header.WriteString(member)
+		members++
+	}
+	return header.String()
+}
+
+// isBaggageKey reports whether key is an RFC 7230 token, as baggage keys must be.
+func isBaggageKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// escapeBaggageValue percent-encodes the bytes of value that are not allowed
+// unencoded in a baggage value.
+func escapeBaggageValue(value string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c > 0x20 && c < 0x7f && c != '"' && c != ',' && c != ';' && c != '\\' && c != '%' {
+			escaped.WriteByte(c)
+			continue
+		}
+		escaped.WriteByte('%')
+		escaped.WriteByte("0123456789ABCDEF"[c>>4])
+		escaped.WriteByte("0123456789ABCDEF"[c&0xf])
+	}
+	return escaped.String()
+}
@@ -0,0 +1,56 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaggage(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		baggage := Baggage{"tenant": "acme", "note": "a, b; c=100%", "userId": "alice"}
+		header := baggage.String()
+		assert.Equal(t, "note=a%2C%20b%3B%20c=100%25,tenant=acme,userId=alice", header)
+		assert.Equal(t, baggage, ParseBaggage(header))
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		assert.Equal(t, Baggage{"key1": "value1", "key2": "value 2", "key3": "x"},
+			ParseBaggage(" key1 = value1 ;prop1;prop2=v, key2=value%202, bad key=1, novalue, key3=x"))
+		assert.Nil(t, ParseBaggage(""))
+		assert.Nil(t, ParseBaggage("key=%zz"))
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		many := make(Baggage)
+		for i := 0; i < MaxBaggageMembers+10; i++ {
+			many[fmt.Sprintf("k%03d", i)] = "v"
+		}
+		assert.Len(t, ParseBaggage(many.String()), MaxBaggageMembers)
+
+		large := Baggage{"a": strings.Repeat("x", MaxBaggageBytes-10), "b": strings.Repeat("y", 20), "c": "z"}
+		header := large.String()
+		assert.LessOrEqual(t, len(header), MaxBaggageBytes)
+		assert.Equal(t, Baggage{"a": large["a"], "c": "z"}, ParseBaggage(header))
+
+		oversized := "a=1,b=" + strings.Repeat("y", MaxBaggageBytes)
+		assert.Equal(t, Baggage{"a": "1"}, ParseBaggage(oversized))
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		ctx := context.Background()
+		assert.Nil(t, BaggageFromContext(ctx))
+		ctx = WithBaggage(ctx, Baggage{"tenant": "acme", "region": "eu"})
+		ctx = WithBaggage(ctx, Baggage{"region": "us"})
+		assert.Equal(t, Baggage{"tenant": "acme", "region": "us"}, BaggageFromContext(ctx))
+	})
+}
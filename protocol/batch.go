@@ -0,0 +1,16 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+// MaxSendBatchSize is the largest number of tasks a tasks/sendBatch request may carry.
+const MaxSendBatchSize = 100
+
+// SendTaskBatchParams are the params of a tasks/sendBatch request.
+type SendTaskBatchParams struct {
+	// Tasks are the tasks to send, each as the params of a tasks/send request.
+	Tasks []SendTaskParams `json:"tasks"`
+}
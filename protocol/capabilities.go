@@ -0,0 +1,50 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+// Capabilities is the result of the a2a/capabilities method: what an agent
+// supports, without the rest of its card.
+type Capabilities struct {
+	// Streaming reports whether the agent streams task events.
+	Streaming bool `json:"streaming"`
+	// PushNotifications reports whether the agent sends push notifications.
+	PushNotifications bool `json:"pushNotifications"`
+	// StateTransitionHistory reports whether the agent keeps task history.
+	StateTransitionHistory bool `json:"stateTransitionHistory"`
+	// Skills lists the IDs of the agent's skills.
+	Skills []string `json:"skills,omitempty"`
+	// Extensions lists the URIs of the card extensions the agent declares.
+	Extensions []string `json:"extensions,omitempty"`
+	// Methods lists the JSON-RPC methods the server handles, including optional
+	// and custom ones.
+	Methods []string `json:"methods"`
+}
+
+// HasSkill reports whether the agent has the skill with the given ID.
+func (c *Capabilities) HasSkill(id string) bool {
+	return contains(c.Skills, id)
+}
+
+// HasExtension reports whether the agent declares the extension with the given URI.
+func (c *Capabilities) HasExtension(uri string) bool {
+	return contains(c.Extensions, uri)
+}
+
+// HasMethod reports whether the server handles the JSON-RPC method.
+func (c *Capabilities) HasMethod(method string) bool {
+	return contains(c.Methods, method)
+}
+
+// contains reports whether values holds value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnsupportedAgentCardVersion is returned when decoding an agent card declaring a
+// spec version DecodeAgentCard does not know.
+var ErrUnsupportedAgentCardVersion = errors.New("unsupported agent card version")
+
+// agentCardDecoders convert an agent card of a spec version, by major and minor
+// version, to the layout of the library's AgentCard. A nil decoder keeps the card
+// as it is.
+var agentCardDecoders = map[string]func(card map[string]json.RawMessage) error{
+	"0.1": nil,
+	"0.2": normalizeAgentCardV02,
+	"0.3": normalizeAgentCardV02,
+}
+
+// AgentCardVersion returns the spec version an agent card declares in its
+// protocolVersion field, or "0.1" for cards predating the field, like those
+// served by this library.
+func AgentCardVersion(data []byte) (string, error) {
+	var card struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(data, &card); err != nil {
+		return "", fmt.Errorf("failed to decode agent card: %w", err)
+	}
+	if card.ProtocolVersion == "" {
+		return "0.1", nil
+	}
+	return card.ProtocolVersion, nil
+}
+
+// NormalizeAgentCard converts an agent card of any supported spec version to the
+// layout of the library's AgentCard. Cards of later versions have the provider's
+// organization, their security schemes and their capability extensions mapped to
+// the provider name, the authentication and the extensions of the card. A card of
+// an unknown version is rejected with an ErrUnsupportedAgentCardVersion error.
+func NormalizeAgentCard(data []byte) ([]byte, error) {
+	version, err := AgentCardVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder, ok := agentCardDecoders[minorVersion(version)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAgentCardVersion, version)
+	}
+	if decoder == nil {
+		return data, nil
+	}
+	var card map[string]json.RawMessage
+	if err := json.Unmarshal(data, &card); err != nil {
+		return nil, fmt.Errorf("failed to decode agent card: %w", err)
+	}
+	if err := decoder(card); err != nil {
+		return nil, fmt.Errorf("failed to decode version %s agent card: %w", version, err)
+	}
+	return json.Marshal(card)
+}
+
+// DecodeAgentCard unmarshals an agent card of any supported spec version into card,
+// typically a *server.AgentCard, after converting it with NormalizeAgentCard.
+func DecodeAgentCard(data []byte, card interface{}) error {
+	normalized, err := NormalizeAgentCard(data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(normalized, card); err != nil {
+		return fmt.Errorf("failed to decode agent card: %w", err)
+	}
+	return nil
+}
+
+// minorVersion returns the major and minor parts of version, e.g. "0.2" for "0.2.5".
+func minorVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// normalizeAgentCardV02 converts a card of spec version 0.2 or 0.3.
+func normalizeAgentCardV02(card map[string]json.RawMessage) error {
+	if err := normalizeCardProvider(card); err != nil {
+		return err
+	}
+	if err := normalizeCardSecurity(card); err != nil {
+		return err
+	}
+	return normalizeCardExtensions(card)
+}
+
+// normalizeCardProvider names the provider after its organization.
+func normalizeCardProvider(card map[string]json.RawMessage) error {
+	raw, ok := card["provider"]
+	if !ok {
+		return nil
+	}
+	var provider map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &provider); err != nil {
+		return fmt.Errorf("invalid provider: %w", err)
+	}
+	organization, ok := provider["organization"]
+	if _, named := provider["name"]; !ok || named {
+		return nil
+	}
+	provider["name"] = organization
+	delete(provider, "organization")
+	return setCardField(card, "provider", provider)
+}
+
+// securityScheme is the part of an OpenAPI security scheme the card conversion uses.
+type securityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// normalizeCardSecurity describes the security schemes of the card as its
+// authentication: the type of the first scheme in security, or of the first one
+// by name if security is empty, required if security lists any. The schemes are
+// kept as the authentication config.
+func normalizeCardSecurity(card map[string]json.RawMessage) error {
+	raw, ok := card["securitySchemes"]
+	if _, set := card["authentication"]; !ok || set {
+		return nil
+	}
+	var schemes map[string]securityScheme
+	if err := json.Unmarshal(raw, &schemes); err != nil {
+		return fmt.Errorf("invalid securitySchemes: %w", err)
+	}
+	if len(schemes) == 0 {
+		return nil
+	}
+	var security []map[string][]string
+	if rawSecurity, ok := card["security"]; ok {
+		if err := json.Unmarshal(rawSecurity, &security); err != nil {
+			return fmt.Errorf("invalid security: %w", err)
+		}
+	}
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	chosen := names[0]
+	if len(security) > 0 {
+		required := make([]string, 0, len(security[0]))
+		for name := range security[0] {
+			if _, ok := schemes[name]; ok {
+				required = append(required, name)
+			}
+		}
+		if len(required) > 0 {
+			sort.Strings(required)
+			chosen = required[0]
+		}
+	}
+	return setCardField(card, "authentication", map[string]interface{}{
+		"type":     authenticationType(schemes[chosen]),
+		"required": len(security) > 0,
+		"config":   raw,
+	})
+}
+
+// authenticationType returns the authentication type of the library's AgentCard
+// that scheme corresponds to.
+func authenticationType(scheme securityScheme) string {
+	switch scheme.Type {
+	case "http":
+		if scheme.Scheme != "" {
+			return strings.ToLower(scheme.Scheme)
+		}
+		return scheme.Type
+	case "oauth2", "openIdConnect":
+		return "oauth"
+	default:
+		return scheme.Type
+	}
+}
+
+// normalizeCardExtensions adds the extensions declared in the capabilities, with
+// their params, to the extensions of the card, keyed by URI.
+func normalizeCardExtensions(card map[string]json.RawMessage) error {
+	raw, ok := card["capabilities"]
+	if !ok {
+		return nil
+	}
+	var capabilities struct {
+		Extensions []struct {
+			URI    string          `json:"uri"`
+			Params json.RawMessage `json:"params"`
+		} `json:"extensions"`
+	}
+	if err := json.Unmarshal(raw, &capabilities); err != nil {
+		return fmt.Errorf("invalid capabilities: %w", err)
+	}
+	if len(capabilities.Extensions) == 0 {
+		return nil
+	}
+	extensions := make(map[string]json.RawMessage)
+	if rawExtensions, ok := card["extensions"]; ok {
+		if err := json.Unmarshal(rawExtensions, &extensions); err != nil {
+			return fmt.Errorf("invalid extensions: %w", err)
+		}
+	}
+	for _, extension := range capabilities.Extensions {
+		if _, ok := extensions[extension.URI]; ok || extension.URI == "" {
+			continue
+		}
+		params := extension.Params
+		if len(params) == 0 {
+			params = json.RawMessage("{}")
+		}
+		extensions[extension.URI] = params
+	}
+	return setCardField(card, "extensions", extensions)
+}
+
+// setCardField sets the field name of card to value encoded as JSON.
+func setCardField(card map[string]json.RawMessage, name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	card[name] = data
+	return nil
+}
@@ -0,0 +1,132 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedCard mirrors the parts of server.AgentCard the version tests check.
+type versionedCard struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Provider *struct {
+		Name string `json:"name"`
+	} `json:"provider"`
+	Capabilities struct {
+		Streaming bool `json:"streaming"`
+	} `json:"capabilities"`
+	Authentication *struct {
+		Type     string          `json:"type"`
+		Required bool            `json:"required"`
+		Config   json.RawMessage `json:"config"`
+	} `json:"authentication"`
+	Skills []struct {
+		ID string `json:"id"`
+	} `json:"skills"`
+	Extensions map[string]json.RawMessage `json:"extensions"`
+}
+
+func TestDecodeAgentCard(t *testing.T) {
+	legacy := `{
+		"name": "Weather Agent",
+		"url": "https://agent.example.com/",
+		"version": "1.0.0",
+		"provider": {"name": "Example Inc"},
+		"capabilities": {"streaming": true},
+		"authentication": {"type": "bearer", "required": true},
+		"skills": [{"id": "forecast", "name": "Forecast"}],
+		"extensions": {"https://example.com/ext/region": {"region": "eu"}}
+	}`
+	current := `{
+		"protocolVersion": "0.2.5",
+		"name": "Weather Agent",
+		"url": "https://agent.example.com/",
+		"version": "1.0.0",
+		"provider": {"organization": "Example Inc", "url": "https://example.com"},
+		"capabilities": {
+			"streaming": true,
+			"extensions": [{"uri": "https://example.com/ext/region", "params": {"region": "eu"}}]
+		},
+		"securitySchemes": {"token": {"type": "http", "scheme": "Bearer"}},
+		"security": [{"token": []}],
+		"skills": [{"id": "forecast", "name": "Forecast", "tags": ["weather"]}]
+	}`
+
+	var fromLegacy, fromCurrent versionedCard
+	require.NoError(t, DecodeAgentCard([]byte(legacy), &fromLegacy))
+	require.NoError(t, DecodeAgentCard([]byte(current), &fromCurrent))
+
+	for name, card := range map[string]versionedCard{"0.1": fromLegacy, "0.2": fromCurrent} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, "Weather Agent", card.Name)
+			require.NotNil(t, card.Provider)
+			assert.Equal(t, "Example Inc", card.Provider.Name)
+			assert.True(t, card.Capabilities.Streaming)
+			require.NotNil(t, card.Authentication)
+			assert.Equal(t, "bearer", card.Authentication.Type)
+			assert.True(t, card.Authentication.Required)
+			require.Len(t, card.Skills, 1)
+			assert.Equal(t, "forecast", card.Skills[0].ID)
+			assert.JSONEq(t, `{"region":"eu"}`, string(card.Extensions["https://example.com/ext/region"]))
+		})
+	}
+	assert.JSONEq(t, `{"token":{"type":"http","scheme":"Bearer"}}`, string(fromCurrent.Authentication.Config),
+		"the security schemes are kept as the authentication config")
+}
+
+func TestDecodeAgentCard_SecuritySchemes(t *testing.T) {
+	decode := func(t *testing.T, card string) versionedCard {
+		var decoded versionedCard
+		require.NoError(t, DecodeAgentCard([]byte(card), &decoded))
+		return decoded
+	}
+
+	t.Run("OAuth2", func(t *testing.T) {
+		card := decode(t, `{"protocolVersion":"0.3.0","securitySchemes":{
+			"key":{"type":"apiKey","in":"header","name":"X-API-Key"},
+			"oauth":{"type":"oauth2","flows":{}}},"security":[{"oauth":["read"]}]}`)
+		require.NotNil(t, card.Authentication)
+		assert.Equal(t, "oauth", card.Authentication.Type)
+		assert.True(t, card.Authentication.Required)
+	})
+
+	t.Run("Optional", func(t *testing.T) {
+		card := decode(t, `{"protocolVersion":"0.2.0","securitySchemes":{
+			"key":{"type":"apiKey","in":"header","name":"X-API-Key"}}}`)
+		require.NotNil(t, card.Authentication)
+		assert.Equal(t, "apiKey", card.Authentication.Type)
+		assert.False(t, card.Authentication.Required)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		card := decode(t, `{"protocolVersion":"0.2.0","name":"open"}`)
+		assert.Nil(t, card.Authentication)
+	})
+}
+
+func TestDecodeAgentCard_Versions(t *testing.T) {
+	version, err := AgentCardVersion([]byte(`{"name":"legacy"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "0.1", version)
+	version, err = AgentCardVersion([]byte(`{"protocolVersion":"0.3.0"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "0.3.0", version)
+
+	var card versionedCard
+	err = DecodeAgentCard([]byte(`{"protocolVersion":"1.0.0","name":"future"}`), &card)
+	require.ErrorIs(t, err, ErrUnsupportedAgentCardVersion)
+	assert.Contains(t, err.Error(), `"1.0.0"`)
+
+	err = DecodeAgentCard([]byte(`{"name":`), &card)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnsupportedAgentCardVersion)
+}
@@ -0,0 +1,202 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedChatRole is returned when a chat message role has no A2A equivalent.
+var ErrUnsupportedChatRole = errors.New("unsupported chat role")
+
+// ErrUnsupportedChatPart is returned when a part or content type cannot be converted
+// between an A2A Message and a ChatMessage.
+var ErrUnsupportedChatPart = errors.New("unsupported chat part")
+
+// ChatRole is the role of a ChatMessage sender, as used by common chat model APIs.
+type ChatRole string
+
+// ChatRole constants define the roles that map to A2A message roles.
+const (
+	// ChatRoleUser corresponds to MessageRoleUser.
+	ChatRoleUser ChatRole = "user"
+	// ChatRoleAssistant corresponds to MessageRoleAgent.
+	ChatRoleAssistant ChatRole = "assistant"
+)
+
+// ChatContentType indicates the type of a ChatContent.
+type ChatContentType string
+
+// ChatContentType constants define the supported chat content types.
+const (
+	// ChatContentText is plain text, converted to and from a TextPart.
+	ChatContentText ChatContentType = "text"
+	// ChatContentImage is an image, converted to and from a FilePart with an image
+	// MIME type.
+	ChatContentImage ChatContentType = "image"
+	// ChatContentData is structured data, converted to and from a DataPart.
+	ChatContentData ChatContentType = "data"
+)
+
+// ChatContent is a single content part of a ChatMessage.
+type ChatContent struct {
+	// Type is the type of the content.
+	Type ChatContentType `json:"type"`
+	// Text is the text of a ChatContentText.
+	Text string `json:"text,omitempty"`
+	// ImageURL is the location of a ChatContentImage: a URL, or a base64 data URL
+	// for inline images.
+	ImageURL string `json:"imageUrl,omitempty"`
+	// MimeType is the optional MIME type of a ChatContentImage.
+	MimeType string `json:"mimeType,omitempty"`
+	// Data is the payload of a ChatContentData.
+	Data interface{} `json:"data,omitempty"`
+}
+
+// ChatMessage is a generic chat message made of a role and content parts, the
+// shape most LLM SDKs use, for bridging A2A messages to model APIs.
+type ChatMessage struct {
+	// Role is the sender of the message.
+	Role ChatRole `json:"role"`
+	// Content is the content parts of the message.
+	Content []ChatContent `json:"content"`
+}
+
+// ToChatMessage converts message to a ChatMessage. Text and data parts map to text
+// and data content, and file parts with an image MIME type to image content, inline
+// bytes becoming a data URL. Any other part, such as a non-image file, is rejected
+// with an ErrUnsupportedChatPart error. File names and metadata are dropped.
+func ToChatMessage(message Message) (ChatMessage, error) {
+	var role ChatRole
+	switch message.Role {
+	case MessageRoleUser:
+		role = ChatRoleUser
+	case MessageRoleAgent:
+		role = ChatRoleAssistant
+	default:
+		return ChatMessage{}, fmt.Errorf("%w: %q", ErrUnsupportedChatRole, message.Role)
+	}
+	chat := ChatMessage{Role: role, Content: make([]ChatContent, 0, len(message.Parts))}
+	for i, part := range message.Parts {
+		content, err := toChatContent(part)
+		if err != nil {
+			return ChatMessage{}, fmt.Errorf("part %d: %w", i, err)
+		}
+		chat.Content = append(chat.Content, content)
+	}
+	return chat, nil
+}
+
+// FromChatMessage converts a ChatMessage to a Message, reversing ToChatMessage.
+// Roles other than user and assistant, such as system or tool, are rejected with
+// an ErrUnsupportedChatRole error, and unknown content types or images without a
+// URL with an ErrUnsupportedChatPart error.
+func FromChatMessage(chat ChatMessage) (Message, error) {
+	var role MessageRole
+	switch chat.Role {
+	case ChatRoleUser:
+		role = MessageRoleUser
+	case ChatRoleAssistant:
+		role = MessageRoleAgent
+	default:
+		return Message{}, fmt.Errorf("%w: %q", ErrUnsupportedChatRole, chat.Role)
+	}
+	parts := make([]Part, 0, len(chat.Content))
+	for i, content := range chat.Content {
+		part, err := fromChatContent(content)
+		if err != nil {
+			return Message{}, fmt.Errorf("content %d: %w", i, err)
+		}
+		parts = append(parts, part)
+	}
+	return NewMessage(role, parts), nil
+}
+
+// toChatContent converts a single message part to chat content.
+func toChatContent(part Part) (ChatContent, error) {
+	switch p := part.(type) {
+	case TextPart:
+		return ChatContent{Type: ChatContentText, Text: p.Text}, nil
+	case DataPart:
+		return ChatContent{Type: ChatContentData, Data: p.Data}, nil
+	case FilePart:
+		var mimeType string
+		if p.File.MimeType != nil {
+			mimeType = *p.File.MimeType
+		}
+		if !strings.HasPrefix(mimeType, "image/") {
+			return ChatContent{}, fmt.Errorf("%w: file of type %q", ErrUnsupportedChatPart, mimeType)
+		}
+		content := ChatContent{Type: ChatContentImage, MimeType: mimeType}
+		switch {
+		case p.File.Bytes != nil:
+			content.ImageURL = "data:" + mimeType + ";base64," + *p.File.Bytes
+		case p.File.URI != nil:
+			content.ImageURL = *p.File.URI
+		default:
+			return ChatContent{}, fmt.Errorf("%w: image without bytes or uri", ErrUnsupportedChatPart)
+		}
+		return content, nil
+	default:
+		return ChatContent{}, fmt.Errorf("%w: %T", ErrUnsupportedChatPart, part)
+	}
+}
+
+// fromChatContent converts a single chat content to a message part.
+func fromChatContent(content ChatContent) (Part, error) {
+	switch content.Type {
+	case ChatContentText:
+		return NewTextPart(content.Text), nil
+	case ChatContentData:
+		return DataPart{Type: PartTypeData, Data: content.Data}, nil
+	case ChatContentImage:
+		if content.ImageURL == "" {
+			return nil, fmt.Errorf("%w: image without url", ErrUnsupportedChatPart)
+		}
+		file := FileContent{}
+		if content.MimeType != "" {
+			mimeType := content.MimeType
+			file.MimeType = &mimeType
+		}
+		if mimeType, data, ok := parseBase64DataURL(content.ImageURL); ok {
+			if mimeType != "" {
+				file.MimeType = &mimeType
+			}
+			file.Bytes = &data
+		} else {
+			uri := content.ImageURL
+			file.URI = &uri
+		}
+		return FilePart{Type: PartTypeFile, File: file}, nil
+	default:
+		return nil, fmt.Errorf("%w: content type %q", ErrUnsupportedChatPart, content.Type)
+	}
+}
+
+// parseBase64DataURL splits a "data:<mime>;base64,<data>" URL into its MIME type
+// and base64 payload. It reports false for other URLs or an invalid payload.
+func parseBase64DataURL(url string) (mimeType, data string, ok bool) {
+	rest, found := strings.CutPrefix(url, "data:")
+	if !found {
+		return "", "", false
+	}
+	header, data, found := strings.Cut(rest, ",")
+	if !found {
+		return "", "", false
+	}
+	mimeType, found = strings.CutSuffix(header, ";base64")
+	if !found {
+		return "", "", false
+	}
+	if _, err := base64.StdEncoding.DecodeString(data); err != nil {
+		return "", "", false
+	}
+	return mimeType, data, true
+}
@@ -0,0 +1,116 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatMessage_RoundTrip(t *testing.T) {
+	stringPtr := func(s string) *string { return &s }
+	tests := []struct {
+		name    string
+		message Message
+		want    ChatMessage
+	}{
+		{
+			name:    "Text",
+			message: NewMessage(MessageRoleUser, []Part{NewTextPart("hello")}),
+			want: ChatMessage{Role: ChatRoleUser, Content: []ChatContent{
+				{Type: ChatContentText, Text: "hello"},
+			}},
+		},
+		{
+			name: "InlineImage",
+			message: NewMessage(MessageRoleAgent, []Part{FilePart{Type: PartTypeFile, File: FileContent{
+				MimeType: stringPtr("image/png"),
+				Bytes:    stringPtr("iVBORw0KGgo="),
+			}}}),
+			want: ChatMessage{Role: ChatRoleAssistant, Content: []ChatContent{
+				{Type: ChatContentImage, ImageURL: "data:image/png;base64,iVBORw0KGgo=", MimeType: "image/png"},
+			}},
+		},
+		{
+			name: "ImageURI",
+			message: NewMessage(MessageRoleUser, []Part{FilePart{Type: PartTypeFile, File: FileContent{
+				MimeType: stringPtr("image/jpeg"),
+				URI:      stringPtr("https://example.com/cat.jpg"),
+			}}}),
+			want: ChatMessage{Role: ChatRoleUser, Content: []ChatContent{
+				{Type: ChatContentImage, ImageURL: "https://example.com/cat.jpg", MimeType: "image/jpeg"},
+			}},
+		},
+		{
+			name: "Data",
+			message: NewMessage(MessageRoleAgent, []Part{DataPart{
+				Type: PartTypeData,
+				Data: map[string]interface{}{"temperature": 21.5},
+			}}),
+			want: ChatMessage{Role: ChatRoleAssistant, Content: []ChatContent{
+				{Type: ChatContentData, Data: map[string]interface{}{"temperature": 21.5}},
+			}},
+		},
+		{
+			name:    "Mixed",
+			message: NewMessage(MessageRoleUser, []Part{NewTextPart("look"), DataPart{Type: PartTypeData, Data: "x"}}),
+			want: ChatMessage{Role: ChatRoleUser, Content: []ChatContent{
+				{Type: ChatContentText, Text: "look"},
+				{Type: ChatContentData, Data: "x"},
+			}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chat, err := ToChatMessage(tc.message)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, chat)
+
+			message, err := FromChatMessage(chat)
+			require.NoError(t, err)
+			assert.Equal(t, tc.message, message)
+		})
+	}
+}
+
+func TestChatMessage_Unsupported(t *testing.T) {
+	pdf := "application/pdf"
+	uri := "https://example.com/doc.pdf"
+	_, err := ToChatMessage(NewMessage(MessageRoleUser, []Part{
+		NewTextPart("read this"),
+		FilePart{Type: PartTypeFile, File: FileContent{MimeType: &pdf, URI: &uri}},
+	}))
+	assert.ErrorIs(t, err, ErrUnsupportedChatPart)
+	assert.Contains(t, err.Error(), "part 1")
+
+	_, err = ToChatMessage(NewMessage("system", nil))
+	assert.ErrorIs(t, err, ErrUnsupportedChatRole)
+
+	_, err = FromChatMessage(ChatMessage{Role: "system"})
+	assert.ErrorIs(t, err, ErrUnsupportedChatRole)
+
+	_, err = FromChatMessage(ChatMessage{Role: ChatRoleUser, Content: []ChatContent{{Type: "audio"}}})
+	assert.ErrorIs(t, err, ErrUnsupportedChatPart)
+
+	_, err = FromChatMessage(ChatMessage{Role: ChatRoleUser, Content: []ChatContent{{Type: ChatContentImage}}})
+	assert.ErrorIs(t, err, ErrUnsupportedChatPart)
+}
+
+func TestFromChatMessage_DataURLMimeType(t *testing.T) {
+	message, err := FromChatMessage(ChatMessage{Role: ChatRoleUser, Content: []ChatContent{
+		{Type: ChatContentImage, ImageURL: "data:image/gif;base64,R0lGODlh"},
+	}})
+	require.NoError(t, err)
+	file := message.Parts[0].(FilePart).File
+	require.NotNil(t, file.MimeType)
+	assert.Equal(t, "image/gif", *file.MimeType)
+	require.NotNil(t, file.Bytes)
+	assert.Equal(t, "R0lGODlh", *file.Bytes)
+	assert.Nil(t, file.URI)
+}
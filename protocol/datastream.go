@@ -0,0 +1,155 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrDataNotArray is returned by DataDecoder for data that is not a JSON array.
+var ErrDataNotArray = errors.New("data part payload is not a JSON array")
+
+// DataDecoder reads the elements of a JSON array payload one at a time with a
+// streaming token reader, so a large payload is never decoded whole:
+//
+//	dec, err := protocol.NewDataDecoder(part)
+//	...
+//	for dec.Next() {
+//		var row Row
+//		if err := dec.Decode(&row); err != nil { ... }
+//	}
+//	if err := dec.Err(); err != nil { ... }
+type DataDecoder struct {
+	dec     *json.Decoder
+	started bool
+	done    bool
+	err     error
+}
+
+// NewDataDecoder returns a decoder over the payload of part. A payload kept as
+// json.RawMessage, see UnmarshalMessageRawData, is read in place; any other
+// payload is encoded to JSON first.
+func NewDataDecoder(part DataPart) (*DataDecoder, error) {
+	raw, ok := part.Data.(json.RawMessage)
+	if !ok {
+		encoded, err := json.Marshal(part.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode data part payload: %w", err)
+		}
+		raw = encoded
+	}
+	return NewDataDecoderReader(bytes.NewReader(raw)), nil
+}
+
+// NewDataDecoderReader returns a decoder over the JSON array read from r.
+func NewDataDecoderReader(r io.Reader) *DataDecoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &DataDecoder{dec: dec}
+}
+
+// Next reports whether another array element is available to Decode. It returns
+// false at the end of the array or on error; see Err.
+func (d *DataDecoder) Next() bool {
+	if d.done {
+		return false
+	}
+	if !d.started {
+		d.started = true
+		token, err := d.dec.Token()
+		if err != nil {
+			return d.fail(fmt.Errorf("failed to read data part payload: %w", err))
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return d.fail(ErrDataNotArray)
+		}
+	}
+	if d.dec.More() {
+		return true
+	}
+	if _, err := d.dec.Token(); err != nil { // The closing bracket.
+		return d.fail(fmt.Errorf("failed to read data part payload: %w", err))
+	}
+	d.done = true
+	return false
+}
+
+// Decode decodes the next array element into v. Numbers decode into interface
+// values as json.Number.
+func (d *DataDecoder) Decode(v interface{}) error {
+	if d.err != nil {
+		return d.err
+	}
+	if err := d.dec.Decode(v); err != nil {
+		d.fail(fmt.Errorf("failed to decode data part element: %w", err))
+		return d.err
+	}
+	return nil
+}
+
+// Err returns the first error met while reading the payload, if any.
+func (d *DataDecoder) Err() error {
+	return d.err
+}
+
+// fail records err and ends the iteration.
+func (d *DataDecoder) fail(err error) bool {
+	d.err = err
+	d.done = true
+	return false
+}
+
+// UnmarshalMessageRawData decodes a JSON message like json.Unmarshal, except that
+// the payload of each DataPart at least minSize bytes long is kept as the raw
+// json.RawMessage instead of being decoded into maps and slices, which take
+// several times the memory. Read such payloads with NewDataDecoder. Compressed
+// payloads are always decoded.
+func UnmarshalMessageRawData(data []byte, minSize int, message *Message) error {
+	type Alias Message // Alias to avoid recursion.
+	temp := &struct {
+		Parts []json.RawMessage `json:"parts"`
+		*Alias
+	}{
+		Alias: (*Alias)(message),
+	}
+	if err := json.Unmarshal(data, temp); err != nil {
+		return fmt.Errorf("failed to unmarshal message base: %w", err)
+	}
+	message.Parts = make([]Part, 0, len(temp.Parts))
+	for i, rawPart := range temp.Parts {
+		part, err := unmarshalRawDataPart(rawPart, minSize)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal part %d: %w", i, err)
+		}
+		message.Parts = append(message.Parts, part)
+	}
+	return nil
+}
+
+// unmarshalRawDataPart decodes a part, keeping the payload of a large DataPart raw.
+func unmarshalRawDataPart(rawPart json.RawMessage, minSize int) (Part, error) {
+	type Alias DataPart // Alias to avoid recursion.
+	temp := &struct {
+		Type PartType        `json:"type"`
+		Data json.RawMessage `json:"data"`
+		*Alias
+	}{
+		Alias: &Alias{},
+	}
+	if err := json.Unmarshal(rawPart, temp); err != nil || temp.Type != PartTypeData ||
+		len(temp.Data) < minSize || (temp.Encoding != nil && *temp.Encoding != "") {
+		return unmarshalPart(rawPart)
+	}
+	part := DataPart(*temp.Alias)
+	part.Type = temp.Type
+	part.Data = temp.Data
+	return part, nil
+}
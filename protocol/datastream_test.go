@@ -0,0 +1,130 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeArray returns the JSON of an array of n objects numbered from zero.
+func largeArray(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"seq":%d,"name":"item-%d"}`, i, i)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+type seqItem struct {
+	Seq  int    `json:"seq"`
+	Name string `json:"name"`
+}
+
+func TestDataDecoder(t *testing.T) {
+	t.Run("LargeArrayBoundedMemory", func(t *testing.T) {
+		const n = 200000
+		raw := largeArray(n)
+		dec, err := NewDataDecoder(DataPart{Type: PartTypeData, Data: json.RawMessage(raw)})
+		require.NoError(t, err)
+
+		var stats runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		baseline := stats.HeapAlloc
+		var peak uint64
+		count := 0
+		for dec.Next() {
+			var item seqItem
+			require.NoError(t, dec.Decode(&item))
+			require.Equal(t, count, item.Seq, "elements arrive in order")
+			count++
+			if count%20000 == 0 {
+				runtime.GC()
+				runtime.ReadMemStats(&stats)
+				if stats.HeapAlloc > baseline && stats.HeapAlloc-baseline > peak {
+					peak = stats.HeapAlloc - baseline
+				}
+			}
+		}
+		require.NoError(t, dec.Err())
+		assert.Equal(t, n, count)
+		// Decoding the payload whole would take several times its size.
+		assert.Less(t, peak, uint64(len(raw)/10), "memory use should not grow with the payload")
+		runtime.KeepAlive(raw)
+	})
+
+	t.Run("DecodedPayload", func(t *testing.T) {
+		dec, err := NewDataDecoder(DataPart{Type: PartTypeData, Data: []interface{}{"a", "b"}})
+		require.NoError(t, err)
+		var items []string
+		for dec.Next() {
+			var item string
+			require.NoError(t, dec.Decode(&item))
+			items = append(items, item)
+		}
+		require.NoError(t, dec.Err())
+		assert.Equal(t, []string{"a", "b"}, items)
+	})
+
+	t.Run("NotArray", func(t *testing.T) {
+		dec, err := NewDataDecoder(DataPart{Type: PartTypeData, Data: map[string]interface{}{"a": 1}})
+		require.NoError(t, err)
+		assert.False(t, dec.Next())
+		assert.ErrorIs(t, dec.Err(), ErrDataNotArray)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		dec := NewDataDecoderReader(strings.NewReader(`[{"seq":0},{"seq":`))
+		require.True(t, dec.Next())
+		var item seqItem
+		require.NoError(t, dec.Decode(&item))
+		require.True(t, dec.Next())
+		assert.Error(t, dec.Decode(&item))
+		assert.False(t, dec.Next())
+		assert.Error(t, dec.Err())
+	})
+}
+
+func TestUnmarshalMessageRawData(t *testing.T) {
+	gzip := DataEncodingGzip
+	compressed, err := json.Marshal(DataPart{Type: PartTypeData, Data: []interface{}{1.0, 2.0}, Encoding: &gzip})
+	require.NoError(t, err)
+	data := []byte(`{"role":"user","parts":[` +
+		`{"type":"text","text":"rows"},` +
+		`{"type":"data","data":{"small":true}},` +
+		`{"type":"data","data":[1, 2, 3, 4, 5, 6],"metadata":{"kind":"rows"}},` +
+		string(compressed) + `]}`)
+
+	var message Message
+	require.NoError(t, UnmarshalMessageRawData(data, 16, &message))
+	assert.Equal(t, MessageRoleUser, message.Role)
+	require.Len(t, message.Parts, 4)
+	assert.Equal(t, "rows", message.Parts[0].(TextPart).Text)
+	assert.Equal(t, map[string]interface{}{"small": true}, message.Parts[1].(DataPart).Data, "small payloads are decoded")
+	large := message.Parts[2].(DataPart)
+	assert.Equal(t, json.RawMessage(`[1, 2, 3, 4, 5, 6]`), large.Data)
+	assert.Equal(t, "rows", large.Metadata["kind"])
+	assert.Equal(t, []interface{}{1.0, 2.0}, message.Parts[3].(DataPart).Data, "compressed payloads are decoded")
+
+	// A raw payload encodes back to the same JSON.
+	encoded, err := json.Marshal(large)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"data","data":[1,2,3,4,5,6],"metadata":{"kind":"rows"}}`, string(encoded))
+}
@@ -0,0 +1,47 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import "encoding/json"
+
+// GrantCreditsParams are the params of a tasks/grantCredits notification, sent by
+// a client over a long-lived connection to let the server send Credits more events
+// of a stream. A stream, opened by a tasks/sendSubscribe or tasks/resubscribe
+// request on the connection, starts without credits; the server sends one event
+// per credit and pauses the stream while it has none left.
+type GrantCreditsParams struct {
+	// ID is the JSON-RPC id of the request that opened the stream.
+	ID string `json:"id"`
+	// Credits is the number of events granted, added to those not used yet.
+	Credits int `json:"credits"`
+}
+
+// Validate checks the params of a tasks/grantCredits notification and returns a
+// *ValidationError listing every invalid field, or nil.
+func (p GrantCreditsParams) Validate() error {
+	errs := &ValidationError{}
+	if p.ID == "" {
+		errs.Add("/id", "is required")
+	}
+	if p.Credits <= 0 {
+		errs.Add("/credits", "must be positive")
+	}
+	return errs.Err()
+}
+
+// StreamEventParams are the params of a tasks/streamEvent notification, carrying
+// an event of a stream from the server over a long-lived connection. The stream
+// ends after the final status event of its task, or an EventClose event.
+type StreamEventParams struct {
+	// ID is the JSON-RPC id of the request that opened the stream.
+	ID string `json:"id"`
+	// EventType is the type of the event, as of the SSE event types such as
+	// EventTaskStatusUpdate.
+	EventType string `json:"eventType"`
+	// Event is the JSON encoding of the event.
+	Event json.RawMessage `json:"event"`
+}
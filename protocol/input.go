@@ -0,0 +1,117 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// TaskInputParams are the params of a tasks/sendInput request.
+type TaskInputParams struct {
+	// ID is the ID of the running task the input is for.
+	ID string `json:"id"`
+	// Message is the input, for example a user message with an audio FilePart.
+	Message Message `json:"message"`
+	// Final marks Message as the last input of the task. The processor sees its
+	// input end once it was delivered, and further input is rejected.
+	Final bool `json:"final,omitempty"`
+}
+
+// Validate checks the params of a tasks/sendInput request and returns a
+// *ValidationError listing every invalid field, or nil.
+func (p TaskInputParams) Validate() error {
+	errs := &ValidationError{}
+	if p.ID == "" {
+		errs.Add("/id", "is required")
+	}
+	validateMessage(errs, "/message", p.Message)
+	return errs.Err()
+}
+
+// NewAudioPart returns a FilePart holding a chunk of audio of the given MIME type,
+// such as "audio/pcm" or "audio/ogg", to stream as input with tasks/sendInput.
+func NewAudioPart(mimeType string, data []byte) FilePart {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	return FilePart{
+		Type: PartTypeFile,
+		File: FileContent{MimeType: &mimeType, Bytes: &encoded},
+	}
+}
+
+// DataKindTranscription is the MetadataKeyDataKind value of a DataPart holding a
+// Transcription.
+const DataKindTranscription = "transcription"
+
+// MetadataKeyDataKind is the DataPart metadata key naming the convention the data
+// follows, such as DataKindTranscription.
+const MetadataKeyDataKind = "kind"
+
+// Transcription is the data of a transcription DataPart, sent by an agent
+// recognizing speech in streamed audio input. An agent sends partial
+// transcriptions of the speech recognized so far as it goes, each replacing the
+// previous one, and a final transcription once the speech is fully recognized.
+// See NewTranscriptionPart and TranscriptionFromEvent.
+type Transcription struct {
+	// Text is the speech recognized so far, or all of it if Final is set.
+	Text string `json:"text"`
+	// Final marks the transcription as the final result. Partial ones may still
+	// change as more audio is recognized.
+	Final bool `json:"final"`
+}
+
+// NewTranscriptionPart returns a DataPart holding a transcription of text.
+func NewTranscriptionPart(text string, final bool) DataPart {
+	return DataPart{
+		Type:     PartTypeData,
+		Data:     Transcription{Text: text, Final: final},
+		Metadata: map[string]interface{}{MetadataKeyDataKind: DataKindTranscription},
+	}
+}
+
+// TranscriptionFromPart returns the transcription held by part, and false if part
+// is not a transcription DataPart.
+func TranscriptionFromPart(part Part) (Transcription, bool) {
+	dataPart, ok := part.(DataPart)
+	if !ok || dataPart.Metadata[MetadataKeyDataKind] != DataKindTranscription {
+		return Transcription{}, false
+	}
+	if transcription, ok := dataPart.Data.(Transcription); ok {
+		return transcription, true
+	}
+	// Decoded parts hold the data as a generic JSON value.
+	data, err := json.Marshal(dataPart.Data)
+	if err != nil {
+		return Transcription{}, false
+	}
+	var transcription Transcription
+	if err := json.Unmarshal(data, &transcription); err != nil {
+		return Transcription{}, false
+	}
+	return transcription, true
+}
+
+// TranscriptionFromEvent returns the transcription carried by the message of a
+// TaskMessageEvent or TaskStatusUpdateEvent, and false if it carries none.
+func TranscriptionFromEvent(event TaskEvent) (Transcription, bool) {
+	var message *Message
+	switch event := event.(type) {
+	case TaskMessageEvent:
+		message = &event.Message
+	case TaskStatusUpdateEvent:
+		message = event.Status.Message
+	}
+	if message == nil {
+		return Transcription{}, false
+	}
+	for _, part := range message.Parts {
+		if transcription, ok := TranscriptionFromPart(part); ok {
+			return transcription, true
+		}
+	}
+	return Transcription{}, false
+}
@@ -0,0 +1,67 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptionFromEvent(t *testing.T) {
+	t.Run("DecodedFromTheWire", func(t *testing.T) {
+		event := TaskMessageEvent{
+			ID:      "voice",
+			Message: NewMessage(MessageRoleAgent, []Part{NewTranscriptionPart("hello wor", false)}),
+		}
+		data, err := json.Marshal(event)
+		require.NoError(t, err)
+		var decoded TaskMessageEvent
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		transcription, ok := TranscriptionFromEvent(decoded)
+		require.True(t, ok)
+		assert.Equal(t, Transcription{Text: "hello wor", Final: false}, transcription)
+	})
+
+	t.Run("FinalStatus", func(t *testing.T) {
+		message := NewMessage(MessageRoleAgent, []Part{NewTranscriptionPart("hello world", true)})
+		event := TaskStatusUpdateEvent{
+			ID:     "voice",
+			Status: TaskStatus{State: TaskStateCompleted, Message: &message},
+			Final:  true,
+		}
+		transcription, ok := TranscriptionFromEvent(event)
+		require.True(t, ok)
+		assert.Equal(t, Transcription{Text: "hello world", Final: true}, transcription)
+	})
+
+	t.Run("OtherParts", func(t *testing.T) {
+		event := TaskMessageEvent{Message: NewMessage(MessageRoleAgent, []Part{
+			NewTextPart("hello"),
+			DataPart{Type: PartTypeData, Data: map[string]interface{}{"text": "hello"}},
+		})}
+		_, ok := TranscriptionFromEvent(event)
+		assert.False(t, ok)
+		_, ok = TranscriptionFromEvent(TaskStatusUpdateEvent{Status: TaskStatus{State: TaskStateWorking}})
+		assert.False(t, ok)
+	})
+}
+
+func TestTaskInputParams_Validate(t *testing.T) {
+	params := TaskInputParams{
+		ID:      "voice",
+		Message: NewMessage(MessageRoleUser, []Part{NewAudioPart("audio/pcm", []byte{1, 2, 3})}),
+	}
+	require.NoError(t, params.Validate())
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, TaskInputParams{}.Validate(), &validationErr)
+	assert.Equal(t, "/id", validationErr.Fields[0].Path)
+}
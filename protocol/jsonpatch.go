@@ -0,0 +1,432 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPatch is returned for JSON patches that are malformed or cannot be
+// applied to their target.
+var ErrInvalidPatch = errors.New("invalid JSON patch")
+
+// PatchOp is a JSON Patch (RFC 6902) operation name.
+type PatchOp string
+
+// JSON Patch operations.
+const (
+	PatchOpAdd     PatchOp = "add"
+	PatchOpRemove  PatchOp = "remove"
+	PatchOpReplace PatchOp = "replace"
+	PatchOpMove    PatchOp = "move"
+	PatchOpCopy    PatchOp = "copy"
+	PatchOpTest    PatchOp = "test"
+)
+
+// PatchOperation is a single JSON Patch (RFC 6902) operation.
+type PatchOperation struct {
+	// Op is the operation to perform.
+	Op PatchOp `json:"op"`
+	// Path is the JSON Pointer (RFC 6901) of the target location.
+	Path string `json:"path"`
+	// From is the JSON Pointer of the source location for move and copy.
+	From string `json:"from,omitempty"`
+	// Value is the value for add, replace and test.
+	Value interface{} `json:"value"`
+}
+
+// ValidatePatch checks that every operation of patch is well formed, without
+// applying it to a document.
+func ValidatePatch(patch []PatchOperation) error {
+	for i, op := range patch {
+		if err := op.validate(); err != nil {
+			return fmt.Errorf("%w: operation %d (%s %q): %v", ErrInvalidPatch, i, op.Op, op.Path, err)
+		}
+	}
+	return nil
+}
+
+// validate checks a single operation.
+func (op PatchOperation) validate() error {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case PatchOpAdd, PatchOpReplace, PatchOpTest:
+		return nil
+	case PatchOpRemove:
+		if len(path) == 0 {
+			return errors.New("cannot remove the whole document")
+		}
+		return nil
+	case PatchOpMove, PatchOpCopy:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+		if op.Op == PatchOpMove && len(from) < len(path) && isPrefix(from, path) {
+			return errors.New("cannot move a value into one of its children")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// ApplyPatch applies patch to doc and returns the patched document. doc is first
+// converted to its generic JSON form (maps, slices, float64 numbers and so on),
+// which is also the form of the result. The operations apply all or nothing:
+// doc is left unchanged and an ErrInvalidPatch error is returned if any fails.
+func ApplyPatch(doc interface{}, patch []PatchOperation) (interface{}, error) {
+	if err := ValidatePatch(patch); err != nil {
+		return nil, err
+	}
+	result, err := toGenericJSON(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	for i, op := range patch {
+		result, err = op.apply(result)
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d (%s %q): %v", ErrInvalidPatch, i, op.Op, op.Path, err)
+		}
+	}
+	return result, nil
+}
+
+// apply performs the operation on doc, which is in generic JSON form and owned
+// by the caller.
+func (op PatchOperation) apply(doc interface{}) (interface{}, error) {
+	// The pointers were checked by ValidatePatch.
+	path, _ := parsePointer(op.Path)
+	switch op.Op {
+	case PatchOpAdd:
+		value, err := toGenericJSON(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+	case PatchOpRemove:
+		return modify(doc, path, removeChild)
+	case PatchOpReplace:
+		value, err := toGenericJSON(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return value, nil
+		}
+		return modify(doc, path, func(parent interface{}, key string) (interface{}, error) {
+			return replaceChild(parent, key, value)
+		})
+	case PatchOpMove:
+		from, _ := parsePointer(op.From)
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if len(from) > 0 {
+			if doc, err = modify(doc, from, removeChild); err != nil {
+				return nil, err
+			}
+		}
+		return addValue(doc, path, value)
+	case PatchOpCopy:
+		from, _ := parsePointer(op.From)
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if value, err = toGenericJSON(value); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+	case PatchOpTest:
+		expected, err := toGenericJSON(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		actual, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return nil, errors.New("test failed: value differs")
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// addValue adds value at path, replacing the whole document for the empty path.
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		return addChild(parent, key, value)
+	})
+}
+
+// modify walks doc to the parent of the location at path, which must not be
+// empty, and replaces the parent with what change returns for it and the last
+// path token. It returns the updated document.
+func modify(
+	doc interface{},
+	path []string,
+	change func(parent interface{}, key string) (interface{}, error),
+) (interface{}, error) {
+	if len(path) == 1 {
+		return change(doc, path[0])
+	}
+	child, err := getChild(doc, path[0])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := modify(child, path[1:], change)
+	if err != nil {
+		return nil, err
+	}
+	return replaceChild(doc, path[0], updated)
+}
+
+// getValue returns the value at path in doc.
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	for _, key := range path {
+		child, err := getChild(doc, key)
+		if err != nil {
+			return nil, err
+		}
+		doc = child
+	}
+	return doc, nil
+}
+
+// getChild returns the member or element key of container.
+func getChild(container interface{}, key string) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		child, ok := c[key]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", key)
+		}
+		return child, nil
+	case []interface{}:
+		i, err := arrayIndex(key, len(c)-1)
+		if err != nil {
+			return nil, err
+		}
+		return c[i], nil
+	default:
+		return nil, fmt.Errorf("cannot reference %q in a %s", key, jsonKind(container))
+	}
+}
+
+// addChild sets member key of an object, or inserts before element key of an
+// array, "-" meaning the end.
+func addChild(container interface{}, key string, value interface{}) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		c[key] = value
+		return c, nil
+	case []interface{}:
+		i := len(c)
+		if key != "-" {
+			var err error
+			if i, err = arrayIndex(key, len(c)); err != nil {
+				return nil, err
+			}
+		}
+		c = append(c, nil)
+		copy(c[i+1:], c[i:])
+		c[i] = value
+		return c, nil
+	default:
+		return nil, fmt.Errorf("cannot add %q to a %s", key, jsonKind(container))
+	}
+}
+
+// replaceChild sets the existing member or element key of container.
+func replaceChild(container interface{}, key string, value interface{}) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		if _, ok := c[key]; !ok {
+			return nil, fmt.Errorf("member %q not found", key)
+		}
+		c[key] = value
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(key, len(c)-1)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = value
+		return c, nil
+	default:
+		return nil, fmt.Errorf("cannot reference %q in a %s", key, jsonKind(container))
+	}
+}
+
+// removeChild deletes the existing member or element key of container.
+func removeChild(container interface{}, key string) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		if _, ok := c[key]; !ok {
+			return nil, fmt.Errorf("member %q not found", key)
+		}
+		delete(c, key)
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(key, len(c)-1)
+		if err != nil {
+			return nil, err
+		}
+		return append(c[:i], c[i+1:]...), nil
+	default:
+		return nil, fmt.Errorf("cannot reference %q in a %s", key, jsonKind(container))
+	}
+}
+
+// arrayIndex parses an array index token, which must be at most last.
+func arrayIndex(key string, last int) (int, error) {
+	if key == "" || (len(key) > 1 && key[0] == '0') || strings.TrimLeft(key, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", key)
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i > last {
+		return 0, fmt.Errorf("array index %q out of range", key)
+	}
+	return i, nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("JSON pointer %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("JSON pointer %q has an invalid ~ escape", pointer)
+			}
+		}
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// isPrefix reports whether prefix is a leading part of path.
+func isPrefix(prefix, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// toGenericJSON returns a copy of v in the form encoding/json decodes into an
+// empty interface.
+func toGenericJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// jsonKind names the JSON type of a generic JSON value for error messages.
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// ApplyPatch applies patch to the data of the DataPart at position part of the
+// artifact. The artifact gets a new parts slice, so earlier copies of it, such as
+// events already sent, are not changed.
+func (a *Artifact) ApplyPatch(part int, patch []PatchOperation) error {
+	if part < 0 || part >= len(a.Parts) {
+		return fmt.Errorf("%w: artifact %d has no part %d", ErrInvalidPatch, a.Index, part)
+	}
+	var dataPart DataPart
+	switch p := a.Parts[part].(type) {
+	case DataPart:
+		dataPart = p
+	case *DataPart:
+		dataPart = *p
+	default:
+		return fmt.Errorf("%w: part %d of artifact %d is a %T, not a DataPart", ErrInvalidPatch, part, a.Index, p)
+	}
+	data, err := ApplyPatch(dataPart.Data, patch)
+	if err != nil {
+		return err
+	}
+	dataPart.Data = data
+	parts := append([]Part(nil), a.Parts...)
+	parts[part] = dataPart
+	a.Parts = parts
+	return nil
+}
+
+// PatchArtifacts applies patch to the DataPart at position part of the artifact
+// in artifacts with the given index. An artifact streamed in chunks is stored as
+// one entry per chunk, and part counts through the parts of its chunks in order,
+// from the last chunk that did not append to the ones before, as clients assemble
+// them. It returns a new slice and leaves artifacts unchanged.
+func PatchArtifacts(artifacts []Artifact, index, part int, patch []PatchOperation) ([]Artifact, error) {
+	var chunks []int
+	for i := range artifacts {
+		if artifacts[i].Index != index {
+			continue
+		}
+		if artifacts[i].Append == nil || !*artifacts[i].Append {
+			chunks = chunks[:0] // The chunk starts the artifact over.
+		}
+		chunks = append(chunks, i)
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("%w: no artifact with index %d", ErrInvalidPatch, index)
+	}
+	offset := part
+	for _, i := range chunks {
+		if offset >= len(artifacts[i].Parts) {
+			offset -= len(artifacts[i].Parts)
+			continue
+		}
+		patched := append([]Artifact(nil), artifacts...)
+		if err := patched[i].ApplyPatch(offset, patch); err != nil {
+			return nil, err
+		}
+		return patched, nil
+	}
+	return nil, fmt.Errorf("%w: artifact %d has no part %d", ErrInvalidPatch, index, part)
+}
@@ -0,0 +1,162 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonDoc decodes a JSON document into its generic form.
+func jsonDoc(t *testing.T, s string) interface{} {
+	t.Helper()
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &doc))
+	return doc
+}
+
+func TestApplyPatch(t *testing.T) {
+	const doc = `{"title":"draft","tags":["a","c"],"meta":{"a/b":1,"m~n":2},"rows":[{"v":1}]}`
+	tests := []struct {
+		name  string
+		patch []PatchOperation
+		want  string
+	}{
+		{
+			name:  "add member",
+			patch: []PatchOperation{{Op: PatchOpAdd, Path: "/author", Value: "bob"}},
+			want:  `{"title":"draft","author":"bob","tags":["a","c"],"meta":{"a/b":1,"m~n":2},"rows":[{"v":1}]}`,
+		},
+		{
+			name:  "add inserts into array",
+			patch: []PatchOperation{{Op: PatchOpAdd, Path: "/tags/1", Value: "b"}},
+			want:  `{"title":"draft","tags":["a","b","c"],"meta":{"a/b":1,"m~n":2},"rows":[{"v":1}]}`,
+		},
+		{
+			name:  "add appends to array",
+			patch: []PatchOperation{{Op: PatchOpAdd, Path: "/rows/-", Value: map[string]int{"v": 2}}},
+			want:  `{"title":"draft","tags":["a","c"],"meta":{"a/b":1,"m~n":2},"rows":[{"v":1},{"v":2}]}`,
+		},
+		{
+			name:  "replace nested value",
+			patch: []PatchOperation{{Op: PatchOpReplace, Path: "/rows/0/v", Value: 5}},
+			want:  `{"title":"draft","tags":["a","c"],"meta":{"a/b":1,"m~n":2},"rows":[{"v":5}]}`,
+		},
+		{
+			name:  "replace escaped members",
+			patch: []PatchOperation{{Op: PatchOpReplace, Path: "/meta/a~1b", Value: 3}, {Op: PatchOpRemove, Path: "/meta/m~0n"}},
+			want:  `{"title":"draft","tags":["a","c"],"meta":{"a/b":3},"rows":[{"v":1}]}`,
+		},
+		{
+			name:  "remove array element",
+			patch: []PatchOperation{{Op: PatchOpRemove, Path: "/tags/0"}},
+			want:  `{"title":"draft","tags":["c"],"meta":{"a/b":1,"m~n":2},"rows":[{"v":1}]}`,
+		},
+		{
+			name:  "move and copy",
+			patch: []PatchOperation{{Op: PatchOpMove, From: "/title", Path: "/name"}, {Op: PatchOpCopy, From: "/tags/1", Path: "/tags/0"}},
+			want:  `{"name":"draft","tags":["c","a","c"],"meta":{"a/b":1,"m~n":2},"rows":[{"v":1}]}`,
+		},
+		{
+			name:  "test passes",
+			patch: []PatchOperation{{Op: PatchOpTest, Path: "/rows", Value: []map[string]int{{"v": 1}}}},
+			want:  doc,
+		},
+		{
+			name:  "replace whole document",
+			patch: []PatchOperation{{Op: PatchOpReplace, Path: "", Value: []string{"x"}}},
+			want:  `["x"]`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			original := jsonDoc(t, doc)
+			got, err := ApplyPatch(original, tc.patch)
+			require.NoError(t, err)
+			assert.Equal(t, jsonDoc(t, tc.want), got)
+			assert.Equal(t, jsonDoc(t, doc), original, "the input document must not change")
+		})
+	}
+}
+
+func TestApplyPatch_Invalid(t *testing.T) {
+	const doc = `{"title":"draft","tags":["a"]}`
+	tests := []struct {
+		name  string
+		patch []PatchOperation
+	}{
+		{"unknown op", []PatchOperation{{Op: "merge", Path: "/title"}}},
+		{"path without slash", []PatchOperation{{Op: PatchOpAdd, Path: "title", Value: 1}}},
+		{"bad escape", []PatchOperation{{Op: PatchOpRemove, Path: "/ti~2tle"}}},
+		{"remove missing member", []PatchOperation{{Op: PatchOpRemove, Path: "/author"}}},
+		{"remove whole document", []PatchOperation{{Op: PatchOpRemove, Path: ""}}},
+		{"replace missing member", []PatchOperation{{Op: PatchOpReplace, Path: "/author", Value: "bob"}}},
+		{"add past array end", []PatchOperation{{Op: PatchOpAdd, Path: "/tags/2", Value: "c"}}},
+		{"leading zero index", []PatchOperation{{Op: PatchOpReplace, Path: "/tags/00", Value: "c"}}},
+		{"missing parent", []PatchOperation{{Op: PatchOpAdd, Path: "/meta/a", Value: 1}}},
+		{"child of scalar", []PatchOperation{{Op: PatchOpAdd, Path: "/title/x", Value: 1}}},
+		{"move into child", []PatchOperation{{Op: PatchOpMove, From: "/tags", Path: "/tags/0"}}},
+		{"copy missing from", []PatchOperation{{Op: PatchOpCopy, From: "/author", Path: "/name"}}},
+		{"test fails", []PatchOperation{{Op: PatchOpTest, Path: "/title", Value: "final"}}},
+		{
+			"later operation fails",
+			[]PatchOperation{{Op: PatchOpReplace, Path: "/title", Value: "final"}, {Op: PatchOpRemove, Path: "/x"}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			original := jsonDoc(t, doc)
+			_, err := ApplyPatch(original, tc.patch)
+			assert.ErrorIs(t, err, ErrInvalidPatch)
+			assert.Equal(t, jsonDoc(t, doc), original)
+		})
+	}
+}
+
+func TestValidatePatch(t *testing.T) {
+	assert.NoError(t, ValidatePatch([]PatchOperation{
+		{Op: PatchOpAdd, Path: "/a", Value: 1},
+		{Op: PatchOpMove, From: "/a", Path: "/b"},
+	}))
+	assert.ErrorIs(t, ValidatePatch([]PatchOperation{{Op: PatchOpMove, From: "bad", Path: "/b"}}), ErrInvalidPatch)
+}
+
+func TestPatchArtifacts(t *testing.T) {
+	artifacts := []Artifact{
+		{Index: 0, Parts: []Part{NewTextPart("summary")}},
+		{Index: 1, Parts: []Part{NewTextPart("table"), DataPart{Type: PartTypeData, Data: map[string]interface{}{"rows": 1}}}},
+	}
+	patched, err := PatchArtifacts(artifacts, 1, 1, []PatchOperation{{Op: PatchOpReplace, Path: "/rows", Value: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"rows": float64(2)}, patched[1].Parts[1].(DataPart).Data)
+	assert.Equal(t, map[string]interface{}{"rows": 1}, artifacts[1].Parts[1].(DataPart).Data,
+		"the original artifacts must not change")
+
+	_, err = PatchArtifacts(artifacts, 2, 0, nil)
+	assert.ErrorIs(t, err, ErrInvalidPatch, "unknown artifact")
+	_, err = PatchArtifacts(artifacts, 1, 0, nil)
+	assert.ErrorIs(t, err, ErrInvalidPatch, "part is not a DataPart")
+	_, err = PatchArtifacts(artifacts, 1, 5, nil)
+	assert.ErrorIs(t, err, ErrInvalidPatch, "part out of range")
+
+	// The parts of an artifact streamed in chunks are counted through its chunks.
+	appended := true
+	chunked := []Artifact{
+		{Index: 0, Parts: []Part{NewTextPart("stale")}},
+		{Index: 0, Parts: []Part{NewTextPart("header")}},
+		{Index: 1, Parts: []Part{NewTextPart("other")}},
+		{Index: 0, Append: &appended, Parts: []Part{DataPart{Type: PartTypeData, Data: map[string]interface{}{"rows": 1}}}},
+	}
+	patched, err = PatchArtifacts(chunked, 0, 1, []PatchOperation{{Op: PatchOpReplace, Path: "/rows", Value: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"rows": float64(2)}, patched[3].Parts[0].(DataPart).Data)
+	_, err = PatchArtifacts(chunked, 0, 2, nil)
+	assert.ErrorIs(t, err, ErrInvalidPatch, "part beyond the last chunk")
+}
@@ -0,0 +1,79 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the locale used when neither the request nor the server specifies one.
+const DefaultLocale = "en"
+
+// localePattern matches well-formed BCP 47 language tags (RFC 5646), excluding grandfathered tags.
+var localePattern = regexp.MustCompile(`^(?i:` +
+	`([a-z]{2,3}(-[a-z]{3}){0,3}|[a-z]{4,8})` + // language and extlang
+	`(-[a-z]{4})?` + // script
+	`(-([a-z]{2}|[0-9]{3}))?` + // region
+	`(-([a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*` + // variants
+	`(-[0-9a-wyz](-[a-z0-9]{2,8})+)*` + // extensions
+	`(-x(-[a-z0-9]{1,8})+)?` + // private use
+	`|x(-[a-z0-9]{1,8})+)$`)
+
+// ValidateLocale returns an error if the tag is not a well-formed BCP 47 language tag.
+func ValidateLocale(tag string) error {
+	if !localePattern.MatchString(tag) {
+		return fmt.Errorf("invalid BCP 47 language tag %q", tag)
+	}
+	return nil
+}
+
+// ParseAcceptLanguage parses an Accept-Language header value and returns the valid
+// language tags in order of preference. Wildcards, invalid tags and tags with a
+// quality of zero are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag     string
+		quality float64
+	}
+	var tags []weightedTag
+	for _, entry := range strings.Split(header, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ";")
+		tag := strings.TrimSpace(parts[0])
+		if tag == "" || tag == "*" || ValidateLocale(tag) != nil {
+			continue
+		}
+		quality := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				quality = 0
+			} else {
+				quality = q
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		tags = append(tags, weightedTag{tag: tag, quality: quality})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+	result := make([]string, 0, len(tags))
+	for _, t := range tags {
+		result = append(result, t.tag)
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLocale(t *testing.T) {
+	valid := []string{"en", "en-US", "zh-Hans-CN", "es-419", "sr-Latn-RS", "de-CH-1901", "en-US-x-twain", "x-private", "EN-us"}
+	for _, tag := range valid {
+		assert.NoError(t, ValidateLocale(tag), tag)
+	}
+	invalid := []string{"", "e", "en_US", "en-", "english-language-tag", "en-US-", "123", "en--US"}
+	for _, tag := range invalid {
+		assert.Error(t, ValidateLocale(tag), tag)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"da", "en-GB", "en"}, ParseAcceptLanguage("da, en-GB;q=0.8, en;q=0.7"))
+	assert.Equal(t, []string{"ja", "de-DE"}, ParseAcceptLanguage("de-DE;q=0.8, *;q=0.5, ja;q=0.9, fr;q=0"))
+	assert.Equal(t, []string{"fr"}, ParseAcceptLanguage("en_US, fr, de;q=abc"))
+	assert.Empty(t, ParseAcceptLanguage(""))
+}
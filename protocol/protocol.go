@@ -16,17 +16,89 @@ const (
 	MethodTasksPushNotificationSet = "tasks/pushNotification/set"
 	MethodTasksPushNotificationGet = "tasks/pushNotification/get"
 	MethodTasksResubscribe         = "tasks/resubscribe"
+	// MethodTasksSubscribeMultiple streams the events of several existing tasks over
+	// one SSE connection. It is an extension of this implementation, not part of the spec.
+	MethodTasksSubscribeMultiple = "tasks/subscribeMultiple"
+	// MethodTasksExport and MethodTasksImport move tasks between servers as
+	// TaskSnapshot documents. They are extensions of this implementation.
+	MethodTasksExport = "tasks/export"
+	MethodTasksImport = "tasks/import"
+	// MethodTasksSendBatch sends several tasks in one request, see SendTaskBatchParams.
+	// Each task is handled on its own, as by tasks/send, and the result is an array
+	// with, for each task in order, an object holding either the "task" or the JSON-RPC
+	// "error" tasks/send would have returned for it. It is an extension of this
+	// implementation.
+	MethodTasksSendBatch = "tasks/sendBatch"
+	// MethodTasksSendInput streams another message to a running task, see
+	// TaskInputParams, for input such as audio sent while the agent works. The
+	// result is the task. It is an extension of this implementation.
+	MethodTasksSendInput = "tasks/sendInput"
+	// MethodTasksGrantCredits and MethodTasksStreamEvent are the notifications of
+	// the credit-based flow control of streams over a long-lived connection, see
+	// GrantCreditsParams and StreamEventParams. They are extensions of this
+	// implementation.
+	MethodTasksGrantCredits = "tasks/grantCredits"
+	MethodTasksStreamEvent  = "tasks/streamEvent"
+	// MethodCapabilities returns the agent's Capabilities, a compact summary of its
+	// card and server features. It is an extension of this implementation.
+	MethodCapabilities = "a2a/capabilities"
 )
 
 // A2A SSE Event Types define the standard event type strings used in A2A SSE streams.
 const (
 	EventTaskStatusUpdate   = "task_status_update"
 	EventTaskArtifactUpdate = "task_artifact_update"
+	EventTaskArtifactPatch  = "task_artifact_patch"
+	EventTaskArtifactDelete = "task_artifact_delete"
+	EventTaskMessage        = "task_message"
 	// EventClose is used internally by this implementation's server to signal stream closure.
 	// Note: This might not be part of the formal A2A spec but is used in server logic.
 	EventClose = "close"
 )
 
+// HeaderIdempotencyKey is the HTTP header a client uses to mark retries of the same
+// tasks/send request, so the server returns the existing task instead of creating a new one.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderStreamEventFilter is the HTTP header a client uses to ask the server to only
+// stream events of the given StreamEventFilter type.
+const HeaderStreamEventFilter = "X-A2A-Event-Filter"
+
+// HeaderNotificationToken is the HTTP header carrying the push notification config
+// token on signed push notifications, whose Authorization header holds the signature.
+const HeaderNotificationToken = "X-A2A-Notification-Token"
+
+// StreamEventFilter selects which event types a streaming subscription delivers.
+type StreamEventFilter string
+
+// Stream event filters.
+const (
+	// StreamEventFilterAll delivers status, artifact and message events.
+	StreamEventFilterAll StreamEventFilter = "all"
+	// StreamEventFilterArtifact delivers only artifact events, including patches and deletes.
+	StreamEventFilterArtifact StreamEventFilter = "artifact"
+	// StreamEventFilterStatus delivers only status events.
+	StreamEventFilterStatus StreamEventFilter = "status"
+	// StreamEventFilterMessage delivers only intermediate message events.
+	StreamEventFilterMessage StreamEventFilter = "message"
+)
+
+// Allows reports whether the filter lets the event through.
+// Unknown or empty filters allow all events.
+func (f StreamEventFilter) Allows(event TaskEvent) bool {
+	switch event.(type) {
+	case TaskStatusUpdateEvent, *TaskStatusUpdateEvent:
+		return f != StreamEventFilterArtifact && f != StreamEventFilterMessage
+	case TaskArtifactUpdateEvent, *TaskArtifactUpdateEvent, TaskArtifactPatchEvent, *TaskArtifactPatchEvent,
+		TaskArtifactDeleteEvent, *TaskArtifactDeleteEvent:
+		return f != StreamEventFilterStatus && f != StreamEventFilterMessage
+	case TaskMessageEvent, *TaskMessageEvent:
+		return f != StreamEventFilterStatus && f != StreamEventFilterArtifact
+	default:
+		return true
+	}
+}
+
 // A2A HTTP Endpoint Paths define the standard paths used in the A2A protocol.
 const (
 	// AgentCardPath is the path for the agent metadata JSON endpoint.
@@ -42,6 +42,8 @@ func TestEventTypeConstants(t *testing.T) {
 		"EventTaskStatusUpdate should be 'task_status_update'")
 	assert.Equal(t, "task_artifact_update", protocol.EventTaskArtifactUpdate,
 		"EventTaskArtifactUpdate should be 'task_artifact_update'")
+	assert.Equal(t, "task_artifact_patch", protocol.EventTaskArtifactPatch,
+		"EventTaskArtifactPatch should be 'task_artifact_patch'")
 	assert.Equal(t, "close", protocol.EventClose, "EventClose should be 'close'")
 }
 
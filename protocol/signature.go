@@ -0,0 +1,91 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+)
+
+// Message signature errors.
+var (
+	// ErrMessageSignature is returned when a message signature fails verification.
+	ErrMessageSignature = errors.New("message signature verification failed")
+	// ErrMessageUnsigned is returned when a signed message was expected but the message has no signature.
+	ErrMessageUnsigned = errors.New("message is not signed")
+)
+
+// SignMessage signs the role and parts of message with key and stores the result
+// in message.Signature as a compact JWS with a detached payload, so the content is
+// not duplicated. Metadata is not covered and may be changed after signing.
+// key is an *rsa.PrivateKey (RS256), *ecdsa.PrivateKey (ES256/ES384/ES512 by curve)
+// or ed25519.PrivateKey (EdDSA).
+func SignMessage(message *Message, key interface{}) error {
+	alg, err := signatureAlgorithm(key)
+	if err != nil {
+		return err
+	}
+	payload, err := canonicalMessage(*message)
+	if err != nil {
+		return err
+	}
+	signed, err := jws.Sign(nil, jws.WithKey(alg, key), jws.WithDetachedPayload(payload))
+	if err != nil {
+		return fmt.Errorf("failed to sign message: %w", err)
+	}
+	signature := string(signed)
+	message.Signature = &signature
+	return nil
+}
+
+// VerifyMessage verifies the signature SignMessage stored on message against the
+// signer's public key. It returns an ErrMessageUnsigned error if the message has
+// no signature, and an error wrapping ErrMessageSignature if the signature does
+// not match its role and parts or was made with another key.
+func VerifyMessage(message Message, key interface{}) error {
+	if message.Signature == nil || *message.Signature == "" {
+		return ErrMessageUnsigned
+	}
+	alg, err := signatureAlgorithm(key)
+	if err != nil {
+		return err
+	}
+	payload, err := canonicalMessage(message)
+	if err != nil {
+		return err
+	}
+	if _, err := jws.Verify([]byte(*message.Signature), jws.WithKey(alg, key),
+		jws.WithDetachedPayload(payload)); err != nil {
+		return fmt.Errorf("%w: %v", ErrMessageSignature, err)
+	}
+	return nil
+}
+
+// canonicalMessage returns the canonical JSON encoding of the role and parts of
+// message. The encoding is decoded and encoded again so object keys are sorted,
+// making it independent of whether data parts hold structs or maps.
+func canonicalMessage(message Message) ([]byte, error) {
+	encoded, err := json.Marshal(struct {
+		Role  MessageRole `json:"role"`
+		Parts []Part      `json:"parts"`
+	}{Role: message.Role, Parts: message.Parts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return nil, fmt.Errorf("failed to canonicalize message: %w", err)
+	}
+	canonical, err := json.Marshal(generic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to canonicalize message: %w", err)
+	}
+	return canonical, nil
+}
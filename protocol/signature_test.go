@@ -0,0 +1,117 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignMessage(t *testing.T) {
+	newMessage := func() Message {
+		return NewMessage(MessageRoleUser, []Part{
+			NewTextPart("transfer 10 EUR"),
+			DataPart{Type: PartTypeData, Data: map[string]interface{}{"to": "alice", "amount": 10}},
+		})
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keys := []struct {
+		name string
+		priv interface{}
+		pub  interface{}
+	}{
+		{"RSA", rsaKey, &rsaKey.PublicKey},
+		{"ECDSA", ecKey, &ecKey.PublicKey},
+		{"Ed25519", edKey, edPub},
+	}
+	for _, k := range keys {
+		t.Run(k.name, func(t *testing.T) {
+			message := newMessage()
+			require.NoError(t, SignMessage(&message, k.priv))
+			require.NotNil(t, message.Signature)
+			assert.NoError(t, VerifyMessage(message, k.pub))
+		})
+	}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		// The receiver decodes data parts into maps with float64 numbers.
+		message := newMessage()
+		require.NoError(t, SignMessage(&message, ecKey))
+		encoded, err := json.Marshal(message)
+		require.NoError(t, err)
+		var decoded Message
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.NoError(t, VerifyMessage(decoded, &ecKey.PublicKey))
+	})
+
+	t.Run("StructData", func(t *testing.T) {
+		type transfer struct {
+			To     string `json:"to"`
+			Amount int    `json:"amount"`
+		}
+		message := NewMessage(MessageRoleUser, []Part{
+			NewTextPart("transfer 10 EUR"),
+			DataPart{Type: PartTypeData, Data: transfer{To: "alice", Amount: 10}},
+		})
+		require.NoError(t, SignMessage(&message, ecKey))
+		asMap := newMessage()
+		asMap.Signature = message.Signature
+		assert.NoError(t, VerifyMessage(asMap, &ecKey.PublicKey))
+	})
+
+	t.Run("MetadataNotCovered", func(t *testing.T) {
+		message := newMessage()
+		require.NoError(t, SignMessage(&message, ecKey))
+		message.Metadata = map[string]interface{}{"trace": "abc"}
+		assert.NoError(t, VerifyMessage(message, &ecKey.PublicKey))
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		message := newMessage()
+		require.NoError(t, SignMessage(&message, ecKey))
+		message.Parts[1] = DataPart{Type: PartTypeData, Data: map[string]interface{}{"to": "mallory", "amount": 10}}
+		assert.ErrorIs(t, VerifyMessage(message, &ecKey.PublicKey), ErrMessageSignature)
+
+		message = newMessage()
+		require.NoError(t, SignMessage(&message, ecKey))
+		message.Role = MessageRoleAgent
+		assert.ErrorIs(t, VerifyMessage(message, &ecKey.PublicKey), ErrMessageSignature)
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		message := newMessage()
+		require.NoError(t, SignMessage(&message, ecKey))
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		assert.ErrorIs(t, VerifyMessage(message, &otherKey.PublicKey), ErrMessageSignature)
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		assert.ErrorIs(t, VerifyMessage(newMessage(), &ecKey.PublicKey), ErrMessageUnsigned)
+	})
+
+	t.Run("UnsupportedKey", func(t *testing.T) {
+		message := newMessage()
+		assert.Error(t, SignMessage(&message, []byte("secret")))
+		assert.Nil(t, message.Signature)
+	})
+}
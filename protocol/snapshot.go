@@ -0,0 +1,82 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"fmt"
+	"time"
+)
+
+// TaskSnapshotVersion is the version of the TaskSnapshot format written by this
+// implementation. Snapshots of any version up to it can be imported.
+const TaskSnapshotVersion = 1
+
+// TaskSnapshot is a portable copy of a task, for migrating it to another server
+// or inspecting it offline. Task holds the status, artifacts, metadata and labels,
+// along with the full message history and lifecycle event log.
+type TaskSnapshot struct {
+	// Version is the snapshot format version, see TaskSnapshotVersion.
+	Version int `json:"version"`
+	// ExportedAt is when the snapshot was taken (RFC3339 format).
+	ExportedAt string `json:"exportedAt"`
+	// Task is the exported task.
+	Task Task `json:"task"`
+}
+
+// NewTaskSnapshot returns a snapshot of task in the current format.
+func NewTaskSnapshot(task Task) *TaskSnapshot {
+	return &TaskSnapshot{
+		Version:    TaskSnapshotVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Task:       task,
+	}
+}
+
+// ImportConflict selects what importing a snapshot does when a task with the same
+// ID already exists.
+type ImportConflict string
+
+// ImportConflict enum values.
+const (
+	// ImportConflictReject fails the import. It is the default.
+	ImportConflictReject ImportConflict = "reject"
+	// ImportConflictReplace replaces the existing task and all its state.
+	ImportConflictReplace ImportConflict = "replace"
+	// ImportConflictRename imports the task under a new, unused ID.
+	ImportConflictRename ImportConflict = "rename"
+)
+
+// TaskImportParams are the params of a tasks/import request.
+type TaskImportParams struct {
+	// Snapshot is the task to import.
+	Snapshot TaskSnapshot `json:"snapshot"`
+	// OnConflict is what to do if the task ID is taken; empty means ImportConflictReject.
+	OnConflict ImportConflict `json:"onConflict,omitempty"`
+}
+
+// Validate checks the params of a tasks/import request and returns a
+// *ValidationError listing every invalid field, or nil.
+func (p TaskImportParams) Validate() error {
+	errs := &ValidationError{}
+	switch version := p.Snapshot.Version; {
+	case version < 1:
+		errs.Add("/snapshot/version", "is required")
+	case version > TaskSnapshotVersion:
+		errs.Add("/snapshot/version", fmt.Sprintf("unsupported version %d, at most %d is supported",
+			version, TaskSnapshotVersion))
+	}
+	if p.Snapshot.Task.ID == "" {
+		errs.Add("/snapshot/task/id", "is required")
+	}
+	switch p.OnConflict {
+	case "", ImportConflictReject, ImportConflictReplace, ImportConflictRename:
+	default:
+		errs.Add("/onConflict", fmt.Sprintf("must be %q, %q or %q, got %q",
+			ImportConflictReject, ImportConflictReplace, ImportConflictRename, p.OnConflict))
+	}
+	return errs.Err()
+}
@@ -9,9 +9,18 @@
 package protocol
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonlimit"
 )
 
 // TaskState represents the lifecycle state of a task.
@@ -36,6 +45,45 @@ const (
 	TaskStateUnknown TaskState = "unknown"
 )
 
+// IsFinal reports whether the state is terminal (completed, failed or canceled).
+func (s TaskState) IsFinal() bool {
+	return s == TaskStateCompleted || s == TaskStateFailed || s == TaskStateCanceled
+}
+
+// CancelReason describes why a task was canceled.
+type CancelReason string
+
+// CancelReason constants define the possible reasons for canceling a task.
+const (
+	// CancelReasonUserRequested is used when a client asked for the task to be canceled.
+	CancelReasonUserRequested CancelReason = "user_requested"
+	// CancelReasonTimeout is used when the task exceeded its allotted time.
+	CancelReasonTimeout CancelReason = "timeout"
+	// CancelReasonServerShutdown is used when the server is shutting down.
+	CancelReasonServerShutdown CancelReason = "server_shutdown"
+	// CancelReasonSuperseded is used when the task was replaced by a newer one.
+	CancelReasonSuperseded CancelReason = "superseded"
+	// CancelReasonClientDisconnected is used when the client streaming the task went away.
+	CancelReasonClientDisconnected CancelReason = "client_disconnected"
+)
+
+// IsValid reports whether r is one of the known cancellation reasons.
+func (r CancelReason) IsValid() bool {
+	switch r {
+	case CancelReasonUserRequested, CancelReasonTimeout, CancelReasonServerShutdown, CancelReasonSuperseded,
+		CancelReasonClientDisconnected:
+		return true
+	}
+	return false
+}
+
+// IsClientRequestable reports whether a client may supply r in a tasks/cancel
+// request. Timeout, server shutdown and client disconnects are reserved for the
+// server itself.
+func (r CancelReason) IsClientRequestable() bool {
+	return r == CancelReasonUserRequested || r == CancelReasonSuperseded
+}
+
 // MessageRole indicates the originator of a message (user or agent).
 // See A2A Spec section on Messages.
 type MessageRole string
@@ -60,6 +108,8 @@ const (
 	PartTypeFile PartType = "file"
 	// PartTypeData is for raw binary data.
 	PartTypeData PartType = "data"
+	// PartTypeArtifactRef is for references to an artifact of an earlier task.
+	PartTypeArtifactRef PartType = "artifactRef"
 )
 
 // FileContent represents file data, either directly embedded or via URI.
@@ -104,10 +154,109 @@ type DataPart struct {
 	Type PartType `json:"type"`
 	// Data is the actual data payload.
 	Data interface{} `json:"data"`
+	// Encoding is the optional compression applied to Data on the wire and in storage.
+	// Data itself always holds the decoded value.
+	Encoding *string `json:"encoding,omitempty"`
+	// Metadata is the optional metadata.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ArtifactReferencePart points to an artifact the agent produced earlier, by task ID
+// and artifact index, so that a follow-up message can refer to it without uploading
+// its content again. The server resolves it for the TaskProcessor; see
+// taskmanager.ResolveArtifactReference.
+type ArtifactReferencePart struct {
+	// Type is the type of the part.
+	Type PartType `json:"type"`
+	// TaskID is the ID of the task that produced the artifact.
+	TaskID string `json:"taskId"`
+	// Index is the index of the artifact within the task.
+	Index int `json:"index"`
 	// Metadata is the optional metadata.
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// DataEncodingGzip marks a DataPart whose payload is gzip-compressed JSON, base64-encoded.
+const DataEncodingGzip = "gzip"
+
+// maxDecompressedDataSize limits the size of a decompressed DataPart payload.
+const maxDecompressedDataSize = 64 << 20
+
+// MarshalJSON implements custom marshalling logic for DataPart,
+// compressing the payload when an encoding is set.
+func (p DataPart) MarshalJSON() ([]byte, error) {
+	type Alias DataPart // Alias to avoid recursion.
+	alias := Alias(p)
+	if p.Encoding == nil || *p.Encoding == "" {
+		return json.Marshal(alias)
+	}
+	if *p.Encoding != DataEncodingGzip {
+		return nil, fmt.Errorf("unsupported data part encoding: %s", *p.Encoding)
+	}
+	raw, err := json.Marshal(p.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data part payload: %w", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, fmt.Errorf("failed to compress data part payload: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress data part payload: %w", err)
+	}
+	alias.Data = buf.Bytes() // Encoded as a base64 string.
+	return json.Marshal(alias)
+}
+
+// UnmarshalJSON implements custom unmarshalling logic for DataPart,
+// transparently decompressing encoded payloads.
+func (p *DataPart) UnmarshalJSON(data []byte) error {
+	type Alias DataPart // Alias to avoid recursion.
+	temp := &struct {
+		Data json.RawMessage `json:"data"`
+		*Alias
+	}{
+		Alias: (*Alias)(p),
+	}
+	if err := json.Unmarshal(data, temp); err != nil {
+		return err
+	}
+	p.Data = nil
+	// Check the encoding even without data, or the part could not be encoded again.
+	encoded := p.Encoding != nil && *p.Encoding != ""
+	if encoded && *p.Encoding != DataEncodingGzip {
+		return fmt.Errorf("unsupported data part encoding: %s", *p.Encoding)
+	}
+	if len(temp.Data) == 0 {
+		return nil
+	}
+	if !encoded {
+		return json.Unmarshal(temp.Data, &p.Data)
+	}
+	var compressed []byte
+	if err := json.Unmarshal(temp.Data, &compressed); err != nil {
+		return fmt.Errorf("failed to decode compressed data part payload: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("failed to decompress data part payload: %w", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(io.LimitReader(zr, maxDecompressedDataSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to decompress data part payload: %w", err)
+	}
+	if len(raw) > maxDecompressedDataSize {
+		return fmt.Errorf("decompressed data part payload exceeds %d bytes", maxDecompressedDataSize)
+	}
+	// The outer request's depth limit never saw the compressed payload.
+	if err := jsonlimit.CheckDepth(raw, jsonlimit.DefaultMaxDepth); err != nil {
+		return fmt.Errorf("decompressed data part payload: %w", err)
+	}
+	return json.Unmarshal(raw, &p.Data)
+}
+
 // AuthenticationInfo represents authentication details for external services.
 type AuthenticationInfo struct {
 	// Schemes is a list of authentication schemes supported.
@@ -178,6 +327,27 @@ type PushNotificationConfig struct {
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// PushNotificationParams are the params of a tasks/notifyEvent push notification.
+//
+// A notification may be delivered more than once: the sender retries deliveries
+// that failed, including ones the receiver handled but whose reply was lost. Every
+// attempt to deliver the same event carries the same DeliveryID, so a receiver
+// should ignore a DeliveryID it has already handled. Sequence numbers the events
+// delivered for a task to a webhook from 1 upwards, so a receiver that sees a
+// number skipped knows that an event was lost.
+type PushNotificationParams struct {
+	// ID is the task identifier.
+	ID string `json:"id"`
+	// EventType is the type of Event, such as EventTaskStatusUpdate.
+	EventType string `json:"eventType"`
+	// Event is the task event.
+	Event json.RawMessage `json:"event"`
+	// DeliveryID identifies the event being delivered, unchanged across retries.
+	DeliveryID string `json:"deliveryId,omitempty"`
+	// Sequence is the number of the event among those delivered for the task.
+	Sequence int64 `json:"sequence,omitempty"`
+}
+
 // TaskPushNotificationConfig associates a task ID with push notification settings.
 type TaskPushNotificationConfig struct {
 	// ID is the unique task identifier.
@@ -195,19 +365,46 @@ type Part interface {
 }
 
 // partMarker implementations for concrete types (unexported methods).
-func (TextPart) partMarker() {}
-func (FilePart) partMarker() {}
-func (DataPart) partMarker() {}
+func (TextPart) partMarker()              {}
+func (FilePart) partMarker()              {}
+func (DataPart) partMarker()              {}
+func (ArtifactReferencePart) partMarker() {}
+
+// MetadataKeyInputSchema is the Message metadata key under which an agent moving a
+// task to TaskStateInputRequired may give the JSON Schema that the data of the reply
+// must match.
+const MetadataKeyInputSchema = "inputSchema"
+
+// MetadataKeySkill is the Message metadata key naming the ID of the agent skill,
+// see the agent card, a message is for. Servers using a skill router set it on
+// messages sent without one.
+const MetadataKeySkill = "skillId"
+
+// MetadataKeyInlineContent is the Artifact metadata key that, set to true, lets
+// the artifact content endpoint of a server serve the content for display in the
+// browser rather than as a download. The content stays sandboxed either way.
+const MetadataKeyInlineContent = "inlineContent"
+
+// SkillID returns the skill the message is for, under MetadataKeySkill, or "" if
+// it names none.
+func SkillID(message Message) string {
+	skillID, _ := message.Metadata[MetadataKeySkill].(string)
+	return skillID
+}
 
 // Message represents a single exchange between a user and an agent.
 // See A2A Spec section on Messages.
 type Message struct {
 	// Role is the sender of the message.
 	Role MessageRole `json:"role"`
-	// Parts is the content parts (must implement Part).
+	// Parts is the content parts (must implement Part). Their order, e.g. text
+	// interleaved with images, is kept through serialization and streaming.
 	Parts []Part `json:"parts"`
 	// Metadata is the optional metadata.
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Signature is the optional sender signature of the role and parts, a compact
+	// JWS with a detached payload; see SignMessage and VerifyMessage.
+	Signature *string `json:"signature,omitempty"`
 }
 
 // UnmarshalJSON implements custom unmarshalling logic for Message
@@ -242,7 +439,8 @@ type Artifact struct {
 	Name *string `json:"name,omitempty"`
 	// Description is the description of the artifact.
 	Description *string `json:"description,omitempty"`
-	// Parts is the content parts of the artifact.
+	// Parts is the content parts of the artifact, kept in order like those of a
+	// Message. The parts of appended chunks follow those of the earlier chunks.
 	Parts []Part `json:"parts"`
 	// Index is the index for ordering streamed artifacts.
 	Index int `json:"index"`
@@ -250,6 +448,11 @@ type Artifact struct {
 	Append *bool `json:"append,omitempty"`
 	// LastChunk is a flag indicating if this is the final chunk of an artifact stream.
 	LastChunk *bool `json:"lastChunk,omitempty"`
+	// Checksum is the optional hex-encoded SHA-256 digest of the artifact content,
+	// allowing clients to verify content downloaded from a file URI.
+	// The TaskProcessor must set it for URI artifacts; the task managers only fill it in
+	// for complete, single-part artifacts with inline bytes.
+	Checksum *string `json:"checksum,omitempty"`
 	// Metadata is optional metadata for the artifact.
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -279,6 +482,18 @@ func (a *Artifact) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// maxErrorDataLen limits how much of a malformed payload is quoted in an error.
+const maxErrorDataLen = 128
+
+// truncateData returns data as a string of at most maxErrorDataLen bytes, so
+// errors echoing client input stay small.
+func truncateData(data []byte) string {
+	if len(data) <= maxErrorDataLen {
+		return string(data)
+	}
+	return fmt.Sprintf("%s... (%d bytes)", data[:maxErrorDataLen], len(data))
+}
+
 // unmarshalPart determines the concrete type of a Part from raw JSON
 // based on the "type" field and unmarshals into that concrete type.
 // Internal helper function.
@@ -288,7 +503,7 @@ func unmarshalPart(rawPart json.RawMessage) (Part, error) {
 		Type PartType `json:"type"`
 	}
 	if err := json.Unmarshal(rawPart, &typeDetect); err != nil {
-		return nil, fmt.Errorf("cannot detect part type: %w. Data: %s", err, string(rawPart))
+		return nil, fmt.Errorf("cannot detect part type: %w. Data: %s", err, truncateData(rawPart))
 	}
 	// Unmarshal into the correct concrete type.
 	switch typeDetect.Type {
@@ -310,6 +525,12 @@ func unmarshalPart(rawPart json.RawMessage) (Part, error) {
 			return nil, fmt.Errorf("failed to unmarshal DataPart: %w", err)
 		}
 		return p, nil
+	case PartTypeArtifactRef:
+		var p ArtifactReferencePart
+		if err := json.Unmarshal(rawPart, &p); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ArtifactReferencePart: %w", err)
+		}
+		return p, nil
 	default:
 		// If we need to handle unknown part types gracefully (e.g., store raw JSON),
 		// we would add that logic here. For now, treat as an error.
@@ -326,6 +547,64 @@ type TaskStatus struct {
 	Message *Message `json:"message,omitempty"`
 	// Timestamp is the ISO 8601 timestamp of the status change.
 	Timestamp string `json:"timestamp"`
+	// CancelReason is set when State is canceled and describes why.
+	CancelReason *CancelReason `json:"cancelReason,omitempty"`
+	// EstimatedCompletion is the optional ISO 8601 time the agent expects a
+	// working task to finish. It is cleared when the task leaves the working state.
+	EstimatedCompletion string `json:"estimatedCompletion,omitempty"`
+	// Error is set when State is failed and the agent reported why.
+	Error *TaskError `json:"error,omitempty"`
+}
+
+// TaskError describes why a task failed, so clients can tell whether sending the
+// task again may help.
+type TaskError struct {
+	// Code is an optional machine-readable code for the failure.
+	Code string `json:"code,omitempty"`
+	// Message describes the failure.
+	Message string `json:"message"`
+	// Retryable reports whether the failure is transient, so the task may succeed
+	// if sent again.
+	Retryable bool `json:"retryable"`
+}
+
+// EstimatedCompletionTime returns the parsed EstimatedCompletion, and false if
+// the status carries no valid estimate.
+func (s TaskStatus) EstimatedCompletionTime() (time.Time, bool) {
+	if s.EstimatedCompletion == "" {
+		return time.Time{}, false
+	}
+	eta, err := ParseTime(s.EstimatedCompletion)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return eta, true
+}
+
+// epochMillisThreshold tells epoch milliseconds from epoch seconds in ParseTime:
+// larger numbers are milliseconds, which puts seconds past the year 5000 out of
+// reach.
+const epochMillisThreshold = 1e11
+
+// ParseTime parses a timestamp of a task or event leniently, whatever format the
+// agent sends timestamps in: RFC 3339 with or without fractional seconds, RFC 3339
+// without a time zone, taken as UTC, or a decimal number of seconds or
+// milliseconds since the Unix epoch.
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if epoch > epochMillisThreshold || epoch < -epochMillisThreshold {
+			return time.UnixMilli(epoch).UTC(), nil
+		}
+		return time.Unix(epoch, 0).UTC(), nil
+	}
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return parsed, nil
+	}
+	if parsed, err := time.Parse("2006-01-02T15:04:05.999999999", value); err == nil {
+		return parsed, nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamp %q", value)
 }
 
 // Task represents a unit of work being processed by the agent.
@@ -343,6 +622,75 @@ type Task struct {
 	History []Message `json:"history,omitempty"`
 	// Metadata is the optional metadata associated with the task.
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Labels are optional key/value pairs used to group and select tasks.
+	Labels map[string]string `json:"labels,omitempty"`
+	// DependsOn lists the IDs of the tasks that had to complete before this one ran.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// RequestID is the ID of the JSON-RPC request that created the task, for
+	// correlating the logs of the client and the server.
+	RequestID string `json:"requestId,omitempty"`
+	// Events is the task's lifecycle event log, included only when requested
+	// with TaskQueryParams.IncludeEvents.
+	Events []TaskLifecycleEvent `json:"events,omitempty"`
+}
+
+// TaskLifecycleEventType identifies an entry in a task's lifecycle event log.
+type TaskLifecycleEventType string
+
+// TaskLifecycleEventType enum values.
+const (
+	// TaskLifecycleReceived records a send request for the task.
+	TaskLifecycleReceived TaskLifecycleEventType = "received"
+	// TaskLifecycleProcessingStarted records the processor being invoked.
+	TaskLifecycleProcessingStarted TaskLifecycleEventType = "processing_started"
+	// TaskLifecycleStatusChanged records a new task status.
+	TaskLifecycleStatusChanged TaskLifecycleEventType = "status_changed"
+	// TaskLifecycleError records an error returned by the processor.
+	TaskLifecycleError TaskLifecycleEventType = "error"
+)
+
+// TaskLifecycleEvent is an entry in a task's lifecycle event log. The log records
+// what the server did with the task for debugging, separately from the message history.
+type TaskLifecycleEvent struct {
+	// Type is the kind of event.
+	Type TaskLifecycleEventType `json:"type"`
+	// Timestamp is when the event happened (RFC3339Nano format).
+	Timestamp string `json:"timestamp"`
+	// State is the new task state of a status_changed event.
+	State TaskState `json:"state,omitempty"`
+	// Detail is optional human-readable context, such as an error message.
+	Detail string `json:"detail,omitempty"`
+}
+
+// NewTaskLifecycleEvent creates a TaskLifecycleEvent stamped with the current time.
+func NewTaskLifecycleEvent(eventType TaskLifecycleEventType, state TaskState, detail string) TaskLifecycleEvent {
+	return TaskLifecycleEvent{
+		Type:      eventType,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		State:     state,
+		Detail:    detail,
+	}
+}
+
+// MatchesLabels reports whether the task carries every key/value pair in the selector.
+// An empty selector matches all tasks.
+func (t *Task) MatchesLabels(selector map[string]string) bool {
+	for k, v := range selector {
+		if label, ok := t.Labels[k]; !ok || label != v {
+			return false
+		}
+	}
+	return true
+}
+
+// FinishedBefore reports whether the task is in a final state whose status was
+// last updated before the given time. Tasks without a parseable timestamp never are.
+func (t *Task) FinishedBefore(before time.Time) bool {
+	if !t.Status.State.IsFinal() {
+		return false
+	}
+	updated, err := ParseTime(t.Status.Timestamp)
+	return err == nil && updated.Before(before)
 }
 
 // TaskEvent is an interface for events published during task execution (streaming).
@@ -353,6 +701,9 @@ type TaskEvent interface {
 	eventMarker() // Internal marker method.
 	// IsFinal returns true if this is the final event for the task.
 	IsFinal() bool
+	// TaskID returns the ID of the task the event belongs to, which tells the
+	// tasks of a multiplexed stream apart.
+	TaskID() string
 }
 
 // TaskStatusUpdateEvent indicates a change in the task's lifecycle state.
@@ -371,6 +722,11 @@ type TaskStatusUpdateEvent struct {
 // eventMarker implementation (unexported method).
 func (TaskStatusUpdateEvent) eventMarker() {}
 
+// TaskID implements TaskEvent.
+func (e TaskStatusUpdateEvent) TaskID() string {
+	return e.ID
+}
+
 // IsFinal implements TaskEvent.
 func (e TaskStatusUpdateEvent) IsFinal() bool {
 	return e.Final
@@ -392,11 +748,135 @@ type TaskArtifactUpdateEvent struct {
 // eventMarker implementation (unexported method).
 func (TaskArtifactUpdateEvent) eventMarker() {}
 
+// TaskID implements TaskEvent.
+func (e TaskArtifactUpdateEvent) TaskID() string {
+	return e.ID
+}
+
 // IsFinal implements TaskEvent.
 func (e TaskArtifactUpdateEvent) IsFinal() bool {
 	return e.Final
 }
 
+// TaskArtifactPatchEvent carries JSON Patch (RFC 6902) operations against the data
+// of a DataPart in an artifact streamed earlier, so agents can send granular changes
+// instead of the whole structure again. Clients apply the operations in order to
+// their copy of the artifact, e.g. with client.ArtifactAggregator.
+// Corresponds to the 'task_artifact_patch' event.
+type TaskArtifactPatchEvent struct {
+	// ID is the ID of the task.
+	ID string `json:"id"`
+	// Index is the index of the patched artifact.
+	Index int `json:"index"`
+	// Part is the position of the patched DataPart within the artifact's parts,
+	// counted through the parts of all its chunks for an artifact streamed in chunks.
+	Part int `json:"part"`
+	// Patch is the operations to apply to the part's data.
+	Patch []PatchOperation `json:"patch"`
+	// Metadata is optional metadata.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// eventMarker implementation (unexported method).
+func (TaskArtifactPatchEvent) eventMarker() {}
+
+// TaskID implements TaskEvent.
+func (e TaskArtifactPatchEvent) TaskID() string {
+	return e.ID
+}
+
+// IsFinal implements TaskEvent. Patch events never end a stream.
+func (e TaskArtifactPatchEvent) IsFinal() bool {
+	return false
+}
+
+// TaskArtifactDeleteEvent retracts the artifact with the given index streamed
+// earlier, such as a draft the agent no longer stands by. Clients drop their copy
+// of the artifact; a delete for an index they do not hold is ignored.
+// To replace an artifact instead, agents send a TaskArtifactUpdateEvent for the
+// same index without Append set.
+// Corresponds to the 'task_artifact_delete' event.
+type TaskArtifactDeleteEvent struct {
+	// ID is the ID of the task.
+	ID string `json:"id"`
+	// Index is the index of the deleted artifact.
+	Index int `json:"index"`
+	// Metadata is optional metadata.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// eventMarker implementation (unexported method).
+func (TaskArtifactDeleteEvent) eventMarker() {}
+
+// TaskID implements TaskEvent.
+func (e TaskArtifactDeleteEvent) TaskID() string {
+	return e.ID
+}
+
+// IsFinal implements TaskEvent. Delete events never end a stream.
+func (e TaskArtifactDeleteEvent) IsFinal() bool {
+	return false
+}
+
+// TaskMessageEvent carries an intermediate message sent by the agent while the
+// task is still running, such as a progress note or a clarifying remark.
+// Unlike a status update it does not change the task's state.
+// Corresponds to the 'task_message' event.
+type TaskMessageEvent struct {
+	// ID is the ID of the task.
+	ID string `json:"id"`
+	// Message is the intermediate message.
+	Message Message `json:"message"`
+	// Metadata is optional metadata.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// eventMarker implementation (unexported method).
+func (TaskMessageEvent) eventMarker() {}
+
+// TaskID implements TaskEvent.
+func (e TaskMessageEvent) TaskID() string {
+	return e.ID
+}
+
+// IsFinal implements TaskEvent. Message events never end a stream.
+func (e TaskMessageEvent) IsFinal() bool {
+	return false
+}
+
+// TaskStreamErrorEvent is delivered by the client as the last event of a stream that
+// ended abnormally, e.g. after an idle timeout, so callers can tell a dead stream
+// from a finished one. It is never sent by servers.
+type TaskStreamErrorEvent struct {
+	// ID is the ID of the task.
+	ID string `json:"id"`
+	// Err is why the stream ended.
+	Err error `json:"-"`
+}
+
+// eventMarker implementation (unexported method).
+func (TaskStreamErrorEvent) eventMarker() {}
+
+// TaskID implements TaskEvent.
+func (e TaskStreamErrorEvent) TaskID() string {
+	return e.ID
+}
+
+// IsFinal implements TaskEvent. No events follow a stream error.
+func (e TaskStreamErrorEvent) IsFinal() bool {
+	return true
+}
+
+// Error implements error.
+func (e TaskStreamErrorEvent) Error() string {
+	return fmt.Sprintf("stream for task %s failed: %v", e.ID, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e TaskStreamErrorEvent) Unwrap() error {
+	return e.Err
+}
+
 // SendTaskParams defines the parameters for the tasks_send and tasks_sendSubscribe RPC methods.
 // See A2A Spec section on RPC Methods.
 type SendTaskParams struct {
@@ -406,10 +886,29 @@ type SendTaskParams struct {
 	SessionID *string `json:"sessionId,omitempty"`
 	// Message is the user's message initiating the task.
 	Message Message `json:"message"`
+	// Messages is an optional ordered list of prior messages (e.g. system prompts or examples)
+	// used to seed the task history. They are stored before Message.
+	Messages []Message `json:"messages,omitempty"`
 	// HistoryLength is the requested history length in response.
 	HistoryLength *int `json:"historyLength,omitempty"`
 	// Metadata is the optional metadata.
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Labels are optional key/value pairs applied to the task when it is created.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Locale is the optional BCP 47 language tag the agent should respond in.
+	Locale *string `json:"locale,omitempty"`
+	// DependsOn lists the IDs of existing tasks that must complete before this one
+	// runs. Until then the task stays submitted; it fails if any of them fails or
+	// is canceled.
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// InitialMessages returns the seed Messages followed by Message, in the order
+// they should be stored in the task history.
+func (p SendTaskParams) InitialMessages() []Message {
+	messages := make([]Message, 0, len(p.Messages)+1)
+	messages = append(messages, p.Messages...)
+	return append(messages, p.Message)
 }
 
 // TaskQueryParams defines the parameters for the tasks_get RPC method.
@@ -419,6 +918,18 @@ type TaskQueryParams struct {
 	ID string `json:"id"`
 	// HistoryLength is the requested message history length.
 	HistoryLength *int `json:"historyLength,omitempty"`
+	// IncludeEvents requests the task's lifecycle event log in Task.Events.
+	IncludeEvents bool `json:"includeEvents,omitempty"`
+	// WaitMs asks the agent to hold the request for up to this many milliseconds
+	// until the task's status changes, then return the task (long polling).
+	// Zero returns immediately. Agents may wait less than requested.
+	WaitMs int `json:"waitMs,omitempty"`
+}
+
+// TaskIDsParams defines the parameters for tasks/subscribeMultiple.
+type TaskIDsParams struct {
+	// IDs are the IDs of the tasks to subscribe to.
+	IDs []string `json:"ids"`
 }
 
 // TaskIDParams defines parameters for methods needing only a task ID (e.g., tasks_cancel).
@@ -426,6 +937,18 @@ type TaskQueryParams struct {
 type TaskIDParams struct {
 	// ID is the ID of the task.
 	ID string `json:"id"`
+	// Reason is the optional reason for a tasks/cancel request. Clients may only
+	// send user_requested or superseded; it defaults to user_requested when empty.
+	Reason CancelReason `json:"reason,omitempty"`
+}
+
+// CancelReason returns the cancellation reason for the request,
+// defaulting to CancelReasonUserRequested when none is set.
+func (p TaskIDParams) CancelReason() CancelReason {
+	if p.Reason == "" {
+		return CancelReasonUserRequested
+	}
+	return p.Reason
 }
 
 // --- Factory Functions ---
@@ -451,6 +974,24 @@ func NewMessage(role MessageRole, parts []Part) Message {
 	}
 }
 
+// ComputeChecksum returns the hex-encoded SHA-256 digest of content,
+// in the format used by Artifact.Checksum.
+func ComputeChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// NewCompressedDataPart creates a new DataPart whose payload is gzip-compressed
+// when marshaled and decompressed again when unmarshaled.
+func NewCompressedDataPart(data interface{}) DataPart {
+	encoding := DataEncodingGzip
+	return DataPart{
+		Type:     PartTypeData,
+		Data:     data,
+		Encoding: &encoding,
+	}
+}
+
 // NewTextPart creates a new TextPart containing the given text.
 func NewTextPart(text string) TextPart {
 	return TextPart{
@@ -458,3 +999,13 @@ func NewTextPart(text string) TextPart {
 		Text: text,
 	}
 }
+
+// NewArtifactReference creates an ArtifactReferencePart pointing to the artifact
+// with the given index of the task taskID.
+func NewArtifactReference(taskID string, index int) ArtifactReferencePart {
+	return ArtifactReferencePart{
+		Type:   PartTypeArtifactRef,
+		TaskID: taskID,
+		Index:  index,
+	}
+}
@@ -7,11 +7,17 @@
 package protocol
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonlimit"
 )
 
 // Helper function to get a pointer to a boolean.
@@ -36,6 +42,12 @@ func TestPartConcreteType_MarshalJSON(t *testing.T) {
 		assert.JSONEq(t, `{"type":"data","data":{"a":1}}`, string(jsonData))
 	})
 
+	t.Run("ArtifactReferencePart", func(t *testing.T) {
+		jsonData, err := json.Marshal(NewArtifactReference("task-1", 2))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type":"artifactRef","taskId":"task-1","index":2}`, string(jsonData))
+	})
+
 	// FilePart marshalling test can be added if needed
 }
 
@@ -47,7 +59,8 @@ func TestPartIfaceUnmarshalJSONCont(t *testing.T) {
 		"parts": [
 			{"type":"text", "text":"part1"},
 			{"type":"data", "data":{"val": true}},
-			{"type":"text", "text":"part3"}
+			{"type":"text", "text":"part3"},
+			{"type":"artifactRef", "taskId":"task-1", "index":2}
 		]
 	}`
 
@@ -55,7 +68,7 @@ func TestPartIfaceUnmarshalJSONCont(t *testing.T) {
 	err := json.Unmarshal([]byte(jsonData), &msg)
 	require.NoError(t, err, "Unmarshal into Message should succeed via custom UnmarshalJSON")
 
-	require.Len(t, msg.Parts, 3)
+	require.Len(t, msg.Parts, 4)
 	require.IsType(t, TextPart{}, msg.Parts[0])
 	assert.Equal(t, "part1", msg.Parts[0].(TextPart).Text)
 
@@ -64,6 +77,42 @@ func TestPartIfaceUnmarshalJSONCont(t *testing.T) {
 
 	require.IsType(t, TextPart{}, msg.Parts[2])
 	assert.Equal(t, "part3", msg.Parts[2].(TextPart).Text)
+
+	assert.Equal(t, NewArtifactReference("task-1", 2), msg.Parts[3])
+}
+
+func TestMessage_InterleavedPartsKeepOrder(t *testing.T) {
+	name, mimeType, content := "chart.png", "image/png", "iVBORw0KGgo="
+	original := NewMessage(MessageRoleAgent, []Part{
+		NewTextPart("Here is the chart:"),
+		FilePart{Type: PartTypeFile, File: FileContent{Name: &name, MimeType: &mimeType, Bytes: &content}},
+		NewTextPart("and the numbers behind it:"),
+		DataPart{Type: PartTypeData, Data: map[string]interface{}{"total": float64(42)}},
+		NewTextPart("That is all."),
+	})
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		var decoded Message
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, original.Parts, decoded.Parts)
+
+		// Encoding again gives the same document.
+		again, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(data), string(again))
+	})
+
+	t.Run("RawData", func(t *testing.T) {
+		var decoded Message
+		require.NoError(t, UnmarshalMessageRawData(data, 1, &decoded))
+		require.Len(t, decoded.Parts, len(original.Parts))
+		for i, part := range decoded.Parts {
+			assert.IsType(t, original.Parts[i], part, "part %d", i)
+		}
+		assert.Equal(t, original.Parts[4], decoded.Parts[4])
+	})
 }
 
 // Removed TestArtifactPart_MarshalUnmarshalJSON as ArtifactPart type doesn't exist
@@ -121,3 +170,189 @@ func TestTaskEvent_IsFinal(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskState_IsFinal(t *testing.T) {
+	assert.True(t, TaskStateCompleted.IsFinal())
+	assert.True(t, TaskStateFailed.IsFinal())
+	assert.True(t, TaskStateCanceled.IsFinal())
+	assert.False(t, TaskStateWorking.IsFinal())
+	assert.False(t, TaskStateSubmitted.IsFinal())
+	assert.False(t, TaskStateInputRequired.IsFinal())
+	assert.False(t, TaskState("other").IsFinal())
+}
+
+func TestTask_MatchesLabels(t *testing.T) {
+	task := Task{ID: "labelled", Labels: map[string]string{"customer": "acme", "env": "prod"}}
+
+	assert.True(t, task.MatchesLabels(nil))
+	assert.True(t, task.MatchesLabels(map[string]string{"customer": "acme"}))
+	assert.True(t, task.MatchesLabels(map[string]string{"customer": "acme", "env": "prod"}))
+	assert.False(t, task.MatchesLabels(map[string]string{"customer": "globex"}))
+	assert.False(t, task.MatchesLabels(map[string]string{"customer": "acme", "region": "eu"}))
+
+	unlabelled := Task{ID: "plain"}
+	assert.True(t, unlabelled.MatchesLabels(map[string]string{}))
+	assert.False(t, unlabelled.MatchesLabels(map[string]string{"env": "prod"}))
+}
+
+func TestSendTaskParams_InitialMessages(t *testing.T) {
+	message := NewMessage(MessageRoleUser, []Part{NewTextPart("question")})
+	single := SendTaskParams{ID: "single", Message: message}
+	assert.Equal(t, []Message{message}, single.InitialMessages())
+
+	seed := []Message{
+		NewMessage(MessageRoleUser, []Part{NewTextPart("system")}),
+		NewMessage(MessageRoleAgent, []Part{NewTextPart("example")}),
+	}
+	seeded := SendTaskParams{ID: "seeded", Message: message, Messages: seed}
+	assert.Equal(t, []Message{seed[0], seed[1], message}, seeded.InitialMessages())
+	assert.Len(t, seeded.Messages, 2, "InitialMessages must not modify the seed slice")
+}
+
+func TestDataPart_Compression(t *testing.T) {
+	// Build a large, repetitive JSON payload.
+	rows := make([]interface{}, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		rows = append(rows, map[string]interface{}{
+			"id":     float64(i),
+			"name":   "row name repeated for compression",
+			"active": i%2 == 0,
+		})
+	}
+	payload := map[string]interface{}{"rows": rows}
+
+	roundTrip := func(t *testing.T, part DataPart) ([]byte, DataPart) {
+		msg := NewMessage(MessageRoleAgent, []Part{part})
+		encoded, err := json.Marshal(msg)
+		require.NoError(t, err)
+		var decoded Message
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		require.Len(t, decoded.Parts, 1)
+		decodedPart, ok := decoded.Parts[0].(DataPart)
+		require.True(t, ok)
+		return encoded, decodedPart
+	}
+
+	plainJSON, plainPart := roundTrip(t, DataPart{Type: PartTypeData, Data: payload})
+	assert.Equal(t, payload, plainPart.Data)
+	assert.Nil(t, plainPart.Encoding)
+
+	compressedJSON, compressedPart := roundTrip(t, NewCompressedDataPart(payload))
+	assert.Equal(t, payload, compressedPart.Data, "payload should be decompressed transparently")
+	require.NotNil(t, compressedPart.Encoding)
+	assert.Equal(t, DataEncodingGzip, *compressedPart.Encoding)
+	assert.Contains(t, string(compressedJSON), `"encoding":"gzip"`)
+	assert.Less(t, len(compressedJSON), len(plainJSON)/4, "compressed payload should be much smaller")
+
+	// Re-encoding a decoded part keeps it compressed, so it survives storage.
+	reencoded, err := json.Marshal(compressedPart)
+	require.NoError(t, err)
+	assert.Contains(t, string(reencoded), `"encoding":"gzip"`)
+	assert.NotContains(t, string(reencoded), "row name repeated")
+
+	t.Run("UnsupportedEncoding", func(t *testing.T) {
+		encoding := "brotli"
+		_, err := json.Marshal(DataPart{Type: PartTypeData, Data: payload, Encoding: &encoding})
+		assert.Error(t, err)
+
+		var part DataPart
+		err = json.Unmarshal([]byte(`{"type":"data","encoding":"brotli","data":"AAAA"}`), &part)
+		assert.Error(t, err)
+	})
+
+	t.Run("CorruptPayload", func(t *testing.T) {
+		var part DataPart
+		err := json.Unmarshal([]byte(`{"type":"data","encoding":"gzip","data":"bm90IGd6aXA="}`), &part)
+		assert.Error(t, err)
+	})
+
+	t.Run("DeeplyNestedPayload", func(t *testing.T) {
+		// Compression hides nesting from the request's depth check, so it is checked again.
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write([]byte(strings.Repeat("[", 1000) + strings.Repeat("]", 1000)))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		compressed, err := json.Marshal(buf.Bytes())
+		require.NoError(t, err)
+
+		var part DataPart
+		err = json.Unmarshal([]byte(`{"type":"data","encoding":"gzip","data":`+string(compressed)+`}`), &part)
+		assert.ErrorIs(t, err, jsonlimit.ErrTooDeep)
+	})
+}
+
+func TestUnmarshalPart_TruncatesErrorData(t *testing.T) {
+	var msg Message
+	raw := `{"role":"user","parts":["` + strings.Repeat("x", 10000) + `"]}`
+	err := json.Unmarshal([]byte(raw), &msg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "(10002 bytes)")
+	assert.Less(t, len(err.Error()), 512)
+}
+
+// FuzzMessageUnmarshal checks that decoding arbitrary messages, artifacts and
+// tasks never panics, and that whatever decodes can be encoded again.
+func FuzzMessageUnmarshal(f *testing.F) {
+	for _, seed := range []string{
+		`{"role":"user","parts":[{"type":"text","text":"hi"}]}`,
+		`{"role":"agent","parts":[{"type":"file","file":{"name":"a","bytes":"AA=="}}]}`,
+		`{"role":"agent","parts":[{"type":"data","data":{"a":[1,{"b":null}]}}]}`,
+		`{"role":"agent","parts":[{"type":"data","encoding":"gzip","data":"H4sIAAAAAAAA/4qOBQQAAP//Q7+mFgIAAAA="}]}`,
+		`{"role":"user","parts":[{"type":"text"`,
+		`{"role":"user","parts":[null,1,"x",[],{}]}`,
+		`{"index":0,"parts":[{"type":"text","text":"x"}],"append":true,"lastChunk":false}`,
+		`{"id":"t","status":{"state":"working","message":{"role":"agent","parts":[]}},"artifacts":[{"parts":null}]}`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var msg Message
+		if json.Unmarshal(data, &msg) == nil {
+			if _, err := json.Marshal(msg); err != nil {
+				t.Fatalf("decoded message failed to encode: %v", err)
+			}
+		}
+		var artifact Artifact
+		if json.Unmarshal(data, &artifact) == nil {
+			if _, err := json.Marshal(artifact); err != nil {
+				t.Fatalf("decoded artifact failed to encode: %v", err)
+			}
+		}
+		var task Task
+		if json.Unmarshal(data, &task) == nil {
+			if _, err := json.Marshal(task); err != nil {
+				t.Fatalf("decoded task failed to encode: %v", err)
+			}
+		}
+	})
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	for name, value := range map[string]string{
+		"RFC3339":      "2025-03-04T05:06:07Z",
+		"Offset":       "2025-03-04T06:06:07+01:00",
+		"Nanos":        "2025-03-04T05:06:07.000000000Z",
+		"NoZone":       "2025-03-04T05:06:07",
+		"EpochSeconds": "1741064767",
+		"EpochMillis":  "1741064767000",
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseTime(value)
+			require.NoError(t, err)
+			assert.True(t, want.Equal(got), "got %v", got)
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := ParseTime("yesterday")
+		assert.Error(t, err)
+	})
+
+	t.Run("EstimatedCompletionInEpochMillis", func(t *testing.T) {
+		eta, ok := TaskStatus{EstimatedCompletion: "1741064767000"}.EstimatedCompletionTime()
+		require.True(t, ok)
+		assert.True(t, want.Equal(eta))
+	})
+}
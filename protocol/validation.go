@@ -0,0 +1,116 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes one invalid field of a request.
+type FieldError struct {
+	// Path is the JSON pointer (RFC 6901) of the field within the request params.
+	Path string `json:"path"`
+	// Reason describes what is wrong with the field.
+	Reason string `json:"reason"`
+}
+
+// ValidationError lists every invalid field found in a request, rather than
+// just the first. Servers send it as the data of an invalid params error, and
+// the client returns it so callers can retrieve it with errors.As.
+type ValidationError struct {
+	// Fields are the invalid fields, in the order they were checked.
+	Fields []FieldError `json:"fields"`
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		reasons[i] = field.Path + ": " + field.Reason
+	}
+	return "invalid request: " + strings.Join(reasons, "; ")
+}
+
+// Add records that the field at path is invalid for reason.
+func (e *ValidationError) Add(path, reason string) {
+	e.Fields = append(e.Fields, FieldError{Path: path, Reason: reason})
+}
+
+// Err returns e if it holds any field errors, or nil otherwise.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate checks the params of a tasks/send or tasks/sendSubscribe request and
+// returns a *ValidationError listing every invalid field, or nil.
+func (p SendTaskParams) Validate() error {
+	errs := &ValidationError{}
+	if p.ID == "" {
+		errs.Add("/id", "is required")
+	}
+	validateMessage(errs, "/message", p.Message)
+	for i, message := range p.Messages {
+		validateMessage(errs, fmt.Sprintf("/messages/%d", i), message)
+	}
+	if p.HistoryLength != nil && *p.HistoryLength < 0 {
+		errs.Add("/historyLength", "must not be negative")
+	}
+	if p.Locale != nil && *p.Locale != "" {
+		if err := ValidateLocale(*p.Locale); err != nil {
+			errs.Add("/locale", err.Error())
+		}
+	}
+	seen := make(map[string]bool, len(p.DependsOn))
+	for i, id := range p.DependsOn {
+		path := fmt.Sprintf("/dependsOn/%d", i)
+		switch {
+		case id == "":
+			errs.Add(path, "is required")
+		case id == p.ID:
+			errs.Add(path, "a task cannot depend on itself")
+		case seen[id]:
+			errs.Add(path, fmt.Sprintf("duplicate dependency %q", id))
+		}
+		seen[id] = true
+	}
+	return errs.Err()
+}
+
+// validateMessage records the invalid fields of the message at path.
+func validateMessage(errs *ValidationError, path string, message Message) {
+	switch message.Role {
+	case MessageRoleUser, MessageRoleAgent:
+	case "":
+		errs.Add(path+"/role", "is required")
+	default:
+		errs.Add(path+"/role", fmt.Sprintf("must be %q or %q, got %q", MessageRoleUser, MessageRoleAgent, message.Role))
+	}
+	if len(message.Parts) == 0 {
+		errs.Add(path+"/parts", "must contain at least one part")
+	}
+	for i, part := range message.Parts {
+		switch p := part.(type) {
+		case FilePart:
+			hasBytes := p.File.Bytes != nil && *p.File.Bytes != ""
+			hasURI := p.File.URI != nil && *p.File.URI != ""
+			if !hasBytes && !hasURI {
+				errs.Add(fmt.Sprintf("%s/parts/%d/file", path, i), "must have bytes or uri")
+			}
+		case ArtifactReferencePart:
+			if p.TaskID == "" {
+				errs.Add(fmt.Sprintf("%s/parts/%d/taskId", path, i), "is required")
+			}
+			if p.Index < 0 {
+				errs.Add(fmt.Sprintf("%s/parts/%d/index", path, i), "must not be negative")
+			}
+		}
+	}
+}
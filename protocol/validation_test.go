@@ -0,0 +1,83 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendTaskParams_Validate(t *testing.T) {
+	valid := SendTaskParams{
+		ID:      "task-1",
+		Message: NewMessage(MessageRoleUser, []Part{NewTextPart("hi")}),
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.NoError(t, valid.Validate())
+	})
+
+	t.Run("CollectsEveryField", func(t *testing.T) {
+		historyLength := -1
+		locale := "not a locale"
+		params := SendTaskParams{
+			Message: Message{Role: "system", Parts: []Part{
+				NewTextPart("ok"),
+				FilePart{Type: PartTypeFile, File: FileContent{}},
+				ArtifactReferencePart{Type: PartTypeArtifactRef, Index: -1},
+			}},
+			Messages:      []Message{{Role: MessageRoleAgent}},
+			HistoryLength: &historyLength,
+			Locale:        &locale,
+		}
+		err := params.Validate()
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		paths := make([]string, len(validationErr.Fields))
+		for i, field := range validationErr.Fields {
+			paths[i] = field.Path
+			assert.NotEmpty(t, field.Reason)
+		}
+		assert.Equal(t, []string{
+			"/id",
+			"/message/role",
+			"/message/parts/1/file",
+			"/message/parts/2/taskId",
+			"/message/parts/2/index",
+			"/messages/0/parts",
+			"/historyLength",
+			"/locale",
+		}, paths)
+		assert.Contains(t, err.Error(), "/id: is required; /message/role: must be")
+	})
+
+	t.Run("DependsOn", func(t *testing.T) {
+		params := valid
+		params.DependsOn = []string{"task-0", "", "task-1", "task-0"}
+		err := params.Validate()
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		paths := make([]string, len(validationErr.Fields))
+		for i, field := range validationErr.Fields {
+			paths[i] = field.Path
+		}
+		assert.Equal(t, []string{"/dependsOn/1", "/dependsOn/2", "/dependsOn/3"}, paths)
+		assert.Contains(t, err.Error(), "cannot depend on itself")
+	})
+
+	t.Run("JSONRoundTrip", func(t *testing.T) {
+		err := (&ValidationError{Fields: []FieldError{{Path: "/id", Reason: "is required"}}}).Err()
+		data, marshalErr := json.Marshal(err)
+		require.NoError(t, marshalErr)
+		assert.JSONEq(t, `{"fields":[{"path":"/id","reason":"is required"}]}`, string(data))
+		assert.NoError(t, (&ValidationError{}).Err())
+	})
+}
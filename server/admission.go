@@ -0,0 +1,166 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// ErrCodeAdmissionDenied is the JSON-RPC error code returned when the admission
+// webhook denies a request, or fails while the server fails closed.
+const ErrCodeAdmissionDenied int = -32011
+
+// defaultAdmissionTimeout bounds an admission webhook call without a timeout.
+const defaultAdmissionTimeout = 5 * time.Second
+
+// maxAdmissionResponseSize is the largest admission webhook response read.
+const maxAdmissionResponseSize = 1 << 20
+
+// AdmissionRequest is the body the server POSTs to the admission webhook.
+type AdmissionRequest struct {
+	// Method is the method of the request: tasks/send or tasks/sendSubscribe.
+	Method string `json:"method"`
+	// Params are the params of the request.
+	Params protocol.SendTaskParams `json:"params"`
+	// User is the ID of the authenticated user, if any.
+	User string `json:"user,omitempty"`
+}
+
+// AdmissionResponse is the decision the admission webhook answers with.
+type AdmissionResponse struct {
+	// Allowed admits the request.
+	Allowed bool `json:"allowed"`
+	// Reason tells the client why the request was denied.
+	Reason string `json:"reason,omitempty"`
+	// Params, if set, replace the params of an admitted request. The task ID
+	// cannot change.
+	Params *protocol.SendTaskParams `json:"params,omitempty"`
+	// Metadata, if set, is merged into the task metadata of an admitted request,
+	// for example to tag the task with the tenant of the user.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// admissionWebhook holds the settings of WithAdmissionWebhook.
+type admissionWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// admit sends the params of request to the admission webhook, if any, and applies
+// its decision to params.
+func (s *A2AServer) admit(ctx context.Context, method string, params *protocol.SendTaskParams) *jsonrpc.Error {
+	if s.admission == nil {
+		return nil
+	}
+	decision, err := s.admission.review(ctx, method, *params)
+	if err != nil {
+		if s.admissionFailOpen {
+			log.Warnf("Admitting task %s as the admission webhook failed: %v", params.ID, err)
+			return nil
+		}
+		log.Errorf("Denying task %s as the admission webhook failed: %v", params.ID, err)
+		return jsonrpc.NewError(ErrCodeAdmissionDenied, "admission webhook failed", nil)
+	}
+	if !decision.Allowed {
+		log.Infof("Admission webhook denied task %s: %s", params.ID, decision.Reason)
+		var reason interface{}
+		if decision.Reason != "" {
+			reason = decision.Reason
+		}
+		return jsonrpc.NewError(ErrCodeAdmissionDenied, "request denied", reason)
+	}
+	admitted := *params
+	if decision.Params != nil {
+		if decision.Params.ID != params.ID {
+			log.Errorf("Admission webhook changed the ID of task %s to %q", params.ID, decision.Params.ID)
+			return jsonrpc.NewError(ErrCodeAdmissionDenied, "admission webhook failed", nil)
+		}
+		admitted = *decision.Params
+	}
+	if len(decision.Metadata) > 0 {
+		metadata := make(map[string]interface{}, len(admitted.Metadata)+len(decision.Metadata))
+		for key, value := range admitted.Metadata {
+			metadata[key] = value
+		}
+		for key, value := range decision.Metadata {
+			metadata[key] = value
+		}
+		admitted.Metadata = metadata
+	}
+	if err := admitted.Validate(); err != nil {
+		log.Errorf("Admission webhook made the params of task %s invalid: %v", params.ID, err)
+		return jsonrpc.NewError(ErrCodeAdmissionDenied, "admission webhook failed", nil)
+	}
+	// Params the webhook changed pass the checks the request passed, as if sent.
+	if decision.Params != nil || len(decision.Metadata) > 0 {
+		if checkErr := s.recheckAdmitted(method, &admitted); checkErr != nil {
+			log.Errorf("Admission webhook made the params of task %s invalid: %v", params.ID, checkErr)
+			return jsonrpc.NewError(ErrCodeAdmissionDenied, "admission webhook failed", nil)
+		}
+	}
+	*params = admitted
+	return nil
+}
+
+// recheckAdmitted runs the checks of a send request again on the params of an
+// admitted request: the params schema and, if the webhook replaced them, the checks
+// of the messages.
+func (s *A2AServer) recheckAdmitted(method string, admitted *protocol.SendTaskParams) *jsonrpc.Error {
+	raw, err := json.Marshal(admitted)
+	if err != nil {
+		return jsonrpc.ErrInternalError(err.Error())
+	}
+	if schemaErr := s.validateParams(jsonrpc.Request{Method: method, Params: raw}); schemaErr != nil {
+		return schemaErr
+	}
+	return s.checkSendMessages(admitted)
+}
+
+// review POSTs an AdmissionRequest to the webhook and returns its decision.
+func (w *admissionWebhook) review(
+	ctx context.Context,
+	method string,
+	params protocol.SendTaskParams,
+) (*AdmissionResponse, error) {
+	review := AdmissionRequest{Method: method, Params: params}
+	if user, ok := ctx.Value(auth.AuthUserKey).(*auth.User); ok && user != nil {
+		review.User = user.ID
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal admission request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admission request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("admission request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("admission webhook returned status %d", resp.StatusCode)
+	}
+	var decision AdmissionResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAdmissionResponseSize)).Decode(&decision); err != nil {
+		return nil, fmt.Errorf("failed to decode admission response: %w", err)
+	}
+	return &decision, nil
+}
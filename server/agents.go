@@ -0,0 +1,105 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// RegisterAgent serves another agent from this server under path. Tasks sent to
+// path are run by processor in a task manager of their own, and the agent's card
+// is served at path followed by protocol.AgentCardPath, for example
+// /weather/.well-known/agent.json for the path /weather. The agent shares the
+// options of this server, including its authentication and worker pool.
+//
+// RegisterAgent must be called before Handler or Start.
+func (s *A2AServer) RegisterAgent(path string, card AgentCard, processor taskmanager.TaskProcessor) error {
+	if processor == nil {
+		return errors.New("RegisterAgent requires a non-nil processor")
+	}
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("agent path %q must start with /", path)
+	}
+	path = strings.TrimRight(path, "/")
+	if path == "" || path == strings.TrimRight(s.jsonRPCEndpoint, "/") {
+		return fmt.Errorf("agent path %q conflicts with the server's JSON-RPC endpoint", path)
+	}
+	for _, agent := range s.agents {
+		if agent.jsonRPCEndpoint == path {
+			return fmt.Errorf("an agent is already registered at %q", path)
+		}
+	}
+	if err := card.ValidateExtensions(); err != nil {
+		return fmt.Errorf("invalid agent card: %w", err)
+	}
+	taskManager, err := taskmanager.NewMemoryTaskManager(processor)
+	if err != nil {
+		return fmt.Errorf("failed to create task manager for agent %q: %w", path, err)
+	}
+
+	agent := *s
+	agent.agentCard = card
+	agent.taskManager = taskManager
+	agent.jsonRPCEndpoint = path
+	agent.httpServer = nil
+	agent.agents = nil
+	if err := agent.configureTaskManager(taskManager); err != nil {
+		return err
+	}
+	if s.idempotency != nil {
+		agent.idempotency = newIdempotencyCache(s.idempotencyWindow)
+	}
+	if s.agentCardSigner != nil {
+		signed, err := protocol.SignAgentCard(card, s.agentCardSigner)
+		if err != nil {
+			return fmt.Errorf("failed to sign agent card: %w", err)
+		}
+		agent.signedAgentCard = signed
+	}
+	s.agents = append(s.agents, &agent)
+	return nil
+}
+
+// route adds the card and JSON-RPC endpoints of a registered agent to router,
+// serving the JSON-RPC endpoint through authMiddleware if it is set.
+func (s *A2AServer) route(router *http.ServeMux, authMiddleware *auth.Middleware) {
+	router.HandleFunc(s.jsonRPCEndpoint+protocol.AgentCardPath, s.handleAgentCard)
+	var handler http.Handler = http.HandlerFunc(s.handleJSONRPC)
+	if authMiddleware != nil {
+		handler = authMiddleware.Wrap(handler)
+	}
+	router.Handle(s.jsonRPCEndpoint, handler)
+	// Clients may address the endpoint with a trailing slash.
+	router.Handle(s.jsonRPCEndpoint+"/{$}", handler)
+}
+
+// startRetention starts the retention sweepers of this server and its agents.
+func (s *A2AServer) startRetention() {
+	if s.retention != nil {
+		s.retention.start()
+	}
+	for _, agent := range s.agents {
+		agent.startRetention()
+	}
+}
+
+// closeRetention stops the retention sweepers of this server and its agents.
+func (s *A2AServer) closeRetention() {
+	if s.retention != nil {
+		s.retention.close()
+	}
+	for _, agent := range s.agents {
+		agent.closeRetention()
+	}
+}
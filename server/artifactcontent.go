@@ -0,0 +1,125 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// ArtifactContentPath returns the path under which the endpoint of
+// WithArtifactContentEndpoint at path serves the artifact of taskID with index,
+// for processors setting the URI of a FilePart.
+func ArtifactContentPath(path, taskID string, index int) string {
+	return strings.TrimSuffix(path, "/") + "/" + url.PathEscape(taskID) + "/" + strconv.Itoa(index)
+}
+
+// routeArtifactContent adds the artifact content endpoint, if enabled, to router.
+func (s *A2AServer) routeArtifactContent(router *http.ServeMux) {
+	if s.artifactEndpoint == "" {
+		return
+	}
+	var handler http.Handler = http.HandlerFunc(s.handleArtifactContent)
+	if s.authMiddleware != nil {
+		handler = s.authMiddleware.Wrap(handler)
+	}
+	router.Handle("GET "+strings.TrimSuffix(s.artifactEndpoint, "/")+"/{taskID}/{index}", handler)
+}
+
+// artifactContentCSP keeps content served from the origin of the agent from
+// running scripts or loading anything, should a browser render it.
+const artifactContentCSP = "default-src 'none'; sandbox"
+
+// handleArtifactContent serves the content of an artifact: the inline bytes of its
+// file parts, concatenated in order. Range requests get the requested bytes
+// with a 206 Partial Content status; the ETag, the SHA-256 of the content, lets
+// clients resume an interrupted download with If-Range. The content, written by
+// the agent, is served as a sandboxed download without type sniffing, so an HTML
+// or SVG artifact cannot script the origin; the artifact opts in to inline
+// display with protocol.MetadataKeyInlineContent.
+func (s *A2AServer) handleArtifactContent(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		http.Error(w, "invalid artifact index", http.StatusBadRequest)
+		return
+	}
+	taskID := r.PathValue("taskID")
+	task, err := s.taskManager.OnGetTask(r.Context(), protocol.TaskQueryParams{ID: taskID})
+	if err != nil {
+		var rpcErr *jsonrpc.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == taskmanager.ErrCodeTaskNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		log.Errorf("Failed to get task %s for its artifact content: %v", taskID, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	artifact, ok := protocol.FindArtifact(task.Artifacts, index)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var content []byte
+	mimeType := "application/octet-stream"
+	files := 0
+	for _, part := range artifact.Parts {
+		filePart, ok := part.(protocol.FilePart)
+		if !ok || filePart.File.Bytes == nil {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(*filePart.File.Bytes)
+		if err != nil {
+			log.Errorf("Invalid file bytes in artifact %d of task %s: %v", index, taskID, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if files == 0 && filePart.File.MimeType != nil {
+			mimeType = *filePart.File.MimeType
+		}
+		files++
+		content = append(content, data...)
+	}
+	if files == 0 {
+		http.Error(w, "artifact has no inline file content", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", artifactContentCSP)
+	w.Header().Set("Content-Disposition", contentDisposition(artifact))
+	w.Header().Set("ETag", `"`+protocol.ComputeChecksum(content)+`"`)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+}
+
+// contentDisposition returns the Content-Disposition of the content of artifact:
+// an attachment named after the artifact, unless it opts in to inline display.
+func contentDisposition(artifact protocol.Artifact) string {
+	disposition := "attachment"
+	if inline, _ := artifact.Metadata[protocol.MetadataKeyInlineContent].(bool); inline {
+		disposition = "inline"
+	}
+	if artifact.Name == nil || *artifact.Name == "" {
+		return disposition
+	}
+	if formatted := mime.FormatMediaType(disposition, map[string]string{"filename": *artifact.Name}); formatted != "" {
+		return formatted
+	}
+	return disposition
+}
@@ -0,0 +1,107 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// sendBatchResult is the outcome of one task of a tasks/sendBatch request.
+type sendBatchResult struct {
+	Task  json.RawMessage `json:"task,omitempty"`
+	Error *jsonrpc.Error  `json:"error,omitempty"`
+}
+
+// handleTasksSendBatch handles the tasks/sendBatch method. The tasks are sent
+// concurrently, each as by tasks/send, so one failing does not affect the others.
+// The request's idempotency key, which identifies a single send, is not applied.
+func (s *A2AServer) handleTasksSendBatch(ctx context.Context, w http.ResponseWriter, request jsonrpc.Request) {
+	if s.maxBatchBytes > 0 && int64(len(request.Params)) > s.maxBatchBytes {
+		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidRequest(
+			fmt.Sprintf("a batch holds at most %d bytes, got %d", s.maxBatchBytes, len(request.Params))))
+		return
+	}
+	// Keep the tasks raw so each is decoded as the params of a tasks/send request.
+	var params struct {
+		Tasks []json.RawMessage `json:"tasks"`
+	}
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		s.writeJSONRPCError(w, request.ID, err)
+		return
+	}
+	if len(params.Tasks) == 0 {
+		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidParams("tasks is required"))
+		return
+	}
+	if len(params.Tasks) > s.maxBatchSize {
+		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidRequest(
+			fmt.Sprintf("a batch holds at most %d tasks, got %d", s.maxBatchSize, len(params.Tasks))))
+		return
+	}
+	ctx = context.WithValue(ctx, idempotencyKey{}, nil)
+	results := make([]sendBatchResult, len(params.Tasks))
+	var wg sync.WaitGroup
+	for i, task := range params.Tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.sendBatchTask(ctx, request, task)
+		}()
+	}
+	wg.Wait()
+	s.writeJSONRPCResponse(w, request.ID, results)
+}
+
+// sendBatchTask sends one task of a tasks/sendBatch request through the tasks/send
+// handler and returns what the handler answered.
+func (s *A2AServer) sendBatchTask(ctx context.Context, batch jsonrpc.Request, task json.RawMessage) sendBatchResult {
+	request := jsonrpc.Request{Message: batch.Message, Method: protocol.MethodTasksSend, Params: task}
+	if err := s.validateParams(request); err != nil {
+		return sendBatchResult{Error: s.redactError(err)}
+	}
+	recorder := &batchResponseWriter{header: make(http.Header)}
+	s.handleTasksSend(ctx, recorder, request)
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *jsonrpc.Error  `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.body.Bytes(), &response); err != nil {
+		log.Errorf("Failed to decode a tasks/sendBatch entry response: %v", err)
+		return sendBatchResult{Error: jsonrpc.ErrInternalError("failed to send task")}
+	}
+	return sendBatchResult{Task: response.Result, Error: response.Error}
+}
+
+// batchResponseWriter keeps the response a handler writes for one task of a
+// tasks/sendBatch request.
+type batchResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+// Header implements http.ResponseWriter.
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// Write implements http.ResponseWriter.
+func (w *batchResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+// WriteHeader implements http.ResponseWriter. The status is not needed: the
+// response body tells success from failure.
+func (w *batchResponseWriter) WriteHeader(int) {}
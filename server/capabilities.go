@@ -0,0 +1,68 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// builtinMethods are the JSON-RPC methods every server handles, see
+// routeJSONRPCMethod. The capabilities and the debug endpoint both list them.
+var builtinMethods = []string{
+	protocol.MethodTasksSend,
+	protocol.MethodTasksSendSubscribe,
+	protocol.MethodTasksGet,
+	protocol.MethodTasksCancel,
+	protocol.MethodTasksPushNotificationSet,
+	protocol.MethodTasksPushNotificationGet,
+	protocol.MethodTasksResubscribe,
+	protocol.MethodTasksSubscribeMultiple,
+	protocol.MethodTasksSendBatch,
+	protocol.MethodCapabilities,
+}
+
+// handleCapabilities handles the a2a/capabilities method, answering with the
+// capabilities of the agent card and the methods the server is configured with.
+func (s *A2AServer) handleCapabilities(_ context.Context, w http.ResponseWriter, request jsonrpc.Request) {
+	s.writeJSONRPCResponse(w, request.ID, s.capabilities())
+}
+
+// capabilities summarizes the agent card and the server configuration.
+func (s *A2AServer) capabilities() *protocol.Capabilities {
+	card := s.agentCard
+	caps := &protocol.Capabilities{
+		Streaming:              card.Capabilities.Streaming,
+		PushNotifications:      card.Capabilities.PushNotifications,
+		StateTransitionHistory: card.Capabilities.StateTransitionHistory,
+		Methods:                append([]string(nil), builtinMethods...),
+	}
+	for _, skill := range card.Skills {
+		caps.Skills = append(caps.Skills, skill.ID)
+	}
+	for uri := range card.Extensions {
+		caps.Extensions = append(caps.Extensions, uri)
+	}
+	sort.Strings(caps.Extensions)
+	if _, ok := s.inputStreamer(); ok {
+		caps.Methods = append(caps.Methods, protocol.MethodTasksSendInput)
+	}
+	if s.taskSnapshots {
+		caps.Methods = append(caps.Methods, protocol.MethodTasksExport, protocol.MethodTasksImport)
+	}
+	custom := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		custom = append(custom, name)
+	}
+	sort.Strings(custom)
+	caps.Methods = append(caps.Methods, custom...)
+	return caps
+}
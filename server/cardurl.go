@@ -0,0 +1,121 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+)
+
+// parseAbsoluteURL parses rawURL, which must have a scheme and a host.
+func parseAbsoluteURL(rawURL string) (*url.URL, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("%q is not an absolute URL", rawURL)
+	}
+	return parsed, nil
+}
+
+// checkCardURL compares the URL of the agent card with where the server is
+// reached: the URL of WithExternalURL if set, or else addr, the address it listens
+// on for plain HTTP. A mismatch fails with WithStrictCardURL and is logged otherwise.
+func (s *A2AServer) checkCardURL(addr net.Addr) error {
+	err := s.validateCardURL(addr)
+	if err == nil {
+		return nil
+	}
+	if s.strictCardURL {
+		return fmt.Errorf("agent card URL mismatch: %w", err)
+	}
+	log.Warnf("Agent card URL may be misconfigured: %v", err)
+	return nil
+}
+
+// validateCardURL returns an error if a client following the card URL would not
+// reach the JSON-RPC endpoint of the server, see checkCardURL.
+func (s *A2AServer) validateCardURL(addr net.Addr) error {
+	card, err := parseAbsoluteURL(s.agentCard.URL)
+	if err != nil {
+		return fmt.Errorf("invalid card URL: %w", err)
+	}
+	basePath := ""
+	if s.externalURL != "" {
+		external, err := parseAbsoluteURL(s.externalURL)
+		if err != nil {
+			return fmt.Errorf("invalid external URL: %w", err)
+		}
+		if !strings.EqualFold(card.Scheme, external.Scheme) || !strings.EqualFold(hostPort(card), hostPort(external)) {
+			return fmt.Errorf("card URL %s does not have the origin of the external URL %s", card, external)
+		}
+		basePath = strings.TrimSuffix(external.Path, "/")
+	} else {
+		if err := matchListenAddr(card, addr); err != nil {
+			return err
+		}
+	}
+	if want := basePath + strings.TrimSuffix(s.jsonRPCEndpoint, "/"); strings.TrimSuffix(card.Path, "/") != want {
+		if want == "" {
+			want = "/"
+		}
+		return fmt.Errorf("card URL %s does not have the path of the JSON-RPC endpoint %s", card, want)
+	}
+	return nil
+}
+
+// matchListenAddr returns an error if card cannot be the URL of a plain HTTP server
+// listening on addr. Host names other than localhost are not resolved, so they
+// only need the port of addr.
+func matchListenAddr(card *url.URL, addr net.Addr) error {
+	if !strings.EqualFold(card.Scheme, "http") {
+		return fmt.Errorf("card URL %s is not served: the server listens for plain HTTP on %s", card, addr)
+	}
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return nil
+	}
+	if _, port, _ := net.SplitHostPort(hostPort(card)); port != strconv.Itoa(tcpAddr.Port) {
+		return fmt.Errorf("card URL %s does not have the port the server listens on: %s", card, addr)
+	}
+	if tcpAddr.IP.IsUnspecified() {
+		return nil // Listening on every interface.
+	}
+	host := card.Hostname()
+	ip := net.ParseIP(host)
+	loopback := strings.EqualFold(host, "localhost") || ip != nil && ip.IsLoopback()
+	switch {
+	case tcpAddr.IP.IsLoopback():
+		if !loopback {
+			return fmt.Errorf("card URL %s is not reachable: the server only listens on %s", card, addr)
+		}
+	case loopback || ip != nil && !ip.Equal(tcpAddr.IP):
+		return fmt.Errorf("card URL %s does not have the host the server listens on: %s", card, addr)
+	}
+	return nil
+}
+
+// hostPort returns the host of u with its port, defaulted from the scheme.
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return net.JoinHostPort(u.Hostname(), "443")
+	case "http":
+		return net.JoinHostPort(u.Hostname(), "80")
+	default:
+		return u.Host
+	}
+}
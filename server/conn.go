@@ -0,0 +1,290 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/sse"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// sseFrameEnd ends each event of an SSE stream.
+var sseFrameEnd = []byte("\n\n")
+
+// ServeConn serves the JSON-RPC calls of a client over conn, a long-lived
+// connection carrying newline-delimited JSON-RPC messages such as a WebSocket,
+// until the client closes it or ctx is done. Calls may be in flight concurrently
+// and are answered as they complete, by JSON-RPC id; they go through the same
+// handlers as over HTTP, but not through the auth provider: authenticate the
+// connection before serving it, e.g. when upgrading it.
+//
+// Streams opened by tasks/sendSubscribe and tasks/resubscribe use credit-based
+// flow control, see protocol.GrantCreditsParams: their events are sent as
+// tasks/streamEvent notifications, one per credit the client granted with
+// tasks/grantCredits, and a stream without credits pauses, holding the task's
+// events back as a slow client would, until the client grants more. The close
+// event ending a stream needs no credit. ServeConn closes conn before returning.
+func (s *A2AServer) ServeConn(ctx context.Context, conn io.ReadWriteCloser) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopClosing := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stopClosing()
+	c := &serverConn{server: s, encoder: json.NewEncoder(conn), windows: make(map[string]*creditWindow)}
+	defer func() {
+		cancel()
+		c.handlers.Wait()
+		conn.Close()
+	}()
+
+	decoder := json.NewDecoder(conn)
+	for {
+		var message json.RawMessage
+		if err := decoder.Decode(&message); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		c.serve(ctx, message)
+	}
+}
+
+// serverConn is a connection served by ServeConn.
+type serverConn struct {
+	server   *A2AServer
+	handlers sync.WaitGroup // Calls in flight.
+
+	writeMu sync.Mutex // Serializes writes so messages do not interleave.
+	encoder *json.Encoder
+
+	mu      sync.Mutex
+	windows map[string]*creditWindow // Credits of the open streams, by request id.
+}
+
+// serve handles one message from the client: a credit grant, or a call served in
+// the background.
+func (c *serverConn) serve(ctx context.Context, message json.RawMessage) {
+	var peek struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(message, &peek); err == nil && peek.Method == protocol.MethodTasksGrantCredits {
+		c.grant(peek.Params)
+		return
+	}
+	w := &connResponseWriter{conn: c, ctx: ctx, header: make(http.Header)}
+	request, err := c.server.parseJSONRPCRequest(w, io.NopCloser(bytes.NewReader(message)))
+	if err != nil {
+		w.finish()
+		return
+	}
+	w.requestID = requestIDString(request.ID)
+	// Open the window before reading further messages, so the client may grant
+	// credits right after the request.
+	if request.Method == protocol.MethodTasksSendSubscribe || request.Method == protocol.MethodTasksResubscribe {
+		w.window = c.openWindow(w.requestID)
+	}
+	c.handlers.Add(1)
+	go func() {
+		defer c.handlers.Done()
+		defer c.closeWindow(w.requestID, w.window)
+		c.server.serveJSONRPCRequest(ctx, w, request)
+		w.finish()
+	}()
+}
+
+// grant adds the credits of a tasks/grantCredits notification to its stream.
+func (c *serverConn) grant(rawParams json.RawMessage) {
+	var params protocol.GrantCreditsParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		log.Warnf("Ignoring malformed %s notification: %v", protocol.MethodTasksGrantCredits, err)
+		return
+	}
+	if err := params.Validate(); err != nil {
+		log.Warnf("Ignoring invalid %s notification: %v", protocol.MethodTasksGrantCredits, err)
+		return
+	}
+	c.mu.Lock()
+	window, ok := c.windows[params.ID]
+	c.mu.Unlock()
+	if !ok {
+		// The stream may have ended already.
+		log.Debugf("Ignoring credits granted to unknown stream %q", params.ID)
+		return
+	}
+	window.grant(params.Credits)
+}
+
+// openWindow registers the credit window of the stream opened by request id.
+func (c *serverConn) openWindow(id string) *creditWindow {
+	window := newCreditWindow()
+	c.mu.Lock()
+	c.windows[id] = window
+	c.mu.Unlock()
+	return window
+}
+
+// closeWindow unregisters the credit window of a stream, if any.
+func (c *serverConn) closeWindow(id string, window *creditWindow) {
+	if window == nil {
+		return
+	}
+	c.mu.Lock()
+	if c.windows[id] == window {
+		delete(c.windows, id)
+	}
+	c.mu.Unlock()
+}
+
+// write sends message to the client.
+func (c *serverConn) write(message interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.encoder.Encode(message)
+}
+
+// creditWindow counts the events a stream may still send.
+type creditWindow struct {
+	mu      sync.Mutex
+	credits int
+	granted chan struct{} // Closed by the next grant.
+}
+
+// newCreditWindow creates a window without credits.
+func newCreditWindow() *creditWindow {
+	return &creditWindow{granted: make(chan struct{})}
+}
+
+// grant adds n credits to the window, waking a stream paused on it.
+func (w *creditWindow) grant(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.credits += n
+	close(w.granted)
+	w.granted = make(chan struct{})
+}
+
+// acquire uses a credit, waiting for one to be granted while there is none. It
+// returns ctx's error if ctx is done first.
+func (w *creditWindow) acquire(ctx context.Context) error {
+	for {
+		w.mu.Lock()
+		if w.credits > 0 {
+			w.credits--
+			w.mu.Unlock()
+			return nil
+		}
+		granted := w.granted
+		w.mu.Unlock()
+		select {
+		case <-granted:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// connResponseWriter sends the response a handler writes for a call on a
+// connection: a JSON-RPC response is sent as it is once the handler returns, and
+// each event of an SSE stream as a tasks/streamEvent notification as it is written.
+type connResponseWriter struct {
+	conn      *serverConn
+	ctx       context.Context
+	requestID string
+	window    *creditWindow // Credits of the stream; nil for calls that cannot stream.
+	header    http.Header
+	streaming bool
+	body      bytes.Buffer // The response, or the start of an SSE event not sent yet.
+}
+
+// Header implements http.ResponseWriter.
+func (w *connResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader implements http.ResponseWriter. The status is not needed: the
+// response body tells success from failure.
+func (w *connResponseWriter) WriteHeader(int) {
+	w.streaming = w.window != nil && w.header.Get("Content-Type") == "text/event-stream"
+}
+
+// Write implements http.ResponseWriter. In a stream, it blocks until each event
+// written has a credit.
+func (w *connResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	if !w.streaming {
+		return len(data), nil
+	}
+	for {
+		end := bytes.Index(w.body.Bytes(), sseFrameEnd)
+		if end < 0 {
+			return len(data), nil
+		}
+		frame := w.body.Next(end + len(sseFrameEnd))
+		if err := w.sendEvent(frame); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush implements http.Flusher. Events are sent as they are written.
+func (w *connResponseWriter) Flush() {}
+
+// sendEvent sends an SSE event of the stream once it has a credit.
+func (w *connResponseWriter) sendEvent(frame []byte) error {
+	data, eventType, err := sse.NewEventReader(bytes.NewReader(frame)).ReadEvent()
+	if err != nil && len(data) == 0 {
+		return nil // A keep-alive without data.
+	}
+	var envelope jsonrpc.RawResponse
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		log.Errorf("Failed to decode SSE event of stream %q: %v", w.requestID, err)
+		return nil
+	}
+	if eventType != protocol.EventClose {
+		if err := w.window.acquire(w.ctx); err != nil {
+			return err
+		}
+	}
+	params := protocol.StreamEventParams{ID: w.requestID, EventType: eventType, Event: envelope.Result}
+	return w.conn.write(&jsonrpc.Request{
+		Message: jsonrpc.Message{JSONRPC: jsonrpc.Version},
+		Method:  protocol.MethodTasksStreamEvent,
+		Params:  mustMarshal(params),
+	})
+}
+
+// finish sends the JSON-RPC response of a call that did not stream, if any.
+func (w *connResponseWriter) finish() {
+	if w.streaming || w.body.Len() == 0 {
+		return
+	}
+	if err := w.conn.write(json.RawMessage(bytes.TrimSpace(w.body.Bytes()))); err != nil {
+		log.Errorf("Failed to write response to connection (ID: %v): %v", w.requestID, err)
+	}
+}
+
+// mustMarshal returns the JSON encoding of params, which cannot fail to encode.
+func mustMarshal(params protocol.StreamEventParams) json.RawMessage {
+	data, err := json.Marshal(params)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,137 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+)
+
+// DebugInfo is the document served by the debug endpoint.
+type DebugInfo struct {
+	// Name is the agent's name from its agent card.
+	Name string `json:"name"`
+	// Version is the agent's version from its agent card.
+	Version string `json:"version"`
+	// Skills are the skills registered on the agent card.
+	Skills []AgentSkill `json:"skills"`
+	// Methods are the JSON-RPC methods the server handles, as listed by
+	// a2a/capabilities.
+	Methods []string `json:"methods"`
+	// Config is the server's non-secret configuration.
+	Config DebugConfig `json:"config"`
+	// Paused reports whether the server is paused, see A2AServer.Pause.
+	Paused bool `json:"paused"`
+	// PausedTasks is the number of tasks waiting for the server to resume.
+	PausedTasks int `json:"pausedTasks,omitempty"`
+	// TaskRetention reports the task retention sweeper, if enabled.
+	TaskRetention *TaskRetentionStats `json:"taskRetention,omitempty"`
+	// Queue reports the tasks waiting to start and those rejected.
+	Queue QueueStats `json:"queue"`
+}
+
+// DebugConfig describes the server configuration. It never includes secrets.
+type DebugConfig struct {
+	JSONRPCEndpoint    string   `json:"jsonrpcEndpoint"`
+	CORSEnabled        bool     `json:"corsEnabled"`
+	ReadTimeout        string   `json:"readTimeout"`
+	WriteTimeout       string   `json:"writeTimeout"`
+	IdleTimeout        string   `json:"idleTimeout"`
+	AuthEnabled        bool     `json:"authEnabled"`
+	JWKSEndpoint       string   `json:"jwksEndpoint,omitempty"`
+	DefaultLocale      string   `json:"defaultLocale"`
+	IdempotencyWindow  string   `json:"idempotencyWindow"`
+	TaskIDValidation   bool     `json:"taskIdValidation"`
+	Streaming          bool     `json:"streaming"`
+	WorkerPoolSize     int      `json:"workerPoolSize,omitempty"`
+	FairScheduling     bool     `json:"fairScheduling,omitempty"`
+	TaskRetention      string   `json:"taskRetention,omitempty"`
+	AllowedFileTypes   []string `json:"allowedFileTypes,omitempty"`
+	MaxJSONDepth       int      `json:"maxJsonDepth,omitempty"`
+	MaxRequestSize     int64    `json:"maxRequestSize,omitempty"`
+	MaxBatchSize       int      `json:"maxBatchSize"`
+	MaxBatchBytes      int64    `json:"maxBatchBytes,omitempty"`
+	RawDataMinSize     int      `json:"rawDataMinSize,omitempty"`
+	CancelOnDisconnect bool     `json:"cancelOnDisconnect,omitempty"`
+	ErrorVerbosity     string   `json:"errorVerbosity"`
+	BodyLogging        bool     `json:"bodyLogging,omitempty"`
+	MaxHistoryBytes    int      `json:"maxHistoryBytes,omitempty"`
+	ProcessorTimeout   string   `json:"processorTimeout,omitempty"`
+	MaxArtifacts       int      `json:"maxArtifactsPerTask,omitempty"`
+	MaxTaskWait        string   `json:"maxTaskWait"`
+}
+
+// debugInfo builds the debug document from the server's current state.
+func (s *A2AServer) debugInfo() DebugInfo {
+	skills := s.agentCard.Skills
+	if skills == nil {
+		skills = []AgentSkill{}
+	}
+	info := DebugInfo{
+		Name:    s.agentCard.Name,
+		Version: s.agentCard.Version,
+		Skills:  skills,
+		Methods: s.capabilities().Methods,
+		Config: DebugConfig{
+			JSONRPCEndpoint:    s.jsonRPCEndpoint,
+			CORSEnabled:        s.corsEnabled,
+			ReadTimeout:        s.readTimeout.String(),
+			WriteTimeout:       s.writeTimeout.String(),
+			IdleTimeout:        s.idleTimeout.String(),
+			AuthEnabled:        s.authProvider != nil,
+			DefaultLocale:      s.defaultLocale,
+			IdempotencyWindow:  s.idempotencyWindow.String(),
+			TaskIDValidation:   s.taskIDValidator != nil,
+			Streaming:          s.agentCard.Capabilities.Streaming,
+			WorkerPoolSize:     s.workerPoolSize,
+			FairScheduling:     s.workers != nil && s.workers.fair != nil,
+			MaxJSONDepth:       s.maxJSONDepth,
+			MaxRequestSize:     s.maxRequestSize,
+			MaxBatchSize:       s.maxBatchSize,
+			MaxBatchBytes:      s.maxBatchBytes,
+			RawDataMinSize:     s.rawDataMinSize,
+			CancelOnDisconnect: s.cancelOnDisconnect,
+			ErrorVerbosity:     s.errorVerbosity.String(),
+			BodyLogging:        s.logBodies,
+			MaxHistoryBytes:    s.maxHistoryBytes,
+			MaxArtifacts:       s.maxArtifacts,
+			MaxTaskWait:        s.maxTaskWait.String(),
+		},
+	}
+	info.Paused, info.PausedTasks = s.pause.state()
+	info.Queue = s.queue.stats()
+	if s.jwksEnabled {
+		info.Config.JWKSEndpoint = s.jwksEndpoint
+	}
+	if s.fileTypes != nil {
+		info.Config.AllowedFileTypes = s.fileTypes.allowed
+	}
+	if s.processorTimeout > 0 {
+		info.Config.ProcessorTimeout = s.processorTimeout.String()
+	}
+	if s.retention != nil {
+		stats := s.retention.stats()
+		info.Config.TaskRetention = s.taskRetentionTTL.String()
+		info.TaskRetention = &stats
+	}
+	return info
+}
+
+// handleDebug serves the server's skills, methods and configuration as JSON.
+func (s *A2AServer) handleDebug(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(s.debugInfo()); err != nil {
+		log.Errorf("Failed to encode debug info: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
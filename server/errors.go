@@ -0,0 +1,53 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+)
+
+// ErrorVerbosity decides how much detail JSON-RPC error responses carry in their
+// data field.
+type ErrorVerbosity int
+
+// ErrorVerbosity constants.
+const (
+	// ErrorVerbosityTerse omits the data of internal errors, which may reveal causes
+	// and internal messages, so clients only see the generic message. Errors caused
+	// by the request itself, such as invalid params, keep their details. This is
+	// the default.
+	ErrorVerbosityTerse ErrorVerbosity = iota
+	// ErrorVerbosityVerbose returns the data of every error, including the causes of
+	// internal errors. Use it in development.
+	ErrorVerbosityVerbose
+)
+
+// String returns the name of the level.
+func (v ErrorVerbosity) String() string {
+	switch v {
+	case ErrorVerbosityTerse:
+		return "terse"
+	case ErrorVerbosityVerbose:
+		return "verbose"
+	default:
+		return "unknown"
+	}
+}
+
+// redactError returns err as it is sent to the client under the configured error
+// verbosity. It leaves err unchanged.
+func (s *A2AServer) redactError(err *jsonrpc.Error) *jsonrpc.Error {
+	if s.errorVerbosity == ErrorVerbosityVerbose {
+		return err
+	}
+	redacted := jsonrpc.WithoutInternalData(err)
+	if redacted != err {
+		log.Debugf("Omitting internal error details from response: %v", err.Data)
+	}
+	return redacted
+}
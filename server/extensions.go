@@ -0,0 +1,201 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ValidateExtensionURI checks that uri is a namespaced extension key: an absolute
+// URI such as "https://example.com/ext/billing" or "urn:example:billing", so that
+// extensions from different vendors cannot collide.
+func ValidateExtensionURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("extension key %q is not a URI: %w", uri, err)
+	}
+	if !u.IsAbs() {
+		return fmt.Errorf("extension key %q must be an absolute URI", uri)
+	}
+	if u.Host == "" && u.Opaque == "" {
+		return fmt.Errorf("extension key %q must name a host or an opaque namespace", uri)
+	}
+	return nil
+}
+
+// SetExtension stores value, marshaled to JSON, as the extension named uri.
+func (c *AgentCard) SetExtension(uri string, value interface{}) error {
+	if err := ValidateExtensionURI(uri); err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal extension %q: %w", uri, err)
+	}
+	if c.Extensions == nil {
+		c.Extensions = make(map[string]json.RawMessage)
+	}
+	c.Extensions[uri] = data
+	return nil
+}
+
+// Extension unmarshals the extension named uri into value, which must be a pointer.
+// It reports whether the card declares the extension.
+func (c *AgentCard) Extension(uri string, value interface{}) (bool, error) {
+	data, ok := c.Extensions[uri]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return true, fmt.Errorf("failed to unmarshal extension %q: %w", uri, err)
+	}
+	return true, nil
+}
+
+// ValidateExtensions checks that every extension key of the card is a namespaced URI.
+func (c *AgentCard) ValidateExtensions() error {
+	for uri := range c.Extensions {
+		if err := ValidateExtensionURI(uri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExtensionDecoder decodes the raw JSON of an extension into value, a pointer.
+type ExtensionDecoder func(data []byte, value interface{}) error
+
+// ExtensionRegistry holds decoders for extensions whose JSON does not match the
+// types they are read into, such as agents using snake_case where the types use
+// camelCase. Extensions without a registered decoder are read with json.Unmarshal.
+// It is safe for concurrent use.
+type ExtensionRegistry struct {
+	mu       sync.RWMutex
+	decoders map[string]ExtensionDecoder
+}
+
+// NewExtensionRegistry creates an empty ExtensionRegistry.
+func NewExtensionRegistry() *ExtensionRegistry {
+	return &ExtensionRegistry{decoders: make(map[string]ExtensionDecoder)}
+}
+
+// Register sets the decoder for the extension named uri, replacing any earlier one.
+func (r *ExtensionRegistry) Register(uri string, decoder ExtensionDecoder) error {
+	if err := ValidateExtensionURI(uri); err != nil {
+		return err
+	}
+	if decoder == nil {
+		return fmt.Errorf("decoder for extension %q must not be nil", uri)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decoders[uri] = decoder
+	return nil
+}
+
+// Decode works like AgentCard.Extension, but uses the decoder registered for uri, if any.
+func (r *ExtensionRegistry) Decode(card *AgentCard, uri string, value interface{}) (bool, error) {
+	r.mu.RLock()
+	decoder, ok := r.decoders[uri]
+	r.mu.RUnlock()
+	if !ok {
+		return card.Extension(uri, value)
+	}
+	data, ok := card.Extensions[uri]
+	if !ok {
+		return false, nil
+	}
+	if err := decoder(data, value); err != nil {
+		return true, fmt.Errorf("failed to decode extension %q: %w", uri, err)
+	}
+	return true, nil
+}
+
+// FieldNamer maps a field name as sent by an agent to the name used by the JSON
+// tags of the type it is decoded into.
+type FieldNamer func(name string) string
+
+// SnakeToCamel is a FieldNamer converting snake_case names such as "max_tokens"
+// to camelCase ("maxTokens"). Names without underscores are kept.
+func SnakeToCamel(name string) string {
+	if !strings.Contains(name, "_") {
+		return name
+	}
+	var b strings.Builder
+	upper := false
+	for i, r := range name {
+		switch {
+		case r == '_':
+			upper = i > 0 // A leading underscore is dropped, not capitalizing anything.
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// FieldMap returns a FieldNamer renaming the names in mapping, from the agent's
+// name to the type's, and keeping all others.
+func FieldMap(mapping map[string]string) FieldNamer {
+	return func(name string) string {
+		if renamed, ok := mapping[name]; ok {
+			return renamed
+		}
+		return name
+	}
+}
+
+// RenamingDecoder returns an ExtensionDecoder that renames object keys at any
+// depth with namer before unmarshaling. A key that already has the name another
+// key would be renamed to wins over the renamed one.
+func RenamingDecoder(namer FieldNamer) ExtensionDecoder {
+	return func(data []byte, value interface{}) error {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber() // Keep numbers exact through the re-encoding.
+		var generic interface{}
+		if err := decoder.Decode(&generic); err != nil {
+			return err
+		}
+		renamed, err := json.Marshal(renameFields(generic, namer))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(renamed, value)
+	}
+}
+
+// renameFields renames the object keys of a decoded JSON value with namer.
+func renameFields(value interface{}, namer FieldNamer) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			name := namer(key)
+			if _, exact := v[name]; exact && name != key {
+				continue
+			}
+			renamed[name] = renameFields(item, namer)
+		}
+		return renamed
+	case []interface{}:
+		for i, item := range v {
+			v[i] = renameFields(item, namer)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,123 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// textMediaTypes are the non-text/* types whose content sniffs as text/plain.
+var textMediaTypes = map[string]bool{
+	"application/json":   true,
+	"application/xml":    true,
+	"application/yaml":   true,
+	"application/x-yaml": true,
+}
+
+// fileTypePolicy checks the files in FileParts against an allow-list of MIME types.
+type fileTypePolicy struct {
+	allowed []string                 // Normalized types; "type/*" matches a whole family.
+	detect  func(data []byte) string // Sniffs the MIME type of file content.
+}
+
+// normalizeMediaType lowercases t and strips its parameters. It returns "" if t is malformed.
+func normalizeMediaType(t string) string {
+	mediaType, _, err := mime.ParseMediaType(t)
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// allows reports whether mediaType is on the allow-list.
+func (p *fileTypePolicy) allows(mediaType string) bool {
+	for _, allowed := range p.allowed {
+		if allowed == mediaType {
+			return true
+		}
+		if family, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, family+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns an error if file is not of an allowed MIME type.
+// Inline content is sniffed, and its declared type must agree with what was
+// detected; the only refinement accepted is declaring a text format for
+// content detected as plain text. Files sent by URI cannot be sniffed, so
+// they need an allowed declared type.
+func (p *fileTypePolicy) check(file protocol.FileContent) error {
+	var declared string
+	if file.MimeType != nil && *file.MimeType != "" {
+		if declared = normalizeMediaType(*file.MimeType); declared == "" {
+			return fmt.Errorf("malformed MIME type %q", *file.MimeType)
+		}
+	}
+	if file.Bytes == nil {
+		if declared == "" {
+			return errors.New("file sent by URI must declare its MIME type")
+		}
+		if !p.allows(declared) {
+			return fmt.Errorf("file type %q is not allowed", declared)
+		}
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(*file.Bytes)
+	if err != nil {
+		return fmt.Errorf("file bytes are not valid base64: %w", err)
+	}
+	detected := normalizeMediaType(p.detect(data))
+	if detected == "" {
+		detected = "application/octet-stream"
+	}
+	actual := detected
+	if declared != "" && declared != detected {
+		isText := strings.HasPrefix(declared, "text/") || textMediaTypes[declared]
+		if detected != "text/plain" || !isText {
+			return fmt.Errorf("declared file type %q does not match detected type %q", declared, detected)
+		}
+		actual = declared
+	}
+	if !p.allows(actual) {
+		return fmt.Errorf("file type %q is not allowed", actual)
+	}
+	return nil
+}
+
+// validateFileParts checks the FileParts of message against the policy.
+func (p *fileTypePolicy) validateFileParts(message protocol.Message) *jsonrpc.Error {
+	for i, part := range message.Parts {
+		filePart, ok := part.(protocol.FilePart)
+		if !ok {
+			continue
+		}
+		if err := p.check(filePart.File); err != nil {
+			name := ""
+			if filePart.File.Name != nil {
+				name = fmt.Sprintf(" (%q)", *filePart.File.Name)
+			}
+			return jsonrpc.ErrInvalidParams(fmt.Sprintf("message part %d%s: %v", i, name, err))
+		}
+	}
+	return nil
+}
+
+// validateFileTypes checks the files of a send request against WithAllowedFileTypes.
+func (s *A2AServer) validateFileTypes(message protocol.Message) *jsonrpc.Error {
+	if s.fileTypes == nil {
+		return nil
+	}
+	return s.fileTypes.validateFileParts(message)
+}
@@ -0,0 +1,151 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// idempotencyKey is the context key for the request's Idempotency-Key header.
+type idempotencyKey struct{}
+
+// errIdempotencyKeyReused is returned by acquire when a key is repeated with different params.
+var errIdempotencyKeyReused = errors.New("idempotency key was already used with different parameters")
+
+// idempotencyScope identifies an idempotency key together with the caller that sent it,
+// so that different callers never see each other's tasks.
+type idempotencyScope struct {
+	userID string // Empty for unauthenticated requests.
+	key    string
+}
+
+// newIdempotencyScope scopes key to the authenticated user in ctx, if any.
+func newIdempotencyScope(ctx context.Context, key string) idempotencyScope {
+	scope := idempotencyScope{key: key}
+	if user, ok := ctx.Value(auth.AuthUserKey).(*auth.User); ok && user != nil {
+		scope.userID = user.ID
+	}
+	return scope
+}
+
+// hashSendParams returns a digest of the request params, used to detect a key
+// being reused for a different request.
+func hashSendParams(params protocol.SendTaskParams) string {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// idempotencyEntry records the task created for an idempotency key.
+// done is closed once the first request for the key has finished.
+type idempotencyEntry struct {
+	done       chan struct{}
+	paramsHash string
+	taskID     string // Empty if the first request failed.
+	expiry     time.Time
+}
+
+// expiryItem is an entry in the expiry heap.
+type expiryItem struct {
+	scope idempotencyScope
+	entry *idempotencyEntry
+}
+
+// expiryHeap orders completed entries by expiry, earliest first.
+type expiryHeap []expiryItem
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].entry.expiry.Before(h[j].entry.expiry) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+// Push implements heap.Interface.
+func (h *expiryHeap) Push(x interface{}) { *h = append(*h, x.(expiryItem)) }
+
+// Pop implements heap.Interface.
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// idempotencyCache deduplicates tasks/send requests carrying the same idempotency key
+// from the same caller within a time window.
+type idempotencyCache struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	entries map[idempotencyScope]*idempotencyEntry
+	expiry  expiryHeap
+}
+
+// newIdempotencyCache creates a cache that remembers keys for the given window.
+func newIdempotencyCache(window time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		window:  window,
+		entries: make(map[idempotencyScope]*idempotencyEntry),
+	}
+}
+
+// acquire returns the entry for the key. If owner is true, the caller is the first
+// request for the key and must call complete; otherwise it should wait on entry.done.
+// It fails with errIdempotencyKeyReused if the key was first used with other params.
+func (c *idempotencyCache) acquire(
+	scope idempotencyScope,
+	paramsHash string,
+) (entry *idempotencyEntry, owner bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeExpired(time.Now())
+	if e, ok := c.entries[scope]; ok {
+		if e.paramsHash != paramsHash {
+			return nil, false, errIdempotencyKeyReused
+		}
+		return e, false, nil
+	}
+	e := &idempotencyEntry{done: make(chan struct{}), paramsHash: paramsHash}
+	c.entries[scope] = e
+	return e, true, nil
+}
+
+// removeExpired drops entries whose window has passed. The caller must hold c.mu.
+func (c *idempotencyCache) removeExpired(now time.Time) {
+	for len(c.expiry) > 0 && now.After(c.expiry[0].entry.expiry) {
+		item := heap.Pop(&c.expiry).(expiryItem)
+		if c.entries[item.scope] == item.entry {
+			delete(c.entries, item.scope)
+		}
+	}
+}
+
+// complete records the outcome of the first request for the key. Failed requests
+// are forgotten so that a retry can try again.
+func (c *idempotencyCache) complete(scope idempotencyScope, entry *idempotencyEntry, taskID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if taskID == "" {
+		delete(c.entries, scope)
+	} else {
+		entry.taskID = taskID
+		entry.expiry = time.Now().Add(c.window)
+		heap.Push(&c.expiry, expiryItem{scope: scope, entry: entry})
+	}
+	close(entry.done)
+}
@@ -0,0 +1,78 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// inputStreamer returns the task manager as a taskmanager.InputStreamer, and false
+// if it cannot take streamed input.
+func (s *A2AServer) inputStreamer() (taskmanager.InputStreamer, bool) {
+	streamer, ok := s.taskManager.(taskmanager.InputStreamer)
+	return streamer, ok
+}
+
+// handleTasksSendInput handles the tasks/sendInput method, delivering the input to
+// the processor of the running task and answering with the task.
+func (s *A2AServer) handleTasksSendInput(
+	ctx context.Context,
+	w http.ResponseWriter,
+	request jsonrpc.Request,
+	streamer taskmanager.InputStreamer,
+) {
+	var params protocol.TaskInputParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		s.writeJSONRPCError(w, request.ID, err)
+		return
+	}
+	if err := params.Validate(); err != nil {
+		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidParams(err))
+		return
+	}
+	if typeErr := s.validateFileTypes(params.Message); typeErr != nil {
+		s.writeJSONRPCError(w, request.ID, typeErr)
+		return
+	}
+	if sigErr := s.verifyMessageSignature(params.Message); sigErr != nil {
+		s.writeJSONRPCError(w, request.ID, sigErr)
+		return
+	}
+	if err := streamer.OnSendInput(ctx, params); err != nil {
+		var rpcErr *jsonrpc.Error
+		switch {
+		case errors.As(err, &rpcErr):
+		case errors.Is(err, taskmanager.ErrInputClosed):
+			rpcErr = jsonrpc.ErrInvalidRequest(err.Error())
+		default:
+			rpcErr = jsonrpc.ErrInternalError(err.Error())
+		}
+		log.Errorf("Error sending input to task %s: %v", params.ID, err)
+		s.writeJSONRPCError(w, request.ID, rpcErr)
+		return
+	}
+	task, err := s.taskManager.OnGetTask(ctx, protocol.TaskQueryParams{ID: params.ID})
+	if err != nil {
+		log.Errorf("Error getting task %s after input: %v", params.ID, err)
+		if rpcErr, ok := err.(*jsonrpc.Error); ok {
+			s.writeJSONRPCError(w, request.ID, rpcErr)
+		} else {
+			s.writeJSONRPCError(w, request.ID,
+				jsonrpc.ErrInternalError(fmt.Sprintf("failed to get task: %v", err)))
+		}
+		return
+	}
+	s.writeJSONRPCResponse(w, request.ID, task)
+}
@@ -0,0 +1,110 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+)
+
+// MethodHandler serves a custom JSON-RPC method registered with RegisterMethod. It
+// receives the raw params of the request and returns the result to encode, or an
+// error. Errors created with NewMethodError, or with the constructors of the A2A
+// errors such as NewTaskNotFoundError, are sent as they are; any other error is
+// reported as an internal error.
+type MethodHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// NewMethodError returns an error a MethodHandler can return to answer with the
+// given JSON-RPC error code, message and optional data.
+func NewMethodError(code int, message string, data interface{}) error {
+	return jsonrpc.NewError(code, message, data)
+}
+
+// NewTaskNotFoundError returns the A2A Task Not Found error (-32001) for taskID.
+func NewTaskNotFoundError(taskID string) error {
+	return jsonrpc.NewTaskNotFoundError(taskID)
+}
+
+// NewTaskNotCancelableError returns the A2A Task Not Cancelable error (-32002) for
+// taskID.
+func NewTaskNotCancelableError(taskID string) error {
+	return jsonrpc.NewTaskNotCancelableError(taskID)
+}
+
+// NewPushNotificationNotSupportedError returns the A2A Push Notification Not
+// Supported error (-32003).
+func NewPushNotificationNotSupportedError() error {
+	return jsonrpc.NewPushNotificationNotSupportedError()
+}
+
+// NewUnsupportedOperationError returns the A2A Unsupported Operation error (-32004)
+// for the named operation.
+func NewUnsupportedOperationError(operation string) error {
+	return jsonrpc.NewUnsupportedOperationError(operation)
+}
+
+// NewContentTypeNotSupportedError returns the A2A Content Type Not Supported error
+// (-32005) for the given MIME types.
+func NewContentTypeNotSupportedError(contentTypes ...string) error {
+	return jsonrpc.NewContentTypeNotSupportedError(contentTypes...)
+}
+
+// NewInvalidAgentResponseError returns the A2A Invalid Agent Response error
+// (-32006), with detail describing what is wrong with the response.
+func NewInvalidAgentResponseError(detail string) error {
+	return jsonrpc.NewInvalidAgentResponseError(detail)
+}
+
+// RegisterMethod serves the JSON-RPC method name with handler, next to the A2A
+// methods: requests go through the same endpoint, authentication, request limits
+// and params schemas, and errors are reported the same way. Names starting with
+// "tasks/" or "a2a/", which are kept for A2A methods, or "rpc.", which JSON-RPC
+// reserves, are rejected, as is a name registered before. The method is also
+// served by the agents added with RegisterAgent, and listed in their capabilities.
+//
+// RegisterMethod must be called before the server handles requests.
+func (s *A2AServer) RegisterMethod(name string, handler MethodHandler) error {
+	if handler == nil {
+		return errors.New("RegisterMethod requires a non-nil handler")
+	}
+	if name == "" || strings.HasPrefix(name, "tasks/") || strings.HasPrefix(name, "a2a/") ||
+		strings.HasPrefix(name, "rpc.") {
+		return fmt.Errorf("method name %q is reserved", name)
+	}
+	if _, exists := s.methods[name]; exists {
+		return fmt.Errorf("method %q is already registered", name)
+	}
+	s.methods[name] = handler
+	return nil
+}
+
+// handleCustomMethod serves a request for a method registered with RegisterMethod.
+func (s *A2AServer) handleCustomMethod(
+	ctx context.Context,
+	w http.ResponseWriter,
+	request jsonrpc.Request,
+	handler MethodHandler,
+) {
+	result, err := handler(ctx, request.Params)
+	if err != nil {
+		var rpcErr *jsonrpc.Error
+		if !errors.As(err, &rpcErr) {
+			log.Errorf("Error handling method %s (Request ID: %v): %v", request.Method, request.ID, err)
+			rpcErr = jsonrpc.ErrInternalError(err.Error())
+		}
+		s.writeJSONRPCError(w, request.ID, rpcErr)
+		return
+	}
+	s.writeJSONRPCResponse(w, request.ID, result)
+}
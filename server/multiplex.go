@@ -0,0 +1,177 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/sse"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/textchunk"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// maxSubscribedTasks caps the tasks of a single tasks/subscribeMultiple request.
+const maxSubscribedTasks = 100
+
+// handleTasksSubscribeMultiple handles the tasks/subscribeMultiple method, streaming
+// the events of several tasks, each tagged with its task ID, over one SSE stream.
+// The stream closes once every task reached a final state.
+func (s *A2AServer) handleTasksSubscribeMultiple(
+	ctx context.Context,
+	w http.ResponseWriter,
+	request jsonrpc.Request,
+) {
+	var params protocol.TaskIDsParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		s.writeJSONRPCError(w, request.ID, err)
+		return
+	}
+	ids, rpcErr := uniqueTaskIDs(params.IDs)
+	if rpcErr != nil {
+		s.writeJSONRPCError(w, request.ID, rpcErr)
+		return
+	}
+
+	// Ensure client is accepting SSE.
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Error("Streaming is not supported by the underlying http responseWriter")
+		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInternalError("server does not support streaming"))
+		return
+	}
+
+	// Subscriptions opened before a failing one end with this context.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	channels := make([]<-chan protocol.TaskEvent, 0, len(ids))
+	for _, id := range ids {
+		eventsChan, err := s.taskManager.OnResubscribe(ctx, protocol.TaskIDParams{ID: id})
+		if err != nil {
+			log.Errorf("Error calling OnResubscribe for task %s: %v", id, err)
+			if rpcErr, ok := err.(*jsonrpc.Error); ok {
+				s.writeJSONRPCError(w, request.ID, rpcErr)
+			} else {
+				s.writeJSONRPCError(w, request.ID,
+					jsonrpc.ErrInternalError(fmt.Sprintf("failed to subscribe to task %s events: %v", id, err)))
+			}
+			return
+		}
+		channels = append(channels, eventsChan)
+	}
+
+	s.startSSEStream(w, flusher)
+	taskIDs := strings.Join(ids, ",")
+	log.Infof("SSE stream opened for tasks %s (Request ID: %v)", taskIDs, request.ID)
+
+	filter := streamEventFilterFromContext(ctx)
+	// Artifact chunks of different tasks interleave, so each task keeps its own carry.
+	runeCarries := make(map[string]*textchunk.Carry)
+	eventsChan := fanInTaskEvents(ctx, channels)
+	for {
+		select {
+		case event, ok := <-eventsChan:
+			if !ok {
+				log.Infof("SSE stream closing for tasks %s (all tasks ended)", taskIDs)
+				s.writeSSECloseEvent(w, flusher, taskIDs, request.ID)
+				return
+			}
+			runeCarry, ok := runeCarries[event.TaskID()]
+			if !ok {
+				runeCarry = textchunk.NewCarry()
+				runeCarries[event.TaskID()] = runeCarry
+			}
+			event, eventType, _ := prepareSSEEvent(event, runeCarry)
+			if eventType == "" {
+				log.Warnf("Unknown event type received for task %s: %T. Skipping.", event.TaskID(), event)
+				continue
+			}
+			// The stream ends when all tasks are done, so a filtered final status is simply dropped.
+			if !filter.Allows(event) {
+				continue
+			}
+			s.extendWriteDeadline(w)
+			if err := sse.FormatJSONRPCEvent(w, eventType, request.ID, s.formatTimes(event)); err != nil {
+				log.Errorf("Error writing SSE JSON-RPC event for tasks %s (client likely disconnected): %v. "+
+					"Closing stream.", taskIDs, err)
+				return
+			}
+			flusher.Flush()
+		case <-ctx.Done():
+			log.Infof("SSE client disconnected for tasks %s (Request ID: %v). Closing stream.",
+				taskIDs, request.ID)
+			return
+		}
+	}
+}
+
+// uniqueTaskIDs validates the task IDs of a tasks/subscribeMultiple request and
+// removes duplicates, keeping the order of first appearance.
+func uniqueTaskIDs(ids []string) ([]string, *jsonrpc.Error) {
+	if len(ids) == 0 {
+		return nil, jsonrpc.ErrInvalidParams("at least one task ID is required")
+	}
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			return nil, jsonrpc.ErrInvalidParams("task IDs must not be empty")
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > maxSubscribedTasks {
+		return nil, jsonrpc.ErrInvalidParams(
+			fmt.Sprintf("at most %d tasks can be subscribed to at once, got %d", maxSubscribedTasks, len(unique)))
+	}
+	return unique, nil
+}
+
+// fanInTaskEvents merges the event channels of several tasks into one. Each task
+// contributes events until its channel closes or it sends its final status, and
+// the merged channel closes once every task is done or ctx ends.
+func fanInTaskEvents(ctx context.Context, channels []<-chan protocol.TaskEvent) <-chan protocol.TaskEvent {
+	// Buffer one event per task so a slow writer does not hold up every forwarder.
+	merged := make(chan protocol.TaskEvent, len(channels))
+	var wg sync.WaitGroup
+	for _, eventsChan := range channels {
+		wg.Add(1)
+		go func(eventsChan <-chan protocol.TaskEvent) {
+			defer wg.Done()
+			for {
+				select {
+				case event, ok := <-eventsChan:
+					if !ok {
+						return
+					}
+					select {
+					case merged <- event:
+					case <-ctx.Done():
+						return
+					}
+					if e, ok := event.(protocol.TaskStatusUpdateEvent); ok && (e.Final || e.Status.State.IsFinal()) {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(eventsChan)
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+	return merged
+}
@@ -7,15 +7,20 @@
 package server
 
 import (
+	"encoding/json"
+	"net/http"
 	"time"
 
 	"trpc.group/trpc-go/trpc-a2a-go/auth"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
 
 const (
-	defaultReadTimeout  = 10 * time.Second
-	defaultWriteTimeout = 10 * time.Second
-	defaultIdleTimeout  = 60 * time.Second
+	defaultReadTimeout       = 10 * time.Second
+	defaultWriteTimeout      = 10 * time.Second
+	defaultIdleTimeout       = 60 * time.Second
+	defaultIdempotencyWindow = 10 * time.Minute
+	defaultMaxTaskWait       = 30 * time.Second
 )
 
 // Option is a function that configures the A2AServer.
@@ -36,6 +41,16 @@ func WithJSONRPCEndpoint(path string) Option {
 	}
 }
 
+// WithStrictJSONRPC controls validation of the jsonrpc version and request id.
+// When enabled (the default), requests whose jsonrpc field is not "2.0" or whose id
+// is not a string, integer or null are rejected with an invalid request error.
+// Disable it to accept requests from legacy clients.
+func WithStrictJSONRPC(enabled bool) Option {
+	return func(s *A2AServer) {
+		s.strictJSONRPC = enabled
+	}
+}
+
 // WithReadTimeout sets the read timeout for the HTTP server.
 func WithReadTimeout(timeout time.Duration) Option {
 	return func(s *A2AServer) {
@@ -57,6 +72,173 @@ func WithIdleTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithDefaultLocale sets the BCP 47 locale passed to the processor when a request
+// carries neither a locale nor an Accept-Language header. Default is "en".
+// Malformed tags are ignored.
+func WithDefaultLocale(locale string) Option {
+	return func(s *A2AServer) {
+		if protocol.ValidateLocale(locale) == nil {
+			s.defaultLocale = locale
+		}
+	}
+}
+
+// WithIdempotencyWindow sets how long the server remembers Idempotency-Key headers on
+// tasks/send requests. A key repeated by the same authenticated user within the window
+// returns the existing task instead of starting a new one; a repeat with different
+// params is rejected as invalid. Default is 10 minutes; zero disables deduplication.
+func WithIdempotencyWindow(window time.Duration) Option {
+	return func(s *A2AServer) {
+		if window >= 0 {
+			s.idempotencyWindow = window
+		}
+	}
+}
+
+// WithTaskIDValidator sets a function that checks client-supplied task IDs on
+// tasks/send and tasks/sendSubscribe. Requests whose ID fails validation are
+// rejected with an invalid params error.
+func WithTaskIDValidator(validator func(id string) error) Option {
+	return func(s *A2AServer) {
+		s.taskIDValidator = validator
+	}
+}
+
+// WithMessageVerificationKey requires the message of every tasks/send and
+// tasks/sendSubscribe request to carry a signature made with the private key
+// matching key, as produced by protocol.SignMessage. Unsigned messages and
+// messages whose signature does not verify are rejected with an invalid params
+// error. key is an *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey.
+func WithMessageVerificationKey(key interface{}) Option {
+	return func(s *A2AServer) {
+		s.messageKey = key
+	}
+}
+
+// WithDebugEndpoint serves the agent's registered skills, supported JSON-RPC methods
+// and non-secret configuration as JSON on GET requests to path.
+// The endpoint is only registered when an auth provider is set, and requires authentication.
+func WithDebugEndpoint(path string) Option {
+	return func(s *A2AServer) {
+		s.debugEndpoint = path
+	}
+}
+
+// WithAgentCardSigner signs the agent card with key so clients can verify its
+// authenticity. The signed card is served as a compact JWS to clients that accept
+// application/jose; others still receive the plain JSON card.
+// See protocol.SignAgentCard for the supported key types.
+func WithAgentCardSigner(key interface{}) Option {
+	return func(s *A2AServer) {
+		s.agentCardSigner = key
+	}
+}
+
+// WithWorkerPool bounds tasks/send and tasks/sendSubscribe requests to size running
+// at once. A streaming request holds its worker until the stream ends, since the
+// processor runs for the life of the stream.
+// Use WithWorkerQueue to size the queue and choose what happens when it is full.
+func WithWorkerPool(size int) Option {
+	return func(s *A2AServer) {
+		if size > 0 {
+			s.workerPoolSize = size
+		}
+	}
+}
+
+// WithWorkerQueue sets how many tasks may wait for a worker and the policy applied once
+// the queue is full. The queue defaults to the pool size with QueuePolicyBlock.
+// It has no effect without WithWorkerPool.
+func WithWorkerQueue(size int, policy QueuePolicy) Option {
+	return func(s *A2AServer) {
+		if size >= 0 {
+			s.workerQueueSize = size
+		}
+		s.workerQueuePolicy = policy
+	}
+}
+
+// WithMaxQueueDepth caps at n the tasks waiting to start, for a worker of
+// WithWorkerPool or for Resume after Pause. Tasks sent while n are waiting are
+// rejected with an ErrCodeServerBusy error and a Retry-After header instead of
+// waiting, so sustained overload cannot pile up work the server cannot handle.
+// QueueStats counts the rejections. Zero, the default, leaves the queue unlimited.
+func WithMaxQueueDepth(n int) Option {
+	return func(s *A2AServer) {
+		if n >= 0 {
+			s.maxQueueDepth = n
+		}
+	}
+}
+
+// WithFairScheduling makes the worker pool give free workers to waiting tasks one
+// session at a time, in turn, rather than in arrival order, so a session flooding
+// the server with tasks cannot starve other sessions. Tasks of one session still
+// run in arrival order; tasks without a session ID count as sessions of their own.
+// It has no effect without WithWorkerPool.
+func WithFairScheduling() Option {
+	return func(s *A2AServer) {
+		s.fairScheduling = true
+	}
+}
+
+// WithCancelOnDisconnect cancels a task started with tasks/sendSubscribe, with
+// protocol.CancelReasonClientDisconnected, when the client closes the stream
+// before the task reaches a final state, so it stops using resources for a client
+// that is gone. Streams reopened with tasks/resubscribe do not cancel the task.
+// It is off by default, for tasks that should outlive their client.
+func WithCancelOnDisconnect() Option {
+	return func(s *A2AServer) {
+		s.cancelOnDisconnect = true
+	}
+}
+
+// WithErrorVerbosity sets how much detail JSON-RPC error responses of every method
+// carry in their data field. The default, ErrorVerbosityTerse, is safe for
+// production; use ErrorVerbosityVerbose in development to see the causes of
+// internal errors.
+func WithErrorVerbosity(level ErrorVerbosity) Option {
+	return func(s *A2AServer) {
+		s.errorVerbosity = level
+	}
+}
+
+// WithTimeFormat sets the format of the timestamps of the tasks and events in the
+// responses and stream events of every method, such as the status timestamp, for
+// clients that cannot parse the RFC 3339 timestamps the task managers record, for
+// example TimeFormatSeconds or TimeFormatEpochMillis. Timestamps in metadata and
+// DataParts are left as they are. Clients of this module parse any of these
+// formats, see protocol.ParseTime.
+func WithTimeFormat(format TimeFormat) Option {
+	return func(s *A2AServer) {
+		s.timeFormat = format
+	}
+}
+
+// WithBodyLogging logs the body of every JSON-RPC request and response, and every
+// SSE event, at debug level for deep debugging. The values of fields such as token,
+// credentials or password are redacted, but bodies may still carry personal data,
+// so keep it off, the default, in production.
+func WithBodyLogging() Option {
+	return func(s *A2AServer) {
+		s.logBodies = true
+	}
+}
+
+// WithTaskRetention deletes tasks that reached a final state more than ttl ago.
+// While Start is serving, a background sweeper runs every ttl or every minute,
+// whichever is shorter; see SweepTasks for servers used through Handler.
+// If onEvict is non-nil it is called with each task before deletion, e.g. to
+// archive it. The task manager must implement taskmanager.TaskPruner.
+func WithTaskRetention(ttl time.Duration, onEvict func(protocol.Task)) Option {
+	return func(s *A2AServer) {
+		if ttl > 0 {
+			s.taskRetentionTTL = ttl
+			s.onTaskEvict = onEvict
+		}
+	}
+}
+
 // WithAuthProvider sets the authentication provider for the server.
 // If not set, the server will not require authentication.
 func WithAuthProvider(provider auth.Provider) Option {
@@ -76,3 +258,254 @@ func WithJWKSEndpoint(enabled bool, path string) Option {
 		}
 	}
 }
+
+// WithAllowedFileTypes restricts the files clients may send in FileParts to the
+// given MIME types; "image/*" allows a whole family. Inline file content is
+// sniffed rather than trusted: a declared type that disagrees with the content
+// is rejected, as is content of a type not on the list. Files sent by URI are
+// checked by their declared type. Rejected requests get an invalid params error.
+// Use WithFileTypeDetector to replace the content sniffer.
+func WithAllowedFileTypes(types ...string) Option {
+	return func(s *A2AServer) {
+		if s.fileTypes == nil {
+			s.fileTypes = &fileTypePolicy{detect: http.DetectContentType}
+		}
+		for _, t := range types {
+			if mediaType := normalizeMediaType(t); mediaType != "" {
+				s.fileTypes.allowed = append(s.fileTypes.allowed, mediaType)
+			}
+		}
+	}
+}
+
+// WithFileTypeDetector sets the function that detects the MIME type of file content
+// for WithAllowedFileTypes. Default is http.DetectContentType.
+// It has no effect without WithAllowedFileTypes.
+func WithFileTypeDetector(detect func(data []byte) string) Option {
+	return func(s *A2AServer) {
+		if detect != nil {
+			s.fileTypeDetector = detect
+		}
+	}
+}
+
+// WithSkillRouter routes the messages of tasks/send and tasks/sendSubscribe sent
+// without a skill, under protocol.MetadataKeySkill, to the skill of the agent card
+// picked by router, for example by the types of their parts. The skill is set in
+// the message metadata before the task manager sees the message, see
+// protocol.SkillID. Messages for which router fails, or picks a skill not on the
+// agent card, are rejected with an invalid params error.
+func WithSkillRouter(router SkillRouter) Option {
+	return func(s *A2AServer) {
+		s.skillRouter = router
+	}
+}
+
+// WithAdmissionWebhook makes tasks/send and tasks/sendSubscribe requests, which
+// create or continue a task, wait for the approval of an external authorization
+// service: the server POSTs an AdmissionRequest to url and honors the
+// AdmissionResponse, admitting the request, possibly with changed params or added
+// metadata, or denying it with an ErrCodeAdmissionDenied error. Changed params
+// must pass the checks of the request again, such as WithAllowedFileTypes and
+// WithParamSchema, or the request is denied. A call taking longer than timeout,
+// 5 seconds if zero, fails. Requests are denied when the webhook fails, unless
+// WithAdmissionFailOpen is set.
+func WithAdmissionWebhook(url string, timeout time.Duration) Option {
+	return func(s *A2AServer) {
+		if timeout <= 0 {
+			timeout = defaultAdmissionTimeout
+		}
+		s.admission = &admissionWebhook{url: url, client: &http.Client{Timeout: timeout}}
+	}
+}
+
+// WithAdmissionFailOpen admits requests when the admission webhook of
+// WithAdmissionWebhook fails, for example when it cannot be reached, instead of
+// denying them.
+func WithAdmissionFailOpen() Option {
+	return func(s *A2AServer) {
+		s.admissionFailOpen = true
+	}
+}
+
+// WithArtifactContentEndpoint serves the content of artifacts over plain HTTP GET
+// at path/{taskID}/{index}, see ArtifactContentPath: the inline bytes of the file
+// parts of the artifact with index of the task. Range requests are answered with
+// 206 Partial Content, so clients can resume interrupted downloads, see
+// client.A2AClient.DownloadArtifact. Content is served as a sandboxed attachment,
+// see protocol.MetadataKeyInlineContent. The endpoint is behind the auth provider,
+// if any, and serves the tasks of the server, not those of agents added with
+// RegisterAgent.
+func WithArtifactContentEndpoint(path string) Option {
+	return func(s *A2AServer) {
+		s.artifactEndpoint = path
+	}
+}
+
+// WithStrictCardURL refuses to serve an agent card whose URL does not match where
+// the server is reached: Start fails when the card URL does not have the scheme,
+// host, port and JSON-RPC endpoint path of the address it listens on. Behind a
+// reverse proxy, set the URL clients use with WithExternalURL, which NewA2AServer
+// then checks the card URL against. Without this option a mismatch is only logged.
+func WithStrictCardURL() Option {
+	return func(s *A2AServer) {
+		s.strictCardURL = true
+	}
+}
+
+// WithExternalURL sets the URL clients reach the server at, such as the URL of a
+// reverse proxy in front of it, for checking the agent card URL against instead of
+// the listen address, see WithStrictCardURL. The card URL must have the origin of
+// externalURL and its path followed by the JSON-RPC endpoint path.
+func WithExternalURL(externalURL string) Option {
+	return func(s *A2AServer) {
+		s.externalURL = externalURL
+	}
+}
+
+// WithMaxJSONDepth limits how deeply objects and arrays may nest in a request body.
+// Deeper requests are rejected with a parse error before they are decoded, which
+// keeps hostile payloads from exhausting the stack or CPU of the decoder.
+// Default is 64; zero disables the limit.
+func WithMaxJSONDepth(depth int) Option {
+	return func(s *A2AServer) {
+		if depth >= 0 {
+			s.maxJSONDepth = depth
+		}
+	}
+}
+
+// WithMaxRequestSize limits the size in bytes of a JSON-RPC request body.
+// Larger requests are rejected with an invalid request error. Default is no limit.
+func WithMaxRequestSize(size int64) Option {
+	return func(s *A2AServer) {
+		if size >= 0 {
+			s.maxRequestSize = size
+		}
+	}
+}
+
+// WithMaxBatchSize limits the number of tasks of a tasks/sendBatch request, at most
+// protocol.MaxSendBatchSize, which is the default. A larger batch is rejected as a
+// whole with an invalid request error, before any of its tasks is sent.
+func WithMaxBatchSize(n int) Option {
+	return func(s *A2AServer) {
+		if n > 0 && n <= protocol.MaxSendBatchSize {
+			s.maxBatchSize = n
+		}
+	}
+}
+
+// WithMaxBatchBytes limits the size in bytes of the tasks of a tasks/sendBatch
+// request, their encoded params together. A larger batch is rejected as a whole
+// with an invalid request error before it is decoded. Default is no limit.
+func WithMaxBatchBytes(size int64) Option {
+	return func(s *A2AServer) {
+		if size >= 0 {
+			s.maxBatchBytes = size
+		}
+	}
+}
+
+// WithParamSchema validates the params of requests for method against a JSON Schema
+// before the method is handled. Requests that do not match are rejected with an
+// invalid params error whose data lists the violations as ParamViolation values.
+// Omitted params are validated as null. NewA2AServer fails if the schema is invalid.
+func WithParamSchema(method string, schema json.RawMessage) Option {
+	return func(s *A2AServer) {
+		if s.rawParamSchemas == nil {
+			s.rawParamSchemas = make(map[string]json.RawMessage)
+		}
+		s.rawParamSchemas[method] = schema
+	}
+}
+
+// WithMaxHistoryBytes caps the JSON-encoded size of the message history kept for
+// each task at n bytes. Once a new message would exceed the cap the oldest messages
+// are evicted; a single message larger than n is not stored in the history at all,
+// though it is still processed. The task manager must implement
+// taskmanager.HistoryLimiter. Default is no cap.
+func WithMaxHistoryBytes(n int) Option {
+	return func(s *A2AServer) {
+		if n >= 0 {
+			s.maxHistoryBytes = n
+		}
+	}
+}
+
+// WithProcessorTimeout bounds how long a task processor may run before the task is
+// failed with a timeout error and the processor's context is cancelled with
+// taskmanager.ErrProcessorTimeout. For streaming tasks the timeout restarts on each
+// event the task emits, so only a processor that goes quiet for d is stopped.
+// The task manager must implement taskmanager.ProcessorLimiter. Default is no bound.
+func WithProcessorTimeout(d time.Duration) Option {
+	return func(s *A2AServer) {
+		if d >= 0 {
+			s.processorTimeout = d
+		}
+	}
+}
+
+// WithMaxArtifactsPerTask caps the distinct artifacts, by index, a task may
+// produce at n. An artifact past the cap is rejected with
+// taskmanager.ErrTooManyArtifacts and fails the task, so no further artifacts are
+// accepted. Streaming appends to an existing artifact don't count. The task manager
+// must implement taskmanager.ArtifactLimiter. Default is no cap.
+func WithMaxArtifactsPerTask(n int) Option {
+	return func(s *A2AServer) {
+		if n >= 0 {
+			s.maxArtifacts = n
+		}
+	}
+}
+
+// WithSlowClientTimeout applies backpressure to streaming clients that read
+// slower than their task emits events. Once a client's event buffer is full, the
+// processor's emit call blocks until the client catches up, for up to d, and a
+// client that still has no room is dropped. A write to an SSE stream that makes no
+// progress for d closes the stream. This replaces the server's write timeout for
+// SSE streams. The task manager must implement taskmanager.BackpressureLimiter.
+// Default is to drop the events a slow client has no room for.
+func WithSlowClientTimeout(d time.Duration) Option {
+	return func(s *A2AServer) {
+		if d >= 0 {
+			s.slowClientTimeout = d
+		}
+	}
+}
+
+// WithRawDataParts keeps the payload of each DataPart of at least minSize bytes in
+// tasks/send and tasks/sendSubscribe messages as its raw json.RawMessage, instead
+// of decoding it into maps and slices, which take several times the memory.
+// Processors read such payloads element by element with protocol.NewDataDecoder,
+// which also accepts decoded payloads. Compressed payloads are always decoded.
+// A minSize of zero disables it, which is the default.
+func WithRawDataParts(minSize int) Option {
+	return func(s *A2AServer) {
+		if minSize >= 0 {
+			s.rawDataMinSize = minSize
+		}
+	}
+}
+
+// WithTaskSnapshots serves the tasks/export and tasks/import methods, which export
+// a task with its history and artifacts as a protocol.TaskSnapshot and import one,
+// for migrating tasks between servers. The methods are only served when an auth
+// provider is set. The task manager must implement taskmanager.TaskSnapshotter.
+func WithTaskSnapshots() Option {
+	return func(s *A2AServer) {
+		s.taskSnapshots = true
+	}
+}
+
+// WithMaxTaskWait caps how long a long-poll tasks/get request (one with waitMs set)
+// is held waiting for the task's status to change. The wait is also kept below the
+// write timeout so the task can still be written. Default is 30 seconds; zero
+// disables long polling, answering such requests immediately.
+func WithMaxTaskWait(wait time.Duration) Option {
+	return func(s *A2AServer) {
+		if wait >= 0 {
+			s.maxTaskWait = wait
+		}
+	}
+}
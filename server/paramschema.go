@@ -0,0 +1,90 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+)
+
+// ParamViolation describes one way request params failed their method's schema.
+// It is the element type of the data of the invalid params error.
+type ParamViolation struct {
+	// Location is the JSON pointer of the offending value within params.
+	Location string `json:"location"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// compileParamSchemas compiles the schemas registered with WithParamSchema.
+func compileParamSchemas(raw map[string]json.RawMessage) (map[string]*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	methods := make([]string, 0, len(raw))
+	for method := range raw {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	schemas := make(map[string]*jsonschema.Schema, len(raw))
+	for _, method := range methods {
+		url := "a2a://params/" + method
+		if err := compiler.AddResource(url, bytes.NewReader(raw[method])); err != nil {
+			return nil, fmt.Errorf("invalid params schema for %s: %w", method, err)
+		}
+		schema, err := compiler.Compile(url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid params schema for %s: %w", method, err)
+		}
+		schemas[method] = schema
+	}
+	return schemas, nil
+}
+
+// validateParams checks the params of request against the schema of its method, if any.
+// Omitted params are validated as null.
+func (s *A2AServer) validateParams(request jsonrpc.Request) *jsonrpc.Error {
+	schema, ok := s.paramSchemas[request.Method]
+	if !ok {
+		return nil
+	}
+	var params interface{}
+	if len(request.Params) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(request.Params))
+		decoder.UseNumber() // Keep numbers exact for integer and bound checks.
+		if err := decoder.Decode(&params); err != nil {
+			return jsonrpc.ErrInvalidParams(fmt.Sprintf("failed to parse params: %v", err))
+		}
+	}
+	err := schema.Validate(params)
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return jsonrpc.ErrInvalidParams(err.Error())
+	}
+	return jsonrpc.ErrInvalidParams(paramViolations(validationErr))
+}
+
+// paramViolations flattens a validation error into its leaf causes, which name
+// the specific values at fault rather than the schemas containing them.
+func paramViolations(err *jsonschema.ValidationError) []ParamViolation {
+	if len(err.Causes) == 0 {
+		return []ParamViolation{{Location: err.InstanceLocation, Message: err.Message}}
+	}
+	var violations []ParamViolation
+	for _, cause := range err.Causes {
+		violations = append(violations, paramViolations(cause)...)
+	}
+	return violations
+}
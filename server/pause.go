@@ -0,0 +1,106 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+)
+
+// errServerPaused is returned for a task whose request ended while it waited for
+// the server to resume.
+var errServerPaused = errors.New("server is paused")
+
+// pauseGate holds new tasks back while the server is paused.
+type pauseGate struct {
+	mu      sync.Mutex
+	resumed chan struct{} // Closed by resume; nil while not paused.
+	waiting int           // Tasks waiting for resume.
+}
+
+// pause makes wait block until resume and reports whether the gate was open.
+func (g *pauseGate) pause() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed != nil {
+		return false
+	}
+	g.resumed = make(chan struct{})
+	return true
+}
+
+// resume releases the waiting tasks and reports whether the gate was paused.
+func (g *pauseGate) resume() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed == nil {
+		return false
+	}
+	close(g.resumed)
+	g.resumed = nil
+	return true
+}
+
+// wait returns once the gate is open, or an errServerPaused error if ctx is done
+// first.
+func (g *pauseGate) wait(ctx context.Context) error {
+	g.mu.Lock()
+	resumed := g.resumed
+	if resumed == nil {
+		g.mu.Unlock()
+		return nil
+	}
+	g.waiting++
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.waiting--
+		g.mu.Unlock()
+	}()
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", errServerPaused, ctx.Err())
+	}
+}
+
+// state reports whether the gate is paused and how many tasks wait for it.
+func (g *pauseGate) state() (paused bool, waiting int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.resumed != nil, g.waiting
+}
+
+// Pause stops the server, and the agents registered on it, from starting tasks,
+// for example during a maintenance window. Tasks sent while paused wait for Resume
+// until their request's context is done, when they are rejected with a server busy
+// error; tasks already running are not affected. Pause does nothing if the server
+// is already paused.
+func (s *A2AServer) Pause() {
+	if s.pause.pause() {
+		log.Info("A2A server paused: new tasks wait for Resume")
+	}
+}
+
+// Resume lets the server start tasks again after Pause, starting the tasks that
+// waited for it.
+func (s *A2AServer) Resume() {
+	if s.pause.resume() {
+		log.Info("A2A server resumed")
+	}
+}
+
+// Paused reports whether the server is paused.
+func (s *A2AServer) Paused() bool {
+	paused, _ := s.pause.state()
+	return paused
+}
@@ -0,0 +1,74 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// queueRetryAfter is the Retry-After hint sent with tasks rejected because the
+// server had no room for them.
+const queueRetryAfter = time.Second
+
+// errTaskQueueFull is returned for a task submitted while WithMaxQueueDepth tasks
+// are already waiting to start.
+var errTaskQueueFull = errors.New("task queue is full")
+
+// QueueStats reports the tasks waiting to start and the tasks rejected for lack of
+// room.
+type QueueStats struct {
+	// Depth is the number of tasks waiting for a worker or for Resume.
+	Depth int64 `json:"depth"`
+	// MaxDepth is the limit set with WithMaxQueueDepth, zero if unlimited.
+	MaxDepth int64 `json:"maxDepth,omitempty"`
+	// Rejected is the number of tasks rejected because the queue, or the worker
+	// pool queue under QueuePolicyReject, was full.
+	Rejected int64 `json:"rejected"`
+}
+
+// taskQueue counts the tasks waiting to start, rejecting those over maxDepth.
+type taskQueue struct {
+	maxDepth int64 // Zero is unlimited.
+	depth    atomic.Int64
+	rejected atomic.Int64
+}
+
+// enter adds a task to the queue and returns the function removing it once it
+// starts or gives up, or an errTaskQueueFull error if the queue is full.
+func (q *taskQueue) enter() (func(), error) {
+	if depth := q.depth.Add(1); q.maxDepth > 0 && depth > q.maxDepth {
+		q.depth.Add(-1)
+		return nil, fmt.Errorf("%w: %d tasks are waiting", errTaskQueueFull, q.maxDepth)
+	}
+	return func() { q.depth.Add(-1) }, nil
+}
+
+// stats returns a snapshot of the queue counters.
+func (q *taskQueue) stats() QueueStats {
+	return QueueStats{Depth: q.depth.Load(), MaxDepth: q.maxDepth, Rejected: q.rejected.Load()}
+}
+
+// QueueStats returns the task queue counters. The queue is shared with the agents
+// registered on the server.
+func (s *A2AServer) QueueStats() QueueStats {
+	return s.queue.stats()
+}
+
+// writeServerBusy answers a task the server did not start because of err. Tasks
+// rejected for lack of room are counted and told when to retry.
+func (s *A2AServer) writeServerBusy(w http.ResponseWriter, id interface{}, err error) {
+	if errors.Is(err, errTaskQueueFull) || errors.Is(err, errWorkerPoolFull) {
+		s.queue.rejected.Add(1)
+		w.Header().Set("Retry-After", strconv.Itoa(int(queueRetryAfter/time.Second)))
+	}
+	s.writeJSONRPCError(w, id, errServerBusy(err))
+}
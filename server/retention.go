@@ -0,0 +1,182 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// maxRetentionSweepInterval caps how long the sweeper waits between sweeps.
+const maxRetentionSweepInterval = time.Minute
+
+// TaskRetentionStats reports what the task retention sweeper has done so far.
+type TaskRetentionStats struct {
+	// Sweeps is the number of completed sweeps.
+	Sweeps int64 `json:"sweeps"`
+	// Evicted is the number of tasks deleted across all sweeps.
+	Evicted int64 `json:"evicted"`
+	// Errors is the number of sweeps that failed.
+	Errors int64 `json:"errors"`
+	// LastSweep is when the last sweep finished; zero if none has.
+	LastSweep time.Time `json:"lastSweep"`
+	// Paused reports whether background sweeps are paused.
+	Paused bool `json:"paused"`
+}
+
+// taskRetention deletes tasks that finished more than ttl ago on a schedule.
+type taskRetention struct {
+	pruner  taskmanager.TaskPruner
+	ttl     time.Duration
+	onEvict func(protocol.Task)
+
+	now       func() time.Time                                 // Clock, replaced in tests.
+	newTicker func(d time.Duration) (<-chan time.Time, func()) // Sweep schedule, replaced in tests.
+
+	paused    atomic.Bool
+	sweeps    atomic.Int64
+	evicted   atomic.Int64
+	failures  atomic.Int64
+	lastSweep atomic.Int64 // Unix nanoseconds of the last finished sweep.
+
+	mu   sync.Mutex    // Guards stop and done.
+	stop chan struct{} // Closed to stop the background loop; nil when not running.
+	done chan struct{} // Closed once the background loop has exited.
+}
+
+// newTaskRetention creates a sweeper for pruner. It does not start it.
+func newTaskRetention(pruner taskmanager.TaskPruner, ttl time.Duration, onEvict func(protocol.Task)) *taskRetention {
+	return &taskRetention{
+		pruner:  pruner,
+		ttl:     ttl,
+		onEvict: onEvict,
+		now:     time.Now,
+		newTicker: func(d time.Duration) (<-chan time.Time, func()) {
+			ticker := time.NewTicker(d)
+			return ticker.C, ticker.Stop
+		},
+	}
+}
+
+// interval returns the time between background sweeps.
+func (r *taskRetention) interval() time.Duration {
+	return min(r.ttl, maxRetentionSweepInterval)
+}
+
+// start runs sweeps in the background until stopped. Starting twice is a no-op.
+func (r *taskRetention) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	ticks, stopTicker := r.newTicker(r.interval())
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		defer stopTicker()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticks:
+				if r.paused.Load() {
+					continue
+				}
+				if _, err := r.sweep(context.Background()); err != nil {
+					log.Errorf("Task retention sweep failed: %v", err)
+				}
+			}
+		}
+	}(r.stop, r.done)
+}
+
+// close stops the background loop, waiting for a sweep in progress to finish.
+func (r *taskRetention) close() {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// sweep deletes the tasks that finished more than ttl ago and records the outcome.
+func (r *taskRetention) sweep(ctx context.Context) (int, error) {
+	evicted, err := r.pruner.PruneTasks(ctx, r.now().Add(-r.ttl), r.onEvict)
+	r.sweeps.Add(1)
+	r.evicted.Add(int64(evicted))
+	r.lastSweep.Store(r.now().UnixNano())
+	if err != nil {
+		r.failures.Add(1)
+		return evicted, err
+	}
+	if evicted > 0 {
+		log.Debugf("Task retention sweep evicted %d tasks", evicted)
+	}
+	return evicted, nil
+}
+
+// stats returns a snapshot of the sweeper's counters.
+func (r *taskRetention) stats() TaskRetentionStats {
+	stats := TaskRetentionStats{
+		Sweeps:  r.sweeps.Load(),
+		Evicted: r.evicted.Load(),
+		Errors:  r.failures.Load(),
+		Paused:  r.paused.Load(),
+	}
+	if last := r.lastSweep.Load(); last != 0 {
+		stats.LastSweep = time.Unix(0, last)
+	}
+	return stats
+}
+
+// SweepTasks deletes the tasks that finished longer ago than the retention
+// period set with WithTaskRetention, and returns how many were deleted.
+// The background sweeper only runs while Start is serving; servers used through
+// Handler can call SweepTasks on their own schedule instead.
+// It does nothing without WithTaskRetention, and runs even while sweeps are paused.
+func (s *A2AServer) SweepTasks(ctx context.Context) (int, error) {
+	if s.retention == nil {
+		return 0, nil
+	}
+	return s.retention.sweep(ctx)
+}
+
+// PauseTaskRetention stops background sweeps from deleting tasks until
+// ResumeTaskRetention is called.
+func (s *A2AServer) PauseTaskRetention() {
+	if s.retention != nil {
+		s.retention.paused.Store(true)
+	}
+}
+
+// ResumeTaskRetention resumes background sweeps paused by PauseTaskRetention.
+func (s *A2AServer) ResumeTaskRetention() {
+	if s.retention != nil {
+		s.retention.paused.Store(false)
+	}
+}
+
+// TaskRetentionStats returns the task retention counters. It returns the zero
+// value without WithTaskRetention.
+func (s *A2AServer) TaskRetentionStats() TaskRetentionStats {
+	if s.retention == nil {
+		return TaskRetentionStats{}
+	}
+	return s.retention.stats()
+}
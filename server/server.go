@@ -9,17 +9,27 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"mime"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
 	"trpc.group/trpc-go/trpc-a2a-go/auth"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonlimit"
 	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/redact"
 	"trpc.group/trpc-go/trpc-a2a-go/internal/sse"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/textchunk"
 	"trpc.group/trpc-go/trpc-a2a-go/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
@@ -43,6 +53,72 @@ type A2AServer struct {
 	pushAuth       *auth.PushNotificationAuthenticator // Push notification authenticator.
 	jwksEnabled    bool                                // Flag to enable/disable JWKS endpoint.
 	jwksEndpoint   string                              // Path for the JWKS endpoint.
+
+	defaultLocale string // Locale used when the request specifies none.
+
+	idempotencyWindow time.Duration     // How long idempotency keys are remembered.
+	idempotency       *idempotencyCache // Deduplicates tasks/send requests by idempotency key.
+
+	taskIDValidator func(id string) error // Optional check for client-supplied task IDs.
+
+	debugEndpoint string // Path for the debug endpoint; empty disables it.
+
+	messageKey interface{} // Public key client message signatures must verify against, if any.
+
+	agentCardSigner interface{} // Private key used to sign the agent card, if any.
+	signedAgentCard []byte      // Compact JWS of the agent card, set when agentCardSigner is.
+
+	workerPoolSize    int         // Number of workers processing tasks (0 disables the pool).
+	workerQueueSize   int         // Tasks that may wait for a worker; defaults to workerPoolSize.
+	workerQueuePolicy QueuePolicy // What to do with tasks submitted while the queue is full.
+	fairScheduling    bool        // Give free workers to waiting tasks round-robin across sessions.
+	workers           *workerPool // Bounded pool running task manager calls.
+	pause             *pauseGate  // Holds new tasks back between Pause and Resume.
+	maxQueueDepth     int         // Tasks that may wait to start (0 is unlimited).
+	queue             *taskQueue  // Counts the tasks waiting to start.
+
+	strictJSONRPC  bool  // Reject requests with a wrong jsonrpc version or invalid id type.
+	maxJSONDepth   int   // Deepest nesting allowed in a request body (0 disables the check).
+	maxRequestSize int64 // Largest request body accepted in bytes (0 is unlimited).
+	maxBatchSize   int   // Most tasks in a tasks/sendBatch request.
+	maxBatchBytes  int64 // Largest tasks/sendBatch params in bytes (0 is unlimited).
+
+	taskRetentionTTL time.Duration       // How long finished tasks are kept (0 keeps them).
+	onTaskEvict      func(protocol.Task) // Called with each task before retention deletes it.
+	retention        *taskRetention      // Sweeper deleting finished tasks, if enabled.
+
+	fileTypes        *fileTypePolicy          // Allowed FilePart types; nil accepts any.
+	fileTypeDetector func(data []byte) string // Replaces the fileTypes content sniffer, if set.
+	skillRouter      SkillRouter              // Picks the skill of messages sent without one, if set.
+
+	admission         *admissionWebhook // Approves the tasks/send and tasks/sendSubscribe requests, if set.
+	admissionFailOpen bool              // Admit requests when the admission webhook fails.
+
+	artifactEndpoint string // Path serving artifact content, see WithArtifactContentEndpoint.
+
+	externalURL   string // URL clients reach the server at, behind a reverse proxy.
+	strictCardURL bool   // Refuse to start when the card URL does not match the server.
+
+	maxTaskWait time.Duration // Longest a long-poll tasks/get waits for a status change.
+
+	events *taskmanager.EventBus // Task events of the server and its agents, see SubscribeEvents.
+
+	maxHistoryBytes    int            // Largest encoded message history kept per task (0 is unlimited).
+	processorTimeout   time.Duration  // Longest a processor may run, or go without events when streaming.
+	maxArtifacts       int            // Cap on distinct artifacts per task, or 0 for none.
+	slowClientTimeout  time.Duration  // Longest an SSE write or emitted event waits for a slow client.
+	taskSnapshots      bool           // Serve tasks/export and tasks/import; requires authentication.
+	rawDataMinSize     int            // Smallest DataPart payload kept as raw JSON (0 decodes all).
+	cancelOnDisconnect bool           // Cancel streamed tasks when their client disconnects.
+	errorVerbosity     ErrorVerbosity // Detail of JSON-RPC error data sent to clients.
+	logBodies          bool           // Log JSON-RPC request and response bodies at debug level.
+	timeFormat         TimeFormat     // Formats the timestamps sent, if set.
+
+	rawParamSchemas map[string]json.RawMessage    // Params schemas by method, as configured.
+	paramSchemas    map[string]*jsonschema.Schema // Compiled rawParamSchemas.
+
+	agents  []*A2AServer             // Agents added with RegisterAgent, in order.
+	methods map[string]MethodHandler // Custom methods added with RegisterMethod.
 }
 
 // NewA2AServer creates a new A2AServer instance with the given agent card
@@ -52,23 +128,86 @@ func NewA2AServer(agentCard AgentCard, taskManager taskmanager.TaskManager, opts
 	if taskManager == nil {
 		return nil, errors.New("NewA2AServer requires a non-nil taskManager")
 	}
+	if err := agentCard.ValidateExtensions(); err != nil {
+		return nil, fmt.Errorf("invalid agent card: %w", err)
+	}
 	server := &A2AServer{
-		agentCard:       agentCard,
-		taskManager:     taskManager,
-		corsEnabled:     true, // Enable CORS by default for easier development.
-		jsonRPCEndpoint: protocol.DefaultJSONRPCPath,
-		readTimeout:     defaultReadTimeout,
-		writeTimeout:    defaultWriteTimeout,
-		idleTimeout:     defaultIdleTimeout,
-		jwksEnabled:     false,
-		jwksEndpoint:    protocol.JWKSPath,
+		agentCard:         agentCard,
+		taskManager:       taskManager,
+		corsEnabled:       true, // Enable CORS by default for easier development.
+		jsonRPCEndpoint:   protocol.DefaultJSONRPCPath,
+		defaultLocale:     protocol.DefaultLocale,
+		idempotencyWindow: defaultIdempotencyWindow,
+		readTimeout:       defaultReadTimeout,
+		writeTimeout:      defaultWriteTimeout,
+		idleTimeout:       defaultIdleTimeout,
+		jwksEnabled:       false,
+		jwksEndpoint:      protocol.JWKSPath,
+		workerQueueSize:   -1,
+		strictJSONRPC:     true,
+		maxJSONDepth:      jsonlimit.DefaultMaxDepth,
+		maxBatchSize:      protocol.MaxSendBatchSize,
+		maxTaskWait:       defaultMaxTaskWait,
+		methods:           make(map[string]MethodHandler),
+		pause:             &pauseGate{},
+		events:            taskmanager.NewEventBus(),
 	}
 	for _, opt := range opts {
 		opt(server)
 	}
+	server.queue = &taskQueue{maxDepth: int64(server.maxQueueDepth)}
+	if server.fileTypes != nil && server.fileTypeDetector != nil {
+		server.fileTypes.detect = server.fileTypeDetector
+	}
+	if len(server.rawParamSchemas) > 0 {
+		schemas, err := compileParamSchemas(server.rawParamSchemas)
+		if err != nil {
+			return nil, err
+		}
+		server.paramSchemas = schemas
+	}
+	if server.idempotencyWindow > 0 {
+		server.idempotency = newIdempotencyCache(server.idempotencyWindow)
+	}
+	if server.workerPoolSize > 0 {
+		if server.workerQueueSize < 0 {
+			server.workerQueueSize = server.workerPoolSize
+		}
+		server.workers = newWorkerPool(
+			server.workerPoolSize, server.workerQueueSize, server.workerQueuePolicy, server.fairScheduling,
+		)
+	}
+	if err := server.configureTaskManager(taskManager); err != nil {
+		return nil, err
+	}
+	if server.taskSnapshots {
+		if _, ok := taskManager.(taskmanager.TaskSnapshotter); !ok {
+			return nil, errors.New("task snapshots require a task manager implementing taskmanager.TaskSnapshotter")
+		}
+		if server.authProvider == nil {
+			log.Warnf("Task snapshot methods disabled: they require an auth provider")
+			server.taskSnapshots = false
+		}
+	}
+	if server.externalURL != "" {
+		if _, err := parseAbsoluteURL(server.externalURL); err != nil {
+			return nil, fmt.Errorf("invalid external URL: %w", err)
+		}
+		if err := server.checkCardURL(nil); err != nil {
+			return nil, err
+		}
+	}
+	if server.agentCardSigner != nil {
+		signed, err := protocol.SignAgentCard(server.agentCard, server.agentCardSigner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign agent card: %w", err)
+		}
+		server.signedAgentCard = signed
+	}
 	// Initialize authentication components if auth provider is set.
 	if server.authProvider != nil {
 		server.authMiddleware = auth.NewMiddleware(server.authProvider)
+		server.authMiddleware.SetRealm(server.agentCard.Name)
 	}
 	// Initialize push notification authenticator.
 	if server.jwksEnabled {
@@ -80,6 +219,61 @@ func NewA2AServer(agentCard AgentCard, taskManager taskmanager.TaskManager, opts
 	return server, nil
 }
 
+// configureTaskManager applies the options implemented by task managers to
+// taskManager, and sets up the retention sweeper for its tasks.
+func (s *A2AServer) configureTaskManager(taskManager taskmanager.TaskManager) error {
+	s.retention = nil
+	if s.taskRetentionTTL > 0 {
+		pruner, ok := taskManager.(taskmanager.TaskPruner)
+		if !ok {
+			return errors.New("task retention requires a task manager implementing taskmanager.TaskPruner")
+		}
+		s.retention = newTaskRetention(pruner, s.taskRetentionTTL, s.onTaskEvict)
+	}
+	if s.maxHistoryBytes > 0 {
+		limiter, ok := taskManager.(taskmanager.HistoryLimiter)
+		if !ok {
+			return errors.New("a history size cap requires a task manager implementing taskmanager.HistoryLimiter")
+		}
+		limiter.SetMaxHistoryBytes(s.maxHistoryBytes)
+	}
+	if s.processorTimeout > 0 {
+		limiter, ok := taskManager.(taskmanager.ProcessorLimiter)
+		if !ok {
+			return errors.New("a processor timeout requires a task manager implementing taskmanager.ProcessorLimiter")
+		}
+		limiter.SetProcessorTimeout(s.processorTimeout)
+	}
+	if s.maxArtifacts > 0 {
+		limiter, ok := taskManager.(taskmanager.ArtifactLimiter)
+		if !ok {
+			return errors.New("an artifact cap requires a task manager implementing taskmanager.ArtifactLimiter")
+		}
+		limiter.SetMaxArtifactsPerTask(s.maxArtifacts)
+	}
+	if s.slowClientTimeout > 0 {
+		limiter, ok := taskManager.(taskmanager.BackpressureLimiter)
+		if !ok {
+			return errors.New("a slow client timeout requires a task manager implementing taskmanager.BackpressureLimiter")
+		}
+		limiter.SetSubscriberSendTimeout(s.slowClientTimeout)
+	}
+	if publisher, ok := taskManager.(taskmanager.EventPublisher); ok {
+		publisher.SetEventBus(s.events)
+	}
+	return nil
+}
+
+// SubscribeEvents subscribes to the lifecycle events of every task of the server
+// and of the agents registered on it, see taskmanager.BusEventType, for
+// observability or in-process side effects. Events are only published by task
+// managers implementing taskmanager.EventPublisher. A subscriber that falls more
+// than buffer events behind loses events as selected by policy, rather than
+// slowing the tasks down. Close the subscription once done with it.
+func (s *A2AServer) SubscribeEvents(buffer int, policy taskmanager.BusPolicy) *taskmanager.BusSubscription {
+	return s.events.Subscribe(buffer, policy)
+}
+
 // Start begins listening for HTTP requests on the specified network address.
 // It blocks until the server is stopped via Stop() or an error occurs.
 func (s *A2AServer) Start(address string) error {
@@ -91,9 +285,25 @@ func (s *A2AServer) Start(address string) error {
 		IdleTimeout:  s.idleTimeout,
 	}
 
-	log.Infof("Starting A2A server listening on %s...", address)
-	// ListenAndServe blocks. It returns http.ErrServerClosed on graceful shutdown.
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if address == "" {
+		address = ":http"
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("http server ListenAndServe error: %w", err)
+	}
+	if s.externalURL == "" {
+		if err := s.checkCardURL(listener.Addr()); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	s.startRetention()
+	log.Infof("Starting A2A server listening on %s...", listener.Addr())
+	// Serve blocks. It returns http.ErrServerClosed on graceful shutdown.
+	if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+		s.closeRetention()
 		return fmt.Errorf("http server ListenAndServe error: %w", err)
 	}
 	log.Info("A2A server stopped.")
@@ -110,6 +320,10 @@ func (s *A2AServer) Stop(ctx context.Context) error {
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("http server shutdown failed: %w", err)
 	}
+	if s.workers != nil {
+		s.workers.close()
+	}
+	s.closeRetention()
 	log.Info("A2A server shutdown complete.")
 	return nil
 }
@@ -132,11 +346,26 @@ func (s *A2AServer) Handler() http.Handler {
 		// No authentication required.
 		router.HandleFunc(s.jsonRPCEndpoint, s.handleJSONRPC)
 	}
+	// Endpoints of the agents added with RegisterAgent.
+	for _, agent := range s.agents {
+		agent.route(router, s.authMiddleware)
+	}
+	// Artifact content endpoint, if enabled.
+	s.routeArtifactContent(router)
+	// Debug endpoint, only served behind authentication.
+	if s.debugEndpoint != "" {
+		if s.authMiddleware != nil {
+			router.Handle(s.debugEndpoint, s.authMiddleware.Wrap(http.HandlerFunc(s.handleDebug)))
+		} else {
+			log.Warnf("Debug endpoint %s disabled: it requires an auth provider", s.debugEndpoint)
+		}
+	}
 	return router
 }
 
 // handleAgentCard serves the agent's metadata card as JSON.
 // Corresponds to GET /.well-known/agent.json in A2A Spec.
+// Clients accepting application/jose get the JWS-signed card when signing is configured.
 func (s *A2AServer) handleAgentCard(w http.ResponseWriter, r *http.Request) {
 	if s.corsEnabled {
 		s.setCORSHeaders(w)
@@ -145,6 +374,16 @@ func (s *A2AServer) handleAgentCard(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 		return
 	}
+	if s.signedAgentCard != nil {
+		w.Header().Add("Vary", "Accept")
+	}
+	if s.signedAgentCard != nil && acceptsMediaType(r.Header.Get("Accept"), protocol.AgentCardJWSContentType) {
+		w.Header().Set("Content-Type", protocol.AgentCardJWSContentType)
+		if _, err := w.Write(s.signedAgentCard); err != nil {
+			log.Errorf("Failed to write signed agent card: %v", err)
+		}
+		return
+	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if err := json.NewEncoder(w).Encode(s.agentCard); err != nil {
 		log.Errorf("Failed to encode agent card: %v", err)
@@ -153,6 +392,17 @@ func (s *A2AServer) handleAgentCard(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// acceptsMediaType reports whether an Accept header lists mediaType explicitly.
+func acceptsMediaType(accept, mediaType string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		parsed, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && parsed == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
 // handleJSONRPC is the main handler for all JSON-RPC 2.0 requests.
 // Routes methods like tasks/send, tasks/get, etc., as defined in A2A Spec.
 func (s *A2AServer) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
@@ -177,8 +427,165 @@ func (s *A2AServer) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Carry the client's stream event filter, if any, to the SSE handlers.
+	ctx := r.Context()
+	if filter := r.Header.Get(protocol.HeaderStreamEventFilter); filter != "" {
+		ctx = context.WithValue(ctx, streamEventFilterKey{}, protocol.StreamEventFilter(filter))
+	}
+	if key := r.Header.Get(protocol.HeaderIdempotencyKey); key != "" {
+		ctx = context.WithValue(ctx, idempotencyKey{}, key)
+	}
+	if etag := r.Header.Get("If-None-Match"); etag != "" {
+		ctx = context.WithValue(ctx, ifNoneMatchKey{}, etag)
+	}
+	// Keep the Accept-Language header as a locale fallback for send requests.
+	if acceptLanguage := r.Header.Get("Accept-Language"); acceptLanguage != "" {
+		ctx = context.WithValue(ctx, acceptLanguageKey{}, acceptLanguage)
+	}
+	// Make the baggage of the call available to the processor.
+	if header := r.Header.Values(protocol.HeaderBaggage); len(header) > 0 {
+		if baggage := protocol.ParseBaggage(strings.Join(header, ",")); len(baggage) > 0 {
+			ctx = protocol.WithBaggage(ctx, baggage)
+		}
+	}
+	s.serveJSONRPCRequest(ctx, w, request)
+}
+
+// serveJSONRPCRequest validates the params of a parsed request and routes it to
+// its handler.
+func (s *A2AServer) serveJSONRPCRequest(ctx context.Context, w http.ResponseWriter, request jsonrpc.Request) {
+	// Let the task manager record the request ID on the tasks the request creates.
+	if id := requestIDString(request.ID); id != "" {
+		ctx = taskmanager.WithRequestID(ctx, id)
+	}
+
+	if err := s.validateParams(request); err != nil {
+		s.writeJSONRPCError(w, request.ID, err)
+		return
+	}
+
 	// Route to appropriate handler based on method
-	s.routeJSONRPCMethod(r.Context(), w, request)
+	s.routeJSONRPCMethod(ctx, w, request)
+}
+
+// requestIDString formats a JSON-RPC request ID, a string or a number, as a
+// string, returning "" for a null ID.
+func requestIDString(id interface{}) string {
+	switch id := id.(type) {
+	case nil:
+		return ""
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	default:
+		return fmt.Sprint(id)
+	}
+}
+
+// streamEventFilterKey is the context key for the client's requested stream event filter.
+type streamEventFilterKey struct{}
+
+// validateTaskID applies the configured task ID validator to a client-supplied task ID.
+// Empty IDs are left to the caller's required-field checks.
+func (s *A2AServer) validateTaskID(id string) *jsonrpc.Error {
+	if s.taskIDValidator == nil || id == "" {
+		return nil
+	}
+	if err := s.taskIDValidator(id); err != nil {
+		return jsonrpc.ErrInvalidParams(fmt.Sprintf("invalid task ID %q: %v", id, err))
+	}
+	return nil
+}
+
+// checkSendMessages checks the messages of a send request, those seeding the task
+// history included, against WithAllowedFileTypes and WithMessageVerificationKey,
+// then routes the message to a skill with WithSkillRouter.
+func (s *A2AServer) checkSendMessages(params *protocol.SendTaskParams) *jsonrpc.Error {
+	for i, message := range params.Messages {
+		if typeErr := s.validateFileTypes(message); typeErr != nil {
+			return seedMessageError(i, typeErr)
+		}
+		if sigErr := s.verifyMessageSignature(message); sigErr != nil {
+			return seedMessageError(i, sigErr)
+		}
+	}
+	if typeErr := s.validateFileTypes(params.Message); typeErr != nil {
+		return typeErr
+	}
+	if sigErr := s.verifyMessageSignature(params.Message); sigErr != nil {
+		return sigErr
+	}
+	return s.routeSkill(&params.Message)
+}
+
+// seedMessageError returns err, the invalid params error of a check, for the
+// message of params.Messages at index.
+func seedMessageError(index int, err *jsonrpc.Error) *jsonrpc.Error {
+	return jsonrpc.ErrInvalidParams(fmt.Sprintf("messages[%d]: %v", index, err.Data))
+}
+
+// verifyMessageSignature checks the message signature against the key set with
+// WithMessageVerificationKey, if any.
+func (s *A2AServer) verifyMessageSignature(message protocol.Message) *jsonrpc.Error {
+	if s.messageKey == nil {
+		return nil
+	}
+	if err := protocol.VerifyMessage(message, s.messageKey); err != nil {
+		return jsonrpc.ErrInvalidParams(err.Error())
+	}
+	return nil
+}
+
+// acceptLanguageKey is the context key for the request's Accept-Language header.
+type acceptLanguageKey struct{}
+
+// resolveLocale determines the locale for a send request and stores it in both the
+// params and the returned context. An explicit but malformed locale is rejected;
+// otherwise it falls back to the Accept-Language header and then the server default.
+func (s *A2AServer) resolveLocale(
+	ctx context.Context,
+	params *protocol.SendTaskParams,
+) (context.Context, *jsonrpc.Error) {
+	locale := s.defaultLocale
+	if params.Locale != nil && *params.Locale != "" {
+		if err := protocol.ValidateLocale(*params.Locale); err != nil {
+			return ctx, jsonrpc.ErrInvalidParams(err.Error())
+		}
+		locale = *params.Locale
+	} else if header, ok := ctx.Value(acceptLanguageKey{}).(string); ok {
+		if tags := protocol.ParseAcceptLanguage(header); len(tags) > 0 {
+			locale = tags[0]
+		}
+	}
+	params.Locale = &locale
+	return taskmanager.WithLocale(ctx, locale), nil
+}
+
+// resolveArtifactReferences makes the artifacts that message references available
+// to the processor through the returned context.
+func (s *A2AServer) resolveArtifactReferences(
+	ctx context.Context,
+	message protocol.Message,
+) (context.Context, *jsonrpc.Error) {
+	ctx, err := taskmanager.ResolveArtifactReferences(ctx, s.taskManager, message)
+	if err == nil {
+		return ctx, nil
+	}
+	if rpcErr, ok := err.(*jsonrpc.Error); ok {
+		return ctx, rpcErr
+	}
+	log.Errorf("Error resolving artifact references: %v", err)
+	return ctx, jsonrpc.ErrInternalError(fmt.Sprintf("failed to resolve artifact references: %v", err))
+}
+
+// streamEventFilterFromContext returns the stream event filter stored in the context,
+// defaulting to all events.
+func streamEventFilterFromContext(ctx context.Context) protocol.StreamEventFilter {
+	if filter, ok := ctx.Value(streamEventFilterKey{}).(protocol.StreamEventFilter); ok {
+		return filter
+	}
+	return protocol.StreamEventFilterAll
 }
 
 // validateJSONRPCRequest validates basic HTTP requirements for JSON-RPC.
@@ -210,16 +617,35 @@ func (s *A2AServer) validateJSONRPCRequest(w http.ResponseWriter, r *http.Reques
 func (s *A2AServer) parseJSONRPCRequest(w http.ResponseWriter, body io.ReadCloser) (jsonrpc.Request, error) {
 	var request jsonrpc.Request
 
-	// Read the request body
-	bodyBytes, err := io.ReadAll(body)
+	// It's important to close the body, even though ReadAll consumes it
+	defer body.Close()
+
+	// Read the request body, one byte past the limit to detect oversized bodies.
+	reader := io.Reader(body)
+	if s.maxRequestSize > 0 {
+		reader = io.LimitReader(body, s.maxRequestSize+1)
+	}
+	bodyBytes, err := io.ReadAll(reader)
 	if err != nil {
 		s.writeJSONRPCError(w, nil,
 			jsonrpc.ErrParseError(fmt.Sprintf("failed to read request body: %v", err)))
 		return request, err
 	}
+	if s.maxRequestSize > 0 && int64(len(bodyBytes)) > s.maxRequestSize {
+		err := fmt.Errorf("request body exceeds %d bytes", s.maxRequestSize)
+		s.writeJSONRPCError(w, nil, jsonrpc.ErrInvalidRequest(err.Error()))
+		return request, err
+	}
+	if s.logBodies {
+		log.Debugf("JSON-RPC request body: %s", redact.JSON(bodyBytes))
+	}
 
-	// It's important to close the body, even though ReadAll consumes it
-	defer body.Close()
+	// Bound the nesting before decoding, since the decoder recurses per level.
+	if err := jsonlimit.CheckDepth(bodyBytes, s.maxJSONDepth); err != nil {
+		s.writeJSONRPCError(w, nil,
+			jsonrpc.ErrParseError(fmt.Sprintf("failed to parse JSON request: %v", err)))
+		return request, err
+	}
 
 	// Parse the JSON request
 	if err := json.Unmarshal(bodyBytes, &request); err != nil {
@@ -228,6 +654,16 @@ func (s *A2AServer) parseJSONRPCRequest(w http.ResponseWriter, body io.ReadClose
 		return request, err
 	}
 
+	// Legacy clients may send other versions or ids; skip the checks when relaxed.
+	if !s.strictJSONRPC {
+		return request, nil
+	}
+	// Validate the id first: an invalid id cannot be echoed back in the error.
+	if err := jsonrpc.ValidateID(request.ID); err != nil {
+		request.ID = nil
+		s.writeJSONRPCError(w, nil, jsonrpc.ErrInvalidRequest(err.Error()))
+		return request, fmt.Errorf("invalid JSON-RPC id: %w", err)
+	}
 	// Validate JSON-RPC version
 	if request.JSONRPC != jsonrpc.Version {
 		s.writeJSONRPCError(w, request.ID,
@@ -257,13 +693,46 @@ func (s *A2AServer) routeJSONRPCMethod(ctx context.Context, w http.ResponseWrite
 		s.handleTasksPushNotificationGet(ctx, w, request)
 	case protocol.MethodTasksResubscribe: // A2A Spec: tasks/resubscribe
 		s.handleTasksResubscribe(ctx, w, request)
+	case protocol.MethodTasksSubscribeMultiple:
+		s.handleTasksSubscribeMultiple(ctx, w, request)
+	case protocol.MethodTasksSendBatch:
+		s.handleTasksSendBatch(ctx, w, request)
+	case protocol.MethodTasksSendInput:
+		if streamer, ok := s.inputStreamer(); ok {
+			s.handleTasksSendInput(ctx, w, request, streamer)
+			return
+		}
+		s.writeMethodNotFound(w, request)
+	case protocol.MethodCapabilities:
+		s.handleCapabilities(ctx, w, request)
+	case protocol.MethodTasksExport:
+		if s.taskSnapshots {
+			s.handleTasksExport(ctx, w, request)
+			return
+		}
+		s.writeMethodNotFound(w, request)
+	case protocol.MethodTasksImport:
+		if s.taskSnapshots {
+			s.handleTasksImport(ctx, w, request)
+			return
+		}
+		s.writeMethodNotFound(w, request)
 	default:
-		log.Warnf("Method not found: %s (Request ID: %v)", request.Method, request.ID)
-		s.writeJSONRPCError(w, request.ID,
-			jsonrpc.ErrMethodNotFound(fmt.Sprintf("method '%s' not supported", request.Method)))
+		if handler, ok := s.methods[request.Method]; ok {
+			s.handleCustomMethod(ctx, w, request, handler)
+			return
+		}
+		s.writeMethodNotFound(w, request)
 	}
 }
 
+// writeMethodNotFound answers a request for a method the server does not handle.
+func (s *A2AServer) writeMethodNotFound(w http.ResponseWriter, request jsonrpc.Request) {
+	log.Warnf("Method not found: %s (Request ID: %v)", request.Method, request.ID)
+	s.writeJSONRPCError(w, request.ID,
+		jsonrpc.ErrMethodNotFound(fmt.Sprintf("method '%s' not supported", request.Method)))
+}
+
 // unmarshalParams is a helper function to unmarshal JSON-RPC params into the provided struct.
 // It returns an error if unmarshalling fails, which is already formatted as a JSON-RPC error.
 func (s *A2AServer) unmarshalParams(params json.RawMessage, v interface{}) *jsonrpc.Error {
@@ -273,15 +742,161 @@ func (s *A2AServer) unmarshalParams(params json.RawMessage, v interface{}) *json
 	return nil
 }
 
+// unmarshalSendParams unmarshals the params of a send request, keeping the large
+// DataPart payloads of its message raw when configured with WithRawDataParts.
+func (s *A2AServer) unmarshalSendParams(params json.RawMessage, v *protocol.SendTaskParams) *jsonrpc.Error {
+	if s.rawDataMinSize <= 0 {
+		return s.unmarshalParams(params, v)
+	}
+	type alias protocol.SendTaskParams // Alias to decode the message separately.
+	temp := &struct {
+		Message json.RawMessage `json:"message"`
+		*alias
+	}{
+		alias: (*alias)(v),
+	}
+	if err := s.unmarshalParams(params, temp); err != nil {
+		return err
+	}
+	if len(temp.Message) == 0 {
+		return nil
+	}
+	if err := protocol.UnmarshalMessageRawData(temp.Message, s.rawDataMinSize, &v.Message); err != nil {
+		return jsonrpc.ErrInvalidParams(fmt.Sprintf("failed to parse params: %v", err))
+	}
+	return nil
+}
+
 // handleTasksSend handles the tasks_send method.
 func (s *A2AServer) handleTasksSend(ctx context.Context, w http.ResponseWriter, request jsonrpc.Request) {
 	var params protocol.SendTaskParams
-	if err := s.unmarshalParams(request.Params, &params); err != nil {
+	if err := s.unmarshalSendParams(request.Params, &params); err != nil {
 		s.writeJSONRPCError(w, request.ID, err)
 		return
 	}
-	// Delegate to the task manager.
-	task, err := s.taskManager.OnSendTask(ctx, params)
+	if err := params.Validate(); err != nil {
+		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidParams(err))
+		return
+	}
+	if idErr := s.validateTaskID(params.ID); idErr != nil {
+		s.writeJSONRPCError(w, request.ID, idErr)
+		return
+	}
+	if checkErr := s.checkSendMessages(&params); checkErr != nil {
+		s.writeJSONRPCError(w, request.ID, checkErr)
+		return
+	}
+	if admissionErr := s.admit(ctx, request.Method, &params); admissionErr != nil {
+		s.writeJSONRPCError(w, request.ID, admissionErr)
+		return
+	}
+	ctx, localeErr := s.resolveLocale(ctx, &params)
+	if localeErr != nil {
+		s.writeJSONRPCError(w, request.ID, localeErr)
+		return
+	}
+	ctx, refErr := s.resolveArtifactReferences(ctx, params.Message)
+	if refErr != nil {
+		s.writeJSONRPCError(w, request.ID, refErr)
+		return
+	}
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	if !ok || s.idempotency == nil {
+		_, _ = s.sendTask(ctx, w, request, params)
+		return
+	}
+	// Deduplicate retries carrying the same idempotency key from the same caller.
+	scope := newIdempotencyScope(ctx, key)
+	entry, replayed, keyErr := s.acquireIdempotencyKey(ctx, scope, hashSendParams(params))
+	if keyErr != nil {
+		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidParams(keyErr.Error()))
+		return
+	}
+	if entry == nil {
+		s.writeJSONRPCError(w, request.ID,
+			jsonrpc.ErrInternalError("request canceled while waiting for a duplicate request"))
+		return
+	}
+	if replayed {
+		s.replayIdempotentTask(ctx, w, request, entry.taskID)
+		return
+	}
+	var taskID string
+	defer func() { s.idempotency.complete(scope, entry, taskID) }()
+	if task, err := s.sendTask(ctx, w, request, params); err == nil && task != nil {
+		taskID = task.ID
+	}
+}
+
+// acquireIdempotencyKey claims the idempotency key for this request, waiting for an
+// in-flight request with the same key to finish first. It returns the existing entry
+// with replayed set if a task was already created for the key, or the newly owned
+// entry otherwise. A nil entry means the context ended while waiting. An error means
+// the key was used with different params.
+func (s *A2AServer) acquireIdempotencyKey(
+	ctx context.Context,
+	scope idempotencyScope,
+	paramsHash string,
+) (entry *idempotencyEntry, replayed bool, err error) {
+	for {
+		entry, owner, err := s.idempotency.acquire(scope, paramsHash)
+		if err != nil {
+			return nil, false, err
+		}
+		if owner {
+			return entry, false, nil
+		}
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, false, nil
+		}
+		if entry.taskID != "" {
+			return entry, true, nil
+		}
+		// The first request failed; try to claim the key again.
+	}
+}
+
+// replayIdempotentTask writes the current state of a task previously created for
+// the same idempotency key.
+func (s *A2AServer) replayIdempotentTask(
+	ctx context.Context,
+	w http.ResponseWriter,
+	request jsonrpc.Request,
+	taskID string,
+) {
+	log.Infof("Returning existing task %s for repeated idempotency key (Request ID: %v)", taskID, request.ID)
+	task, err := s.taskManager.OnGetTask(ctx, protocol.TaskQueryParams{ID: taskID})
+	if err != nil {
+		if rpcErr, ok := err.(*jsonrpc.Error); ok {
+			s.writeJSONRPCError(w, request.ID, rpcErr)
+		} else {
+			s.writeJSONRPCError(w, request.ID,
+				jsonrpc.ErrInternalError(fmt.Sprintf("failed to get task: %v", err)))
+		}
+		return
+	}
+	s.writeJSONRPCResponse(w, request.ID, task)
+}
+
+// sendTask delegates a tasks/send request to the task manager and writes the response.
+func (s *A2AServer) sendTask(
+	ctx context.Context,
+	w http.ResponseWriter,
+	request jsonrpc.Request,
+	params protocol.SendTaskParams,
+) (*protocol.Task, error) {
+	// Delegate to the task manager, on the worker pool if configured.
+	var task *protocol.Task
+	var err error
+	if poolErr := s.runTask(ctx, params, func() {
+		task, err = s.taskManager.OnSendTask(ctx, params)
+	}); poolErr != nil {
+		log.Errorf("Rejected tasks/send for task %s: %v", params.ID, poolErr)
+		s.writeServerBusy(w, request.ID, poolErr)
+		return nil, poolErr
+	}
 	if err != nil {
 		log.Errorf("Error calling OnSendTask for task %s: %v", params.ID, err)
 		// Check if it's already a JSON-RPC error
@@ -292,9 +907,50 @@ func (s *A2AServer) handleTasksSend(ctx context.Context, w http.ResponseWriter,
 			s.writeJSONRPCError(w, request.ID,
 				jsonrpc.ErrInternalError(fmt.Sprintf("task processing failed: %v", err)))
 		}
-		return
+		return nil, err
 	}
 	s.writeJSONRPCResponse(w, request.ID, task)
+	return task, nil
+}
+
+// runTask runs fn, which handles the task of params, once the server is not paused
+// and a worker pool slot is free when a pool is configured. It returns an error only
+// if the task queue was full, the pause outlasted ctx or the pool did not accept fn.
+func (s *A2AServer) runTask(ctx context.Context, params protocol.SendTaskParams, fn func()) error {
+	release, err := s.acquireWorker(ctx, params)
+	if err != nil {
+		return err
+	}
+	defer release()
+	fn()
+	return nil
+}
+
+// acquireWorker queues the task of params, waits for the server not to be paused,
+// takes a worker pool slot for it when a pool is configured and returns the
+// function that frees it.
+func (s *A2AServer) acquireWorker(ctx context.Context, params protocol.SendTaskParams) (func(), error) {
+	leave, err := s.queue.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	if err := s.pause.wait(ctx); err != nil {
+		return nil, err
+	}
+	if s.workers == nil {
+		return func() {}, nil
+	}
+	return s.workers.acquire(ctx, schedulingSession(params))
+}
+
+// schedulingSession returns the session a task is scheduled under: its session
+// ID, or the task ID for tasks without one.
+func schedulingSession(params protocol.SendTaskParams) string {
+	if params.SessionID != nil && *params.SessionID != "" {
+		return "session:" + *params.SessionID
+	}
+	return "task:" + params.ID
 }
 
 // handleTasksGet handles the tasks_get method.
@@ -304,6 +960,11 @@ func (s *A2AServer) handleTasksGet(ctx context.Context, w http.ResponseWriter, r
 		s.writeJSONRPCError(w, request.ID, err)
 		return
 	}
+	if params.WaitMs > 0 {
+		if err := s.waitForTaskChange(ctx, params.ID, time.Duration(params.WaitMs)*time.Millisecond); err != nil {
+			return // The client went away.
+		}
+	}
 	task, err := s.taskManager.OnGetTask(ctx, params)
 	if err != nil {
 		// Check if the error is already a JSONRPCError (e.g., TaskNotFound).
@@ -318,9 +979,69 @@ func (s *A2AServer) handleTasksGet(ctx context.Context, w http.ResponseWriter, r
 		}
 		return
 	}
+	// Let pollers skip unchanged tasks: the ETag identifies this exact result.
+	if etag := taskETag(task); etag != "" {
+		w.Header().Set("ETag", etag)
+		if match, ok := ctx.Value(ifNoneMatchKey{}).(string); ok && etagMatches(match, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
 	s.writeJSONRPCResponse(w, request.ID, task)
 }
 
+// waitForTaskChange holds a long-poll tasks/get request until the task's status
+// changes or wait elapses, capped by maxTaskWait and the write timeout. Task
+// managers that are not a taskmanager.TaskWaiter answer immediately. It returns
+// an error only if ctx, the request's context, is done.
+func (s *A2AServer) waitForTaskChange(ctx context.Context, taskID string, wait time.Duration) error {
+	waiter, ok := s.taskManager.(taskmanager.TaskWaiter)
+	if !ok {
+		return nil
+	}
+	wait = min(wait, s.maxTaskWait)
+	if s.writeTimeout > 0 {
+		// Leave a tenth of the write timeout to fetch and write the task.
+		wait = min(wait, s.writeTimeout-s.writeTimeout/10)
+	}
+	if wait <= 0 {
+		return nil
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	if err := waiter.WaitForTaskChange(waitCtx, taskID); err != nil && ctx.Err() == nil &&
+		!errors.Is(err, context.DeadlineExceeded) {
+		// Let OnGetTask report the error, e.g. an unknown task.
+		log.Debugf("Waiting for task %s to change: %v", taskID, err)
+	}
+	return ctx.Err()
+}
+
+// ifNoneMatchKey is the context key for the request's If-None-Match header.
+type ifNoneMatchKey struct{}
+
+// taskETag returns a strong ETag for the task as returned by tasks/get,
+// or "" if it cannot be encoded.
+func taskETag(task *protocol.Task) string {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header value lists etag.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 // handleTasksCancel handles the tasks_cancel method.
 func (s *A2AServer) handleTasksCancel(ctx context.Context, w http.ResponseWriter, request jsonrpc.Request) {
 	var params protocol.TaskIDParams
@@ -328,6 +1049,16 @@ func (s *A2AServer) handleTasksCancel(ctx context.Context, w http.ResponseWriter
 		s.writeJSONRPCError(w, request.ID, err)
 		return
 	}
+	if params.Reason != "" && !params.Reason.IsValid() {
+		s.writeJSONRPCError(w, request.ID,
+			jsonrpc.ErrInvalidParams(fmt.Sprintf("unknown cancel reason %q", params.Reason)))
+		return
+	}
+	if params.Reason != "" && !params.Reason.IsClientRequestable() {
+		s.writeJSONRPCError(w, request.ID,
+			jsonrpc.ErrInvalidParams(fmt.Sprintf("cancel reason %q is reserved for the server", params.Reason)))
+		return
+	}
 	task, err := s.taskManager.OnCancelTask(ctx, params)
 	if err != nil {
 		if rpcErr, ok := err.(*jsonrpc.Error); ok {
@@ -345,6 +1076,8 @@ func (s *A2AServer) handleTasksCancel(ctx context.Context, w http.ResponseWriter
 
 // handleSSEStream handles an SSE stream for a task, including setup and event forwarding.
 // It sets the appropriate headers, logs connection status, and forwards events to the client.
+// It reports whether the stream ran until the task finished rather than until the
+// client disconnected.
 func (s *A2AServer) handleSSEStream(
 	ctx context.Context,
 	w http.ResponseWriter,
@@ -353,18 +1086,8 @@ func (s *A2AServer) handleSSEStream(
 	taskID string,
 	requestID interface{},
 	isResubscribe bool,
-) {
-	// Set headers for SSE.
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	if s.corsEnabled {
-		s.setCORSHeaders(w)
-	}
-
-	// Indicate successful subscription setup.
-	w.WriteHeader(http.StatusOK)
-	flusher.Flush() // Send headers immediately.
+) bool {
+	s.startSSEStream(w, flusher)
 
 	// Log appropriate message based on whether this is a new subscription or resubscribe
 	if isResubscribe {
@@ -375,6 +1098,9 @@ func (s *A2AServer) handleSSEStream(
 
 	// Use request context to detect client disconnection.
 	clientClosed := ctx.Done()
+	filter := streamEventFilterFromContext(ctx)
+	// Keep text chunks on rune boundaries; split runes would not survive JSON encoding.
+	runeCarry := textchunk.NewCarry()
 
 	// --- Event Forwarding Loop ---
 	for {
@@ -383,64 +1109,158 @@ func (s *A2AServer) handleSSEStream(
 			if !ok {
 				// Channel closed by task manager (task finished or error).
 				log.Infof("SSE stream closing for task %s (event channel closed by manager)", taskID)
-				// Send a final SSE event indicating closure.
-				closeData := sse.CloseEventData{
-					TaskID: taskID,
-					Reason: "task ended",
-				}
-				// Use JSON-RPC format for the close event
-				if err := sse.FormatJSONRPCEvent(w, protocol.EventClose, requestID, closeData); err != nil {
-					log.Errorf("Error writing SSE JSON-RPC close event for task %s: %v", taskID, err)
-				} else {
-					flusher.Flush()
-				}
-				return // End the handler.
+				s.writeSSECloseEvent(w, flusher, taskID, requestID)
+				return true // End the handler.
 			}
 
 			// Determine event type string for SSE.
 			var eventType string
-			switch event.(type) {
-			case protocol.TaskStatusUpdateEvent:
-				eventType = protocol.EventTaskStatusUpdate
-			case protocol.TaskArtifactUpdateEvent:
-				eventType = protocol.EventTaskArtifactUpdate
-			default:
+			var terminal bool
+			event, eventType, terminal = prepareSSEEvent(event, runeCarry)
+			if eventType == "" {
 				log.Warnf("Unknown event type received for task %s: %T. Skipping.", taskID, event)
 				continue // Skip unknown event types
 			}
+			// Drop events the client did not ask for, but still end the stream on a final status.
+			if !filter.Allows(event) {
+				if terminal {
+					log.Infof("SSE stream closing for task %s (final status event filtered)", taskID)
+					s.writeSSECloseEvent(w, flusher, taskID, requestID)
+					return true
+				}
+				continue
+			}
 
 			// Write the event to the SSE stream using JSON-RPC format.
-			if err := sse.FormatJSONRPCEvent(w, eventType, requestID, event); err != nil {
+			s.logResponseBody(requestID, event)
+			s.extendWriteDeadline(w)
+			if err := sse.FormatJSONRPCEvent(w, eventType, requestID, s.formatTimes(event)); err != nil {
 				// Error writing, likely client disconnected.
 				log.Errorf("Error writing SSE JSON-RPC event for task %s (client likely disconnected): %v. "+
 					"Closing stream.", taskID, err)
-				return // Exit the handler.
+				return false // Exit the handler.
 			}
 			// Flush the buffer to ensure the event is sent immediately.
 			flusher.Flush()
+			// Stop after the final status event, even if the manager keeps the channel open.
+			if terminal {
+				log.Infof("SSE stream closing for task %s (final status event sent)", taskID)
+				s.writeSSECloseEvent(w, flusher, taskID, requestID)
+				return true // End the handler.
+			}
 		case <-clientClosed:
 			// Client disconnected (request context canceled).
 			log.Infof("SSE client disconnected for task %s (Request ID: %v). Closing stream.", taskID, requestID)
-			return // Exit the handler.
+			return false // Exit the handler.
+		}
+	}
+}
+
+// extendWriteDeadline gives the next write to an SSE stream slowClientTimeout to
+// reach the client, if set, so the stream of a client that stops reading fails
+// instead of blocking forever.
+func (s *A2AServer) extendWriteDeadline(w http.ResponseWriter) {
+	if s.slowClientTimeout <= 0 {
+		return
+	}
+	deadline := time.Now().Add(s.slowClientTimeout)
+	if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil &&
+		!errors.Is(err, http.ErrNotSupported) {
+		log.Debugf("Failed to set SSE write deadline: %v", err)
+	}
+}
+
+// startSSEStream sets the SSE headers and flushes them, indicating a successful
+// subscription setup.
+func (s *A2AServer) startSSEStream(w http.ResponseWriter, flusher http.Flusher) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	if s.corsEnabled {
+		s.setCORSHeaders(w)
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush() // Send headers immediately.
+}
+
+// prepareSSEEvent returns the event as it is written to an SSE stream, its SSE
+// event type and whether it is the task's final status event. The event type is
+// empty for unknown events.
+func prepareSSEEvent(
+	event protocol.TaskEvent,
+	runeCarry *textchunk.Carry,
+) (protocol.TaskEvent, string, bool) {
+	switch e := event.(type) {
+	case protocol.TaskStatusUpdateEvent:
+		// Terminal states always carry final=true so clients know to stop reading.
+		if e.Status.State.IsFinal() {
+			e.Final = true
 		}
+		return e, protocol.EventTaskStatusUpdate, e.Final
+	case protocol.TaskArtifactUpdateEvent:
+		return runeCarry.Apply(e), protocol.EventTaskArtifactUpdate, false
+	case protocol.TaskArtifactPatchEvent:
+		return e, protocol.EventTaskArtifactPatch, false
+	case protocol.TaskArtifactDeleteEvent:
+		return e, protocol.EventTaskArtifactDelete, false
+	case protocol.TaskMessageEvent:
+		return e, protocol.EventTaskMessage, false
+	default:
+		return event, "", false
+	}
+}
+
+// writeSSECloseEvent sends the SSE event indicating that the stream is closing.
+func (s *A2AServer) writeSSECloseEvent(
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	taskID string,
+	requestID interface{},
+) {
+	closeData := sse.CloseEventData{
+		TaskID: taskID,
+		Reason: "task ended",
 	}
+	s.extendWriteDeadline(w)
+	// Use JSON-RPC format for the close event
+	if err := sse.FormatJSONRPCEvent(w, protocol.EventClose, requestID, closeData); err != nil {
+		log.Errorf("Error writing SSE JSON-RPC close event for task %s: %v", taskID, err)
+		return
+	}
+	flusher.Flush()
 }
 
 // handleTasksSendSubscribe handles the tasks_sendSubscribe method using Server-Sent Events (SSE).
 func (s *A2AServer) handleTasksSendSubscribe(ctx context.Context, w http.ResponseWriter, request jsonrpc.Request) {
 	var params protocol.SendTaskParams
-	if err := s.unmarshalParams(request.Params, &params); err != nil {
+	if err := s.unmarshalSendParams(request.Params, &params); err != nil {
 		s.writeJSONRPCError(w, request.ID, err)
 		return
 	}
-
-	// Validate required fields.
-	if params.ID == "" {
-		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidParams("task ID is required"))
+	if err := params.Validate(); err != nil {
+		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidParams(err))
+		return
+	}
+	if idErr := s.validateTaskID(params.ID); idErr != nil {
+		s.writeJSONRPCError(w, request.ID, idErr)
+		return
+	}
+	if checkErr := s.checkSendMessages(&params); checkErr != nil {
+		s.writeJSONRPCError(w, request.ID, checkErr)
+		return
+	}
+	if admissionErr := s.admit(ctx, request.Method, &params); admissionErr != nil {
+		s.writeJSONRPCError(w, request.ID, admissionErr)
+		return
+	}
+	ctx, localeErr := s.resolveLocale(ctx, &params)
+	if localeErr != nil {
+		s.writeJSONRPCError(w, request.ID, localeErr)
 		return
 	}
-	if params.Message.Role == "" || len(params.Message.Parts) == 0 {
-		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidParams("message with at least one part is required"))
+	ctx, refErr := s.resolveArtifactReferences(ctx, params.Message)
+	if refErr != nil {
+		s.writeJSONRPCError(w, request.ID, refErr)
 		return
 	}
 
@@ -457,8 +1277,23 @@ func (s *A2AServer) handleTasksSendSubscribe(ctx context.Context, w http.Respons
 		return
 	}
 
-	// Get the event channel from the task manager.
-	eventsChan, err := s.taskManager.OnSendTaskSubscribe(ctx, params)
+	// The processor keeps running after OnSendTaskSubscribe returns, so the worker
+	// slot is held until the stream ends rather than only for the subscription setup.
+	release, poolErr := s.acquireWorker(ctx, params)
+	if poolErr != nil {
+		log.Errorf("Rejected tasks/sendSubscribe for task %s: %v", params.ID, poolErr)
+		s.writeServerBusy(w, request.ID, poolErr)
+		return
+	}
+	held := true
+	defer func() {
+		if held {
+			release()
+		}
+	}()
+	// The processor gets the request's values but not its cancellation, so the task
+	// survives its client disconnecting unless WithCancelOnDisconnect is set.
+	eventsChan, err := s.taskManager.OnSendTaskSubscribe(context.WithoutCancel(ctx), params)
 	if err != nil {
 		log.Errorf("Error calling OnSendTaskSubscribe for task %s: %v", params.ID, err)
 		s.writeJSONRPCError(w, request.ID,
@@ -467,12 +1302,50 @@ func (s *A2AServer) handleTasksSendSubscribe(ctx context.Context, w http.Respons
 	}
 
 	// Use the helper function to handle the SSE stream
-	s.handleSSEStream(ctx, w, flusher, eventsChan, params.ID, request.ID, false)
+	if s.handleSSEStream(ctx, w, flusher, eventsChan, params.ID, request.ID, false) {
+		return
+	}
+	// The client went away before the task finished.
+	if s.cancelOnDisconnect {
+		s.cancelDisconnectedTask(ctx, params.ID)
+	} else if s.workers != nil {
+		held = false
+		go holdWorker(eventsChan, release)
+	}
+}
+
+// holdWorker frees the worker of a task that outlived its stream once the task
+// sends its final status or its event channel closes.
+func holdWorker(eventsChan <-chan protocol.TaskEvent, release func()) {
+	defer release()
+	for event := range eventsChan {
+		if event.IsFinal() {
+			return
+		}
+	}
+}
+
+// cancelDisconnectedTask cancels the task whose client closed its stream, unless
+// the task already reached a final state.
+func (s *A2AServer) cancelDisconnectedTask(ctx context.Context, taskID string) {
+	// The request context is done; cancel with its values but without its deadline.
+	ctx = context.WithoutCancel(ctx)
+	task, err := s.taskManager.OnGetTask(ctx, protocol.TaskQueryParams{ID: taskID})
+	if err != nil || task.Status.State.IsFinal() {
+		return
+	}
+	params := protocol.TaskIDParams{ID: taskID, Reason: protocol.CancelReasonClientDisconnected}
+	if _, err := s.taskManager.OnCancelTask(ctx, params); err != nil {
+		log.Warnf("Failed to cancel task %s after its client disconnected: %v", taskID, err)
+		return
+	}
+	log.Infof("Canceled task %s after its client disconnected", taskID)
 }
 
 // writeJSONRPCResponse encodes and writes a successful JSON-RPC response.
 func (s *A2AServer) writeJSONRPCResponse(w http.ResponseWriter, id interface{}, result interface{}) {
-	response := jsonrpc.NewResponse(id, result)
+	response := jsonrpc.NewResponse(id, s.formatTimes(result))
+	s.logResponseBody(id, response)
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusOK) // Success is always 200 OK for JSON-RPC itself.
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -489,7 +1362,8 @@ func (s *A2AServer) writeJSONRPCError(w http.ResponseWriter, id interface{}, err
 		err = jsonrpc.ErrInternalError("writeJSONRPCError called with nil error")
 		log.Errorf("Programming ERROR: writeJSONRPCError called with nil error (Request ID: %v)", id)
 	}
-	response := jsonrpc.NewErrorResponse(id, err)
+	response := jsonrpc.NewErrorResponse(id, s.redactError(err))
+	s.logResponseBody(id, response)
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	// Map JSON-RPC error codes to HTTP status codes where appropriate.
 	httpStatus := http.StatusInternalServerError // Default for Internal errors.
@@ -502,6 +1376,10 @@ func (s *A2AServer) writeJSONRPCError(w http.ResponseWriter, id interface{}, err
 		httpStatus = http.StatusNotFound
 	case jsonrpc.CodeInvalidParams:
 		httpStatus = http.StatusBadRequest
+	case ErrCodeServerBusy:
+		httpStatus = http.StatusServiceUnavailable
+	case ErrCodeAdmissionDenied:
+		httpStatus = http.StatusForbidden
 		// Add other mappings for custom server errors (-32000 to -32099) if desired.
 	}
 	w.WriteHeader(httpStatus)
@@ -511,12 +1389,22 @@ func (s *A2AServer) writeJSONRPCError(w http.ResponseWriter, id interface{}, err
 	}
 }
 
+// logResponseBody logs body, a response or SSE event for the request with the
+// given ID, at debug level with secrets redacted, if WithBodyLogging is set.
+func (s *A2AServer) logResponseBody(id interface{}, body interface{}) {
+	if s.logBodies {
+		log.Debugf("JSON-RPC response body (ID: %v): %s", id, redact.Value(body))
+	}
+}
+
 // setCORSHeaders adds permissive CORS headers for development/testing.
 // WARNING: This is insecure for production. Configure origins explicitly.
 func (s *A2AServer) setCORSHeaders(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*") // INSECURE
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-None-Match, "+
+		protocol.HeaderStreamEventFilter+", "+protocol.HeaderIdempotencyKey+", "+protocol.HeaderBaggage)
+	w.Header().Set("Access-Control-Expose-Headers", "ETag, Retry-After")
 	// Max-Age might be useful but not strictly necessary here.
 }
 
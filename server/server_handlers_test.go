@@ -9,12 +9,20 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -23,6 +31,8 @@ import (
 
 	"trpc.group/trpc-go/trpc-a2a-go/auth"
 	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/sse"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
 )
@@ -174,6 +184,28 @@ func TestA2AServer_HandlerErrors(t *testing.T) {
 			jsonrpc.CodeInvalidRequest, "jsonrpc field must be '2.0'")
 	})
 
+	// Test missing JSONRPC version
+	t.Run("Missing JSONRPC Version", func(t *testing.T) {
+		reqBody := bytes.NewBufferString(`{"method":"tasks/get","params":{"id":"t"},"id":"test-id"}`)
+		testJSONRPCErrorResponse(t, testServer, http.MethodPost, reqBody, "application/json",
+			jsonrpc.CodeInvalidRequest, "jsonrpc field must be '2.0'")
+	})
+
+	// Test malformed request ids
+	for name, id := range map[string]string{
+		"Fractional ID": `1.5`,
+		"Object ID":     `{"n":1}`,
+		"Array ID":      `[1]`,
+		"Boolean ID":    `true`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			reqBody := bytes.NewBufferString(
+				`{"jsonrpc":"2.0","method":"tasks/get","params":{"id":"t"},"id":` + id + `}`)
+			testJSONRPCErrorResponse(t, testServer, http.MethodPost, reqBody, "application/json",
+				jsonrpc.CodeInvalidRequest, "id must")
+		})
+	}
+
 	// Test unknown method
 	t.Run("Unknown Method", func(t *testing.T) {
 		reqBody := bytes.NewBufferString(
@@ -505,3 +537,2350 @@ func TestA2AServer_StartStop(t *testing.T) {
 		t.Fatal("Timed out waiting for server to stop")
 	}
 }
+
+// localeProcessor records the locale seen in the processing context.
+type localeProcessor struct {
+	locale string
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *localeProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	p.locale = taskmanager.LocaleFromContext(ctx)
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+func TestA2AServer_Locale(t *testing.T) {
+	sendWithLocale := func(t *testing.T, opts []Option, locale *string, acceptLanguage string) (*localeProcessor, jsonrpc.Response) {
+		processor := &localeProcessor{}
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		ts, _ := setupTestServer(t, tm, opts...)
+		params := protocol.SendTaskParams{
+			ID:      "locale-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+			Locale:  locale,
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, "locale-req")
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		return processor, decodeJSONRPCResponse(t, resp)
+	}
+
+	t.Run("ExplicitLocale", func(t *testing.T) {
+		processor, resp := sendWithLocale(t, nil, stringPtr("fr-CA"), "de")
+		require.Nil(t, resp.Error)
+		assert.Equal(t, "fr-CA", processor.locale)
+	})
+
+	t.Run("AcceptLanguageHeader", func(t *testing.T) {
+		processor, resp := sendWithLocale(t, nil, nil, "de-DE;q=0.8, ja;q=0.9, *;q=0.1")
+		require.Nil(t, resp.Error)
+		assert.Equal(t, "ja", processor.locale)
+	})
+
+	t.Run("InvalidAcceptLanguageDefaulted", func(t *testing.T) {
+		processor, resp := sendWithLocale(t, nil, nil, "not a locale!")
+		require.Nil(t, resp.Error)
+		assert.Equal(t, protocol.DefaultLocale, processor.locale)
+	})
+
+	t.Run("ServerDefault", func(t *testing.T) {
+		processor, resp := sendWithLocale(t, []Option{WithDefaultLocale("es-419")}, nil, "")
+		require.Nil(t, resp.Error)
+		assert.Equal(t, "es-419", processor.locale)
+	})
+
+	t.Run("InvalidLocaleRejected", func(t *testing.T) {
+		processor, resp := sendWithLocale(t, nil, stringPtr("en_US!"), "")
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, resp.Error.Code)
+		assert.Empty(t, processor.locale, "processor should not run for an invalid locale")
+	})
+}
+
+// slowCountingProcessor counts calls and completes tasks after a delay.
+type slowCountingProcessor struct {
+	delay time.Duration
+	calls atomic.Int32
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *slowCountingProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	p.calls.Add(1)
+	time.Sleep(p.delay)
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+func TestA2AServer_Idempotency(t *testing.T) {
+	sendAs := func(t *testing.T, ts *httptest.Server, taskID, text, key, apiKey string) jsonrpc.Response {
+		params := protocol.SendTaskParams{
+			ID:      taskID,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)}),
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, taskID)
+		if key != "" {
+			req.Header.Set(protocol.HeaderIdempotencyKey, key)
+		}
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		return decodeJSONRPCResponse(t, resp)
+	}
+	send := func(t *testing.T, ts *httptest.Server, taskID, key string) jsonrpc.Response {
+		return sendAs(t, ts, taskID, "hi", key, "")
+	}
+	taskIDOf := func(t *testing.T, resp jsonrpc.Response) string {
+		require.Nil(t, resp.Error)
+		resultBytes, err := json.Marshal(resp.Result)
+		require.NoError(t, err)
+		var task protocol.Task
+		require.NoError(t, json.Unmarshal(resultBytes, &task))
+		return task.ID
+	}
+	newServer := func(t *testing.T, processor *slowCountingProcessor, opts ...Option) *httptest.Server {
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		ts, _ := setupTestServer(t, tm, opts...)
+		return ts
+	}
+
+	t.Run("RepeatedKeyReturnsExistingTask", func(t *testing.T) {
+		processor := &slowCountingProcessor{}
+		ts := newServer(t, processor)
+		assert.Equal(t, "task-1", taskIDOf(t, send(t, ts, "task-1", "key-1")))
+		assert.Equal(t, "task-1", taskIDOf(t, send(t, ts, "task-1", "key-1")))
+		assert.Equal(t, int32(1), processor.calls.Load())
+	})
+
+	t.Run("RepeatedKeyWithDifferentParamsRejected", func(t *testing.T) {
+		processor := &slowCountingProcessor{}
+		ts := newServer(t, processor)
+		assert.Equal(t, "task-1", taskIDOf(t, send(t, ts, "task-1", "key-1")))
+
+		resp := send(t, ts, "task-2", "key-1")
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, resp.Error.Code)
+		resp = sendAs(t, ts, "task-1", "something else", "key-1", "")
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, resp.Error.Code)
+		assert.Equal(t, int32(1), processor.calls.Load())
+	})
+
+	t.Run("KeysScopedPerUser", func(t *testing.T) {
+		processor := &slowCountingProcessor{}
+		provider := auth.NewAPIKeyAuthProvider(map[string]string{"alice-key": "alice", "bob-key": "bob"}, "")
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		_, a2aServer := setupTestServer(t, tm, WithAuthProvider(provider))
+		// Serve through Handler so that the auth middleware runs.
+		ts := httptest.NewServer(a2aServer.Handler())
+		defer ts.Close()
+
+		resp := sendAs(t, ts, "alice-task", "hi", "shared-key", "alice-key")
+		assert.Equal(t, "alice-task", taskIDOf(t, resp))
+		// Another user reusing the key must not see alice's task.
+		resp = sendAs(t, ts, "bob-task", "hi", "shared-key", "bob-key")
+		assert.Equal(t, "bob-task", taskIDOf(t, resp))
+		resp = sendAs(t, ts, "alice-task", "hi", "shared-key", "alice-key")
+		assert.Equal(t, "alice-task", taskIDOf(t, resp))
+		assert.Equal(t, int32(2), processor.calls.Load())
+	})
+
+	t.Run("ConcurrentDuplicates", func(t *testing.T) {
+		processor := &slowCountingProcessor{delay: 100 * time.Millisecond}
+		ts := newServer(t, processor)
+		results := make(chan string, 2)
+		for i := 0; i < 2; i++ {
+			go func(taskID string) {
+				resp := send(t, ts, taskID, "key-concurrent")
+				if resp.Error != nil {
+					results <- ""
+					return
+				}
+				resultBytes, _ := json.Marshal(resp.Result)
+				var task protocol.Task
+				_ = json.Unmarshal(resultBytes, &task)
+				results <- task.ID
+			}("task-a")
+		}
+		first, second := <-results, <-results
+		assert.NotEmpty(t, first)
+		assert.Equal(t, first, second)
+		assert.Equal(t, int32(1), processor.calls.Load())
+	})
+
+	t.Run("WindowExpires", func(t *testing.T) {
+		processor := &slowCountingProcessor{}
+		ts := newServer(t, processor, WithIdempotencyWindow(50*time.Millisecond))
+		assert.Equal(t, "task-1", taskIDOf(t, send(t, ts, "task-1", "key-1")))
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, "task-2", taskIDOf(t, send(t, ts, "task-2", "key-1")))
+		assert.Equal(t, int32(2), processor.calls.Load())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		processor := &slowCountingProcessor{}
+		ts := newServer(t, processor, WithIdempotencyWindow(0))
+		assert.Equal(t, "task-1", taskIDOf(t, send(t, ts, "task-1", "key-1")))
+		assert.Equal(t, "task-2", taskIDOf(t, send(t, ts, "task-2", "key-1")))
+		assert.Equal(t, int32(2), processor.calls.Load())
+	})
+
+	t.Run("NoKey", func(t *testing.T) {
+		processor := &slowCountingProcessor{}
+		ts := newServer(t, processor)
+		assert.Equal(t, "task-1", taskIDOf(t, send(t, ts, "task-1", "")))
+		assert.Equal(t, "task-2", taskIDOf(t, send(t, ts, "task-2", "")))
+		assert.Equal(t, int32(2), processor.calls.Load())
+	})
+}
+
+func TestA2AServer_TaskIDValidator(t *testing.T) {
+	idPattern := regexp.MustCompile(`^org-[0-9a-f]{8}$`)
+	validator := func(id string) error {
+		if !idPattern.MatchString(id) {
+			return errors.New("task ID must match org-<8 hex digits>")
+		}
+		return nil
+	}
+	ts, _ := setupTestServer(t, newMockTaskManager(), WithTaskIDValidator(validator))
+	message := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")})
+
+	for _, method := range []string{protocol.MethodTasksSend, protocol.MethodTasksSendSubscribe} {
+		t.Run(method+"/Invalid", func(t *testing.T) {
+			for _, id := range []string{"task-1", "org-XYZ12345", "org-0123456789"} {
+				params := protocol.SendTaskParams{ID: id, Message: message}
+				req, _ := createJSONRPCRequest(t, method, params, id)
+				resp := executeRequest(t, ts, req, ts.URL)
+				jsonResp := decodeJSONRPCResponse(t, resp)
+				resp.Body.Close()
+				require.NotNil(t, jsonResp.Error, "ID %q should be rejected", id)
+				assert.Equal(t, jsonrpc.CodeInvalidParams, jsonResp.Error.Code)
+				assert.Contains(t, jsonResp.Error.Data, "org-<8 hex digits>")
+			}
+		})
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		params := protocol.SendTaskParams{ID: "org-0a1b2c3d", Message: message}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, params.ID)
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		jsonResp := decodeJSONRPCResponse(t, resp)
+		assert.Nil(t, jsonResp.Error)
+	})
+}
+
+// concurrencyProcessor records the peak number of concurrent Process calls.
+// The task with ID blockID signals started and waits for release; others sleep for delay.
+type concurrencyProcessor struct {
+	delay    time.Duration
+	blockID  string
+	release  chan struct{}
+	started  chan struct{}
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *concurrencyProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	if taskID == p.blockID {
+		close(p.started)
+		<-p.release
+	} else {
+		time.Sleep(p.delay)
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+func TestA2AServer_WorkerPool(t *testing.T) {
+	newServer := func(t *testing.T, processor taskmanager.TaskProcessor, opts ...Option) *httptest.Server {
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		ts, _ := setupTestServer(t, tm, opts...)
+		return ts
+	}
+	send := func(t *testing.T, ts *httptest.Server, method, taskID string) *http.Response {
+		params := protocol.SendTaskParams{
+			ID:      taskID,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		}
+		req, _ := createJSONRPCRequest(t, method, params, taskID)
+		return executeRequest(t, ts, req, ts.URL)
+	}
+	newBlockingProcessor := func(blockID string) *concurrencyProcessor {
+		return &concurrencyProcessor{blockID: blockID, release: make(chan struct{}), started: make(chan struct{})}
+	}
+
+	t.Run("ConcurrencyBounded", func(t *testing.T) {
+		const poolSize, tasks = 3, 12
+		processor := &concurrencyProcessor{delay: 20 * time.Millisecond}
+		ts := newServer(t, processor, WithWorkerPool(poolSize), WithWorkerQueue(tasks, QueuePolicyBlock))
+
+		errs := make(chan error, tasks)
+		for i := 0; i < tasks; i++ {
+			go func(i int) {
+				resp := send(t, ts, protocol.MethodTasksSend, fmt.Sprintf("pool-task-%d", i))
+				defer resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					errs <- fmt.Errorf("task %d: status %d", i, resp.StatusCode)
+					return
+				}
+				errs <- nil
+			}(i)
+		}
+		for i := 0; i < tasks; i++ {
+			assert.NoError(t, <-errs)
+		}
+		assert.LessOrEqual(t, processor.peak.Load(), int32(poolSize))
+		assert.Equal(t, int32(poolSize), processor.peak.Load(), "pool should be fully used")
+	})
+
+	t.Run("RejectWhenFull", func(t *testing.T) {
+		processor := newBlockingProcessor("busy-task-1")
+		ts := newServer(t, processor, WithWorkerPool(1), WithWorkerQueue(0, QueuePolicyReject))
+
+		done := make(chan int)
+		go func() {
+			resp := send(t, ts, protocol.MethodTasksSend, "busy-task-1")
+			resp.Body.Close()
+			done <- resp.StatusCode
+		}()
+		<-processor.started
+
+		resp := send(t, ts, protocol.MethodTasksSend, "busy-task-2")
+		jsonResp := decodeJSONRPCResponse(t, resp)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.NotNil(t, jsonResp.Error)
+		assert.Equal(t, ErrCodeServerBusy, jsonResp.Error.Code)
+
+		close(processor.release)
+		assert.Equal(t, http.StatusOK, <-done)
+	})
+
+	t.Run("StreamingHoldsWorkerUntilDone", func(t *testing.T) {
+		processor := newBlockingProcessor("stream-task")
+		ts := newServer(t, processor, WithWorkerPool(1), WithWorkerQueue(0, QueuePolicyReject))
+
+		stream := send(t, ts, protocol.MethodTasksSendSubscribe, "stream-task")
+		defer stream.Body.Close()
+		require.Equal(t, http.StatusOK, stream.StatusCode)
+		<-processor.started
+
+		// The streaming processor is still running, so it keeps the single worker.
+		resp := send(t, ts, protocol.MethodTasksSend, "during-stream-task")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+		// Once the stream finishes the worker is free again.
+		close(processor.release)
+		_, err := io.Copy(io.Discard, stream.Body)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			resp := send(t, ts, protocol.MethodTasksSend, "after-stream-task")
+			resp.Body.Close()
+			return resp.StatusCode == http.StatusOK
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("HandlerOnlyStartsNoWorkers", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		for i := 0; i < 5; i++ {
+			_, err := NewA2AServer(defaultAgentCard(), newMockTaskManager(), WithWorkerPool(8))
+			require.NoError(t, err)
+		}
+		assert.Less(t, runtime.NumGoroutine(), before+8, "unused pools should not start goroutines")
+	})
+}
+
+// disconnectProcessor completes tasks at once, except for disconnect-task, for
+// which it signals started and runs until its context is canceled, closing
+// stopped, or until release is closed.
+type disconnectProcessor struct {
+	started chan struct{}
+	stopped chan struct{}
+	release chan struct{}
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *disconnectProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	if taskID != "disconnect-task" {
+		return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+	}
+	close(p.started)
+	select {
+	case <-ctx.Done():
+		close(p.stopped)
+		return ctx.Err()
+	case <-p.release:
+		return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+	}
+}
+
+// floodProcessor emits chunks large artifacts as fast as the task manager lets it,
+// recording the longest an emit call blocked.
+type floodProcessor struct {
+	chunks   int
+	longest  time.Duration
+	finished chan struct{}
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *floodProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	defer close(p.finished)
+	chunk := strings.Repeat("x", 64<<10)
+	for i := 0; i < p.chunks; i++ {
+		start := time.Now()
+		if err := handle.AddArtifact(protocol.Artifact{
+			Index: i,
+			Parts: []protocol.Part{protocol.NewTextPart(chunk)},
+		}); err != nil {
+			return err
+		}
+		p.longest = max(p.longest, time.Since(start))
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+func TestA2AServer_WithSlowClientTimeout(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	// Far more than the socket buffers between the server and the client hold.
+	processor := &floodProcessor{chunks: 400, finished: make(chan struct{})}
+	tm, err := taskmanager.NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+	ts, _ := setupTestServer(t, tm, WithSlowClientTimeout(timeout))
+	params := protocol.SendTaskParams{
+		ID:      "flood-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+	}
+	req, _ := createJSONRPCRequest(t, protocol.MethodTasksSendSubscribe, params, "flood-req")
+	stream := executeRequest(t, ts, req, ts.URL)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	// The client reads nothing: the processor is held back, then the client dropped.
+	select {
+	case <-processor.finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("processor blocked on the slow client for good")
+	}
+	assert.GreaterOrEqual(t, processor.longest, timeout, "emitting should wait for the slow client")
+
+	// The stream ends without the events the client was too slow for.
+	reader := sse.NewEventReader(stream.Body)
+	artifacts, final := 0, false
+	for {
+		data, eventType, err := reader.ReadEvent()
+		if err != nil {
+			break
+		}
+		switch eventType {
+		case protocol.EventTaskArtifactUpdate:
+			artifacts++
+		case protocol.EventTaskStatusUpdate:
+			final = final || strings.Contains(string(data), `"final":true`)
+		}
+	}
+	assert.Less(t, artifacts, processor.chunks)
+	assert.False(t, final, "the dropped client should not see the final status")
+}
+
+func TestA2AServer_CancelOnDisconnect(t *testing.T) {
+	// subscribe starts the task over a stream, returning its response and the manager.
+	subscribe := func(t *testing.T, opts ...Option) (
+		*disconnectProcessor, taskmanager.TaskManager, *httptest.Server, *http.Response,
+	) {
+		processor := &disconnectProcessor{
+			started: make(chan struct{}),
+			stopped: make(chan struct{}),
+			release: make(chan struct{}),
+		}
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		ts, _ := setupTestServer(t, tm, opts...)
+		params := protocol.SendTaskParams{
+			ID:      "disconnect-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSendSubscribe, params, "disconnect-req")
+		stream := executeRequest(t, ts, req, ts.URL)
+		require.Equal(t, http.StatusOK, stream.StatusCode)
+		<-processor.started
+		return processor, tm, ts, stream
+	}
+	taskState := func(t *testing.T, tm taskmanager.TaskManager) *protocol.Task {
+		task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "disconnect-task"})
+		require.NoError(t, err)
+		return task
+	}
+
+	t.Run("Enabled", func(t *testing.T) {
+		processor, tm, _, stream := subscribe(t, WithCancelOnDisconnect())
+		// Closing the body before the stream ends drops the connection.
+		require.NoError(t, stream.Body.Close())
+
+		select {
+		case <-processor.stopped:
+		case <-time.After(2 * time.Second):
+			t.Fatal("processor was not canceled after the client disconnected")
+		}
+		require.Eventually(t, func() bool {
+			return taskState(t, tm).Status.State == protocol.TaskStateCanceled
+		}, 2*time.Second, 10*time.Millisecond)
+		task := taskState(t, tm)
+		require.NotNil(t, task.Status.CancelReason)
+		assert.Equal(t, protocol.CancelReasonClientDisconnected, *task.Status.CancelReason)
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		processor, tm, _, stream := subscribe(t)
+		require.NoError(t, stream.Body.Close())
+
+		select {
+		case <-processor.stopped:
+			t.Fatal("processor was canceled although the option is off")
+		case <-time.After(100 * time.Millisecond):
+		}
+		assert.Equal(t, protocol.TaskStateWorking, taskState(t, tm).Status.State)
+		close(processor.release)
+		require.Eventually(t, func() bool {
+			return taskState(t, tm).Status.State == protocol.TaskStateCompleted
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("SurvivingTaskKeepsWorker", func(t *testing.T) {
+		processor, tm, ts, stream := subscribe(t, WithWorkerPool(1), WithWorkerQueue(0, QueuePolicyReject))
+		require.NoError(t, stream.Body.Close())
+		send := func() int {
+			params := protocol.SendTaskParams{
+				ID:      "next-task",
+				Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+			}
+			req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, "next-req")
+			resp := executeRequest(t, ts, req, ts.URL)
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, http.StatusServiceUnavailable, send(), "the running task keeps the only worker")
+		assert.Equal(t, protocol.TaskStateWorking, taskState(t, tm).Status.State)
+
+		// The worker is freed once the task finishes.
+		close(processor.release)
+		require.Eventually(t, func() bool {
+			return taskState(t, tm).Status.State == protocol.TaskStateCompleted
+		}, 2*time.Second, 10*time.Millisecond)
+		require.Eventually(t, func() bool { return send() == http.StatusOK }, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("FinishedStreamKeepsTask", func(t *testing.T) {
+		processor, tm, _, stream := subscribe(t, WithCancelOnDisconnect())
+		close(processor.release)
+		_, err := io.Copy(io.Discard, stream.Body)
+		require.NoError(t, err)
+		require.NoError(t, stream.Body.Close())
+		assert.Equal(t, protocol.TaskStateCompleted, taskState(t, tm).Status.State)
+	})
+}
+
+// startOrderProcessor records the order in which tasks start. The task with ID
+// blockID signals started and waits for release before completing.
+type startOrderProcessor struct {
+	blockID string
+	started chan struct{}
+	release chan struct{}
+	mu      sync.Mutex
+	order   []string
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *startOrderProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	p.mu.Lock()
+	p.order = append(p.order, taskID)
+	p.mu.Unlock()
+	if taskID == p.blockID {
+		close(p.started)
+		<-p.release
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// waitingTasks returns the number of tasks waiting in a fair scheduler.
+func waitingTasks(f *fairScheduler) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, queue := range f.waiting {
+		n += len(queue)
+	}
+	return n
+}
+
+func TestA2AServer_FairScheduling(t *testing.T) {
+	t.Run("FloodingSessionDoesNotStarveOthers", func(t *testing.T) {
+		processor := &startOrderProcessor{
+			blockID: "flood-0", started: make(chan struct{}), release: make(chan struct{}),
+		}
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		ts, s := setupTestServer(t, tm, WithWorkerPool(1), WithWorkerQueue(20, QueuePolicyBlock), WithFairScheduling())
+		assert.True(t, s.debugInfo().Config.FairScheduling)
+
+		var wg sync.WaitGroup
+		send := func(taskID, sessionID string) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				params := protocol.SendTaskParams{
+					ID:        taskID,
+					SessionID: &sessionID,
+					Message:   protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+				}
+				req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, taskID)
+				resp := executeRequest(t, ts, req, ts.URL)
+				resp.Body.Close()
+			}()
+		}
+		send("flood-0", "busy")
+		<-processor.started
+		queue := func(taskID, sessionID string) {
+			want := waitingTasks(s.workers.fair) + 1
+			send(taskID, sessionID)
+			require.Eventually(t, func() bool { return waitingTasks(s.workers.fair) == want }, 2*time.Second, time.Millisecond)
+		}
+		for i := 1; i <= 5; i++ {
+			queue(fmt.Sprintf("flood-%d", i), "busy")
+		}
+		queue("quiet-task", "quiet")
+
+		close(processor.release)
+		wg.Wait()
+		// The busy session's running and next task go first; then it is the quiet
+		// session's turn, although five busy tasks arrived before it.
+		assert.Equal(t, []string{"flood-0", "flood-1", "quiet-task", "flood-2", "flood-3", "flood-4", "flood-5"},
+			processor.order)
+	})
+
+	t.Run("CancelledWaiterFreesTurn", func(t *testing.T) {
+		pool := newWorkerPool(1, 5, QueuePolicyBlock, true)
+		release, err := pool.acquire(context.Background(), "a")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errs := make(chan error, 1)
+		go func() {
+			_, err := pool.acquire(ctx, "b")
+			errs <- err
+		}()
+		require.Eventually(t, func() bool { return waitingTasks(pool.fair) == 1 }, 2*time.Second, time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-errs, context.Canceled)
+		assert.Zero(t, waitingTasks(pool.fair))
+
+		release()
+		acquired := make(chan struct{})
+		go func() {
+			release, err := pool.acquire(context.Background(), "c")
+			assert.NoError(t, err)
+			release()
+			close(acquired)
+		}()
+		select {
+		case <-acquired:
+		case <-time.After(2 * time.Second):
+			t.Fatal("the slot freed by the first task was not handed out")
+		}
+		pool.close()
+	})
+}
+
+func TestA2AServer_StrictJSONRPC(t *testing.T) {
+	send := func(t *testing.T, ts *httptest.Server, body string) jsonrpc.Response {
+		req, err := http.NewRequest(http.MethodPost, ts.URL, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return decodeJSONRPCResponse(t, resp)
+	}
+	mockTM := newMockTaskManager()
+	mockTM.tasks["legacy-task"] = &protocol.Task{ID: "legacy-task"}
+
+	t.Run("ValidIDs", func(t *testing.T) {
+		ts, _ := setupTestServer(t, mockTM)
+		for _, id := range []string{`"abc"`, `42`, `-7`, `null`} {
+			resp := send(t, ts, `{"jsonrpc":"2.0","method":"tasks/get","params":{"id":"legacy-task"},"id":`+id+`}`)
+			assert.Nil(t, resp.Error, "id %s should be accepted", id)
+		}
+	})
+
+	t.Run("InvalidIDNotEchoed", func(t *testing.T) {
+		ts, _ := setupTestServer(t, mockTM)
+		resp := send(t, ts, `{"jsonrpc":"2.0","method":"tasks/get","params":{"id":"legacy-task"},"id":2.5}`)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidRequest, resp.Error.Code)
+		assert.Nil(t, resp.ID)
+	})
+
+	t.Run("Relaxed", func(t *testing.T) {
+		ts, _ := setupTestServer(t, mockTM, WithStrictJSONRPC(false))
+		resp := send(t, ts, `{"jsonrpc":"1.0","method":"tasks/get","params":{"id":"legacy-task"},"id":1.5}`)
+		assert.Nil(t, resp.Error)
+		assert.Equal(t, 1.5, resp.ID)
+	})
+}
+
+func TestA2AServer_RequestLimits(t *testing.T) {
+	post := func(t *testing.T, ts *httptest.Server, body string) jsonrpc.Response {
+		resp, err := ts.Client().Post(ts.URL, "application/json", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return decodeJSONRPCResponse(t, resp)
+	}
+	mockTM := newMockTaskManager()
+	mockTM.tasks["task"] = &protocol.Task{ID: "task"}
+	nested := func(depth int) string {
+		return `{"jsonrpc":"2.0","method":"tasks/get","id":1,"params":{"id":"task","metadata":{"x":` +
+			strings.Repeat("[", depth) + strings.Repeat("]", depth) + `}}}`
+	}
+
+	t.Run("DefaultDepth", func(t *testing.T) {
+		ts, _ := setupTestServer(t, mockTM)
+		assert.Nil(t, post(t, ts, nested(20)).Error)
+		resp := post(t, ts, nested(10000))
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, jsonrpc.CodeParseError, resp.Error.Code)
+		assert.Contains(t, resp.Error.Data, "nested too deeply")
+	})
+
+	t.Run("CustomDepth", func(t *testing.T) {
+		ts, _ := setupTestServer(t, mockTM, WithMaxJSONDepth(4))
+		resp := post(t, ts, nested(2))
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, jsonrpc.CodeParseError, resp.Error.Code)
+
+		ts, _ = setupTestServer(t, mockTM, WithMaxJSONDepth(0))
+		assert.Nil(t, post(t, ts, nested(500)).Error)
+	})
+
+	t.Run("MaxRequestSize", func(t *testing.T) {
+		body := `{"jsonrpc":"2.0","method":"tasks/get","id":1,"params":{"id":"task"}}`
+		ts, _ := setupTestServer(t, mockTM, WithMaxRequestSize(int64(len(body))))
+		assert.Nil(t, post(t, ts, body).Error)
+		resp := post(t, ts, body+" ")
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidRequest, resp.Error.Code)
+	})
+}
+
+// FuzzHandleJSONRPC checks that arbitrary request bodies never panic the server
+// and that every non-streaming reply is a well-formed JSON-RPC response.
+func FuzzHandleJSONRPC(f *testing.F) {
+	seeds := []string{
+		`{"jsonrpc":"2.0","method":"tasks/get","params":{"id":"task"},"id":1}`,
+		`{"jsonrpc":"2.0","method":"tasks/send","params":{"id":"task","message":{"role":"user",` +
+			`"parts":[{"type":"text","text":"hi"}]}},"id":"a"}`,
+		`{"jsonrpc":"2.0","method":"tasks/send","params":{"id":"task","message":{"role":"user",` +
+			`"parts":[{"type":"data","encoding":"gzip","data":"H4sI"}]}},"id":"a"}`,
+		`{"jsonrpc":"2.0","method":"tasks/cancel","params":{"id":"task"},"id":null}`,
+		`{"jsonrpc":"2.0","method":"tasks/pushNotification/set","params":{},"id":[1]}`,
+		`{"jsonrpc":"2.0","method":"tasks/get","params":[],"id":{}}`,
+		`{"jsonrpc":"2.0","method":"tasks/get","params":{"id":"task"`,
+		`[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[`,
+		`{"jsonrpc":"2.0","method":"tasks/send","params":{"message":{"parts":[` +
+			strings.Repeat(`{"type":"text","text":""},`, 200) + `{}]}},"id":1}`,
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+	mockTM := newMockTaskManager()
+	mockTM.tasks["task"] = &protocol.Task{ID: "task"}
+	a2aServer, err := NewA2AServer(defaultAgentCard(), mockTM)
+	require.NoError(f, err)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		a2aServer.handleJSONRPC(rec, req)
+		if !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+			return // Streaming replies are server-sent events.
+		}
+		var resp jsonrpc.RawResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("reply is not JSON: %v: %q", err, rec.Body.String())
+		}
+		if resp.JSONRPC != jsonrpc.Version {
+			t.Fatalf("reply has wrong jsonrpc version: %q", rec.Body.String())
+		}
+	})
+}
+
+func TestA2AServer_ParamSchema(t *testing.T) {
+	// tasks/send must name a sessionId, which the protocol leaves optional.
+	schema := json.RawMessage(`{
+		"type": "object",
+		"required": ["id", "sessionId"],
+		"properties": {"sessionId": {"type": "string", "minLength": 1}}
+	}`)
+	mockTM := newMockTaskManager()
+	ts, _ := setupTestServer(t, mockTM, WithParamSchema(protocol.MethodTasksSend, schema))
+	send := func(t *testing.T, params interface{}) jsonrpc.Response {
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, "req-1")
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		return decodeJSONRPCResponse(t, resp)
+	}
+	message := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")})
+
+	t.Run("Valid", func(t *testing.T) {
+		sessionID := "session-1"
+		mockTM.SendResponse = &protocol.Task{ID: "task-1", Status: protocol.TaskStatus{State: protocol.TaskStateCompleted}}
+		resp := send(t, protocol.SendTaskParams{ID: "task-1", SessionID: &sessionID, Message: message})
+		assert.Nil(t, resp.Error)
+	})
+
+	t.Run("MissingField", func(t *testing.T) {
+		resp := send(t, protocol.SendTaskParams{ID: "task-2", Message: message})
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, resp.Error.Code)
+		assert.Equal(t, "req-1", resp.ID)
+		data, err := json.Marshal(resp.Error.Data)
+		require.NoError(t, err)
+		var violations []ParamViolation
+		require.NoError(t, json.Unmarshal(data, &violations))
+		require.Len(t, violations, 1)
+		assert.Equal(t, "", violations[0].Location)
+		assert.Contains(t, violations[0].Message, "sessionId")
+	})
+
+	t.Run("InvalidField", func(t *testing.T) {
+		resp := send(t, map[string]interface{}{"id": "task-3", "sessionId": ""})
+		require.NotNil(t, resp.Error)
+		data, err := json.Marshal(resp.Error.Data)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"location":"/sessionId"`)
+	})
+
+	t.Run("OtherMethodsUnchecked", func(t *testing.T) {
+		mockTM.tasks["task-4"] = &protocol.Task{ID: "task-4"}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksGet, protocol.TaskQueryParams{ID: "task-4"}, "req-2")
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		assert.Nil(t, decodeJSONRPCResponse(t, resp).Error)
+	})
+
+	t.Run("InvalidSchema", func(t *testing.T) {
+		_, err := NewA2AServer(defaultAgentCard(), mockTM,
+			WithParamSchema(protocol.MethodTasksSend, json.RawMessage(`{"type": 42}`)))
+		assert.ErrorContains(t, err, "invalid params schema for tasks/send")
+	})
+}
+
+func TestA2AServer_TaskRetention(t *testing.T) {
+	t.Run("RequiresPruner", func(t *testing.T) {
+		_, err := NewA2AServer(defaultAgentCard(), newMockTaskManager(), WithTaskRetention(time.Hour, nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("SweeperEvictsAndArchives", func(t *testing.T) {
+		tm, err := taskmanager.NewMemoryTaskManager(&localeProcessor{})
+		require.NoError(t, err)
+		var archived []string
+		var archivedMu sync.Mutex
+		s, err := NewA2AServer(defaultAgentCard(), tm, WithTaskRetention(time.Hour, func(task protocol.Task) {
+			archivedMu.Lock()
+			defer archivedMu.Unlock()
+			archived = append(archived, task.ID)
+		}))
+		require.NoError(t, err)
+
+		// Drive the sweeper with a fake clock and ticker.
+		now := time.Now()
+		var clockMu sync.Mutex
+		s.retention.now = func() time.Time {
+			clockMu.Lock()
+			defer clockMu.Unlock()
+			return now
+		}
+		advance := func(d time.Duration) {
+			clockMu.Lock()
+			defer clockMu.Unlock()
+			now = now.Add(d)
+		}
+		ticks := make(chan time.Time)
+		var interval time.Duration
+		s.retention.newTicker = func(d time.Duration) (<-chan time.Time, func()) {
+			interval = d
+			return ticks, func() {}
+		}
+		s.retention.start()
+		defer s.retention.close()
+		assert.Equal(t, time.Minute, interval)
+
+		_, err = tm.OnSendTask(context.Background(), protocol.SendTaskParams{
+			ID:      "old-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		})
+		require.NoError(t, err)
+		// tick delivers a tick and waits for the sweep it triggers to finish;
+		// the loop only takes the second tick once the first one is handled.
+		tick := func() {
+			ticks <- now
+			ticks <- now
+		}
+
+		tick()
+		assert.Equal(t, int64(0), s.TaskRetentionStats().Evicted, "Task is within the retention period")
+
+		s.PauseTaskRetention()
+		advance(2 * time.Hour)
+		before := s.TaskRetentionStats().Sweeps
+		tick()
+		assert.Equal(t, before, s.TaskRetentionStats().Sweeps, "Paused sweeper should not sweep")
+		assert.True(t, s.TaskRetentionStats().Paused)
+
+		s.ResumeTaskRetention()
+		tick()
+		stats := s.TaskRetentionStats()
+		assert.Equal(t, int64(1), stats.Evicted)
+		assert.False(t, stats.LastSweep.IsZero())
+		archivedMu.Lock()
+		assert.Equal(t, []string{"old-task"}, archived)
+		archivedMu.Unlock()
+		_, err = tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "old-task"})
+		assert.Error(t, err)
+		assert.Equal(t, int64(1), s.debugInfo().TaskRetention.Evicted)
+	})
+
+	t.Run("ManualSweep", func(t *testing.T) {
+		tm, err := taskmanager.NewMemoryTaskManager(&localeProcessor{})
+		require.NoError(t, err)
+		s, err := NewA2AServer(defaultAgentCard(), tm, WithTaskRetention(time.Second, nil))
+		require.NoError(t, err)
+		s.retention.now = func() time.Time { return time.Now().Add(time.Hour) }
+		_, err = tm.OnSendTask(context.Background(), protocol.SendTaskParams{
+			ID:      "manual-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		})
+		require.NoError(t, err)
+		evicted, err := s.SweepTasks(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, evicted)
+	})
+}
+
+func TestA2AServer_MaxHistoryBytes(t *testing.T) {
+	t.Run("RequiresHistoryLimiter", func(t *testing.T) {
+		_, err := NewA2AServer(defaultAgentCard(), newMockTaskManager(), WithMaxHistoryBytes(1024))
+		assert.Error(t, err)
+	})
+
+	t.Run("EvictsOldestMessages", func(t *testing.T) {
+		tm, err := taskmanager.NewMemoryTaskManager(&localeProcessor{})
+		require.NoError(t, err)
+		const maxBytes = 400
+		s, err := NewA2AServer(defaultAgentCard(), tm, WithMaxHistoryBytes(maxBytes))
+		require.NoError(t, err)
+		assert.Equal(t, maxBytes, s.debugInfo().Config.MaxHistoryBytes)
+
+		var seeds []protocol.Message
+		for i := 0; i < 10; i++ {
+			seeds = append(seeds, protocol.NewMessage(protocol.MessageRoleUser,
+				[]protocol.Part{protocol.NewTextPart(fmt.Sprintf("message %d %s", i, strings.Repeat("x", 40)))}))
+		}
+		oversized := protocol.NewMessage(protocol.MessageRoleUser,
+			[]protocol.Part{protocol.NewTextPart(strings.Repeat("y", 2*maxBytes))})
+		_, err = tm.OnSendTask(context.Background(), protocol.SendTaskParams{
+			ID:       "history-task",
+			Messages: append(seeds, oversized),
+			Message:  protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("latest")}),
+		})
+		require.NoError(t, err)
+
+		historyLength := 100
+		task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{
+			ID:            "history-task",
+			HistoryLength: &historyLength,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, task.History)
+		assert.Less(t, len(task.History), len(seeds), "oldest messages are evicted")
+		encoded, err := json.Marshal(task.History)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(encoded), maxBytes)
+		assert.NotContains(t, string(encoded), "yyyy", "an oversized message is not stored")
+		last := task.History[len(task.History)-1]
+		assert.Equal(t, "latest", last.Parts[0].(protocol.TextPart).Text)
+	})
+}
+
+func TestA2AServer_ProcessorTimeout(t *testing.T) {
+	_, err := NewA2AServer(defaultAgentCard(), newMockTaskManager(), WithProcessorTimeout(time.Second))
+	assert.Error(t, err, "the task manager must implement taskmanager.ProcessorLimiter")
+
+	tm, err := taskmanager.NewMemoryTaskManager(&localeProcessor{})
+	require.NoError(t, err)
+	s, err := NewA2AServer(defaultAgentCard(), tm, WithProcessorTimeout(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, "1s", s.debugInfo().Config.ProcessorTimeout)
+}
+
+func TestA2AServer_MaxArtifactsPerTask(t *testing.T) {
+	_, err := NewA2AServer(defaultAgentCard(), newMockTaskManager(), WithMaxArtifactsPerTask(2))
+	assert.Error(t, err, "the task manager must implement taskmanager.ArtifactLimiter")
+
+	tm, err := taskmanager.NewMemoryTaskManager(&localeProcessor{})
+	require.NoError(t, err)
+	s, err := NewA2AServer(defaultAgentCard(), tm, WithMaxArtifactsPerTask(2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.debugInfo().Config.MaxArtifacts)
+}
+
+func TestA2AServer_TaskSnapshots(t *testing.T) {
+	_, err := NewA2AServer(defaultAgentCard(), newMockTaskManager(), WithTaskSnapshots())
+	assert.Error(t, err, "the task manager must implement taskmanager.TaskSnapshotter")
+
+	tm, err := taskmanager.NewMemoryTaskManager(&localeProcessor{})
+	require.NoError(t, err)
+	s, err := NewA2AServer(defaultAgentCard(), tm, WithTaskSnapshots())
+	require.NoError(t, err)
+	assert.NotContains(t, s.debugInfo().Methods, protocol.MethodTasksExport, "disabled without authentication")
+}
+
+// rowsProcessor reads the rows of the first DataPart of the message with a
+// protocol.DataDecoder.
+type rowsProcessor struct {
+	raw  bool
+	rows []int
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *rowsProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	part := msg.Parts[0].(protocol.DataPart)
+	_, p.raw = part.Data.(json.RawMessage)
+	dec, err := protocol.NewDataDecoder(part)
+	if err != nil {
+		return err
+	}
+	for dec.Next() {
+		var row int
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		p.rows = append(p.rows, row)
+	}
+	if err := dec.Err(); err != nil {
+		return err
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+func TestA2AServer_RawDataParts(t *testing.T) {
+	send := func(t *testing.T, opts ...Option) *rowsProcessor {
+		processor := &rowsProcessor{}
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		ts, _ := setupTestServer(t, tm, opts...)
+		params := protocol.SendTaskParams{
+			ID: "rows-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{
+				protocol.DataPart{Type: protocol.PartTypeData, Data: []int{3, 1, 2}},
+			}),
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, "rows-req")
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		require.Nil(t, decodeJSONRPCResponse(t, resp).Error)
+		return processor
+	}
+
+	t.Run("Raw", func(t *testing.T) {
+		processor := send(t, WithRawDataParts(1))
+		assert.True(t, processor.raw, "the payload reaches the processor undecoded")
+		assert.Equal(t, []int{3, 1, 2}, processor.rows)
+	})
+
+	t.Run("Decoded", func(t *testing.T) {
+		processor := send(t)
+		assert.False(t, processor.raw)
+		assert.Equal(t, []int{3, 1, 2}, processor.rows)
+	})
+
+	t.Run("DebugConfig", func(t *testing.T) {
+		tm, err := taskmanager.NewMemoryTaskManager(&localeProcessor{})
+		require.NoError(t, err)
+		s, err := NewA2AServer(defaultAgentCard(), tm, WithRawDataParts(1<<20))
+		require.NoError(t, err)
+		assert.Equal(t, 1<<20, s.debugInfo().Config.RawDataMinSize)
+	})
+}
+
+func TestA2AServer_ErrorVerbosity(t *testing.T) {
+	// fail sends a task the task manager fails with an internal error and an
+	// invalid request, returning both errors.
+	fail := func(t *testing.T, opts ...Option) (internal, invalid *jsonrpc.Error) {
+		tm := newMockTaskManager()
+		tm.SendError = errors.New("database password rejected")
+		ts, _ := setupTestServer(t, tm, opts...)
+		send := func(params protocol.SendTaskParams) *jsonrpc.Error {
+			req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, "verbosity-req")
+			resp := executeRequest(t, ts, req, ts.URL)
+			defer resp.Body.Close()
+			rpcResp := decodeJSONRPCResponse(t, resp)
+			require.NotNil(t, rpcResp.Error)
+			return rpcResp.Error
+		}
+		message := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")})
+		internal = send(protocol.SendTaskParams{ID: "verbosity-task", Message: message})
+		invalid = send(protocol.SendTaskParams{Message: message})
+		return internal, invalid
+	}
+
+	t.Run("TerseByDefault", func(t *testing.T) {
+		internal, invalid := fail(t)
+		assert.Equal(t, jsonrpc.CodeInternalError, internal.Code)
+		assert.Equal(t, "Internal error", internal.Message)
+		assert.Nil(t, internal.Data, "the cause must not reach the client")
+		assert.Equal(t, jsonrpc.CodeInvalidParams, invalid.Code)
+		assert.NotNil(t, invalid.Data, "errors caused by the request keep their details")
+	})
+
+	t.Run("Verbose", func(t *testing.T) {
+		internal, invalid := fail(t, WithErrorVerbosity(ErrorVerbosityVerbose))
+		assert.Equal(t, jsonrpc.CodeInternalError, internal.Code)
+		assert.Contains(t, internal.Data, "database password rejected")
+		assert.NotNil(t, invalid.Data)
+	})
+
+	t.Run("DebugConfig", func(t *testing.T) {
+		s, err := NewA2AServer(defaultAgentCard(), newMockTaskManager())
+		require.NoError(t, err)
+		assert.Equal(t, "terse", s.debugInfo().Config.ErrorVerbosity)
+		s, err = NewA2AServer(defaultAgentCard(), newMockTaskManager(), WithErrorVerbosity(ErrorVerbosityVerbose))
+		require.NoError(t, err)
+		assert.Equal(t, "verbose", s.debugInfo().Config.ErrorVerbosity)
+	})
+}
+
+// inlineFile returns a FilePart carrying data, declared as mimeType unless it is empty.
+func inlineFile(name, mimeType string, data []byte) protocol.FilePart {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	part := protocol.FilePart{
+		Type: protocol.PartTypeFile,
+		File: protocol.FileContent{Name: &name, Bytes: &encoded},
+	}
+	if mimeType != "" {
+		part.File.MimeType = &mimeType
+	}
+	return part
+}
+
+func TestA2AServer_AllowedFileTypes(t *testing.T) {
+	pngData := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdfData := []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	jsonData := []byte(`{"hello": "world"}`)
+	uri := "https://example.com/diagram.jpg"
+	jpegType := "image/jpeg"
+
+	tests := []struct {
+		name    string
+		part    protocol.FilePart
+		wantErr string
+	}{
+		{name: "Allowed", part: inlineFile("logo.png", "image/png", pngData)},
+		{name: "AllowedUndeclared", part: inlineFile("logo.png", "", pngData)},
+		{name: "AllowedWithParams", part: inlineFile("logo.png", "Image/PNG; q=1", pngData)},
+		{name: "TextRefinement", part: inlineFile("data.json", "application/json", jsonData)},
+		{
+			name: "AllowedURI",
+			part: protocol.FilePart{Type: protocol.PartTypeFile, File: protocol.FileContent{URI: &uri, MimeType: &jpegType}},
+		},
+		{
+			name:    "Disallowed",
+			part:    inlineFile("report.pdf", "application/pdf", pdfData),
+			wantErr: `message part 1 ("report.pdf"): file type "application/pdf" is not allowed`,
+		},
+		{
+			name:    "Mislabeled",
+			part:    inlineFile("invoice.png", "image/png", pdfData),
+			wantErr: `declared file type "image/png" does not match detected type "application/pdf"`,
+		},
+		{
+			name:    "MislabeledAsText",
+			part:    inlineFile("notes.txt", "text/plain", pngData),
+			wantErr: `declared file type "text/plain" does not match detected type "image/png"`,
+		},
+		{
+			name:    "UndeclaredURI",
+			part:    protocol.FilePart{Type: protocol.PartTypeFile, File: protocol.FileContent{URI: &uri}},
+			wantErr: "file sent by URI must declare its MIME type",
+		},
+		{
+			name:    "MalformedType",
+			part:    inlineFile("logo.png", "image/", pngData),
+			wantErr: `malformed MIME type "image/"`,
+		},
+	}
+
+	ts, _ := setupTestServer(t, newMockTaskManager(), WithAllowedFileTypes("image/*", "application/json"))
+	for _, method := range []string{protocol.MethodTasksSend, protocol.MethodTasksSendSubscribe} {
+		for _, tc := range tests {
+			t.Run(method+"/"+tc.name, func(t *testing.T) {
+				message := protocol.NewMessage(protocol.MessageRoleUser,
+					[]protocol.Part{protocol.NewTextPart("see attached"), tc.part})
+				params := protocol.SendTaskParams{ID: "file-task", Message: message}
+				req, _ := createJSONRPCRequest(t, method, params, "file-task")
+				resp := executeRequest(t, ts, req, ts.URL)
+				defer resp.Body.Close()
+				if tc.wantErr == "" {
+					if method == protocol.MethodTasksSend {
+						assert.Nil(t, decodeJSONRPCResponse(t, resp).Error)
+					} else {
+						assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+					}
+					return
+				}
+				jsonResp := decodeJSONRPCResponse(t, resp)
+				require.NotNil(t, jsonResp.Error)
+				assert.Equal(t, jsonrpc.CodeInvalidParams, jsonResp.Error.Code)
+				assert.Contains(t, jsonResp.Error.Data, tc.wantErr)
+			})
+		}
+	}
+
+	t.Run("SeedMessages", func(t *testing.T) {
+		for name, part := range map[string]protocol.FilePart{
+			"Disallowed": inlineFile("report.pdf", "application/pdf", pdfData),
+			"Mislabeled": inlineFile("invoice.png", "image/png", pdfData),
+		} {
+			params := protocol.SendTaskParams{
+				ID: "seeded-file-task",
+				Messages: []protocol.Message{
+					protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+					protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{part}),
+				},
+				Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+			}
+			req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, "seeded-file-task")
+			resp := executeRequest(t, ts, req, ts.URL)
+			jsonResp := decodeJSONRPCResponse(t, resp)
+			resp.Body.Close()
+			require.NotNil(t, jsonResp.Error, name)
+			assert.Equal(t, jsonrpc.CodeInvalidParams, jsonResp.Error.Code, name)
+			assert.Contains(t, jsonResp.Error.Data, "messages[1]: message part 0", name)
+		}
+	})
+
+	t.Run("CustomDetector", func(t *testing.T) {
+		detector := func(data []byte) string {
+			if bytes.HasPrefix(data, []byte("MZ")) {
+				return "application/vnd.microsoft.portable-executable"
+			}
+			return http.DetectContentType(data)
+		}
+		ts, _ := setupTestServer(t, newMockTaskManager(),
+			WithAllowedFileTypes("application/octet-stream"), WithFileTypeDetector(detector))
+		for data, allowed := range map[string]bool{"MZ\x90\x00": false, "\x00\x01\x02\x03": true} {
+			message := protocol.NewMessage(protocol.MessageRoleUser,
+				[]protocol.Part{inlineFile("blob.bin", "", []byte(data))})
+			params := protocol.SendTaskParams{ID: "file-task", Message: message}
+			req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, "file-task")
+			resp := executeRequest(t, ts, req, ts.URL)
+			jsonResp := decodeJSONRPCResponse(t, resp)
+			resp.Body.Close()
+			assert.Equal(t, allowed, jsonResp.Error == nil, "content %q", data)
+		}
+	})
+}
+
+func TestA2AServer_TasksGetETag(t *testing.T) {
+	tm := newMockTaskManager()
+	tm.tasks["etag-task"] = protocol.NewTask("etag-task", nil)
+	ts, _ := setupTestServer(t, tm)
+	get := func(ifNoneMatch string) *http.Response {
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksGet, protocol.TaskQueryParams{ID: "etag-task"}, "1")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp := executeRequest(t, ts, req, ts.URL)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := get("")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	require.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	assert.Nil(t, decodeJSONRPCResponse(t, resp).Error)
+
+	for _, header := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		resp := get(header)
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode, "If-None-Match: %s", header)
+		assert.Equal(t, etag, resp.Header.Get("ETag"))
+	}
+
+	resp = get(`"stale"`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, decodeJSONRPCResponse(t, resp).Error)
+}
+
+func TestA2AServer_SubscribeMultipleParams(t *testing.T) {
+	ts, _ := setupTestServer(t, newMockTaskManager())
+	tooMany := make([]string, maxSubscribedTasks+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("task-%d", i)
+	}
+	for name, ids := range map[string][]string{
+		"NoIDs":   nil,
+		"EmptyID": {"task-1", ""},
+		"TooMany": tooMany,
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, _ := createJSONRPCRequest(t, protocol.MethodTasksSubscribeMultiple,
+				protocol.TaskIDsParams{IDs: ids}, "1")
+			resp := executeRequest(t, ts, req, ts.URL)
+			defer resp.Body.Close()
+			rpcErr := decodeJSONRPCResponse(t, resp).Error
+			require.NotNil(t, rpcErr)
+			assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+		})
+	}
+
+	unique, rpcErr := uniqueTaskIDs([]string{"b", "a", "b"})
+	require.Nil(t, rpcErr)
+	assert.Equal(t, []string{"b", "a"}, unique)
+}
+
+// namedProcessor completes each task with a message naming the processor.
+type namedProcessor struct {
+	name string
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *namedProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	reply := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart(p.name)})
+	return handle.UpdateStatus(protocol.TaskStateCompleted, &reply)
+}
+
+func TestA2AServer_RegisterAgent(t *testing.T) {
+	newServer := func(t *testing.T) *A2AServer {
+		tm, err := taskmanager.NewMemoryTaskManager(&namedProcessor{name: "main"})
+		require.NoError(t, err)
+		s, err := NewA2AServer(defaultAgentCard(), tm)
+		require.NoError(t, err)
+		return s
+	}
+	cardFor := func(name string) AgentCard {
+		card := defaultAgentCard()
+		card.Name = name
+		card.URL = "http://localhost/" + name
+		return card
+	}
+	send := func(t *testing.T, ts *httptest.Server, path, taskID string) protocol.Task {
+		params := protocol.SendTaskParams{
+			ID:      taskID,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, taskID)
+		resp := executeRequest(t, ts, req, ts.URL+path)
+		defer resp.Body.Close()
+		rpcResp := decodeJSONRPCResponse(t, resp)
+		require.Nil(t, rpcResp.Error)
+		data, err := json.Marshal(rpcResp.Result)
+		require.NoError(t, err)
+		var task protocol.Task
+		require.NoError(t, json.Unmarshal(data, &task))
+		return task
+	}
+	replyText := func(t *testing.T, task protocol.Task) string {
+		require.NotNil(t, task.Status.Message)
+		require.Len(t, task.Status.Message.Parts, 1)
+		part, ok := task.Status.Message.Parts[0].(protocol.TextPart)
+		require.True(t, ok)
+		return part.Text
+	}
+	getCard := func(t *testing.T, ts *httptest.Server, path string) AgentCard {
+		resp, err := ts.Client().Get(ts.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var card AgentCard
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&card))
+		return card
+	}
+
+	t.Run("RoutesTasksByPath", func(t *testing.T) {
+		s := newServer(t)
+		require.NoError(t, s.RegisterAgent("/alpha", cardFor("alpha"), &namedProcessor{name: "alpha"}))
+		require.NoError(t, s.RegisterAgent("/beta/", cardFor("beta"), &namedProcessor{name: "beta"}))
+		ts := httptest.NewServer(s.Handler())
+		defer ts.Close()
+
+		assert.Equal(t, "alpha", replyText(t, send(t, ts, "/alpha", "task-a")))
+		assert.Equal(t, "beta", replyText(t, send(t, ts, "/beta", "task-b")))
+		assert.Equal(t, "beta", replyText(t, send(t, ts, "/beta/", "task-b2")))
+		assert.Equal(t, "main", replyText(t, send(t, ts, "/", "task-main")))
+
+		// Each agent keeps its own tasks: the same ID may be used by both.
+		assert.Equal(t, "alpha", replyText(t, send(t, ts, "/alpha", "shared")))
+		assert.Equal(t, "beta", replyText(t, send(t, ts, "/beta", "shared")))
+	})
+
+	t.Run("ServesCardPerPath", func(t *testing.T) {
+		s := newServer(t)
+		require.NoError(t, s.RegisterAgent("/alpha", cardFor("alpha"), &namedProcessor{name: "alpha"}))
+		require.NoError(t, s.RegisterAgent("/beta", cardFor("beta"), &namedProcessor{name: "beta"}))
+		ts := httptest.NewServer(s.Handler())
+		defer ts.Close()
+
+		assert.Equal(t, cardFor("alpha"), getCard(t, ts, "/alpha"+protocol.AgentCardPath))
+		assert.Equal(t, cardFor("beta"), getCard(t, ts, "/beta"+protocol.AgentCardPath))
+		assert.Equal(t, defaultAgentCard(), getCard(t, ts, protocol.AgentCardPath))
+	})
+
+	t.Run("InvalidPaths", func(t *testing.T) {
+		s := newServer(t)
+		require.NoError(t, s.RegisterAgent("/alpha", cardFor("alpha"), &namedProcessor{name: "alpha"}))
+		assert.ErrorContains(t, s.RegisterAgent("/alpha/", cardFor("other"), &namedProcessor{}), "already registered")
+		assert.ErrorContains(t, s.RegisterAgent("/", cardFor("other"), &namedProcessor{}), "conflicts")
+		assert.ErrorContains(t, s.RegisterAgent("beta", cardFor("other"), &namedProcessor{}), "must start with /")
+		assert.Error(t, s.RegisterAgent("/beta", cardFor("beta"), nil))
+		assert.Len(t, s.agents, 1)
+	})
+}
+
+// gatedProcessor completes each task once release is closed.
+type gatedProcessor struct {
+	release chan struct{}
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *gatedProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+func TestA2AServer_Pause(t *testing.T) {
+	send := func(t *testing.T, ts *httptest.Server, taskID string) <-chan jsonrpc.Response {
+		params := protocol.SendTaskParams{
+			ID:      taskID,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, taskID)
+		done := make(chan jsonrpc.Response, 1)
+		go func() {
+			resp := executeRequest(t, ts, req, ts.URL)
+			defer resp.Body.Close()
+			done <- decodeJSONRPCResponse(t, resp)
+		}()
+		return done
+	}
+	state := func(t *testing.T, rpcResp jsonrpc.Response) protocol.TaskState {
+		require.Nil(t, rpcResp.Error)
+		data, err := json.Marshal(rpcResp.Result)
+		require.NoError(t, err)
+		var task protocol.Task
+		require.NoError(t, json.Unmarshal(data, &task))
+		return task.Status.State
+	}
+
+	t.Run("TasksWaitForResume", func(t *testing.T) {
+		tm, err := taskmanager.NewMemoryTaskManager(&namedProcessor{name: "main"})
+		require.NoError(t, err)
+		ts, s := setupTestServer(t, tm)
+		s.Pause()
+		assert.True(t, s.Paused())
+
+		done := make([]<-chan jsonrpc.Response, 2)
+		for i := range done {
+			done[i] = send(t, ts, fmt.Sprintf("paused-%d", i))
+		}
+		require.Eventually(t, func() bool {
+			return s.debugInfo().PausedTasks == 2
+		}, time.Second, 5*time.Millisecond)
+		assert.True(t, s.debugInfo().Paused)
+		_, err = tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "paused-0"})
+		assert.Error(t, err, "a task sent while paused must not start")
+
+		s.Resume()
+		assert.False(t, s.Paused())
+		for _, ch := range done {
+			select {
+			case rpcResp := <-ch:
+				assert.Equal(t, protocol.TaskStateCompleted, state(t, rpcResp))
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for a task sent while paused")
+			}
+		}
+		assert.Zero(t, s.debugInfo().PausedTasks)
+	})
+
+	t.Run("RunningTasksContinue", func(t *testing.T) {
+		processor := &gatedProcessor{release: make(chan struct{})}
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		ts, s := setupTestServer(t, tm)
+		done := send(t, ts, "running")
+		require.Eventually(t, func() bool {
+			_, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "running"})
+			return err == nil
+		}, time.Second, 5*time.Millisecond)
+
+		s.Pause()
+		defer s.Resume()
+		close(processor.release)
+		select {
+		case rpcResp := <-done:
+			assert.Equal(t, protocol.TaskStateCompleted, state(t, rpcResp))
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the running task")
+		}
+	})
+
+	t.Run("DeadlineWhilePaused", func(t *testing.T) {
+		s, err := NewA2AServer(defaultAgentCard(), &mockTaskManager{})
+		require.NoError(t, err)
+		s.Pause()
+		defer s.Resume()
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		ran := false
+		err = s.runTask(ctx, protocol.SendTaskParams{ID: "late"}, func() { ran = true })
+		assert.ErrorIs(t, err, errServerPaused)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.False(t, ran)
+		assert.Zero(t, s.debugInfo().PausedTasks)
+		assert.Equal(t, ErrCodeServerBusy, errServerBusy(err).Code)
+	})
+}
+
+func TestA2AServer_MaxQueueDepth(t *testing.T) {
+	send := func(t *testing.T, ts *httptest.Server, taskID string) <-chan *http.Response {
+		params := protocol.SendTaskParams{
+			ID:      taskID,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, taskID)
+		done := make(chan *http.Response, 1)
+		go func() {
+			done <- executeRequest(t, ts, req, ts.URL)
+		}()
+		return done
+	}
+	wait := func(t *testing.T, done <-chan *http.Response) *http.Response {
+		select {
+		case resp := <-done:
+			t.Cleanup(func() { resp.Body.Close() })
+			return resp
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for a response")
+			return nil
+		}
+	}
+	assertRejected := func(t *testing.T, resp *http.Response) {
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+		rpcResp := decodeJSONRPCResponse(t, resp)
+		require.NotNil(t, rpcResp.Error)
+		assert.Equal(t, ErrCodeServerBusy, rpcResp.Error.Code)
+	}
+
+	t.Run("RejectsWhileFullDuringPause", func(t *testing.T) {
+		tm, err := taskmanager.NewMemoryTaskManager(&namedProcessor{name: "main"})
+		require.NoError(t, err)
+		ts, s := setupTestServer(t, tm, WithMaxQueueDepth(2))
+		s.Pause()
+		queued := []<-chan *http.Response{send(t, ts, "queued-0"), send(t, ts, "queued-1")}
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Depth == 2
+		}, time.Second, 5*time.Millisecond)
+
+		assertRejected(t, wait(t, send(t, ts, "rejected")))
+		assert.Equal(t, QueueStats{Depth: 2, MaxDepth: 2, Rejected: 1}, s.QueueStats())
+		assert.Equal(t, int64(1), s.debugInfo().Queue.Rejected)
+
+		s.Resume()
+		for _, done := range queued {
+			assert.Equal(t, http.StatusOK, wait(t, done).StatusCode)
+		}
+		assert.Zero(t, s.QueueStats().Depth)
+	})
+
+	t.Run("CountsTasksWaitingForWorkers", func(t *testing.T) {
+		processor := &gatedProcessor{release: make(chan struct{})}
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		ts, s := setupTestServer(t, tm, WithWorkerPool(1), WithMaxQueueDepth(1))
+		running := send(t, ts, "running")
+		require.Eventually(t, func() bool {
+			_, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "running"})
+			return err == nil
+		}, time.Second, 5*time.Millisecond)
+		waiting := send(t, ts, "waiting")
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Depth == 1
+		}, time.Second, 5*time.Millisecond)
+
+		assertRejected(t, wait(t, send(t, ts, "rejected")))
+		close(processor.release)
+		assert.Equal(t, http.StatusOK, wait(t, running).StatusCode)
+		assert.Equal(t, http.StatusOK, wait(t, waiting).StatusCode)
+		assert.Equal(t, int64(1), s.QueueStats().Rejected)
+	})
+
+	t.Run("UnlimitedByDefault", func(t *testing.T) {
+		s, err := NewA2AServer(defaultAgentCard(), &mockTaskManager{})
+		require.NoError(t, err)
+		assert.Equal(t, QueueStats{}, s.QueueStats())
+	})
+}
+
+// debugRecorder records the Debug messages logged through it.
+type debugRecorder struct {
+	log.Logger
+	mu     sync.Mutex
+	debugs []string
+}
+
+func (l *debugRecorder) Debugf(format string, args ...interface{}) {
+	l.mu.Lock()
+	l.debugs = append(l.debugs, fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+	l.Logger.Debugf(format, args...)
+}
+
+// matching returns the recorded messages containing substr.
+func (l *debugRecorder) matching(substr string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var matched []string
+	for _, debug := range l.debugs {
+		if strings.Contains(debug, substr) {
+			matched = append(matched, debug)
+		}
+	}
+	return matched
+}
+
+func TestA2AServer_BodyLogging(t *testing.T) {
+	send := func(t *testing.T, opts ...Option) *debugRecorder {
+		logger := &debugRecorder{Logger: log.Default}
+		log.Default = logger
+		t.Cleanup(func() { log.Default = logger.Logger })
+
+		tm, err := taskmanager.NewMemoryTaskManager(&namedProcessor{name: "main"})
+		require.NoError(t, err)
+		ts, _ := setupTestServer(t, tm, opts...)
+		part := protocol.NewTextPart("logged text")
+		part.Metadata = map[string]interface{}{"token": "s3cret-token"}
+		params := protocol.SendTaskParams{
+			ID:      "body-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{part}),
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, "body-req")
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return logger
+	}
+
+	t.Run("LoggedWhenEnabled", func(t *testing.T) {
+		logger := send(t, WithBodyLogging())
+		requests := logger.matching("JSON-RPC request body")
+		require.Len(t, requests, 1)
+		assert.Contains(t, requests[0], "logged text")
+		assert.Contains(t, requests[0], `"token":"[REDACTED]"`)
+		assert.NotContains(t, requests[0], "s3cret-token")
+
+		responses := logger.matching("JSON-RPC response body (ID: body-req)")
+		require.Len(t, responses, 1)
+		assert.Contains(t, responses[0], "body-task")
+		assert.NotContains(t, responses[0], "s3cret-token")
+	})
+
+	t.Run("AbsentByDefault", func(t *testing.T) {
+		logger := send(t)
+		assert.Empty(t, logger.matching("JSON-RPC request body"))
+		assert.Empty(t, logger.matching("JSON-RPC response body"))
+	})
+}
+
+func TestA2AServer_SubscribeEvents(t *testing.T) {
+	tm, err := taskmanager.NewMemoryTaskManager(&floodProcessor{chunks: 1, finished: make(chan struct{})})
+	require.NoError(t, err)
+	s, err := NewA2AServer(defaultAgentCard(), tm)
+	require.NoError(t, err)
+	require.NoError(t, s.RegisterAgent("/alpha", defaultAgentCard(), &namedProcessor{name: "alpha"}))
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	sub := s.SubscribeEvents(0, taskmanager.BusDropNewest)
+	defer sub.Close()
+
+	send := func(path, taskID string) {
+		params := protocol.SendTaskParams{
+			ID:      taskID,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, taskID)
+		resp := executeRequest(t, ts, req, ts.URL+path)
+		defer resp.Body.Close()
+		require.Nil(t, decodeJSONRPCResponse(t, resp).Error)
+	}
+	send("/", "main-task")
+	send("/alpha", "agent-task")
+
+	events := map[string][]taskmanager.BusEventType{}
+	for len(sub.Events()) > 0 {
+		event := <-sub.Events()
+		events[event.TaskID] = append(events[event.TaskID], event.Type)
+	}
+	assert.Equal(t, []taskmanager.BusEventType{
+		taskmanager.BusEventTaskCreated,
+		taskmanager.BusEventStatusChanged, // Working.
+		taskmanager.BusEventArtifact,
+		taskmanager.BusEventStatusChanged, // Completed.
+		taskmanager.BusEventTaskFinished,
+	}, events["main-task"])
+	assert.Equal(t, []taskmanager.BusEventType{
+		taskmanager.BusEventTaskCreated,
+		taskmanager.BusEventStatusChanged,
+		taskmanager.BusEventStatusChanged,
+		taskmanager.BusEventTaskFinished,
+	}, events["agent-task"], "agents publish to the server's bus")
+	assert.Zero(t, sub.Dropped())
+}
+
+// skillProcessor completes each task with a message naming the skill it was routed to.
+type skillProcessor struct{}
+
+// Process implements taskmanager.TaskProcessor.
+func (skillProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	reply := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart(protocol.SkillID(msg))})
+	return handle.UpdateStatus(protocol.TaskStateCompleted, &reply)
+}
+
+func TestA2AServer_WithSkillRouter(t *testing.T) {
+	card := defaultAgentCard()
+	card.Skills = []AgentSkill{{ID: "chat", Name: "Chat"}, {ID: "vision", Name: "Vision"}}
+	router := func(msg protocol.Message) (string, error) {
+		for _, part := range msg.Parts {
+			switch part := part.(type) {
+			case protocol.FilePart:
+				if part.File.MimeType != nil && strings.HasPrefix(*part.File.MimeType, "image/") {
+					return "vision", nil
+				}
+				return "", errors.New("unsupported file type")
+			case protocol.DataPart:
+				return "translate", nil
+			}
+		}
+		return "chat", nil
+	}
+	tm, err := taskmanager.NewMemoryTaskManager(skillProcessor{})
+	require.NoError(t, err)
+	s, err := NewA2AServer(card, tm, WithSkillRouter(router))
+	require.NoError(t, err)
+	ts := httptest.NewServer(http.HandlerFunc(s.handleJSONRPC))
+	defer ts.Close()
+
+	send := func(t *testing.T, taskID string, message protocol.Message) jsonrpc.Response {
+		params := protocol.SendTaskParams{ID: taskID, Message: message}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, taskID)
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		return decodeJSONRPCResponse(t, resp)
+	}
+	skillOf := func(t *testing.T, rpcResp jsonrpc.Response) string {
+		require.Nil(t, rpcResp.Error)
+		data, err := json.Marshal(rpcResp.Result)
+		require.NoError(t, err)
+		var task protocol.Task
+		require.NoError(t, json.Unmarshal(data, &task))
+		require.NotNil(t, task.Status.Message)
+		return task.Status.Message.Parts[0].(protocol.TextPart).Text
+	}
+	image := func(mimeType string) protocol.Part {
+		data := base64.StdEncoding.EncodeToString([]byte("img"))
+		return protocol.FilePart{
+			Type: protocol.PartTypeFile,
+			File: protocol.FileContent{MimeType: &mimeType, Bytes: &data},
+		}
+	}
+
+	t.Run("RoutesByContent", func(t *testing.T) {
+		text := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hello")})
+		assert.Equal(t, "chat", skillOf(t, send(t, "text-task", text)))
+		picture := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{
+			protocol.NewTextPart("what is this?"), image("image/png"),
+		})
+		assert.Equal(t, "vision", skillOf(t, send(t, "image-task", picture)))
+	})
+
+	t.Run("ExplicitSkillKept", func(t *testing.T) {
+		msg := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{image("image/png")})
+		msg.Metadata = map[string]interface{}{protocol.MetadataKeySkill: "chat"}
+		assert.Equal(t, "chat", skillOf(t, send(t, "explicit-task", msg)))
+	})
+
+	t.Run("RouterError", func(t *testing.T) {
+		msg := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{image("video/mp4")})
+		rpcResp := send(t, "video-task", msg)
+		require.NotNil(t, rpcResp.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcResp.Error.Code)
+		assert.Contains(t, rpcResp.Error.Message+fmt.Sprint(rpcResp.Error.Data), "unsupported file type")
+	})
+
+	t.Run("UnknownSkill", func(t *testing.T) {
+		msg := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.DataPart{
+			Type: protocol.PartTypeData,
+			Data: map[string]interface{}{"a": 1},
+		}})
+		rpcResp := send(t, "data-task", msg)
+		require.NotNil(t, rpcResp.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcResp.Error.Code)
+		assert.Contains(t, rpcResp.Error.Message+fmt.Sprint(rpcResp.Error.Data), `unknown skill "translate"`)
+		_, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "data-task"})
+		assert.Error(t, err, "a rejected message should not create a task")
+	})
+}
+
+func TestA2AServer_WithTimeFormat(t *testing.T) {
+	const recorded = "2025-03-04T05:06:07.123456789Z"
+	task := &protocol.Task{
+		ID: "time-task",
+		Status: protocol.TaskStatus{
+			State:               protocol.TaskStateWorking,
+			Timestamp:           recorded,
+			EstimatedCompletion: "2025-03-04T06:00:00Z",
+		},
+		Metadata: map[string]interface{}{"timestamp": recorded},
+		Events:   []protocol.TaskLifecycleEvent{{Type: protocol.TaskLifecycleReceived, Timestamp: recorded}},
+	}
+
+	getTask := func(t *testing.T, opts ...Option) string {
+		tm := newMockTaskManager()
+		tm.GetResponse = task
+		testServer, _ := setupTestServer(t, tm, opts...)
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksGet, protocol.TaskQueryParams{ID: task.ID}, "time-1")
+		resp := executeRequest(t, testServer, req, testServer.URL)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		body := getTask(t)
+		assert.Contains(t, body, `"timestamp":"`+recorded+`"`)
+		assert.Contains(t, body, `"estimatedCompletion":"2025-03-04T06:00:00Z"`)
+	})
+
+	for name, tc := range map[string]struct {
+		format    TimeFormat
+		timestamp string
+		eta       string
+	}{
+		"Seconds":     {TimeFormatSeconds, "2025-03-04T05:06:07Z", "2025-03-04T06:00:00Z"},
+		"Millis":      {TimeFormatMillis, "2025-03-04T05:06:07.123Z", "2025-03-04T06:00:00.000Z"},
+		"EpochMillis": {TimeFormatEpochMillis, "1741064767123", "1741068000000"},
+		"Layout":      {TimeLayout(time.DateTime), "2025-03-04 05:06:07", "2025-03-04 06:00:00"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := getTask(t, WithTimeFormat(tc.format))
+			var response struct {
+				Result struct {
+					Status   protocol.TaskStatus           `json:"status"`
+					Metadata map[string]interface{}        `json:"metadata"`
+					Events   []protocol.TaskLifecycleEvent `json:"events"`
+				} `json:"result"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(body), &response))
+			assert.Equal(t, tc.timestamp, response.Result.Status.Timestamp)
+			assert.Equal(t, tc.eta, response.Result.Status.EstimatedCompletion)
+			require.Len(t, response.Result.Events, 1)
+			assert.Equal(t, tc.timestamp, response.Result.Events[0].Timestamp)
+			// Application data is left as it is.
+			assert.Equal(t, recorded, response.Result.Metadata["timestamp"])
+		})
+	}
+
+	t.Run("StreamEvents", func(t *testing.T) {
+		tm := newMockTaskManager()
+		tm.SubscribeEvents = []protocol.TaskEvent{protocol.TaskStatusUpdateEvent{
+			ID:     "time-task",
+			Status: protocol.TaskStatus{State: protocol.TaskStateCompleted, Timestamp: recorded},
+			Final:  true,
+		}}
+		testServer, _ := setupTestServer(t, tm, WithTimeFormat(TimeFormatEpochMillis))
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSendSubscribe, protocol.SendTaskParams{
+			ID:      "time-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+		}, "time-2")
+		req.Header.Set("Accept", "text/event-stream")
+		resp := executeRequest(t, testServer, req, testServer.URL)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"timestamp":"1741064767123"`)
+		assert.NotContains(t, string(body), recorded)
+	})
+}
+
+func TestA2AServer_WithAdmissionWebhook(t *testing.T) {
+	var reviews []AdmissionRequest
+	var mu sync.Mutex
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review AdmissionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+		mu.Lock()
+		reviews = append(reviews, review)
+		mu.Unlock()
+		var decision AdmissionResponse
+		switch review.Params.Message.Parts[0].(protocol.TextPart).Text {
+		case "approve":
+			decision.Allowed = true
+		case "deny":
+			decision.Reason = "tenant over quota"
+		case "mutate":
+			decision.Allowed = true
+			params := review.Params
+			params.Labels = map[string]string{"reviewed": "true"}
+			decision.Params = &params
+			decision.Metadata = map[string]interface{}{"tenant": "acme"}
+		case "rename":
+			decision.Allowed = true
+			params := review.Params
+			params.ID = "other-task"
+			decision.Params = &params
+		case "inject":
+			decision.Allowed = true
+			params := review.Params
+			page := base64.StdEncoding.EncodeToString([]byte("<html><script>alert(1)</script></html>"))
+			html := "text/html"
+			params.Message.Parts = append(params.Message.Parts, protocol.FilePart{
+				Type: protocol.PartTypeFile,
+				File: protocol.FileContent{MimeType: &html, Bytes: &page},
+			})
+			decision.Params = &params
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+			decision.Allowed = true
+		default:
+			http.Error(w, "broken", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(decision)
+	}))
+	defer webhook.Close()
+
+	newServer := func(t *testing.T, opts ...Option) *httptest.Server {
+		tm, err := taskmanager.NewMemoryTaskManager(skillProcessor{})
+		require.NoError(t, err)
+		opts = append([]Option{WithAdmissionWebhook(webhook.URL, 100*time.Millisecond)}, opts...)
+		ts, _ := setupTestServer(t, tm, opts...)
+		return ts
+	}
+	send := func(t *testing.T, ts *httptest.Server, taskID, text string) (*http.Response, jsonrpc.Response) {
+		params := protocol.SendTaskParams{
+			ID:       taskID,
+			Message:  protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)}),
+			Metadata: map[string]interface{}{"source": "test"},
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSend, params, taskID)
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		return resp, decodeJSONRPCResponse(t, resp)
+	}
+	taskOf := func(t *testing.T, rpcResp jsonrpc.Response) protocol.Task {
+		require.Nil(t, rpcResp.Error)
+		data, err := json.Marshal(rpcResp.Result)
+		require.NoError(t, err)
+		var task protocol.Task
+		require.NoError(t, json.Unmarshal(data, &task))
+		return task
+	}
+	ts := newServer(t)
+
+	t.Run("Approves", func(t *testing.T) {
+		_, rpcResp := send(t, ts, "approved-task", "approve")
+		task := taskOf(t, rpcResp)
+		assert.Equal(t, "approved-task", task.ID)
+		mu.Lock()
+		review := reviews[len(reviews)-1]
+		mu.Unlock()
+		assert.Equal(t, protocol.MethodTasksSend, review.Method)
+		assert.Equal(t, "approved-task", review.Params.ID)
+	})
+
+	t.Run("Denies", func(t *testing.T) {
+		resp, rpcResp := send(t, ts, "denied-task", "deny")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.NotNil(t, rpcResp.Error)
+		assert.Equal(t, ErrCodeAdmissionDenied, rpcResp.Error.Code)
+		assert.Equal(t, "tenant over quota", rpcResp.Error.Data)
+	})
+
+	t.Run("Mutates", func(t *testing.T) {
+		_, rpcResp := send(t, ts, "mutated-task", "mutate")
+		task := taskOf(t, rpcResp)
+		assert.Equal(t, "acme", task.Metadata["tenant"])
+		assert.Equal(t, "test", task.Metadata["source"])
+		assert.Equal(t, map[string]string{"reviewed": "true"}, task.Labels)
+	})
+
+	t.Run("CannotRenameTask", func(t *testing.T) {
+		_, rpcResp := send(t, ts, "renamed-task", "rename")
+		require.NotNil(t, rpcResp.Error)
+		assert.Equal(t, ErrCodeAdmissionDenied, rpcResp.Error.Code)
+	})
+
+	t.Run("AdmittedParamsChecked", func(t *testing.T) {
+		restricted := newServer(t, WithAllowedFileTypes("image/png"))
+		_, rpcResp := send(t, restricted, "injected-task", "inject")
+		require.NotNil(t, rpcResp.Error)
+		assert.Equal(t, ErrCodeAdmissionDenied, rpcResp.Error.Code)
+		_, rpcResp = send(t, restricted, "clean-task", "approve")
+		assert.Equal(t, "clean-task", taskOf(t, rpcResp).ID)
+	})
+
+	t.Run("FailsClosed", func(t *testing.T) {
+		for _, text := range []string{"crash", "slow"} {
+			_, rpcResp := send(t, ts, "closed-"+text, text)
+			require.NotNil(t, rpcResp.Error, text)
+			assert.Equal(t, ErrCodeAdmissionDenied, rpcResp.Error.Code, text)
+		}
+	})
+
+	t.Run("FailsOpen", func(t *testing.T) {
+		open := newServer(t, WithAdmissionFailOpen())
+		_, rpcResp := send(t, open, "open-task", "crash")
+		assert.Equal(t, "open-task", taskOf(t, rpcResp).ID)
+	})
+
+	t.Run("Streaming", func(t *testing.T) {
+		params := protocol.SendTaskParams{
+			ID:      "streamed-denied-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("deny")}),
+		}
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSendSubscribe, params, "stream-1")
+		req.Header.Set("Accept", "text/event-stream")
+		resp := executeRequest(t, ts, req, ts.URL)
+		defer resp.Body.Close()
+		rpcResp := decodeJSONRPCResponse(t, resp)
+		require.NotNil(t, rpcResp.Error)
+		assert.Equal(t, ErrCodeAdmissionDenied, rpcResp.Error.Code)
+	})
+}
+
+func TestA2AServer_ArtifactContent(t *testing.T) {
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	encoded := base64.StdEncoding.EncodeToString(content)
+	mimeType := "text/plain"
+	tm := newMockTaskManager()
+	tm.tasks["file-task"] = &protocol.Task{
+		ID:     "file-task",
+		Status: protocol.TaskStatus{State: protocol.TaskStateCompleted},
+		Artifacts: []protocol.Artifact{{
+			Index: 0,
+			Parts: []protocol.Part{protocol.FilePart{
+				Type: protocol.PartTypeFile,
+				File: protocol.FileContent{MimeType: &mimeType, Bytes: &encoded},
+			}},
+		}},
+	}
+	a2aServer, err := NewA2AServer(defaultAgentCard(), tm, WithArtifactContentEndpoint("/artifacts"))
+	require.NoError(t, err)
+	testServer := httptest.NewServer(a2aServer.Handler())
+	t.Cleanup(testServer.Close)
+	contentURL := testServer.URL + ArtifactContentPath("/artifacts", "file-task", 0)
+
+	get := func(t *testing.T, url string, header map[string]string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	t.Run("Full", func(t *testing.T) {
+		resp, body := get(t, contentURL, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "bytes", resp.Header.Get("Accept-Ranges"))
+		assert.Equal(t, mimeType, resp.Header.Get("Content-Type"))
+		assert.Equal(t, `"`+protocol.ComputeChecksum(content)+`"`, resp.Header.Get("ETag"))
+		assert.Equal(t, content, body)
+	})
+
+	t.Run("TwoRanges", func(t *testing.T) {
+		first, head := get(t, contentURL, map[string]string{"Range": "bytes=0-9"})
+		require.Equal(t, http.StatusPartialContent, first.StatusCode)
+		assert.Equal(t, "bytes", first.Header.Get("Accept-Ranges"))
+		assert.Equal(t, fmt.Sprintf("bytes 0-9/%d", len(content)), first.Header.Get("Content-Range"))
+		second, tail := get(t, contentURL, map[string]string{
+			"Range":    "bytes=10-",
+			"If-Range": first.Header.Get("ETag"),
+		})
+		require.Equal(t, http.StatusPartialContent, second.StatusCode)
+		assert.Equal(t, content, append(head, tail...))
+	})
+
+	t.Run("SandboxedHTML", func(t *testing.T) {
+		page := base64.StdEncoding.EncodeToString([]byte("<script>alert(document.cookie)</script>"))
+		html := "text/html"
+		name := "report.html"
+		tm.tasks["html-task"] = &protocol.Task{
+			ID:     "html-task",
+			Status: protocol.TaskStatus{State: protocol.TaskStateCompleted},
+			Artifacts: []protocol.Artifact{
+				{Index: 0, Name: &name, Parts: []protocol.Part{protocol.FilePart{
+					Type: protocol.PartTypeFile,
+					File: protocol.FileContent{MimeType: &html, Bytes: &page},
+				}}},
+				{Index: 1, Metadata: map[string]interface{}{protocol.MetadataKeyInlineContent: true},
+					Parts: []protocol.Part{protocol.FilePart{
+						Type: protocol.PartTypeFile,
+						File: protocol.FileContent{MimeType: &html, Bytes: &page},
+					}}},
+			},
+		}
+		resp, _ := get(t, testServer.URL+ArtifactContentPath("/artifacts", "html-task", 0), nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+		assert.Equal(t, "default-src 'none'; sandbox", resp.Header.Get("Content-Security-Policy"))
+		assert.Equal(t, `attachment; filename=report.html`, resp.Header.Get("Content-Disposition"))
+
+		resp, _ = get(t, testServer.URL+ArtifactContentPath("/artifacts", "html-task", 1), nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "inline", resp.Header.Get("Content-Disposition"))
+		assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+		assert.Equal(t, "default-src 'none'; sandbox", resp.Header.Get("Content-Security-Policy"))
+	})
+
+	t.Run("StaleIfRange", func(t *testing.T) {
+		resp, body := get(t, contentURL, map[string]string{"Range": "bytes=10-", "If-Range": `"stale"`})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, content, body)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		for _, path := range []string{
+			ArtifactContentPath("/artifacts", "missing-task", 0),
+			ArtifactContentPath("/artifacts", "file-task", 1),
+		} {
+			resp, _ := get(t, testServer.URL+path, nil)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+		}
+		resp, _ := get(t, testServer.URL+"/artifacts/file-task/first", nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestA2AServer_WithStrictCardURL(t *testing.T) {
+	newServer := func(t *testing.T, cardURL string, opts ...Option) (*A2AServer, error) {
+		card := defaultAgentCard()
+		card.URL = cardURL
+		return NewA2AServer(card, newMockTaskManager(), append([]Option{WithStrictCardURL()}, opts...)...)
+	}
+	listenAddr := func(host string, port int) net.Addr {
+		return &net.TCPAddr{IP: net.ParseIP(host), Port: port}
+	}
+
+	t.Run("ListenAddr", func(t *testing.T) {
+		for _, tc := range []struct {
+			name    string
+			cardURL string
+			addr    net.Addr
+			opts    []Option
+			ok      bool
+		}{
+			{"AnyInterface", "http://agent.example.com:8080/", listenAddr("::", 8080), nil, true},
+			{"Loopback", "http://localhost:8080", listenAddr("127.0.0.1", 8080), nil, true},
+			{"SameIP", "http://10.0.0.5:8080/", listenAddr("10.0.0.5", 8080), nil, true},
+			{"DefaultPort", "http://agent.example.com/", listenAddr("0.0.0.0", 80), nil, true},
+			{"EndpointPath", "http://localhost:8080/rpc", listenAddr("127.0.0.1", 8080),
+				[]Option{WithJSONRPCEndpoint("/rpc")}, true},
+			{"WrongPort", "http://localhost:9090/", listenAddr("127.0.0.1", 8080), nil, false},
+			{"WrongScheme", "https://localhost:8080/", listenAddr("127.0.0.1", 8080), nil, false},
+			{"WrongIP", "http://10.0.0.6:8080/", listenAddr("10.0.0.5", 8080), nil, false},
+			{"LoopbackOnly", "http://agent.example.com:8080/", listenAddr("127.0.0.1", 8080), nil, false},
+			{"WrongPath", "http://localhost:8080/other", listenAddr("127.0.0.1", 8080), nil, false},
+			{"NotAbsolute", "/test-agent", listenAddr("127.0.0.1", 8080), nil, false},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				a2aServer, err := newServer(t, tc.cardURL, tc.opts...)
+				require.NoError(t, err)
+				err = a2aServer.checkCardURL(tc.addr)
+				if tc.ok {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorContains(t, err, "agent card URL mismatch")
+				}
+			})
+		}
+	})
+
+	t.Run("StartRefusesMismatch", func(t *testing.T) {
+		a2aServer, err := newServer(t, "http://localhost:1/")
+		require.NoError(t, err)
+		err = a2aServer.Start("127.0.0.1:0")
+		assert.ErrorContains(t, err, "does not have the port the server listens on")
+	})
+
+	t.Run("NotStrictOnlyWarns", func(t *testing.T) {
+		card := defaultAgentCard()
+		card.URL = "http://localhost:1/"
+		a2aServer, err := NewA2AServer(card, newMockTaskManager())
+		require.NoError(t, err)
+		assert.NoError(t, a2aServer.checkCardURL(listenAddr("127.0.0.1", 8080)))
+	})
+
+	t.Run("ExternalURL", func(t *testing.T) {
+		_, err := newServer(t, "https://agents.example.com/travel/",
+			WithExternalURL("https://agents.example.com/travel"))
+		assert.NoError(t, err)
+		_, err = newServer(t, "https://agents.example.com:443/travel/rpc",
+			WithExternalURL("https://agents.example.com/travel/"), WithJSONRPCEndpoint("/rpc"))
+		assert.NoError(t, err)
+
+		_, err = newServer(t, "http://localhost:8080/", WithExternalURL("https://agents.example.com/travel"))
+		assert.ErrorContains(t, err, "does not have the origin of the external URL")
+		_, err = newServer(t, "https://agents.example.com/", WithExternalURL("https://agents.example.com/travel"))
+		assert.ErrorContains(t, err, "does not have the path of the JSON-RPC endpoint /travel")
+		_, err = newServer(t, "https://agents.example.com/", WithExternalURL("agents.example.com"))
+		assert.ErrorContains(t, err, "invalid external URL")
+	})
+}
+
+func TestA2AServer_WithMaxBatchSize(t *testing.T) {
+	batch := func(n int) protocol.SendTaskBatchParams {
+		params := protocol.SendTaskBatchParams{Tasks: make([]protocol.SendTaskParams, n)}
+		for i := range params.Tasks {
+			params.Tasks[i] = protocol.SendTaskParams{
+				ID: fmt.Sprintf("batch-limit-%d", i),
+				Message: protocol.Message{
+					Role:  protocol.MessageRoleUser,
+					Parts: []protocol.Part{protocol.NewTextPart("hello")},
+				},
+			}
+		}
+		return params
+	}
+	sendBatch := func(t *testing.T, params protocol.SendTaskBatchParams, opts ...Option) jsonrpc.Response {
+		testServer, _ := setupTestServer(t, newMockTaskManager(), opts...)
+		req, _ := createJSONRPCRequest(t, protocol.MethodTasksSendBatch, params, "batch-limit")
+		resp := executeRequest(t, testServer, req, testServer.URL)
+		defer resp.Body.Close()
+		return decodeJSONRPCResponse(t, resp)
+	}
+
+	t.Run("AtLimit", func(t *testing.T) {
+		response := sendBatch(t, batch(3), WithMaxBatchSize(3))
+		require.Nil(t, response.Error)
+		results, ok := response.Result.([]interface{})
+		require.True(t, ok, "result is %T", response.Result)
+		assert.Len(t, results, 3)
+	})
+
+	t.Run("OverLimit", func(t *testing.T) {
+		response := sendBatch(t, batch(4), WithMaxBatchSize(3))
+		require.NotNil(t, response.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidRequest, response.Error.Code)
+		assert.Contains(t, fmt.Sprint(response.Error.Data), "at most 3 tasks, got 4")
+	})
+
+	t.Run("DefaultLimit", func(t *testing.T) {
+		response := sendBatch(t, batch(protocol.MaxSendBatchSize+1), WithMaxBatchSize(protocol.MaxSendBatchSize+10))
+		require.NotNil(t, response.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidRequest, response.Error.Code)
+	})
+
+	t.Run("Bytes", func(t *testing.T) {
+		params := batch(2)
+		encoded, err := json.Marshal(params)
+		require.NoError(t, err)
+		response := sendBatch(t, params, WithMaxBatchBytes(int64(len(encoded))))
+		require.Nil(t, response.Error)
+
+		response = sendBatch(t, params, WithMaxBatchBytes(int64(len(encoded)-1)))
+		require.NotNil(t, response.Error)
+		assert.Equal(t, jsonrpc.CodeInvalidRequest, response.Error.Code)
+		assert.Contains(t, fmt.Sprint(response.Error.Data), fmt.Sprintf("at most %d bytes", len(encoded)-1))
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
 	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
 	"trpc.group/trpc-go/trpc-a2a-go/internal/sse"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
@@ -112,6 +113,8 @@ func TestA2AServer_HandleAgentCard(t *testing.T) {
 	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"), "Content-Type should be application/json")
 	// Check CORS header (enabled by default)
 	assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, resp.Header.Get("Access-Control-Allow-Headers"), protocol.HeaderBaggage)
+	assert.Contains(t, resp.Header.Get("Access-Control-Expose-Headers"), "Retry-After")
 
 	// Decode and compare body
 	var receivedCard AgentCard
@@ -120,6 +123,220 @@ func TestA2AServer_HandleAgentCard(t *testing.T) {
 	assert.Equal(t, agentCard, receivedCard, "Received agent card should match original")
 }
 
+func TestAgentCard_Extensions(t *testing.T) {
+	type billing struct {
+		Currency string  `json:"currency"`
+		PerCall  float64 `json:"perCall"`
+	}
+	const billingURI = "https://example.com/a2a/ext/billing"
+
+	t.Run("SetAndGet", func(t *testing.T) {
+		card := defaultAgentCard()
+		require.NoError(t, card.SetExtension(billingURI, billing{Currency: "EUR", PerCall: 0.25}))
+		require.NoError(t, card.SetExtension("urn:example:tier", "gold"))
+
+		var got billing
+		found, err := card.Extension(billingURI, &got)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, billing{Currency: "EUR", PerCall: 0.25}, got)
+
+		var tier string
+		found, err = card.Extension("urn:example:tier", &tier)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "gold", tier)
+
+		found, err = card.Extension("https://example.com/a2a/ext/missing", &tier)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		found, err = card.Extension(billingURI, &tier)
+		assert.True(t, found)
+		assert.Error(t, err, "a billing object cannot be read as a string")
+	})
+
+	t.Run("InvalidKeys", func(t *testing.T) {
+		card := defaultAgentCard()
+		for _, key := range []string{"billing", "/ext/billing", "https:", "example.com/ext", "%zz"} {
+			assert.Error(t, card.SetExtension(key, true), "key %q", key)
+		}
+		assert.Empty(t, card.Extensions)
+
+		card.Extensions = map[string]json.RawMessage{"billing": json.RawMessage(`{}`)}
+		_, err := NewA2AServer(card, newMockTaskManager())
+		assert.ErrorContains(t, err, `extension key "billing"`)
+	})
+
+	t.Run("Served", func(t *testing.T) {
+		card := defaultAgentCard()
+		require.NoError(t, card.SetExtension(billingURI, billing{Currency: "USD"}))
+		a2aServer, err := NewA2AServer(card, newMockTaskManager())
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		a2aServer.handleAgentCard(rec, httptest.NewRequest(http.MethodGet, protocol.AgentCardPath, nil))
+		assert.Contains(t, rec.Body.String(), `"extensions":{"`+billingURI+`":{"currency":"USD","perCall":0}}`)
+	})
+}
+
+func TestExtensionRegistry(t *testing.T) {
+	type retryPolicy struct {
+		MaxAttempts int   `json:"maxAttempts"`
+		BackoffMS   int64 `json:"backoffMs"`
+	}
+	type limits struct {
+		MaxTokens int           `json:"maxTokens"`
+		Retry     retryPolicy   `json:"retryPolicy"`
+		Windows   []retryPolicy `json:"windows"`
+	}
+	const limitsURI = "https://example.com/a2a/ext/limits"
+	card := defaultAgentCard()
+	// A snake_case agent, as another implementation might serve it.
+	card.Extensions = map[string]json.RawMessage{limitsURI: json.RawMessage(`{
+		"max_tokens": 512,
+		"retry_policy": {"max_attempts": 3, "backoff_ms": 9007199254740993},
+		"windows": [{"max_attempts": 1}]
+	}`)}
+	want := limits{
+		MaxTokens: 512,
+		Retry:     retryPolicy{MaxAttempts: 3, BackoffMS: 9007199254740993},
+		Windows:   []retryPolicy{{MaxAttempts: 1}},
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		var got limits
+		found, err := NewExtensionRegistry().Decode(&card, limitsURI, &got)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Zero(t, got.MaxTokens, "snake_case keys do not match the camelCase tags")
+		assert.Zero(t, got.Retry)
+	})
+
+	t.Run("SnakeToCamel", func(t *testing.T) {
+		registry := NewExtensionRegistry()
+		require.NoError(t, registry.Register(limitsURI, RenamingDecoder(SnakeToCamel)))
+		var got limits
+		found, err := registry.Decode(&card, limitsURI, &got)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("FieldMap", func(t *testing.T) {
+		const quotaURI = "urn:example:quota"
+		card := defaultAgentCard()
+		card.Extensions = map[string]json.RawMessage{
+			quotaURI: json.RawMessage(`{"tok_limit": 64, "maxTokens": 128, "retries": {"n": 2}}`),
+		}
+		registry := NewExtensionRegistry()
+		require.NoError(t, registry.Register(quotaURI, RenamingDecoder(FieldMap(map[string]string{
+			"tok_limit": "windowTokens",
+			"retries":   "retryPolicy",
+			"n":         "maxAttempts",
+		}))))
+		var got struct {
+			limits
+			WindowTokens int `json:"windowTokens"`
+		}
+		found, err := registry.Decode(&card, quotaURI, &got)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, 64, got.WindowTokens)
+		assert.Equal(t, 128, got.MaxTokens)
+		assert.Equal(t, 2, got.Retry.MaxAttempts)
+	})
+
+	t.Run("ExactNameWins", func(t *testing.T) {
+		var got limits
+		err := RenamingDecoder(SnakeToCamel)([]byte(`{"max_tokens": 1, "maxTokens": 2}`), &got)
+		require.NoError(t, err)
+		assert.Equal(t, 2, got.MaxTokens)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		registry := NewExtensionRegistry()
+		require.NoError(t, registry.Register("urn:example:missing", RenamingDecoder(SnakeToCamel)))
+		var got limits
+		found, err := registry.Decode(&card, "urn:example:missing", &got)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		registry := NewExtensionRegistry()
+		assert.Error(t, registry.Register("limits", RenamingDecoder(SnakeToCamel)))
+		assert.Error(t, registry.Register(limitsURI, nil))
+		require.NoError(t, registry.Register(limitsURI, RenamingDecoder(SnakeToCamel)))
+		var tier string
+		found, err := registry.Decode(&card, limitsURI, &tier)
+		assert.True(t, found)
+		assert.ErrorContains(t, err, limitsURI)
+	})
+
+	for name, want := range map[string]string{
+		"max_tokens":  "maxTokens",
+		"a_b_c":       "aBC",
+		"plain":       "plain",
+		"_private":    "private",
+		"trailing_":   "trailing",
+		"double__gap": "doubleGap",
+	} {
+		assert.Equal(t, want, SnakeToCamel(name), name)
+	}
+}
+
+func TestA2AServer_DebugEndpoint(t *testing.T) {
+	agentCard := defaultAgentCard()
+	agentCard.Skills = []AgentSkill{
+		{ID: "summarize", Name: "Summarize"},
+		{ID: "translate", Name: "Translate"},
+	}
+	provider := auth.NewAPIKeyAuthProvider(map[string]string{"debug-key": "operator"}, "")
+	a2aServer, err := NewA2AServer(agentCard, newMockTaskManager(),
+		WithAuthProvider(provider), WithDebugEndpoint("/debug/a2a"))
+	require.NoError(t, err)
+	testServer := httptest.NewServer(a2aServer.Handler())
+	defer testServer.Close()
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		resp, err := testServer.Client().Get(testServer.URL + "/debug/a2a")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Authenticated", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, testServer.URL+"/debug/a2a", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-API-Key", "debug-key")
+		resp, err := testServer.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var info DebugInfo
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		assert.Equal(t, agentCard.Name, info.Name)
+		assert.Equal(t, agentCard.Skills, info.Skills)
+		assert.Contains(t, info.Methods, protocol.MethodTasksSend)
+		assert.Contains(t, info.Methods, protocol.MethodTasksResubscribe)
+		assert.Equal(t, a2aServer.capabilities().Methods, info.Methods, "the methods a2a/capabilities lists")
+		assert.Equal(t, protocol.DefaultJSONRPCPath, info.Config.JSONRPCEndpoint)
+		assert.True(t, info.Config.AuthEnabled)
+	})
+
+	t.Run("DisabledWithoutAuth", func(t *testing.T) {
+		a2aServer, err := NewA2AServer(agentCard, newMockTaskManager(), WithDebugEndpoint("/debug/a2a"))
+		require.NoError(t, err)
+		testServer := httptest.NewServer(a2aServer.Handler())
+		defer testServer.Close()
+		resp, err := testServer.Client().Get(testServer.URL + "/debug/a2a")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
 func TestA2AServer_HandleJSONRPC_Methods(t *testing.T) {
 	mockTM := newMockTaskManager()
 	agentCard := defaultAgentCard()
@@ -165,7 +382,7 @@ func TestA2AServer_HandleJSONRPC_Methods(t *testing.T) {
 		assert.Nil(t, resp.Result, "Response result should be nil")
 		require.NotNil(t, resp.Error, "Response error should not be nil")
 		assert.Equal(t, jsonrpc.CodeInternalError, resp.Error.Code)
-		assert.Contains(t, resp.Error.Data, "mock send task failed")
+		assert.Nil(t, resp.Error.Data, "internal error details are omitted by default")
 	})
 
 	// --- Test tasks/get ---
@@ -241,6 +458,33 @@ func TestA2AServer_HandleJSONRPC_Methods(t *testing.T) {
 		assert.Equal(t, taskmanager.ErrCodeTaskNotFound, resp.Error.Code)
 	})
 
+	t.Run("tasks/cancel unknown reason", func(t *testing.T) {
+		mockTM.CancelError = nil
+
+		params := protocol.TaskIDParams{ID: taskID, Reason: protocol.CancelReason("bored")}
+		resp := performJSONRPCRequest(t, testServer, "tasks/cancel", params, "req-cancel-reason")
+
+		assert.Nil(t, resp.Result, "Response result should be nil")
+		require.NotNil(t, resp.Error, "Response error should not be nil")
+		assert.Equal(t, jsonrpc.CodeInvalidParams, resp.Error.Code)
+	})
+
+	t.Run("tasks/cancel server-only reason", func(t *testing.T) {
+		mockTM.CancelError = nil
+
+		for _, reason := range []protocol.CancelReason{
+			protocol.CancelReasonTimeout, protocol.CancelReasonServerShutdown,
+			protocol.CancelReasonClientDisconnected,
+		} {
+			params := protocol.TaskIDParams{ID: taskID, Reason: reason}
+			resp := performJSONRPCRequest(t, testServer, "tasks/cancel", params, "req-cancel-reserved")
+
+			assert.Nil(t, resp.Result, "Response result should be nil for %s", reason)
+			require.NotNil(t, resp.Error, "Response error should not be nil for %s", reason)
+			assert.Equal(t, jsonrpc.CodeInvalidParams, resp.Error.Code)
+		}
+	})
+
 	// --- Test unknown method ---
 	t.Run("unknown method", func(t *testing.T) {
 		params := map[string]string{"data": "foo"}
@@ -731,3 +975,137 @@ func (m *mockTaskManager) ProcessTask(
 
 	return task, nil
 }
+
+// TestA2AServer_HandleSSEStream_FinalEvent verifies that the SSE stream ends after a
+// terminal status event with final=true, even if the event channel is never closed.
+func TestA2AServer_HandleSSEStream_FinalEvent(t *testing.T) {
+	terminalStates := []protocol.TaskState{
+		protocol.TaskStateCompleted,
+		protocol.TaskStateFailed,
+		protocol.TaskStateCanceled,
+	}
+	a2aServer, err := NewA2AServer(defaultAgentCard(), newMockTaskManager())
+	require.NoError(t, err)
+	for _, state := range terminalStates {
+		t.Run(string(state), func(t *testing.T) {
+			taskID := "sse-final-" + string(state)
+			eventsChan := make(chan protocol.TaskEvent, 2)
+			eventsChan <- protocol.TaskStatusUpdateEvent{
+				ID:     taskID,
+				Status: protocol.TaskStatus{State: protocol.TaskStateWorking},
+			}
+			// Final flag deliberately omitted; the server must set it.
+			eventsChan <- protocol.TaskStatusUpdateEvent{
+				ID:     taskID,
+				Status: protocol.TaskStatus{State: state},
+			}
+
+			recorder := httptest.NewRecorder()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				a2aServer.handleSSEStream(context.Background(), recorder, recorder, eventsChan, taskID, taskID, false)
+			}()
+			select {
+			case <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatal("handleSSEStream did not return after final event")
+			}
+
+			reader := sse.NewEventReader(recorder.Body)
+			var eventTypes []string
+			var lastStatus protocol.TaskStatusUpdateEvent
+			for {
+				data, eventType, err := reader.ReadEvent()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				eventTypes = append(eventTypes, eventType)
+				if eventType == protocol.EventTaskStatusUpdate {
+					var resp jsonrpc.RawResponse
+					require.NoError(t, json.Unmarshal(data, &resp))
+					require.NoError(t, json.Unmarshal(resp.Result, &lastStatus))
+				}
+			}
+			assert.Equal(t, []string{
+				protocol.EventTaskStatusUpdate,
+				protocol.EventTaskStatusUpdate,
+				protocol.EventClose,
+			}, eventTypes)
+			assert.Equal(t, state, lastStatus.Status.State)
+			assert.True(t, lastStatus.Final, "terminal status event should be marked final")
+		})
+	}
+}
+
+// TestA2AServer_HandleSSEStream_EventFilter verifies that the server honors the
+// client's stream event filter and still closes the stream on the final status.
+func TestA2AServer_HandleSSEStream_EventFilter(t *testing.T) {
+	a2aServer, err := NewA2AServer(defaultAgentCard(), newMockTaskManager())
+	require.NoError(t, err)
+	tests := []struct {
+		filter   protocol.StreamEventFilter
+		expected []string
+	}{
+		{protocol.StreamEventFilterArtifact, []string{
+			protocol.EventTaskArtifactUpdate,
+			protocol.EventClose,
+		}},
+		{protocol.StreamEventFilterStatus, []string{
+			protocol.EventTaskStatusUpdate,
+			protocol.EventTaskStatusUpdate,
+			protocol.EventClose,
+		}},
+		{protocol.StreamEventFilterAll, []string{
+			protocol.EventTaskStatusUpdate,
+			protocol.EventTaskArtifactUpdate,
+			protocol.EventTaskStatusUpdate,
+			protocol.EventClose,
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.filter), func(t *testing.T) {
+			taskID := "sse-filter-" + string(tc.filter)
+			eventsChan := make(chan protocol.TaskEvent, 3)
+			eventsChan <- protocol.TaskStatusUpdateEvent{
+				ID:     taskID,
+				Status: protocol.TaskStatus{State: protocol.TaskStateWorking},
+			}
+			eventsChan <- protocol.TaskArtifactUpdateEvent{
+				ID:       taskID,
+				Artifact: protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart("chunk")}},
+			}
+			eventsChan <- protocol.TaskStatusUpdateEvent{
+				ID:     taskID,
+				Status: protocol.TaskStatus{State: protocol.TaskStateCompleted},
+				Final:  true,
+			}
+
+			ctx := context.WithValue(context.Background(), streamEventFilterKey{}, tc.filter)
+			recorder := httptest.NewRecorder()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				a2aServer.handleSSEStream(ctx, recorder, recorder, eventsChan, taskID, taskID, false)
+			}()
+			select {
+			case <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatal("handleSSEStream did not return after final event")
+			}
+
+			reader := sse.NewEventReader(recorder.Body)
+			var eventTypes []string
+			for {
+				_, eventType, err := reader.ReadEvent()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				eventTypes = append(eventTypes, eventType)
+			}
+			assert.Equal(t, tc.expected, eventTypes)
+		})
+	}
+}
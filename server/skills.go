@@ -0,0 +1,51 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// SkillRouter picks the skill of the agent card a message is for by inspecting its
+// parts, see WithSkillRouter.
+type SkillRouter func(message protocol.Message) (skillID string, err error)
+
+// hasSkill reports whether the agent card has the skill with the given ID.
+func (s *A2AServer) hasSkill(skillID string) bool {
+	for _, skill := range s.agentCard.Skills {
+		if skill.ID == skillID {
+			return true
+		}
+	}
+	return false
+}
+
+// routeSkill sets the skill of a message sent without one to the one picked by the
+// skill router, if any. It returns an invalid params error if the router fails or
+// picks a skill the agent card does not have.
+func (s *A2AServer) routeSkill(message *protocol.Message) *jsonrpc.Error {
+	if s.skillRouter == nil || protocol.SkillID(*message) != "" {
+		return nil
+	}
+	skillID, err := s.skillRouter(*message)
+	if err != nil {
+		return jsonrpc.ErrInvalidParams(fmt.Sprintf("no skill for the message: %v", err))
+	}
+	if !s.hasSkill(skillID) {
+		return jsonrpc.ErrInvalidParams(fmt.Sprintf("no skill for the message: unknown skill %q", skillID))
+	}
+	metadata := make(map[string]interface{}, len(message.Metadata)+1)
+	for key, value := range message.Metadata {
+		metadata[key] = value
+	}
+	metadata[protocol.MetadataKeySkill] = skillID
+	message.Metadata = metadata
+	return nil
+}
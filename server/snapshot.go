@@ -0,0 +1,64 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// handleTasksExport handles the tasks/export method, enabled with WithTaskSnapshots.
+func (s *A2AServer) handleTasksExport(ctx context.Context, w http.ResponseWriter, request jsonrpc.Request) {
+	var params protocol.TaskIDParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		s.writeJSONRPCError(w, request.ID, err)
+		return
+	}
+	snapshot, err := taskmanager.ExportTask(ctx, s.taskManager, params.ID)
+	if err != nil {
+		s.writeSnapshotError(w, request.ID, "export", params.ID, err)
+		return
+	}
+	s.writeJSONRPCResponse(w, request.ID, snapshot)
+}
+
+// handleTasksImport handles the tasks/import method, enabled with WithTaskSnapshots.
+func (s *A2AServer) handleTasksImport(ctx context.Context, w http.ResponseWriter, request jsonrpc.Request) {
+	var params protocol.TaskImportParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		s.writeJSONRPCError(w, request.ID, err)
+		return
+	}
+	if err := params.Validate(); err != nil {
+		s.writeJSONRPCError(w, request.ID, jsonrpc.ErrInvalidParams(err))
+		return
+	}
+	task, err := taskmanager.ImportTask(ctx, s.taskManager, params)
+	if err != nil {
+		s.writeSnapshotError(w, request.ID, "import", params.Snapshot.Task.ID, err)
+		return
+	}
+	log.Infof("Imported task %s as %s", params.Snapshot.Task.ID, task.ID)
+	s.writeJSONRPCResponse(w, request.ID, task)
+}
+
+// writeSnapshotError writes the error of exporting or importing a task.
+func (s *A2AServer) writeSnapshotError(w http.ResponseWriter, id interface{}, op, taskID string, err error) {
+	if rpcErr, ok := err.(*jsonrpc.Error); ok {
+		log.Errorf("Error during task %s of %s: %v", op, taskID, rpcErr)
+		s.writeJSONRPCError(w, id, rpcErr)
+		return
+	}
+	log.Errorf("Unexpected error during task %s of %s: %v", op, taskID, err)
+	s.writeJSONRPCError(w, id, jsonrpc.ErrInternalError(fmt.Sprintf("failed to %s task: %v", op, err)))
+}
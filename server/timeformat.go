@@ -0,0 +1,101 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// TimeFormat formats the timestamps the server sends, see WithTimeFormat.
+type TimeFormat func(time.Time) string
+
+// TimeFormatSeconds formats timestamps as RFC 3339 in UTC, truncated to seconds.
+func TimeFormatSeconds(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// TimeFormatMillis formats timestamps as RFC 3339 in UTC with milliseconds.
+func TimeFormatMillis(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// TimeFormatEpochMillis formats timestamps as the decimal number of milliseconds
+// since the Unix epoch.
+func TimeFormatEpochMillis(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+// TimeLayout returns a TimeFormat formatting timestamps in UTC with layout, as
+// time.Time.Format does.
+func TimeLayout(layout string) TimeFormat {
+	return func(t time.Time) string {
+		return t.UTC().Format(layout)
+	}
+}
+
+// timeFields are the JSON fields of the protocol types holding timestamps.
+var timeFields = map[string]bool{"timestamp": true, "estimatedCompletion": true}
+
+// opaqueFields are the JSON fields holding application data, whose timestamps, if
+// any, are not the server's to reformat.
+var opaqueFields = map[string]bool{"metadata": true, "data": true}
+
+// formatTimes returns value, a result or event about to be sent, with its
+// timestamps in the format of WithTimeFormat, if set.
+func (s *A2AServer) formatTimes(value interface{}) interface{} {
+	if s.timeFormat == nil {
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value // Let the encoder report it.
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Keep numbers exact through the re-encoding.
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return value
+	}
+	return formatTimeFields(generic, s.timeFormat)
+}
+
+// formatTimeFields reformats the timestamps of a decoded JSON value at any depth,
+// leaving those it cannot parse as they are.
+func formatTimeFields(value interface{}, format TimeFormat) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if opaqueFields[key] {
+				continue
+			}
+			if text, ok := item.(string); ok && timeFields[key] && text != "" {
+				parsed, err := protocol.ParseTime(text)
+				if err != nil {
+					log.Debugf("Not reformatting unparseable %s %q: %v", key, text, err)
+					continue
+				}
+				v[key] = format(parsed)
+				continue
+			}
+			v[key] = formatTimeFields(item, format)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = formatTimeFields(item, format)
+		}
+		return v
+	default:
+		return value
+	}
+}
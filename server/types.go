@@ -7,6 +7,8 @@
 // Package server contains the A2A server implementation and related types.
 package server
 
+import "encoding/json"
+
 // AgentCapabilities defines the capabilities supported by an agent.
 type AgentCapabilities struct {
 	// Streaming is a flag indicating if the agent supports streaming responses.
@@ -78,4 +80,7 @@ type AgentCard struct {
 	DefaultOutputModes []string `json:"defaultOutputModes"`
 	// Skills are optional list of specific skills.
 	Skills []AgentSkill `json:"skills,omitempty"`
+	// Extensions holds vendor-specific capabilities as raw JSON, keyed by namespaced URI.
+	// Use SetExtension and Extension to access them as typed values.
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
 }
@@ -0,0 +1,244 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+)
+
+// ErrCodeServerBusy is the JSON-RPC error code returned when the worker pool queue is full.
+const ErrCodeServerBusy int = -32010
+
+// QueuePolicy decides what happens to a task submitted while the worker pool queue is full.
+type QueuePolicy int
+
+// QueuePolicy constants.
+const (
+	// QueuePolicyBlock waits for room in the queue until the request context is done.
+	QueuePolicyBlock QueuePolicy = iota
+	// QueuePolicyReject rejects the task immediately with a server busy error.
+	QueuePolicyReject
+)
+
+// errWorkerPoolFull is returned by submit when the queue is full under QueuePolicyReject.
+var errWorkerPoolFull = errors.New("worker pool queue is full")
+
+// errWorkerPoolClosed is returned by submit after the pool has been closed.
+var errWorkerPoolClosed = errors.New("worker pool is closed")
+
+// workerPool bounds how many tasks run at once and how many may wait for a turn.
+// Tasks run on the caller's goroutine once they hold a slot, so the pool owns no
+// goroutines and a server used only through Handler needs no shutdown.
+type workerPool struct {
+	slots  chan struct{} // One token per running task.
+	queue  chan struct{} // One token per admitted task, running or waiting.
+	policy QueuePolicy
+	fair   *fairScheduler // Hands out slots across sessions in turn instead of slots, if set.
+
+	mu     sync.RWMutex // Guards closed against close.
+	closed bool
+	wg     sync.WaitGroup // Tracks admitted tasks so close can wait for them.
+}
+
+// newWorkerPool creates a pool running up to size tasks with up to queueSize waiting.
+// With fair set, waiting tasks get free slots round-robin across their sessions.
+func newWorkerPool(size, queueSize int, policy QueuePolicy, fair bool) *workerPool {
+	p := &workerPool{
+		slots:  make(chan struct{}, size),
+		queue:  make(chan struct{}, size+queueSize),
+		policy: policy,
+	}
+	if fair {
+		p.fair = newFairScheduler(size)
+	}
+	return p
+}
+
+// acquire admits a task of the given session according to the pool's policy and
+// waits for a free slot. The returned release function frees the slot and may be
+// called more than once.
+func (p *workerPool) acquire(ctx context.Context, session string) (func(), error) {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return nil, errWorkerPoolClosed
+	}
+	p.wg.Add(1)
+	p.mu.RUnlock()
+	if err := p.admit(ctx); err != nil {
+		p.wg.Done()
+		return nil, err
+	}
+	if err := p.takeSlot(ctx, session); err != nil {
+		<-p.queue
+		p.wg.Done()
+		return nil, err
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.freeSlot()
+			<-p.queue
+			p.wg.Done()
+		})
+	}, nil
+}
+
+// takeSlot waits for a free slot for a task of session.
+func (p *workerPool) takeSlot(ctx context.Context, session string) error {
+	if p.fair != nil {
+		return p.fair.acquire(ctx, session)
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// freeSlot returns a slot taken with takeSlot.
+func (p *workerPool) freeSlot() {
+	if p.fair != nil {
+		p.fair.release()
+		return
+	}
+	<-p.slots
+}
+
+// admit takes a queue token, failing fast under QueuePolicyReject.
+func (p *workerPool) admit(ctx context.Context) error {
+	if p.policy == QueuePolicyReject {
+		select {
+		case p.queue <- struct{}{}:
+			return nil
+		default:
+			return errWorkerPoolFull
+		}
+	}
+	select {
+	case p.queue <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops admitting tasks and waits for admitted tasks to finish.
+func (p *workerPool) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// fairScheduler hands out a fixed number of slots to waiting tasks one session at
+// a time, in turn, so a session with many waiting tasks cannot starve the others.
+// Tasks of the same session are served in arrival order.
+type fairScheduler struct {
+	mu      sync.Mutex
+	free    int                        // Slots nobody holds.
+	turns   []string                   // Sessions with waiting tasks, next to be served first.
+	waiting map[string][]chan struct{} // Waiting tasks by session, closed once given a slot.
+}
+
+// newFairScheduler creates a scheduler for size slots.
+func newFairScheduler(size int) *fairScheduler {
+	return &fairScheduler{free: size, waiting: make(map[string][]chan struct{})}
+}
+
+// acquire waits until a task of session is given a slot or ctx is done.
+func (f *fairScheduler) acquire(ctx context.Context, session string) error {
+	f.mu.Lock()
+	if f.free > 0 && len(f.turns) == 0 {
+		f.free--
+		f.mu.Unlock()
+		return nil
+	}
+	granted := make(chan struct{})
+	if len(f.waiting[session]) == 0 {
+		f.turns = append(f.turns, session)
+	}
+	f.waiting[session] = append(f.waiting[session], granted)
+	f.mu.Unlock()
+	select {
+	case <-granted:
+		return nil
+	case <-ctx.Done():
+	}
+	f.mu.Lock()
+	if !f.dequeue(session, granted) {
+		// The slot was given just as ctx ended; pass it on.
+		f.mu.Unlock()
+		f.release()
+		return ctx.Err()
+	}
+	f.mu.Unlock()
+	return ctx.Err()
+}
+
+// release frees a slot, giving it to the next session in turn if any task waits.
+func (f *fairScheduler) release() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.turns) == 0 {
+		f.free++
+		return
+	}
+	session := f.turns[0]
+	f.turns = f.turns[1:]
+	queue := f.waiting[session]
+	close(queue[0])
+	if len(queue) == 1 {
+		delete(f.waiting, session)
+	} else {
+		f.waiting[session] = queue[1:]
+		f.turns = append(f.turns, session) // Back of the line.
+	}
+}
+
+// dequeue removes a task of session that stopped waiting, and reports whether
+// it was still waiting. The caller must hold f.mu.
+func (f *fairScheduler) dequeue(session string, granted chan struct{}) bool {
+	queue := f.waiting[session]
+	for i, ch := range queue {
+		if ch != granted {
+			continue
+		}
+		queue = append(queue[:i:i], queue[i+1:]...)
+		if len(queue) > 0 {
+			f.waiting[session] = queue
+			return true
+		}
+		delete(f.waiting, session)
+		for j, turn := range f.turns {
+			if turn == session {
+				f.turns = append(f.turns[:j:j], f.turns[j+1:]...)
+				break
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// errServerBusy converts a worker pool submission error to a JSON-RPC error.
+func errServerBusy(err error) *jsonrpc.Error {
+	return &jsonrpc.Error{
+		Code:    ErrCodeServerBusy,
+		Message: "Server busy",
+		Data:    err.Error(),
+	}
+}
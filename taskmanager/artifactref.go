@@ -0,0 +1,65 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// artifactRefKey is the context key for the artifact an ArtifactReferencePart points to.
+type artifactRefKey struct {
+	taskID string
+	index  int
+}
+
+// ResolveArtifactReferences looks up the artifacts that the ArtifactReferencePart
+// parts of message point to in tm and returns a copy of ctx carrying them, so the
+// TaskProcessor can read them with ResolveArtifactReference. The server calls it
+// before handing the message to the task manager. A reference to a missing task or
+// artifact yields an invalid params error.
+func ResolveArtifactReferences(ctx context.Context, tm TaskManager, message protocol.Message) (context.Context, error) {
+	tasks := make(map[string]*protocol.Task)
+	for i, part := range message.Parts {
+		ref, ok := part.(protocol.ArtifactReferencePart)
+		if !ok {
+			continue
+		}
+		task, ok := tasks[ref.TaskID]
+		if !ok {
+			var err error
+			task, err = tm.OnGetTask(ctx, protocol.TaskQueryParams{ID: ref.TaskID})
+			var rpcErr *jsonrpc.Error
+			if errors.As(err, &rpcErr) && rpcErr.Code == ErrCodeTaskNotFound {
+				return ctx, jsonrpc.ErrInvalidParams(
+					fmt.Sprintf("message part %d: referenced task %q not found", i, ref.TaskID))
+			}
+			if err != nil {
+				return ctx, fmt.Errorf("failed to get referenced task %s: %w", ref.TaskID, err)
+			}
+			tasks[ref.TaskID] = task
+		}
+		artifact, ok := protocol.FindArtifact(task.Artifacts, ref.Index)
+		if !ok {
+			return ctx, jsonrpc.ErrInvalidParams(
+				fmt.Sprintf("message part %d: task %q has no artifact %d", i, ref.TaskID, ref.Index))
+		}
+		ctx = context.WithValue(ctx, artifactRefKey{taskID: ref.TaskID, index: ref.Index}, artifact)
+	}
+	return ctx, nil
+}
+
+// ResolveArtifactReference returns the artifact ref points to, as resolved by the
+// server for the message being processed, and whether it was resolved.
+func ResolveArtifactReference(ctx context.Context, ref protocol.ArtifactReferencePart) (protocol.Artifact, bool) {
+	artifact, ok := ctx.Value(artifactRefKey{taskID: ref.TaskID, index: ref.Index}).(protocol.Artifact)
+	return artifact, ok
+}
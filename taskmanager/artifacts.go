@@ -0,0 +1,39 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// ErrTooManyArtifacts is returned when an artifact would take a task over the cap
+// set with ArtifactLimiter. The task is failed and accepts no further updates.
+var ErrTooManyArtifacts = errors.New("too many artifacts")
+
+// CheckArtifactLimit returns an error wrapping ErrTooManyArtifacts if adding added
+// to the task's artifacts would give it more than limit distinct artifacts. Chunks
+// for an index the task already has, such as streamed appends, do not count.
+// A limit of zero or less means no limit.
+func CheckArtifactLimit(task *protocol.Task, added []protocol.Artifact, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	indexes := make(map[int]struct{}, len(task.Artifacts)+len(added))
+	for _, artifact := range task.Artifacts {
+		indexes[artifact.Index] = struct{}{}
+	}
+	for _, artifact := range added {
+		indexes[artifact.Index] = struct{}{}
+	}
+	if len(indexes) > limit {
+		return fmt.Errorf("%w: task %s is limited to %d artifacts", ErrTooManyArtifacts, task.ID, limit)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"errors"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+var (
+	// ErrEventDropped is returned by SendSubscriberEvent when a subscriber had no
+	// room for an event and no send timeout was set.
+	ErrEventDropped = errors.New("subscriber has no room for the event")
+	// ErrSlowSubscriber is returned by SendSubscriberEvent when a subscriber did
+	// not make room for an event within the send timeout.
+	ErrSlowSubscriber = errors.New("subscriber too slow to receive events")
+)
+
+// SendSubscriberEvent delivers event to a subscriber's channel. With a zero timeout
+// it never blocks, returning ErrEventDropped if ch is full. Otherwise it waits up
+// to timeout for room in ch, and returns ErrSlowSubscriber if there was none, so
+// the caller can drop the subscriber.
+func SendSubscriberEvent(ch chan<- protocol.TaskEvent, event protocol.TaskEvent, timeout time.Duration) error {
+	select {
+	case ch <- event:
+		return nil
+	default:
+	}
+	if timeout <= 0 {
+		return ErrEventDropped
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case ch <- event:
+		return nil
+	case <-timer.C:
+		return ErrSlowSubscriber
+	}
+}
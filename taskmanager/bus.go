@@ -0,0 +1,193 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// defaultBusBuffer is the buffer of a BusSubscription created with a buffer of
+// zero or less.
+const defaultBusBuffer = 64
+
+// BusEventType identifies the kind of a BusEvent.
+type BusEventType string
+
+// BusEventType enum values.
+const (
+	// BusEventTaskCreated is published when a task is created.
+	BusEventTaskCreated BusEventType = "task_created"
+	// BusEventStatusChanged is published for each new status of a task.
+	BusEventStatusChanged BusEventType = "status_changed"
+	// BusEventArtifact is published when an artifact is added, patched, replaced
+	// or deleted.
+	BusEventArtifact BusEventType = "artifact"
+	// BusEventMessage is published for each intermediate message of a task.
+	BusEventMessage BusEventType = "message"
+	// BusEventTaskFinished is published after the status event of a task reaching a
+	// final state: completed, failed or canceled.
+	BusEventTaskFinished BusEventType = "task_finished"
+)
+
+// BusEvent is an event of a task published on an EventBus.
+type BusEvent struct {
+	// Type is the kind of event.
+	Type BusEventType
+	// TaskID is the ID of the task.
+	TaskID string
+	// Time is when the event was published.
+	Time time.Time
+	// Event is the task event behind the bus event, as sent to the task's
+	// subscribers: the status update of status_changed and task_finished events,
+	// the artifact event of artifact events and the message event of message
+	// events. It is nil for task_created events.
+	Event protocol.TaskEvent
+}
+
+// BusPolicy selects what a BusSubscription drops when its buffer is full, so a
+// slow subscriber never blocks the task manager.
+type BusPolicy int
+
+// BusPolicy enum values.
+const (
+	// BusDropNewest drops the event that does not fit, keeping the buffered ones.
+	BusDropNewest BusPolicy = iota
+	// BusDropOldest drops the oldest buffered event to make room for the new one.
+	BusDropOldest
+)
+
+// EventBus publishes the events of the tasks of one or more task managers to
+// in-process subscribers, for observability or side effects such as notifying a
+// UI. Task managers implementing EventPublisher publish to it. It is safe for
+// concurrent use.
+type EventBus struct {
+	mu   sync.RWMutex
+	subs map[*BusSubscription]struct{}
+}
+
+// NewEventBus creates an EventBus without subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[*BusSubscription]struct{})}
+}
+
+// Subscribe returns a subscription receiving the events published from then on,
+// in order, through a channel buffering up to buffer events. A buffer of zero or
+// less defaults to 64. Events that do not fit are dropped as selected by policy.
+// Close the subscription once done with it.
+func (b *EventBus) Subscribe(buffer int, policy BusPolicy) *BusSubscription {
+	if buffer <= 0 {
+		buffer = defaultBusBuffer
+	}
+	sub := &BusSubscription{bus: b, events: make(chan BusEvent, buffer), policy: policy}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Publish sends event to every subscriber without blocking. A nil bus drops it.
+func (b *EventBus) Publish(event BusEvent) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		sub.deliver(event)
+	}
+}
+
+// publishTaskEvent publishes the bus events for event, a task event sent to the
+// subscribers of taskID.
+func (b *EventBus) publishTaskEvent(taskID string, event protocol.TaskEvent) {
+	if b == nil {
+		return
+	}
+	busEvent := BusEvent{TaskID: taskID, Event: event}
+	switch event.(type) {
+	case protocol.TaskStatusUpdateEvent:
+		busEvent.Type = BusEventStatusChanged
+	case protocol.TaskMessageEvent:
+		busEvent.Type = BusEventMessage
+	default:
+		busEvent.Type = BusEventArtifact
+	}
+	b.Publish(busEvent)
+	if status, ok := event.(protocol.TaskStatusUpdateEvent); ok && status.Status.State.IsFinal() {
+		busEvent.Type = BusEventTaskFinished
+		b.Publish(busEvent)
+	}
+}
+
+// BusSubscription receives the events of an EventBus.
+type BusSubscription struct {
+	bus     *EventBus
+	events  chan BusEvent
+	policy  BusPolicy
+	dropped atomic.Int64
+
+	mu     sync.Mutex // Serializes deliveries with Close.
+	closed bool
+}
+
+// Events returns the channel of the subscription's events. It is closed by Close.
+func (s *BusSubscription) Events() <-chan BusEvent {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because the buffer was full.
+func (s *BusSubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close stops the subscription and closes its channel. It may be called more
+// than once.
+func (s *BusSubscription) Close() {
+	s.bus.mu.Lock()
+	delete(s.bus.subs, s)
+	s.bus.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+}
+
+// deliver buffers event, dropping an event as selected by the policy if the
+// buffer is full.
+func (s *BusSubscription) deliver(event BusEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.events <- event:
+		return
+	default:
+	}
+	s.dropped.Add(1)
+	if s.policy != BusDropOldest {
+		return
+	}
+	select {
+	case <-s.events:
+	default:
+	}
+	select {
+	case s.events <- event:
+	default:
+	}
+}
@@ -0,0 +1,65 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// CancelError is the cancellation cause attached to a processor's context when
+// its task is canceled. It matches context.Canceled with errors.Is.
+type CancelError struct {
+	// Reason is why the task was canceled.
+	Reason protocol.CancelReason
+}
+
+// Error implements error.
+func (e *CancelError) Error() string {
+	return fmt.Sprintf("task canceled: %s", e.Reason)
+}
+
+// Is reports whether target is context.Canceled.
+func (e *CancelError) Is(target error) bool {
+	return target == context.Canceled
+}
+
+// CancelReasonFromContext returns the reason the task owning ctx was canceled.
+// The second result is false if ctx has not been canceled through the task manager.
+func CancelReasonFromContext(ctx context.Context) (protocol.CancelReason, bool) {
+	var cancelErr *CancelError
+	if errors.As(context.Cause(ctx), &cancelErr) {
+		return cancelErr.Reason, true
+	}
+	return "", false
+}
+
+// NewCancelMessage creates the agent message recorded in a task's canceled status.
+func NewCancelMessage(taskID string, reason protocol.CancelReason) *protocol.Message {
+	var text string
+	switch reason {
+	case protocol.CancelReasonUserRequested:
+		text = fmt.Sprintf("Task %s was canceled by user request", taskID)
+	case protocol.CancelReasonTimeout:
+		text = fmt.Sprintf("Task %s was canceled because it timed out", taskID)
+	case protocol.CancelReasonServerShutdown:
+		text = fmt.Sprintf("Task %s was canceled because the server is shutting down", taskID)
+	case protocol.CancelReasonSuperseded:
+		text = fmt.Sprintf("Task %s was canceled because it was superseded", taskID)
+	case protocol.CancelReasonClientDisconnected:
+		text = fmt.Sprintf("Task %s was canceled because its client disconnected", taskID)
+	default:
+		text = fmt.Sprintf("Task %s was canceled: %s", taskID, reason)
+	}
+	return &protocol.Message{
+		Role:  protocol.MessageRoleAgent,
+		Parts: []protocol.Part{protocol.NewTextPart(text)},
+	}
+}
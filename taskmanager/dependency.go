@@ -0,0 +1,110 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// dependencyPollInterval bounds how long WaitForDependencies waits for a status
+// change before looking at a dependency again, so changes that do not wake the
+// TaskWaiter, such as those made by another process, are still seen.
+const dependencyPollInterval = time.Second
+
+// TaskLookup returns the task with the given ID, or an ErrTaskNotFound error.
+type TaskLookup func(ctx context.Context, taskID string) (*protocol.Task, error)
+
+// DependencyError reports that a task a pending task depends on did not complete,
+// so the pending task is failed instead of run.
+type DependencyError struct {
+	// TaskID is the ID of the dependency.
+	TaskID string
+	// State is the final state the dependency ended in.
+	State protocol.TaskState
+}
+
+// Error implements error.
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("dependency task %s ended %s", e.TaskID, e.State)
+}
+
+// CheckDependencies returns an invalid params error if a task in dependsOn does not
+// exist or depends, directly or through other tasks, on taskID, since the tasks
+// would then wait for each other forever.
+func CheckDependencies(ctx context.Context, taskID string, dependsOn []string, lookup TaskLookup) error {
+	visited := make(map[string]bool)
+	var visit func(id string, path []string) error
+	visit = func(id string, path []string) error {
+		if id == taskID {
+			return jsonrpc.ErrInvalidParams(fmt.Sprintf("dependency cycle: %v", append(path, id)))
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+		task, err := lookup(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, next := range task.DependsOn {
+			if err := visit(next, append(path, id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, id := range dependsOn {
+		if _, err := lookup(ctx, id); err != nil {
+			var rpcErr *jsonrpc.Error
+			if errors.As(err, &rpcErr) && rpcErr.Code == ErrCodeTaskNotFound {
+				return jsonrpc.ErrInvalidParams(fmt.Sprintf("dependency task %q not found", id))
+			}
+			return fmt.Errorf("failed to get dependency task %s: %w", id, err)
+		}
+		if err := visit(id, []string{taskID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitForDependencies blocks until every task in dependsOn has completed. It returns
+// a *DependencyError as soon as one of them fails or is canceled, and ctx.Err() if
+// ctx is done first.
+func WaitForDependencies(ctx context.Context, dependsOn []string, lookup TaskLookup, waiter TaskWaiter) error {
+	for _, id := range dependsOn {
+		for {
+			task, err := lookup(ctx, id)
+			if err != nil {
+				return fmt.Errorf("failed to get dependency task %s: %w", id, err)
+			}
+			state := task.Status.State
+			if state == protocol.TaskStateCompleted {
+				break
+			}
+			if state.IsFinal() {
+				return &DependencyError{TaskID: id, State: state}
+			}
+			waitCtx, cancel := context.WithTimeout(ctx, dependencyPollInterval)
+			err = waiter.WaitForTaskChange(waitCtx, id)
+			cancel()
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("failed to wait for dependency task %s: %w", id, err)
+			}
+		}
+	}
+	return nil
+}
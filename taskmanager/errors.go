@@ -19,16 +19,15 @@ const (
 	ErrCodeTaskNotFound                  int = -32001 // Custom server error code range.
 	ErrCodeTaskFinal                     int = -32002
 	ErrCodePushNotificationNotConfigured int = -32003
+	// ErrCodeTaskExists stays clear of the A2A codes, -32001 to -32006, and of the
+	// server's ErrCodeServerBusy and ErrCodeAdmissionDenied.
+	ErrCodeTaskExists int = -32012
 )
 
 // ErrTaskNotFound creates a JSON-RPC error for task not found.
 // Exported function.
 func ErrTaskNotFound(taskID string) *jsonrpc.Error {
-	return &jsonrpc.Error{
-		Code:    ErrCodeTaskNotFound,
-		Message: "Task not found",
-		Data:    fmt.Sprintf("Task with ID '%s' was not found.", taskID),
-	}
+	return jsonrpc.NewTaskNotFoundError(taskID)
 }
 
 // ErrTaskFinalState creates a JSON-RPC error for attempting an operation on a task
@@ -52,3 +51,12 @@ func ErrPushNotificationNotConfigured(taskID string) *jsonrpc.Error {
 		Data:    fmt.Sprintf("Task '%s' does not have push notifications configured.", taskID),
 	}
 }
+
+// ErrTaskExists creates a JSON-RPC error for importing a task whose ID is taken.
+func ErrTaskExists(taskID string) *jsonrpc.Error {
+	return &jsonrpc.Error{
+		Code:    ErrCodeTaskExists,
+		Message: "Task already exists",
+		Data:    fmt.Sprintf("Task with ID '%s' already exists.", taskID),
+	}
+}
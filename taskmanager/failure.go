@@ -0,0 +1,59 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"errors"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// CodedError is an error with a machine-readable code. TaskHandle.Fail reports
+// the code in the failed status, so clients can act on the kind of failure.
+type CodedError struct {
+	// Code identifies the kind of failure, such as "rate_limited".
+	Code string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// WithErrorCode returns err with code attached, for TaskHandle.Fail.
+func WithErrorCode(code string, err error) error {
+	return &CodedError{Code: code, Err: err}
+}
+
+// FailureStatus returns the failed status TaskHandle.Fail sets for err, whose
+// TaskError takes its code from a *CodedError in the chain of err.
+func FailureStatus(err error, retryable bool) protocol.TaskStatus {
+	text := "task failed"
+	if err != nil {
+		text = err.Error()
+	}
+	taskErr := &protocol.TaskError{Message: text, Retryable: retryable}
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		taskErr.Code = coded.Code
+	}
+	return protocol.TaskStatus{
+		State: protocol.TaskStateFailed,
+		Message: &protocol.Message{
+			Role:  protocol.MessageRoleAgent,
+			Parts: []protocol.Part{protocol.NewTextPart(text)},
+		},
+		Error: taskErr,
+	}
+}
@@ -0,0 +1,95 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// ErrInputClosed is returned by InputStreamer.OnSendInput for a task that takes no
+// more input, because the final input was sent or its processor is not running.
+var ErrInputClosed = errors.New("task is not taking input")
+
+// inputBufferSize is the number of input messages buffered for a processor.
+const inputBufferSize = 16
+
+// taskInput carries the input streamed to the processor of a running task.
+type taskInput struct {
+	messages chan protocol.Message
+	done     chan struct{} // Closed once the processor ended.
+
+	mu     sync.Mutex // Serializes senders, so input is delivered in order.
+	closed bool       // The final input was sent.
+}
+
+// newTaskInput creates the input of a processor about to run.
+func newTaskInput() *taskInput {
+	return &taskInput{
+		messages: make(chan protocol.Message, inputBufferSize),
+		done:     make(chan struct{}),
+	}
+}
+
+// send delivers message to the processor, closing the input after it if final.
+func (in *taskInput) send(ctx context.Context, message protocol.Message, final bool) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.closed {
+		return ErrInputClosed
+	}
+	select {
+	case in.messages <- message:
+	case <-in.done:
+		return ErrInputClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if final {
+		in.closed = true
+		close(in.messages)
+	}
+	return nil
+}
+
+// openInput creates the input of the processor about to run for taskID.
+func (m *MemoryTaskManager) openInput(taskID string) *taskInput {
+	input := newTaskInput()
+	m.inputs.Store(taskID, input)
+	return input
+}
+
+// closeInput rejects further input once the processor reading input ended.
+func (m *MemoryTaskManager) closeInput(taskID string, input *taskInput) {
+	m.inputs.CompareAndDelete(taskID, input)
+	close(input.done)
+}
+
+// OnSendInput implements InputStreamer. Input is delivered to the processor only;
+// it is not added to the task's message history.
+func (m *MemoryTaskManager) OnSendInput(ctx context.Context, params protocol.TaskInputParams) error {
+	task, err := m.getTaskInternal(params.ID)
+	if err != nil {
+		return err
+	}
+	if task.Status.State.IsFinal() {
+		return ErrTaskFinalState(params.ID, task.Status.State)
+	}
+	input, ok := m.inputs.Load(params.ID)
+	if !ok {
+		return ErrInputClosed
+	}
+	return input.(*taskInput).send(ctx, params.Message, params.Final)
+}
+
+// Input implements InputReader.
+func (h *memoryTaskHandle) Input() <-chan protocol.Message {
+	return h.input.messages
+}
@@ -9,6 +9,7 @@ package taskmanager
 
 import (
 	"context"
+	"time"
 
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
@@ -21,13 +22,58 @@ type TaskHandle interface {
 	UpdateStatus(state protocol.TaskState, msg *protocol.Message) error
 
 	// AddArtifact adds a new artifact to the task.
+	// Artifacts and chunks added one after another are stored and streamed in that
+	// order, with their parts in order; see protocol.AssembleArtifacts.
 	// Returns an error if the task cannot be found or updated.
 	AddArtifact(artifact protocol.Artifact) error
 
+	// SendMessage sends an intermediate message to the client while the task is running.
+	// The message is added to the task history but does not change the task's status.
+	// Returns an error if the task cannot be found.
+	SendMessage(msg protocol.Message) error
+
+	// PatchArtifact applies JSON Patch (RFC 6902) operations to the data of the
+	// DataPart at position part of the artifact with the given index, and streams
+	// them as a protocol.TaskArtifactPatchEvent instead of the whole artifact. The
+	// parts of an artifact added in chunks are counted through all its chunks.
+	// An invalid patch is rejected with a protocol.ErrInvalidPatch error and changes nothing.
+	PatchArtifact(index, part int, patch []protocol.PatchOperation) error
+
+	// ReplaceArtifact replaces every chunk of the artifact with artifact.Index with
+	// artifact, e.g. a final version superseding a draft, and streams it as an update
+	// without Append set. An index the task does not have yet is added.
+	ReplaceArtifact(artifact protocol.Artifact) error
+
+	// DeleteArtifact removes the artifact with the given index and streams a
+	// protocol.TaskArtifactDeleteEvent. Deleting an index the task does not have is
+	// rejected with a protocol.ErrArtifactNotFound error.
+	DeleteArtifact(index int) error
+
+	// Complete marks the task completed with msg as its result, adding artifacts
+	// in the same update so clients never see the status before its output.
+	// Every later update through the handle, including Complete, is rejected with
+	// an ErrTaskFinalState error.
+	Complete(msg protocol.Message, artifacts ...protocol.Artifact) error
+
+	// Fail marks the task failed because of err, whose message becomes the status
+	// message. The status carries a protocol.TaskError with retryable, telling the
+	// client whether sending the task again may help, and the code of a CodedError
+	// in the chain of err. Like Complete, it rejects later updates.
+	Fail(err error, retryable bool) error
+
+	// SetEstimatedCompletion sets the time the task is expected to finish on its
+	// working status and streams the updated status. It can be called again as the
+	// estimate changes, and is rejected unless the task is working.
+	SetEstimatedCompletion(eta time.Time) error
+
 	// IsStreamingRequest returns true if the task was initiated via a streaming request
 	// (OnSendTaskSubscribe) rather than a synchronous request (OnSendTask).
 	// This allows the TaskProcessor to adapt its behavior based on the request type.
 	IsStreamingRequest() bool
+
+	// GetMessageHistory returns the messages stored for the task so far, oldest first,
+	// including any seed messages supplied in SendTaskParams.Messages.
+	GetMessageHistory() ([]protocol.Message, error)
 }
 
 // TaskProcessor defines the interface for the core agent logic that processes a task.
@@ -36,7 +82,8 @@ type TaskProcessor interface {
 	// Process executes the specific logic for a task.
 	// It receives the task ID, the initial message, and a TaskHandle for callbacks.
 	// It should use handle.Context() to check for cancellation.
-	// It should report progress and results via handle.UpdateStatus and handle.AddArtifact.
+	// It should report progress and results via handle.UpdateStatus, handle.AddArtifact
+	// and handle.SendMessage.
 	// Returning an error indicates the processing failed fundamentally.
 	Process(ctx context.Context, taskID string, initialMsg protocol.Message, handle TaskHandle) error
 }
@@ -80,3 +127,99 @@ type TaskManager interface {
 	// It reestablishes an SSE stream for an existing task.
 	OnResubscribe(ctx context.Context, params protocol.TaskIDParams) (<-chan protocol.TaskEvent, error)
 }
+
+// TaskPruner is implemented by task managers that can delete finished tasks,
+// so servers can bound how much task state they keep.
+type TaskPruner interface {
+	// PruneTasks deletes tasks that reached a final state before the given time,
+	// along with their message history and push notification configuration.
+	// If onEvict is non-nil it is called with each task before the task is deleted.
+	// It returns the number of tasks deleted.
+	PruneTasks(ctx context.Context, before time.Time, onEvict func(protocol.Task)) (int, error)
+}
+
+// HistoryLimiter is implemented by task managers that can cap the size of the
+// message history they keep per task.
+type HistoryLimiter interface {
+	// SetMaxHistoryBytes caps the JSON-encoded size of each task's message history
+	// at n bytes, evicting the oldest messages once a new message would exceed it.
+	// A single message larger than n is not stored. Zero or less removes the cap.
+	SetMaxHistoryBytes(n int)
+}
+
+// ProcessorLimiter is implemented by task managers that can bound how long a task
+// processor runs, so a hanging processor cannot hold a task forever.
+type ProcessorLimiter interface {
+	// SetProcessorTimeout fails a task whose processor runs longer than d, cancelling
+	// the processor's context with ErrProcessorTimeout. For streaming tasks the
+	// timeout restarts on each event the task emits. Zero or less removes the bound.
+	SetProcessorTimeout(d time.Duration)
+}
+
+// ArtifactLimiter is implemented by task managers that can cap the number of
+// artifacts a task may produce, so a runaway processor cannot emit them unbounded.
+type ArtifactLimiter interface {
+	// SetMaxArtifactsPerTask caps each task at n distinct artifacts. An artifact
+	// beyond the cap is rejected with ErrTooManyArtifacts and the task is failed;
+	// chunks appended to an existing artifact do not count. Zero or less removes the cap.
+	SetMaxArtifactsPerTask(n int)
+}
+
+// BackpressureLimiter is implemented by task managers that can make a task wait for
+// slow subscribers instead of dropping the events they have no room for.
+type BackpressureLimiter interface {
+	// SetSubscriberSendTimeout makes sending an event to a subscriber whose buffer
+	// is full block the emitting processor for up to d. A subscriber still full
+	// after d is dropped and receives no further events. Zero or less restores the
+	// default of dropping the events a subscriber has no room for.
+	SetSubscriberSendTimeout(d time.Duration)
+}
+
+// TaskSnapshotter is implemented by task managers that can export and import tasks
+// as snapshots, so tasks can be migrated between servers. See ExportTask and ImportTask.
+type TaskSnapshotter interface {
+	// ExportTask returns a snapshot of the task, including its full message history
+	// and lifecycle event log. It returns an error if the task does not exist.
+	ExportTask(ctx context.Context, taskID string) (*protocol.TaskSnapshot, error)
+	// RestoreTask stores task, along with the message history and lifecycle events
+	// in its History and Events fields. If a task with the same ID exists it is
+	// replaced, with all its state, when replace is set; otherwise ErrTaskExists is
+	// returned. The restored task is not processed.
+	RestoreTask(ctx context.Context, task protocol.Task, replace bool) error
+}
+
+// TaskWaiter is implemented by task managers that can block until a task's status
+// changes, so servers can answer long-poll tasks/get requests.
+type TaskWaiter interface {
+	// WaitForTaskChange blocks until the status of the task changes after the call
+	// begins or the task is deleted. It returns immediately if the task is already
+	// in a final state, and returns ctx.Err() if ctx is done first.
+	// It returns an error if the task does not exist.
+	WaitForTaskChange(ctx context.Context, taskID string) error
+}
+
+// InputStreamer is implemented by task managers that can deliver input streamed to
+// a running task with tasks/sendInput to its processor, see InputReader.
+type InputStreamer interface {
+	// OnSendInput delivers the input message to the processor of the running task,
+	// blocking while the processor has not taken the earlier input off its buffer.
+	// It returns ErrInputClosed once the final input was sent or the processor
+	// ended, and an error if the task does not exist or is in a final state.
+	OnSendInput(ctx context.Context, params protocol.TaskInputParams) error
+}
+
+// InputReader is implemented by the task handles of task managers implementing
+// InputStreamer. Processors taking streamed input assert it on their handle.
+type InputReader interface {
+	// Input returns the messages streamed to the task, in the order sent. The
+	// channel is closed after the final input message.
+	Input() <-chan protocol.Message
+}
+
+// EventPublisher is implemented by task managers that can publish the events of
+// all their tasks to an EventBus, see BusEventType.
+type EventPublisher interface {
+	// SetEventBus publishes the events of every task to bus from then on. A nil
+	// bus stops publishing.
+	SetEventBus(bus *EventBus)
+}
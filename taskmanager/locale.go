@@ -0,0 +1,24 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import "context"
+
+// localeKey is the context key for the user's locale.
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying the user's BCP 47 locale.
+// The server sets it before calling the task manager, so it reaches the TaskProcessor.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the user's locale stored in ctx, or an empty string if none is set.
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
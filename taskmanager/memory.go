@@ -9,12 +9,15 @@ package taskmanager
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"trpc.group/trpc-go/trpc-a2a-go/internal/checksum"
 	"trpc.group/trpc-go/trpc-a2a-go/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
@@ -28,26 +31,61 @@ type MemoryTaskManager struct {
 	Processor TaskProcessor
 	// Tasks is a map of task IDs to tasks.
 	Tasks map[string]*protocol.Task
-	// TasksMutex is a mutex for the Tasks map.
+	// TasksMutex is a mutex for the Tasks map and the LabelIndex.
 	TasksMutex sync.RWMutex
+	// LabelIndex maps "key=value" label pairs to the IDs of tasks carrying them.
+	LabelIndex map[string]map[string]struct{}
 	// Messages is a map of task IDs to message history.
 	Messages map[string][]protocol.Message
 	// MessagesMutex is a mutex for the Messages map.
 	MessagesMutex sync.RWMutex
+	// maxHistoryBytes caps the encoded size of each task's history (0 is no cap).
+	// It is guarded by MessagesMutex.
+	maxHistoryBytes int
 	// Subscribers is a map of task IDs to subscriber channels.
 	Subscribers map[string][]chan<- protocol.TaskEvent
 	// SubMutex is a mutex for the Subscribers map.
 	SubMutex sync.RWMutex
 	// Contexts is a map of task IDs to cancellation functions.
 	Contexts map[string]context.CancelFunc
-	// ContextsMutex is a mutex for the Contexts map.
+	// ContextsMutex is a mutex for the Contexts and cancelCauses maps.
 	ContextsMutex sync.RWMutex
+	// cancelCauses holds the cause-aware cancel functions behind Contexts,
+	// so OnCancelTask can pass the cancellation reason to the processor.
+	cancelCauses map[string]context.CancelCauseFunc
 	// PushNotifications is a map of task IDs to push notification configurations.
 	PushNotifications map[string]protocol.PushNotificationConfig
 	// PushNotificationsMutex is a mutex for the PushNotifications map.
 	PushNotificationsMutex sync.RWMutex
+	// Events is a map of task IDs to lifecycle event logs.
+	Events map[string][]protocol.TaskLifecycleEvent
+	// EventsMutex is a mutex for the Events map.
+	EventsMutex sync.RWMutex
+	// PushQueue, when set, delivers task events to the push notification
+	// configured for the task. Set it before the manager is used.
+	PushQueue *PushQueue
+	// statusWaiters holds, per task, a channel closed on its next status change.
+	// It is guarded by TasksMutex.
+	statusWaiters map[string]chan struct{}
+	// processorTimeout bounds how long a processor may run; see SetProcessorTimeout.
+	processorTimeout atomic.Int64
+	// maxArtifacts caps the distinct artifacts per task; see SetMaxArtifactsPerTask.
+	maxArtifacts atomic.Int64
+	// sendTimeout bounds how long an event waits for a slow subscriber; see
+	// SetSubscriberSendTimeout.
+	sendTimeout atomic.Int64
+	// watchdogs holds the watchdogs of streaming tasks, reset on each event.
+	watchdogs sync.Map
+	// inputs holds the *taskInput of each running processor, by task ID.
+	inputs sync.Map
+	// bus receives the events of all tasks; see SetEventBus.
+	bus atomic.Pointer[EventBus]
 }
 
+// MaxLifecycleEvents is the number of lifecycle events kept per task; older
+// events are discarded first.
+const MaxLifecycleEvents = 100
+
 // NewMemoryTaskManager creates a new instance with the provided TaskProcessor.
 func NewMemoryTaskManager(processor TaskProcessor) (*MemoryTaskManager, error) {
 	if processor == nil {
@@ -56,10 +94,14 @@ func NewMemoryTaskManager(processor TaskProcessor) (*MemoryTaskManager, error) {
 	return &MemoryTaskManager{
 		Processor:         processor,
 		Tasks:             make(map[string]*protocol.Task),
+		LabelIndex:        make(map[string]map[string]struct{}),
 		Messages:          make(map[string][]protocol.Message),
 		Subscribers:       make(map[string][]chan<- protocol.TaskEvent),
 		Contexts:          make(map[string]context.CancelFunc),
+		cancelCauses:      make(map[string]context.CancelCauseFunc),
 		PushNotifications: make(map[string]protocol.PushNotificationConfig),
+		Events:            make(map[string][]protocol.TaskLifecycleEvent),
+		statusWaiters:     make(map[string]chan struct{}),
 	}, nil
 }
 
@@ -73,7 +115,9 @@ func (m *MemoryTaskManager) processTaskWithProcessor(
 	handle := &memoryTaskHandle{
 		taskID:  taskID,
 		manager: m,
+		input:   m.openInput(taskID),
 	}
+	defer m.closeInput(taskID, handle.input)
 
 	// Set initial status to Working before calling Process
 	if err := m.UpdateTaskStatus(taskID, protocol.TaskStateWorking, nil); err != nil {
@@ -81,15 +125,40 @@ func (m *MemoryTaskManager) processTaskWithProcessor(
 		return fmt.Errorf("failed to set initial working status: %w", err)
 	}
 
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	handle.ctx = ctx
+	// Let OnCancelTask stop the processor.
+	m.ContextsMutex.Lock()
+	m.Contexts[taskID] = func() { cancel(nil) }
+	m.cancelCauses[taskID] = cancel
+	m.ContextsMutex.Unlock()
+	defer func() {
+		m.ContextsMutex.Lock()
+		delete(m.Contexts, taskID)
+		delete(m.cancelCauses, taskID)
+		m.ContextsMutex.Unlock()
+	}()
+	watchdog := m.startWatchdog(taskID, handle, cancel)
+	defer watchdog.Stop()
+
 	// Delegate the actual processing to the injected processor
-	if err := m.Processor.Process(ctx, taskID, message, handle); err != nil {
+	m.recordEvent(taskID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleProcessingStarted, "", ""))
+	if err := m.runProcessor(ctx, taskID, message, handle, watchdog); err != nil {
+		if errors.Is(err, ErrProcessorTimeout) {
+			return err // The watchdog already failed the task.
+		}
+		if handle.canceled() {
+			return nil // The task is canceled, not failed.
+		}
 		log.Errorf("Processor failed for task %s: %v", taskID, err)
+		m.recordEvent(taskID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleError, "", err.Error()))
 		errMsg := &protocol.Message{
 			Role:  protocol.MessageRoleAgent,
 			Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
 		}
 		// Log update error while still handling the processor error
-		if updateErr := m.UpdateTaskStatus(taskID, protocol.TaskStateFailed, errMsg); updateErr != nil {
+		if updateErr := handle.UpdateStatus(protocol.TaskStateFailed, errMsg); updateErr != nil {
 			log.Errorf("Failed to update task %s status to failed: %v", taskID, updateErr)
 		}
 		return err
@@ -102,6 +171,7 @@ func (m *MemoryTaskManager) processTaskWithProcessor(
 // It returns immediately, with the processing continuing asynchronously.
 func (m *MemoryTaskManager) startTaskSubscribe(
 	ctx context.Context,
+	cancel context.CancelCauseFunc,
 	taskID string,
 	message protocol.Message,
 ) {
@@ -109,22 +179,36 @@ func (m *MemoryTaskManager) startTaskSubscribe(
 	handle := &memoryTaskHandle{
 		taskID:  taskID,
 		manager: m,
+		ctx:     ctx,
+		input:   m.openInput(taskID),
 	}
 
 	log.Debugf("SSE Processor started for task %s", taskID)
 
+	watchdog := m.startWatchdog(taskID, handle, cancel)
+	if watchdog != nil {
+		m.watchdogs.Store(taskID, watchdog)
+	}
+
 	// Start the processor in a goroutine
 	go func() {
 		var err error
+		defer func() {
+			watchdog.Stop()
+			m.watchdogs.CompareAndDelete(taskID, watchdog)
+			m.closeInput(taskID, handle.input)
+		}()
+		m.recordEvent(taskID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleProcessingStarted, "", ""))
 		if err = m.Processor.Process(ctx, taskID, message, handle); err != nil {
 			log.Errorf("Processor failed for task %s in subscribe: %v", taskID, err)
+			m.recordEvent(taskID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleError, "", err.Error()))
 			if ctx.Err() != context.Canceled {
 				// Only update to failed if not already cancelled
 				errMsg := &protocol.Message{
 					Role:  protocol.MessageRoleAgent,
 					Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
 				}
-				if updateErr := m.UpdateTaskStatus(taskID, protocol.TaskStateFailed, errMsg); updateErr != nil {
+				if updateErr := handle.UpdateStatus(protocol.TaskStateFailed, errMsg); updateErr != nil {
 					log.Errorf("Failed to update task %s status to failed: %v", taskID, updateErr)
 				}
 			}
@@ -133,24 +217,108 @@ func (m *MemoryTaskManager) startTaskSubscribe(
 		// Clean up the context regardless of how we finish
 		m.ContextsMutex.Lock()
 		delete(m.Contexts, taskID)
+		delete(m.cancelCauses, taskID)
 		m.ContextsMutex.Unlock()
 
 		log.Debugf("Processor finished for task %s in subscribe (Error: %v). Goroutine exiting.", taskID, err)
 	}()
 }
 
+// SetProcessorTimeout implements ProcessorLimiter.
+func (m *MemoryTaskManager) SetProcessorTimeout(d time.Duration) {
+	m.processorTimeout.Store(int64(max(d, 0)))
+}
+
+// SetMaxArtifactsPerTask implements ArtifactLimiter.
+func (m *MemoryTaskManager) SetMaxArtifactsPerTask(n int) {
+	m.maxArtifacts.Store(int64(max(n, 0)))
+}
+
+// SetEventBus implements EventPublisher.
+func (m *MemoryTaskManager) SetEventBus(bus *EventBus) {
+	m.bus.Store(bus)
+}
+
+// SetSubscriberSendTimeout implements BackpressureLimiter.
+func (m *MemoryTaskManager) SetSubscriberSendTimeout(d time.Duration) {
+	m.sendTimeout.Store(int64(max(d, 0)))
+}
+
+// startWatchdog starts the processor watchdog of a task, if a processor timeout is
+// set. When it fires the processor's context is cancelled and the task failed.
+func (m *MemoryTaskManager) startWatchdog(
+	taskID string,
+	handle *memoryTaskHandle,
+	cancel context.CancelCauseFunc,
+) *ProcessorWatchdog {
+	timeout := time.Duration(m.processorTimeout.Load())
+	return NewProcessorWatchdog(timeout, func() {
+		err := fmt.Errorf("%w after %v", ErrProcessorTimeout, timeout)
+		log.Errorf("Processor for task %s: %v", taskID, err)
+		cancel(err)
+		m.recordEvent(taskID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleError, "", err.Error()))
+		errMsg := &protocol.Message{
+			Role:  protocol.MessageRoleAgent,
+			Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
+		}
+		if failErr := handle.fail(errMsg); failErr != nil {
+			log.Errorf("Failed to update task %s status to failed: %v", taskID, failErr)
+		}
+	})
+}
+
+// runProcessor runs the processor, returning early with an ErrProcessorTimeout error
+// if the watchdog fires first, even if the processor ignores its context.
+func (m *MemoryTaskManager) runProcessor(
+	ctx context.Context,
+	taskID string,
+	message protocol.Message,
+	handle *memoryTaskHandle,
+	watchdog *ProcessorWatchdog,
+) error {
+	if watchdog == nil {
+		return m.Processor.Process(ctx, taskID, message, handle)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Processor.Process(ctx, taskID, message, handle)
+	}()
+	select {
+	case err := <-done:
+		if cause := context.Cause(ctx); err != nil && errors.Is(cause, ErrProcessorTimeout) {
+			return cause
+		}
+		return err
+	case <-watchdog.Fired():
+		return context.Cause(ctx)
+	}
+}
+
 // OnSendTask handles the creation or retrieval of a task and initiates synchronous processing.
 // It implements the TaskManager interface.
 func (m *MemoryTaskManager) OnSendTask(ctx context.Context, params protocol.SendTaskParams) (*protocol.Task, error) {
-	_ = m.upsertTask(params)                  // Get or create task entry. Ignore return.
-	m.storeMessage(params.ID, params.Message) // Store the initial user message.
+	if err := CheckDependencies(ctx, params.ID, params.DependsOn, m.lookupTask); err != nil {
+		return nil, err
+	}
+	_ = m.upsertTask(ctx, params)  // Get or create task entry. Ignore return.
+	m.storeInitialMessages(params) // Store the seed messages and the initial user message.
+	m.recordEvent(params.ID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleReceived, "", ""))
 
 	// Create a cancellable context for this specific task processing
 	taskCtx, cancel := context.WithCancel(ctx)
 	defer cancel() // Ensure context is cancelled eventually
 
-	// Process the task
-	err := m.processTaskWithProcessor(taskCtx, params.ID, params.Message)
+	// Hold the task until its dependencies completed, then process it
+	err := WaitForDependencies(taskCtx, params.DependsOn, m.lookupTask, m)
+	var depErr *DependencyError
+	if err != nil {
+		m.failPending(params.ID, err)
+		if errors.As(err, &depErr) {
+			err = nil // The failed dependency is reported by the task's status.
+		}
+	} else {
+		err = m.processTaskWithProcessor(taskCtx, params.ID, params.Message)
+	}
 
 	// Return the latest task state after processing
 	finalTask, e := m.getTaskInternal(params.ID)
@@ -169,26 +337,39 @@ func (m *MemoryTaskManager) OnSendTaskSubscribe(
 	ctx context.Context,
 	params protocol.SendTaskParams,
 ) (<-chan protocol.TaskEvent, error) {
+	if err := CheckDependencies(ctx, params.ID, params.DependsOn, m.lookupTask); err != nil {
+		return nil, err
+	}
 	// Create a new task or update an existing one
-	task := m.upsertTask(params)
-	// Store the message that came with the request
-	m.storeMessage(params.ID, params.Message)
+	task := m.upsertTask(ctx, params)
+	// Store the seed messages and the message that came with the request
+	m.storeInitialMessages(params)
+	m.recordEvent(params.ID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleReceived, "", ""))
 
 	// Create event channel for this specific subscriber
 	eventChan := make(chan protocol.TaskEvent, 10) // Buffered to prevent blocking sends
 	m.addSubscriber(params.ID, eventChan)
 
 	// Create a cancellable context for the processor
-	processorCtx, cancel := context.WithCancel(ctx)
+	processorCtx, cancel := context.WithCancelCause(ctx)
 
 	// Store the cancel function
 	m.ContextsMutex.Lock()
-	m.Contexts[params.ID] = cancel
+	m.Contexts[params.ID] = func() { cancel(nil) }
+	m.cancelCauses[params.ID] = cancel
 	m.ContextsMutex.Unlock()
 
-	// Set initial state if new (submitted -> working)
+	if len(params.DependsOn) > 0 {
+		// Report the pending task, and start it once its dependencies completed.
+		m.notifySubscribers(params.ID, protocol.TaskStatusUpdateEvent{ID: params.ID, Status: task.Status})
+		go m.startAfterDependencies(processorCtx, cancel, params)
+		return eventChan, nil
+	}
+
+	// Set initial state if new (submitted -> working), or if the task is worked
+	// on again after reaching a final state.
 	// This will generate the first event for subscribers
-	if task.Status.State == protocol.TaskStateSubmitted {
+	if task.Status.State == protocol.TaskStateSubmitted || task.Status.State.IsFinal() {
 		if err := m.UpdateTaskStatus(params.ID, protocol.TaskStateWorking, nil); err != nil {
 			m.removeSubscriber(params.ID, eventChan)
 			close(eventChan)
@@ -197,18 +378,199 @@ func (m *MemoryTaskManager) OnSendTaskSubscribe(
 	}
 
 	// Start the processor in a goroutine
-	m.startTaskSubscribe(processorCtx, params.ID, params.Message)
+	m.startTaskSubscribe(processorCtx, cancel, params.ID, params.Message)
 
 	// Return the channel for events
 	return eventChan, nil
 }
 
+// startAfterDependencies waits for the dependencies of a streaming task and then
+// processes it, or fails it if a dependency did not complete.
+func (m *MemoryTaskManager) startAfterDependencies(
+	ctx context.Context,
+	cancel context.CancelCauseFunc,
+	params protocol.SendTaskParams,
+) {
+	err := WaitForDependencies(ctx, params.DependsOn, m.lookupTask, m)
+	if err == nil {
+		err = m.UpdateTaskStatus(params.ID, protocol.TaskStateWorking, nil)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			m.failPending(params.ID, err)
+		}
+		m.ContextsMutex.Lock()
+		delete(m.Contexts, params.ID)
+		delete(m.cancelCauses, params.ID)
+		m.ContextsMutex.Unlock()
+		cancel(nil)
+		return
+	}
+	m.startTaskSubscribe(ctx, cancel, params.ID, params.Message)
+}
+
+// failPending fails a task that stopped waiting for its dependencies because of
+// err, unless it already reached a final state, e.g. by being canceled.
+func (m *MemoryTaskManager) failPending(taskID string, err error) {
+	task, getErr := m.getTaskInternal(taskID)
+	if getErr != nil || task.Status.State.IsFinal() {
+		return
+	}
+	log.Infof("Failing task %s waiting for dependencies: %v", taskID, err)
+	m.recordEvent(taskID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleError, "", err.Error()))
+	errMsg := &protocol.Message{
+		Role:  protocol.MessageRoleAgent,
+		Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
+	}
+	if updateErr := m.UpdateTaskStatus(taskID, protocol.TaskStateFailed, errMsg); updateErr != nil {
+		log.Errorf("Failed to update task %s status to failed: %v", taskID, updateErr)
+	}
+}
+
+// lookupTask is the TaskLookup of the manager's tasks.
+func (m *MemoryTaskManager) lookupTask(_ context.Context, taskID string) (*protocol.Task, error) {
+	return m.getTaskInternal(taskID)
+}
+
 // getTaskInternal retrieves the task without locking (caller must handle locks).
 // Returns nil if not found.
 func (m *MemoryTaskManager) getTaskInternal(taskID string) (*protocol.Task, error) {
 	return m.getTaskWithValidation(taskID)
 }
 
+// ListTasksByLabels returns copies of all tasks whose labels match every key/value
+// pair in the selector. An empty selector returns all tasks.
+func (m *MemoryTaskManager) ListTasksByLabels(selector map[string]string) []protocol.Task {
+	m.TasksMutex.RLock()
+	defer m.TasksMutex.RUnlock()
+	var candidates map[string]struct{}
+	// Start from the smallest indexed set to keep lookups cheap.
+	for k, v := range selector {
+		ids := m.LabelIndex[labelIndexKey(k, v)]
+		if len(ids) == 0 {
+			return nil
+		}
+		if candidates == nil || len(ids) < len(candidates) {
+			candidates = ids
+		}
+	}
+	var tasks []protocol.Task
+	if candidates == nil {
+		for _, task := range m.Tasks {
+			tasks = append(tasks, copyTask(task))
+		}
+		return tasks
+	}
+	for id := range candidates {
+		if task, ok := m.Tasks[id]; ok && task.MatchesLabels(selector) {
+			tasks = append(tasks, copyTask(task))
+		}
+	}
+	return tasks
+}
+
+// PruneTasks implements TaskPruner. The eviction hook runs without any locks held,
+// and a task updated while the hook runs is kept.
+func (m *MemoryTaskManager) PruneTasks(
+	ctx context.Context, before time.Time, onEvict func(protocol.Task),
+) (int, error) {
+	m.TasksMutex.RLock()
+	var expired []protocol.Task
+	for _, task := range m.Tasks {
+		if task.FinishedBefore(before) {
+			expired = append(expired, copyTask(task))
+		}
+	}
+	m.TasksMutex.RUnlock()
+	pruned := 0
+	for _, task := range expired {
+		if err := ctx.Err(); err != nil {
+			return pruned, err
+		}
+		if onEvict != nil {
+			onEvict(task)
+		}
+		if m.deleteTask(task.ID, task.Status) {
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// deleteTask removes the task and its associated state, unless its status has
+// changed from the given one.
+func (m *MemoryTaskManager) deleteTask(taskID string, status protocol.TaskStatus) bool {
+	m.TasksMutex.Lock()
+	task, exists := m.Tasks[taskID]
+	if !exists || task.Status.State != status.State || task.Status.Timestamp != status.Timestamp {
+		m.TasksMutex.Unlock()
+		return false
+	}
+	delete(m.Tasks, taskID)
+	m.unindexLabels(task)
+	m.TasksMutex.Unlock()
+	m.MessagesMutex.Lock()
+	delete(m.Messages, taskID)
+	m.MessagesMutex.Unlock()
+	m.PushNotificationsMutex.Lock()
+	delete(m.PushNotifications, taskID)
+	m.PushNotificationsMutex.Unlock()
+	m.EventsMutex.Lock()
+	delete(m.Events, taskID)
+	m.EventsMutex.Unlock()
+	return true
+}
+
+// ExportTask implements TaskSnapshotter.
+func (m *MemoryTaskManager) ExportTask(ctx context.Context, taskID string) (*protocol.TaskSnapshot, error) {
+	task, err := m.getTaskWithValidation(taskID)
+	if err != nil {
+		return nil, err
+	}
+	m.MessagesMutex.RLock()
+	task.History = append([]protocol.Message(nil), m.Messages[taskID]...)
+	m.MessagesMutex.RUnlock()
+	m.EventsMutex.RLock()
+	task.Events = append([]protocol.TaskLifecycleEvent(nil), m.Events[taskID]...)
+	m.EventsMutex.RUnlock()
+	return protocol.NewTaskSnapshot(*task), nil
+}
+
+// RestoreTask implements TaskSnapshotter.
+func (m *MemoryTaskManager) RestoreTask(ctx context.Context, task protocol.Task, replace bool) error {
+	restored := copyTask(&task)
+	restored.History, restored.Events = nil, nil
+	labels := restored.Labels
+	restored.Labels = nil
+	m.TasksMutex.Lock()
+	existing, exists := m.Tasks[task.ID]
+	if exists && !replace {
+		m.TasksMutex.Unlock()
+		return ErrTaskExists(task.ID)
+	}
+	if exists {
+		m.unindexLabels(existing)
+		m.wakeStatusWaiters(task.ID)
+	}
+	m.Tasks[task.ID] = &restored
+	m.indexLabels(&restored, labels)
+	m.TasksMutex.Unlock()
+	m.MessagesMutex.Lock()
+	m.Messages[task.ID] = append([]protocol.Message(nil), task.History...)
+	m.MessagesMutex.Unlock()
+	m.PushNotificationsMutex.Lock()
+	delete(m.PushNotifications, task.ID)
+	m.PushNotificationsMutex.Unlock()
+	m.EventsMutex.Lock()
+	if m.Events == nil {
+		m.Events = make(map[string][]protocol.TaskLifecycleEvent)
+	}
+	m.Events[task.ID] = append([]protocol.TaskLifecycleEvent(nil), task.Events...)
+	m.EventsMutex.Unlock()
+	log.Infof("Restored task %s from snapshot", task.ID)
+	return nil
+}
+
 // OnGetTask retrieves the current state of a task, including optional message history.
 // It implements the TaskManager interface.
 func (m *MemoryTaskManager) OnGetTask(ctx context.Context, params protocol.TaskQueryParams) (*protocol.Task, error) {
@@ -216,6 +578,11 @@ func (m *MemoryTaskManager) OnGetTask(ctx context.Context, params protocol.TaskQ
 	if err != nil {
 		return nil, err // Already an ErrTaskNotFound or similar.
 	}
+	if params.IncludeEvents {
+		m.EventsMutex.RLock()
+		task.Events = append([]protocol.TaskLifecycleEvent(nil), m.Events[params.ID]...)
+		m.EventsMutex.RUnlock()
+	}
 	// Add message history if requested.
 	if params.HistoryLength != nil {
 		// historyLength == 0 means "get all history"
@@ -248,26 +615,26 @@ func (m *MemoryTaskManager) OnGetTask(ctx context.Context, params protocol.TaskQ
 // OnCancelTask attempts to cancel an ongoing task.
 // It implements the TaskManager interface.
 func (m *MemoryTaskManager) OnCancelTask(ctx context.Context, params protocol.TaskIDParams) (*protocol.Task, error) {
-	m.TasksMutex.Lock()
-	task, exists := m.Tasks[params.ID]
-	if !exists {
-		m.TasksMutex.Unlock()
-		return nil, ErrTaskNotFound(params.ID)
+	task, err := m.getTaskWithValidation(params.ID)
+	if err != nil {
+		return nil, err
 	}
-	m.TasksMutex.Unlock() // Release lock before potential context cancel / status update.
-	// Check if task is already in a final state (read lock again).
-	m.TasksMutex.RLock()
-	alreadyFinal := isFinalState(task.Status.State)
-	m.TasksMutex.RUnlock()
-	if alreadyFinal {
+	// Check if task is already in a final state.
+	if task.Status.State.IsFinal() {
 		return task, ErrTaskFinalState(params.ID, task.Status.State)
 	}
-	// Find and call the context cancel func stored for this taskID.
+	reason := params.CancelReason()
+	// Find and call the context cancel func stored for this taskID. The handle of
+	// the processor rejects its updates from then on, so subscribers get the
+	// canceled status as the final event rather than whatever the processor
+	// reports as it stops.
 	var cancelFound bool
 	m.ContextsMutex.Lock()
-	cancel, exists := m.Contexts[params.ID]
-	if exists {
-		cancel() // Call the cancel function.
+	if cancel, exists := m.cancelCauses[params.ID]; exists {
+		cancel(&CancelError{Reason: reason}) // Call the cancel function.
+		cancelFound = true
+	} else if cancel, exists := m.Contexts[params.ID]; exists {
+		cancel() // Registered directly in Contexts, so no reason can be attached.
 		cancelFound = true
 		// Don't delete the context here - let the processor goroutine clean up.
 	}
@@ -275,15 +642,13 @@ func (m *MemoryTaskManager) OnCancelTask(ctx context.Context, params protocol.Ta
 	if !cancelFound {
 		log.Warnf("Warning: No cancellation function found for task %s", params.ID)
 	}
-	// Create a cancellation message.
-	cancelMsg := &protocol.Message{
-		Role: protocol.MessageRoleAgent,
-		Parts: []protocol.Part{
-			protocol.NewTextPart(fmt.Sprintf("Task %s was canceled by user request", params.ID)),
-		},
-	}
-	// Update state to Cancelled.
-	if err := m.UpdateTaskStatus(params.ID, protocol.TaskStateCanceled, cancelMsg); err != nil {
+	// Update state to Cancelled, recording the reason. This fails if the task
+	// reached a final state in the meantime.
+	if err := m.updateTaskStatus(params.ID, protocol.TaskStatus{
+		State:        protocol.TaskStateCanceled,
+		Message:      NewCancelMessage(params.ID, reason),
+		CancelReason: &reason,
+	}); err != nil {
 		log.Errorf("Error updating status to Cancelled for task %s: %v", params.ID, err)
 		return nil, err
 	}
@@ -295,10 +660,57 @@ func (m *MemoryTaskManager) OnCancelTask(ctx context.Context, params protocol.Ta
 	return updatedTask, nil
 }
 
+// WaitForTaskChange implements TaskWaiter.
+func (m *MemoryTaskManager) WaitForTaskChange(ctx context.Context, taskID string) error {
+	m.TasksMutex.Lock()
+	task, exists := m.Tasks[taskID]
+	if !exists {
+		m.TasksMutex.Unlock()
+		return ErrTaskNotFound(taskID)
+	}
+	if task.Status.State.IsFinal() {
+		m.TasksMutex.Unlock()
+		return nil
+	}
+	if m.statusWaiters == nil {
+		m.statusWaiters = make(map[string]chan struct{})
+	}
+	changed, ok := m.statusWaiters[taskID]
+	if !ok {
+		changed = make(chan struct{})
+		m.statusWaiters[taskID] = changed
+	}
+	m.TasksMutex.Unlock()
+	select {
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wakeStatusWaiters releases the WaitForTaskChange calls waiting on the task.
+// The caller must hold TasksMutex.
+func (m *MemoryTaskManager) wakeStatusWaiters(taskID string) {
+	if changed, ok := m.statusWaiters[taskID]; ok {
+		close(changed)
+		delete(m.statusWaiters, taskID)
+	}
+}
+
 // UpdateTaskStatus updates the task's state and notifies any subscribers.
-// Returns an error if the task does not exist.
+// Returns an error if the task does not exist, or if both its state and the new
+// one are final.
 // Exported method (used by memoryTaskHandle).
 func (m *MemoryTaskManager) UpdateTaskStatus(taskID string, state protocol.TaskState, message *protocol.Message) error {
+	return m.updateTaskStatus(taskID, protocol.TaskStatus{State: state, Message: message})
+}
+
+// updateTaskStatus replaces the task's status, stamping it with the current time.
+// A final status cannot be replaced by another final status, so a processor
+// reporting its result late cannot overwrite a canceled task; a task in a final
+// state can still be worked on again.
+func (m *MemoryTaskManager) updateTaskStatus(taskID string, status protocol.TaskStatus) error {
 	m.TasksMutex.Lock()
 	task, exists := m.Tasks[taskID]
 	if !exists {
@@ -306,29 +718,80 @@ func (m *MemoryTaskManager) UpdateTaskStatus(taskID string, state protocol.TaskS
 		log.Warnf("Warning: UpdateTaskStatus called for non-existent task %s", taskID)
 		return ErrTaskNotFound(taskID)
 	}
-	// Update status fields.
-	task.Status = protocol.TaskStatus{
-		State:     state,
-		Message:   message,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	if state := task.Status.State; state.IsFinal() && status.State.IsFinal() {
+		m.TasksMutex.Unlock()
+		return ErrTaskFinalState(taskID, state)
 	}
+	// Update status fields.
+	status.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	keepEstimatedCompletion(&status, task.Status)
+	task.Status = status
+	m.wakeStatusWaiters(taskID)
 	// Create a copy for notification before unlocking.
 	taskCopy := *task
 	m.TasksMutex.Unlock() // Unlock before potentially blocking on channel send.
 	// Store the message in history if provided
-	if message != nil {
+	if status.Message != nil {
 		// Convert TaskStatus Message (which is a pointer) to a Message value for history
-		m.storeMessage(taskID, *message)
+		m.storeMessage(taskID, *status.Message)
 	}
+	m.recordStatusEvent(taskID, status)
 	// Notify subscribers outside the lock.
 	m.notifySubscribers(taskID, protocol.TaskStatusUpdateEvent{
 		ID:     taskID,
 		Status: taskCopy.Status,
-		Final:  isFinalState(state),
+		Final:  status.State.IsFinal(),
 	})
 	return nil
 }
 
+// SetEstimatedCompletion sets the estimated completion time on the working task's
+// status and notifies subscribers with the updated status.
+// Returns an error if the task does not exist or is not working.
+func (m *MemoryTaskManager) SetEstimatedCompletion(taskID string, eta time.Time) error {
+	m.TasksMutex.Lock()
+	task, exists := m.Tasks[taskID]
+	if !exists {
+		m.TasksMutex.Unlock()
+		log.Warnf("Warning: SetEstimatedCompletion called for non-existent task %s", taskID)
+		return ErrTaskNotFound(taskID)
+	}
+	if err := checkEstimable(taskID, task.Status.State); err != nil {
+		m.TasksMutex.Unlock()
+		return err
+	}
+	task.Status.EstimatedCompletion = eta.UTC().Format(time.RFC3339)
+	task.Status.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	m.wakeStatusWaiters(taskID)
+	status := task.Status
+	m.TasksMutex.Unlock()
+	m.notifySubscribers(taskID, protocol.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: status,
+	})
+	return nil
+}
+
+// checkEstimable rejects estimated completion times for tasks that are not working.
+func checkEstimable(taskID string, state protocol.TaskState) error {
+	if state.IsFinal() {
+		return ErrTaskFinalState(taskID, state)
+	}
+	if state != protocol.TaskStateWorking {
+		return fmt.Errorf("task %s is %s: estimated completion can only be set while working", taskID, state)
+	}
+	return nil
+}
+
+// keepEstimatedCompletion carries the previous estimate over to a status that
+// keeps the task working without setting one of its own.
+func keepEstimatedCompletion(status *protocol.TaskStatus, previous protocol.TaskStatus) {
+	if status.State == protocol.TaskStateWorking && previous.State == protocol.TaskStateWorking &&
+		status.EstimatedCompletion == "" {
+		status.EstimatedCompletion = previous.EstimatedCompletion
+	}
+}
+
 // AddArtifact adds an artifact to the task and notifies subscribers.
 // Returns an error if the task does not exist.
 // Exported method (used by memoryTaskHandle).
@@ -340,6 +803,11 @@ func (m *MemoryTaskManager) AddArtifact(taskID string, artifact protocol.Artifac
 		log.Warnf("Warning: AddArtifact called for non-existent task %s", taskID)
 		return ErrTaskNotFound(taskID)
 	}
+	if err := CheckArtifactLimit(task, []protocol.Artifact{artifact}, int(m.maxArtifacts.Load())); err != nil {
+		m.TasksMutex.Unlock()
+		return err
+	}
+	checksum.EnsureArtifact(&artifact)
 	// Append the artifact.
 	if task.Artifacts == nil {
 		task.Artifacts = make([]protocol.Artifact, 0, 1)
@@ -357,21 +825,168 @@ func (m *MemoryTaskManager) AddArtifact(taskID string, artifact protocol.Artifac
 	return nil
 }
 
+// PatchArtifact applies patch to a DataPart of the task's artifact with the given
+// index and notifies subscribers with the patch itself.
+// Returns an error if the task does not exist or the patch does not apply.
+func (m *MemoryTaskManager) PatchArtifact(taskID string, index, part int, patch []protocol.PatchOperation) error {
+	m.TasksMutex.Lock()
+	task, exists := m.Tasks[taskID]
+	if !exists {
+		m.TasksMutex.Unlock()
+		log.Warnf("Warning: PatchArtifact called for non-existent task %s", taskID)
+		return ErrTaskNotFound(taskID)
+	}
+	artifacts, err := protocol.PatchArtifacts(task.Artifacts, index, part, patch)
+	if err != nil {
+		m.TasksMutex.Unlock()
+		return err
+	}
+	task.Artifacts = artifacts
+	m.TasksMutex.Unlock()
+	m.notifySubscribers(taskID, protocol.TaskArtifactPatchEvent{
+		ID:    taskID,
+		Index: index,
+		Part:  part,
+		Patch: patch,
+	})
+	return nil
+}
+
+// ReplaceArtifact replaces the task's artifact with artifact.Index by artifact and
+// notifies subscribers with it. Returns an error if the task does not exist.
+func (m *MemoryTaskManager) ReplaceArtifact(taskID string, artifact protocol.Artifact) error {
+	m.TasksMutex.Lock()
+	task, exists := m.Tasks[taskID]
+	if !exists {
+		m.TasksMutex.Unlock()
+		log.Warnf("Warning: ReplaceArtifact called for non-existent task %s", taskID)
+		return ErrTaskNotFound(taskID)
+	}
+	if err := CheckArtifactLimit(task, []protocol.Artifact{artifact}, int(m.maxArtifacts.Load())); err != nil {
+		m.TasksMutex.Unlock()
+		return err
+	}
+	checksum.EnsureArtifact(&artifact)
+	task.Artifacts = protocol.ReplaceArtifacts(task.Artifacts, artifact)
+	artifact = task.Artifacts[len(task.Artifacts)-1]
+	m.TasksMutex.Unlock()
+	m.notifySubscribers(taskID, protocol.TaskArtifactUpdateEvent{
+		ID:       taskID,
+		Artifact: artifact,
+		Final:    artifact.LastChunk != nil && *artifact.LastChunk,
+	})
+	return nil
+}
+
+// DeleteArtifact removes the task's artifact with the given index and notifies
+// subscribers. Returns an error if the task or the artifact does not exist.
+func (m *MemoryTaskManager) DeleteArtifact(taskID string, index int) error {
+	m.TasksMutex.Lock()
+	task, exists := m.Tasks[taskID]
+	if !exists {
+		m.TasksMutex.Unlock()
+		log.Warnf("Warning: DeleteArtifact called for non-existent task %s", taskID)
+		return ErrTaskNotFound(taskID)
+	}
+	artifacts, err := protocol.DeleteArtifacts(task.Artifacts, index)
+	if err != nil {
+		m.TasksMutex.Unlock()
+		return err
+	}
+	task.Artifacts = artifacts
+	m.TasksMutex.Unlock()
+	m.notifySubscribers(taskID, protocol.TaskArtifactDeleteEvent{ID: taskID, Index: index})
+	return nil
+}
+
+// CompleteTask sets the task's status to completed with message as its result and
+// appends artifacts, all under one lock so readers see the result and its output
+// together. Subscribers get the artifact events before the final status event.
+// Returns an error if the task is already in a final state.
+func (m *MemoryTaskManager) CompleteTask(taskID string, message protocol.Message, artifacts ...protocol.Artifact) error {
+	m.TasksMutex.Lock()
+	task, exists := m.Tasks[taskID]
+	if !exists {
+		m.TasksMutex.Unlock()
+		log.Warnf("Warning: CompleteTask called for non-existent task %s", taskID)
+		return ErrTaskNotFound(taskID)
+	}
+	if state := task.Status.State; state.IsFinal() {
+		m.TasksMutex.Unlock()
+		return ErrTaskFinalState(taskID, state)
+	}
+	if err := CheckArtifactLimit(task, artifacts, int(m.maxArtifacts.Load())); err != nil {
+		m.TasksMutex.Unlock()
+		return err
+	}
+	for i := range artifacts {
+		checksum.EnsureArtifact(&artifacts[i])
+	}
+	task.Artifacts = append(task.Artifacts, artifacts...)
+	task.Status = protocol.TaskStatus{
+		State:     protocol.TaskStateCompleted,
+		Message:   &message,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	m.wakeStatusWaiters(taskID)
+	status := task.Status
+	m.TasksMutex.Unlock()
+	m.storeMessage(taskID, message)
+	m.recordStatusEvent(taskID, status)
+	for _, artifact := range artifacts {
+		m.notifySubscribers(taskID, protocol.TaskArtifactUpdateEvent{
+			ID:       taskID,
+			Artifact: artifact,
+			Final:    artifact.LastChunk != nil && *artifact.LastChunk,
+		})
+	}
+	m.notifySubscribers(taskID, protocol.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: status,
+		Final:  true,
+	})
+	return nil
+}
+
+// SendMessage records an intermediate agent message in the task's history and
+// pushes it to subscribers without changing the task's status.
+func (m *MemoryTaskManager) SendMessage(taskID string, message protocol.Message) error {
+	m.TasksMutex.RLock()
+	_, exists := m.Tasks[taskID]
+	m.TasksMutex.RUnlock()
+	if !exists {
+		log.Warnf("Warning: SendMessage called for non-existent task %s", taskID)
+		return ErrTaskNotFound(taskID)
+	}
+	m.storeMessage(taskID, message)
+	m.notifySubscribers(taskID, protocol.TaskMessageEvent{
+		ID:      taskID,
+		Message: message,
+	})
+	return nil
+}
+
 // --- Internal Helper Methods (Unexported) ---
 
-// upsertTask creates a new task or updates metadata if it already exists.
-// Assumes locks are handled by the caller if needed, but acquires its own lock.
-func (m *MemoryTaskManager) upsertTask(params protocol.SendTaskParams) *protocol.Task {
+// upsertTask creates a new task or updates metadata if it already exists. A new
+// task records the request ID in ctx. Acquires its own lock.
+func (m *MemoryTaskManager) upsertTask(ctx context.Context, params protocol.SendTaskParams) *protocol.Task {
 	m.TasksMutex.Lock()
 	defer m.TasksMutex.Unlock()
 	task, exists := m.Tasks[params.ID]
 	if !exists {
 		task = protocol.NewTask(params.ID, params.SessionID)
+		task.RequestID = RequestIDFromContext(ctx)
 		m.Tasks[params.ID] = task
-		log.Infof("Created new task %s (Session: %v)", params.ID, params.SessionID)
+		m.indexLabels(task, params.Labels)
+		log.Infof("Created new task %s (Session: %v, Request: %s)", params.ID, params.SessionID, task.RequestID)
+		m.bus.Load().Publish(BusEvent{Type: BusEventTaskCreated, TaskID: params.ID})
 	} else {
 		log.Debugf("Updating existing task %s", params.ID)
 	}
+	if len(params.DependsOn) > 0 {
+		task.DependsOn = append([]string(nil), params.DependsOn...)
+	}
 	// Update metadata if provided.
 	if params.Metadata != nil {
 		if task.Metadata == nil {
@@ -384,18 +999,106 @@ func (m *MemoryTaskManager) upsertTask(params protocol.SendTaskParams) *protocol
 	return task
 }
 
+// indexLabels sets the labels on a newly created task and records them in the label index.
+// Assumes the caller holds the TasksMutex write lock.
+func (m *MemoryTaskManager) indexLabels(task *protocol.Task, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	task.Labels = make(map[string]string, len(labels))
+	for k, v := range labels {
+		task.Labels[k] = v
+		key := labelIndexKey(k, v)
+		if m.LabelIndex[key] == nil {
+			m.LabelIndex[key] = make(map[string]struct{})
+		}
+		m.LabelIndex[key][task.ID] = struct{}{}
+	}
+}
+
+// unindexLabels removes the task from the label index.
+// Assumes the caller holds the TasksMutex write lock.
+func (m *MemoryTaskManager) unindexLabels(task *protocol.Task) {
+	for k, v := range task.Labels {
+		key := labelIndexKey(k, v)
+		delete(m.LabelIndex[key], task.ID)
+		if len(m.LabelIndex[key]) == 0 {
+			delete(m.LabelIndex, key)
+		}
+	}
+}
+
+// labelIndexKey returns the label index key for a key/value pair.
+func labelIndexKey(key, value string) string {
+	return key + "=" + value
+}
+
+// copyTask returns a copy of the task that shares no maps or slices with it,
+// so callers can use it without holding TasksMutex.
+func copyTask(task *protocol.Task) protocol.Task {
+	taskCopy := *task
+	if task.Status.Message != nil {
+		message := *task.Status.Message
+		taskCopy.Status.Message = &message
+	}
+	if task.Artifacts != nil {
+		taskCopy.Artifacts = append([]protocol.Artifact(nil), task.Artifacts...)
+	}
+	if task.History != nil {
+		taskCopy.History = append([]protocol.Message(nil), task.History...)
+	}
+	if task.Metadata != nil {
+		taskCopy.Metadata = make(map[string]interface{}, len(task.Metadata))
+		for k, v := range task.Metadata {
+			taskCopy.Metadata[k] = v
+		}
+	}
+	if task.Labels != nil {
+		taskCopy.Labels = make(map[string]string, len(task.Labels))
+		for k, v := range task.Labels {
+			taskCopy.Labels[k] = v
+		}
+	}
+	if task.DependsOn != nil {
+		taskCopy.DependsOn = append([]string(nil), task.DependsOn...)
+	}
+	return taskCopy
+}
+
+// storeInitialMessages adds the seed messages and the request message to the task's history, in order.
+func (m *MemoryTaskManager) storeInitialMessages(params protocol.SendTaskParams) {
+	for _, message := range params.InitialMessages() {
+		m.storeMessage(params.ID, message)
+	}
+}
+
+// SetMaxHistoryBytes implements HistoryLimiter.
+func (m *MemoryTaskManager) SetMaxHistoryBytes(n int) {
+	m.MessagesMutex.Lock()
+	defer m.MessagesMutex.Unlock()
+	m.maxHistoryBytes = max(n, 0)
+}
+
 // storeMessage adds a message to the task's history.
 // Assumes locks are handled by the caller if needed, but acquires its own lock.
 func (m *MemoryTaskManager) storeMessage(taskID string, message protocol.Message) {
 	m.MessagesMutex.Lock()
 	defer m.MessagesMutex.Unlock()
+	if m.maxHistoryBytes > 0 {
+		if size := messageSize(message); size > m.maxHistoryBytes {
+			log.Warnf("Warning: message of %d bytes for task %s exceeds the %d byte history cap; not stored",
+				size, taskID, m.maxHistoryBytes)
+			return
+		}
+	}
 	if _, exists := m.Messages[taskID]; !exists {
 		m.Messages[taskID] = make([]protocol.Message, 0, 1) // Initialize with capacity.
 	}
 	// Create a copy of the message to store, ensuring history isolation.
 	messageCopy := protocol.Message{
-		Role:     message.Role,
-		Metadata: message.Metadata, // Shallow copy of map is usually fine.
+		Role:      message.Role,
+		Metadata:  message.Metadata, // Shallow copy of map is usually fine.
+		Signature: message.Signature,
 	}
 	if message.Parts != nil {
 		// Copy the slice of parts (shallow copy of interface values is correct).
@@ -403,6 +1106,55 @@ func (m *MemoryTaskManager) storeMessage(taskID string, message protocol.Message
 		copy(messageCopy.Parts, message.Parts)
 	}
 	m.Messages[taskID] = append(m.Messages[taskID], messageCopy)
+	if m.maxHistoryBytes > 0 {
+		m.Messages[taskID] = trimHistory(m.Messages[taskID], m.maxHistoryBytes)
+	}
+}
+
+// trimHistory drops the oldest messages of history until its encoded size is at
+// most maxBytes.
+func trimHistory(history []protocol.Message, maxBytes int) []protocol.Message {
+	total := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		total += messageSize(history[i])
+		if total > maxBytes {
+			return append([]protocol.Message(nil), history[i+1:]...)
+		}
+	}
+	return history
+}
+
+// messageSize returns the JSON-encoded size of message in bytes.
+func messageSize(message protocol.Message) int {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// recordEvent appends event to the task's lifecycle event log.
+func (m *MemoryTaskManager) recordEvent(taskID string, event protocol.TaskLifecycleEvent) {
+	m.EventsMutex.Lock()
+	defer m.EventsMutex.Unlock()
+	if m.Events == nil {
+		m.Events = make(map[string][]protocol.TaskLifecycleEvent)
+	}
+	events := append(m.Events[taskID], event)
+	if len(events) > MaxLifecycleEvents {
+		events = append([]protocol.TaskLifecycleEvent(nil), events[len(events)-MaxLifecycleEvents:]...)
+	}
+	m.Events[taskID] = events
+}
+
+// recordStatusEvent records a status change in the task's lifecycle event log,
+// noting the reason of a cancellation.
+func (m *MemoryTaskManager) recordStatusEvent(taskID string, status protocol.TaskStatus) {
+	var detail string
+	if status.CancelReason != nil {
+		detail = string(*status.CancelReason)
+	}
+	m.recordEvent(taskID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleStatusChanged, status.State, detail))
 }
 
 // addSubscriber adds a channel to the list of subscribers for a task.
@@ -441,6 +1193,11 @@ func (m *MemoryTaskManager) removeSubscriber(taskID string, ch chan<- protocol.T
 
 // notifySubscribers sends an event to all current subscribers of a task.
 func (m *MemoryTaskManager) notifySubscribers(taskID string, event protocol.TaskEvent) {
+	if watchdog, ok := m.watchdogs.Load(taskID); ok {
+		watchdog.(*ProcessorWatchdog).Reset()
+	}
+	m.enqueuePush(taskID, event)
+	m.bus.Load().publishTaskEvent(taskID, event)
 	m.SubMutex.RLock()
 	subs, exists := m.Subscribers[taskID]
 	if !exists || len(subs) == 0 {
@@ -453,20 +1210,36 @@ func (m *MemoryTaskManager) notifySubscribers(taskID string, event protocol.Task
 	m.SubMutex.RUnlock()
 	log.Debugf("Notifying %d subscribers for task %s (Event Type: %T, Final: %t)",
 		len(subsCopy), taskID, event, event.IsFinal())
-	// Send events outside the lock.
+	// Send events outside the lock. Without a send timeout the sends don't block,
+	// so one slow subscriber cannot delay the others or the processor.
+	timeout := time.Duration(m.sendTimeout.Load())
 	for _, ch := range subsCopy {
-		// Use a select with a default case for a non-blocking send.
-		// This prevents one slow/blocked subscriber from delaying others.
-		select {
-		case ch <- event:
-			// Event sent successfully.
-		default:
-			// Channel buffer is full or channel is closed.
+		switch err := SendSubscriberEvent(ch, event, timeout); {
+		case errors.Is(err, ErrSlowSubscriber):
+			log.Warnf("Warning: Dropping task %s subscriber - no room for events for %v.", taskID, timeout)
+			m.removeSubscriber(taskID, ch)
+		case err != nil:
 			log.Warnf("Warning: Dropping event for task %s subscriber - channel full or closed.", taskID)
 		}
 	}
 }
 
+// enqueuePush queues event for the task's push notification webhook, if any.
+func (m *MemoryTaskManager) enqueuePush(taskID string, event protocol.TaskEvent) {
+	if m.PushQueue == nil {
+		return
+	}
+	m.PushNotificationsMutex.RLock()
+	config, exists := m.PushNotifications[taskID]
+	m.PushNotificationsMutex.RUnlock()
+	if !exists {
+		return
+	}
+	if err := m.PushQueue.Enqueue(context.Background(), taskID, config, event); err != nil {
+		log.Errorf("Failed to queue push notification for task %s: %v", taskID, err)
+	}
+}
+
 // OnPushNotificationSet implements TaskManager.OnPushNotificationSet.
 // It sets push notification configuration for a task.
 func (m *MemoryTaskManager) OnPushNotificationSet(
@@ -517,16 +1290,16 @@ func (m *MemoryTaskManager) OnPushNotificationGet(
 // OnResubscribe implements TaskManager.OnResubscribe.
 // It allows a client to reestablish an SSE stream for an existing task.
 func (m *MemoryTaskManager) OnResubscribe(ctx context.Context, params protocol.TaskIDParams) (<-chan protocol.TaskEvent, error) {
-	m.TasksMutex.RLock()
-	task, exists := m.Tasks[params.ID]
-	m.TasksMutex.RUnlock()
-	if !exists {
-		return nil, ErrTaskNotFound(params.ID)
+	// Work on a copy; the stored task keeps changing while events are sent.
+	task, err := m.getTaskWithValidation(params.ID)
+	if err != nil {
+		return nil, err
 	}
-	// Create a channel for events.
-	eventChan := make(chan protocol.TaskEvent)
+	// Create a channel for events, buffered like OnSendTaskSubscribe's since
+	// notifySubscribers drops events a subscriber is not ready for.
+	eventChan := make(chan protocol.TaskEvent, 10)
 	// For tasks in final state, just send a status update event and close.
-	if isFinalState(task.Status.State) {
+	if task.Status.State.IsFinal() {
 		go func() {
 			// Send a task status update event.
 			event := protocol.TaskStatusUpdateEvent{
@@ -560,7 +1333,7 @@ func (m *MemoryTaskManager) OnResubscribe(ctx context.Context, params protocol.T
 		event := protocol.TaskStatusUpdateEvent{
 			ID:     task.ID,
 			Status: task.Status,
-			Final:  isFinalState(task.Status.State),
+			Final:  task.Status.State.IsFinal(),
 		}
 		select {
 		case eventChan <- event:
@@ -594,12 +1367,12 @@ func processError(err error) error {
 // Returns task and nil if found, nil and error if not found.
 func (m *MemoryTaskManager) getTaskWithValidation(taskID string) (*protocol.Task, error) {
 	m.TasksMutex.RLock()
+	defer m.TasksMutex.RUnlock()
 	task, exists := m.Tasks[taskID]
-	m.TasksMutex.RUnlock()
-
 	if !exists {
 		return nil, ErrTaskNotFound(taskID)
 	}
-	taskCopy := *task // Return a copy.
+	// Copy under the lock, as writers update the task in place.
+	taskCopy := copyTask(task)
 	return &taskCopy, nil
 }
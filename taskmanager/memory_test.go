@@ -8,7 +8,10 @@ package taskmanager
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -18,6 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
 
@@ -625,18 +629,81 @@ func TestMemoryTaskManager_OnCancelTask(t *testing.T) {
 		"Already completed task should remain in completed state after cancel attempt")
 }
 
-// --- Test Helpers ---
+func TestMemoryTaskManager_OnCancelTaskReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		reason   protocol.CancelReason
+		expected protocol.CancelReason
+	}{
+		{"Default", "", protocol.CancelReasonUserRequested},
+		{"UserRequested", protocol.CancelReasonUserRequested, protocol.CancelReasonUserRequested},
+		{"Timeout", protocol.CancelReasonTimeout, protocol.CancelReasonTimeout},
+		{"ServerShutdown", protocol.CancelReasonServerShutdown, protocol.CancelReasonServerShutdown},
+		{"Superseded", protocol.CancelReasonSuperseded, protocol.CancelReasonSuperseded},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			started := make(chan struct{})
+			seen := make(chan protocol.CancelReason, 1)
+			processor := &mockProcessor{
+				processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+					close(started)
+					<-ctx.Done()
+					reason, _ := CancelReasonFromContext(ctx)
+					seen <- reason
+					return ctx.Err()
+				},
+			}
+			tm, err := NewMemoryTaskManager(processor)
+			require.NoError(t, err)
+
+			taskID := "cancel-reason-" + tc.name
+			eventChan, err := tm.OnSendTaskSubscribe(context.Background(), createTestTask(taskID, "wait"))
+			require.NoError(t, err)
+			<-started
+
+			task, err := tm.OnCancelTask(context.Background(), protocol.TaskIDParams{ID: taskID, Reason: tc.reason})
+			require.NoError(t, err)
+			assert.Equal(t, protocol.TaskStateCanceled, task.Status.State)
+			require.NotNil(t, task.Status.CancelReason)
+			assert.Equal(t, tc.expected, *task.Status.CancelReason)
+
+			select {
+			case reason := <-seen:
+				assert.Equal(t, tc.expected, reason)
+			case <-time.After(time.Second):
+				t.Fatal("Processor did not observe cancellation")
+			}
+
+			events := collectTaskEvents(t, eventChan, protocol.TaskStateCanceled, time.Second)
+			require.NotEmpty(t, events)
+			final, ok := events[len(events)-1].(protocol.TaskStatusUpdateEvent)
+			require.True(t, ok)
+			require.NotNil(t, final.Status.CancelReason)
+			assert.Equal(t, tc.expected, *final.Status.CancelReason)
+		})
+	}
+}
 
-func TestIsFinalState(t *testing.T) {
-	assert.True(t, isFinalState(protocol.TaskStateCompleted))
-	assert.True(t, isFinalState(protocol.TaskStateFailed))
-	assert.True(t, isFinalState(protocol.TaskStateCanceled))
-	assert.False(t, isFinalState(protocol.TaskStateWorking))
-	assert.False(t, isFinalState(protocol.TaskStateSubmitted))     // Check defined non-final state.
-	assert.False(t, isFinalState(protocol.TaskStateInputRequired)) // Check defined non-final state.
-	assert.False(t, isFinalState(protocol.TaskState("other")))
+func TestCancelReasonFromContext(t *testing.T) {
+	_, ok := CancelReasonFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, ok = CancelReasonFromContext(ctx)
+	assert.False(t, ok)
+
+	ctx, cancelCause := context.WithCancelCause(context.Background())
+	cancelCause(&CancelError{Reason: protocol.CancelReasonTimeout})
+	reason, ok := CancelReasonFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, protocol.CancelReasonTimeout, reason)
+	assert.ErrorIs(t, context.Cause(ctx), context.Canceled)
 }
 
+// --- Test Helpers ---
+
 func TestMemTaskManagerPushNotif(t *testing.T) {
 	processor := &mockProcessor{}
 	tm, err := NewMemoryTaskManager(processor)
@@ -887,3 +954,1234 @@ func TestMemoryTaskManager_OnResubscribe(t *testing.T) {
 	assert.Equal(t, protocol.TaskStateCompleted, completedStatusEvent.Status.State)
 	assert.True(t, completedStatusEvent.Final)
 }
+
+// TestMemoryTaskManager_AddArtifactChecksum tests that checksums are populated for
+// artifacts with inline file bytes and left untouched otherwise.
+func TestMemoryTaskManager_AddArtifactChecksum(t *testing.T) {
+	tm, err := NewMemoryTaskManager(&mockProcessor{})
+	require.NoError(t, err)
+	taskID := "artifact-checksum-task"
+	tm.upsertTask(context.Background(), createTestTask(taskID, "checksum"))
+
+	content := []byte("artifact file content")
+	encoded := base64.StdEncoding.EncodeToString(content)
+	uri := "https://example.com/artifact.bin"
+	preset := "preset-checksum"
+
+	require.NoError(t, tm.AddArtifact(taskID, protocol.Artifact{
+		Parts: []protocol.Part{protocol.FilePart{
+			Type: protocol.PartTypeFile,
+			File: protocol.FileContent{Bytes: &encoded},
+		}},
+	}))
+	require.NoError(t, tm.AddArtifact(taskID, protocol.Artifact{
+		Index: 1,
+		Parts: []protocol.Part{protocol.FilePart{
+			Type: protocol.PartTypeFile,
+			File: protocol.FileContent{URI: &uri},
+		}},
+		Checksum: &preset,
+	}))
+	require.NoError(t, tm.AddArtifact(taskID, protocol.Artifact{
+		Index: 2,
+		Parts: []protocol.Part{protocol.NewTextPart("no file")},
+	}))
+
+	task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: taskID})
+	require.NoError(t, err)
+	require.Len(t, task.Artifacts, 3)
+	require.NotNil(t, task.Artifacts[0].Checksum)
+	assert.Equal(t, protocol.ComputeChecksum(content), *task.Artifacts[0].Checksum)
+	require.NotNil(t, task.Artifacts[1].Checksum)
+	assert.Equal(t, preset, *task.Artifacts[1].Checksum, "processor-provided checksum should be kept")
+	assert.Nil(t, task.Artifacts[2].Checksum)
+}
+
+// TestMemoryTaskManager_ListTasksByLabels tests label assignment at creation and selector-based listing.
+func TestMemoryTaskManager_ListTasksByLabels(t *testing.T) {
+	tm, err := NewMemoryTaskManager(&mockProcessor{})
+	require.NoError(t, err)
+
+	labelled := map[string]map[string]string{
+		"task-a": {"customer": "acme", "env": "prod"},
+		"task-b": {"customer": "acme", "env": "staging"},
+		"task-c": {"customer": "globex", "env": "prod"},
+		"task-d": nil,
+	}
+	for id, labels := range labelled {
+		params := createTestTask(id, "labels")
+		params.Labels = labels
+		tm.upsertTask(context.Background(), params)
+	}
+	// Labels are only applied at creation.
+	update := createTestTask("task-a", "labels")
+	update.Labels = map[string]string{"customer": "initech"}
+	tm.upsertTask(context.Background(), update)
+
+	taskIDs := func(tasks []protocol.Task) []string {
+		ids := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	t.Run("SingleSelector", func(t *testing.T) {
+		tasks := tm.ListTasksByLabels(map[string]string{"customer": "acme"})
+		assert.ElementsMatch(t, []string{"task-a", "task-b"}, taskIDs(tasks))
+	})
+
+	t.Run("MultipleSelectors", func(t *testing.T) {
+		tasks := tm.ListTasksByLabels(map[string]string{"customer": "acme", "env": "prod"})
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "task-a", tasks[0].ID)
+		assert.Equal(t, map[string]string{"customer": "acme", "env": "prod"}, tasks[0].Labels)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		assert.Empty(t, tm.ListTasksByLabels(map[string]string{"customer": "initech"}))
+		assert.Empty(t, tm.ListTasksByLabels(map[string]string{"customer": "globex", "env": "staging"}))
+	})
+
+	t.Run("EmptySelector", func(t *testing.T) {
+		tasks := tm.ListTasksByLabels(nil)
+		assert.ElementsMatch(t, []string{"task-a", "task-b", "task-c", "task-d"}, taskIDs(tasks))
+	})
+	t.Run("ResultsAreCopies", func(t *testing.T) {
+		tasks := tm.ListTasksByLabels(map[string]string{"customer": "globex"})
+		require.Len(t, tasks, 1)
+		tasks[0].Labels["customer"] = "tampered"
+		tasks[0].Artifacts = append(tasks[0].Artifacts, protocol.Artifact{})
+
+		tasks = tm.ListTasksByLabels(map[string]string{"customer": "globex"})
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "globex", tasks[0].Labels["customer"])
+		assert.Empty(t, tasks[0].Artifacts)
+	})
+}
+
+// TestMemoryTaskManager_SeedMessages tests that seed messages are stored in history
+// before processing and are visible to the processor.
+func TestMemoryTaskManager_SeedMessages(t *testing.T) {
+	var seen []protocol.Message
+	processor := &mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			history, err := handle.GetMessageHistory()
+			if err != nil {
+				return err
+			}
+			seen = history
+			return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+		},
+	}
+	tm, err := NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+
+	params := createTestTask("seeded-task", "What is 2+2?")
+	params.Messages = []protocol.Message{
+		protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("You are a calculator.")}),
+		protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("1+1")}),
+		protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("2")}),
+	}
+
+	task, err := tm.OnSendTask(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+
+	texts := func(messages []protocol.Message) []string {
+		result := make([]string, 0, len(messages))
+		for _, msg := range messages {
+			result = append(result, msg.Parts[0].(protocol.TextPart).Text)
+		}
+		return result
+	}
+	expected := []string{"You are a calculator.", "1+1", "2", "What is 2+2?"}
+	assert.Equal(t, expected, texts(seen), "processor should see seed messages before the request message")
+	assert.Equal(t, "What is 2+2?", processor.lastMessage.Parts[0].(protocol.TextPart).Text)
+	assert.Equal(t, protocol.MessageRoleAgent, seen[2].Role)
+
+	historyLength := 10
+	task, err = tm.OnGetTask(context.Background(), protocol.TaskQueryParams{
+		ID:            "seeded-task",
+		HistoryLength: &historyLength,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, texts(task.History))
+}
+
+func TestMemoryTaskManager_SendMessage(t *testing.T) {
+	processor := &mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			progress := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("halfway")})
+			if err := handle.SendMessage(progress); err != nil {
+				return err
+			}
+			return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+		},
+	}
+	tm, err := NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+
+	eventChan, err := tm.OnSendTaskSubscribe(context.Background(), createTestTask("message-task", "go"))
+	require.NoError(t, err)
+
+	events := collectTaskEvents(t, eventChan, protocol.TaskStateCompleted, 3*time.Second)
+	var messageEvents []protocol.TaskMessageEvent
+	for _, event := range events {
+		if e, ok := event.(protocol.TaskMessageEvent); ok {
+			messageEvents = append(messageEvents, e)
+		}
+	}
+	require.Len(t, messageEvents, 1)
+	assert.Equal(t, "message-task", messageEvents[0].ID)
+	assert.Equal(t, "halfway", messageEvents[0].Message.Parts[0].(protocol.TextPart).Text)
+	assert.True(t, events[len(events)-1].IsFinal(), "stream should end with the final status")
+
+	// The message is in the history but the status is unaffected by it.
+	tm.MessagesMutex.RLock()
+	history := tm.Messages["message-task"]
+	tm.MessagesMutex.RUnlock()
+	require.Len(t, history, 2)
+	assert.Equal(t, protocol.MessageRoleAgent, history[1].Role)
+
+	err = tm.SendMessage("missing-task", protocol.NewMessage(protocol.MessageRoleAgent, nil))
+	assert.Error(t, err)
+}
+
+func TestMemoryTaskManager_PruneTasks(t *testing.T) {
+	newManager := func(t *testing.T) *MemoryTaskManager {
+		tm, err := NewMemoryTaskManager(&mockProcessor{})
+		require.NoError(t, err)
+		for _, id := range []string{"done-task", "running-task"} {
+			params := createTestTask(id, "prune")
+			params.Labels = map[string]string{"team": "a"}
+			tm.upsertTask(context.Background(), params)
+			tm.storeMessage(id, params.Message)
+		}
+		require.NoError(t, tm.UpdateTaskStatus("done-task", protocol.TaskStateCompleted, nil))
+		require.NoError(t, tm.UpdateTaskStatus("running-task", protocol.TaskStateWorking, nil))
+		tm.PushNotifications["done-task"] = protocol.PushNotificationConfig{URL: "http://example.com/hook"}
+		return tm
+	}
+
+	t.Run("DeletesFinishedTasks", func(t *testing.T) {
+		tm := newManager(t)
+		pruned, err := tm.PruneTasks(context.Background(), time.Now().Add(-time.Hour), nil)
+		require.NoError(t, err)
+		assert.Equal(t, 0, pruned, "Nothing finished before an hour ago")
+
+		var evicted []protocol.Task
+		pruned, err = tm.PruneTasks(context.Background(), time.Now().Add(time.Hour), func(task protocol.Task) {
+			evicted = append(evicted, task)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, pruned)
+		require.Len(t, evicted, 1)
+		assert.Equal(t, "done-task", evicted[0].ID)
+		assert.Equal(t, protocol.TaskStateCompleted, evicted[0].Status.State)
+
+		_, err = tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "done-task"})
+		assert.Error(t, err)
+		assert.NotContains(t, tm.Messages, "done-task")
+		assert.NotContains(t, tm.PushNotifications, "done-task")
+		tasks := tm.ListTasksByLabels(map[string]string{"team": "a"})
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "running-task", tasks[0].ID)
+	})
+
+	t.Run("TaskUpdatedDuringHookIsKept", func(t *testing.T) {
+		tm := newManager(t)
+		pruned, err := tm.PruneTasks(context.Background(), time.Now().Add(time.Hour), func(task protocol.Task) {
+			require.NoError(t, tm.UpdateTaskStatus(task.ID, protocol.TaskStateWorking, nil))
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, pruned)
+		_, err = tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "done-task"})
+		assert.NoError(t, err)
+	})
+}
+
+func TestMemoryTaskManager_Complete(t *testing.T) {
+	result := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("done")})
+	artifact := protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart("output")}}
+	lateErrs := make(chan []error, 1)
+	processor := &mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			if err := handle.Complete(result, artifact); err != nil {
+				return err
+			}
+			lateErrs <- []error{
+				handle.UpdateStatus(protocol.TaskStateWorking, nil),
+				handle.AddArtifact(artifact),
+				handle.SendMessage(result),
+				handle.Complete(result),
+			}
+			// A processor error after Complete must not turn the task into failed.
+			return errors.New("late failure")
+		},
+	}
+	tm, err := NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+
+	eventChan, err := tm.OnSendTaskSubscribe(context.Background(), createTestTask("complete-task", "go"))
+	require.NoError(t, err)
+	events := collectTaskEvents(t, eventChan, protocol.TaskStateCompleted, 3*time.Second)
+
+	for _, err := range <-lateErrs {
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, ErrCodeTaskFinal, rpcErr.Code)
+	}
+
+	// The artifact is delivered before the final status that reports it.
+	require.GreaterOrEqual(t, len(events), 2)
+	_, isArtifact := events[len(events)-2].(protocol.TaskArtifactUpdateEvent)
+	assert.True(t, isArtifact, "artifact event should precede the final status")
+	final, ok := events[len(events)-1].(protocol.TaskStatusUpdateEvent)
+	require.True(t, ok)
+	assert.True(t, final.Final)
+	require.NotNil(t, final.Status.Message)
+	assert.Equal(t, "done", final.Status.Message.Parts[0].(protocol.TextPart).Text)
+
+	require.Eventually(t, func() bool {
+		tm.ContextsMutex.RLock()
+		defer tm.ContextsMutex.RUnlock()
+		_, running := tm.Contexts["complete-task"]
+		return !running
+	}, time.Second, 5*time.Millisecond, "processor should finish")
+	task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "complete-task"})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+	require.Len(t, task.Artifacts, 1)
+	assert.Equal(t, "output", task.Artifacts[0].Parts[0].(protocol.TextPart).Text)
+
+	err = tm.CompleteTask("missing-task", result)
+	assert.Error(t, err)
+}
+
+func TestMemoryTaskManager_Fail(t *testing.T) {
+	lateErrs := make(chan []error, 1)
+	processor := &mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			cause := WithErrorCode("rate_limited", errors.New("upstream is rate limiting"))
+			if err := handle.Fail(fmt.Errorf("calling upstream: %w", cause), true); err != nil {
+				return err
+			}
+			lateErrs <- []error{
+				handle.UpdateStatus(protocol.TaskStateCompleted, nil),
+				handle.Fail(errors.New("again"), false),
+			}
+			// The processor's own error must not replace the reported failure.
+			return errors.New("late failure")
+		},
+	}
+	tm, err := NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+
+	eventChan, err := tm.OnSendTaskSubscribe(context.Background(), createTestTask("fail-task", "go"))
+	require.NoError(t, err)
+	events := collectTaskEvents(t, eventChan, protocol.TaskStateFailed, 3*time.Second)
+	for _, err := range <-lateErrs {
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, ErrCodeTaskFinal, rpcErr.Code)
+	}
+	final, ok := events[len(events)-1].(protocol.TaskStatusUpdateEvent)
+	require.True(t, ok)
+	assert.True(t, final.Final)
+	assert.NotNil(t, final.Status.Error)
+
+	require.Eventually(t, func() bool {
+		tm.ContextsMutex.RLock()
+		defer tm.ContextsMutex.RUnlock()
+		_, running := tm.Contexts["fail-task"]
+		return !running
+	}, time.Second, 5*time.Millisecond, "processor should finish")
+	task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "fail-task"})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateFailed, task.Status.State)
+	require.NotNil(t, task.Status.Error)
+	assert.Equal(t, protocol.TaskError{
+		Code:      "rate_limited",
+		Message:   "calling upstream: upstream is rate limiting",
+		Retryable: true,
+	}, *task.Status.Error)
+	require.NotNil(t, task.Status.Message)
+	assertTextPart(t, task.Status.Message.Parts[0], "calling upstream: upstream is rate limiting")
+
+	t.Run("WithoutCode", func(t *testing.T) {
+		status := FailureStatus(errors.New("bad input"), false)
+		assert.Equal(t, protocol.TaskStateFailed, status.State)
+		assert.Equal(t, &protocol.TaskError{Message: "bad input"}, status.Error)
+	})
+}
+
+func TestMemoryTaskManager_SubscriberSendTimeout(t *testing.T) {
+	event := protocol.TaskStatusUpdateEvent{ID: "slow-task", Status: protocol.TaskStatus{State: protocol.TaskStateWorking}}
+	subscribers := func(tm *MemoryTaskManager) int {
+		tm.SubMutex.RLock()
+		defer tm.SubMutex.RUnlock()
+		return len(tm.Subscribers["slow-task"])
+	}
+
+	t.Run("DropsEventsByDefault", func(t *testing.T) {
+		tm, err := NewMemoryTaskManager(&mockProcessor{})
+		require.NoError(t, err)
+		ch := make(chan protocol.TaskEvent, 1)
+		tm.addSubscriber("slow-task", ch)
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			tm.notifySubscribers("slow-task", event)
+		}
+		assert.Less(t, time.Since(start), 50*time.Millisecond, "sends should not block")
+		assert.Len(t, ch, 1)
+		assert.Equal(t, 1, subscribers(tm), "the subscriber is kept")
+	})
+
+	t.Run("BlocksThenDropsSlowSubscriber", func(t *testing.T) {
+		const timeout = 50 * time.Millisecond
+		tm, err := NewMemoryTaskManager(&mockProcessor{})
+		require.NoError(t, err)
+		tm.SetSubscriberSendTimeout(timeout)
+		ch := make(chan protocol.TaskEvent, 1)
+		tm.addSubscriber("slow-task", ch)
+		tm.notifySubscribers("slow-task", event)
+
+		// A subscriber making room in time receives the event.
+		go func() {
+			time.Sleep(timeout / 5)
+			<-ch
+		}()
+		tm.notifySubscribers("slow-task", event)
+		assert.Len(t, ch, 1)
+		assert.Equal(t, 1, subscribers(tm))
+
+		// One that does not is dropped after the timeout.
+		start := time.Now()
+		tm.notifySubscribers("slow-task", event)
+		assert.GreaterOrEqual(t, time.Since(start), timeout, "the send should wait for the subscriber")
+		assert.Equal(t, 0, subscribers(tm))
+
+		start = time.Now()
+		tm.notifySubscribers("slow-task", event)
+		assert.Less(t, time.Since(start), timeout, "a dropped subscriber no longer blocks sends")
+	})
+}
+
+func TestMemoryTaskManager_MaxArtifactsPerTask(t *testing.T) {
+	artifact := func(index int, text string, appendChunk bool) protocol.Artifact {
+		return protocol.Artifact{
+			Index:  index,
+			Parts:  []protocol.Part{protocol.NewTextPart(text)},
+			Append: &appendChunk,
+		}
+	}
+	errs := make(chan []error, 1)
+	processor := &mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			errs <- []error{
+				handle.AddArtifact(artifact(0, "first", false)),
+				handle.AddArtifact(artifact(1, "second", false)),
+				handle.AddArtifact(artifact(0, " more", true)),
+				handle.AddArtifact(artifact(2, "third", false)),
+				handle.AddArtifact(artifact(1, " late", true)),
+			}
+			return nil
+		},
+	}
+	tm, err := NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+	tm.SetMaxArtifactsPerTask(2)
+
+	eventChan, err := tm.OnSendTaskSubscribe(context.Background(), createTestTask("artifact-cap", "go"))
+	require.NoError(t, err)
+	events := collectTaskEvents(t, eventChan, protocol.TaskStateFailed, 3*time.Second)
+
+	results := <-errs
+	for _, err := range results[:3] {
+		assert.NoError(t, err, "appends to an existing artifact don't count")
+	}
+	assert.ErrorIs(t, results[3], ErrTooManyArtifacts)
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, results[4], &rpcErr, "the failed task accepts no further artifacts")
+	assert.Equal(t, ErrCodeTaskFinal, rpcErr.Code)
+
+	final, ok := events[len(events)-1].(protocol.TaskStatusUpdateEvent)
+	require.True(t, ok)
+	require.NotNil(t, final.Status.Message)
+	assert.Contains(t, final.Status.Message.Parts[0].(protocol.TextPart).Text, "limited to 2 artifacts")
+
+	task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "artifact-cap"})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateFailed, task.Status.State)
+	assert.Len(t, task.Artifacts, 3, "the rejected artifact is not stored")
+}
+
+// lifecycleSummary returns the type, and state if any, of each lifecycle event.
+func lifecycleSummary(events []protocol.TaskLifecycleEvent) []string {
+	summary := make([]string, 0, len(events))
+	for _, event := range events {
+		entry := string(event.Type)
+		if event.State != "" {
+			entry += ":" + string(event.State)
+		}
+		summary = append(summary, entry)
+	}
+	return summary
+}
+
+func TestMemoryTaskManager_LifecycleEvents(t *testing.T) {
+	processor := &mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			if taskID == "failing-task" {
+				return errors.New("model unavailable")
+			}
+			if err := handle.UpdateStatus(protocol.TaskStateWorking, nil); err != nil {
+				return err
+			}
+			return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+		},
+	}
+	tm, err := NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+
+	t.Run("Completed", func(t *testing.T) {
+		_, err := tm.OnSendTask(context.Background(), createTestTask("events-task", "go"))
+		require.NoError(t, err)
+		task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "events-task", IncludeEvents: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"received",
+			"status_changed:working",
+			"processing_started",
+			"status_changed:working",
+			"status_changed:completed",
+		}, lifecycleSummary(task.Events))
+		for _, event := range task.Events {
+			_, err := time.Parse(time.RFC3339Nano, event.Timestamp)
+			assert.NoError(t, err)
+		}
+		// The log is only included on request, and never mixed into the history.
+		task, err = tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "events-task"})
+		require.NoError(t, err)
+		assert.Nil(t, task.Events)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		_, err := tm.OnSendTask(context.Background(), createTestTask("failing-task", "go"))
+		require.Error(t, err)
+		task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "failing-task", IncludeEvents: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"received",
+			"status_changed:working",
+			"processing_started",
+			"error",
+			"status_changed:failed",
+		}, lifecycleSummary(task.Events))
+		assert.Equal(t, "model unavailable", task.Events[3].Detail)
+	})
+
+	t.Run("Bounded", func(t *testing.T) {
+		for i := 0; i < MaxLifecycleEvents+10; i++ {
+			require.NoError(t, tm.UpdateTaskStatus("events-task", protocol.TaskStateWorking, nil))
+		}
+		task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "events-task", IncludeEvents: true})
+		require.NoError(t, err)
+		assert.Len(t, task.Events, MaxLifecycleEvents)
+		assert.Equal(t, protocol.TaskLifecycleStatusChanged, task.Events[0].Type)
+	})
+}
+
+func TestMemoryTaskManager_WaitForTaskChange(t *testing.T) {
+	tm, err := NewMemoryTaskManager(&mockProcessor{})
+	require.NoError(t, err)
+	tm.upsertTask(context.Background(), createTestTask("wait-task", "go"))
+	require.NoError(t, tm.UpdateTaskStatus("wait-task", protocol.TaskStateWorking, nil))
+
+	t.Run("Timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, tm.WaitForTaskChange(ctx, "wait-task"), context.DeadlineExceeded)
+	})
+
+	t.Run("WokenByStatusChange", func(t *testing.T) {
+		const waiters = 3
+		woken := make(chan error, waiters)
+		for i := 0; i < waiters; i++ {
+			go func() { woken <- tm.WaitForTaskChange(context.Background(), "wait-task") }()
+		}
+		require.Eventually(t, func() bool {
+			tm.TasksMutex.RLock()
+			defer tm.TasksMutex.RUnlock()
+			return tm.statusWaiters["wait-task"] != nil
+		}, time.Second, time.Millisecond)
+		time.Sleep(10 * time.Millisecond) // Let every waiter block.
+		require.NoError(t, tm.UpdateTaskStatus("wait-task", protocol.TaskStateInputRequired, nil))
+		for i := 0; i < waiters; i++ {
+			select {
+			case err := <-woken:
+				assert.NoError(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("waiter was not woken by the status change")
+			}
+		}
+	})
+
+	t.Run("FinalTask", func(t *testing.T) {
+		require.NoError(t, tm.CompleteTask("wait-task", protocol.NewMessage(protocol.MessageRoleAgent, nil)))
+		assert.NoError(t, tm.WaitForTaskChange(context.Background(), "wait-task"))
+	})
+
+	t.Run("UnknownTask", func(t *testing.T) {
+		assert.Error(t, tm.WaitForTaskChange(context.Background(), "missing-task"))
+	})
+}
+
+func TestMemoryTaskManager_SetEstimatedCompletion(t *testing.T) {
+	tm, err := NewMemoryTaskManager(&mockProcessor{})
+	require.NoError(t, err)
+	tm.upsertTask(context.Background(), createTestTask("eta-task", "go"))
+	eta := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("RequiresWorking", func(t *testing.T) {
+		assert.ErrorContains(t, tm.SetEstimatedCompletion("eta-task", eta), "only be set while working")
+		assert.Error(t, tm.SetEstimatedCompletion("missing-task", eta))
+	})
+
+	t.Run("KeptWhileWorking", func(t *testing.T) {
+		require.NoError(t, tm.UpdateTaskStatus("eta-task", protocol.TaskStateWorking, nil))
+		require.NoError(t, tm.SetEstimatedCompletion("eta-task", eta.In(time.FixedZone("UTC+8", 8*3600))))
+		require.NoError(t, tm.UpdateTaskStatus("eta-task", protocol.TaskStateWorking, nil))
+		task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "eta-task"})
+		require.NoError(t, err)
+		assert.Equal(t, "2030-01-02T03:04:05Z", task.Status.EstimatedCompletion)
+		got, ok := task.Status.EstimatedCompletionTime()
+		require.True(t, ok)
+		assert.True(t, eta.Equal(got))
+	})
+
+	t.Run("ClearedOnLeavingWorking", func(t *testing.T) {
+		require.NoError(t, tm.UpdateTaskStatus("eta-task", protocol.TaskStateInputRequired, nil))
+		task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "eta-task"})
+		require.NoError(t, err)
+		_, ok := task.Status.EstimatedCompletionTime()
+		assert.False(t, ok)
+	})
+
+	t.Run("FinalTask", func(t *testing.T) {
+		require.NoError(t, tm.CompleteTask("eta-task", protocol.NewMessage(protocol.MessageRoleAgent, nil)))
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, tm.SetEstimatedCompletion("eta-task", eta), &rpcErr)
+		assert.Equal(t, ErrCodeTaskFinal, rpcErr.Code)
+	})
+}
+
+func TestMemoryTaskManager_MaxHistoryBytes(t *testing.T) {
+	tm, err := NewMemoryTaskManager(&mockProcessor{})
+	require.NoError(t, err)
+	message := func(text string) protocol.Message {
+		return protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)})
+	}
+	size := messageSize(message("aaaa"))
+	tm.SetMaxHistoryBytes(3 * size)
+	history := func() []string {
+		tm.MessagesMutex.RLock()
+		defer tm.MessagesMutex.RUnlock()
+		var texts []string
+		for _, msg := range tm.Messages["history-task"] {
+			texts = append(texts, msg.Parts[0].(protocol.TextPart).Text)
+		}
+		return texts
+	}
+
+	for _, text := range []string{"0000", "1111", "2222"} {
+		tm.storeMessage("history-task", message(text))
+	}
+	assert.Equal(t, []string{"0000", "1111", "2222"}, history(), "history fits the cap exactly")
+
+	tm.storeMessage("history-task", message("3333"))
+	assert.Equal(t, []string{"1111", "2222", "3333"}, history(), "oldest message evicted")
+
+	// A message needing the room of two evicts two.
+	tm.storeMessage("history-task", message(strings.Repeat("4", size+4)))
+	assert.Equal(t, []string{"3333", strings.Repeat("4", size+4)}, history())
+
+	// A message larger than the whole cap is not stored and evicts nothing.
+	tm.storeMessage("history-task", message(strings.Repeat("5", 3*size)))
+	assert.Equal(t, []string{"3333", strings.Repeat("4", size+4)}, history())
+
+	tm.SetMaxHistoryBytes(0)
+	tm.storeMessage("history-task", message(strings.Repeat("6", 3*size)))
+	assert.Len(t, history(), 3, "no cap")
+}
+
+func TestMemoryTaskManager_Snapshots(t *testing.T) {
+	ctx := context.Background()
+	processor := &mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			if err := handle.AddArtifact(protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart("report")}}); err != nil {
+				return err
+			}
+			reply := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("done")})
+			return handle.Complete(reply)
+		},
+	}
+	source, err := NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+	params := createTestTask("snapshot-task", "write a report")
+	params.Metadata = map[string]interface{}{"owner": "alice"}
+	params.Labels = map[string]string{"team": "docs"}
+	_, err = source.OnSendTask(ctx, params)
+	require.NoError(t, err)
+
+	snapshot, err := ExportTask(ctx, source, "snapshot-task")
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskSnapshotVersion, snapshot.Version)
+	assert.Equal(t, protocol.TaskStateCompleted, snapshot.Task.Status.State)
+	assert.Len(t, snapshot.Task.History, 2)
+	assert.NotEmpty(t, snapshot.Task.Events)
+
+	target, err := NewMemoryTaskManager(&mockProcessor{})
+	require.NoError(t, err)
+	imported, err := ImportTask(ctx, target, protocol.TaskImportParams{Snapshot: *snapshot})
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot-task", imported.ID)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		exported, err := ExportTask(ctx, target, "snapshot-task")
+		require.NoError(t, err)
+		assert.Equal(t, snapshot.Task, exported.Task)
+		assert.Len(t, target.ListTasksByLabels(map[string]string{"team": "docs"}), 1)
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		_, err := ImportTask(ctx, target, protocol.TaskImportParams{
+			Snapshot: *snapshot, OnConflict: protocol.ImportConflictReject,
+		})
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, ErrCodeTaskExists, rpcErr.Code)
+		assert.False(t, isTaskExists(jsonrpc.NewUnsupportedOperationError("tasks/import")),
+			"an unsupported operation is not a conflict")
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		for _, want := range []string{"snapshot-task-imported-1", "snapshot-task-imported-2"} {
+			task, err := ImportTask(ctx, target, protocol.TaskImportParams{
+				Snapshot: *snapshot, OnConflict: protocol.ImportConflictRename,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, want, task.ID)
+		}
+		assert.Len(t, target.ListTasksByLabels(map[string]string{"team": "docs"}), 3)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		replacement := *snapshot
+		replacement.Task = copyTask(&snapshot.Task)
+		replacement.Task.Labels = map[string]string{"team": "ops"}
+		replacement.Task.History = nil
+		_, err := ImportTask(ctx, target, protocol.TaskImportParams{
+			Snapshot: replacement, OnConflict: protocol.ImportConflictReplace,
+		})
+		require.NoError(t, err)
+		exported, err := ExportTask(ctx, target, "snapshot-task")
+		require.NoError(t, err)
+		assert.Empty(t, exported.Task.History)
+		assert.Len(t, target.ListTasksByLabels(map[string]string{"team": "docs"}), 2, "old labels are unindexed")
+		assert.Len(t, target.ListTasksByLabels(map[string]string{"team": "ops"}), 1)
+	})
+
+	t.Run("NewerVersion", func(t *testing.T) {
+		newer := *snapshot
+		newer.Version = protocol.TaskSnapshotVersion + 1
+		newer.Task.ID = "future-task"
+		_, err := ImportTask(ctx, target, protocol.TaskImportParams{Snapshot: newer})
+		var validationErr *protocol.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "/snapshot/version", validationErr.Fields[0].Path)
+	})
+
+	t.Run("UnknownTask", func(t *testing.T) {
+		_, err := ExportTask(ctx, source, "missing-task")
+		assert.Error(t, err)
+	})
+}
+
+func TestMemoryTaskManager_Dependencies(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	release := make(map[string]chan struct{})
+	processor := &mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			mu.Lock()
+			ran = append(ran, taskID)
+			wait := release[taskID]
+			mu.Unlock()
+			if wait != nil {
+				select {
+				case <-wait:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			if strings.HasPrefix(taskID, "failing") {
+				return errors.New("prerequisite broke")
+			}
+			return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+		},
+	}
+	ranTasks := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), ran...)
+	}
+	blockUntilReleased := func(taskID string) chan struct{} {
+		mu.Lock()
+		defer mu.Unlock()
+		release[taskID] = make(chan struct{})
+		return release[taskID]
+	}
+	tm, err := NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("DependentRunsAfterPrerequisite", func(t *testing.T) {
+		prereqDone := blockUntilReleased("prereq")
+		_, err := tm.OnSendTaskSubscribe(ctx, createTestTask("prereq", "first"))
+		require.NoError(t, err)
+
+		params := createTestTask("dependent", "second")
+		params.DependsOn = []string{"prereq"}
+		events, err := tm.OnSendTaskSubscribe(ctx, params)
+		require.NoError(t, err)
+		first := (<-events).(protocol.TaskStatusUpdateEvent)
+		assert.Equal(t, protocol.TaskStateSubmitted, first.Status.State)
+
+		time.Sleep(20 * time.Millisecond)
+		task, err := tm.OnGetTask(ctx, protocol.TaskQueryParams{ID: "dependent"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateSubmitted, task.Status.State)
+		assert.Equal(t, []string{"prereq"}, task.DependsOn)
+		assert.NotContains(t, ranTasks(), "dependent")
+
+		close(prereqDone)
+		collected := collectTaskEvents(t, events, protocol.TaskStateCompleted, 2*time.Second)
+		last := collected[len(collected)-1].(protocol.TaskStatusUpdateEvent)
+		assert.Equal(t, protocol.TaskStateCompleted, last.Status.State)
+		assert.Equal(t, []string{"prereq", "dependent"}, ranTasks())
+	})
+
+	t.Run("SyncSendWaits", func(t *testing.T) {
+		prereqDone := blockUntilReleased("sync-prereq")
+		go tm.OnSendTask(ctx, createTestTask("sync-prereq", "first"))
+		require.Eventually(t, func() bool {
+			task, err := tm.OnGetTask(ctx, protocol.TaskQueryParams{ID: "sync-prereq"})
+			return err == nil && task.Status.State == protocol.TaskStateWorking
+		}, time.Second, time.Millisecond)
+
+		params := createTestTask("sync-dependent", "second")
+		params.DependsOn = []string{"sync-prereq"}
+		done := make(chan *protocol.Task, 1)
+		go func() {
+			task, err := tm.OnSendTask(ctx, params)
+			assert.NoError(t, err)
+			done <- task
+		}()
+		select {
+		case <-done:
+			t.Fatal("dependent task finished before its prerequisite")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(prereqDone)
+		select {
+		case task := <-done:
+			assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+		case <-time.After(2 * time.Second):
+			t.Fatal("dependent task did not run after its prerequisite completed")
+		}
+	})
+
+	t.Run("FailedPrerequisiteFailsDependent", func(t *testing.T) {
+		prereqDone := blockUntilReleased("failing-prereq")
+		_, err := tm.OnSendTaskSubscribe(ctx, createTestTask("failing-prereq", "first"))
+		require.NoError(t, err)
+		params := createTestTask("doomed", "second")
+		params.DependsOn = []string{"failing-prereq"}
+		events, err := tm.OnSendTaskSubscribe(ctx, params)
+		require.NoError(t, err)
+		close(prereqDone)
+
+		collected := collectTaskEvents(t, events, protocol.TaskStateFailed, 2*time.Second)
+		last := collected[len(collected)-1].(protocol.TaskStatusUpdateEvent)
+		require.Equal(t, protocol.TaskStateFailed, last.Status.State)
+		assertTextPart(t, last.Status.Message.Parts[0], "dependency task failing-prereq ended failed")
+		assert.NotContains(t, ranTasks(), "doomed")
+	})
+
+	t.Run("CanceledWhilePending", func(t *testing.T) {
+		blockUntilReleased("slow-prereq")
+		_, err := tm.OnSendTaskSubscribe(ctx, createTestTask("slow-prereq", "first"))
+		require.NoError(t, err)
+		params := createTestTask("waiting", "second")
+		params.DependsOn = []string{"slow-prereq"}
+		_, err = tm.OnSendTaskSubscribe(ctx, params)
+		require.NoError(t, err)
+
+		task, err := tm.OnCancelTask(ctx, protocol.TaskIDParams{ID: "waiting"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCanceled, task.Status.State)
+		time.Sleep(20 * time.Millisecond)
+		task, err = tm.OnGetTask(ctx, protocol.TaskQueryParams{ID: "waiting"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCanceled, task.Status.State)
+		_, err = tm.OnCancelTask(ctx, protocol.TaskIDParams{ID: "slow-prereq"})
+		require.NoError(t, err)
+		assert.NotContains(t, ranTasks(), "waiting")
+	})
+
+	t.Run("RejectsUnknownDependency", func(t *testing.T) {
+		params := createTestTask("orphan", "second")
+		params.DependsOn = []string{"missing"}
+		_, err := tm.OnSendTask(ctx, params)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+		_, err = tm.OnGetTask(ctx, protocol.TaskQueryParams{ID: "orphan"})
+		assert.Error(t, err, "a rejected task is not created")
+	})
+
+	t.Run("RejectsCycle", func(t *testing.T) {
+		tm.upsertTask(context.Background(), createTestTask("cycle-a", "a"))
+		b := createTestTask("cycle-b", "b")
+		b.DependsOn = []string{"cycle-a"}
+		tm.upsertTask(context.Background(), b)
+		c := createTestTask("cycle-c", "c")
+		c.DependsOn = []string{"cycle-b"}
+		tm.upsertTask(context.Background(), c)
+
+		a := createTestTask("cycle-a", "again")
+		a.DependsOn = []string{"cycle-c"}
+		_, err := tm.OnSendTaskSubscribe(ctx, a)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+		assert.Contains(t, rpcErr.Data, "[cycle-a cycle-c cycle-b cycle-a]")
+	})
+}
+
+// recordingLogger records the Info messages logged through it.
+type recordingLogger struct {
+	log.Logger
+	mu    sync.Mutex
+	infos []string
+}
+
+func (l *recordingLogger) Infof(format string, args ...interface{}) {
+	l.mu.Lock()
+	l.infos = append(l.infos, fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+	l.Logger.Infof(format, args...)
+}
+
+func (l *recordingLogger) contains(substr string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, info := range l.infos {
+		if strings.Contains(info, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMemoryTaskManager_RequestID(t *testing.T) {
+	logger := &recordingLogger{Logger: log.Default}
+	log.Default = logger
+	defer func() { log.Default = logger.Logger }()
+
+	tm, err := NewMemoryTaskManager(&mockProcessor{})
+	require.NoError(t, err)
+	ctx := WithRequestID(context.Background(), "req-42")
+	task, err := tm.OnSendTask(ctx, createTestTask("traced", "hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "req-42", task.RequestID)
+	assert.True(t, logger.contains("Created new task traced (Session: <nil>, Request: req-42)"))
+
+	got, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "traced"})
+	require.NoError(t, err)
+	assert.Equal(t, "req-42", got.RequestID)
+	assert.Empty(t, RequestIDFromContext(context.Background()))
+}
+
+func TestMemoryTaskManager_CancelStreamingTask(t *testing.T) {
+	stopErr := make(chan error, 1)
+	tm, err := NewMemoryTaskManager(&mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			<-ctx.Done()
+			// A processor reporting its own failure as it stops.
+			stopErr <- handle.UpdateStatus(protocol.TaskStateFailed, nil)
+			return ctx.Err()
+		},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	eventChan, err := tm.OnSendTaskSubscribe(ctx, createTestTask("stream-cancel", "wait"))
+	require.NoError(t, err)
+	select {
+	case event := <-eventChan:
+		require.Equal(t, protocol.TaskStateWorking, event.(protocol.TaskStatusUpdateEvent).Status.State)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the task to start")
+	}
+
+	_, err = tm.OnCancelTask(ctx, protocol.TaskIDParams{ID: "stream-cancel"})
+	require.NoError(t, err)
+	select {
+	case event := <-eventChan:
+		statusEvent, ok := event.(protocol.TaskStatusUpdateEvent)
+		require.True(t, ok)
+		assert.True(t, statusEvent.Final)
+		assert.Equal(t, protocol.TaskStateCanceled, statusEvent.Status.State)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the canceled status")
+	}
+
+	// The processor's report of the stop is rejected and reaches no subscriber.
+	assert.Error(t, <-stopErr)
+	select {
+	case event := <-eventChan:
+		t.Fatalf("unexpected event after the canceled status: %+v", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+	task, err := tm.OnGetTask(ctx, protocol.TaskQueryParams{ID: "stream-cancel"})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateCanceled, task.Status.State)
+}
+
+func TestMemoryTaskManager_CompleteAfterCancel(t *testing.T) {
+	ctx := context.Background()
+	newManager := func(t *testing.T) (*MemoryTaskManager, chan struct{}, chan error) {
+		started, completeErr := make(chan struct{}, 1), make(chan error, 1)
+		tm, err := NewMemoryTaskManager(&mockProcessor{
+			processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+				started <- struct{}{}
+				<-ctx.Done()
+				// A processor finishing its work regardless of the cancellation.
+				completeErr <- handle.Complete(protocol.NewMessage(protocol.MessageRoleAgent,
+					[]protocol.Part{protocol.NewTextPart("done")}))
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		return tm, started, completeErr
+	}
+
+	t.Run("Send", func(t *testing.T) {
+		tm, started, completeErr := newManager(t)
+		sent := make(chan *protocol.Task, 1)
+		go func() {
+			task, err := tm.OnSendTask(ctx, createTestTask("send-cancel", "wait"))
+			assert.NoError(t, err)
+			sent <- task
+		}()
+		<-started
+		_, err := tm.OnCancelTask(ctx, protocol.TaskIDParams{ID: "send-cancel"})
+		require.NoError(t, err)
+
+		assert.Error(t, <-completeErr)
+		assert.Equal(t, protocol.TaskStateCanceled, (<-sent).Status.State)
+		task, err := tm.OnGetTask(ctx, protocol.TaskQueryParams{ID: "send-cancel"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCanceled, task.Status.State)
+	})
+
+	t.Run("Subscribe", func(t *testing.T) {
+		tm, started, completeErr := newManager(t)
+		eventChan, err := tm.OnSendTaskSubscribe(ctx, createTestTask("subscribe-cancel", "wait"))
+		require.NoError(t, err)
+		<-started
+		_, err = tm.OnCancelTask(ctx, protocol.TaskIDParams{ID: "subscribe-cancel"})
+		require.NoError(t, err)
+		assert.Error(t, <-completeErr)
+
+		var finals []protocol.TaskState
+		for done := false; !done; {
+			select {
+			case event := <-eventChan:
+				if statusEvent, ok := event.(protocol.TaskStatusUpdateEvent); ok && statusEvent.Final {
+					finals = append(finals, statusEvent.Status.State)
+				}
+			case <-time.After(20 * time.Millisecond):
+				done = true
+			}
+		}
+		assert.Equal(t, []protocol.TaskState{protocol.TaskStateCanceled}, finals)
+	})
+
+	t.Run("ManagerRejectsFinalOverwrite", func(t *testing.T) {
+		tm, _, _ := newManager(t)
+		tm.Tasks["final"] = &protocol.Task{ID: "final", Status: protocol.TaskStatus{State: protocol.TaskStateCanceled}}
+		assert.Error(t, tm.CompleteTask("final", protocol.NewMessage(protocol.MessageRoleAgent, nil)))
+		assert.Error(t, tm.UpdateTaskStatus("final", protocol.TaskStateFailed, nil))
+		assert.Equal(t, protocol.TaskStateCanceled, tm.Tasks["final"].Status.State)
+		// A final task can still be worked on again.
+		assert.NoError(t, tm.UpdateTaskStatus("final", protocol.TaskStateWorking, nil))
+	})
+}
+
+func TestMemoryTaskManager_SendInput(t *testing.T) {
+	ctx := context.Background()
+	received := make(chan []string, 1)
+	tm, err := NewMemoryTaskManager(&mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			reader, ok := handle.(InputReader)
+			if !ok {
+				return errors.New("handle does not implement InputReader")
+			}
+			var texts []string
+			for input := range reader.Input() {
+				texts = append(texts, input.Parts[0].(protocol.TextPart).Text)
+			}
+			received <- texts
+			return handle.Complete(protocol.NewMessage(protocol.MessageRoleAgent, nil))
+		},
+	})
+	require.NoError(t, err)
+	input := func(taskID, text string, final bool) protocol.TaskInputParams {
+		return protocol.TaskInputParams{
+			ID:      taskID,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)}),
+			Final:   final,
+		}
+	}
+
+	t.Run("DeliveredInOrder", func(t *testing.T) {
+		_, err := tm.OnSendTaskSubscribe(ctx, createTestTask("input-task", "start"))
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, tm.OnSendInput(ctx, input("input-task", fmt.Sprintf("chunk-%d", i), i == 2)))
+		}
+		select {
+		case texts := <-received:
+			assert.Equal(t, []string{"chunk-0", "chunk-1", "chunk-2"}, texts)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the processor to read its input")
+		}
+		require.Eventually(t, func() bool {
+			task, err := tm.OnGetTask(ctx, protocol.TaskQueryParams{ID: "input-task"})
+			return err == nil && task.Status.State == protocol.TaskStateCompleted
+		}, time.Second, 5*time.Millisecond)
+
+		err = tm.OnSendInput(ctx, input("input-task", "late", false))
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, ErrCodeTaskFinal, rpcErr.Code)
+	})
+
+	t.Run("RejectedAfterFinalInput", func(t *testing.T) {
+		blocked, err := NewMemoryTaskManager(&mockProcessor{
+			processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+		require.NoError(t, err)
+		_, err = blocked.OnSendTaskSubscribe(ctx, createTestTask("closed-input", "start"))
+		require.NoError(t, err)
+		require.NoError(t, blocked.OnSendInput(ctx, input("closed-input", "last", true)))
+		assert.ErrorIs(t, blocked.OnSendInput(ctx, input("closed-input", "more", false)), ErrInputClosed)
+		_, err = blocked.OnCancelTask(ctx, protocol.TaskIDParams{ID: "closed-input"})
+		require.NoError(t, err)
+	})
+
+	t.Run("UnknownTask", func(t *testing.T) {
+		err := tm.OnSendInput(ctx, input("no-such-task", "hi", false))
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, ErrCodeTaskNotFound, rpcErr.Code)
+	})
+}
+
+func TestEventBus(t *testing.T) {
+	publish := func(bus *EventBus, n int) {
+		for i := 0; i < n; i++ {
+			bus.Publish(BusEvent{Type: BusEventMessage, TaskID: fmt.Sprintf("task-%d", i)})
+		}
+	}
+	drain := func(sub *BusSubscription) []string {
+		var ids []string
+		for len(sub.Events()) > 0 {
+			ids = append(ids, (<-sub.Events()).TaskID)
+		}
+		return ids
+	}
+
+	t.Run("DropNewest", func(t *testing.T) {
+		bus := NewEventBus()
+		sub := bus.Subscribe(2, BusDropNewest)
+		defer sub.Close()
+		publish(bus, 4)
+		assert.Equal(t, []string{"task-0", "task-1"}, drain(sub))
+		assert.Equal(t, int64(2), sub.Dropped())
+	})
+
+	t.Run("DropOldest", func(t *testing.T) {
+		bus := NewEventBus()
+		sub := bus.Subscribe(2, BusDropOldest)
+		defer sub.Close()
+		publish(bus, 4)
+		assert.Equal(t, []string{"task-2", "task-3"}, drain(sub))
+		assert.Equal(t, int64(2), sub.Dropped())
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		bus := NewEventBus()
+		sub := bus.Subscribe(0, BusDropNewest)
+		sub.Close()
+		sub.Close()
+		publish(bus, 1)
+		_, ok := <-sub.Events()
+		assert.False(t, ok)
+	})
+
+	t.Run("PublishedByManager", func(t *testing.T) {
+		tm, err := NewMemoryTaskManager(&mockProcessor{
+			processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+				if err := handle.AddArtifact(protocol.Artifact{
+					Parts: []protocol.Part{protocol.NewTextPart("result")},
+				}); err != nil {
+					return err
+				}
+				return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+			},
+		})
+		require.NoError(t, err)
+		bus := NewEventBus()
+		tm.SetEventBus(bus)
+		sub := bus.Subscribe(0, BusDropNewest)
+		defer sub.Close()
+		_, err = tm.OnSendTask(context.Background(), createTestTask("bus-task", "hi"))
+		require.NoError(t, err)
+		var events []string
+		for len(sub.Events()) > 0 {
+			event := <-sub.Events()
+			assert.Equal(t, "bus-task", event.TaskID)
+			assert.False(t, event.Time.IsZero())
+			if status, ok := event.Event.(protocol.TaskStatusUpdateEvent); ok {
+				events = append(events, fmt.Sprintf("%s:%s", event.Type, status.Status.State))
+			} else {
+				events = append(events, string(event.Type))
+			}
+		}
+		assert.Equal(t, []string{
+			"task_created",
+			"status_changed:working",
+			"artifact",
+			"status_changed:completed",
+			"task_finished:completed",
+		}, events)
+	})
+}
@@ -0,0 +1,162 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// defaultPushDedupSize is the default number of delivery IDs a PushDeduplicator
+// remembers.
+const defaultPushDedupSize = 1024
+
+// PushDelivery describes the delivery of an event by a PushQueue. The queue passes
+// it to its PushSender through the context, see PushDeliveryFromContext.
+type PushDelivery struct {
+	// ID identifies the event being delivered. It is the same for every attempt.
+	ID string
+	// Sequence numbers the events delivered for the task to the webhook, from 1.
+	Sequence int64
+	// Attempt counts the attempts to deliver the event, from 1.
+	Attempt int
+}
+
+// pushDeliveryKey is the context key of the PushDelivery.
+type pushDeliveryKey struct{}
+
+// withPushDelivery returns a copy of ctx carrying delivery.
+func withPushDelivery(ctx context.Context, delivery PushDelivery) context.Context {
+	return context.WithValue(ctx, pushDeliveryKey{}, delivery)
+}
+
+// PushDeliveryFromContext returns the delivery a PushQueue passes to its PushSender,
+// and false if ctx does not come from a PushQueue.
+func PushDeliveryFromContext(ctx context.Context) (PushDelivery, bool) {
+	delivery, ok := ctx.Value(pushDeliveryKey{}).(PushDelivery)
+	return delivery, ok
+}
+
+// newDeliveryID returns a random delivery ID.
+func newDeliveryID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// MarshalPushNotification returns the tasks/notifyEvent JSON-RPC notification
+// delivering event for taskID. If ctx carries a PushDelivery, its ID and sequence
+// number are added to the params, see protocol.PushNotificationParams.
+func MarshalPushNotification(ctx context.Context, taskID string, event protocol.TaskEvent) ([]byte, error) {
+	var eventType string
+	switch event.(type) {
+	case protocol.TaskStatusUpdateEvent:
+		eventType = protocol.EventTaskStatusUpdate
+	case protocol.TaskArtifactUpdateEvent:
+		eventType = protocol.EventTaskArtifactUpdate
+	case protocol.TaskArtifactPatchEvent:
+		eventType = protocol.EventTaskArtifactPatch
+	case protocol.TaskArtifactDeleteEvent:
+		eventType = protocol.EventTaskArtifactDelete
+	case protocol.TaskMessageEvent:
+		eventType = protocol.EventTaskMessage
+	default:
+		return nil, fmt.Errorf("unsupported event type: %T", event)
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	params := protocol.PushNotificationParams{ID: taskID, EventType: eventType, Event: eventJSON}
+	if delivery, ok := PushDeliveryFromContext(ctx); ok {
+		params.DeliveryID = delivery.ID
+		params.Sequence = delivery.Sequence
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "tasks/notifyEvent",
+		"params":  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	return body, nil
+}
+
+// PushDeduplicator helps a webhook receiver apply the dedup contract of
+// protocol.PushNotificationParams: it remembers the delivery IDs of recent
+// notifications, to spot retried ones, and the last sequence number of each task,
+// to spot lost ones. Use one per webhook. It is safe for concurrent use.
+type PushDeduplicator struct {
+	mu        sync.Mutex
+	seen      map[string]struct{}
+	order     []string // Ring of the remembered delivery IDs, oldest at next.
+	next      int
+	sequences map[string]int64 // Last sequence number checked per task.
+}
+
+// NewPushDeduplicator creates a PushDeduplicator remembering the last size delivery
+// IDs. A size of zero or less defaults to 1024.
+func NewPushDeduplicator(size int) *PushDeduplicator {
+	if size <= 0 {
+		size = defaultPushDedupSize
+	}
+	return &PushDeduplicator{
+		seen:      make(map[string]struct{}, size),
+		order:     make([]string, 0, size),
+		sequences: make(map[string]int64),
+	}
+}
+
+// Check records a notification and reports whether it duplicates one checked
+// before, in which case it should be ignored, and how many events of its task
+// were skipped since the last one checked. A duplicate reports no missed events.
+// Notifications without a delivery ID or sequence number are not tracked by it.
+func (d *PushDeduplicator) Check(params protocol.PushNotificationParams) (duplicate bool, missed int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if params.DeliveryID != "" {
+		if _, ok := d.seen[params.DeliveryID]; ok {
+			return true, 0
+		}
+		d.remember(params.DeliveryID)
+	}
+	if params.Sequence > 0 {
+		last := d.sequences[params.ID]
+		if params.Sequence > last {
+			missed = params.Sequence - last - 1
+			d.sequences[params.ID] = params.Sequence
+		}
+	}
+	return false, missed
+}
+
+// Forget drops the sequence number kept for taskID, once the task has ended.
+func (d *PushDeduplicator) Forget(taskID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.sequences, taskID)
+}
+
+// remember adds id to the remembered delivery IDs, forgetting the oldest one if
+// they are at capacity. d.mu must be held.
+func (d *PushDeduplicator) remember(id string) {
+	if len(d.order) < cap(d.order) {
+		d.order = append(d.order, id)
+	} else {
+		delete(d.seen, d.order[d.next])
+		d.order[d.next] = id
+		d.next = (d.next + 1) % len(d.order)
+	}
+	d.seen[id] = struct{}{}
+}
@@ -0,0 +1,396 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+const (
+	// defaultPushQueueSize is the default number of events queued per webhook.
+	defaultPushQueueSize = 64
+	// defaultPushMaxInFlight is the default number of deliveries running at once.
+	defaultPushMaxInFlight = 4
+	// defaultPushTimeout bounds a single delivery of the default HTTP sender.
+	defaultPushTimeout = 10 * time.Second
+)
+
+// ErrPushQueueClosed is returned by PushQueue.Enqueue after Close.
+var ErrPushQueueClosed = errors.New("push queue closed")
+
+// PushSender delivers a single task event to the webhook in config. The context
+// given by a PushQueue carries the PushDelivery being attempted.
+type PushSender func(
+	ctx context.Context,
+	taskID string,
+	config protocol.PushNotificationConfig,
+	event protocol.TaskEvent,
+) error
+
+// PushQueueOption configures a PushQueue.
+type PushQueueOption func(*PushQueue)
+
+// WithPushQueueSize sets how many events may wait per webhook. Defaults to 64.
+func WithPushQueueSize(size int) PushQueueOption {
+	return func(q *PushQueue) {
+		if size > 0 {
+			q.size = size
+		}
+	}
+}
+
+// WithPushMaxInFlight sets how many deliveries may run at once across all
+// webhooks. Each webhook still gets its events one at a time, in order. Defaults to 4.
+func WithPushMaxInFlight(n int) PushQueueOption {
+	return func(q *PushQueue) {
+		if n > 0 {
+			q.maxInFlight = n
+		}
+	}
+}
+
+// WithPushRetry makes the queue attempt a delivery that fails up to maxAttempts
+// times, waiting backoff before the second attempt and twice as long before each
+// one after it. Every attempt carries the same delivery ID, so a receiver can
+// ignore the ones it already handled. Deliveries are attempted once by default.
+func WithPushRetry(maxAttempts int, backoff time.Duration) PushQueueOption {
+	return func(q *PushQueue) {
+		if maxAttempts > 0 {
+			q.maxAttempts = maxAttempts
+			q.backoff = backoff
+		}
+	}
+}
+
+// PushQueue delivers push notifications through a bounded queue per webhook URL,
+// so a slow endpoint neither receives a burst of requests nor holds up others.
+//
+// A status update waiting behind another status update of the same task
+// replaces it, so a webhook that falls behind only gets the latest intermediate
+// status. When a webhook's queue is full, intermediate status updates are
+// dropped, while artifact, message and terminal status events make Enqueue wait
+// for room instead: they are never coalesced away or dropped.
+//
+// Each delivery is given a PushDelivery with a random ID and the next sequence
+// number of its task and webhook, so a receiver can ignore duplicates and spot
+// events lost after all attempts failed, see protocol.PushNotificationParams.
+// It is safe for concurrent use.
+type PushQueue struct {
+	sender      PushSender
+	size        int
+	maxInFlight int
+	maxAttempts int
+	backoff     time.Duration
+	inFlight    chan struct{} // Semaphore bounding concurrent deliveries.
+
+	ctx    context.Context // Canceled when Close gives up waiting.
+	cancel context.CancelFunc
+	wg     sync.WaitGroup // Tracks the webhook workers.
+
+	mu        sync.Mutex
+	closed    bool
+	webhooks  map[string]*webhookQueue
+	sequences map[pushStream]int64 // Last sequence number of each task and webhook.
+}
+
+// pushStream identifies the events of one task delivered to one webhook.
+type pushStream struct {
+	url    string
+	taskID string
+}
+
+// webhookQueue holds the events waiting for one webhook.
+type webhookQueue struct {
+	pending []pushItem
+	running bool          // A worker is draining pending.
+	room    chan struct{} // Closed and replaced whenever an event leaves pending.
+}
+
+// pushItem is an event waiting for delivery.
+type pushItem struct {
+	taskID string
+	config protocol.PushNotificationConfig
+	event  protocol.TaskEvent
+}
+
+// NewPushQueue creates a PushQueue delivering events with sender.
+// Use NewHTTPPushSender for the standard tasks/notifyEvent webhook call.
+func NewPushQueue(sender PushSender, opts ...PushQueueOption) (*PushQueue, error) {
+	if sender == nil {
+		return nil, errors.New("push sender cannot be nil")
+	}
+	q := &PushQueue{
+		sender:      sender,
+		size:        defaultPushQueueSize,
+		maxInFlight: defaultPushMaxInFlight,
+		maxAttempts: 1,
+		webhooks:    make(map[string]*webhookQueue),
+		sequences:   make(map[pushStream]int64),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	q.inFlight = make(chan struct{}, q.maxInFlight)
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	return q, nil
+}
+
+// Enqueue queues event for delivery to the webhook in config. It returns once the
+// event is queued, coalesced or, for an intermediate status update hitting a full
+// queue, dropped. An event that must be delivered waits for room until ctx is done.
+func (q *PushQueue) Enqueue(
+	ctx context.Context,
+	taskID string,
+	config protocol.PushNotificationConfig,
+	event protocol.TaskEvent,
+) error {
+	item := pushItem{taskID: taskID, config: config, event: event}
+	q.mu.Lock()
+	for {
+		if q.closed {
+			q.mu.Unlock()
+			return ErrPushQueueClosed
+		}
+		wq, ok := q.webhooks[config.URL]
+		if !ok {
+			wq = &webhookQueue{room: make(chan struct{})}
+			q.webhooks[config.URL] = wq
+		}
+		if wq.add(item, q.size) {
+			q.startWorker(config.URL, wq)
+			q.mu.Unlock()
+			return nil
+		}
+		if isIntermediateStatus(event) {
+			q.mu.Unlock()
+			log.Warnf("Push queue for %s is full; dropping intermediate status of task %s", config.URL, taskID)
+			return nil
+		}
+		// Wait for the worker to make room.
+		room := wq.room
+		q.mu.Unlock()
+		select {
+		case <-room:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		q.mu.Lock()
+	}
+}
+
+// add queues item unless the queue is full, coalescing it with a status update of
+// the same task waiting right before it. It reports whether item was taken.
+func (wq *webhookQueue) add(item pushItem, size int) bool {
+	if _, isStatus := item.event.(protocol.TaskStatusUpdateEvent); isStatus && len(wq.pending) > 0 {
+		last := &wq.pending[len(wq.pending)-1]
+		if last.taskID == item.taskID && isIntermediateStatus(last.event) {
+			*last = item
+			return true
+		}
+	}
+	if len(wq.pending) < size {
+		wq.pending = append(wq.pending, item)
+		return true
+	}
+	// Make room by dropping an intermediate status, which a later one supersedes.
+	if !isIntermediateStatus(item.event) {
+		for i, pending := range wq.pending {
+			if isIntermediateStatus(pending.event) {
+				wq.pending = append(wq.pending[:i], wq.pending[i+1:]...)
+				wq.pending = append(wq.pending, item)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startWorker starts draining wq unless a worker already is. q.mu must be held.
+func (q *PushQueue) startWorker(url string, wq *webhookQueue) {
+	if wq.running {
+		return
+	}
+	wq.running = true
+	q.wg.Add(1)
+	go q.drain(url, wq)
+}
+
+// drain delivers the events of one webhook in order until none are left.
+func (q *PushQueue) drain(url string, wq *webhookQueue) {
+	defer q.wg.Done()
+	for {
+		q.mu.Lock()
+		if len(wq.pending) == 0 {
+			wq.running = false
+			delete(q.webhooks, url)
+			q.mu.Unlock()
+			return
+		}
+		item := wq.pending[0]
+		wq.pending = wq.pending[1:]
+		close(wq.room)
+		wq.room = make(chan struct{})
+		delivery := PushDelivery{ID: newDeliveryID(), Sequence: q.nextSequence(url, item)}
+		q.mu.Unlock()
+
+		if q.ctx.Err() != nil {
+			continue // Close gave up: drop what is left without delivering it.
+		}
+		q.deliver(url, item, delivery)
+	}
+}
+
+// nextSequence returns the sequence number of item, forgetting the task's
+// numbering once its final status is delivered. q.mu must be held.
+func (q *PushQueue) nextSequence(url string, item pushItem) int64 {
+	stream := pushStream{url: url, taskID: item.taskID}
+	sequence := q.sequences[stream] + 1
+	if statusEvent, ok := item.event.(protocol.TaskStatusUpdateEvent); ok &&
+		(statusEvent.Final || statusEvent.Status.State.IsFinal()) {
+		delete(q.sequences, stream)
+	} else {
+		q.sequences[stream] = sequence
+	}
+	return sequence
+}
+
+// deliver sends item to url, attempting it again while it fails and attempts are
+// left. It gives up when Close does.
+func (q *PushQueue) deliver(url string, item pushItem, delivery PushDelivery) {
+	for delivery.Attempt = 1; ; delivery.Attempt++ {
+		select {
+		case q.inFlight <- struct{}{}:
+		case <-q.ctx.Done():
+			return
+		}
+		err := q.sender(withPushDelivery(q.ctx, delivery), item.taskID, item.config, item.event)
+		<-q.inFlight
+		if err == nil {
+			return
+		}
+		if delivery.Attempt >= q.maxAttempts || q.ctx.Err() != nil {
+			log.Errorf("Failed to push %T for task %s to %s: %v", item.event, item.taskID, url, err)
+			return
+		}
+		log.Debugf("Push of %T for task %s to %s failed, retrying: %v", item.event, item.taskID, url, err)
+		timer := time.NewTimer(q.backoff << (delivery.Attempt - 1))
+		select {
+		case <-timer.C:
+		case <-q.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be delivered.
+// If ctx is done first, deliveries in progress are canceled, the rest are
+// dropped, and ctx's error is returned.
+func (q *PushQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		// Wake callers waiting for room so they see the queue is closed.
+		for _, wq := range q.webhooks {
+			close(wq.room)
+			wq.room = make(chan struct{})
+		}
+	}
+	q.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// isIntermediateStatus reports whether event is a non-terminal status update.
+func isIntermediateStatus(event protocol.TaskEvent) bool {
+	statusEvent, ok := event.(protocol.TaskStatusUpdateEvent)
+	return ok && !statusEvent.Final && !statusEvent.Status.State.IsFinal()
+}
+
+// PushSigner signs push notification payloads. It is implemented by
+// auth.PushNotificationAuthenticator, whose signing keys can be rotated while in use.
+type PushSigner interface {
+	// CreateAuthorizationHeader returns the Authorization header value signing payload.
+	CreateAuthorizationHeader(payload []byte) (string, error)
+}
+
+// NewHTTPPushSender returns a PushSender that posts each event to the webhook as a
+// tasks/notifyEvent JSON-RPC notification, sending the config token as a bearer
+// token. A nil client uses one with a 10 second timeout.
+func NewHTTPPushSender(client *http.Client) PushSender {
+	return NewSignedHTTPPushSender(client, nil)
+}
+
+// NewSignedHTTPPushSender is like NewHTTPPushSender, but signs each notification
+// with signer: the Authorization header carries the signature, and the config token,
+// if any, moves to the HeaderNotificationToken header. A nil signer sends unsigned
+// notifications.
+func NewSignedHTTPPushSender(client *http.Client, signer PushSigner) PushSender {
+	if client == nil {
+		client = &http.Client{Timeout: defaultPushTimeout}
+	}
+	return func(
+		ctx context.Context,
+		taskID string,
+		config protocol.PushNotificationConfig,
+		event protocol.TaskEvent,
+	) error {
+		body, err := MarshalPushNotification(ctx, taskID, event)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create notification request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if signer != nil {
+			authHeader, err := signer.CreateAuthorizationHeader(body)
+			if err != nil {
+				return fmt.Errorf("failed to sign notification: %w", err)
+			}
+			req.Header.Set("Authorization", authHeader)
+			if config.Token != "" {
+				req.Header.Set(protocol.HeaderNotificationToken, config.Token)
+			}
+		} else if config.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+config.Token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to send notification: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			respBody, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("notification failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,539 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// slowWebhook is a PushSender that holds every delivery until released.
+type slowWebhook struct {
+	started chan string   // Receives the label of each delivery as it starts.
+	release chan struct{} // Each receive lets one delivery finish.
+
+	mu         sync.Mutex
+	delivered  []string
+	inFlight   int
+	maxFlights int
+}
+
+func newSlowWebhook() *slowWebhook {
+	return &slowWebhook{started: make(chan string, 100), release: make(chan struct{})}
+}
+
+func (w *slowWebhook) send(
+	ctx context.Context, taskID string, config protocol.PushNotificationConfig, event protocol.TaskEvent,
+) error {
+	w.mu.Lock()
+	w.inFlight++
+	w.maxFlights = max(w.maxFlights, w.inFlight)
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.inFlight--
+		w.mu.Unlock()
+	}()
+	label := eventLabel(event)
+	w.started <- label
+	select {
+	case <-w.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	w.mu.Lock()
+	w.delivered = append(w.delivered, label)
+	w.mu.Unlock()
+	return nil
+}
+
+// waitStarted waits for the delivery of label to start.
+func (w *slowWebhook) waitStarted(t *testing.T, label string) {
+	t.Helper()
+	select {
+	case got := <-w.started:
+		require.Equal(t, label, got)
+	case <-time.After(time.Second):
+		t.Fatalf("delivery of %s did not start", label)
+	}
+}
+
+func (w *slowWebhook) deliveries() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.delivered...)
+}
+
+// statusEvent returns a status update tagged with label through its timestamp.
+func statusEvent(label string, state protocol.TaskState) protocol.TaskStatusUpdateEvent {
+	return protocol.TaskStatusUpdateEvent{
+		ID:     "task",
+		Status: protocol.TaskStatus{State: state, Timestamp: label},
+		Final:  state.IsFinal(),
+	}
+}
+
+// artifactEvent returns an artifact update tagged with label through its name.
+func artifactEvent(label string) protocol.TaskArtifactUpdateEvent {
+	return protocol.TaskArtifactUpdateEvent{ID: "task", Artifact: protocol.Artifact{Name: &label}}
+}
+
+func eventLabel(event protocol.TaskEvent) string {
+	switch e := event.(type) {
+	case protocol.TaskStatusUpdateEvent:
+		return e.Status.Timestamp
+	case protocol.TaskArtifactUpdateEvent:
+		return *e.Artifact.Name
+	}
+	return fmt.Sprintf("%T", event)
+}
+
+func TestPushQueue_CoalescesStatusUpdates(t *testing.T) {
+	webhook := newSlowWebhook()
+	queue, err := NewPushQueue(webhook.send)
+	require.NoError(t, err)
+	config := protocol.PushNotificationConfig{URL: "https://example.com/webhook"}
+	ctx := context.Background()
+
+	require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("s0", protocol.TaskStateWorking)))
+	webhook.waitStarted(t, "s0")
+	// The webhook is stuck on s0; s1..s5 collapse into s5, but artifacts split the run.
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent(fmt.Sprintf("s%d", i), protocol.TaskStateWorking)))
+	}
+	require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a1")))
+	require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("s6", protocol.TaskStateWorking)))
+	require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("s7", protocol.TaskStateWorking)))
+	require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a2")))
+	require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("s8", protocol.TaskStateWorking)))
+	require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("done", protocol.TaskStateCompleted)))
+
+	want := []string{"s0", "s5", "a1", "s7", "a2", "done"}
+	for i, label := range want {
+		if i > 0 {
+			webhook.waitStarted(t, label)
+		}
+		webhook.release <- struct{}{}
+	}
+	require.NoError(t, queue.Close(ctx))
+	assert.Equal(t, want, webhook.deliveries())
+}
+
+func TestPushQueue_FullQueue(t *testing.T) {
+	config := protocol.PushNotificationConfig{URL: "https://example.com/webhook"}
+	ctx := context.Background()
+
+	t.Run("DropsIntermediateAndBlocksTerminal", func(t *testing.T) {
+		webhook := newSlowWebhook()
+		queue, err := NewPushQueue(webhook.send, WithPushQueueSize(2))
+		require.NoError(t, err)
+
+		require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("s0", protocol.TaskStateWorking)))
+		webhook.waitStarted(t, "s0")
+		require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a1")))
+		require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a2")))
+		// Full of artifacts: the intermediate status is dropped without waiting.
+		require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("s1", protocol.TaskStateWorking)))
+
+		// The terminal status waits for room instead.
+		enqueued := make(chan error, 1)
+		go func() {
+			enqueued <- queue.Enqueue(ctx, "task", config, statusEvent("done", protocol.TaskStateCompleted))
+		}()
+		select {
+		case err := <-enqueued:
+			t.Fatalf("terminal status enqueued into a full queue: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, queue.Enqueue(shortCtx, "task", config, artifactEvent("a3")), context.DeadlineExceeded)
+
+		webhook.release <- struct{}{}
+		require.NoError(t, <-enqueued)
+		for _, label := range []string{"a1", "a2", "done"} {
+			webhook.waitStarted(t, label)
+			webhook.release <- struct{}{}
+		}
+		require.NoError(t, queue.Close(ctx))
+		assert.Equal(t, []string{"s0", "a1", "a2", "done"}, webhook.deliveries())
+	})
+
+	t.Run("EvictsIntermediateForTerminal", func(t *testing.T) {
+		webhook := newSlowWebhook()
+		queue, err := NewPushQueue(webhook.send, WithPushQueueSize(2))
+		require.NoError(t, err)
+
+		require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("s0", protocol.TaskStateWorking)))
+		webhook.waitStarted(t, "s0")
+		require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("s1", protocol.TaskStateWorking)))
+		require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a1")))
+		// The queue is full, so the pending s1 makes way for the terminal status.
+		require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("done", protocol.TaskStateFailed)))
+
+		webhook.release <- struct{}{}
+		for _, label := range []string{"a1", "done"} {
+			webhook.waitStarted(t, label)
+			webhook.release <- struct{}{}
+		}
+		require.NoError(t, queue.Close(ctx))
+		assert.Equal(t, []string{"s0", "a1", "done"}, webhook.deliveries())
+	})
+}
+
+func TestPushQueue_MaxInFlight(t *testing.T) {
+	webhook := newSlowWebhook()
+	queue, err := NewPushQueue(webhook.send, WithPushMaxInFlight(1))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	urls := []string{"https://a.example.com", "https://b.example.com"}
+	for _, url := range urls {
+		config := protocol.PushNotificationConfig{URL: url}
+		for i := 0; i < 3; i++ {
+			require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent(fmt.Sprintf("%s/%d", url, i))))
+		}
+	}
+	for i := 0; i < 6; i++ {
+		select {
+		case <-webhook.started:
+		case <-time.After(time.Second):
+			t.Fatalf("delivery %d did not start", i)
+		}
+		// Give a second delivery the chance to start if the limit were not enforced.
+		time.Sleep(10 * time.Millisecond)
+		webhook.release <- struct{}{}
+	}
+	require.NoError(t, queue.Close(ctx))
+	assert.Len(t, webhook.deliveries(), 6)
+	webhook.mu.Lock()
+	defer webhook.mu.Unlock()
+	assert.Equal(t, 1, webhook.maxFlights)
+}
+
+func TestPushQueue_Close(t *testing.T) {
+	config := protocol.PushNotificationConfig{URL: "https://example.com/webhook"}
+	ctx := context.Background()
+
+	t.Run("RejectsEnqueue", func(t *testing.T) {
+		queue, err := NewPushQueue(newSlowWebhook().send)
+		require.NoError(t, err)
+		require.NoError(t, queue.Close(ctx))
+		err = queue.Enqueue(ctx, "task", config, artifactEvent("a1"))
+		assert.ErrorIs(t, err, ErrPushQueueClosed)
+	})
+
+	t.Run("TimesOut", func(t *testing.T) {
+		webhook := newSlowWebhook()
+		queue, err := NewPushQueue(webhook.send, WithPushQueueSize(1))
+		require.NoError(t, err)
+		require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a1")))
+		webhook.waitStarted(t, "a1")
+		require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a2")))
+		// A caller waiting for room is released by Close.
+		enqueued := make(chan error, 1)
+		go func() {
+			enqueued <- queue.Enqueue(ctx, "task", config, artifactEvent("a3"))
+		}()
+
+		closeCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, queue.Close(closeCtx), context.DeadlineExceeded)
+		assert.ErrorIs(t, <-enqueued, ErrPushQueueClosed)
+		assert.Empty(t, webhook.deliveries())
+	})
+
+	t.Run("NilSender", func(t *testing.T) {
+		_, err := NewPushQueue(nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestPushQueue_WithPushRetry(t *testing.T) {
+	type check struct {
+		label     string
+		sequence  int64
+		duplicate bool
+		missed    int64
+	}
+	var (
+		mu     sync.Mutex
+		checks []check
+	)
+	dedup := NewPushDeduplicator(0)
+	done := make(chan struct{})
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification struct {
+			Params protocol.PushNotificationParams `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		params := notification.Params
+		var event struct {
+			Artifact protocol.Artifact   `json:"artifact"`
+			Status   protocol.TaskStatus `json:"status"`
+		}
+		require.NoError(t, json.Unmarshal(params.Event, &event))
+		label := event.Status.Timestamp
+		if event.Artifact.Name != nil {
+			label = *event.Artifact.Name
+		}
+		duplicate, missed := dedup.Check(params)
+		mu.Lock()
+		checks = append(checks, check{label, params.Sequence, duplicate, missed})
+		mu.Unlock()
+		if label == "done" {
+			close(done)
+		}
+		// The first delivery of a2 is handled, but its reply is lost.
+		if label == "a2" && !duplicate {
+			http.Error(w, "lost reply", http.StatusServiceUnavailable)
+		}
+	}))
+	defer webhook.Close()
+
+	queue, err := NewPushQueue(NewHTTPPushSender(nil), WithPushRetry(3, time.Millisecond))
+	require.NoError(t, err)
+	ctx := context.Background()
+	config := protocol.PushNotificationConfig{URL: webhook.URL}
+	require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a1")))
+	require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a2")))
+	require.NoError(t, queue.Enqueue(ctx, "task", config, statusEvent("done", protocol.TaskStateCompleted)))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the final status")
+	}
+	require.NoError(t, queue.Close(ctx))
+
+	assert.Equal(t, []check{
+		{"a1", 1, false, 0},
+		{"a2", 2, false, 0},
+		{"a2", 2, true, 0}, // The retry is flagged as a duplicate.
+		{"done", 3, false, 0},
+	}, checks)
+}
+
+func TestPushQueue_DeliveryContext(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		deliveries []PushDelivery
+	)
+	failures := 2
+	queue, err := NewPushQueue(func(
+		ctx context.Context, taskID string, config protocol.PushNotificationConfig, event protocol.TaskEvent,
+	) error {
+		delivery, ok := PushDeliveryFromContext(ctx)
+		require.True(t, ok)
+		mu.Lock()
+		defer mu.Unlock()
+		deliveries = append(deliveries, delivery)
+		if failures > 0 {
+			failures--
+			return fmt.Errorf("unavailable")
+		}
+		return nil
+	}, WithPushRetry(2, time.Millisecond))
+	require.NoError(t, err)
+	ctx := context.Background()
+	config := protocol.PushNotificationConfig{URL: "https://example.com/webhook"}
+	require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a1")))
+	require.NoError(t, queue.Enqueue(ctx, "task", config, artifactEvent("a2")))
+	require.NoError(t, queue.Close(ctx))
+
+	require.Len(t, deliveries, 3)
+	// a1 failed twice and was given up; a2 was delivered at its first attempt.
+	assert.Equal(t, deliveries[0].ID, deliveries[1].ID)
+	assert.Equal(t, []int{1, 2, 1}, []int{deliveries[0].Attempt, deliveries[1].Attempt, deliveries[2].Attempt})
+	assert.NotEqual(t, deliveries[0].ID, deliveries[2].ID)
+	assert.Equal(t, int64(1), deliveries[0].Sequence)
+	assert.Equal(t, int64(2), deliveries[2].Sequence)
+
+	_, ok := PushDeliveryFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestPushDeduplicator(t *testing.T) {
+	notification := func(id string, sequence int64) protocol.PushNotificationParams {
+		return protocol.PushNotificationParams{ID: "task", DeliveryID: id, Sequence: sequence}
+	}
+
+	t.Run("DetectsGaps", func(t *testing.T) {
+		dedup := NewPushDeduplicator(0)
+		duplicate, missed := dedup.Check(notification("d1", 1))
+		assert.False(t, duplicate)
+		assert.Zero(t, missed)
+		duplicate, missed = dedup.Check(notification("d4", 4))
+		assert.False(t, duplicate)
+		assert.Equal(t, int64(2), missed)
+		duplicate, missed = dedup.Check(notification("d4", 4))
+		assert.True(t, duplicate)
+		assert.Zero(t, missed)
+
+		// A new numbering after the task is forgotten is not a gap.
+		dedup.Forget("task")
+		_, missed = dedup.Check(notification("e1", 1))
+		assert.Zero(t, missed)
+	})
+
+	t.Run("RemembersRecentIDs", func(t *testing.T) {
+		dedup := NewPushDeduplicator(2)
+		for _, id := range []string{"d1", "d2", "d3"} {
+			duplicate, _ := dedup.Check(notification(id, 0))
+			assert.False(t, duplicate)
+		}
+		duplicate, _ := dedup.Check(notification("d3", 0))
+		assert.True(t, duplicate)
+		// d1 was forgotten to make room for d3.
+		duplicate, _ = dedup.Check(notification("d1", 0))
+		assert.False(t, duplicate)
+	})
+
+	t.Run("UntrackedWithoutDeliveryID", func(t *testing.T) {
+		dedup := NewPushDeduplicator(0)
+		duplicate, _ := dedup.Check(notification("", 0))
+		assert.False(t, duplicate)
+		duplicate, _ = dedup.Check(notification("", 0))
+		assert.False(t, duplicate)
+	})
+}
+
+func TestNewHTTPPushSender(t *testing.T) {
+	var (
+		gotAuth string
+		gotBody map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "nope", http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	send := NewHTTPPushSender(nil)
+	config := protocol.PushNotificationConfig{URL: server.URL, Token: "secret"}
+	err := send(context.Background(), "task-1", config, statusEvent("s0", protocol.TaskStateWorking))
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer secret", gotAuth)
+	assert.Equal(t, "tasks/notifyEvent", gotBody["method"])
+	params := gotBody["params"].(map[string]interface{})
+	assert.Equal(t, "task-1", params["id"])
+	assert.Equal(t, protocol.EventTaskStatusUpdate, params["eventType"])
+
+	config.URL = server.URL + "/fail"
+	err = send(context.Background(), "task-1", config, artifactEvent("a1"))
+	assert.ErrorContains(t, err, "status 502")
+}
+
+func TestNewSignedHTTPPushSender(t *testing.T) {
+	signer := auth.NewPushNotificationAuthenticator()
+	require.NoError(t, signer.GenerateKeyPair())
+	jwksServer := httptest.NewServer(http.HandlerFunc(signer.HandleJWKS))
+	defer jwksServer.Close()
+	jwksClient := auth.NewJWKSClient(jwksServer.URL, time.Hour)
+	jwksClient.SetMinRefreshInterval(0)
+	verifier := auth.NewPushNotificationAuthenticator()
+	verifier.UseJWKSClient(jwksClient)
+
+	var (
+		verifyErr error
+		gotToken  string
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		verifyErr = verifier.VerifyPushNotification(r, body)
+		gotToken = r.Header.Get(protocol.HeaderNotificationToken)
+	}))
+	defer webhook.Close()
+
+	send := NewSignedHTTPPushSender(nil, signer)
+	config := protocol.PushNotificationConfig{URL: webhook.URL, Token: "secret"}
+	require.NoError(t, send(context.Background(), "task-1", config, statusEvent("s0", protocol.TaskStateWorking)))
+	assert.NoError(t, verifyErr)
+	assert.Equal(t, "secret", gotToken, "the config token moves out of the Authorization header")
+
+	// Deliveries after a rotation are signed with the new key and still verify.
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	require.NoError(t, signer.AddSigningKey("rotated", newKey))
+	require.NoError(t, send(context.Background(), "task-1", config, artifactEvent("a1")))
+	assert.NoError(t, verifyErr)
+}
+
+func TestMemoryTaskManager_PushQueue(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		delivered []protocol.TaskEvent
+	)
+	queue, err := NewPushQueue(func(
+		ctx context.Context, taskID string, config protocol.PushNotificationConfig, event protocol.TaskEvent,
+	) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, event)
+		return nil
+	})
+	require.NoError(t, err)
+
+	processor := &mockProcessor{
+		processFunc: func(ctx context.Context, taskID string, msg protocol.Message, handle TaskHandle) error {
+			if err := handle.AddArtifact(protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart("result")}}); err != nil {
+				return err
+			}
+			return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+		},
+	}
+	tm, err := NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+	tm.PushQueue = queue
+	tm.PushNotifications["push-task"] = protocol.PushNotificationConfig{URL: "https://example.com/webhook"}
+
+	_, err = tm.OnSendTask(context.Background(), protocol.SendTaskParams{
+		ID:      "push-task",
+		Message: protocol.Message{Role: protocol.MessageRoleUser, Parts: []protocol.Part{protocol.NewTextPart("hi")}},
+	})
+	require.NoError(t, err)
+	_, err = tm.OnSendTask(context.Background(), protocol.SendTaskParams{
+		ID:      "no-push-task",
+		Message: protocol.Message{Role: protocol.MessageRoleUser, Parts: []protocol.Part{protocol.NewTextPart("hi")}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, queue.Close(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, delivered)
+	var artifacts int
+	for _, event := range delivered {
+		if _, ok := event.(protocol.TaskArtifactUpdateEvent); ok {
+			artifacts++
+		}
+	}
+	assert.Equal(t, 1, artifacts)
+	last, ok := delivered[len(delivered)-1].(protocol.TaskStatusUpdateEvent)
+	require.True(t, ok)
+	assert.Equal(t, "push-task", last.ID)
+	assert.Equal(t, protocol.TaskStateCompleted, last.Status.State)
+}
@@ -0,0 +1,201 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// maxStreamEvents is the number of latest events the event log of
+// WithPersistentEventLog keeps per task.
+const maxStreamEvents = 1000
+
+// streamLockCount is the number of locks the event logs of the tasks are spread
+// over, so that the events of different tasks are rarely serialized.
+const streamLockCount = 64
+
+// streamLock returns the lock serializing the event log of taskID with the
+// notifications of its subscribers.
+func (m *TaskManager) streamLock(taskID string) *sync.Mutex {
+	hash := fnv.New32a()
+	hash.Write([]byte(taskID))
+	return &m.streamMus[hash.Sum32()%streamLockCount]
+}
+
+// storedEvent is a task event as kept in the event log.
+type storedEvent struct {
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// encodeStreamEvent returns the event log entry of event.
+func encodeStreamEvent(event protocol.TaskEvent) ([]byte, error) {
+	var eventType string
+	switch event.(type) {
+	case protocol.TaskStatusUpdateEvent:
+		eventType = protocol.EventTaskStatusUpdate
+	case protocol.TaskArtifactUpdateEvent:
+		eventType = protocol.EventTaskArtifactUpdate
+	case protocol.TaskArtifactPatchEvent:
+		eventType = protocol.EventTaskArtifactPatch
+	case protocol.TaskArtifactDeleteEvent:
+		eventType = protocol.EventTaskArtifactDelete
+	case protocol.TaskMessageEvent:
+		eventType = protocol.EventTaskMessage
+	default:
+		return nil, fmt.Errorf("unsupported event type: %T", event)
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(storedEvent{Type: eventType, Event: eventJSON})
+}
+
+// decodeStreamEvent returns the event of an event log entry.
+func decodeStreamEvent(data []byte) (protocol.TaskEvent, error) {
+	var stored storedEvent
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	var event protocol.TaskEvent
+	var err error
+	switch stored.Type {
+	case protocol.EventTaskStatusUpdate:
+		var statusEvent protocol.TaskStatusUpdateEvent
+		err = json.Unmarshal(stored.Event, &statusEvent)
+		event = statusEvent
+	case protocol.EventTaskArtifactUpdate:
+		var artifactEvent protocol.TaskArtifactUpdateEvent
+		err = json.Unmarshal(stored.Event, &artifactEvent)
+		event = artifactEvent
+	case protocol.EventTaskArtifactPatch:
+		var patchEvent protocol.TaskArtifactPatchEvent
+		err = json.Unmarshal(stored.Event, &patchEvent)
+		event = patchEvent
+	case protocol.EventTaskArtifactDelete:
+		var deleteEvent protocol.TaskArtifactDeleteEvent
+		err = json.Unmarshal(stored.Event, &deleteEvent)
+		event = deleteEvent
+	case protocol.EventTaskMessage:
+		var messageEvent protocol.TaskMessageEvent
+		err = json.Unmarshal(stored.Event, &messageEvent)
+		event = messageEvent
+	default:
+		return nil, fmt.Errorf("unsupported event type: %q", stored.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// appendStreamEvent appends event to the task's event log, keeping the latest
+// maxStreamEvents, in one MULTI transaction. The stream lock of taskID must be
+// held.
+func (m *TaskManager) appendStreamEvent(ctx context.Context, taskID string, event protocol.TaskEvent) {
+	streamKey := streamPrefix + taskID
+	eventBytes, err := encodeStreamEvent(event)
+	if err != nil {
+		log.Errorf("Failed to serialize event for task %s: %v", taskID, err)
+		return
+	}
+	if _, err := m.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, streamKey, eventBytes)
+		pipe.LTrim(ctx, streamKey, -maxStreamEvents, -1)
+		pipe.Expire(ctx, streamKey, m.expiration)
+		return nil
+	}); err != nil {
+		log.Errorf("Failed to store event for task %s in Redis: %v", taskID, err)
+	}
+}
+
+// getStreamEvents retrieves the event log of a task.
+func (m *TaskManager) getStreamEvents(ctx context.Context, taskID string) ([]protocol.TaskEvent, error) {
+	eventsRaw, err := m.client.LRange(ctx, streamPrefix+taskID, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve events: %w", err)
+	}
+	events := make([]protocol.TaskEvent, 0, len(eventsRaw))
+	for _, eventBytes := range eventsRaw {
+		event, err := decodeStreamEvent([]byte(eventBytes))
+		if err != nil {
+			log.Errorf("Failed to deserialize event for task %s: %v", taskID, err)
+			continue // Skip invalid events.
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// resubscribeFromLog replays the event log of task, then forwards its live events
+// until its final status. A task without a log starts from its current status.
+func (m *TaskManager) resubscribeFromLog(
+	ctx context.Context,
+	task *protocol.Task,
+) (<-chan protocol.TaskEvent, error) {
+	// Read the log and subscribe together, so each event is either replayed or
+	// received live.
+	live := make(chan protocol.TaskEvent, 10)
+	streamMu := m.streamLock(task.ID)
+	streamMu.Lock()
+	events, err := m.getStreamEvents(ctx, task.ID)
+	if err == nil {
+		m.addSubscriber(task.ID, live)
+	}
+	streamMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		events = append(events, protocol.TaskStatusUpdateEvent{
+			ID:     task.ID,
+			Status: task.Status,
+			Final:  task.Status.State.IsFinal(),
+		})
+	}
+	eventChan := make(chan protocol.TaskEvent, 10)
+	go func() {
+		defer close(eventChan)
+		defer m.removeSubscriber(task.ID, live)
+		send := func(event protocol.TaskEvent) bool {
+			select {
+			case eventChan <- event:
+				status, ok := event.(protocol.TaskStatusUpdateEvent)
+				return !ok || !status.Final
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for _, event := range events {
+			if !send(event) {
+				return
+			}
+		}
+		log.Debugf("Replayed %d events to resubscribed client for task %s", len(events), task.ID)
+		for {
+			select {
+			case event, ok := <-live:
+				if !ok || !send(event) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return eventChan, nil
+}
@@ -94,9 +94,7 @@ func pollTaskUntilDone(ctx context.Context, a2aClient *client.A2AClient, taskID
 			}
 
 			// Check if the task is done
-			if task.Status.State == protocol.TaskStateCompleted ||
-				task.Status.State == protocol.TaskStateFailed ||
-				task.Status.State == protocol.TaskStateCanceled {
+			if task.Status.State.IsFinal() {
 				return task, nil
 			}
 
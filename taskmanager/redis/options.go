@@ -8,6 +8,9 @@ package redis
 
 import (
 	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
 )
 
 // Option is a function that configures the RedisTaskManager.
@@ -19,3 +22,27 @@ func WithExpiration(expiration time.Duration) Option {
 		o.expiration = expiration
 	}
 }
+
+// WithPushDelivery delivers task events to the push notification webhook
+// configured for each task, through a taskmanager.PushQueue created with opts.
+// Close waits for queued notifications to be delivered.
+func WithPushDelivery(opts ...taskmanager.PushQueueOption) Option {
+	return func(o *TaskManager) {
+		queue, err := taskmanager.NewPushQueue(o.deliverPushNotification, opts...)
+		if err != nil {
+			log.Errorf("Failed to create push queue: %v", err)
+			return
+		}
+		o.pushQueue = queue
+	}
+}
+
+// WithPersistentEventLog stores the events sent to the subscribers of each task in
+// Redis, keeping the latest 1000, so that OnResubscribe replays them before the
+// live events, even from a manager started after the one that ran the task, e.g.
+// after a restart. Without it, a resubscribe starts from the task's current status.
+func WithPersistentEventLog() Option {
+	return func(o *TaskManager) {
+		o.persistEvents = true
+	}
+}
@@ -20,8 +20,28 @@ import (
 	"trpc.group/trpc-go/trpc-a2a-go/auth"
 	"trpc.group/trpc-go/trpc-a2a-go/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
 )
 
+// enqueuePush queues event for the task's push notification webhook, if any.
+func (m *TaskManager) enqueuePush(taskID string, event protocol.TaskEvent) {
+	if m.pushQueue == nil {
+		return
+	}
+	ctx := context.Background()
+	config, err := m.getPushNotificationConfig(ctx, taskID)
+	if err != nil {
+		log.Errorf("Failed to get push notification config for task %s: %v", taskID, err)
+		return
+	}
+	if config == nil {
+		return
+	}
+	if err := m.pushQueue.Enqueue(ctx, taskID, *config, event); err != nil {
+		log.Errorf("Failed to queue push notification for task %s: %v", taskID, err)
+	}
+}
+
 // getPushNotificationConfig retrieves a push notification configuration for a task.
 func (m *TaskManager) getPushNotificationConfig(
 	ctx context.Context, taskID string,
@@ -42,44 +62,14 @@ func (m *TaskManager) getPushNotificationConfig(
 	return &config, nil
 }
 
-// sendPushNotification sends a notification to the registered webhook URL.
-func (m *TaskManager) sendPushNotification(
-	ctx context.Context, taskID string, event protocol.TaskEvent,
+// deliverPushNotification sends an event to the webhook in config. It is the
+// taskmanager.PushSender behind WithPushDelivery.
+func (m *TaskManager) deliverPushNotification(
+	ctx context.Context, taskID string, config protocol.PushNotificationConfig, event protocol.TaskEvent,
 ) error {
-	// Get the notification config.
-	config, err := m.getPushNotificationConfig(ctx, taskID)
-	if err != nil {
-		return fmt.Errorf("failed to get push notification config: %w", err)
-	}
-	if config == nil {
-		// No push notification configured, nothing to do.
-		return nil
-	}
-
-	// Prepare the notification payload.
-	eventType := ""
-	if _, isStatus := event.(protocol.TaskStatusUpdateEvent); isStatus {
-		eventType = protocol.EventTaskStatusUpdate
-	} else if _, isArtifact := event.(protocol.TaskArtifactUpdateEvent); isArtifact {
-		eventType = protocol.EventTaskArtifactUpdate
-	} else {
-		return fmt.Errorf("unsupported event type: %T", event)
-	}
-
-	notification := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  "tasks/notifyEvent",
-		"params": map[string]interface{}{
-			"id":        taskID,
-			"eventType": eventType,
-			"event":     event,
-		},
-	}
-
-	// Marshal the notification to JSON.
-	body, err := json.Marshal(notification)
+	body, err := taskmanager.MarshalPushNotification(ctx, taskID, event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal notification: %w", err)
+		return err
 	}
 
 	// Create HTTP request.
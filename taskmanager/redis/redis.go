@@ -12,12 +12,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"trpc.group/trpc-go/trpc-a2a-go/auth"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/checksum"
 	"trpc.group/trpc-go/trpc-a2a-go/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
@@ -29,9 +32,14 @@ const (
 	messagePrefix          = "msg:"
 	pushNotificationPrefix = "push:"
 	subscriberPrefix       = "sub:"
+	labelPrefix            = "label:"
+	eventsPrefix           = "events:"
+	streamPrefix           = "stream:"
 
 	// Default expiration time for Redis keys (30 days).
 	defaultExpiration = 30 * 24 * time.Hour
+	// pushDrainTimeout bounds how long Close waits for queued push notifications.
+	pushDrainTimeout = 10 * time.Second
 )
 
 // TaskManager provides a concrete, Redis-based implementation of the
@@ -45,6 +53,8 @@ type TaskManager struct {
 	client redis.UniversalClient
 	// expiration is the time after which Redis keys expire.
 	expiration time.Duration
+	// maxHistoryBytes caps the encoded size of each task's history (0 is no cap).
+	maxHistoryBytes atomic.Int64
 
 	// subMu is a mutex for the Subscribers map.
 	subMu sync.RWMutex
@@ -54,12 +64,38 @@ type TaskManager struct {
 	// cancelMu is a mutex for the cancels map.
 	cancelMu sync.RWMutex
 	// cancels is a map of task IDs to cancellation functions.
-	cancels map[string]context.CancelFunc
+	// The cause passed to a function should be a *taskmanager.CancelError.
+	cancels map[string]context.CancelCauseFunc
 
 	// pushAuth is the push notification authenticator.
 	pushAuth *auth.PushNotificationAuthenticator
 	// pushAuthMu is a mutex for the pushAuth field.
 	pushAuthMu sync.Mutex
+	// pushQueue delivers push notifications; nil unless WithPushDelivery is used.
+	pushQueue *taskmanager.PushQueue
+
+	// waitMu is a mutex for the statusWaiters map.
+	waitMu sync.Mutex
+	// statusWaiters holds, per task, a channel closed on its next status change
+	// made by this process.
+	statusWaiters map[string]chan struct{}
+
+	// processorTimeout bounds how long a processor may run; see SetProcessorTimeout.
+	processorTimeout atomic.Int64
+	// maxArtifacts caps the distinct artifacts per task; see SetMaxArtifactsPerTask.
+	maxArtifacts atomic.Int64
+	// sendTimeout bounds how long an event waits for a slow subscriber; see
+	// SetSubscriberSendTimeout.
+	sendTimeout atomic.Int64
+	// watchdogs holds the watchdogs of streaming tasks, reset on each event.
+	watchdogs sync.Map
+
+	// persistEvents stores the events of each task for OnResubscribe to replay;
+	// see WithPersistentEventLog.
+	persistEvents bool
+	// streamMus serialize appending events to the event log of a task and
+	// notifying its subscribers with resubscribes reading the log; see streamLock.
+	streamMus [streamLockCount]sync.Mutex
 }
 
 // NewRedisTaskManager creates a new Redis-based TaskManager with the provided options.
@@ -77,11 +113,12 @@ func NewRedisTaskManager(
 	}
 	expiration := defaultExpiration
 	manager := &TaskManager{
-		processor:   processor,
-		client:      client,
-		expiration:  expiration,
-		subscribers: make(map[string][]chan<- protocol.TaskEvent),
-		cancels:     make(map[string]context.CancelFunc),
+		processor:     processor,
+		client:        client,
+		expiration:    expiration,
+		subscribers:   make(map[string][]chan<- protocol.TaskEvent),
+		cancels:       make(map[string]context.CancelCauseFunc),
+		statusWaiters: make(map[string]chan struct{}),
 	}
 	for _, opt := range opts {
 		opt(manager)
@@ -93,16 +130,172 @@ func NewRedisTaskManager(
 type redisTaskHandle struct {
 	taskID  string
 	manager *TaskManager
+	ctx     context.Context // The processor's context, if OnCancelTask can cancel it.
+
+	mu     sync.Mutex         // Serializes updates so none can slip in after Complete.
+	sealed protocol.TaskState // Set by Complete or a processor timeout; later updates are rejected.
+}
+
+// canceled reports whether OnCancelTask stopped the processor. Its updates are
+// then rejected so they cannot replace the canceled status.
+func (h *redisTaskHandle) canceled() bool {
+	if h.ctx == nil {
+		return false
+	}
+	_, ok := taskmanager.CancelReasonFromContext(h.ctx)
+	return ok
 }
 
 // UpdateStatus implements TaskHandle.
 func (h *redisTaskHandle) UpdateStatus(state protocol.TaskState, msg *protocol.Message) error {
-	return h.manager.UpdateTaskStatus(h.taskID, state, msg)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	if h.canceled() {
+		return taskmanager.ErrTaskFinalState(h.taskID, protocol.TaskStateCanceled)
+	}
+	return h.manager.updateRunStatus(h.taskID, protocol.TaskStatus{State: state, Message: msg})
 }
 
 // AddArtifact implements TaskHandle
 func (h *redisTaskHandle) AddArtifact(artifact protocol.Artifact) error {
-	return h.manager.AddArtifact(h.taskID, artifact)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.limitArtifacts(h.manager.AddArtifact(h.taskID, artifact))
+}
+
+// PatchArtifact implements TaskHandle.
+func (h *redisTaskHandle) PatchArtifact(index, part int, patch []protocol.PatchOperation) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.manager.PatchArtifact(h.taskID, index, part, patch)
+}
+
+// ReplaceArtifact implements TaskHandle.
+func (h *redisTaskHandle) ReplaceArtifact(artifact protocol.Artifact) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.limitArtifacts(h.manager.ReplaceArtifact(h.taskID, artifact))
+}
+
+// DeleteArtifact implements TaskHandle.
+func (h *redisTaskHandle) DeleteArtifact(index int) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.manager.DeleteArtifact(h.taskID, index)
+}
+
+// SendMessage implements TaskHandle.
+func (h *redisTaskHandle) SendMessage(msg protocol.Message) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.manager.SendMessage(h.taskID, msg)
+}
+
+// Complete implements TaskHandle.
+func (h *redisTaskHandle) Complete(msg protocol.Message, artifacts ...protocol.Artifact) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	if h.canceled() {
+		return taskmanager.ErrTaskFinalState(h.taskID, protocol.TaskStateCanceled)
+	}
+	if err := h.manager.CompleteTask(h.taskID, msg, artifacts...); err != nil {
+		return h.limitArtifacts(err)
+	}
+	h.sealed = protocol.TaskStateCompleted
+	return nil
+}
+
+// Fail implements TaskHandle.
+func (h *redisTaskHandle) Fail(err error, retryable bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	if h.canceled() {
+		return taskmanager.ErrTaskFinalState(h.taskID, protocol.TaskStateCanceled)
+	}
+	if updateErr := h.manager.updateRunStatus(h.taskID, taskmanager.FailureStatus(err, retryable)); updateErr != nil {
+		return updateErr
+	}
+	h.sealed = protocol.TaskStateFailed
+	return nil
+}
+
+// SetEstimatedCompletion implements TaskHandle.
+func (h *redisTaskHandle) SetEstimatedCompletion(eta time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.manager.SetEstimatedCompletion(h.taskID, eta)
+}
+
+// GetMessageHistory implements TaskHandle.
+func (h *redisTaskHandle) GetMessageHistory() ([]protocol.Message, error) {
+	return h.manager.getMessageHistory(context.Background(), h.taskID, 0)
+}
+
+// fail fails the task with msg and rejects later updates, unless the task already
+// reached a final state.
+func (h *redisTaskHandle) fail(msg *protocol.Message) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.failLocked(msg)
+}
+
+// failLocked is fail for callers holding h.mu.
+func (h *redisTaskHandle) failLocked(msg *protocol.Message) error {
+	if h.sealed != "" {
+		return nil
+	}
+	task, err := h.manager.getTaskInternal(context.Background(), h.taskID)
+	if err != nil {
+		return err
+	}
+	if task.Status.State.IsFinal() {
+		h.sealed = task.Status.State
+		return nil
+	}
+	h.sealed = protocol.TaskStateFailed
+	return h.manager.UpdateTaskStatus(h.taskID, protocol.TaskStateFailed, msg)
+}
+
+// limitArtifacts fails the task if err reports it exceeded its artifact cap, and
+// returns err.
+func (h *redisTaskHandle) limitArtifacts(err error) error {
+	if errors.Is(err, taskmanager.ErrTooManyArtifacts) {
+		msg := &protocol.Message{
+			Role:  protocol.MessageRoleAgent,
+			Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
+		}
+		if failErr := h.failLocked(msg); failErr != nil {
+			log.Errorf("Failed to update task %s status to failed: %v", h.taskID, failErr)
+		}
+	}
+	return err
 }
 
 // IsStreamingRequest implements TaskHandle.
@@ -118,16 +311,40 @@ func (h *redisTaskHandle) IsStreamingRequest() bool {
 
 // OnSendTask handles the creation or retrieval of a task and initiates synchronous processing.
 func (m *TaskManager) OnSendTask(ctx context.Context, params protocol.SendTaskParams) (*protocol.Task, error) {
+	if err := taskmanager.CheckDependencies(ctx, params.ID, params.DependsOn, m.getTaskInternal); err != nil {
+		return nil, err
+	}
 	// Create or update task
 	_ = m.upsertTask(ctx, params)
-	// Store the initial message
-	m.storeMessage(ctx, params.ID, params.Message)
+	// Store the seed messages and the initial message
+	m.storeInitialMessages(ctx, params)
+	m.recordEvent(ctx, params.ID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleReceived, "", ""))
 	// Create a cancellable context for this specific task processing
-	taskCtx, cancel := context.WithCancel(ctx)
-	defer cancel() // Ensure context is cancelled eventually.
+	taskCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil) // Ensure context is cancelled eventually.
+	// Let OnCancelTask stop the processor.
+	m.cancelMu.Lock()
+	m.cancels[params.ID] = cancel
+	m.cancelMu.Unlock()
+	defer func() {
+		m.cancelMu.Lock()
+		delete(m.cancels, params.ID)
+		m.cancelMu.Unlock()
+	}()
 	handle := &redisTaskHandle{
 		taskID:  params.ID,
 		manager: m,
+		ctx:     taskCtx,
+	}
+	// Hold the task until its dependencies completed.
+	if err := taskmanager.WaitForDependencies(taskCtx, params.DependsOn, m.getTaskInternal, m); err != nil {
+		m.failPending(params.ID, err)
+		latestTask, _ := m.getTaskInternal(ctx, params.ID) // Ignore get error for now.
+		var depErr *taskmanager.DependencyError
+		if errors.As(err, &depErr) {
+			return latestTask, nil // The failed dependency is reported by the task's status.
+		}
+		return latestTask, err
 	}
 	// Set initial status to Working *before* calling Process.
 	if err := m.UpdateTaskStatus(params.ID, protocol.TaskStateWorking, nil); err != nil {
@@ -136,16 +353,21 @@ func (m *TaskManager) OnSendTask(ctx context.Context, params protocol.SendTaskPa
 		latestTask, _ := m.getTaskInternal(ctx, params.ID) // Ignore get error for now.
 		return latestTask, fmt.Errorf("failed to set initial working status: %w", err)
 	}
+	watchdog := m.startWatchdog(params.ID, handle, cancel)
+	defer watchdog.Stop()
 	// Delegate the actual processing to the injected processor (synchronously).
-	var processorErr error
-	if processorErr = m.processor.Process(taskCtx, params.ID, params.Message, handle); processorErr != nil {
+	m.recordEvent(ctx, params.ID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleProcessingStarted, "", ""))
+	processorErr := m.runProcessor(taskCtx, params, handle, watchdog)
+	if processorErr != nil && !errors.Is(processorErr, taskmanager.ErrProcessorTimeout) && !handle.canceled() {
 		log.Errorf("Processor failed for task %s: %v", params.ID, processorErr)
+		m.recordEvent(ctx, params.ID,
+			protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleError, "", processorErr.Error()))
 		errMsg := &protocol.Message{
 			Role:  protocol.MessageRoleAgent,
 			Parts: []protocol.Part{protocol.NewTextPart(processorErr.Error())},
 		}
 		// Log update error while still handling the processor error.
-		if updateErr := m.UpdateTaskStatus(params.ID, protocol.TaskStateFailed, errMsg); updateErr != nil {
+		if updateErr := handle.UpdateStatus(protocol.TaskStateFailed, errMsg); updateErr != nil {
 			log.Errorf("Failed to update task %s status to failed: %v", params.ID, updateErr)
 		}
 	}
@@ -164,50 +386,77 @@ func (m *TaskManager) OnSendTaskSubscribe(
 	ctx context.Context,
 	params protocol.SendTaskParams,
 ) (<-chan protocol.TaskEvent, error) {
+	if err := taskmanager.CheckDependencies(ctx, params.ID, params.DependsOn, m.getTaskInternal); err != nil {
+		return nil, err
+	}
 	// Create a new task or update an existing one.
 	task := m.upsertTask(ctx, params)
-	// Store the message that came with the request.
-	m.storeMessage(ctx, params.ID, params.Message)
+	// Store the seed messages and the message that came with the request.
+	m.storeInitialMessages(ctx, params)
+	m.recordEvent(ctx, params.ID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleReceived, "", ""))
 	// Create event channel for this specific subscriber.
 	eventChan := make(chan protocol.TaskEvent, 10) // Buffered to prevent blocking sends.
 	m.addSubscriber(params.ID, eventChan)
 	// Create a cancellable context for the processor.
-	processorCtx, cancel := context.WithCancel(ctx)
+	processorCtx, cancel := context.WithCancelCause(ctx)
 	// Store the cancel function.
 	m.cancelMu.Lock()
 	m.cancels[params.ID] = cancel
 	m.cancelMu.Unlock()
-	// Set initial state if new (submitted -> working).
-	// This will generate the first event for subscribers.
-	if task.Status.State == protocol.TaskStateSubmitted {
+	pending := len(params.DependsOn) > 0
+	if pending {
+		// Report the pending task; it starts once its dependencies completed.
+		m.notifySubscribers(params.ID, protocol.TaskStatusUpdateEvent{ID: params.ID, Status: task.Status})
+	} else if task.Status.State == protocol.TaskStateSubmitted || task.Status.State.IsFinal() {
+		// Set initial state if new (submitted -> working), or if the task is
+		// worked on again after reaching a final state.
+		// This will generate the first event for subscribers.
 		if err := m.UpdateTaskStatus(params.ID, protocol.TaskStateWorking, nil); err != nil {
 			m.removeSubscriber(params.ID, eventChan)
 			close(eventChan)
 			return nil, err
 		}
 	}
+	// Create a handle for the processor to interact with the task.
+	handle := &redisTaskHandle{
+		taskID:  params.ID,
+		manager: m,
+		ctx:     processorCtx,
+	}
 	// Start the processor in a goroutine.
 	go func() {
-		// Create a handle for the processor to interact with the task.
-		handle := &redisTaskHandle{
-			taskID:  params.ID,
-			manager: m,
+		if pending && !m.awaitDependencies(processorCtx, params) {
+			m.cancelMu.Lock()
+			delete(m.cancels, params.ID)
+			m.cancelMu.Unlock()
+			cancel(nil)
+			m.removeSubscriber(params.ID, eventChan)
+			close(eventChan)
+			return
+		}
+		watchdog := m.startWatchdog(params.ID, handle, cancel)
+		if watchdog != nil {
+			m.watchdogs.Store(params.ID, watchdog)
 		}
+		defer func() {
+			watchdog.Stop()
+			m.watchdogs.CompareAndDelete(params.ID, watchdog)
+		}()
 		log.Debugf("SSE Processor started for task %s", params.ID)
 		var err error
+		m.recordEvent(context.Background(), params.ID,
+			protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleProcessingStarted, "", ""))
 		if err = m.processor.Process(processorCtx, params.ID, params.Message, handle); err != nil {
 			log.Errorf("Processor failed for task %s in subscribe: %v", params.ID, err)
+			m.recordEvent(context.Background(), params.ID,
+				protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleError, "", err.Error()))
 			if processorCtx.Err() != context.Canceled {
 				// Only update to failed if not already cancelled.
 				errMsg := &protocol.Message{
 					Role:  protocol.MessageRoleAgent,
 					Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
 				}
-				if updateErr := m.UpdateTaskStatus(
-					params.ID,
-					protocol.TaskStateFailed,
-					errMsg,
-				); updateErr != nil {
+				if updateErr := handle.UpdateStatus(protocol.TaskStateFailed, errMsg); updateErr != nil {
 					log.Errorf("Failed to update task %s status to failed: %v", params.ID, updateErr)
 				}
 			}
@@ -226,6 +475,106 @@ func (m *TaskManager) OnSendTaskSubscribe(
 	return eventChan, nil
 }
 
+// awaitDependencies waits for the dependencies of a streaming task and sets it
+// working. It fails the task and returns false if a dependency did not complete.
+func (m *TaskManager) awaitDependencies(ctx context.Context, params protocol.SendTaskParams) bool {
+	err := taskmanager.WaitForDependencies(ctx, params.DependsOn, m.getTaskInternal, m)
+	if err == nil {
+		err = m.UpdateTaskStatus(params.ID, protocol.TaskStateWorking, nil)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			m.failPending(params.ID, err)
+		}
+		return false
+	}
+	return true
+}
+
+// failPending fails a task that stopped waiting for its dependencies because of
+// err, unless it already reached a final state, e.g. by being canceled.
+func (m *TaskManager) failPending(taskID string, err error) {
+	task, getErr := m.getTaskInternal(context.Background(), taskID)
+	if getErr != nil || task.Status.State.IsFinal() {
+		return
+	}
+	log.Infof("Failing task %s waiting for dependencies: %v", taskID, err)
+	m.recordEvent(context.Background(), taskID,
+		protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleError, "", err.Error()))
+	errMsg := &protocol.Message{
+		Role:  protocol.MessageRoleAgent,
+		Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
+	}
+	if updateErr := m.UpdateTaskStatus(taskID, protocol.TaskStateFailed, errMsg); updateErr != nil {
+		log.Errorf("Failed to update task %s status to failed: %v", taskID, updateErr)
+	}
+}
+
+// SetMaxArtifactsPerTask implements taskmanager.ArtifactLimiter.
+func (m *TaskManager) SetMaxArtifactsPerTask(n int) {
+	m.maxArtifacts.Store(int64(max(n, 0)))
+}
+
+// SetProcessorTimeout implements taskmanager.ProcessorLimiter.
+func (m *TaskManager) SetProcessorTimeout(d time.Duration) {
+	m.processorTimeout.Store(int64(max(d, 0)))
+}
+
+// SetSubscriberSendTimeout implements taskmanager.BackpressureLimiter.
+func (m *TaskManager) SetSubscriberSendTimeout(d time.Duration) {
+	m.sendTimeout.Store(int64(max(d, 0)))
+}
+
+// startWatchdog starts the processor watchdog of a task, if a processor timeout is
+// set. When it fires the processor's context is cancelled and the task failed.
+func (m *TaskManager) startWatchdog(
+	taskID string,
+	handle *redisTaskHandle,
+	cancel context.CancelCauseFunc,
+) *taskmanager.ProcessorWatchdog {
+	timeout := time.Duration(m.processorTimeout.Load())
+	return taskmanager.NewProcessorWatchdog(timeout, func() {
+		err := fmt.Errorf("%w after %v", taskmanager.ErrProcessorTimeout, timeout)
+		log.Errorf("Processor for task %s: %v", taskID, err)
+		cancel(err)
+		m.recordEvent(context.Background(), taskID,
+			protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleError, "", err.Error()))
+		errMsg := &protocol.Message{
+			Role:  protocol.MessageRoleAgent,
+			Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
+		}
+		if failErr := handle.fail(errMsg); failErr != nil {
+			log.Errorf("Failed to update task %s status to failed: %v", taskID, failErr)
+		}
+	})
+}
+
+// runProcessor runs the processor, returning early with an ErrProcessorTimeout error
+// if the watchdog fires first, even if the processor ignores its context.
+func (m *TaskManager) runProcessor(
+	ctx context.Context,
+	params protocol.SendTaskParams,
+	handle *redisTaskHandle,
+	watchdog *taskmanager.ProcessorWatchdog,
+) error {
+	if watchdog == nil {
+		return m.processor.Process(ctx, params.ID, params.Message, handle)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- m.processor.Process(ctx, params.ID, params.Message, handle)
+	}()
+	select {
+	case err := <-done:
+		if cause := context.Cause(ctx); err != nil && errors.Is(cause, taskmanager.ErrProcessorTimeout) {
+			return cause
+		}
+		return err
+	case <-watchdog.Fired():
+		return context.Cause(ctx)
+	}
+}
+
 // OnGetTask retrieves the current state of a task.
 func (m *TaskManager) OnGetTask(
 	ctx context.Context,
@@ -235,6 +584,14 @@ func (m *TaskManager) OnGetTask(
 	if err != nil {
 		return nil, err
 	}
+	if params.IncludeEvents {
+		events, err := m.getEvents(ctx, params.ID)
+		if err != nil {
+			log.Warnf("Failed to retrieve lifecycle events for task %s: %v", params.ID, err)
+		} else {
+			task.Events = events
+		}
+	}
 	// Optionally include message history if requested.
 	if params.HistoryLength != nil && *params.HistoryLength > 0 {
 		history, err := m.getMessageHistory(ctx, params.ID, *params.HistoryLength)
@@ -258,14 +615,28 @@ func (m *TaskManager) OnCancelTask(
 		return nil, err
 	}
 	// Check if task is already in a final state.
-	if isFinalState(task.Status.State) {
+	if task.Status.State.IsFinal() {
 		return task, taskmanager.ErrTaskFinalState(params.ID, task.Status.State)
 	}
+	reason := params.CancelReason()
+	// Update state to Cancelled, recording the reason, before stopping the
+	// processor: its goroutine closes the subscriber channels as it exits, which
+	// must not happen before the canceled status reached them. The processor
+	// cannot change the status of the canceled task in the meantime, see
+	// updateRunStatus.
+	if err := m.updateTaskStatus(params.ID, protocol.TaskStatus{
+		State:        protocol.TaskStateCanceled,
+		Message:      taskmanager.NewCancelMessage(params.ID, reason),
+		CancelReason: &reason,
+	}); err != nil {
+		log.Errorf("Error updating status to Cancelled for task %s: %v", params.ID, err)
+		return nil, err
+	}
 	var cancelFound bool
 	m.cancelMu.Lock()
 	cancel, exists := m.cancels[params.ID]
 	if exists {
-		cancel() // Call the cancel function.
+		cancel(&taskmanager.CancelError{Reason: reason}) // Call the cancel function.
 		cancelFound = true
 		// Don't delete the context here - let the processor goroutine clean up.
 	}
@@ -274,18 +645,6 @@ func (m *TaskManager) OnCancelTask(
 	if !cancelFound {
 		log.Warnf("Warning: No cancellation function found for task %s", params.ID)
 	}
-	// Create a cancellation message.
-	cancelMsg := &protocol.Message{
-		Role: protocol.MessageRoleAgent,
-		Parts: []protocol.Part{
-			protocol.NewTextPart(fmt.Sprintf("Task %s was canceled by user request", params.ID)),
-		},
-	}
-	// Update state to Cancelled.
-	if err := m.UpdateTaskStatus(params.ID, protocol.TaskStateCanceled, cancelMsg); err != nil {
-		log.Errorf("Error updating status to Cancelled for task %s: %v", params.ID, err)
-		return nil, err
-	}
 	// Fetch the updated task state to return.
 	updatedTask, err := m.getTaskInternal(ctx, params.ID)
 	if err != nil {
@@ -358,10 +717,14 @@ func (m *TaskManager) OnResubscribe(
 	if err != nil {
 		return nil, err
 	}
-	// Create a channel for events.
-	eventChan := make(chan protocol.TaskEvent)
+	if m.persistEvents {
+		return m.resubscribeFromLog(ctx, task)
+	}
+	// Create a channel for events, buffered like OnSendTaskSubscribe's since
+	// notifySubscribers drops events a subscriber is not ready for.
+	eventChan := make(chan protocol.TaskEvent, 10)
 	// For tasks in final state, just send a status update event and close.
-	if isFinalState(task.Status.State) {
+	if task.Status.State.IsFinal() {
 		go func() {
 			// Send a task status update event
 			event := protocol.TaskStatusUpdateEvent{
@@ -395,7 +758,7 @@ func (m *TaskManager) OnResubscribe(
 		event := protocol.TaskStatusUpdateEvent{
 			ID:     task.ID,
 			Status: task.Status,
-			Final:  isFinalState(task.Status.State),
+			Final:  task.Status.State.IsFinal(),
 		}
 		select {
 		case eventChan <- event:
@@ -412,23 +775,49 @@ func (m *TaskManager) OnResubscribe(
 }
 
 // UpdateTaskStatus updates the task's state and notifies subscribers.
+// Returns an error if the task does not exist, or if both its state and the new
+// one are final.
 func (m *TaskManager) UpdateTaskStatus(
 	taskID string,
 	state protocol.TaskState,
 	message *protocol.Message,
 ) error {
+	return m.updateTaskStatus(taskID, protocol.TaskStatus{State: state, Message: message})
+}
+
+// updateTaskStatus replaces the task's status, stamping it with the current time.
+// A final status cannot be replaced by another final status, so a processor
+// reporting its result late cannot overwrite a canceled task; a task in a final
+// state can still be worked on again.
+func (m *TaskManager) updateTaskStatus(taskID string, status protocol.TaskStatus) error {
+	return m.writeTaskStatus(taskID, status, false)
+}
+
+// updateRunStatus is updateTaskStatus for the processor of the task, which cannot
+// change the status at all once the task is final, e.g. canceled while it ran.
+func (m *TaskManager) updateRunStatus(taskID string, status protocol.TaskStatus) error {
+	return m.writeTaskStatus(taskID, status, true)
+}
+
+// writeTaskStatus implements updateTaskStatus and, if running, updateRunStatus.
+func (m *TaskManager) writeTaskStatus(taskID string, status protocol.TaskStatus, running bool) error {
 	ctx := context.Background()
 	task, err := m.getTaskInternal(ctx, taskID)
 	if err != nil {
 		log.Warnf("Warning: UpdateTaskStatus called for non-existent task %s", taskID)
 		return err
 	}
+	if state := task.Status.State; state.IsFinal() && (running || status.State.IsFinal()) {
+		return taskmanager.ErrTaskFinalState(taskID, state)
+	}
 	// Update status fields.
-	task.Status = protocol.TaskStatus{
-		State:     state,
-		Message:   message,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	status.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	if status.State == protocol.TaskStateWorking && task.Status.State == protocol.TaskStateWorking &&
+		status.EstimatedCompletion == "" {
+		// A working update without an estimate keeps the previous one.
+		status.EstimatedCompletion = task.Status.EstimatedCompletion
 	}
+	task.Status = status
 	// Store updated task.
 	taskKey := taskPrefix + taskID
 	taskBytes, err := json.Marshal(task)
@@ -438,19 +827,92 @@ func (m *TaskManager) UpdateTaskStatus(
 	if err := m.client.Set(ctx, taskKey, taskBytes, m.expiration).Err(); err != nil {
 		return fmt.Errorf("failed to update task status: %w", err)
 	}
+	m.wakeStatusWaiters(taskID)
 	// Store the message in history if provided.
-	if message != nil {
-		m.storeMessage(ctx, taskID, *message)
+	if status.Message != nil {
+		m.storeMessage(ctx, taskID, *status.Message)
 	}
+	m.recordStatusEvent(ctx, taskID, status)
 	// Notify subscribers.
 	m.notifySubscribers(taskID, protocol.TaskStatusUpdateEvent{
 		ID:     taskID,
 		Status: task.Status,
-		Final:  isFinalState(state),
+		Final:  status.State.IsFinal(),
 	})
 	return nil
 }
 
+// SetEstimatedCompletion sets the estimated completion time on the working task's
+// status and notifies subscribers with the updated status.
+func (m *TaskManager) SetEstimatedCompletion(taskID string, eta time.Time) error {
+	ctx := context.Background()
+	task, err := m.getTaskInternal(ctx, taskID)
+	if err != nil {
+		log.Warnf("Warning: SetEstimatedCompletion called for non-existent task %s", taskID)
+		return err
+	}
+	if task.Status.State.IsFinal() {
+		return taskmanager.ErrTaskFinalState(taskID, task.Status.State)
+	}
+	if task.Status.State != protocol.TaskStateWorking {
+		return fmt.Errorf("task %s is %s: estimated completion can only be set while working", taskID, task.Status.State)
+	}
+	task.Status.EstimatedCompletion = eta.UTC().Format(time.RFC3339)
+	task.Status.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	taskBytes, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+	if err := m.client.Set(ctx, taskPrefix+taskID, taskBytes, m.expiration).Err(); err != nil {
+		return fmt.Errorf("failed to update task status: %w", err)
+	}
+	m.wakeStatusWaiters(taskID)
+	m.notifySubscribers(taskID, protocol.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: task.Status,
+	})
+	return nil
+}
+
+// WaitForTaskChange implements taskmanager.TaskWaiter. Only status changes made
+// through this TaskManager wake the call; changes made by other processes sharing
+// the Redis instance are seen when ctx is done.
+func (m *TaskManager) WaitForTaskChange(ctx context.Context, taskID string) error {
+	// Register before reading the task, so a change made in between still wakes us.
+	m.waitMu.Lock()
+	changed, ok := m.statusWaiters[taskID]
+	if !ok {
+		changed = make(chan struct{})
+		m.statusWaiters[taskID] = changed
+	}
+	m.waitMu.Unlock()
+	task, err := m.getTaskInternal(ctx, taskID)
+	if err != nil {
+		// Do not keep a waiter for a task that does not exist.
+		m.wakeStatusWaiters(taskID)
+		return err
+	}
+	if task.Status.State.IsFinal() {
+		return nil
+	}
+	select {
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wakeStatusWaiters releases the WaitForTaskChange calls waiting on the task.
+func (m *TaskManager) wakeStatusWaiters(taskID string) {
+	m.waitMu.Lock()
+	defer m.waitMu.Unlock()
+	if changed, ok := m.statusWaiters[taskID]; ok {
+		close(changed)
+		delete(m.statusWaiters, taskID)
+	}
+}
+
 // AddArtifact adds an artifact to the task and notifies subscribers.
 func (m *TaskManager) AddArtifact(taskID string, artifact protocol.Artifact) error {
 	ctx := context.Background()
@@ -459,6 +921,10 @@ func (m *TaskManager) AddArtifact(taskID string, artifact protocol.Artifact) err
 		log.Warnf("Warning: AddArtifact called for non-existent task %s", taskID)
 		return err
 	}
+	if err := taskmanager.CheckArtifactLimit(task, []protocol.Artifact{artifact}, int(m.maxArtifacts.Load())); err != nil {
+		return err
+	}
+	checksum.EnsureArtifact(&artifact)
 	// Append the artifact.
 	if task.Artifacts == nil {
 		task.Artifacts = make([]protocol.Artifact, 0, 1)
@@ -483,15 +949,319 @@ func (m *TaskManager) AddArtifact(taskID string, artifact protocol.Artifact) err
 	return nil
 }
 
-// --- Internal Helper Methods ---
+// PatchArtifact applies patch to a DataPart of the task's artifact with the given
+// index, stores the result and notifies subscribers with the patch itself.
+func (m *TaskManager) PatchArtifact(taskID string, index, part int, patch []protocol.PatchOperation) error {
+	ctx := context.Background()
+	task, err := m.getTaskInternal(ctx, taskID)
+	if err != nil {
+		log.Warnf("Warning: PatchArtifact called for non-existent task %s", taskID)
+		return err
+	}
+	artifacts, err := protocol.PatchArtifacts(task.Artifacts, index, part, patch)
+	if err != nil {
+		return err
+	}
+	task.Artifacts = artifacts
+	taskKey := taskPrefix + taskID
+	taskBytes, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+	if err := m.client.Set(ctx, taskKey, taskBytes, m.expiration).Err(); err != nil {
+		return fmt.Errorf("failed to update task artifacts: %w", err)
+	}
+	m.notifySubscribers(taskID, protocol.TaskArtifactPatchEvent{
+		ID:    taskID,
+		Index: index,
+		Part:  part,
+		Patch: patch,
+	})
+	return nil
+}
 
-// isFinalState checks if a TaskState represents a terminal state.
-func isFinalState(state protocol.TaskState) bool {
-	return state == protocol.TaskStateCompleted ||
-		state == protocol.TaskStateFailed ||
-		state == protocol.TaskStateCanceled
+// ReplaceArtifact replaces the task's artifact with artifact.Index by artifact,
+// stores the result and notifies subscribers with it.
+func (m *TaskManager) ReplaceArtifact(taskID string, artifact protocol.Artifact) error {
+	ctx := context.Background()
+	task, err := m.getTaskInternal(ctx, taskID)
+	if err != nil {
+		log.Warnf("Warning: ReplaceArtifact called for non-existent task %s", taskID)
+		return err
+	}
+	if err := taskmanager.CheckArtifactLimit(task, []protocol.Artifact{artifact}, int(m.maxArtifacts.Load())); err != nil {
+		return err
+	}
+	checksum.EnsureArtifact(&artifact)
+	task.Artifacts = protocol.ReplaceArtifacts(task.Artifacts, artifact)
+	artifact = task.Artifacts[len(task.Artifacts)-1]
+	if err := m.storeArtifacts(ctx, task); err != nil {
+		return err
+	}
+	m.notifySubscribers(taskID, protocol.TaskArtifactUpdateEvent{
+		ID:       taskID,
+		Artifact: artifact,
+		Final:    artifact.LastChunk != nil && *artifact.LastChunk,
+	})
+	return nil
+}
+
+// DeleteArtifact removes the task's artifact with the given index, stores the
+// result and notifies subscribers.
+func (m *TaskManager) DeleteArtifact(taskID string, index int) error {
+	ctx := context.Background()
+	task, err := m.getTaskInternal(ctx, taskID)
+	if err != nil {
+		log.Warnf("Warning: DeleteArtifact called for non-existent task %s", taskID)
+		return err
+	}
+	artifacts, err := protocol.DeleteArtifacts(task.Artifacts, index)
+	if err != nil {
+		return err
+	}
+	task.Artifacts = artifacts
+	if err := m.storeArtifacts(ctx, task); err != nil {
+		return err
+	}
+	m.notifySubscribers(taskID, protocol.TaskArtifactDeleteEvent{ID: taskID, Index: index})
+	return nil
+}
+
+// storeArtifacts writes task back after a change to its artifacts.
+func (m *TaskManager) storeArtifacts(ctx context.Context, task *protocol.Task) error {
+	taskBytes, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+	if err := m.client.Set(ctx, taskPrefix+task.ID, taskBytes, m.expiration).Err(); err != nil {
+		return fmt.Errorf("failed to update task artifacts: %w", err)
+	}
+	return nil
 }
 
+// CompleteTask sets the task's status to completed with message as its result and
+// appends artifacts, storing both in a single write. Subscribers get the artifact
+// events before the final status event. Returns an error if the task is already
+// in a final state.
+func (m *TaskManager) CompleteTask(taskID string, message protocol.Message, artifacts ...protocol.Artifact) error {
+	ctx := context.Background()
+	task, err := m.getTaskInternal(ctx, taskID)
+	if err != nil {
+		log.Warnf("Warning: CompleteTask called for non-existent task %s", taskID)
+		return err
+	}
+	if state := task.Status.State; state.IsFinal() {
+		return taskmanager.ErrTaskFinalState(taskID, state)
+	}
+	if err := taskmanager.CheckArtifactLimit(task, artifacts, int(m.maxArtifacts.Load())); err != nil {
+		return err
+	}
+	for i := range artifacts {
+		checksum.EnsureArtifact(&artifacts[i])
+	}
+	task.Artifacts = append(task.Artifacts, artifacts...)
+	task.Status = protocol.TaskStatus{
+		State:     protocol.TaskStateCompleted,
+		Message:   &message,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	taskKey := taskPrefix + taskID
+	taskBytes, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+	if err := m.client.Set(ctx, taskKey, taskBytes, m.expiration).Err(); err != nil {
+		return fmt.Errorf("failed to complete task: %w", err)
+	}
+	m.wakeStatusWaiters(taskID)
+	m.storeMessage(ctx, taskID, message)
+	m.recordStatusEvent(ctx, taskID, task.Status)
+	for _, artifact := range artifacts {
+		m.notifySubscribers(taskID, protocol.TaskArtifactUpdateEvent{
+			ID:       taskID,
+			Artifact: artifact,
+			Final:    artifact.LastChunk != nil && *artifact.LastChunk,
+		})
+	}
+	m.notifySubscribers(taskID, protocol.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: task.Status,
+		Final:  true,
+	})
+	return nil
+}
+
+// SendMessage records an intermediate agent message in the task's history and
+// pushes it to subscribers without changing the task's status.
+func (m *TaskManager) SendMessage(taskID string, message protocol.Message) error {
+	ctx := context.Background()
+	if _, err := m.getTaskInternal(ctx, taskID); err != nil {
+		log.Warnf("Warning: SendMessage called for non-existent task %s", taskID)
+		return err
+	}
+	m.storeMessage(ctx, taskID, message)
+	m.notifySubscribers(taskID, protocol.TaskMessageEvent{
+		ID:      taskID,
+		Message: message,
+	})
+	return nil
+}
+
+// ListTasksByLabels returns all tasks whose labels match every key/value pair in
+// the selector. An empty selector returns all tasks.
+func (m *TaskManager) ListTasksByLabels(ctx context.Context, selector map[string]string) ([]protocol.Task, error) {
+	var ids []string
+	if len(selector) == 0 {
+		iter := m.client.Scan(ctx, 0, taskPrefix+"*", 0).Iterator()
+		for iter.Next(ctx) {
+			ids = append(ids, strings.TrimPrefix(iter.Val(), taskPrefix))
+		}
+		if err := iter.Err(); err != nil {
+			return nil, fmt.Errorf("failed to scan tasks: %w", err)
+		}
+	} else {
+		keys := make([]string, 0, len(selector))
+		for k, v := range selector {
+			keys = append(keys, labelKey(k, v))
+		}
+		var err error
+		if ids, err = m.client.SInter(ctx, keys...).Result(); err != nil {
+			return nil, fmt.Errorf("failed to look up task labels: %w", err)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = taskPrefix + id
+	}
+	values, err := m.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tasks from Redis: %w", err)
+	}
+	var tasks []protocol.Task
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // The task expired but its label entry has not yet.
+		}
+		var task protocol.Task
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			log.Errorf("Failed to deserialize task %s: %v", ids[i], err)
+			continue
+		}
+		if task.MatchesLabels(selector) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// PruneTasks implements taskmanager.TaskPruner. It scans all tasks, so run it
+// sparingly on large keyspaces; keys still expire on their own after the
+// configured expiration.
+func (m *TaskManager) PruneTasks(
+	ctx context.Context, before time.Time, onEvict func(protocol.Task),
+) (int, error) {
+	tasks, err := m.ListTasksByLabels(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	for _, task := range tasks {
+		if !task.FinishedBefore(before) {
+			continue
+		}
+		if onEvict != nil {
+			onEvict(task)
+		}
+		keys := []string{
+			taskPrefix + task.ID, messagePrefix + task.ID, pushNotificationPrefix + task.ID, eventsPrefix + task.ID,
+			streamPrefix + task.ID,
+		}
+		if err := m.client.Del(ctx, keys...).Err(); err != nil {
+			return pruned, fmt.Errorf("failed to delete task %s: %w", task.ID, err)
+		}
+		for k, v := range task.Labels {
+			m.client.SRem(ctx, labelKey(k, v), task.ID)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// ExportTask implements taskmanager.TaskSnapshotter.
+func (m *TaskManager) ExportTask(ctx context.Context, taskID string) (*protocol.TaskSnapshot, error) {
+	task, err := m.getTaskInternal(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.History, err = m.getMessageHistory(ctx, taskID, 0); err != nil {
+		return nil, err
+	}
+	if task.Events, err = m.getEvents(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return protocol.NewTaskSnapshot(*task), nil
+}
+
+// RestoreTask implements taskmanager.TaskSnapshotter.
+func (m *TaskManager) RestoreTask(ctx context.Context, task protocol.Task, replace bool) error {
+	history, events := task.History, task.Events
+	task.History, task.Events = nil, nil
+	taskBytes, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+	taskKey := taskPrefix + task.ID
+	if !replace {
+		stored, err := m.client.SetNX(ctx, taskKey, taskBytes, m.expiration).Result()
+		if err != nil {
+			return fmt.Errorf("failed to store task in Redis: %w", err)
+		}
+		if !stored {
+			return taskmanager.ErrTaskExists(task.ID)
+		}
+	} else {
+		if existing, err := m.getTaskInternal(ctx, task.ID); err == nil {
+			for k, v := range existing.Labels {
+				m.client.SRem(ctx, labelKey(k, v), task.ID)
+			}
+		}
+		if err := m.client.Set(ctx, taskKey, taskBytes, m.expiration).Err(); err != nil {
+			return fmt.Errorf("failed to store task in Redis: %w", err)
+		}
+	}
+	keys := []string{
+		messagePrefix + task.ID, pushNotificationPrefix + task.ID, eventsPrefix + task.ID, streamPrefix + task.ID,
+	}
+	if err := m.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to clear state of task %s: %w", task.ID, err)
+	}
+	m.indexLabels(ctx, &task)
+	for _, message := range history {
+		messageBytes, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("failed to serialize message: %w", err)
+		}
+		if err := m.client.RPush(ctx, messagePrefix+task.ID, messageBytes).Err(); err != nil {
+			return fmt.Errorf("failed to store message history in Redis: %w", err)
+		}
+	}
+	m.client.Expire(ctx, messagePrefix+task.ID, m.expiration)
+	for _, event := range events {
+		m.recordEvent(ctx, task.ID, event)
+	}
+	if replace {
+		m.wakeStatusWaiters(task.ID)
+	}
+	log.Infof("Restored task %s from snapshot", task.ID)
+	return nil
+}
+
+// --- Internal Helper Methods ---
+
 // getTaskInternal retrieves a task from Redis.
 func (m *TaskManager) getTaskInternal(ctx context.Context, taskID string) (*protocol.Task, error) {
 	taskKey := taskPrefix + taskID
@@ -527,13 +1297,18 @@ func (m *TaskManager) upsertTask(ctx context.Context, params protocol.SendTaskPa
 	} else if err == redis.Nil {
 		// Task doesn't exist, create new one.
 		task = protocol.NewTask(params.ID, params.SessionID)
-		log.Infof("Created new task %s (Session: %v)", params.ID, params.SessionID)
+		task.Labels = copyLabels(params.Labels)
+		task.RequestID = taskmanager.RequestIDFromContext(ctx)
+		log.Infof("Created new task %s (Session: %v, Request: %s)", params.ID, params.SessionID, task.RequestID)
 	} else {
 		// Redis error.
 		log.Errorf("Redis error when retrieving task %s: %v", params.ID, err)
 		// Fall back to creating a new task.
 		task = protocol.NewTask(params.ID, params.SessionID)
 	}
+	if len(params.DependsOn) > 0 {
+		task.DependsOn = append([]string(nil), params.DependsOn...)
+	}
 	// Update metadata if provided.
 	if params.Metadata != nil {
 		if task.Metadata == nil {
@@ -552,16 +1327,61 @@ func (m *TaskManager) upsertTask(ctx context.Context, params protocol.SendTaskPa
 	if err := m.client.Set(ctx, taskKey, taskBytes, m.expiration).Err(); err != nil {
 		log.Errorf("Failed to store task %s in Redis: %v", params.ID, err)
 	}
+	if err == nil {
+		m.indexLabels(ctx, task)
+	}
 	return task
 }
 
+// indexLabels adds the task to the label sets used by ListTasksByLabels.
+func (m *TaskManager) indexLabels(ctx context.Context, task *protocol.Task) {
+	for k, v := range task.Labels {
+		key := labelKey(k, v)
+		if err := m.client.SAdd(ctx, key, task.ID).Err(); err != nil {
+			log.Errorf("Failed to index label %s=%s for task %s: %v", k, v, task.ID, err)
+			continue
+		}
+		m.client.Expire(ctx, key, m.expiration)
+	}
+}
+
+// labelKey returns the Redis key of the set of task IDs carrying a label.
+func labelKey(key, value string) string {
+	return labelPrefix + key + "=" + value
+}
+
+// copyLabels returns a copy of the labels map, or nil if it is empty.
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	return copied
+}
+
+// storeInitialMessages adds the seed messages and the request message to the task's history, in order.
+func (m *TaskManager) storeInitialMessages(ctx context.Context, params protocol.SendTaskParams) {
+	for _, message := range params.InitialMessages() {
+		m.storeMessage(ctx, params.ID, message)
+	}
+}
+
+// SetMaxHistoryBytes implements taskmanager.HistoryLimiter.
+func (m *TaskManager) SetMaxHistoryBytes(n int) {
+	m.maxHistoryBytes.Store(int64(max(n, 0)))
+}
+
 // storeMessage adds a message to the task's history in Redis.
 func (m *TaskManager) storeMessage(ctx context.Context, taskID string, message protocol.Message) {
 	messagesKey := messagePrefix + taskID
 	// Create a copy of the message to store.
 	messageCopy := protocol.Message{
-		Role:     message.Role,
-		Metadata: message.Metadata,
+		Role:      message.Role,
+		Metadata:  message.Metadata,
+		Signature: message.Signature,
 	}
 	if message.Parts != nil {
 		messageCopy.Parts = make([]protocol.Part, len(message.Parts))
@@ -573,16 +1393,47 @@ func (m *TaskManager) storeMessage(ctx context.Context, taskID string, message p
 		log.Errorf("Failed to serialize message for task %s: %v", taskID, err)
 		return
 	}
+	maxBytes := m.maxHistoryBytes.Load()
+	if maxBytes > 0 && int64(len(messageBytes)) > maxBytes {
+		log.Warnf("Message of %d bytes for task %s exceeds the %d byte history cap; not stored",
+			len(messageBytes), taskID, maxBytes)
+		return
+	}
 	// Add the message to a Redis list.
 	if err := m.client.RPush(ctx, messagesKey, messageBytes).Err(); err != nil {
 		log.Errorf("Failed to store message for task %s in Redis: %v", taskID, err)
 		return
 	}
+	if maxBytes > 0 {
+		m.trimHistory(ctx, taskID, maxBytes)
+	}
 	// Set expiration on the message list.
 	m.client.Expire(ctx, messagesKey, m.expiration)
 }
 
+// trimHistory drops the oldest messages of the task's history until its encoded
+// size is at most maxBytes.
+func (m *TaskManager) trimHistory(ctx context.Context, taskID string, maxBytes int64) {
+	messagesKey := messagePrefix + taskID
+	messages, err := m.client.LRange(ctx, messagesKey, 0, -1).Result()
+	if err != nil {
+		log.Errorf("Failed to read message history for task %s: %v", taskID, err)
+		return
+	}
+	var total int64
+	for i := len(messages) - 1; i >= 0; i-- {
+		total += int64(len(messages[i]))
+		if total > maxBytes {
+			if err := m.client.LTrim(ctx, messagesKey, int64(i+1), -1).Err(); err != nil {
+				log.Errorf("Failed to trim message history for task %s: %v", taskID, err)
+			}
+			return
+		}
+	}
+}
+
 // getMessageHistory retrieves message history for a task.
+// A non-positive limit returns the full history.
 func (m *TaskManager) getMessageHistory(
 	ctx context.Context,
 	taskID string,
@@ -599,7 +1450,7 @@ func (m *TaskManager) getMessageHistory(
 	}
 	// Calculate range for LRANGE (get the latest messages).
 	start := int64(0)
-	if count > int64(limit) {
+	if limit > 0 && count > int64(limit) {
 		start = count - int64(limit)
 	}
 	// Get messages.
@@ -620,6 +1471,52 @@ func (m *TaskManager) getMessageHistory(
 	return messages, nil
 }
 
+// recordEvent appends an event to the task's lifecycle event log in Redis,
+// keeping the latest taskmanager.MaxLifecycleEvents.
+func (m *TaskManager) recordEvent(ctx context.Context, taskID string, event protocol.TaskLifecycleEvent) {
+	eventsKey := eventsPrefix + taskID
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("Failed to serialize lifecycle event for task %s: %v", taskID, err)
+		return
+	}
+	if err := m.client.RPush(ctx, eventsKey, eventBytes).Err(); err != nil {
+		log.Errorf("Failed to store lifecycle event for task %s in Redis: %v", taskID, err)
+		return
+	}
+	// Keep only the latest events, and set expiration on the list.
+	m.client.LTrim(ctx, eventsKey, -taskmanager.MaxLifecycleEvents, -1)
+	m.client.Expire(ctx, eventsKey, m.expiration)
+}
+
+// recordStatusEvent records a status change in the task's lifecycle event log,
+// noting the reason of a cancellation.
+func (m *TaskManager) recordStatusEvent(ctx context.Context, taskID string, status protocol.TaskStatus) {
+	var detail string
+	if status.CancelReason != nil {
+		detail = string(*status.CancelReason)
+	}
+	m.recordEvent(ctx, taskID, protocol.NewTaskLifecycleEvent(protocol.TaskLifecycleStatusChanged, status.State, detail))
+}
+
+// getEvents retrieves the lifecycle event log of a task.
+func (m *TaskManager) getEvents(ctx context.Context, taskID string) ([]protocol.TaskLifecycleEvent, error) {
+	eventsRaw, err := m.client.LRange(ctx, eventsPrefix+taskID, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve lifecycle events: %w", err)
+	}
+	events := make([]protocol.TaskLifecycleEvent, 0, len(eventsRaw))
+	for _, eventBytes := range eventsRaw {
+		var event protocol.TaskLifecycleEvent
+		if err := json.Unmarshal([]byte(eventBytes), &event); err != nil {
+			log.Errorf("Failed to deserialize lifecycle event for task %s: %v", taskID, err)
+			continue // Skip invalid events.
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
 // addSubscriber adds a channel to the list of subscribers for a task.
 func (m *TaskManager) addSubscriber(taskID string, ch chan<- protocol.TaskEvent) {
 	m.subMu.Lock()
@@ -659,26 +1556,38 @@ func (m *TaskManager) removeSubscriber(taskID string, ch chan<- protocol.TaskEve
 
 // notifySubscribers sends an event to all current subscribers of a task.
 func (m *TaskManager) notifySubscribers(taskID string, event protocol.TaskEvent) {
-	m.subMu.RLock()
-	subs, exists := m.subscribers[taskID]
-	if !exists || len(subs) == 0 {
-		m.subMu.RUnlock()
-		return // No subscribers to notify.
+	if watchdog, ok := m.watchdogs.Load(taskID); ok {
+		watchdog.(*taskmanager.ProcessorWatchdog).Reset()
+	}
+	m.enqueuePush(taskID, event)
+	var streamMu *sync.Mutex
+	if m.persistEvents {
+		// Held until the subscribers are copied; see resubscribeFromLog.
+		streamMu = m.streamLock(taskID)
+		streamMu.Lock()
+		m.appendStreamEvent(context.Background(), taskID, event)
 	}
 	// Copy the slice of channels under read lock
-	subsCopy := make([]chan<- protocol.TaskEvent, len(subs))
-	copy(subsCopy, subs)
+	m.subMu.RLock()
+	subsCopy := make([]chan<- protocol.TaskEvent, len(m.subscribers[taskID]))
+	copy(subsCopy, m.subscribers[taskID])
 	m.subMu.RUnlock()
+	if streamMu != nil {
+		streamMu.Unlock()
+	}
+	if len(subsCopy) == 0 {
+		return // No subscribers to notify.
+	}
 	log.Debugf("Notifying %d subscribers for task %s (Event Type: %T, Final: %t)",
 		len(subsCopy), taskID, event, event.IsFinal())
-	// Send events outside the lock.
+	// Send events outside the lock, blocking on full subscribers only with a send timeout.
+	timeout := time.Duration(m.sendTimeout.Load())
 	for _, ch := range subsCopy {
-		// Use a select with a default case for a non-blocking send.
-		select {
-		case ch <- event:
-			// Event sent successfully.
-		default:
-			// Channel buffer is full or channel is closed.
+		switch err := taskmanager.SendSubscriberEvent(ch, event, timeout); {
+		case errors.Is(err, taskmanager.ErrSlowSubscriber):
+			log.Warnf("Warning: Dropping task %s subscriber - no room for events for %v.", taskID, timeout)
+			m.removeSubscriber(taskID, ch)
+		case err != nil:
 			log.Warnf("Warning: Dropping event for task %s subscriber - channel full or closed.", taskID)
 		}
 	}
@@ -689,9 +1598,9 @@ func (m *TaskManager) Close() error {
 	// Cancel all active contexts.
 	m.cancelMu.Lock()
 	for _, cancel := range m.cancels {
-		cancel()
+		cancel(&taskmanager.CancelError{Reason: protocol.CancelReasonServerShutdown})
 	}
-	m.cancels = make(map[string]context.CancelFunc)
+	m.cancels = make(map[string]context.CancelCauseFunc)
 	m.cancelMu.Unlock()
 
 	// Close all subscriber channels.
@@ -714,6 +1623,14 @@ func (m *TaskManager) Close() error {
 	}
 	m.subscribers = make(map[string][]chan<- protocol.TaskEvent)
 	m.subMu.Unlock()
+	// Deliver the queued push notifications.
+	if m.pushQueue != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), pushDrainTimeout)
+		if err := m.pushQueue.Close(ctx); err != nil {
+			log.Warnf("Push notifications not delivered before close: %v", err)
+		}
+		cancel()
+	}
 	// Close the Redis client.
 	return m.client.Close()
 }
@@ -7,7 +7,11 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -17,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
 )
@@ -444,6 +449,70 @@ func TestE2E_PushNotifications(t *testing.T) {
 	assert.Equal(t, pushConfig.PushNotificationConfig.Token, retrievedConfig.PushNotificationConfig.Token)
 }
 
+// Test delivering push notifications to a webhook
+func TestE2E_PushDelivery(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []map[string]interface{}
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+			assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+			mu.Lock()
+			received = append(received, body["params"].(map[string]interface{}))
+			mu.Unlock()
+		}
+	}))
+	defer webhook.Close()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err, "Failed to create miniredis server")
+	defer mr.Close()
+	client := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{mr.Addr()}})
+	manager, err := NewRedisTaskManager(client, newTestProcessor(), WithPushDelivery())
+	require.NoError(t, err, "Failed to create Redis task manager")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Subscribe to a slow task so the config is in place before it completes
+	taskParams := protocol.SendTaskParams{
+		ID: "test-push-delivery",
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleUser,
+			Parts: []protocol.Part{protocol.NewTextPart("sleep:Task with push delivery")},
+		},
+	}
+	events, err := manager.OnSendTaskSubscribe(ctx, taskParams)
+	require.NoError(t, err, "Failed to send task")
+	_, err = manager.OnPushNotificationSet(ctx, protocol.TaskPushNotificationConfig{
+		ID: taskParams.ID,
+		PushNotificationConfig: protocol.PushNotificationConfig{
+			URL:            webhook.URL,
+			Token:          "test-token",
+			Authentication: &protocol.AuthenticationInfo{Schemes: []string{"bearer"}},
+		},
+	})
+	require.NoError(t, err, "Failed to set push notification config")
+	for event := range events {
+		if event.IsFinal() {
+			break
+		}
+	}
+
+	// Close waits for the queued notifications to be delivered
+	require.NoError(t, manager.Close())
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, received, "Webhook should receive notifications")
+	last := received[len(received)-1]
+	assert.Equal(t, taskParams.ID, last["id"])
+	assert.Equal(t, protocol.EventTaskStatusUpdate, last["eventType"])
+	status := last["event"].(map[string]interface{})["status"].(map[string]interface{})
+	assert.Equal(t, string(protocol.TaskStateCompleted), status["state"])
+}
+
 // Test error handling for non-existent tasks
 func TestE2E_ErrorHandling(t *testing.T) {
 	manager, mr := setupRedisTest(t)
@@ -549,3 +618,962 @@ func TestE2E_InputRequired(t *testing.T) {
 func intPtr(i int) *int {
 	return &i
 }
+
+// historyProcessor records the message history visible through the task handle.
+type historyProcessor struct {
+	mu      sync.Mutex
+	history []protocol.Message
+}
+
+// Process implements TaskProcessor.
+func (p *historyProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	initialMsg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	history, err := handle.GetMessageHistory()
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.history = history
+	p.mu.Unlock()
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// Test that seed messages are stored before the request message and visible to the processor
+func TestE2E_SeedMessages(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	processor := &historyProcessor{}
+	manager.processor = processor
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	taskParams := protocol.SendTaskParams{
+		ID: "seeded-task",
+		Message: protocol.Message{
+			Role:  protocol.MessageRoleUser,
+			Parts: []protocol.Part{protocol.NewTextPart("question")},
+		},
+		Messages: []protocol.Message{
+			{Role: protocol.MessageRoleUser, Parts: []protocol.Part{protocol.NewTextPart("system")}},
+			{Role: protocol.MessageRoleUser, Parts: []protocol.Part{protocol.NewTextPart("example question")}},
+			{Role: protocol.MessageRoleAgent, Parts: []protocol.Part{protocol.NewTextPart("example answer")}},
+		},
+	}
+	task, err := manager.OnSendTask(ctx, taskParams)
+	require.NoError(t, err, "Failed to send task")
+	assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+
+	processor.mu.Lock()
+	history := processor.history
+	processor.mu.Unlock()
+	require.Len(t, history, 4, "Processor should see all seed messages and the request message")
+	for i, text := range []string{"system", "example question", "example answer", "question"} {
+		assert.Equal(t, text, history[i].Parts[0].(protocol.TextPart).Text)
+	}
+	assert.Equal(t, protocol.MessageRoleAgent, history[2].Role)
+}
+
+// cancelReasonProcessor blocks until canceled and reports the cancellation reason it observed.
+type cancelReasonProcessor struct {
+	started chan struct{}
+	reasons chan protocol.CancelReason
+}
+
+// Process implements TaskProcessor.
+func (p *cancelReasonProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	initialMsg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	p.started <- struct{}{}
+	<-ctx.Done()
+	reason, _ := taskmanager.CancelReasonFromContext(ctx)
+	p.reasons <- reason
+	return ctx.Err()
+}
+
+// Test that the cancellation reason reaches the processor and the final task status
+func TestE2E_TaskCancellationReason(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	processor := &cancelReasonProcessor{
+		started: make(chan struct{}, 1),
+		reasons: make(chan protocol.CancelReason, 1),
+	}
+	manager.processor = processor
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, reason := range []protocol.CancelReason{
+		protocol.CancelReasonUserRequested,
+		protocol.CancelReasonTimeout,
+		protocol.CancelReasonServerShutdown,
+		protocol.CancelReasonSuperseded,
+	} {
+		taskID := "cancel-reason-" + string(reason)
+		_, err := manager.OnSendTaskSubscribe(ctx, protocol.SendTaskParams{
+			ID: taskID,
+			Message: protocol.Message{
+				Role:  protocol.MessageRoleUser,
+				Parts: []protocol.Part{protocol.NewTextPart("wait")},
+			},
+		})
+		require.NoError(t, err, "Failed to subscribe to task")
+		<-processor.started
+
+		cancelledTask, err := manager.OnCancelTask(ctx, protocol.TaskIDParams{ID: taskID, Reason: reason})
+		require.NoError(t, err, "Failed to cancel task")
+		require.NotNil(t, cancelledTask.Status.CancelReason)
+		assert.Equal(t, reason, *cancelledTask.Status.CancelReason)
+		assert.Equal(t, reason, <-processor.reasons, "Processor should observe the cancellation reason")
+
+		retrievedTask, err := manager.OnGetTask(ctx, protocol.TaskQueryParams{ID: taskID})
+		require.NoError(t, err, "Failed to retrieve task")
+		require.NotNil(t, retrievedTask.Status.CancelReason)
+		assert.Equal(t, reason, *retrievedTask.Status.CancelReason)
+	}
+}
+
+// completeAfterCancelProcessor completes its task regardless of being canceled.
+type completeAfterCancelProcessor struct {
+	started     chan struct{}
+	completeErr chan error
+}
+
+// Process implements TaskProcessor.
+func (p *completeAfterCancelProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	initialMsg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	p.started <- struct{}{}
+	<-ctx.Done()
+	p.completeErr <- handle.Complete(protocol.NewMessage(protocol.MessageRoleAgent,
+		[]protocol.Part{protocol.NewTextPart("done")}))
+	return nil
+}
+
+// Test that a processor cannot replace the canceled status of its task
+func TestE2E_CompleteAfterCancel(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	processor := &completeAfterCancelProcessor{
+		started:     make(chan struct{}, 1),
+		completeErr: make(chan error, 1),
+	}
+	manager.processor = processor
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sent := make(chan *protocol.Task, 1)
+	go func() {
+		task, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+			ID:      "complete-after-cancel",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("wait")}),
+		})
+		assert.NoError(t, err)
+		sent <- task
+	}()
+	<-processor.started
+	_, err := manager.OnCancelTask(ctx, protocol.TaskIDParams{ID: "complete-after-cancel"})
+	require.NoError(t, err, "Failed to cancel task")
+
+	assert.Error(t, <-processor.completeErr, "Complete should be rejected after the cancellation")
+	assert.Equal(t, protocol.TaskStateCanceled, (<-sent).Status.State)
+	task, err := manager.OnGetTask(ctx, protocol.TaskQueryParams{ID: "complete-after-cancel"})
+	require.NoError(t, err, "Failed to retrieve task")
+	assert.Equal(t, protocol.TaskStateCanceled, task.Status.State)
+	assert.Error(t, manager.CompleteTask("complete-after-cancel", protocol.NewMessage(protocol.MessageRoleAgent, nil)),
+		"A canceled task should not be completed")
+}
+
+// Test that tasks can be listed by the labels they were created with
+func TestE2E_ListTasksByLabels(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	manager.processor = &historyProcessor{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	labelled := map[string]map[string]string{
+		"task-a": {"customer": "acme", "env": "prod"},
+		"task-b": {"customer": "acme", "env": "staging"},
+		"task-c": {"customer": "globex", "env": "prod"},
+		"task-d": nil,
+	}
+	for id, labels := range labelled {
+		_, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+			ID:      id,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("labels")}),
+			Labels:  labels,
+		})
+		require.NoError(t, err, "Failed to send task")
+	}
+	taskIDs := func(tasks []protocol.Task) []string {
+		ids := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	tasks, err := manager.ListTasksByLabels(ctx, map[string]string{"customer": "acme"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"task-a", "task-b"}, taskIDs(tasks))
+
+	tasks, err = manager.ListTasksByLabels(ctx, map[string]string{"customer": "acme", "env": "prod"})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, map[string]string{"customer": "acme", "env": "prod"}, tasks[0].Labels)
+
+	tasks, err = manager.ListTasksByLabels(ctx, map[string]string{"customer": "initech"})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	tasks, err = manager.ListTasksByLabels(ctx, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"task-a", "task-b", "task-c", "task-d"}, taskIDs(tasks))
+
+	// Expired tasks are skipped even while their label entries remain.
+	mr.Del(taskPrefix + "task-a")
+	tasks, err = manager.ListTasksByLabels(ctx, map[string]string{"customer": "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task-b"}, taskIDs(tasks))
+}
+
+func TestE2E_PruneTasks(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	manager.processor = &historyProcessor{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, id := range []string{"done-task", "running-task"} {
+		_, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+			ID:      id,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("prune")}),
+			Labels:  map[string]string{"team": "a"},
+		})
+		require.NoError(t, err, "Failed to send task")
+	}
+	require.NoError(t, manager.UpdateTaskStatus("running-task", protocol.TaskStateWorking, nil))
+
+	// Nothing has finished before an hour ago.
+	pruned, err := manager.PruneTasks(ctx, time.Now().Add(-time.Hour), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, pruned)
+
+	var evicted []string
+	pruned, err = manager.PruneTasks(ctx, time.Now().Add(time.Hour), func(task protocol.Task) {
+		// The task is still readable while the hook archives it.
+		assert.True(t, mr.Exists(taskPrefix+task.ID))
+		evicted = append(evicted, task.ID)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	assert.Equal(t, []string{"done-task"}, evicted)
+
+	assert.False(t, mr.Exists(taskPrefix+"done-task"))
+	assert.False(t, mr.Exists(messagePrefix+"done-task"))
+	members, err := mr.SMembers(labelKey("team", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"running-task"}, members)
+	_, err = manager.OnGetTask(ctx, protocol.TaskQueryParams{ID: "running-task"})
+	assert.NoError(t, err)
+}
+
+// completeProcessor completes the task through TaskHandle.Complete and then
+// records the errors returned for further updates.
+type completeProcessor struct {
+	lateErrs []error
+}
+
+// Process implements TaskProcessor.
+func (p *completeProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	initialMsg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	result := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("done")})
+	if err := handle.Complete(result, protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart("output")}}); err != nil {
+		return err
+	}
+	p.lateErrs = []error{
+		handle.UpdateStatus(protocol.TaskStateWorking, nil),
+		handle.AddArtifact(protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart("late")}}),
+		handle.SendMessage(result),
+	}
+	return nil
+}
+
+func TestE2E_Complete(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	processor := &completeProcessor{}
+	manager.processor = processor
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	task, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+		ID:      "complete-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+	require.NotNil(t, task.Status.Message)
+	assert.Equal(t, "done", task.Status.Message.Parts[0].(protocol.TextPart).Text)
+	require.Len(t, task.Artifacts, 1)
+	assert.Equal(t, "output", task.Artifacts[0].Parts[0].(protocol.TextPart).Text)
+
+	require.Len(t, processor.lateErrs, 3)
+	for _, err := range processor.lateErrs {
+		assert.Error(t, err, "updates after Complete should be rejected")
+	}
+}
+
+// Test the lifecycle event log of completed and failed tasks
+func TestE2E_LifecycleEvents(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	summarize := func(events []protocol.TaskLifecycleEvent) []string {
+		var summary []string
+		for _, event := range events {
+			entry := string(event.Type)
+			if event.State != "" {
+				entry += ":" + string(event.State)
+			}
+			summary = append(summary, entry)
+		}
+		return summary
+	}
+
+	for _, tc := range []struct {
+		id, text string
+		want     []string
+	}{
+		{
+			id:   "test-events-completed",
+			text: "Task with events",
+			want: []string{"received", "status_changed:working", "processing_started", "status_changed:completed"},
+		},
+		{
+			id:   "test-events-failed",
+			text: "fail:Task with events",
+			want: []string{
+				"received", "status_changed:working", "processing_started",
+				"status_changed:working", "error", "status_changed:failed",
+			},
+		},
+	} {
+		_, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+			ID: tc.id,
+			Message: protocol.Message{
+				Role:  protocol.MessageRoleUser,
+				Parts: []protocol.Part{protocol.NewTextPart(tc.text)},
+			},
+		})
+		require.NoError(t, err, "Failed to send task")
+		task, err := manager.OnGetTask(ctx, protocol.TaskQueryParams{ID: tc.id, IncludeEvents: true})
+		require.NoError(t, err, "Failed to get task")
+		assert.Equal(t, tc.want, summarize(task.Events))
+		assert.True(t, mr.Exists("events:"+tc.id), "Lifecycle events should be stored in Redis")
+
+		task, err = manager.OnGetTask(ctx, protocol.TaskQueryParams{ID: tc.id})
+		require.NoError(t, err, "Failed to get task")
+		assert.Nil(t, task.Events, "Events should only be included on request")
+	}
+}
+
+func TestE2E_WaitForTaskChange(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	ctx := context.Background()
+
+	_, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+		ID:      "test-wait",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("Task to wait on")}),
+	})
+	require.NoError(t, err, "Failed to send task")
+	require.NoError(t, manager.WaitForTaskChange(ctx, "test-wait"), "A final task should not be waited on")
+
+	require.NoError(t, manager.UpdateTaskStatus("test-wait", protocol.TaskStateWorking, nil))
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, manager.WaitForTaskChange(timeoutCtx, "test-wait"), context.DeadlineExceeded)
+
+	woken := make(chan error, 1)
+	go func() { woken <- manager.WaitForTaskChange(ctx, "test-wait") }()
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, manager.UpdateTaskStatus("test-wait", protocol.TaskStateCompleted, nil))
+	select {
+	case err := <-woken:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Waiter was not woken by the status change")
+	}
+
+	assert.Error(t, manager.WaitForTaskChange(ctx, "test-wait-missing"))
+	manager.waitMu.Lock()
+	assert.Empty(t, manager.statusWaiters, "No waiter should be kept for a missing task")
+	manager.waitMu.Unlock()
+}
+
+func TestE2E_EstimatedCompletion(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	ctx := context.Background()
+
+	_, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+		ID:      "test-eta",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("Task with ETA")}),
+	})
+	require.NoError(t, err, "Failed to send task")
+	eta := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.Error(t, manager.SetEstimatedCompletion("test-eta", eta), "A final task should reject an estimate")
+
+	require.NoError(t, manager.UpdateTaskStatus("test-eta", protocol.TaskStateWorking, nil))
+	subCtx, cancelSub := context.WithCancel(ctx)
+	defer cancelSub()
+	eventChan, err := manager.OnResubscribe(subCtx, protocol.TaskIDParams{ID: "test-eta"})
+	require.NoError(t, err)
+	// Subscribers that are not receiving miss events, so forward them into a buffer.
+	events := make(chan protocol.TaskEvent, 10)
+	go func() {
+		for event := range eventChan {
+			events <- event
+		}
+	}()
+	<-events // Resubscribing replays the current status first.
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, manager.SetEstimatedCompletion("test-eta", eta))
+	select {
+	case event := <-events:
+		status, ok := event.(protocol.TaskStatusUpdateEvent)
+		require.True(t, ok, "Expected a status update, got %T", event)
+		assert.Equal(t, "2030-01-02T03:04:05Z", status.Status.EstimatedCompletion)
+	case <-time.After(time.Second):
+		t.Fatal("No status update for the estimate")
+	}
+
+	require.NoError(t, manager.UpdateTaskStatus("test-eta", protocol.TaskStateWorking, nil))
+	task, err := manager.OnGetTask(ctx, protocol.TaskQueryParams{ID: "test-eta"})
+	require.NoError(t, err)
+	got, ok := task.Status.EstimatedCompletionTime()
+	require.True(t, ok, "A working update should keep the estimate")
+	assert.True(t, eta.Equal(got))
+}
+
+// replaceDeleteProcessor replaces a draft artifact and deletes a scratch one.
+type replaceDeleteProcessor struct {
+	missingErr error
+}
+
+func (p *replaceDeleteProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	for _, artifact := range []protocol.Artifact{
+		{Index: 0, Parts: []protocol.Part{protocol.NewTextPart("draft")}},
+		{Index: 1, Parts: []protocol.Part{protocol.NewTextPart("scratch")}},
+	} {
+		if err := handle.AddArtifact(artifact); err != nil {
+			return err
+		}
+	}
+	if err := handle.ReplaceArtifact(protocol.Artifact{
+		Index: 0,
+		Parts: []protocol.Part{protocol.NewTextPart("final")},
+	}); err != nil {
+		return err
+	}
+	if err := handle.DeleteArtifact(1); err != nil {
+		return err
+	}
+	p.missingErr = handle.DeleteArtifact(1)
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// Test that replaced and deleted artifacts are persisted
+func TestE2E_ReplaceDeleteArtifacts(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	processor := &replaceDeleteProcessor{}
+	manager.processor = processor
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+		ID:      "replace-delete-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+	})
+	require.NoError(t, err)
+	assert.ErrorIs(t, processor.missingErr, protocol.ErrArtifactNotFound)
+
+	task, err := manager.OnGetTask(ctx, protocol.TaskQueryParams{ID: "replace-delete-task"})
+	require.NoError(t, err)
+	require.Len(t, task.Artifacts, 1)
+	assert.Equal(t, 0, task.Artifacts[0].Index)
+	assert.Equal(t, "final", task.Artifacts[0].Parts[0].(protocol.TextPart).Text)
+}
+
+// Test that the message history is capped by its encoded size
+func TestE2E_MaxHistoryBytes(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	ctx := context.Background()
+	message := func(text string) protocol.Message {
+		return protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)})
+	}
+	encoded, err := json.Marshal(message("aaaa"))
+	require.NoError(t, err)
+	size := len(encoded)
+	manager.SetMaxHistoryBytes(2 * size)
+	history := func() []string {
+		messages, err := manager.getMessageHistory(ctx, "history-task", 0)
+		require.NoError(t, err)
+		var texts []string
+		for _, msg := range messages {
+			texts = append(texts, msg.Parts[0].(protocol.TextPart).Text)
+		}
+		return texts
+	}
+
+	for _, text := range []string{"0000", "1111", "2222"} {
+		manager.storeMessage(ctx, "history-task", message(text))
+	}
+	assert.Equal(t, []string{"1111", "2222"}, history(), "oldest message evicted")
+
+	// A message larger than the whole cap is not stored and evicts nothing.
+	manager.storeMessage(ctx, "history-task", message(strings.Repeat("x", 2*size)))
+	assert.Equal(t, []string{"1111", "2222"}, history())
+}
+
+// hangingProcessor blocks without watching its context until release is closed.
+type hangingProcessor struct {
+	release chan struct{}
+}
+
+func (p *hangingProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	<-p.release
+	return nil
+}
+
+// Test that a hanging processor is stopped and its task failed after the timeout
+func TestE2E_ProcessorTimeout(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	processor := &hangingProcessor{release: make(chan struct{})}
+	defer close(processor.release)
+	manager.processor = processor
+	manager.SetProcessorTimeout(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	task, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+		ID:      "hanging-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateFailed, task.Status.State)
+	require.NotNil(t, task.Status.Message)
+	assert.Contains(t, task.Status.Message.Parts[0].(protocol.TextPart).Text, "timed out")
+}
+
+// artifactProcessor emits count distinct artifacts, each followed by an appended
+// chunk, then tries to complete the task, recording the errors returned.
+type artifactProcessor struct {
+	count int
+	errs  []error
+}
+
+// Process implements TaskProcessor.
+func (p *artifactProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	initialMsg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	appendChunk := true
+	for i := 0; i < p.count; i++ {
+		p.errs = append(p.errs,
+			handle.AddArtifact(protocol.Artifact{Index: i, Parts: []protocol.Part{protocol.NewTextPart("part")}}),
+			handle.AddArtifact(protocol.Artifact{
+				Index:  i,
+				Parts:  []protocol.Part{protocol.NewTextPart(" more")},
+				Append: &appendChunk,
+			}),
+		)
+	}
+	result := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("done")})
+	p.errs = append(p.errs, handle.Complete(result))
+	return nil
+}
+
+func TestE2E_MaxArtifactsPerTask(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	processor := &artifactProcessor{count: 3}
+	manager.processor = processor
+	manager.SetMaxArtifactsPerTask(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	task, err := manager.OnSendTask(ctx, protocol.SendTaskParams{
+		ID:      "artifact-cap",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateFailed, task.Status.State)
+	require.NotNil(t, task.Status.Message)
+	assert.Contains(t, task.Status.Message.Parts[0].(protocol.TextPart).Text, "limited to 2 artifacts")
+	assert.Len(t, task.Artifacts, 4, "two artifacts with one appended chunk each")
+
+	require.Len(t, processor.errs, 7)
+	for _, err := range processor.errs[:4] {
+		assert.NoError(t, err)
+	}
+	assert.ErrorIs(t, processor.errs[4], taskmanager.ErrTooManyArtifacts)
+	for _, err := range processor.errs[5:] {
+		assert.Error(t, err, "updates after the cap is exceeded should be rejected")
+	}
+}
+
+func TestE2E_TaskSnapshots(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	ctx := context.Background()
+
+	// Export from an in-memory manager and import into Redis.
+	source, err := taskmanager.NewMemoryTaskManager(&completeProcessor{})
+	require.NoError(t, err)
+	_, err = source.OnSendTask(ctx, protocol.SendTaskParams{
+		ID:       "snapshot-task",
+		Message:  protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+		Metadata: map[string]interface{}{"owner": "alice"},
+		Labels:   map[string]string{"team": "docs"},
+	})
+	require.NoError(t, err)
+	snapshot, err := taskmanager.ExportTask(ctx, source, "snapshot-task")
+	require.NoError(t, err)
+
+	_, err = taskmanager.ImportTask(ctx, manager, protocol.TaskImportParams{Snapshot: *snapshot})
+	require.NoError(t, err)
+	exported, err := taskmanager.ExportTask(ctx, manager, "snapshot-task")
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.Task, exported.Task)
+	tasks, err := manager.ListTasksByLabels(ctx, map[string]string{"team": "docs"})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	_, err = taskmanager.ImportTask(ctx, manager, protocol.TaskImportParams{Snapshot: *snapshot})
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, taskmanager.ErrCodeTaskExists, rpcErr.Code)
+
+	renamed, err := taskmanager.ImportTask(ctx, manager, protocol.TaskImportParams{
+		Snapshot: *snapshot, OnConflict: protocol.ImportConflictRename,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot-task-imported-1", renamed.ID)
+
+	replacement := *snapshot
+	replacement.Task.Labels = map[string]string{"team": "ops"}
+	replacement.Task.History = nil
+	_, err = taskmanager.ImportTask(ctx, manager, protocol.TaskImportParams{
+		Snapshot: replacement, OnConflict: protocol.ImportConflictReplace,
+	})
+	require.NoError(t, err)
+	exported, err = taskmanager.ExportTask(ctx, manager, "snapshot-task")
+	require.NoError(t, err)
+	assert.Empty(t, exported.Task.History)
+	tasks, err = manager.ListTasksByLabels(ctx, map[string]string{"team": "docs"})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1, "only the renamed copy keeps the old label")
+}
+
+// gatedProcessor completes each task once its gate, if any, is closed, recording
+// the order in which tasks started processing.
+type gatedProcessor struct {
+	mu    sync.Mutex
+	gates map[string]chan struct{}
+	ran   []string
+}
+
+// Process implements TaskProcessor.
+func (p *gatedProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	p.mu.Lock()
+	p.ran = append(p.ran, taskID)
+	gate := p.gates[taskID]
+	p.mu.Unlock()
+	if gate != nil {
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+func (p *gatedProcessor) started() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.ran...)
+}
+
+// Test that a task depending on another runs only after it completed
+func TestE2E_TaskDependencies(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	gate := make(chan struct{})
+	processor := &gatedProcessor{gates: map[string]chan struct{}{"prereq": gate}}
+	manager.processor = processor
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	message := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")})
+
+	_, err := manager.OnSendTaskSubscribe(ctx, protocol.SendTaskParams{ID: "prereq", Message: message})
+	require.NoError(t, err)
+	events, err := manager.OnSendTaskSubscribe(ctx, protocol.SendTaskParams{
+		ID:        "dependent",
+		Message:   message,
+		DependsOn: []string{"prereq"},
+	})
+	require.NoError(t, err)
+	pending := (<-events).(protocol.TaskStatusUpdateEvent)
+	assert.Equal(t, protocol.TaskStateSubmitted, pending.Status.State)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, []string{"prereq"}, processor.started())
+
+	close(gate)
+	var last protocol.TaskStatusUpdateEvent
+	for event := range events {
+		if status, ok := event.(protocol.TaskStatusUpdateEvent); ok {
+			last = status
+		}
+	}
+	assert.Equal(t, protocol.TaskStateCompleted, last.Status.State)
+	assert.Equal(t, []string{"prereq", "dependent"}, processor.started())
+
+	// A task cannot then become a dependency of its own dependent.
+	_, err = manager.OnSendTask(ctx, protocol.SendTaskParams{
+		ID:        "prereq",
+		Message:   message,
+		DependsOn: []string{"dependent"},
+	})
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+}
+
+// stoppingProcessor reports a failure of its own when its context is canceled,
+// as processors wrapping a canceled call often do.
+type stoppingProcessor struct {
+	stopErr chan error // Receives the error of the failure report.
+}
+
+// Process implements TaskProcessor.
+func (p *stoppingProcessor) Process(
+	ctx context.Context, taskID string, msg protocol.Message, handle taskmanager.TaskHandle,
+) error {
+	<-ctx.Done()
+	p.stopErr <- handle.UpdateStatus(protocol.TaskStateFailed, nil)
+	return ctx.Err()
+}
+
+// Test that canceling a streaming task ends its stream with the canceled status.
+func TestE2E_CancelStreamingTask(t *testing.T) {
+	manager, mr := setupRedisTest(t)
+	defer mr.Close()
+	defer manager.Close()
+	processor := &stoppingProcessor{stopErr: make(chan error, 1)}
+	manager.processor = processor
+	ctx := context.Background()
+
+	eventChan, err := manager.OnSendTaskSubscribe(ctx, protocol.SendTaskParams{
+		ID:      "stream-cancel",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("wait")}),
+	})
+	require.NoError(t, err)
+	select {
+	case event := <-eventChan:
+		require.Equal(t, protocol.TaskStateWorking, event.(protocol.TaskStatusUpdateEvent).Status.State)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the task to start")
+	}
+
+	_, err = manager.OnCancelTask(ctx, protocol.TaskIDParams{ID: "stream-cancel"})
+	require.NoError(t, err)
+	var events []protocol.TaskEvent
+	timeout := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case event, ok := <-eventChan:
+			if !ok {
+				done = true
+				break
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatal("timed out waiting for the stream to close")
+		}
+	}
+	require.Len(t, events, 1, "the canceled status is the last event before the channel closes")
+	statusEvent, ok := events[0].(protocol.TaskStatusUpdateEvent)
+	require.True(t, ok)
+	assert.True(t, statusEvent.Final)
+	assert.Equal(t, protocol.TaskStateCanceled, statusEvent.Status.State)
+
+	// The processor's own report of the stop does not replace the canceled status.
+	assert.Error(t, <-processor.stopErr)
+	task, err := manager.OnGetTask(ctx, protocol.TaskQueryParams{ID: "stream-cancel"})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskStateCanceled, task.Status.State)
+}
+
+func TestE2E_PersistentEventLog(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err, "Failed to create miniredis server")
+	defer mr.Close()
+	newManager := func(t *testing.T) *TaskManager {
+		client := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{mr.Addr()}})
+		manager, err := NewRedisTaskManager(client, newTestProcessor(), WithPersistentEventLog())
+		require.NoError(t, err, "Failed to create Redis task manager")
+		return manager
+	}
+	// collect describes the events of ch until it is closed.
+	collect := func(t *testing.T, ch <-chan protocol.TaskEvent) []string {
+		var events []string
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case event, ok := <-ch:
+				if !ok {
+					return events
+				}
+				switch e := event.(type) {
+				case protocol.TaskStatusUpdateEvent:
+					events = append(events, fmt.Sprintf("status:%s:%t", e.Status.State, e.Final))
+				case protocol.TaskArtifactUpdateEvent:
+					events = append(events, fmt.Sprintf("artifact:%d", e.Artifact.Index))
+				default:
+					events = append(events, fmt.Sprintf("%T", event))
+				}
+			case <-timeout:
+				t.Fatal("Test timed out waiting for events")
+			}
+		}
+	}
+	send := func(id, text string) protocol.SendTaskParams {
+		return protocol.SendTaskParams{
+			ID:      id,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)}),
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("ReplayedAfterRestart", func(t *testing.T) {
+		first := newManager(t)
+		events, err := first.OnSendTaskSubscribe(ctx, send("restart-task", "artifacts:persisted"))
+		require.NoError(t, err)
+		streamed := collect(t, events)
+		require.NoError(t, first.Close())
+		assert.Contains(t, streamed, "artifact:2")
+
+		// A new manager over the same store replays what the first one sent.
+		second := newManager(t)
+		defer second.Close()
+		replay, err := second.OnResubscribe(ctx, protocol.TaskIDParams{ID: "restart-task"})
+		require.NoError(t, err)
+		assert.Equal(t, streamed, collect(t, replay))
+	})
+
+	t.Run("LiveAfterReplay", func(t *testing.T) {
+		manager := newManager(t)
+		defer manager.Close()
+		events, err := manager.OnSendTaskSubscribe(ctx, send("live-task", "cancel:slow"))
+		require.NoError(t, err)
+		streamed := make(chan []string, 1)
+		go func() { streamed <- collect(t, events) }()
+		time.Sleep(250 * time.Millisecond)
+
+		// Joining mid-task gets every event once, in order.
+		resubscribed, err := manager.OnResubscribe(ctx, protocol.TaskIDParams{ID: "live-task"})
+		require.NoError(t, err)
+		assert.Equal(t, <-streamed, collect(t, resubscribed))
+	})
+
+	t.Run("TrimmedAndExpiring", func(t *testing.T) {
+		manager := newManager(t)
+		defer manager.Close()
+		for i := 0; i < maxStreamEvents+5; i++ {
+			manager.notifySubscribers("trimmed-task", protocol.TaskStatusUpdateEvent{
+				ID:     "trimmed-task",
+				Status: protocol.TaskStatus{State: protocol.TaskStateWorking},
+			})
+		}
+		streamKey := streamPrefix + "trimmed-task"
+		entries, err := mr.List(streamKey)
+		require.NoError(t, err)
+		assert.Len(t, entries, maxStreamEvents, "the log keeps the latest events")
+		assert.Greater(t, mr.TTL(streamKey), time.Duration(0), "the log expires with the task")
+	})
+
+	t.Run("DeletedWithTask", func(t *testing.T) {
+		manager := newManager(t)
+		defer manager.Close()
+		_, err := manager.OnSendTask(ctx, send("pruned-task", "done"))
+		require.NoError(t, err)
+		require.True(t, mr.Exists(streamPrefix+"pruned-task"))
+		_, err = manager.PruneTasks(ctx, time.Now().Add(time.Minute), nil)
+		require.NoError(t, err)
+		assert.False(t, mr.Exists(streamPrefix+"pruned-task"))
+	})
+}
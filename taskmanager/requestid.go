@@ -0,0 +1,26 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import "context"
+
+// requestIDKey is the context key for the JSON-RPC request ID.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the JSON-RPC request being
+// handled. The server sets it before calling the task manager, which records it on
+// the tasks the request creates.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the JSON-RPC request ID stored in ctx, or an empty
+// string if none is set.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,62 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+)
+
+// maxImportRenames bounds the IDs tried when importing with ImportConflictRename.
+const maxImportRenames = 100
+
+// ExportTask returns a portable snapshot of the task managed by tm, which must
+// implement TaskSnapshotter.
+func ExportTask(ctx context.Context, tm TaskManager, taskID string) (*protocol.TaskSnapshot, error) {
+	snapshotter, ok := tm.(TaskSnapshotter)
+	if !ok {
+		return nil, fmt.Errorf("task manager %T does not implement TaskSnapshotter", tm)
+	}
+	return snapshotter.ExportTask(ctx, taskID)
+}
+
+// ImportTask stores the task of a snapshot in tm, which must implement
+// TaskSnapshotter, and returns it as stored. Snapshots of a newer format version
+// than protocol.TaskSnapshotVersion are rejected. If the task ID is taken, the
+// import fails with ErrTaskExists, replaces the existing task, or stores the task
+// as "<id>-imported-<n>", as selected by params.OnConflict.
+func ImportTask(ctx context.Context, tm TaskManager, params protocol.TaskImportParams) (*protocol.Task, error) {
+	snapshotter, ok := tm.(TaskSnapshotter)
+	if !ok {
+		return nil, fmt.Errorf("task manager %T does not implement TaskSnapshotter", tm)
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	task := params.Snapshot.Task
+	err := snapshotter.RestoreTask(ctx, task, params.OnConflict == protocol.ImportConflictReplace)
+	if params.OnConflict == protocol.ImportConflictRename {
+		for n := 1; isTaskExists(err) && n <= maxImportRenames; n++ {
+			task.ID = fmt.Sprintf("%s-imported-%d", params.Snapshot.Task.ID, n)
+			err = snapshotter.RestoreTask(ctx, task, false)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tm.OnGetTask(ctx, protocol.TaskQueryParams{ID: task.ID})
+}
+
+// isTaskExists reports whether err is an ErrTaskExists error.
+func isTaskExists(err error) bool {
+	var rpcErr *jsonrpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.Code == ErrCodeTaskExists
+}
@@ -7,6 +7,12 @@
 package taskmanager
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/log"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 )
 
@@ -16,16 +22,168 @@ import (
 type memoryTaskHandle struct {
 	taskID  string
 	manager *MemoryTaskManager
+	ctx     context.Context // The processor's context, if OnCancelTask can cancel it.
+	input   *taskInput      // Input streamed to the task while the processor runs.
+
+	mu     sync.Mutex         // Serializes updates so none can slip in after Complete.
+	sealed protocol.TaskState // Set by Complete or a processor timeout; later updates are rejected.
+}
+
+// canceled reports whether OnCancelTask stopped the processor. Its updates are
+// then rejected so they cannot replace the canceled status.
+func (h *memoryTaskHandle) canceled() bool {
+	if h.ctx == nil {
+		return false
+	}
+	_, ok := CancelReasonFromContext(h.ctx)
+	return ok
 }
 
 // UpdateStatus implements TaskHandle.
 func (h *memoryTaskHandle) UpdateStatus(state protocol.TaskState, msg *protocol.Message) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	if h.canceled() {
+		return ErrTaskFinalState(h.taskID, protocol.TaskStateCanceled)
+	}
 	return h.manager.UpdateTaskStatus(h.taskID, state, msg)
 }
 
 // AddArtifact implements TaskHandle.
 func (h *memoryTaskHandle) AddArtifact(artifact protocol.Artifact) error {
-	return h.manager.AddArtifact(h.taskID, artifact)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.limitArtifacts(h.manager.AddArtifact(h.taskID, artifact))
+}
+
+// PatchArtifact implements TaskHandle.
+func (h *memoryTaskHandle) PatchArtifact(index, part int, patch []protocol.PatchOperation) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.manager.PatchArtifact(h.taskID, index, part, patch)
+}
+
+// ReplaceArtifact implements TaskHandle.
+func (h *memoryTaskHandle) ReplaceArtifact(artifact protocol.Artifact) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.limitArtifacts(h.manager.ReplaceArtifact(h.taskID, artifact))
+}
+
+// DeleteArtifact implements TaskHandle.
+func (h *memoryTaskHandle) DeleteArtifact(index int) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.manager.DeleteArtifact(h.taskID, index)
+}
+
+// SendMessage implements TaskHandle.
+func (h *memoryTaskHandle) SendMessage(msg protocol.Message) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.manager.SendMessage(h.taskID, msg)
+}
+
+// Complete implements TaskHandle.
+func (h *memoryTaskHandle) Complete(msg protocol.Message, artifacts ...protocol.Artifact) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	if h.canceled() {
+		return ErrTaskFinalState(h.taskID, protocol.TaskStateCanceled)
+	}
+	if err := h.manager.CompleteTask(h.taskID, msg, artifacts...); err != nil {
+		return h.limitArtifacts(err)
+	}
+	h.sealed = protocol.TaskStateCompleted
+	return nil
+}
+
+// Fail implements TaskHandle.
+func (h *memoryTaskHandle) Fail(err error, retryable bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	if h.canceled() {
+		return ErrTaskFinalState(h.taskID, protocol.TaskStateCanceled)
+	}
+	if updateErr := h.manager.updateTaskStatus(h.taskID, FailureStatus(err, retryable)); updateErr != nil {
+		return updateErr
+	}
+	h.sealed = protocol.TaskStateFailed
+	return nil
+}
+
+// SetEstimatedCompletion implements TaskHandle.
+func (h *memoryTaskHandle) SetEstimatedCompletion(eta time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sealed != "" {
+		return ErrTaskFinalState(h.taskID, h.sealed)
+	}
+	return h.manager.SetEstimatedCompletion(h.taskID, eta)
+}
+
+// fail fails the task with msg and rejects later updates, unless the task already
+// reached a final state.
+func (h *memoryTaskHandle) fail(msg *protocol.Message) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.failLocked(msg)
+}
+
+// failLocked is fail for callers holding h.mu.
+func (h *memoryTaskHandle) failLocked(msg *protocol.Message) error {
+	if h.sealed != "" {
+		return nil
+	}
+	task, err := h.manager.getTaskInternal(h.taskID)
+	if err != nil {
+		return err
+	}
+	if task.Status.State.IsFinal() {
+		h.sealed = task.Status.State
+		return nil
+	}
+	h.sealed = protocol.TaskStateFailed
+	return h.manager.UpdateTaskStatus(h.taskID, protocol.TaskStateFailed, msg)
+}
+
+// limitArtifacts fails the task if err reports it exceeded its artifact cap, and
+// returns err.
+func (h *memoryTaskHandle) limitArtifacts(err error) error {
+	if errors.Is(err, ErrTooManyArtifacts) {
+		msg := &protocol.Message{
+			Role:  protocol.MessageRoleAgent,
+			Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
+		}
+		if failErr := h.failLocked(msg); failErr != nil {
+			log.Errorf("Failed to update task %s status to failed: %v", h.taskID, failErr)
+		}
+	}
+	return err
 }
 
 // IsStreamingRequest checks if this task was initiated with a streaming request (OnSendTaskSubscribe).
@@ -39,8 +197,10 @@ func (h *memoryTaskHandle) IsStreamingRequest() bool {
 	return exists && len(subscribers) > 0
 }
 
-// isFinalState checks if a TaskState represents a terminal state.
-// Not exported as it's an internal helper.
-func isFinalState(state protocol.TaskState) bool {
-	return state == protocol.TaskStateCompleted || state == protocol.TaskStateFailed || state == protocol.TaskStateCanceled
+// GetMessageHistory implements TaskHandle.
+func (h *memoryTaskHandle) GetMessageHistory() ([]protocol.Message, error) {
+	h.manager.MessagesMutex.RLock()
+	defer h.manager.MessagesMutex.RUnlock()
+	history := h.manager.Messages[h.taskID]
+	return append([]protocol.Message(nil), history...), nil
 }
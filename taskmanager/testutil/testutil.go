@@ -0,0 +1,437 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+// Package testutil provides a harness for unit testing TaskProcessor implementations
+// without running a server or task manager.
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"trpc.group/trpc-go/trpc-a2a-go/internal/checksum"
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+)
+
+// DefaultTaskID is the task ID used by RunProcessor unless WithTaskID is given.
+const DefaultTaskID = "test-task"
+
+// ErrNotInputRequired is returned by Result.Continue when the task is not waiting for input.
+var ErrNotInputRequired = errors.New("task is not in input-required state")
+
+var (
+	_ taskmanager.TaskHandle  = (*Handle)(nil)
+	_ taskmanager.InputReader = (*Handle)(nil)
+)
+
+// Handle is a fake taskmanager.TaskHandle that records everything a processor emits.
+// It is safe for concurrent use.
+type Handle struct {
+	taskID    string
+	streaming bool
+	input     chan protocol.Message
+
+	mu        sync.Mutex
+	status    protocol.TaskStatus
+	events    []protocol.TaskEvent
+	artifacts []protocol.Artifact
+	history   []protocol.Message
+	completed bool // Set by Complete or Fail; later updates are rejected.
+}
+
+// NewHandle creates a new recording handle for the given task. Its input holds
+// the given messages, see Input.
+func NewHandle(taskID string, streaming bool, input ...protocol.Message) *Handle {
+	h := &Handle{
+		taskID:    taskID,
+		streaming: streaming,
+		input:     make(chan protocol.Message, len(input)),
+		status:    protocol.TaskStatus{State: protocol.TaskStateSubmitted},
+	}
+	for _, message := range input {
+		h.input <- message
+	}
+	close(h.input)
+	return h
+}
+
+// UpdateStatus implements taskmanager.TaskHandle.
+func (h *Handle) UpdateStatus(state protocol.TaskState, msg *protocol.Message) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.completed {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.status.State)
+	}
+	status := protocol.TaskStatus{
+		State:     state,
+		Message:   msg,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	if state == protocol.TaskStateWorking && h.status.State == protocol.TaskStateWorking {
+		status.EstimatedCompletion = h.status.EstimatedCompletion
+	}
+	h.status = status
+	if msg != nil {
+		h.history = append(h.history, *msg)
+	}
+	h.events = append(h.events, protocol.TaskStatusUpdateEvent{
+		ID:     h.taskID,
+		Status: h.status,
+		Final:  state.IsFinal(),
+	})
+	return nil
+}
+
+// AddArtifact implements taskmanager.TaskHandle.
+func (h *Handle) AddArtifact(artifact protocol.Artifact) error {
+	checksum.EnsureArtifact(&artifact)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.completed {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.status.State)
+	}
+	h.artifacts = append(h.artifacts, artifact)
+	h.events = append(h.events, protocol.TaskArtifactUpdateEvent{
+		ID:       h.taskID,
+		Artifact: artifact,
+		Final:    artifact.LastChunk != nil && *artifact.LastChunk,
+	})
+	return nil
+}
+
+// PatchArtifact implements taskmanager.TaskHandle.
+func (h *Handle) PatchArtifact(index, part int, patch []protocol.PatchOperation) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.completed {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.status.State)
+	}
+	artifacts, err := protocol.PatchArtifacts(h.artifacts, index, part, patch)
+	if err != nil {
+		return err
+	}
+	h.artifacts = artifacts
+	h.events = append(h.events, protocol.TaskArtifactPatchEvent{
+		ID:    h.taskID,
+		Index: index,
+		Part:  part,
+		Patch: patch,
+	})
+	return nil
+}
+
+// ReplaceArtifact implements taskmanager.TaskHandle.
+func (h *Handle) ReplaceArtifact(artifact protocol.Artifact) error {
+	checksum.EnsureArtifact(&artifact)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.completed {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.status.State)
+	}
+	h.artifacts = protocol.ReplaceArtifacts(h.artifacts, artifact)
+	artifact = h.artifacts[len(h.artifacts)-1]
+	h.events = append(h.events, protocol.TaskArtifactUpdateEvent{
+		ID:       h.taskID,
+		Artifact: artifact,
+		Final:    artifact.LastChunk != nil && *artifact.LastChunk,
+	})
+	return nil
+}
+
+// DeleteArtifact implements taskmanager.TaskHandle.
+func (h *Handle) DeleteArtifact(index int) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.completed {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.status.State)
+	}
+	artifacts, err := protocol.DeleteArtifacts(h.artifacts, index)
+	if err != nil {
+		return err
+	}
+	h.artifacts = artifacts
+	h.events = append(h.events, protocol.TaskArtifactDeleteEvent{ID: h.taskID, Index: index})
+	return nil
+}
+
+// SendMessage implements taskmanager.TaskHandle.
+func (h *Handle) SendMessage(msg protocol.Message) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.completed {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.status.State)
+	}
+	h.history = append(h.history, msg)
+	h.events = append(h.events, protocol.TaskMessageEvent{
+		ID:      h.taskID,
+		Message: msg,
+	})
+	return nil
+}
+
+// Complete implements taskmanager.TaskHandle.
+func (h *Handle) Complete(msg protocol.Message, artifacts ...protocol.Artifact) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.completed {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.status.State)
+	}
+	h.completed = true
+	for _, artifact := range artifacts {
+		checksum.EnsureArtifact(&artifact)
+		h.artifacts = append(h.artifacts, artifact)
+		h.events = append(h.events, protocol.TaskArtifactUpdateEvent{
+			ID:       h.taskID,
+			Artifact: artifact,
+			Final:    artifact.LastChunk != nil && *artifact.LastChunk,
+		})
+	}
+	h.history = append(h.history, msg)
+	h.status = protocol.TaskStatus{
+		State:     protocol.TaskStateCompleted,
+		Message:   &msg,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	h.events = append(h.events, protocol.TaskStatusUpdateEvent{
+		ID:     h.taskID,
+		Status: h.status,
+		Final:  true,
+	})
+	return nil
+}
+
+// Fail implements taskmanager.TaskHandle.
+func (h *Handle) Fail(err error, retryable bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.completed {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.status.State)
+	}
+	h.completed = true
+	h.status = taskmanager.FailureStatus(err, retryable)
+	h.status.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	h.history = append(h.history, *h.status.Message)
+	h.events = append(h.events, protocol.TaskStatusUpdateEvent{
+		ID:     h.taskID,
+		Status: h.status,
+		Final:  true,
+	})
+	return nil
+}
+
+// SetEstimatedCompletion implements taskmanager.TaskHandle.
+func (h *Handle) SetEstimatedCompletion(eta time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.completed {
+		return taskmanager.ErrTaskFinalState(h.taskID, h.status.State)
+	}
+	if h.status.State != protocol.TaskStateWorking {
+		return fmt.Errorf("task %s is %s: estimated completion can only be set while working", h.taskID, h.status.State)
+	}
+	h.status.EstimatedCompletion = eta.UTC().Format(time.RFC3339)
+	h.status.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	h.events = append(h.events, protocol.TaskStatusUpdateEvent{
+		ID:     h.taskID,
+		Status: h.status,
+	})
+	return nil
+}
+
+// IsStreamingRequest implements taskmanager.TaskHandle.
+func (h *Handle) IsStreamingRequest() bool {
+	return h.streaming
+}
+
+// GetMessageHistory implements taskmanager.TaskHandle.
+func (h *Handle) GetMessageHistory() ([]protocol.Message, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]protocol.Message(nil), h.history...), nil
+}
+
+// Input implements taskmanager.InputReader, returning the input messages given to
+// NewHandle or WithInput, followed by the end of input.
+func (h *Handle) Input() <-chan protocol.Message {
+	return h.input
+}
+
+// AddMessages appends messages to the task history, as the task manager does
+// for the messages of a send request.
+func (h *Handle) AddMessages(messages ...protocol.Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.history = append(h.history, messages...)
+}
+
+// Status returns the most recent status reported through the handle.
+func (h *Handle) Status() protocol.TaskStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+// Events returns a copy of all events recorded so far, in emission order.
+func (h *Handle) Events() []protocol.TaskEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]protocol.TaskEvent(nil), h.events...)
+}
+
+// Artifacts returns a copy of all artifacts recorded so far, in emission order.
+func (h *Handle) Artifacts() []protocol.Artifact {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]protocol.Artifact(nil), h.artifacts...)
+}
+
+// StatusUpdates returns a copy of all status update events recorded so far.
+func (h *Handle) StatusUpdates() []protocol.TaskStatusUpdateEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var updates []protocol.TaskStatusUpdateEvent
+	for _, event := range h.events {
+		if update, ok := event.(protocol.TaskStatusUpdateEvent); ok {
+			updates = append(updates, update)
+		}
+	}
+	return updates
+}
+
+// Option configures a RunProcessor call.
+type Option func(*runOptions)
+
+type runOptions struct {
+	taskID    string
+	streaming bool
+	seed      []protocol.Message
+	input     []protocol.Message
+}
+
+// WithTaskID sets the task ID passed to the processor.
+func WithTaskID(taskID string) Option {
+	return func(o *runOptions) {
+		o.taskID = taskID
+	}
+}
+
+// WithStreaming makes the handle report the task as a streaming request.
+func WithStreaming(streaming bool) Option {
+	return func(o *runOptions) {
+		o.streaming = streaming
+	}
+}
+
+// WithSeedMessages seeds the task history with the given messages before the
+// run message, like SendTaskParams.Messages.
+func WithSeedMessages(messages ...protocol.Message) Option {
+	return func(o *runOptions) {
+		o.seed = append(o.seed, messages...)
+	}
+}
+
+// WithInput streams messages to the processor as input sent with tasks/sendInput,
+// the last one being final. See taskmanager.InputReader.
+func WithInput(messages ...protocol.Message) Option {
+	return func(o *runOptions) {
+		o.input = append(o.input, messages...)
+	}
+}
+
+// Result holds the events recorded while running a processor.
+type Result struct {
+	// TaskID is the ID of the task that was processed.
+	TaskID string
+	// Handle is the recording handle passed to the processor.
+	Handle *Handle
+
+	processor taskmanager.TaskProcessor
+}
+
+// Events returns all recorded events in emission order.
+func (r *Result) Events() []protocol.TaskEvent {
+	return r.Handle.Events()
+}
+
+// StatusUpdates returns all recorded status update events.
+func (r *Result) StatusUpdates() []protocol.TaskStatusUpdateEvent {
+	return r.Handle.StatusUpdates()
+}
+
+// Artifacts returns all recorded artifacts.
+func (r *Result) Artifacts() []protocol.Artifact {
+	return r.Handle.Artifacts()
+}
+
+// FinalStatus returns the last status reported for the task.
+func (r *Result) FinalStatus() protocol.TaskStatus {
+	return r.Handle.Status()
+}
+
+// FinalState returns the state of the last status reported for the task.
+func (r *Result) FinalState() protocol.TaskState {
+	return r.Handle.Status().State
+}
+
+// Continue simulates the client answering an input-required prompt by running the
+// processor again for the same task with the given message. Events are appended to
+// the existing result.
+func (r *Result) Continue(ctx context.Context, message protocol.Message) error {
+	if state := r.FinalState(); state != protocol.TaskStateInputRequired {
+		return fmt.Errorf("%w: current state is %s", ErrNotInputRequired, state)
+	}
+	return process(ctx, r.processor, r.TaskID, message, r.Handle)
+}
+
+// RunProcessor drives the processor through a single task with the given message,
+// mimicking the task manager lifecycle: the task is set to working before Process is
+// called, and to failed if Process returns an error. The returned Result is always
+// non-nil; the error is the one returned by the processor.
+func RunProcessor(
+	ctx context.Context,
+	processor taskmanager.TaskProcessor,
+	message protocol.Message,
+	opts ...Option,
+) (*Result, error) {
+	o := &runOptions{taskID: DefaultTaskID}
+	for _, opt := range opts {
+		opt(o)
+	}
+	result := &Result{
+		TaskID:    o.taskID,
+		Handle:    NewHandle(o.taskID, o.streaming, o.input...),
+		processor: processor,
+	}
+	result.Handle.AddMessages(o.seed...)
+	return result, process(ctx, processor, o.taskID, message, result.Handle)
+}
+
+// process runs one Process call against the handle.
+func process(
+	ctx context.Context,
+	processor taskmanager.TaskProcessor,
+	taskID string,
+	message protocol.Message,
+	handle *Handle,
+) error {
+	handle.AddMessages(message)
+	if err := handle.UpdateStatus(protocol.TaskStateWorking, nil); err != nil {
+		return err
+	}
+	if err := processor.Process(ctx, taskID, message, handle); err != nil {
+		errMsg := &protocol.Message{
+			Role:  protocol.MessageRoleAgent,
+			Parts: []protocol.Part{protocol.NewTextPart(err.Error())},
+		}
+		_ = handle.UpdateStatus(protocol.TaskStateFailed, errMsg)
+		return err
+	}
+	return nil
+}
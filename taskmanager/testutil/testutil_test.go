@@ -0,0 +1,273 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-a2a-go/protocol"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
+	"trpc.group/trpc-go/trpc-a2a-go/taskmanager/testutil"
+)
+
+// upperProcessor emits the upper-cased input text as an artifact.
+type upperProcessor struct{}
+
+func (upperProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	text := msg.Parts[0].(protocol.TextPart).Text
+	lastChunk := true
+	if err := handle.AddArtifact(protocol.Artifact{
+		Name:      stringPtr("result"),
+		Parts:     []protocol.Part{protocol.NewTextPart(strings.ToUpper(text))},
+		LastChunk: &lastChunk,
+	}); err != nil {
+		return err
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// greeterProcessor asks for a name before completing.
+type greeterProcessor struct{}
+
+func (greeterProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	text := msg.Parts[0].(protocol.TextPart).Text
+	if text == "hello" {
+		prompt := protocol.NewMessage(protocol.MessageRoleAgent,
+			[]protocol.Part{protocol.NewTextPart("What is your name?")})
+		return handle.UpdateStatus(protocol.TaskStateInputRequired, &prompt)
+	}
+	if err := handle.AddArtifact(protocol.Artifact{
+		Parts: []protocol.Part{protocol.NewTextPart("Hello, " + text)},
+	}); err != nil {
+		return err
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func textMessage(text string) protocol.Message {
+	return protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)})
+}
+
+func TestRunProcessor(t *testing.T) {
+	result, err := testutil.RunProcessor(context.Background(), upperProcessor{}, textMessage("abc"),
+		testutil.WithTaskID("upper-task"), testutil.WithStreaming(true))
+	require.NoError(t, err)
+
+	assert.Equal(t, "upper-task", result.TaskID)
+	assert.True(t, result.Handle.IsStreamingRequest())
+	assert.Equal(t, protocol.TaskStateCompleted, result.FinalState())
+
+	updates := result.StatusUpdates()
+	require.Len(t, updates, 2)
+	assert.Equal(t, protocol.TaskStateWorking, updates[0].Status.State)
+	assert.False(t, updates[0].Final)
+	assert.True(t, updates[1].Final)
+
+	events := result.Events()
+	require.Len(t, events, 3)
+	artifactEvent, ok := events[1].(protocol.TaskArtifactUpdateEvent)
+	require.True(t, ok)
+	assert.Equal(t, "upper-task", artifactEvent.ID)
+	assert.True(t, artifactEvent.Final)
+}
+
+func TestRunProcessor_Error(t *testing.T) {
+	failing := processorFunc(func(ctx context.Context, taskID string, msg protocol.Message,
+		handle taskmanager.TaskHandle) error {
+		return errors.New("boom")
+	})
+	result, err := testutil.RunProcessor(context.Background(), failing, textMessage("abc"))
+	require.EqualError(t, err, "boom")
+	assert.Equal(t, testutil.DefaultTaskID, result.TaskID)
+	assert.Equal(t, protocol.TaskStateFailed, result.FinalState())
+	require.NotNil(t, result.FinalStatus().Message)
+	assert.Equal(t, "boom", result.FinalStatus().Message.Parts[0].(protocol.TextPart).Text)
+}
+
+func TestResult_Continue(t *testing.T) {
+	ctx := context.Background()
+	result, err := testutil.RunProcessor(ctx, greeterProcessor{}, textMessage("hello"))
+	require.NoError(t, err)
+	require.Equal(t, protocol.TaskStateInputRequired, result.FinalState())
+	assert.Empty(t, result.Artifacts())
+
+	require.NoError(t, result.Continue(ctx, textMessage("Ada")))
+	assert.Equal(t, protocol.TaskStateCompleted, result.FinalState())
+	artifacts := result.Artifacts()
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "Hello, Ada", artifacts[0].Parts[0].(protocol.TextPart).Text)
+
+	states := make([]protocol.TaskState, 0)
+	for _, update := range result.StatusUpdates() {
+		states = append(states, update.Status.State)
+	}
+	assert.Equal(t, []protocol.TaskState{
+		protocol.TaskStateWorking,
+		protocol.TaskStateInputRequired,
+		protocol.TaskStateWorking,
+		protocol.TaskStateCompleted,
+	}, states)
+
+	// The task is completed, so it can no longer be continued.
+	err = result.Continue(ctx, textMessage("again"))
+	assert.ErrorIs(t, err, testutil.ErrNotInputRequired)
+}
+
+// processorFunc adapts a function to the TaskProcessor interface.
+type processorFunc func(ctx context.Context, taskID string, msg protocol.Message,
+	handle taskmanager.TaskHandle) error
+
+func (f processorFunc) Process(ctx context.Context, taskID string, msg protocol.Message,
+	handle taskmanager.TaskHandle) error {
+	return f(ctx, taskID, msg, handle)
+}
+
+// ExampleRunProcessor demonstrates asserting on the artifacts emitted by a processor.
+func ExampleRunProcessor() {
+	result, err := testutil.RunProcessor(context.Background(), upperProcessor{}, textMessage("hello"))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	for _, artifact := range result.Artifacts() {
+		fmt.Println(*artifact.Name, artifact.Parts[0].(protocol.TextPart).Text)
+	}
+	fmt.Println(result.FinalState())
+	// Output:
+	// result HELLO
+	// completed
+}
+
+// ExampleResult_Continue demonstrates simulating an input-required continuation.
+func ExampleResult_Continue() {
+	ctx := context.Background()
+	result, _ := testutil.RunProcessor(ctx, greeterProcessor{}, textMessage("hello"))
+	fmt.Println(result.FinalState(), result.FinalStatus().Message.Parts[0].(protocol.TextPart).Text)
+
+	if err := result.Continue(ctx, textMessage("Ada")); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(result.FinalState(), result.Artifacts()[0].Parts[0].(protocol.TextPart).Text)
+	// Output:
+	// input-required What is your name?
+	// completed Hello, Ada
+}
+
+func TestRunProcessor_SeedMessages(t *testing.T) {
+	var seen []protocol.Message
+	recorder := processorFunc(func(ctx context.Context, taskID string, msg protocol.Message,
+		handle taskmanager.TaskHandle) error {
+		history, err := handle.GetMessageHistory()
+		if err != nil {
+			return err
+		}
+		seen = history
+		return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+	})
+	_, err := testutil.RunProcessor(context.Background(), recorder, textMessage("question"),
+		testutil.WithSeedMessages(textMessage("system"), textMessage("example")))
+	require.NoError(t, err)
+	require.Len(t, seen, 3)
+	assert.Equal(t, "system", seen[0].Parts[0].(protocol.TextPart).Text)
+	assert.Equal(t, "question", seen[2].Parts[0].(protocol.TextPart).Text)
+}
+
+func TestHandle_Complete(t *testing.T) {
+	handle := testutil.NewHandle("complete-task", false)
+	result := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("done")})
+	artifact := protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart("output")}}
+	require.NoError(t, handle.Complete(result, artifact))
+
+	status := handle.Status()
+	assert.Equal(t, protocol.TaskStateCompleted, status.State)
+	require.NotNil(t, status.Message)
+	assert.Equal(t, "done", status.Message.Parts[0].(protocol.TextPart).Text)
+	events := handle.Events()
+	require.Len(t, events, 2)
+	assert.IsType(t, protocol.TaskArtifactUpdateEvent{}, events[0])
+	assert.True(t, events[1].IsFinal())
+
+	assert.Error(t, handle.UpdateStatus(protocol.TaskStateWorking, nil))
+	assert.Error(t, handle.AddArtifact(artifact))
+	assert.Error(t, handle.SendMessage(result))
+	assert.Error(t, handle.Complete(result))
+	assert.Len(t, handle.Events(), 2, "rejected updates should not be recorded")
+}
+
+func TestHandle_Fail(t *testing.T) {
+	handle := testutil.NewHandle("fail-task", false)
+	require.NoError(t, handle.Fail(taskmanager.WithErrorCode("quota", errors.New("quota exceeded")), true))
+
+	status := handle.Status()
+	assert.Equal(t, protocol.TaskStateFailed, status.State)
+	assert.Equal(t, &protocol.TaskError{Code: "quota", Message: "quota exceeded", Retryable: true}, status.Error)
+	require.Len(t, handle.Events(), 1)
+	assert.True(t, handle.Events()[0].IsFinal())
+
+	assert.Error(t, handle.UpdateStatus(protocol.TaskStateCompleted, nil))
+	assert.Error(t, handle.Fail(errors.New("again"), false))
+	assert.Len(t, handle.Events(), 1, "rejected updates should not be recorded")
+}
+
+func TestHandle_PatchArtifact(t *testing.T) {
+	handle := testutil.NewHandle("patch-task", true)
+	require.NoError(t, handle.AddArtifact(protocol.Artifact{
+		Parts: []protocol.Part{protocol.DataPart{Type: protocol.PartTypeData, Data: map[string]interface{}{"n": 1}}},
+	}))
+	require.NoError(t, handle.PatchArtifact(0, 0, []protocol.PatchOperation{
+		{Op: protocol.PatchOpReplace, Path: "/n", Value: 2},
+	}))
+	assert.ErrorIs(t, handle.PatchArtifact(0, 0, []protocol.PatchOperation{
+		{Op: protocol.PatchOpRemove, Path: "/missing"},
+	}), protocol.ErrInvalidPatch)
+
+	artifacts := handle.Artifacts()
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, map[string]interface{}{"n": float64(2)}, artifacts[0].Parts[0].(protocol.DataPart).Data)
+	events := handle.Events()
+	require.Len(t, events, 2, "rejected patches should not be recorded")
+	assert.IsType(t, protocol.TaskArtifactPatchEvent{}, events[1])
+}
+
+func TestRunProcessor_WithInput(t *testing.T) {
+	joiner := processorFunc(func(ctx context.Context, taskID string, msg protocol.Message,
+		handle taskmanager.TaskHandle) error {
+		var texts []string
+		for input := range handle.(taskmanager.InputReader).Input() {
+			texts = append(texts, input.Parts[0].(protocol.TextPart).Text)
+		}
+		return handle.Complete(textMessage(strings.Join(texts, "+")))
+	})
+	result, err := testutil.RunProcessor(context.Background(), joiner, textMessage("start"),
+		testutil.WithInput(textMessage("a"), textMessage("b")))
+	require.NoError(t, err)
+	status := result.FinalStatus()
+	assert.Equal(t, protocol.TaskStateCompleted, status.State)
+	assert.Equal(t, "a+b", status.Message.Parts[0].(protocol.TextPart).Text)
+}
@@ -0,0 +1,63 @@
+// Tencent is pleased to support the open source community by making trpc-a2a-go available.
+//
+// Copyright (C) 2025 THL A29 Limited, a Tencent company.  All rights reserved.
+//
+// trpc-a2a-go is licensed under the Apache License Version 2.0.
+
+package taskmanager
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrProcessorTimeout is the cause of a processor's context cancellation when the
+// processor ran longer than the timeout set with ProcessorLimiter.
+var ErrProcessorTimeout = errors.New("task processor timed out")
+
+// ProcessorWatchdog fails a task whose processor runs too long. For streaming tasks
+// it is reset on every event, so it only fires once the processor goes the whole
+// timeout without emitting one. Task managers implementing ProcessorLimiter use it.
+// A nil ProcessorWatchdog never fires.
+type ProcessorWatchdog struct {
+	timer   *time.Timer
+	timeout time.Duration
+	fired   chan struct{}
+}
+
+// NewProcessorWatchdog starts a watchdog that calls onTimeout once timeout elapses.
+// It returns nil, a watchdog that never fires, if timeout is not positive.
+func NewProcessorWatchdog(timeout time.Duration, onTimeout func()) *ProcessorWatchdog {
+	if timeout <= 0 {
+		return nil
+	}
+	w := &ProcessorWatchdog{timeout: timeout, fired: make(chan struct{})}
+	w.timer = time.AfterFunc(timeout, func() {
+		onTimeout()
+		close(w.fired)
+	})
+	return w
+}
+
+// Reset restarts the timeout, unless the watchdog already fired or was stopped.
+func (w *ProcessorWatchdog) Reset() {
+	if w != nil && w.timer.Stop() {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// Stop stops the watchdog without firing it.
+func (w *ProcessorWatchdog) Stop() {
+	if w != nil {
+		w.timer.Stop()
+	}
+}
+
+// Fired returns a channel closed once the watchdog fired and onTimeout returned.
+// It is nil, and so never ready, for a nil watchdog.
+func (w *ProcessorWatchdog) Fired() <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+	return w.fired
+}
@@ -63,6 +63,17 @@ func TestJWTAuthentication(t *testing.T) {
 	})
 	assert.Error(t, err, "Unauthenticated request should fail")
 	assert.Contains(t, err.Error(), "401", "Expected 401 Unauthorized")
+	var authErr *client.UnauthorizedError
+	require.ErrorAs(t, err, &authErr)
+	assert.Equal(t, []auth.Challenge{{Scheme: "Bearer", Realm: "Auth Test Server"}}, authErr.Challenges)
+	assert.Contains(t, err.Error(), `agent accepts: Bearer realm="Auth Test Server"`)
+
+	_, err = basicClient.StreamTask(ctx, protocol.SendTaskParams{
+		ID:      "task1",
+		Message: createTextMessage("Hello, World!"),
+	})
+	require.ErrorAs(t, err, &authErr)
+	assert.Equal(t, "Bearer", authErr.Challenges[0].Scheme)
 
 	// Create a transport that adds the JWT token
 	transport := &authRoundTripper{
@@ -697,6 +708,100 @@ func (h *mockTaskHandle) AddArtifact(artifact protocol.Artifact) error {
 	return nil
 }
 
+// PatchArtifact implements the TaskHandle interface.
+func (h *mockTaskHandle) PatchArtifact(index, part int, patch []protocol.PatchOperation) error {
+	task, err := h.manager.Task(h.taskID)
+	if err != nil {
+		return err
+	}
+
+	artifacts, err := protocol.PatchArtifacts(task.Artifacts, index, part, patch)
+	if err != nil {
+		return err
+	}
+	task.Artifacts = artifacts
+	h.manager.tasks[h.taskID] = task
+	return nil
+}
+
+// ReplaceArtifact implements the TaskHandle interface.
+func (h *mockTaskHandle) ReplaceArtifact(artifact protocol.Artifact) error {
+	task, err := h.manager.Task(h.taskID)
+	if err != nil {
+		return err
+	}
+
+	task.Artifacts = protocol.ReplaceArtifacts(task.Artifacts, artifact)
+	h.manager.tasks[h.taskID] = task
+	return nil
+}
+
+// DeleteArtifact implements the TaskHandle interface.
+func (h *mockTaskHandle) DeleteArtifact(index int) error {
+	task, err := h.manager.Task(h.taskID)
+	if err != nil {
+		return err
+	}
+
+	artifacts, err := protocol.DeleteArtifacts(task.Artifacts, index)
+	if err != nil {
+		return err
+	}
+	task.Artifacts = artifacts
+	h.manager.tasks[h.taskID] = task
+	return nil
+}
+
+// SendMessage implements the TaskHandle interface.
+func (h *mockTaskHandle) SendMessage(message protocol.Message) error {
+	task, err := h.manager.Task(h.taskID)
+	if err != nil {
+		return err
+	}
+
+	task.History = append(task.History, message)
+	h.manager.tasks[h.taskID] = task
+	return nil
+}
+
+// Complete implements the TaskHandle interface.
+func (h *mockTaskHandle) Complete(message protocol.Message, artifacts ...protocol.Artifact) error {
+	task, err := h.manager.Task(h.taskID)
+	if err != nil {
+		return err
+	}
+
+	task.Artifacts = append(task.Artifacts, artifacts...)
+	task.Status.State = protocol.TaskStateCompleted
+	task.Status.Message = &message
+	h.manager.tasks[h.taskID] = task
+	return nil
+}
+
+// Fail implements the TaskHandle interface.
+func (h *mockTaskHandle) Fail(err error, retryable bool) error {
+	task, getErr := h.manager.Task(h.taskID)
+	if getErr != nil {
+		return getErr
+	}
+
+	task.Status = taskmanager.FailureStatus(err, retryable)
+	h.manager.tasks[h.taskID] = task
+	return nil
+}
+
+// SetEstimatedCompletion implements the TaskHandle interface.
+func (h *mockTaskHandle) SetEstimatedCompletion(eta time.Time) error {
+	task, err := h.manager.Task(h.taskID)
+	if err != nil {
+		return err
+	}
+
+	task.Status.EstimatedCompletion = eta.UTC().Format(time.RFC3339)
+	h.manager.tasks[h.taskID] = task
+	return nil
+}
+
 // IsStreamingRequest implements the TaskHandle interface.
 // It determines if this task was initiated via a streaming request.
 func (h *mockTaskHandle) IsStreamingRequest() bool {
@@ -712,6 +817,15 @@ func (h *mockTaskHandle) IsStreamingRequest() bool {
 	return false
 }
 
+// GetMessageHistory implements the TaskHandle interface.
+func (h *mockTaskHandle) GetMessageHistory() ([]protocol.Message, error) {
+	task, err := h.manager.Task(h.taskID)
+	if err != nil {
+		return nil, err
+	}
+	return task.History, nil
+}
+
 // AddResponse adds a response to a task.
 func (h *mockTaskHandle) AddResponse(response protocol.Message) error {
 	task, err := h.manager.Task(h.taskID)
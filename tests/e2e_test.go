@@ -7,18 +7,33 @@
 package tests
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"trpc.group/trpc-go/trpc-a2a-go/auth"
 	"trpc.group/trpc-go/trpc-a2a-go/client"
+	"trpc.group/trpc-go/trpc-a2a-go/internal/jsonrpc"
 	"trpc.group/trpc-go/trpc-a2a-go/protocol"
 	"trpc.group/trpc-go/trpc-a2a-go/server"
 	"trpc.group/trpc-go/trpc-a2a-go/taskmanager"
@@ -369,13 +384,6 @@ func (h *testHelper) sendTestMessage(taskID string, text string) (*protocol.Task
 	})
 }
 
-// isFinalState checks if the given task state is a terminal state.
-func isFinalState(state protocol.TaskState) bool {
-	return state == protocol.TaskStateCompleted ||
-		state == protocol.TaskStateFailed ||
-		state == protocol.TaskStateCanceled
-}
-
 // waitForTaskCompletion waits for a task to reach a final state.
 func waitForTaskCompletion(ctx context.Context, client *client.A2AClient, taskID string) (*protocol.Task, error) {
 	for {
@@ -388,7 +396,7 @@ func waitForTaskCompletion(ctx context.Context, client *client.A2AClient, taskID
 				return nil, err
 			}
 
-			if isFinalState(task.Status.State) {
+			if task.Status.State.IsFinal() {
 				return task, nil
 			}
 
@@ -505,3 +513,2294 @@ func TestE2E_BasicAgent_Streaming(t *testing.T) {
 }
 
 // ... existing code ...
+
+// countingProcessor completes every task and counts how often it runs.
+type countingProcessor struct {
+	calls atomic.Int32
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *countingProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	p.calls.Add(1)
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// TestE2E_IdempotentSendTasks tests that retries with the same idempotency key
+// create a single task.
+func TestE2E_IdempotentSendTasks(t *testing.T) {
+	processor := &countingProcessor{}
+	helper := newTestHelper(t, processor)
+	defer helper.cleanup()
+
+	ctx := context.Background()
+	params := protocol.SendTaskParams{
+		ID:      "idempotent-task-1",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("once")}),
+	}
+	first, err := helper.client.SendTasks(ctx, params, client.WithIdempotencyKey("order-42"))
+	require.NoError(t, err)
+
+	// A retry of the same request with the same key returns the original task.
+	second, err := helper.client.SendTasks(ctx, params, client.WithIdempotencyKey("order-42"))
+	require.NoError(t, err)
+	require.Equal(t, first.ID, second.ID)
+	require.Equal(t, protocol.TaskStateCompleted, second.Status.State)
+	require.Equal(t, int32(1), processor.calls.Load())
+
+	// Reusing the key for a different request is rejected and creates nothing.
+	params.ID = "idempotent-task-2"
+	_, err = helper.client.SendTasks(ctx, params, client.WithIdempotencyKey("order-42"))
+	require.Error(t, err)
+	_, err = helper.client.GetTasks(ctx, protocol.TaskQueryParams{ID: "idempotent-task-2"})
+	require.Error(t, err)
+
+	// A different key creates a new task.
+	third, err := helper.client.SendTasks(ctx, params, client.WithIdempotencyKey("order-43"))
+	require.NoError(t, err)
+	require.Equal(t, "idempotent-task-2", third.ID)
+	require.Equal(t, int32(2), processor.calls.Load())
+}
+
+// messagingProcessor sends an intermediate message before completing the task.
+type messagingProcessor struct{}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *messagingProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	if err := handle.UpdateStatus(protocol.TaskStateWorking, nil); err != nil {
+		return err
+	}
+	progress := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("Looking that up...")})
+	if err := handle.SendMessage(progress); err != nil {
+		return err
+	}
+	result := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("Done.")})
+	return handle.UpdateStatus(protocol.TaskStateCompleted, &result)
+}
+
+// TestE2E_IntermediateMessages tests that messages sent mid-task reach the client
+// as message events, separate from the final status.
+func TestE2E_IntermediateMessages(t *testing.T) {
+	helper := newTestHelper(t, &messagingProcessor{})
+	defer helper.cleanup()
+
+	eventChan, err := helper.client.StreamTask(context.Background(), protocol.SendTaskParams{
+		ID:      "messaging-task-1",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("look it up")}),
+	})
+	require.NoError(t, err)
+	events := collectAllTaskEvents(eventChan)
+
+	var messages []protocol.TaskMessageEvent
+	var final *protocol.TaskStatusUpdateEvent
+	for _, event := range events {
+		switch e := event.(type) {
+		case protocol.TaskMessageEvent:
+			require.Nil(t, final, "message event should arrive before the final status")
+			messages = append(messages, e)
+		case protocol.TaskStatusUpdateEvent:
+			if e.Final {
+				final = &e
+			}
+		}
+	}
+	require.Len(t, messages, 1)
+	assert.Equal(t, "messaging-task-1", messages[0].ID)
+	assert.Equal(t, protocol.MessageRoleAgent, messages[0].Message.Role)
+	assert.Equal(t, "Looking that up...", getTextPartContent(messages[0].Message.Parts))
+	assert.False(t, messages[0].IsFinal())
+
+	require.NotNil(t, final, "should receive the final status")
+	assert.Equal(t, protocol.TaskStateCompleted, final.Status.State)
+	require.NotNil(t, final.Status.Message)
+	assert.Equal(t, "Done.", getTextPartContent(final.Status.Message.Parts))
+
+	// The intermediate message is kept in the task history.
+	historyLength := 10
+	task, err := helper.client.GetTasks(context.Background(), protocol.TaskQueryParams{
+		ID:            "messaging-task-1",
+		HistoryLength: &historyLength,
+	})
+	require.NoError(t, err)
+	var history []string
+	for _, msg := range task.History {
+		history = append(history, getTextPartContent(msg.Parts))
+	}
+	assert.Contains(t, history, "Looking that up...")
+}
+
+// splitRuneProcessor streams text in two chunks split inside a multi-byte rune.
+type splitRuneProcessor struct {
+	text  string
+	split int
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *splitRuneProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	chunks := []string{p.text[:p.split], p.text[p.split:]}
+	for i, chunk := range chunks {
+		appendChunk, lastChunk := i > 0, i == len(chunks)-1
+		if err := handle.AddArtifact(protocol.Artifact{
+			Index:     0,
+			Parts:     []protocol.Part{protocol.NewTextPart(chunk)},
+			Append:    &appendChunk,
+			LastChunk: &lastChunk,
+		}); err != nil {
+			return err
+		}
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// TestE2E_StreamedTextSplitRune tests that a rune split across streamed chunks
+// survives the SSE and JSON path intact.
+func TestE2E_StreamedTextSplitRune(t *testing.T) {
+	full := "hi 😀!"
+	helper := newTestHelper(t, &splitRuneProcessor{text: full, split: 5}) // Inside the emoji.
+	defer helper.cleanup()
+
+	eventChan, err := helper.client.StreamTask(context.Background(), protocol.SendTaskParams{
+		ID:      "split-rune-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("stream")}),
+	})
+	require.NoError(t, err)
+
+	aggregator := client.NewTextAggregator()
+	var chunks int
+	for _, event := range collectAllTaskEvents(eventChan) {
+		artifactEvent, ok := event.(protocol.TaskArtifactUpdateEvent)
+		if !ok {
+			continue
+		}
+		chunks++
+		for _, part := range artifactEvent.Artifact.Parts {
+			text := part.(protocol.TextPart).Text
+			assert.True(t, utf8.ValidString(text), "chunk %q should be valid UTF-8", text)
+			assert.NotContains(t, text, string(utf8.RuneError))
+		}
+		aggregator.Add(artifactEvent)
+	}
+	assert.Equal(t, 2, chunks)
+	assert.Equal(t, full, aggregator.Text(0))
+}
+
+// patchProcessor streams a DataPart artifact and then updates it with JSON patches,
+// including a part of a chunk appended to it.
+type patchProcessor struct{}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *patchProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	if err := handle.AddArtifact(protocol.Artifact{
+		Index: 0,
+		Parts: []protocol.Part{protocol.DataPart{
+			Type: protocol.PartTypeData,
+			Data: map[string]interface{}{"status": "running", "steps": []string{"plan"}, "eta": 30},
+		}},
+	}); err != nil {
+		return err
+	}
+	patches := [][]protocol.PatchOperation{
+		{{Op: protocol.PatchOpAdd, Path: "/steps/-", Value: "build"}},
+		{{Op: protocol.PatchOpReplace, Path: "/status", Value: "done"}, {Op: protocol.PatchOpRemove, Path: "/eta"}},
+	}
+	for _, patch := range patches {
+		if err := handle.PatchArtifact(0, 0, patch); err != nil {
+			return err
+		}
+	}
+	appendChunk := true
+	if err := handle.AddArtifact(protocol.Artifact{
+		Index:  0,
+		Append: &appendChunk,
+		Parts:  []protocol.Part{protocol.DataPart{Type: protocol.PartTypeData, Data: map[string]interface{}{"count": 1}}},
+	}); err != nil {
+		return err
+	}
+	if err := handle.PatchArtifact(0, 1, []protocol.PatchOperation{
+		{Op: protocol.PatchOpReplace, Path: "/count", Value: 2},
+	}); err != nil {
+		return err
+	}
+	// An invalid patch is rejected and never reaches the client.
+	invalid := []protocol.PatchOperation{{Op: protocol.PatchOpRemove, Path: "/missing"}}
+	if err := handle.PatchArtifact(0, 0, invalid); !errors.Is(err, protocol.ErrInvalidPatch) {
+		return fmt.Errorf("invalid patch accepted: %v", err)
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// TestE2E_ArtifactPatches tests that patch events stream over SSE and rebuild the
+// same artifact the server stores.
+func TestE2E_ArtifactPatches(t *testing.T) {
+	helper := newTestHelper(t, &patchProcessor{})
+	defer helper.cleanup()
+
+	eventChan, err := helper.client.StreamTask(context.Background(), protocol.SendTaskParams{
+		ID:      "patch-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("build")}),
+	})
+	require.NoError(t, err)
+
+	aggregator := client.NewArtifactAggregator()
+	var patchEvents int
+	var final *protocol.TaskStatusUpdateEvent
+	for _, event := range collectAllTaskEvents(eventChan) {
+		switch e := event.(type) {
+		case protocol.TaskArtifactPatchEvent:
+			patchEvents++
+		case protocol.TaskStatusUpdateEvent:
+			if e.Final {
+				final = &e
+			}
+		}
+		require.NoError(t, aggregator.Add(event))
+	}
+	require.NotNil(t, final)
+	assert.Equal(t, protocol.TaskStateCompleted, final.Status.State)
+	assert.Equal(t, 3, patchEvents)
+
+	want := map[string]interface{}{"status": "done", "steps": []interface{}{"plan", "build"}}
+	wantAppended := map[string]interface{}{"count": float64(2)}
+	artifact, ok := aggregator.Artifact(0)
+	require.True(t, ok)
+	require.Len(t, artifact.Parts, 2)
+	assert.Equal(t, want, artifact.Parts[0].(protocol.DataPart).Data)
+	assert.Equal(t, wantAppended, artifact.Parts[1].(protocol.DataPart).Data)
+
+	task, err := helper.client.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "patch-task"})
+	require.NoError(t, err)
+	require.Len(t, task.Artifacts, 2, "one entry per chunk")
+	assert.Equal(t, want, task.Artifacts[0].Parts[0].(protocol.DataPart).Data)
+	assert.Equal(t, wantAppended, task.Artifacts[1].Parts[0].(protocol.DataPart).Data)
+}
+
+// draftProcessor streams a draft artifact, replaces it with the final version, and
+// deletes a scratch artifact before completing.
+type draftProcessor struct{}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *draftProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	appendChunk := true
+	steps := []protocol.Artifact{
+		{Index: 0, Parts: []protocol.Part{protocol.NewTextPart("draft ")}},
+		{Index: 0, Parts: []protocol.Part{protocol.NewTextPart("answer")}, Append: &appendChunk},
+		{Index: 1, Parts: []protocol.Part{protocol.NewTextPart("scratch")}},
+	}
+	for _, artifact := range steps {
+		if err := handle.AddArtifact(artifact); err != nil {
+			return err
+		}
+	}
+	if err := handle.ReplaceArtifact(protocol.Artifact{
+		Index: 0,
+		Parts: []protocol.Part{protocol.NewTextPart("final answer")},
+	}); err != nil {
+		return err
+	}
+	if err := handle.DeleteArtifact(1); err != nil {
+		return err
+	}
+	if err := handle.DeleteArtifact(1); !errors.Is(err, protocol.ErrArtifactNotFound) {
+		return fmt.Errorf("deleting a missing artifact: %v", err)
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// TestE2E_ArtifactReplaceDelete tests that replaced and deleted artifacts are
+// reflected both in the streamed events and in the stored task.
+func TestE2E_ArtifactReplaceDelete(t *testing.T) {
+	helper := newTestHelper(t, &draftProcessor{})
+	defer helper.cleanup()
+
+	eventChan, err := helper.client.StreamTask(context.Background(), protocol.SendTaskParams{
+		ID:      "draft-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("write")}),
+	})
+	require.NoError(t, err)
+
+	aggregator := client.NewArtifactAggregator()
+	var deleteEvents int
+	var final *protocol.TaskStatusUpdateEvent
+	for _, event := range collectAllTaskEvents(eventChan) {
+		switch e := event.(type) {
+		case protocol.TaskArtifactDeleteEvent:
+			deleteEvents++
+			assert.Equal(t, 1, e.Index)
+		case protocol.TaskStatusUpdateEvent:
+			if e.Final {
+				final = &e
+			}
+		}
+		require.NoError(t, aggregator.Add(event))
+	}
+	require.NotNil(t, final)
+	assert.Equal(t, protocol.TaskStateCompleted, final.Status.State)
+	assert.Equal(t, 1, deleteEvents)
+
+	artifact, ok := aggregator.Artifact(0)
+	require.True(t, ok)
+	require.Len(t, artifact.Parts, 1)
+	assert.Equal(t, "final answer", artifact.Parts[0].(protocol.TextPart).Text)
+	_, ok = aggregator.Artifact(1)
+	assert.False(t, ok)
+
+	task, err := helper.client.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "draft-task"})
+	require.NoError(t, err)
+	require.Len(t, task.Artifacts, 1)
+	assert.Equal(t, 0, task.Artifacts[0].Index)
+	assert.Equal(t, "final answer", task.Artifacts[0].Parts[0].(protocol.TextPart).Text)
+}
+
+// TestE2E_AgentCardExtensions tests that card extensions, known or not, survive
+// the trip from server to client.
+func TestE2E_AgentCardExtensions(t *testing.T) {
+	agentCard := createDefaultTestAgentCard()
+	require.NoError(t, agentCard.SetExtension("https://example.com/ext/known", map[string]int{"limit": 3}))
+	// An extension this client knows nothing about must survive the round trip.
+	agentCard.Extensions["urn:vendor:unknown"] = json.RawMessage(`{"nested":[1,{"deep":true}]}`)
+
+	tm, err := taskmanager.NewMemoryTaskManager(&countingProcessor{})
+	require.NoError(t, err)
+	a2aServer, err := server.NewA2AServer(agentCard, tm)
+	require.NoError(t, err)
+	ts := httptest.NewServer(a2aServer.Handler())
+	defer ts.Close()
+
+	a2aClient, err := client.NewA2AClient(ts.URL)
+	require.NoError(t, err)
+	var card server.AgentCard
+	require.NoError(t, a2aClient.GetAgentCard(context.Background(), &card))
+
+	var known map[string]int
+	found, err := card.Extension("https://example.com/ext/known", &known)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, map[string]int{"limit": 3}, known)
+
+	reencoded, err := json.Marshal(card)
+	require.NoError(t, err)
+	assert.Contains(t, string(reencoded), `"urn:vendor:unknown":{"nested":[1,{"deep":true}]}`)
+}
+
+// TestE2E_SignedAgentCard tests fetching and verifying a JWS-signed agent card.
+func TestE2E_SignedAgentCard(t *testing.T) {
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	agentCard := createDefaultTestAgentCard()
+	agentCard.Version = "1.2.3"
+
+	tm, err := taskmanager.NewMemoryTaskManager(&countingProcessor{})
+	require.NoError(t, err)
+	signedServer, err := server.NewA2AServer(agentCard, tm, server.WithAgentCardSigner(signingKey))
+	require.NoError(t, err)
+	ts := httptest.NewServer(signedServer.Handler())
+	defer ts.Close()
+	ctx := context.Background()
+
+	t.Run("Valid", func(t *testing.T) {
+		a2aClient, err := client.NewA2AClient(ts.URL, client.WithAgentCardVerificationKey(&signingKey.PublicKey))
+		require.NoError(t, err)
+		var card server.AgentCard
+		require.NoError(t, a2aClient.GetAgentCard(ctx, &card))
+		require.Equal(t, agentCard, card)
+	})
+
+	t.Run("PlainClient", func(t *testing.T) {
+		a2aClient, err := client.NewA2AClient(ts.URL)
+		require.NoError(t, err)
+		var card server.AgentCard
+		require.NoError(t, a2aClient.GetAgentCard(ctx, &card))
+		require.Equal(t, agentCard, card)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		// A man in the middle swaps the payload but keeps the original signature.
+		tampered := agentCard
+		tampered.URL = "https://evil.example.com/"
+		payload, err := json.Marshal(tampered)
+		require.NoError(t, err)
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := httptest.NewRecorder()
+			signedServer.Handler().ServeHTTP(rec, r)
+			parts := strings.Split(rec.Body.String(), ".")
+			require.Len(t, parts, 3)
+			parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+			w.Header().Set("Content-Type", rec.Header().Get("Content-Type"))
+			w.Write([]byte(strings.Join(parts, ".")))
+		}))
+		defer proxy.Close()
+
+		a2aClient, err := client.NewA2AClient(proxy.URL, client.WithAgentCardVerificationKey(&signingKey.PublicKey))
+		require.NoError(t, err)
+		var card server.AgentCard
+		require.ErrorIs(t, a2aClient.GetAgentCard(ctx, &card), protocol.ErrAgentCardSignature)
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		unsignedServer, err := server.NewA2AServer(agentCard, tm)
+		require.NoError(t, err)
+		unsigned := httptest.NewServer(unsignedServer.Handler())
+		defer unsigned.Close()
+
+		a2aClient, err := client.NewA2AClient(unsigned.URL, client.WithAgentCardVerificationKey(&signingKey.PublicKey))
+		require.NoError(t, err)
+		var card server.AgentCard
+		require.ErrorIs(t, a2aClient.GetAgentCard(ctx, &card), protocol.ErrAgentCardUnsigned)
+	})
+}
+
+func TestE2E_SignedMessages(t *testing.T) {
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tm, err := taskmanager.NewMemoryTaskManager(&countingProcessor{})
+	require.NoError(t, err)
+	a2aServer, err := server.NewA2AServer(createDefaultTestAgentCard(), tm,
+		server.WithMessageVerificationKey(&signingKey.PublicKey))
+	require.NoError(t, err)
+	ts := httptest.NewServer(a2aServer.Handler())
+	defer ts.Close()
+	ctx := context.Background()
+	params := func(id string) protocol.SendTaskParams {
+		return protocol.SendTaskParams{
+			ID:      id,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("signed")}),
+		}
+	}
+	requireInvalidParams := func(t *testing.T, err error) {
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+	}
+
+	t.Run("Signed", func(t *testing.T) {
+		a2aClient, err := client.NewA2AClient(ts.URL, client.WithMessageSigningKey(signingKey))
+		require.NoError(t, err)
+		task, err := a2aClient.SendTasks(ctx, params("signed-send"))
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+
+		eventChan, err := a2aClient.StreamTask(ctx, params("signed-stream"))
+		require.NoError(t, err)
+		events := collectAllTaskEvents(eventChan)
+		require.NotEmpty(t, events)
+		assert.True(t, events[len(events)-1].IsFinal())
+
+		history, err := a2aClient.GetTasks(ctx, protocol.TaskQueryParams{ID: "signed-send", HistoryLength: intPtr(1)})
+		require.NoError(t, err)
+		require.NotEmpty(t, history.History)
+		assert.NoError(t, protocol.VerifyMessage(history.History[0], &signingKey.PublicKey),
+			"the stored message keeps its signature")
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		a2aClient, err := client.NewA2AClient(ts.URL)
+		require.NoError(t, err)
+		_, err = a2aClient.SendTasks(ctx, params("unsigned-send"))
+		requireInvalidParams(t, err)
+		_, err = a2aClient.StreamTask(ctx, params("unsigned-stream"))
+		requireInvalidParams(t, err)
+	})
+
+	t.Run("SeedMessages", func(t *testing.T) {
+		seeded := func(id string) protocol.SendTaskParams {
+			p := params(id)
+			p.Messages = []protocol.Message{
+				protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("seed")}),
+			}
+			return p
+		}
+		a2aClient, err := client.NewA2AClient(ts.URL, client.WithMessageSigningKey(signingKey))
+		require.NoError(t, err)
+		_, err = a2aClient.SendTasks(ctx, seeded("signed-seeded-send"))
+		require.NoError(t, err)
+
+		// A seed message the signing client did not sign is rejected.
+		forged := seeded("forged-seeded-send")
+		require.NoError(t, protocol.SignMessage(&forged.Message, signingKey))
+		unsigned, err := client.NewA2AClient(ts.URL)
+		require.NoError(t, err)
+		_, err = unsigned.SendTasks(ctx, forged)
+		requireInvalidParams(t, err)
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		a2aClient, err := client.NewA2AClient(ts.URL, client.WithMessageSigningKey(otherKey))
+		require.NoError(t, err)
+		_, err = a2aClient.SendTasks(ctx, params("forged-send"))
+		requireInvalidParams(t, err)
+		_, err = a2aClient.StreamTask(ctx, params("forged-stream"))
+		requireInvalidParams(t, err)
+	})
+}
+
+// artifactEchoProcessor adds the text of each message as an artifact and completes the task.
+type artifactEchoProcessor struct{}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *artifactEchoProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	if err := handle.AddArtifact(protocol.Artifact{Parts: msg.Parts}); err != nil {
+		return err
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// TestE2E_GetTaskIfChanged tests that polling an unchanged task yields
+// ErrTaskNotModified and that a change returns the updated task.
+func TestE2E_GetTaskIfChanged(t *testing.T) {
+	helper := newTestHelper(t, &artifactEchoProcessor{})
+	defer helper.cleanup()
+	ctx := context.Background()
+	send := func(text string) {
+		_, err := helper.client.SendTasks(ctx, protocol.SendTaskParams{
+			ID:      "conditional-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)}),
+		})
+		require.NoError(t, err)
+	}
+	send("first")
+
+	task, etag, err := helper.client.GetTaskIfChanged(ctx, "conditional-task", "")
+	require.NoError(t, err)
+	require.NotEmpty(t, etag)
+	require.Len(t, task.Artifacts, 1)
+
+	t.Run("Unchanged", func(t *testing.T) {
+		task, got, err := helper.client.GetTaskIfChanged(ctx, "conditional-task", etag)
+		assert.ErrorIs(t, err, client.ErrTaskNotModified)
+		assert.Nil(t, task)
+		assert.Equal(t, etag, got)
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		send("second")
+		task, got, err := helper.client.GetTaskIfChanged(ctx, "conditional-task", etag)
+		require.NoError(t, err)
+		assert.NotEqual(t, etag, got)
+		require.Len(t, task.Artifacts, 2)
+		assert.Equal(t, "second", task.Artifacts[1].Parts[0].(protocol.TextPart).Text)
+
+		_, _, err = helper.client.GetTaskIfChanged(ctx, "conditional-task", got)
+		assert.ErrorIs(t, err, client.ErrTaskNotModified)
+	})
+
+	t.Run("UnknownTask", func(t *testing.T) {
+		_, _, err := helper.client.GetTaskIfChanged(ctx, "missing-task", etag)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, client.ErrTaskNotModified)
+	})
+}
+
+// gatedProcessor marks the task working and completes it once release is closed.
+type gatedProcessor struct {
+	release chan struct{}
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *gatedProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	if err := handle.UpdateStatus(protocol.TaskStateWorking, nil); err != nil {
+		return err
+	}
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// taggingProcessor waits for release, then adds an artifact holding the task ID
+// and completes the task.
+type taggingProcessor struct {
+	release chan struct{}
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *taggingProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	if err := handle.UpdateStatus(protocol.TaskStateWorking, nil); err != nil {
+		return err
+	}
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := handle.AddArtifact(protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart(taskID)}}); err != nil {
+		return err
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// collectMultiplexedEvents reads a multiplexed stream until the client closes it,
+// which only happens after the final status of every task.
+func collectMultiplexedEvents(t *testing.T, eventChan <-chan protocol.TaskEvent) []protocol.TaskEvent {
+	var events []protocol.TaskEvent
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case event, ok := <-eventChan:
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatal("multiplexed stream did not close")
+		}
+	}
+}
+
+// TestE2E_StreamTasks tests that one multiplexed subscription delivers the events
+// of several tasks, each tagged with its own task ID.
+func TestE2E_StreamTasks(t *testing.T) {
+	processor := &taggingProcessor{release: make(chan struct{})}
+	helper := newTestHelper(t, processor)
+	defer helper.cleanup()
+	ctx := context.Background()
+
+	ids := []string{"mux-a", "mux-b"}
+	for _, id := range ids {
+		starter, err := helper.client.StreamTask(ctx, protocol.SendTaskParams{
+			ID:      id,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+		})
+		require.NoError(t, err)
+		go collectAllTaskEvents(starter)
+	}
+
+	t.Run("Multiplexed", func(t *testing.T) {
+		// The duplicate ID is subscribed to once.
+		eventChan, err := helper.client.StreamTasks(ctx, append(ids, "mux-a"))
+		require.NoError(t, err)
+		close(processor.release)
+
+		artifacts := make(map[string]string)
+		finalStates := make(map[string]protocol.TaskState)
+		for _, event := range collectMultiplexedEvents(t, eventChan) {
+			require.Contains(t, ids, event.TaskID())
+			switch e := event.(type) {
+			case protocol.TaskArtifactUpdateEvent:
+				artifacts[e.ID] = e.Artifact.Parts[0].(protocol.TextPart).Text
+			case protocol.TaskStatusUpdateEvent:
+				if e.Final {
+					_, seen := finalStates[e.ID]
+					require.False(t, seen, "one final status per task")
+					finalStates[e.ID] = e.Status.State
+				}
+			}
+		}
+		assert.Equal(t, map[string]string{"mux-a": "mux-a", "mux-b": "mux-b"}, artifacts)
+		assert.Equal(t, map[string]protocol.TaskState{
+			"mux-a": protocol.TaskStateCompleted,
+			"mux-b": protocol.TaskStateCompleted,
+		}, finalStates)
+	})
+
+	t.Run("Finished", func(t *testing.T) {
+		// Tasks already done still report their final status.
+		eventChan, err := helper.client.StreamTasks(ctx, ids)
+		require.NoError(t, err)
+		var finals []string
+		for _, event := range collectMultiplexedEvents(t, eventChan) {
+			if event.IsFinal() {
+				finals = append(finals, event.TaskID())
+			}
+		}
+		assert.ElementsMatch(t, ids, finals)
+	})
+
+	t.Run("UnknownTask", func(t *testing.T) {
+		_, err := helper.client.StreamTasks(ctx, []string{"mux-a", "missing"})
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, taskmanager.ErrCodeTaskNotFound, rpcErr.Code)
+	})
+
+	t.Run("NoTasks", func(t *testing.T) {
+		_, err := helper.client.StreamTasks(ctx, nil)
+		assert.Error(t, err)
+	})
+}
+
+// TestE2E_WaitForTaskChange tests that a long-poll tasks/get is woken by a status
+// change, and returns the unchanged task once its timeout elapses.
+func TestE2E_WaitForTaskChange(t *testing.T) {
+	processor := &gatedProcessor{release: make(chan struct{})}
+	helper := newTestHelper(t, processor)
+	defer helper.cleanup()
+	ctx := context.Background()
+
+	sendDone := make(chan error, 1)
+	go func() {
+		_, err := helper.client.SendTasks(ctx, protocol.SendTaskParams{
+			ID:      "long-poll-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+		})
+		sendDone <- err
+	}()
+	require.Eventually(t, func() bool {
+		task, err := helper.client.GetTasks(ctx, protocol.TaskQueryParams{ID: "long-poll-task"})
+		return err == nil && task.Status.State == protocol.TaskStateWorking
+	}, 2*time.Second, 10*time.Millisecond)
+
+	t.Run("Timeout", func(t *testing.T) {
+		start := time.Now()
+		task, err := helper.client.WaitForTaskChange(ctx, "long-poll-task", 200*time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateWorking, task.Status.State)
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("StatusChangeWakes", func(t *testing.T) {
+		time.AfterFunc(100*time.Millisecond, func() { close(processor.release) })
+		start := time.Now()
+		task, err := helper.client.WaitForTaskChange(ctx, "long-poll-task", 5*time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+		assert.Less(t, time.Since(start), 2*time.Second, "the status change should end the wait")
+		require.NoError(t, <-sendDone)
+	})
+
+	t.Run("FinalTaskReturnsAtOnce", func(t *testing.T) {
+		start := time.Now()
+		task, err := helper.client.WaitForTaskChange(ctx, "long-poll-task", 5*time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("UnknownTask", func(t *testing.T) {
+		_, err := helper.client.WaitForTaskChange(ctx, "missing-task", time.Second)
+		assert.Error(t, err)
+	})
+}
+
+// etaProcessor reports an estimated completion time, then revises it each time
+// advance is received before finishing.
+type etaProcessor struct {
+	etas    []time.Time
+	advance chan struct{}
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *etaProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	if err := handle.UpdateStatus(protocol.TaskStateWorking, nil); err != nil {
+		return err
+	}
+	for i, eta := range p.etas {
+		if err := handle.SetEstimatedCompletion(eta); err != nil {
+			return err
+		}
+		if i == 0 {
+			// A progress update keeps the estimate.
+			progress := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("halfway")})
+			if err := handle.UpdateStatus(protocol.TaskStateWorking, &progress); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-p.advance:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return handle.Complete(protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("done")}))
+}
+
+// TestE2E_EstimatedCompletion tests that the estimated completion time reaches
+// clients through streaming and tasks/get, and follows revisions.
+func TestE2E_EstimatedCompletion(t *testing.T) {
+	first := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	second := first.Add(30 * time.Second)
+	ctx := context.Background()
+
+	t.Run("Streaming", func(t *testing.T) {
+		advance := make(chan struct{})
+		close(advance)
+		helper := newTestHelper(t, &etaProcessor{etas: []time.Time{first, second}, advance: advance})
+		defer helper.cleanup()
+		eventChan, err := helper.client.StreamTask(ctx, protocol.SendTaskParams{
+			ID:      "eta-stream-task",
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+		})
+		require.NoError(t, err)
+
+		var etas []time.Time
+		var final *protocol.TaskStatusUpdateEvent
+		for _, event := range collectAllTaskEvents(eventChan) {
+			e, ok := event.(protocol.TaskStatusUpdateEvent)
+			if !ok {
+				continue
+			}
+			if e.Final {
+				final = &e
+				continue
+			}
+			if eta, ok := e.Status.EstimatedCompletionTime(); ok {
+				etas = append(etas, eta)
+			}
+		}
+		assert.Equal(t, []time.Time{first, first, second}, etas)
+		require.NotNil(t, final)
+		assert.Empty(t, final.Status.EstimatedCompletion, "a finished task has no estimate")
+	})
+
+	t.Run("GetTasks", func(t *testing.T) {
+		advance := make(chan struct{})
+		helper := newTestHelper(t, &etaProcessor{etas: []time.Time{first, second}, advance: advance})
+		defer helper.cleanup()
+		sendDone := make(chan error, 1)
+		go func() {
+			_, err := helper.client.SendTasks(ctx, protocol.SendTaskParams{
+				ID:      "eta-get-task",
+				Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+			})
+			sendDone <- err
+		}()
+		etaOf := func() time.Time {
+			task, err := helper.client.GetTasks(ctx, protocol.TaskQueryParams{ID: "eta-get-task"})
+			if err != nil {
+				return time.Time{}
+			}
+			eta, _ := task.Status.EstimatedCompletionTime()
+			return eta
+		}
+		require.Eventually(t, func() bool { return etaOf().Equal(first) }, 2*time.Second, 10*time.Millisecond)
+		advance <- struct{}{}
+		require.Eventually(t, func() bool { return etaOf().Equal(second) }, 2*time.Second, 10*time.Millisecond)
+		advance <- struct{}{}
+		require.NoError(t, <-sendDone)
+		task, err := helper.client.GetTasks(ctx, protocol.TaskQueryParams{ID: "eta-get-task"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+		assert.Empty(t, task.Status.EstimatedCompletion)
+	})
+}
+
+// TestE2E_ValidationErrors tests that invalid send params reach the client as a
+// protocol.ValidationError listing every invalid field.
+func TestE2E_ValidationErrors(t *testing.T) {
+	helper := newTestHelper(t, &gatedProcessor{release: make(chan struct{})})
+	defer helper.cleanup()
+	params := protocol.SendTaskParams{
+		Message: protocol.Message{Role: "robot", Parts: []protocol.Part{
+			protocol.FilePart{Type: protocol.PartTypeFile},
+		}},
+	}
+	wantPaths := []string{"/id", "/message/role", "/message/parts/0/file"}
+	fieldPaths := func(t *testing.T, err error) []string {
+		var validationErr *protocol.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		var paths []string
+		for _, field := range validationErr.Fields {
+			paths = append(paths, field.Path)
+		}
+		return paths
+	}
+
+	t.Run("SendTasks", func(t *testing.T) {
+		_, err := helper.client.SendTasks(context.Background(), params)
+		assert.Equal(t, wantPaths, fieldPaths(t, err))
+	})
+
+	t.Run("StreamTask", func(t *testing.T) {
+		_, err := helper.client.StreamTask(context.Background(), params)
+		assert.Equal(t, wantPaths, fieldPaths(t, err))
+	})
+}
+
+// methodRecorder wraps an agent handler and records the JSON-RPC methods called.
+type methodRecorder struct {
+	handler http.Handler
+
+	mu      sync.Mutex
+	methods []string
+}
+
+func (r *methodRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPost {
+		body, _ := io.ReadAll(req.Body)
+		var request struct {
+			Method string `json:"method"`
+		}
+		if json.Unmarshal(body, &request) == nil {
+			r.mu.Lock()
+			r.methods = append(r.methods, request.Method)
+			r.mu.Unlock()
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	r.handler.ServeHTTP(w, req)
+}
+
+func (r *methodRecorder) called(method string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// TestE2E_Run tests that Run picks streaming or sync from the agent card and
+// returns the same final task either way.
+func TestE2E_Run(t *testing.T) {
+	newAgent := func(t *testing.T, streaming bool) (*client.A2AClient, *methodRecorder) {
+		tm, err := taskmanager.NewMemoryTaskManager(&etaProcessor{
+			etas:    []time.Time{time.Now().Add(time.Minute)},
+			advance: closedChan(),
+		})
+		require.NoError(t, err)
+		agentCard := createDefaultTestAgentCard()
+		agentCard.Capabilities.Streaming = streaming
+		a2aServer, err := server.NewA2AServer(agentCard, tm)
+		require.NoError(t, err)
+		recorder := &methodRecorder{handler: a2aServer.Handler()}
+		httpServer := httptest.NewServer(recorder)
+		t.Cleanup(httpServer.Close)
+		a2aClient, err := client.NewA2AClient(httpServer.URL)
+		require.NoError(t, err)
+		return a2aClient, recorder
+	}
+	params := protocol.SendTaskParams{
+		ID:      "run-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+	}
+	// normalize drops the fields that differ between any two runs.
+	normalize := func(task *protocol.Task) *protocol.Task {
+		task.Status.Timestamp = ""
+		return task
+	}
+	ctx := context.Background()
+
+	streamingClient, streamingAgent := newAgent(t, true)
+	streamed, err := streamingClient.Run(ctx, params)
+	require.NoError(t, err)
+	assert.True(t, streamingAgent.called(protocol.MethodTasksSendSubscribe))
+	assert.False(t, streamingAgent.called(protocol.MethodTasksSend))
+
+	syncClient, syncAgent := newAgent(t, false)
+	synced, err := syncClient.Run(ctx, params)
+	require.NoError(t, err)
+	assert.True(t, syncAgent.called(protocol.MethodTasksSend))
+	assert.False(t, syncAgent.called(protocol.MethodTasksSendSubscribe))
+
+	assert.Equal(t, protocol.TaskStateCompleted, streamed.Status.State)
+	assert.Equal(t, normalize(streamed), normalize(synced))
+
+	t.Run("ForcedMode", func(t *testing.T) {
+		forcedClient, forcedAgent := newAgent(t, true)
+		task, err := forcedClient.Run(ctx, params, client.WithRunMode(client.RunModeSync))
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+		assert.False(t, forcedAgent.called(protocol.MethodTasksSendSubscribe))
+	})
+
+	t.Run("InvalidParams", func(t *testing.T) {
+		_, err := streamingClient.Run(ctx, protocol.SendTaskParams{ID: "run-invalid"})
+		var validationErr *protocol.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
+
+// unreliableProcessor fails the first failures attempts at a task with a coded
+// error marked retryable as given, then completes it.
+type unreliableProcessor struct {
+	failures  int32
+	retryable bool
+	attempts  atomic.Int32
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *unreliableProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	if p.attempts.Add(1) <= p.failures {
+		return handle.Fail(taskmanager.WithErrorCode("upstream_unavailable", errors.New("upstream is down")), p.retryable)
+	}
+	return handle.Complete(protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("done")}))
+}
+
+// TestE2E_StructuredFailures tests that the code and retryable flag of a failure
+// reach the client, and that Run only retries retryable failures.
+func TestE2E_StructuredFailures(t *testing.T) {
+	newAgent := func(t *testing.T, processor *unreliableProcessor) *client.A2AClient {
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		a2aServer, err := server.NewA2AServer(createDefaultTestAgentCard(), tm)
+		require.NoError(t, err)
+		httpServer := httptest.NewServer(a2aServer.Handler())
+		t.Cleanup(httpServer.Close)
+		a2aClient, err := client.NewA2AClient(httpServer.URL)
+		require.NoError(t, err)
+		return a2aClient
+	}
+	params := protocol.SendTaskParams{
+		ID:      "unreliable-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("go")}),
+	}
+	ctx := context.Background()
+
+	t.Run("ErrorReachesClient", func(t *testing.T) {
+		a2aClient := newAgent(t, &unreliableProcessor{failures: 1, retryable: true})
+		task, err := a2aClient.SendTasks(ctx, params)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateFailed, task.Status.State)
+		require.NotNil(t, task.Status.Error)
+		assert.Equal(t, "upstream_unavailable", task.Status.Error.Code)
+		assert.Equal(t, "upstream is down", task.Status.Error.Message)
+		assert.True(t, task.Status.Error.Retryable)
+		assert.True(t, client.IsRetryableFailure(task))
+	})
+
+	t.Run("RunRetriesRetryableFailures", func(t *testing.T) {
+		processor := &unreliableProcessor{failures: 2, retryable: true}
+		a2aClient := newAgent(t, processor)
+		task, err := a2aClient.Run(ctx, params, client.WithRunRetry(3, time.Millisecond))
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+		assert.Nil(t, task.Status.Error)
+		assert.Equal(t, int32(3), processor.attempts.Load())
+	})
+
+	t.Run("RunStopsAtPermanentFailure", func(t *testing.T) {
+		processor := &unreliableProcessor{failures: 2, retryable: false}
+		a2aClient := newAgent(t, processor)
+		task, err := a2aClient.Run(ctx, params, client.WithRunRetry(3, time.Millisecond))
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateFailed, task.Status.State)
+		assert.False(t, client.IsRetryableFailure(task))
+		assert.Equal(t, int32(1), processor.attempts.Load())
+	})
+
+	t.Run("RunGivesUpAfterMaxAttempts", func(t *testing.T) {
+		processor := &unreliableProcessor{failures: 5, retryable: true}
+		a2aClient := newAgent(t, processor)
+		task, err := a2aClient.Run(ctx, params, client.WithRunRetry(2, time.Millisecond))
+		require.NoError(t, err)
+		assert.True(t, client.IsRetryableFailure(task))
+		assert.Equal(t, int32(2), processor.attempts.Load())
+	})
+}
+
+// TestE2E_CustomMethods tests methods registered with RegisterMethod, called with
+// client.Call through the server's authentication and params checks.
+func TestE2E_CustomMethods(t *testing.T) {
+	tm, err := taskmanager.NewMemoryTaskManager(&testStreamingProcessor{})
+	require.NoError(t, err)
+	a2aServer, err := server.NewA2AServer(createDefaultTestAgentCard(), tm,
+		server.WithAuthProvider(auth.NewAPIKeyAuthProvider(map[string]string{"admin-key": "admin"}, "X-API-Key")),
+		server.WithParamSchema("admin/resize",
+			json.RawMessage(`{"type":"object","required":["size"],"properties":{"size":{"type":"integer"}}}`)),
+	)
+	require.NoError(t, err)
+	type resizeParams struct {
+		Size int `json:"size"`
+	}
+	require.NoError(t, a2aServer.RegisterMethod("admin/resize",
+		func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+			var p resizeParams
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			if p.Size > 100 {
+				return nil, server.NewMethodError(-32050, "size too large", map[string]int{"max": 100})
+			}
+			return map[string]int{"size": p.Size}, nil
+		}))
+	require.NoError(t, a2aServer.RegisterMethod("admin/broken",
+		func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+			return nil, errors.New("database password rejected")
+		}))
+	require.NoError(t, a2aServer.RegisterMethod("admin/archive",
+		func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+			return nil, server.NewTaskNotFoundError("archived-task")
+		}))
+	httpServer := httptest.NewServer(a2aServer.Handler())
+	defer httpServer.Close()
+	a2aClient, err := client.NewA2AClient(httpServer.URL, client.WithAPIKeyAuth("admin-key", "X-API-Key"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("Result", func(t *testing.T) {
+		var result resizeParams
+		require.NoError(t, a2aClient.Call(ctx, "admin/resize", resizeParams{Size: 42}, &result))
+		assert.Equal(t, 42, result.Size)
+	})
+
+	t.Run("HandlerError", func(t *testing.T) {
+		err := a2aClient.Call(ctx, "admin/resize", resizeParams{Size: 500}, nil)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, -32050, rpcErr.Code)
+		assert.Equal(t, "size too large", rpcErr.Message)
+	})
+
+	t.Run("A2AError", func(t *testing.T) {
+		err := a2aClient.Call(ctx, "admin/archive", nil, nil)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeTaskNotFound, rpcErr.Code)
+		assert.Equal(t, "Task not found", rpcErr.Message)
+		assert.Equal(t, "Task with ID 'archived-task' was not found.", rpcErr.Data)
+	})
+
+	t.Run("InternalErrorRedacted", func(t *testing.T) {
+		err := a2aClient.Call(ctx, "admin/broken", nil, nil)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInternalError, rpcErr.Code)
+		assert.NotContains(t, err.Error(), "password")
+	})
+
+	t.Run("ParamSchema", func(t *testing.T) {
+		err := a2aClient.Call(ctx, "admin/resize", map[string]string{"size": "big"}, nil)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+	})
+
+	t.Run("RequiresAuthentication", func(t *testing.T) {
+		anonymous, err := client.NewA2AClient(httpServer.URL)
+		require.NoError(t, err)
+		err = anonymous.Call(ctx, "admin/resize", resizeParams{Size: 1}, nil)
+		var httpErr *client.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	})
+
+	t.Run("InvalidRegistrations", func(t *testing.T) {
+		noop := func(ctx context.Context, params json.RawMessage) (interface{}, error) { return nil, nil }
+		assert.Error(t, a2aServer.RegisterMethod("admin/resize", noop), "duplicate")
+		assert.Error(t, a2aServer.RegisterMethod(protocol.MethodTasksGet, noop), "A2A method")
+		assert.Error(t, a2aServer.RegisterMethod("rpc.discover", noop), "reserved by JSON-RPC")
+		assert.Error(t, a2aServer.RegisterMethod("admin/nil", nil))
+	})
+}
+
+// TestE2E_Capabilities tests that the a2a/capabilities method reflects the agent
+// card and the features the server is configured with.
+func TestE2E_Capabilities(t *testing.T) {
+	newAgent := func(t *testing.T, card server.AgentCard, opts ...server.Option) (*server.A2AServer, *client.A2AClient) {
+		tm, err := taskmanager.NewMemoryTaskManager(&testStreamingProcessor{})
+		require.NoError(t, err)
+		a2aServer, err := server.NewA2AServer(card, tm, opts...)
+		require.NoError(t, err)
+		httpServer := httptest.NewServer(a2aServer.Handler())
+		t.Cleanup(httpServer.Close)
+		a2aClient, err := client.NewA2AClient(httpServer.URL, client.WithAPIKeyAuth("key", "X-API-Key"))
+		require.NoError(t, err)
+		return a2aServer, a2aClient
+	}
+	ctx := context.Background()
+
+	t.Run("FromCard", func(t *testing.T) {
+		card := createDefaultTestAgentCard()
+		card.Capabilities = server.AgentCapabilities{Streaming: true, PushNotifications: false}
+		card.Skills = []server.AgentSkill{{ID: "reverse", Name: "Reverse"}, {ID: "summarize", Name: "Summarize"}}
+		require.NoError(t, card.SetExtension("https://example.com/ext/billing", map[string]string{"plan": "pro"}))
+		_, a2aClient := newAgent(t, card)
+
+		caps, err := a2aClient.GetCapabilities(ctx)
+		require.NoError(t, err)
+		assert.True(t, caps.Streaming)
+		assert.False(t, caps.PushNotifications)
+		assert.Equal(t, []string{"reverse", "summarize"}, caps.Skills)
+		assert.True(t, caps.HasSkill("reverse"))
+		assert.False(t, caps.HasSkill("translate"))
+		assert.True(t, caps.HasExtension("https://example.com/ext/billing"))
+		assert.True(t, caps.HasMethod(protocol.MethodTasksSendSubscribe))
+		assert.False(t, caps.HasMethod(protocol.MethodTasksExport), "snapshots are off")
+	})
+
+	t.Run("FromServerOptions", func(t *testing.T) {
+		card := createDefaultTestAgentCard()
+		card.Capabilities = server.AgentCapabilities{}
+		a2aServer, a2aClient := newAgent(t, card,
+			server.WithAuthProvider(auth.NewAPIKeyAuthProvider(map[string]string{"key": "user"}, "X-API-Key")),
+			server.WithTaskSnapshots(),
+		)
+		require.NoError(t, a2aServer.RegisterMethod("admin/stats",
+			func(ctx context.Context, params json.RawMessage) (interface{}, error) { return nil, nil }))
+
+		caps, err := a2aClient.GetCapabilities(ctx)
+		require.NoError(t, err)
+		assert.False(t, caps.Streaming)
+		assert.Empty(t, caps.Skills)
+		assert.True(t, caps.HasMethod(protocol.MethodTasksExport))
+		assert.True(t, caps.HasMethod(protocol.MethodTasksImport))
+		assert.True(t, caps.HasMethod("admin/stats"))
+	})
+
+	t.Run("UnsupportedByAgent", func(t *testing.T) {
+		agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req jsonrpc.Request
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(jsonrpc.NewErrorResponse(req.ID, jsonrpc.ErrMethodNotFound(req.Method))))
+		}))
+		defer agent.Close()
+		a2aClient, err := client.NewA2AClient(agent.URL)
+		require.NoError(t, err)
+		_, err = a2aClient.GetCapabilities(ctx)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeMethodNotFound, rpcErr.Code)
+	})
+
+	t.Run("ReservedForCustomMethods", func(t *testing.T) {
+		a2aServer, _ := newAgent(t, createDefaultTestAgentCard())
+		assert.Error(t, a2aServer.RegisterMethod(protocol.MethodCapabilities,
+			func(ctx context.Context, params json.RawMessage) (interface{}, error) { return nil, nil }))
+	})
+}
+
+// closedChan returns a closed channel, which never blocks receivers.
+func closedChan() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+// hangingProcessor emits a few working updates, one every interval, then hangs
+// without watching its context until release is closed. It records the cause
+// its context was cancelled with.
+type hangingProcessor struct {
+	updates  int
+	interval time.Duration
+	release  chan struct{}
+	cause    chan error
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *hangingProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	go func() {
+		<-ctx.Done()
+		p.cause <- context.Cause(ctx)
+	}()
+	for i := 0; i < p.updates; i++ {
+		time.Sleep(p.interval)
+		if err := handle.UpdateStatus(protocol.TaskStateWorking, nil); err != nil {
+			return err
+		}
+	}
+	<-p.release
+	// Updates after the timeout are rejected.
+	if err := handle.UpdateStatus(protocol.TaskStateCompleted, nil); err == nil {
+		return errors.New("update after timeout accepted")
+	}
+	return nil
+}
+
+// TestE2E_ProcessorTimeout tests that a hanging processor is cancelled and its task
+// failed once the processor timeout elapses.
+func TestE2E_ProcessorTimeout(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	newAgent := func(t *testing.T, processor *hangingProcessor) (*client.A2AClient, *taskmanager.MemoryTaskManager) {
+		tm, err := taskmanager.NewMemoryTaskManager(processor)
+		require.NoError(t, err)
+		a2aServer, err := server.NewA2AServer(createDefaultTestAgentCard(), tm, server.WithProcessorTimeout(timeout))
+		require.NoError(t, err)
+		httpServer := httptest.NewServer(a2aServer.Handler())
+		t.Cleanup(httpServer.Close)
+		a2aClient, err := client.NewA2AClient(httpServer.URL)
+		require.NoError(t, err)
+		return a2aClient, tm
+	}
+	newProcessor := func(t *testing.T, updates int) *hangingProcessor {
+		p := &hangingProcessor{
+			updates:  updates,
+			interval: timeout / 2,
+			release:  make(chan struct{}),
+			cause:    make(chan error, 1),
+		}
+		t.Cleanup(func() { close(p.release) })
+		return p
+	}
+	params := func(id string) protocol.SendTaskParams {
+		return protocol.SendTaskParams{
+			ID:      id,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hang")}),
+		}
+	}
+
+	t.Run("Sync", func(t *testing.T) {
+		processor := newProcessor(t, 0)
+		a2aClient, tm := newAgent(t, processor)
+		start := time.Now()
+		_, err := a2aClient.SendTasks(context.Background(), params("sync-hang"))
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 10*timeout, "the request returns although the processor hangs")
+		assert.ErrorIs(t, <-processor.cause, taskmanager.ErrProcessorTimeout)
+
+		task, err := tm.OnGetTask(context.Background(), protocol.TaskQueryParams{ID: "sync-hang"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateFailed, task.Status.State)
+		require.NotNil(t, task.Status.Message)
+		assert.Contains(t, task.Status.Message.Parts[0].(protocol.TextPart).Text, "timed out")
+	})
+
+	t.Run("StreamingResetsOnEvents", func(t *testing.T) {
+		// Four updates half a timeout apart keep the task alive well past a single
+		// timeout; it only fails once the processor goes quiet.
+		processor := newProcessor(t, 4)
+		a2aClient, _ := newAgent(t, processor)
+		start := time.Now()
+		eventChan, err := a2aClient.StreamTask(context.Background(), params("stream-hang"))
+		require.NoError(t, err)
+
+		var working int
+		var final *protocol.TaskStatusUpdateEvent
+		for _, event := range collectAllTaskEvents(eventChan) {
+			if e, ok := event.(protocol.TaskStatusUpdateEvent); ok {
+				if e.Status.State == protocol.TaskStateWorking {
+					working++
+				}
+				if e.Final {
+					final = &e
+				}
+			}
+		}
+		require.NotNil(t, final)
+		assert.Equal(t, protocol.TaskStateFailed, final.Status.State)
+		assert.GreaterOrEqual(t, working, 4)
+		assert.GreaterOrEqual(t, time.Since(start), 2*timeout)
+		assert.ErrorIs(t, <-processor.cause, taskmanager.ErrProcessorTimeout)
+	})
+}
+
+// formProcessor asks for a structured form with an input schema, and completes the
+// task with the submitted form as an artifact.
+type formProcessor struct{}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *formProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	if form, ok := msg.Parts[0].(protocol.DataPart); ok {
+		if err := handle.AddArtifact(protocol.Artifact{Parts: []protocol.Part{form}}); err != nil {
+			return err
+		}
+		return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+	}
+	prompt := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{protocol.NewTextPart("Where to?")})
+	prompt.Metadata = map[string]interface{}{
+		protocol.MetadataKeyInputSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"city", "nights"},
+			"properties": map[string]interface{}{
+				"city":   map[string]interface{}{"type": "string"},
+				"nights": map[string]interface{}{"type": "integer", "minimum": 1},
+			},
+		},
+	}
+	return handle.UpdateStatus(protocol.TaskStateInputRequired, &prompt)
+}
+
+// TestE2E_RespondWithData tests that a form response is validated against the
+// agent's input schema before it continues the task.
+func TestE2E_RespondWithData(t *testing.T) {
+	helper := newTestHelper(t, &formProcessor{})
+	defer helper.cleanup()
+	ctx := context.Background()
+
+	task, err := helper.sendTestMessage("form-task", "book a hotel")
+	require.NoError(t, err)
+	require.Equal(t, protocol.TaskStateInputRequired, task.Status.State)
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := helper.client.RespondWithData(ctx, "form-task", map[string]interface{}{"nights": 0})
+		require.ErrorIs(t, err, client.ErrInvalidInputData)
+		assert.Contains(t, err.Error(), "city")
+		assert.Contains(t, err.Error(), "/nights")
+
+		task, err := helper.client.GetTasks(ctx, protocol.TaskQueryParams{ID: "form-task"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateInputRequired, task.Status.State, "invalid data is not sent")
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		booking := struct {
+			City   string `json:"city"`
+			Nights int    `json:"nights"`
+		}{City: "Shenzhen", Nights: 2}
+		task, err := helper.client.RespondWithData(ctx, "form-task", booking)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+		require.Len(t, task.Artifacts, 1)
+		form, ok := task.Artifacts[0].Parts[0].(protocol.DataPart)
+		require.True(t, ok)
+		assert.Equal(t, map[string]interface{}{"city": "Shenzhen", "nights": float64(2)}, form.Data)
+	})
+
+	t.Run("NotAwaitingInput", func(t *testing.T) {
+		_, err := helper.client.RespondWithData(ctx, "form-task", map[string]interface{}{"city": "Beijing"})
+		assert.ErrorIs(t, err, client.ErrTaskNotAwaitingInput)
+	})
+}
+
+// TestE2E_TaskSnapshots tests migrating a task between two agents with
+// ExportTask and ImportTask, and that the methods require authentication.
+func TestE2E_TaskSnapshots(t *testing.T) {
+	newAgent := func(t *testing.T, opts ...server.Option) (*client.A2AClient, *client.A2AClient) {
+		tm, err := taskmanager.NewMemoryTaskManager(&artifactEchoProcessor{})
+		require.NoError(t, err)
+		a2aServer, err := server.NewA2AServer(createDefaultTestAgentCard(), tm, opts...)
+		require.NoError(t, err)
+		httpServer := httptest.NewServer(a2aServer.Handler())
+		t.Cleanup(httpServer.Close)
+		authClient, err := client.NewA2AClient(httpServer.URL, client.WithAPIKeyAuth("admin-key", "X-API-Key"))
+		require.NoError(t, err)
+		plainClient, err := client.NewA2AClient(httpServer.URL)
+		require.NoError(t, err)
+		return authClient, plainClient
+	}
+	withAuth := server.WithAuthProvider(auth.NewAPIKeyAuthProvider(map[string]string{"admin-key": "admin"}, "X-API-Key"))
+	ctx := context.Background()
+
+	source, plainSource := newAgent(t, withAuth, server.WithTaskSnapshots())
+	_, err := source.SendTasks(ctx, protocol.SendTaskParams{
+		ID:       "migrated-task",
+		Message:  protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("result")}),
+		Metadata: map[string]interface{}{"owner": "alice"},
+	})
+	require.NoError(t, err)
+	snapshot, err := source.ExportTask(ctx, "migrated-task")
+	require.NoError(t, err)
+	assert.Equal(t, protocol.TaskSnapshotVersion, snapshot.Version)
+	require.Len(t, snapshot.Task.History, 1)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		target, _ := newAgent(t, withAuth, server.WithTaskSnapshots())
+		task, err := target.ImportTask(ctx, *snapshot, "")
+		require.NoError(t, err)
+		assert.Equal(t, "migrated-task", task.ID)
+
+		exported, err := target.ExportTask(ctx, "migrated-task")
+		require.NoError(t, err)
+		assert.Equal(t, snapshot.Task, exported.Task)
+
+		_, err = target.ImportTask(ctx, *snapshot, protocol.ImportConflictReject)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, taskmanager.ErrCodeTaskExists, rpcErr.Code)
+
+		renamed, err := target.ImportTask(ctx, *snapshot, protocol.ImportConflictRename)
+		require.NoError(t, err)
+		assert.Equal(t, "migrated-task-imported-1", renamed.ID)
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		newer := *snapshot
+		newer.Version = protocol.TaskSnapshotVersion + 1
+		_, err := source.ImportTask(ctx, newer, protocol.ImportConflictReplace)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, err := plainSource.ExportTask(ctx, "migrated-task")
+		var httpErr *client.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	})
+
+	t.Run("NoAuthProvider", func(t *testing.T) {
+		_, open := newAgent(t, server.WithTaskSnapshots())
+		_, err := open.ExportTask(ctx, "migrated-task")
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeMethodNotFound, rpcErr.Code)
+	})
+}
+
+// revisionProcessor streams a draft artifact in two chunks for text messages. For
+// messages referencing earlier artifacts it completes with the text they hold.
+type revisionProcessor struct{}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *revisionProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	var texts []string
+	for _, part := range msg.Parts {
+		ref, ok := part.(protocol.ArtifactReferencePart)
+		if !ok {
+			continue
+		}
+		artifact, ok := taskmanager.ResolveArtifactReference(ctx, ref)
+		if !ok {
+			return fmt.Errorf("artifact %d of task %s not resolved", ref.Index, ref.TaskID)
+		}
+		for _, artifactPart := range artifact.Parts {
+			if text, ok := artifactPart.(protocol.TextPart); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+	}
+	if len(texts) == 0 {
+		appended := true
+		if err := handle.AddArtifact(protocol.Artifact{Parts: []protocol.Part{protocol.NewTextPart("draft ")}}); err != nil {
+			return err
+		}
+		if err := handle.AddArtifact(protocol.Artifact{
+			Parts:  []protocol.Part{protocol.NewTextPart("v1")},
+			Append: &appended,
+		}); err != nil {
+			return err
+		}
+		return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+	}
+	reply := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{
+		protocol.NewTextPart("revised " + strings.Join(texts, "")),
+	})
+	return handle.UpdateStatus(protocol.TaskStateCompleted, &reply)
+}
+
+// TestE2E_ArtifactReferences tests that a follow-up message can reference an
+// artifact of an earlier task and that the processor receives it resolved.
+func TestE2E_ArtifactReferences(t *testing.T) {
+	helper := newTestHelper(t, &revisionProcessor{})
+	defer helper.cleanup()
+	ctx := context.Background()
+
+	_, err := helper.sendTestMessage("draft-task", "write a draft")
+	require.NoError(t, err)
+
+	followUp := func(taskID string, ref protocol.ArtifactReferencePart) protocol.SendTaskParams {
+		return protocol.SendTaskParams{
+			ID: taskID,
+			Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{
+				protocol.NewTextPart("revise this"),
+				ref,
+			}),
+		}
+	}
+	replyText := func(t *testing.T, task *protocol.Task) string {
+		require.NotNil(t, task.Status.Message)
+		return task.Status.Message.Parts[0].(protocol.TextPart).Text
+	}
+
+	t.Run("Send", func(t *testing.T) {
+		task, err := helper.client.SendTasks(ctx, followUp("revise-task", protocol.NewArtifactReference("draft-task", 0)))
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+		assert.Equal(t, "revised draft v1", replyText(t, task))
+
+		// The reference itself is what is stored in the history.
+		historyLength := 10
+		task, err = helper.client.GetTasks(ctx, protocol.TaskQueryParams{ID: "revise-task", HistoryLength: &historyLength})
+		require.NoError(t, err)
+		require.NotEmpty(t, task.History)
+		stored := task.History[0].Parts[1]
+		assert.Equal(t, protocol.NewArtifactReference("draft-task", 0), stored)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		events, err := helper.client.StreamTask(ctx, followUp("revise-stream-task", protocol.NewArtifactReference("draft-task", 0)))
+		require.NoError(t, err)
+		var final protocol.TaskStatusUpdateEvent
+		for event := range events {
+			if status, ok := event.(protocol.TaskStatusUpdateEvent); ok && status.IsFinal() {
+				final = status
+			}
+		}
+		require.Equal(t, protocol.TaskStateCompleted, final.Status.State)
+		require.NotNil(t, final.Status.Message)
+		assert.Equal(t, "revised draft v1", final.Status.Message.Parts[0].(protocol.TextPart).Text)
+	})
+
+	t.Run("Unresolvable", func(t *testing.T) {
+		for name, ref := range map[string]protocol.ArtifactReferencePart{
+			"MissingTask":     protocol.NewArtifactReference("no-such-task", 0),
+			"MissingArtifact": protocol.NewArtifactReference("draft-task", 3),
+			"NegativeIndex":   protocol.NewArtifactReference("draft-task", -1),
+		} {
+			_, err := helper.client.SendTasks(ctx, followUp("bad-ref-"+name, ref))
+			var rpcErr *jsonrpc.Error
+			require.ErrorAs(t, err, &rpcErr, name)
+			assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code, name)
+		}
+	})
+}
+
+// TestE2E_RequestID tests that the task records the JSON-RPC request ID that
+// created it, so client and server logs can be correlated.
+func TestE2E_RequestID(t *testing.T) {
+	helper := newTestHelper(t, &echoProcessor{})
+	defer helper.cleanup()
+	ctx := context.Background()
+	params := protocol.SendTaskParams{
+		ID:      "traced-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart("hi")}),
+	}
+
+	task, err := helper.client.SendTasks(ctx, params, client.WithRequestID("trace-7f3a"))
+	require.NoError(t, err)
+	assert.Equal(t, "trace-7f3a", task.RequestID)
+
+	// A later request for the same task keeps the ID of the one that created it.
+	_, err = helper.client.SendTasks(ctx, params, client.WithRequestID("trace-other"))
+	require.NoError(t, err)
+	task, err = helper.client.GetTasks(ctx, protocol.TaskQueryParams{ID: "traced-task"})
+	require.NoError(t, err)
+	assert.Equal(t, "trace-7f3a", task.RequestID)
+
+	// Without the option the client uses the task ID as the request ID.
+	task, err = helper.sendTestMessage("untraced-task", "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "untraced-task", task.RequestID)
+}
+
+func TestE2E_SendTasksBatch(t *testing.T) {
+	helper := newTestHelper(t, &echoProcessor{})
+	defer helper.cleanup()
+	ctx := context.Background()
+	message := func(text string) protocol.Message {
+		return protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{protocol.NewTextPart(text)})
+	}
+
+	t.Run("PartialSuccess", func(t *testing.T) {
+		results, err := helper.client.SendTasksBatch(ctx, []protocol.SendTaskParams{
+			{ID: "batch-1", Message: message("one")},
+			{ID: "batch-invalid", Message: protocol.Message{Role: protocol.MessageRoleUser}},
+			{ID: "batch-3", Message: message("three")},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		for _, i := range []int{0, 2} {
+			require.NoError(t, results[i].Err)
+			require.NotNil(t, results[i].Task)
+		}
+		assert.Equal(t, "batch-1", results[0].Task.ID)
+		assert.Equal(t, "batch-3", results[2].Task.ID)
+
+		assert.Nil(t, results[1].Task)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, results[1].Err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+
+		// The valid tasks were created; the invalid one was not.
+		task, err := helper.client.GetTasks(ctx, protocol.TaskQueryParams{ID: "batch-3"})
+		require.NoError(t, err)
+		assert.Equal(t, "batch-3", task.ID)
+		_, err = helper.client.GetTasks(ctx, protocol.TaskQueryParams{ID: "batch-invalid"})
+		assert.Error(t, err)
+	})
+
+	t.Run("RejectsEmptyAndOversizedBatches", func(t *testing.T) {
+		_, err := helper.client.SendTasksBatch(ctx, nil)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+
+		tasks := make([]protocol.SendTaskParams, protocol.MaxSendBatchSize+1)
+		for i := range tasks {
+			tasks[i] = protocol.SendTaskParams{ID: fmt.Sprintf("oversized-%d", i), Message: message("hi")}
+		}
+		_, err = helper.client.SendTasksBatch(ctx, tasks)
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.CodeInvalidRequest, rpcErr.Code)
+	})
+}
+
+// interleavingProcessor streams the parts of the message it receives, one per
+// appended chunk of artifact 0, then completes with the message itself.
+type interleavingProcessor struct{}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *interleavingProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	for i, part := range msg.Parts {
+		appendChunk, last := i > 0, i == len(msg.Parts)-1
+		artifact := protocol.Artifact{Index: 0, Parts: []protocol.Part{part}, LastChunk: &last}
+		if appendChunk {
+			artifact.Append = &appendChunk
+		}
+		if err := handle.AddArtifact(artifact); err != nil {
+			return err
+		}
+	}
+	result := protocol.NewMessage(protocol.MessageRoleAgent, msg.Parts)
+	return handle.UpdateStatus(protocol.TaskStateCompleted, &result)
+}
+
+// TestE2E_InterleavedPartsKeepOrder tests that the parts of a message mixing text,
+// files and data keep their order through requests, streams and stored tasks.
+func TestE2E_InterleavedPartsKeepOrder(t *testing.T) {
+	helper := newTestHelper(t, &interleavingProcessor{})
+	defer helper.cleanup()
+	name, mimeType, content := "chart.png", "image/png", "iVBORw0KGgo="
+	parts := []protocol.Part{
+		protocol.NewTextPart("Here is the chart:"),
+		protocol.FilePart{Type: protocol.PartTypeFile, File: protocol.FileContent{
+			Name: &name, MimeType: &mimeType, Bytes: &content,
+		}},
+		protocol.NewTextPart("and the numbers behind it:"),
+		protocol.DataPart{Type: protocol.PartTypeData, Data: map[string]interface{}{"total": float64(42)}},
+		protocol.NewTextPart("That is all."),
+	}
+
+	eventChan, err := helper.client.StreamTask(context.Background(), protocol.SendTaskParams{
+		ID:      "interleaved-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, parts),
+	})
+	require.NoError(t, err)
+	var streamed []protocol.Part
+	var final *protocol.TaskStatusUpdateEvent
+	for _, event := range collectAllTaskEvents(eventChan) {
+		switch e := event.(type) {
+		case protocol.TaskArtifactUpdateEvent:
+			streamed = append(streamed, e.Artifact.Parts...)
+		case protocol.TaskStatusUpdateEvent:
+			if e.Final {
+				final = &e
+			}
+		}
+	}
+	assert.Equal(t, parts, streamed, "artifact chunks arrive in the order they were added")
+	require.NotNil(t, final)
+	require.NotNil(t, final.Status.Message)
+	assert.Equal(t, parts, final.Status.Message.Parts)
+
+	task, err := helper.client.GetTasks(context.Background(), protocol.TaskQueryParams{ID: "interleaved-task"})
+	require.NoError(t, err)
+	artifacts := protocol.AssembleArtifacts(task.Artifacts)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, parts, artifacts[0].Parts, "the stored chunks assemble in order")
+}
+
+// TestE2E_AgentCardSpecVersions tests that the client decodes agent cards of
+// different spec versions into the same server.AgentCard.
+func TestE2E_AgentCardSpecVersions(t *testing.T) {
+	cards := map[string]string{
+		"legacy": `{"name":"Versioned Agent","url":"https://agent.example.com/","version":"1.0.0",
+			"provider":{"name":"Example Inc"},"capabilities":{"streaming":true},
+			"authentication":{"type":"apiKey","required":true},
+			"defaultInputModes":["text"],"defaultOutputModes":["text"],
+			"skills":[{"id":"echo","name":"Echo"}]}`,
+		"current": `{"protocolVersion":"0.3.0","name":"Versioned Agent","url":"https://agent.example.com/",
+			"version":"1.0.0","preferredTransport":"JSONRPC",
+			"provider":{"organization":"Example Inc","url":"https://example.com"},
+			"capabilities":{"streaming":true},
+			"securitySchemes":{"key":{"type":"apiKey","in":"header","name":"X-API-Key"}},
+			"security":[{"key":[]}],
+			"defaultInputModes":["text"],"defaultOutputModes":["text"],
+			"skills":[{"id":"echo","name":"Echo","tags":["demo"]}]}`,
+	}
+	decoded := make(map[string]server.AgentCard)
+	for version, body := range cards {
+		cardServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, body)
+		}))
+		defer cardServer.Close()
+		a2aClient, err := client.NewA2AClient(cardServer.URL)
+		require.NoError(t, err)
+		var card server.AgentCard
+		require.NoError(t, a2aClient.GetAgentCard(context.Background(), &card), version)
+		decoded[version] = card
+	}
+	legacy, current := decoded["legacy"], decoded["current"]
+	assert.Equal(t, "Versioned Agent", current.Name)
+	require.NotNil(t, current.Provider)
+	assert.Equal(t, "Example Inc", current.Provider.Name)
+	assert.Equal(t, legacy.Capabilities, current.Capabilities)
+	require.NotNil(t, current.Authentication)
+	assert.Equal(t, legacy.Authentication.Type, current.Authentication.Type)
+	assert.Equal(t, legacy.Authentication.Required, current.Authentication.Required)
+	require.Len(t, current.Skills, 1)
+	assert.Equal(t, legacy.Skills[0].ID, current.Skills[0].ID)
+}
+
+// transcribingProcessor stands in for a speech recognizer: each audio chunk
+// streamed to the task holds a word, and a partial transcription of the words so
+// far is sent once it arrives. It completes with the final transcription once the
+// input ends.
+type transcribingProcessor struct{}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *transcribingProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	reader, ok := handle.(taskmanager.InputReader)
+	if !ok {
+		return errors.New("handle takes no streamed input")
+	}
+	var words []string
+	for {
+		select {
+		case input, ok := <-reader.Input():
+			if !ok {
+				final := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{
+					protocol.NewTranscriptionPart(strings.Join(words, " "), true),
+				})
+				return handle.Complete(final)
+			}
+			for _, part := range input.Parts {
+				filePart, ok := part.(protocol.FilePart)
+				if !ok || filePart.File.Bytes == nil {
+					continue
+				}
+				audio, err := base64.StdEncoding.DecodeString(*filePart.File.Bytes)
+				if err != nil {
+					return err
+				}
+				words = append(words, string(audio))
+			}
+			partial := protocol.NewMessage(protocol.MessageRoleAgent, []protocol.Part{
+				protocol.NewTranscriptionPart(strings.Join(words, " "), false),
+			})
+			if err := handle.SendMessage(partial); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// TestE2E_StreamedAudioInput tests streaming audio chunks to an open task and
+// receiving partial transcriptions, then the final one.
+func TestE2E_StreamedAudioInput(t *testing.T) {
+	helper := newTestHelper(t, &transcribingProcessor{})
+	defer helper.cleanup()
+	ctx := context.Background()
+
+	eventChan, err := helper.client.StreamTask(ctx, protocol.SendTaskParams{
+		ID: "voice-task",
+		Message: protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{
+			protocol.NewTextPart("transcribe"),
+		}),
+	})
+	require.NoError(t, err)
+	chunks := []string{"hello", "streaming", "world"}
+	for i, chunk := range chunks {
+		input := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{
+			protocol.NewAudioPart("audio/pcm", []byte(chunk)),
+		})
+		_, err := helper.client.SendInput(ctx, "voice-task", input, i == len(chunks)-1)
+		require.NoError(t, err)
+	}
+
+	var transcriptions []protocol.Transcription
+	for _, event := range collectAllTaskEvents(eventChan) {
+		if transcription, ok := protocol.TranscriptionFromEvent(event); ok {
+			transcriptions = append(transcriptions, transcription)
+		}
+	}
+	assert.Equal(t, []protocol.Transcription{
+		{Text: "hello"},
+		{Text: "hello streaming"},
+		{Text: "hello streaming world"},
+		{Text: "hello streaming world", Final: true},
+	}, transcriptions)
+
+	// The input ended with the final chunk.
+	input := protocol.NewMessage(protocol.MessageRoleUser, []protocol.Part{
+		protocol.NewAudioPart("audio/pcm", []byte("late")),
+	})
+	_, err = helper.client.SendInput(ctx, "voice-task", input, false)
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, taskmanager.ErrCodeTaskFinal, rpcErr.Code)
+}
+
+// chunkProcessor adds a number of artifacts, then completes the task.
+type chunkProcessor struct {
+	chunks int
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *chunkProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	for i := 0; i < p.chunks; i++ {
+		if err := handle.AddArtifact(protocol.Artifact{
+			Index: i,
+			Parts: []protocol.Part{protocol.NewTextPart(fmt.Sprintf("chunk %d", i))},
+		}); err != nil {
+			return err
+		}
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// TestE2E_ConnFlowControl tests streaming over a long-lived connection, where the
+// server sends one event per credit the client granted.
+func TestE2E_ConnFlowControl(t *testing.T) {
+	tm, err := taskmanager.NewMemoryTaskManager(&chunkProcessor{chunks: 4})
+	require.NoError(t, err)
+	a2aServer, err := server.NewA2AServer(createDefaultTestAgentCard(), tm)
+	require.NoError(t, err)
+	clientConn, serverConn := net.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- a2aServer.ServeConn(context.Background(), serverConn)
+	}()
+	connClient, err := client.NewConnClient(clientConn)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// receive returns the next event of stream, or nil if none arrives in time.
+	receive := func(stream *client.ConnStream, wait time.Duration) (protocol.TaskEvent, bool) {
+		select {
+		case event, ok := <-stream.Events():
+			return event, ok
+		case <-time.After(wait):
+			return nil, true
+		}
+	}
+
+	t.Run("PausesWithoutCredits", func(t *testing.T) {
+		stream, err := connClient.SendTaskSubscribe(ctx, protocol.SendTaskParams{
+			ID:      "credit-task",
+			Message: createTextMessage("stream"),
+		}, 2)
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			event, ok := receive(stream, 3*time.Second)
+			require.True(t, ok)
+			require.NotNil(t, event, "event %d within the credits", i)
+		}
+		event, ok := receive(stream, 200*time.Millisecond)
+		require.True(t, ok)
+		assert.Nil(t, event, "no event beyond the credits")
+
+		require.NoError(t, stream.Grant(100))
+		var events []protocol.TaskEvent
+		for event := range stream.Events() {
+			events = append(events, event)
+		}
+		require.NotEmpty(t, events)
+		last, isStatus := events[len(events)-1].(protocol.TaskStatusUpdateEvent)
+		require.True(t, isStatus)
+		assert.True(t, last.Final)
+		assert.Equal(t, protocol.TaskStateCompleted, last.Status.State)
+		var artifacts int
+		for _, event := range events {
+			if _, ok := event.(protocol.TaskArtifactUpdateEvent); ok {
+				artifacts++
+			}
+		}
+		assert.LessOrEqual(t, 2, artifacts)
+	})
+
+	t.Run("GrantsOneByOne", func(t *testing.T) {
+		stream, err := connClient.Resubscribe(ctx, protocol.TaskIDParams{ID: "credit-task"}, 0)
+		require.NoError(t, err)
+		event, ok := receive(stream, 200*time.Millisecond)
+		require.True(t, ok)
+		assert.Nil(t, event, "no event without credits")
+		require.NoError(t, stream.Grant(1))
+		event, ok = receive(stream, 3*time.Second)
+		require.True(t, ok)
+		status, isStatus := event.(protocol.TaskStatusUpdateEvent)
+		require.True(t, isStatus)
+		assert.Equal(t, protocol.TaskStateCompleted, status.Status.State)
+		_, ok = receive(stream, 3*time.Second)
+		assert.False(t, ok, "the stream ends after the final status")
+	})
+
+	t.Run("ErrorEndsStream", func(t *testing.T) {
+		stream, err := connClient.Resubscribe(ctx, protocol.TaskIDParams{ID: "missing-task"}, 1)
+		require.NoError(t, err)
+		event, ok := receive(stream, 3*time.Second)
+		require.True(t, ok)
+		streamErr, isErr := event.(protocol.TaskStreamErrorEvent)
+		require.True(t, isErr, "got %T", event)
+		var rpcErr *jsonrpc.Error
+		assert.ErrorAs(t, streamErr.Err, &rpcErr)
+	})
+
+	t.Run("Calls", func(t *testing.T) {
+		task, err := connClient.GetTasks(ctx, protocol.TaskQueryParams{ID: "credit-task"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.TaskStateCompleted, task.Status.State)
+	})
+
+	require.NoError(t, connClient.Close())
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("ServeConn did not return after the client closed")
+	}
+}
+
+// fileProcessor completes tasks with an artifact holding content as a file.
+type fileProcessor struct {
+	content []byte
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *fileProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	encoded := base64.StdEncoding.EncodeToString(p.content)
+	mimeType := "application/octet-stream"
+	if err := handle.AddArtifact(protocol.Artifact{
+		Index: 0,
+		Parts: []protocol.Part{protocol.FilePart{
+			Type: protocol.PartTypeFile,
+			File: protocol.FileContent{MimeType: &mimeType, Bytes: &encoded},
+		}},
+	}); err != nil {
+		return err
+	}
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// abortingWriter aborts the response after limit bytes of its body.
+type abortingWriter struct {
+	http.ResponseWriter
+	limit int
+}
+
+func (w *abortingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		w.ResponseWriter.Write(p[:w.limit])
+		w.ResponseWriter.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	w.limit -= len(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// TestE2E_ResumableArtifactDownload tests downloading artifact content that
+// resumes with a Range request after the first response is cut off.
+func TestE2E_ResumableArtifactDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("resumable artifact content "), 1000)
+	tm, err := taskmanager.NewMemoryTaskManager(&fileProcessor{content: content})
+	require.NoError(t, err)
+	a2aServer, err := server.NewA2AServer(createDefaultTestAgentCard(), tm,
+		server.WithArtifactContentEndpoint("/artifacts"))
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var ranges, ifRanges []string
+	abortAfter := 0
+	handler := a2aServer.Handler()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/artifacts/") {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		ifRanges = append(ifRanges, r.Header.Get("If-Range"))
+		limit := abortAfter
+		abortAfter = 0
+		mu.Unlock()
+		if limit > 0 {
+			w = &abortingWriter{ResponseWriter: w, limit: limit}
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	a2aClient, err := client.NewA2AClient(ts.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+	task, err := a2aClient.SendTasks(ctx, protocol.SendTaskParams{
+		ID:      "download-task",
+		Message: createTextMessage("file"),
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		task, err = a2aClient.GetTasks(ctx, protocol.TaskQueryParams{ID: "download-task"})
+		return err == nil && task.Status.State == protocol.TaskStateCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	uri := server.ArtifactContentPath("/artifacts", task.ID, 0)
+
+	reset := func(abort int) {
+		mu.Lock()
+		defer mu.Unlock()
+		ranges, ifRanges, abortAfter = nil, nil, abort
+	}
+
+	t.Run("Complete", func(t *testing.T) {
+		reset(0)
+		var buf bytes.Buffer
+		n, err := a2aClient.DownloadArtifact(ctx, uri, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), n)
+		assert.Equal(t, content, buf.Bytes())
+		assert.Equal(t, []string{""}, ranges)
+	})
+
+	t.Run("ResumesInterrupted", func(t *testing.T) {
+		const cut = 10000
+		reset(cut)
+		var buf bytes.Buffer
+		n, err := a2aClient.DownloadArtifact(ctx, uri, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), n)
+		assert.Equal(t, content, buf.Bytes(), "the two ranges reassemble the content")
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, ranges, 2)
+		assert.Equal(t, "", ranges[0])
+		assert.Equal(t, fmt.Sprintf("bytes=%d-", cut), ranges[1])
+		assert.Equal(t, `"`+protocol.ComputeChecksum(content)+`"`, ifRanges[1])
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		reset(0)
+		_, err := a2aClient.DownloadArtifact(ctx, server.ArtifactContentPath("/artifacts", task.ID, 1), io.Discard)
+		var httpErr *client.HTTPError
+		require.True(t, errors.As(err, &httpErr), "got %v", err)
+		assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+	})
+}
+
+// baggageProcessor records the baggage in the context of the tasks it processes.
+type baggageProcessor struct {
+	mu      sync.Mutex
+	baggage map[string]protocol.Baggage
+}
+
+// Process implements taskmanager.TaskProcessor.
+func (p *baggageProcessor) Process(
+	ctx context.Context,
+	taskID string,
+	msg protocol.Message,
+	handle taskmanager.TaskHandle,
+) error {
+	p.mu.Lock()
+	p.baggage[taskID] = protocol.BaggageFromContext(ctx)
+	p.mu.Unlock()
+	return handle.UpdateStatus(protocol.TaskStateCompleted, nil)
+}
+
+// TestE2E_Baggage tests that the baggage set on the context of a client call
+// reaches the task processor.
+func TestE2E_Baggage(t *testing.T) {
+	processor := &baggageProcessor{baggage: make(map[string]protocol.Baggage)}
+	tm, err := taskmanager.NewMemoryTaskManager(processor)
+	require.NoError(t, err)
+	a2aServer, err := server.NewA2AServer(createDefaultTestAgentCard(), tm)
+	require.NoError(t, err)
+	ts := httptest.NewServer(a2aServer.Handler())
+	t.Cleanup(ts.Close)
+	a2aClient, err := client.NewA2AClient(ts.URL)
+	require.NoError(t, err)
+
+	baggage := protocol.Baggage{"tenant": "acme", "experiment": "blue, v2"}
+	ctx := protocol.WithBaggage(context.Background(), baggage)
+	recorded := func(taskID string) protocol.Baggage {
+		var got protocol.Baggage
+		require.Eventually(t, func() bool {
+			processor.mu.Lock()
+			defer processor.mu.Unlock()
+			var ok bool
+			got, ok = processor.baggage[taskID]
+			return ok
+		}, 5*time.Second, 10*time.Millisecond)
+		return got
+	}
+
+	t.Run("Send", func(t *testing.T) {
+		_, err := a2aClient.SendTasks(ctx, protocol.SendTaskParams{
+			ID:      "baggage-send",
+			Message: createTextMessage("hello"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, baggage, recorded("baggage-send"))
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		events, err := a2aClient.StreamTask(ctx, protocol.SendTaskParams{
+			ID:      "baggage-stream",
+			Message: createTextMessage("hello"),
+		})
+		require.NoError(t, err)
+		for range events {
+		}
+		assert.Equal(t, baggage, recorded("baggage-stream"))
+	})
+
+	t.Run("NoBaggage", func(t *testing.T) {
+		_, err := a2aClient.SendTasks(context.Background(), protocol.SendTaskParams{
+			ID:      "baggage-none",
+			Message: createTextMessage("hello"),
+		})
+		require.NoError(t, err)
+		assert.Nil(t, recorded("baggage-none"))
+	})
+}